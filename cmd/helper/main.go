@@ -1,13 +1,17 @@
 package main
 
 import (
+	"encoding/json"
+	"flag"
 	"fmt"
 	"log"
 	"os"
 	"os/signal"
 	"syscall"
 
+	"github.com/flyhigher139/mhost/internal/cli"
 	"github.com/flyhigher139/mhost/internal/helper"
+	"github.com/flyhigher139/mhost/internal/host"
 	"github.com/flyhigher139/mhost/pkg/logger"
 )
 
@@ -19,35 +23,86 @@ const (
 )
 
 func main() {
-	// 打印版本信息
-	fmt.Printf("mHost Helper Tool v%s\n", Version)
+	opts, err := parseOptions(os.Args[1:])
+	if err != nil {
+		if err == flag.ErrHelp {
+			os.Exit(0)
+		}
+		os.Exit(2)
+	}
+
+	if opts.ShowVersion {
+		printVersion(opts.Output)
+		return
+	}
+
+	if opts.CheckConfig {
+		os.Exit(checkConfig(opts))
+	}
+
+	if opts.ExportBackups != "" {
+		os.Exit(exportBackups(opts))
+	}
+
+	// 打印版本信息，机器可读输出时不混入额外文本
+	if !opts.Output.IsMachineReadable() {
+		fmt.Printf("mHost Helper Tool v%s\n", Version)
+	}
+
+	if errs := opts.validate(); len(errs) > 0 {
+		for _, err := range errs {
+			log.Printf("Invalid configuration: %v", err)
+		}
+		os.Exit(1)
+	}
 
 	// 初始化日志
 	log.SetFlags(log.LstdFlags | log.Lshortfile)
 	log.Println("Starting mHost Helper Tool...")
 
 	// 创建增强日志器
-	logger := logger.NewEnhancedLogger(logger.LogLevelInfo, false)
+	logger, err := opts.newLogger()
+	if err != nil {
+		log.Fatalf("Failed to create logger: %v", err)
+	}
 
 	// 创建Helper Tool实例
-	helperTool, err := helper.NewHostsHelper(ServiceName, logger)
+	helperTool, err := helper.NewHostsHelperWithOptions(&opts.HelperOptions, logger)
 	if err != nil {
 		log.Fatalf("Failed to create HostsHelper: %v", err)
 	}
 
+	if opts.DryRun {
+		log.Println("Dry-run mode enabled, hosts file will not be modified")
+	}
+
 	// 设置信号处理
 	sigChan := make(chan os.Signal, 1)
-	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM, syscall.SIGUSR1)
 
 	// 启动Helper Tool
 	if err := helperTool.Start(); err != nil {
 		log.Fatalf("Failed to start HostsHelper: %v", err)
 	}
 
+	if opts.ShowStatus {
+		printStatus(helperTool, opts.Output)
+		if err := helperTool.Stop(); err != nil {
+			log.Printf("Error stopping HostsHelper: %v", err)
+		}
+		return
+	}
+
 	log.Println("mHost Helper Tool started successfully")
 
-	// 等待信号
-	<-sigChan
+	// 等待信号，SIGUSR1输出状态后继续运行
+	for sig := range sigChan {
+		if sig == syscall.SIGUSR1 {
+			printStatus(helperTool, cli.FormatText)
+			continue
+		}
+		break
+	}
 	log.Println("Received shutdown signal")
 
 	// 停止Helper Tool
@@ -56,4 +111,78 @@ func main() {
 	}
 
 	log.Println("mHost Helper Tool stopped")
-}
\ No newline at end of file
+}
+
+// printVersion 输出版本信息
+func printVersion(format cli.Format) {
+	if format.IsMachineReadable() {
+		writeDocument(format, cli.NewDocument(cli.KindVersion, &cli.VersionInfo{Name: "mhost-helper", Version: Version}))
+		return
+	}
+	fmt.Printf("mHost Helper Tool v%s\n", Version)
+}
+
+// checkConfig 验证配置并输出结果，返回进程退出码
+func checkConfig(opts *cliOptions) int {
+	errs := opts.validate()
+	if opts.Output.IsMachineReadable() {
+		writeDocument(opts.Output, cli.NewDocument(cli.KindConfigCheck, cli.NewConfigCheck(errs)))
+		if len(errs) > 0 {
+			return 1
+		}
+		return 0
+	}
+
+	if len(errs) == 0 {
+		fmt.Println("Configuration OK")
+		return 0
+	}
+
+	for _, err := range errs {
+		fmt.Fprintf(os.Stderr, "config error: %v\n", err)
+	}
+	return 1
+}
+
+// printStatus 输出Helper Tool状态，文本格式保持原有的JSON输出
+func printStatus(helperTool *helper.HostsHelper, format cli.Format) {
+	if format.IsMachineReadable() {
+		writeDocument(format, cli.NewDocument(cli.KindHelperStatus, helperTool.Status()))
+		return
+	}
+
+	data, err := json.MarshalIndent(helperTool.Status(), "", "  ")
+	if err != nil {
+		log.Printf("Failed to marshal status: %v", err)
+		return
+	}
+	fmt.Println(string(data))
+}
+
+// exportBackups 将备份历史导出到标准输出，返回进程退出码；日志只输出错误以免混入导出内容
+func exportBackups(opts *cliOptions) int {
+	format, err := host.ParseExportFormat(opts.ExportBackups)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+
+	backupMgr, err := helper.NewBackupManager(logger.NewEnhancedLogger(logger.LogLevelError, false), opts.BackupDir, opts.MaxBackups)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to open backups: %v\n", err)
+		return 1
+	}
+
+	if err := backupMgr.GetBackupHistory().Export(os.Stdout, format); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	return 0
+}
+
+// writeDocument 以机器可读格式输出文档
+func writeDocument(format cli.Format, doc *cli.Document) {
+	if err := cli.Encode(os.Stdout, format, doc); err != nil {
+		log.Printf("Failed to write output: %v", err)
+	}
+}
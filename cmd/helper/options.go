@@ -0,0 +1,193 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/flyhigher139/mhost/internal/cli"
+	"github.com/flyhigher139/mhost/internal/helper"
+	"github.com/flyhigher139/mhost/internal/host"
+	"github.com/flyhigher139/mhost/pkg/logger"
+)
+
+// 环境变量前缀
+const envPrefix = "MHOST_HELPER_"
+
+// cliOptions 命令行选项
+type cliOptions struct {
+	helper.HelperOptions
+
+	LogLevel    string
+	LogFile     string
+	Daemon      bool
+	ShowVersion bool
+	CheckConfig bool
+	ShowStatus  bool
+	Output      cli.Format
+	// ExportBackups 非空时以该格式（csv、json）导出备份历史后退出
+	ExportBackups string
+}
+
+// parseOptions 解析命令行参数，未显式指定的参数从环境变量读取
+func parseOptions(args []string) (*cliOptions, error) {
+	defaults := helper.DefaultHelperOptions(ServiceName)
+	opts := &cliOptions{}
+
+	fs := flag.NewFlagSet("mhost-helper", flag.ContinueOnError)
+	fs.StringVar(&opts.ServiceName, "service-name", envString("SERVICE_NAME", defaults.ServiceName), "XPC service name")
+	fs.StringVar(&opts.HostsPath, "hosts-path", envString("HOSTS_PATH", defaults.HostsPath), "hosts file path")
+	fs.StringVar(&opts.AuditLogPath, "audit-log", envString("AUDIT_LOG", defaults.AuditLogPath), "audit log file path")
+	fs.StringVar(&opts.BackupDir, "backup-dir", envString("BACKUP_DIR", defaults.BackupDir), "backup directory")
+	fs.IntVar(&opts.MaxBackups, "max-backups", envInt("MAX_BACKUPS", defaults.MaxBackups), "maximum number of backups to keep")
+	fs.BoolVar(&opts.DryRun, "dry-run", envBool("DRY_RUN", false), "do not modify the hosts file")
+	fs.BoolVar(&opts.RestartOnPanic, "restart-on-panic", envBool("RESTART_ON_PANIC", defaults.RestartOnPanic), "restart background components that panic")
+	fs.IntVar(&opts.MaxRestarts, "max-restarts", envInt("MAX_RESTARTS", defaults.MaxRestarts), "maximum automatic restarts per component")
+	fs.DurationVar(&opts.CoalesceWindow, "coalesce-window", envDuration("COALESCE_WINDOW", defaults.CoalesceWindow), "window in which successive hosts writes are merged into one (0 disables waiting)")
+	fs.IntVar(&opts.MaxConcurrent, "max-concurrent", envInt("MAX_CONCURRENT", defaults.MaxConcurrent), "maximum number of requests handled at the same time")
+	fs.StringVar(&opts.LogLevel, "log-level", envString("LOG_LEVEL", "info"), "log level (debug, info, warn, error)")
+	fs.StringVar(&opts.LogFile, "log-file", envString("LOG_FILE", ""), "log file path (daemon mode defaults to /var/log/mhost-helper.log)")
+	fs.BoolVar(&opts.Daemon, "daemon", envBool("DAEMON", false), "run in daemon mode with structured file logging")
+	fs.BoolVar(&opts.ShowVersion, "version", false, "print version and exit")
+	fs.BoolVar(&opts.CheckConfig, "check-config", false, "validate configuration and exit")
+	fs.BoolVar(&opts.ShowStatus, "status", false, "run a self check, print status and exit")
+	fs.StringVar(&opts.ExportBackups, "export-backups", "", "export backup history (csv, json) to stdout and exit")
+	output := fs.String("output", envString("OUTPUT", string(cli.FormatText)), "output format for --version, --check-config and --status (text, json, yaml)")
+
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+
+	format, err := cli.ParseFormat(*output)
+	if err != nil {
+		fmt.Fprintln(fs.Output(), err)
+		return nil, err
+	}
+	opts.Output = format
+
+	if opts.ExportBackups != "" {
+		if _, err := host.ParseExportFormat(opts.ExportBackups); err != nil {
+			fmt.Fprintln(fs.Output(), err)
+			return nil, err
+		}
+	}
+
+	if opts.Daemon && opts.LogFile == "" {
+		opts.LogFile = "/var/log/mhost-helper.log"
+	}
+
+	return opts, nil
+}
+
+// validate 验证选项
+func (o *cliOptions) validate() []error {
+	var errs []error
+
+	if o.ServiceName == "" {
+		errs = append(errs, fmt.Errorf("service name cannot be empty"))
+	}
+
+	if _, err := logger.ParseLogLevel(o.LogLevel); err != nil {
+		errs = append(errs, err)
+	}
+
+	if o.MaxBackups <= 0 {
+		errs = append(errs, fmt.Errorf("max backups must be positive: %d", o.MaxBackups))
+	}
+
+	if o.MaxConcurrent <= 0 {
+		errs = append(errs, fmt.Errorf("max concurrent requests must be positive: %d", o.MaxConcurrent))
+	}
+
+	if o.CoalesceWindow < 0 {
+		errs = append(errs, fmt.Errorf("coalesce window cannot be negative: %v", o.CoalesceWindow))
+	}
+
+	if o.MaxRestarts < 0 {
+		errs = append(errs, fmt.Errorf("max restarts cannot be negative: %d", o.MaxRestarts))
+	}
+
+	paths := map[string]string{
+		"hosts-path": o.HostsPath,
+		"audit-log":  o.AuditLogPath,
+		"backup-dir": o.BackupDir,
+	}
+	if o.LogFile != "" {
+		paths["log-file"] = o.LogFile
+	}
+	for name, path := range paths {
+		if !filepath.IsAbs(path) {
+			errs = append(errs, fmt.Errorf("%s must be an absolute path: %q", name, path))
+		}
+	}
+
+	if info, err := os.Stat(o.HostsPath); err != nil {
+		errs = append(errs, fmt.Errorf("hosts file is not accessible: %w", err))
+	} else if info.IsDir() {
+		errs = append(errs, fmt.Errorf("hosts path is a directory: %s", o.HostsPath))
+	}
+
+	for _, dir := range []string{filepath.Dir(o.AuditLogPath), o.BackupDir} {
+		if info, err := os.Stat(dir); err == nil && !info.IsDir() {
+			errs = append(errs, fmt.Errorf("not a directory: %s", dir))
+		}
+	}
+
+	return errs
+}
+
+// newLogger 根据选项创建日志器
+func (o *cliOptions) newLogger() (logger.Logger, error) {
+	level, err := logger.ParseLogLevel(o.LogLevel)
+	if err != nil {
+		return nil, err
+	}
+
+	if o.LogFile != "" {
+		return logger.NewFileLogger(o.LogFile, level, o.Daemon)
+	}
+
+	return logger.NewEnhancedLogger(level, o.Daemon), nil
+}
+
+// envString 读取字符串环境变量
+func envString(key, fallback string) string {
+	if value, ok := os.LookupEnv(envPrefix + key); ok {
+		return value
+	}
+	return fallback
+}
+
+// envInt 读取整数环境变量
+func envInt(key string, fallback int) int {
+	if value, ok := os.LookupEnv(envPrefix + key); ok {
+		if n, err := strconv.Atoi(strings.TrimSpace(value)); err == nil {
+			return n
+		}
+	}
+	return fallback
+}
+
+// envDuration 读取时长环境变量，例如"200ms"
+func envDuration(key string, fallback time.Duration) time.Duration {
+	if value, ok := os.LookupEnv(envPrefix + key); ok {
+		if d, err := time.ParseDuration(strings.TrimSpace(value)); err == nil {
+			return d
+		}
+	}
+	return fallback
+}
+
+// envBool 读取布尔环境变量
+func envBool(key string, fallback bool) bool {
+	if value, ok := os.LookupEnv(envPrefix + key); ok {
+		if b, err := strconv.ParseBool(strings.TrimSpace(value)); err == nil {
+			return b
+		}
+	}
+	return fallback
+}
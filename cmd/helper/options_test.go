@@ -0,0 +1,186 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/flyhigher139/mhost/internal/cli"
+	"github.com/flyhigher139/mhost/internal/helper"
+)
+
+// TestParseOptions 测试显式指定的参数优先于环境变量，环境变量优先于默认值，无法解析的环境变量使用默认值
+func TestParseOptions(t *testing.T) {
+	defaults := helper.DefaultHelperOptions(ServiceName)
+
+	tests := []struct {
+		name  string
+		env   map[string]string
+		args  []string
+		check func(t *testing.T, opts *cliOptions)
+	}{
+		{
+			name: "defaults",
+			check: func(t *testing.T, opts *cliOptions) {
+				assert.Equal(t, defaults.ServiceName, opts.ServiceName)
+				assert.Equal(t, defaults.HostsPath, opts.HostsPath)
+				assert.Equal(t, defaults.MaxBackups, opts.MaxBackups)
+				assert.Equal(t, defaults.CoalesceWindow, opts.CoalesceWindow)
+				assert.Equal(t, "info", opts.LogLevel)
+				assert.Empty(t, opts.LogFile)
+				assert.False(t, opts.DryRun)
+				assert.Equal(t, cli.FormatText, opts.Output)
+			},
+		},
+		{
+			name: "environment",
+			env: map[string]string{
+				"HOSTS_PATH":      "/tmp/hosts",
+				"MAX_BACKUPS":     "5",
+				"DRY_RUN":         "true",
+				"COALESCE_WINDOW": "200ms",
+				"OUTPUT":          "json",
+			},
+			check: func(t *testing.T, opts *cliOptions) {
+				assert.Equal(t, "/tmp/hosts", opts.HostsPath)
+				assert.Equal(t, 5, opts.MaxBackups)
+				assert.True(t, opts.DryRun)
+				assert.Equal(t, 200*time.Millisecond, opts.CoalesceWindow)
+				assert.Equal(t, cli.FormatJSON, opts.Output)
+			},
+		},
+		{
+			name: "flags override environment",
+			env:  map[string]string{"HOSTS_PATH": "/tmp/hosts", "MAX_BACKUPS": "5", "DRY_RUN": "true", "LOG_LEVEL": "debug"},
+			args: []string{"--hosts-path", "/etc/hosts.test", "--max-backups", "7", "--dry-run=false", "--log-level", "warn"},
+			check: func(t *testing.T, opts *cliOptions) {
+				assert.Equal(t, "/etc/hosts.test", opts.HostsPath)
+				assert.Equal(t, 7, opts.MaxBackups)
+				assert.False(t, opts.DryRun)
+				assert.Equal(t, "warn", opts.LogLevel)
+			},
+		},
+		{
+			name: "invalid environment values fall back to defaults",
+			env:  map[string]string{"MAX_BACKUPS": "many", "DRY_RUN": "maybe", "COALESCE_WINDOW": "soon"},
+			check: func(t *testing.T, opts *cliOptions) {
+				assert.Equal(t, defaults.MaxBackups, opts.MaxBackups)
+				assert.False(t, opts.DryRun)
+				assert.Equal(t, defaults.CoalesceWindow, opts.CoalesceWindow)
+			},
+		},
+		{
+			name: "daemon mode defaults the log file",
+			env:  map[string]string{"DAEMON": "1"},
+			check: func(t *testing.T, opts *cliOptions) {
+				assert.True(t, opts.Daemon)
+				assert.Equal(t, "/var/log/mhost-helper.log", opts.LogFile)
+			},
+		},
+		{
+			name: "explicit log file in daemon mode",
+			args: []string{"--daemon", "--log-file", "/tmp/helper.log"},
+			check: func(t *testing.T, opts *cliOptions) {
+				assert.Equal(t, "/tmp/helper.log", opts.LogFile)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for key, value := range tt.env {
+				t.Setenv(envPrefix+key, value)
+			}
+			opts, err := parseOptions(tt.args)
+			require.NoError(t, err)
+			tt.check(t, opts)
+		})
+	}
+}
+
+// TestParseOptionsErrors 测试无效的输出格式和导出格式
+func TestParseOptionsErrors(t *testing.T) {
+	_, err := parseOptions([]string{"--output", "csv"})
+	assert.Error(t, err)
+
+	t.Setenv(envPrefix+"OUTPUT", "xml")
+	_, err = parseOptions(nil)
+	assert.Error(t, err)
+	_, err = parseOptions([]string{"--output", "yaml"})
+	assert.NoError(t, err, "the flag overrides an invalid environment value")
+
+	_, err = parseOptions([]string{"--output", "text", "--export-backups", "xml"})
+	assert.Error(t, err)
+	_, err = parseOptions([]string{"--unknown"})
+	assert.Error(t, err)
+}
+
+// TestValidate 测试选项验证的各项错误
+func TestValidate(t *testing.T) {
+	dir := t.TempDir()
+	hostsPath := filepath.Join(dir, "hosts")
+	require.NoError(t, os.WriteFile(hostsPath, []byte("127.0.0.1\tlocalhost\n"), 0644))
+	notDir := filepath.Join(dir, "file")
+	require.NoError(t, os.WriteFile(notDir, nil, 0644))
+
+	valid := func() *cliOptions {
+		return &cliOptions{
+			HelperOptions: helper.HelperOptions{
+				ServiceName:   ServiceName,
+				HostsPath:     hostsPath,
+				AuditLogPath:  filepath.Join(dir, "audit.log"),
+				BackupDir:     filepath.Join(dir, "backups"),
+				MaxBackups:    10,
+				MaxConcurrent: 4,
+			},
+			LogLevel: "info",
+		}
+	}
+
+	tests := []struct {
+		name   string
+		modify func(o *cliOptions)
+		errors []string
+	}{
+		{name: "valid", modify: func(o *cliOptions) {}},
+		{name: "empty service name", modify: func(o *cliOptions) { o.ServiceName = "" }, errors: []string{"service name cannot be empty"}},
+		{name: "invalid log level", modify: func(o *cliOptions) { o.LogLevel = "verbose" }, errors: []string{"verbose"}},
+		{name: "max backups", modify: func(o *cliOptions) { o.MaxBackups = 0 }, errors: []string{"max backups must be positive: 0"}},
+		{name: "max concurrent", modify: func(o *cliOptions) { o.MaxConcurrent = -1 }, errors: []string{"max concurrent requests must be positive: -1"}},
+		{name: "coalesce window", modify: func(o *cliOptions) { o.CoalesceWindow = -time.Second }, errors: []string{"coalesce window cannot be negative"}},
+		{name: "max restarts", modify: func(o *cliOptions) { o.MaxRestarts = -1 }, errors: []string{"max restarts cannot be negative: -1"}},
+		{name: "relative log file", modify: func(o *cliOptions) { o.LogFile = "helper.log" }, errors: []string{`log-file must be an absolute path: "helper.log"`}},
+		{
+			name:   "relative hosts path",
+			modify: func(o *cliOptions) { o.HostsPath = "hosts" },
+			errors: []string{`hosts-path must be an absolute path: "hosts"`, "hosts file is not accessible"},
+		},
+		{name: "missing hosts file", modify: func(o *cliOptions) { o.HostsPath = filepath.Join(dir, "missing") }, errors: []string{"hosts file is not accessible"}},
+		{name: "hosts path is a directory", modify: func(o *cliOptions) { o.HostsPath = dir }, errors: []string{"hosts path is a directory"}},
+		{name: "backup dir is a file", modify: func(o *cliOptions) { o.BackupDir = notDir }, errors: []string{"not a directory: " + notDir}},
+		{
+			name: "multiple errors",
+			modify: func(o *cliOptions) {
+				o.ServiceName = ""
+				o.MaxBackups = 0
+			},
+			errors: []string{"service name cannot be empty", "max backups must be positive"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := valid()
+			tt.modify(opts)
+			errs := opts.validate()
+			require.Len(t, errs, len(tt.errors))
+			for i, expected := range tt.errors {
+				assert.ErrorContains(t, errs[i], expected)
+			}
+		})
+	}
+}
@@ -1,6 +1,8 @@
 package main
 
 import (
+	"flag"
+	"fmt"
 	"log"
 	"os"
 
@@ -8,11 +10,32 @@ import (
 	"fyne.io/fyne/v2/app"
 	"fyne.io/fyne/v2/widget"
 
+	"github.com/flyhigher139/mhost/internal/command"
 	"github.com/flyhigher139/mhost/internal/ui"
 )
 
 // main 应用程序入口点
 func main() {
+	// 子命令（daemon、status、apply等）无需启动GUI
+	if len(os.Args) > 1 {
+		if command.Has(os.Args[1]) {
+			os.Exit(command.Run("mhost", os.Args[1], os.Args[2:]))
+		}
+	}
+
+	// 启动参数：--apply启动后立即应用Profile，配合--exit应用后退出而不打开窗口
+	options, err := parseStartupOptions(os.Args[1:])
+	if err != nil {
+		if err == flag.ErrHelp {
+			os.Exit(0)
+		}
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	if options.exit {
+		os.Exit(runStartupApply(options))
+	}
+
 	// 创建Fyne应用
 	myApp := app.NewWithID("com.gevin.mhost")
 
@@ -31,6 +54,9 @@ func main() {
 
 	// 设置窗口内容
 	mainWindow.SetContent(uiManager.GetMainContainer())
+	if options.apply != "" {
+		uiManager.ApplyProfileOnStartup(options.apply)
+	}
 
 	// 设置窗口关闭回调
 	mainWindow.SetCloseIntercept(func() {
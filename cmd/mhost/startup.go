@@ -0,0 +1,121 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/flyhigher139/mhost/internal/activity"
+	"github.com/flyhigher139/mhost/internal/config"
+	"github.com/flyhigher139/mhost/internal/controller"
+	"github.com/flyhigher139/mhost/internal/host"
+	"github.com/flyhigher139/mhost/pkg/models"
+)
+
+// startupEventSource 启动参数应用Profile时记录活动使用的事件源
+const startupEventSource = "startup"
+
+// startupOptions GUI启动参数
+type startupOptions struct {
+	apply string // 启动后立即应用的Profile名称或ID
+	exit  bool   // 应用后退出，不打开窗口
+	force bool   // 不打开窗口应用时忽略hosts文件规模限制
+}
+
+// parseStartupOptions 解析GUI启动参数，例如 mhost --apply work --exit
+func parseStartupOptions(args []string) (*startupOptions, error) {
+	options := &startupOptions{}
+	fs := flag.NewFlagSet("mhost", flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: mhost [--apply <profile> [--exit [--force]]]")
+		fmt.Fprintln(fs.Output(), "       mhost <command> [flags]")
+		fs.PrintDefaults()
+	}
+	fs.StringVar(&options.apply, "apply", "", "apply this profile (name or ID) on startup")
+	fs.BoolVar(&options.exit, "exit", false, "with --apply, exit after applying without opening the window")
+	fs.BoolVar(&options.force, "force", false, "with --exit, apply even if the hosts file exceeds the configured size limits")
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+	if fs.NArg() > 0 {
+		return nil, fmt.Errorf("unexpected argument: %s", fs.Arg(0))
+	}
+	if options.exit && options.apply == "" {
+		return nil, errors.New("--exit requires --apply")
+	}
+	if options.force && !options.exit {
+		return nil, errors.New("--force requires --exit")
+	}
+	return options, nil
+}
+
+// runStartupApply 不打开窗口，使用与GUI相同的工作区配置和应用流程应用Profile，返回进程退出码
+func runStartupApply(options *startupOptions) int {
+	workspaces := config.NewWorkspaceManager("")
+	workspace, err := workspaces.ActiveWorkspace()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to resolve workspace: %v\n", err)
+		return 1
+	}
+	// 在终端中运行，未安装Helper时通过sudo写入hosts文件
+	managers, err := controller.OpenWorkspace(workspace, host.DefaultElevator())
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	profileManager, hostManager := managers.ProfileManager, managers.HostManager
+
+	if _, _, err := host.CaptureSharedBaseline(hostManager, workspaces); err != nil {
+		fmt.Fprintf(os.Stderr, "warning: failed to capture hosts baseline: %v\n", err)
+	}
+
+	c := controller.New(profileManager, hostManager)
+	if err := c.Load(); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to load profiles: %v\n", err)
+		return 1
+	}
+	target := c.ResolveProfile(options.apply)
+	if target == nil {
+		fmt.Fprintf(os.Stderr, "%v: %s\n", models.ErrProfileNotFound, options.apply)
+		return 1
+	}
+	c.SelectProfile(target)
+
+	feed := activity.NewFeed(activity.DefaultFeedPath(workspace.DataDir))
+	result, err := c.Apply(host.ApplyOptions{IgnoreLimits: options.force})
+	if errors.Is(err, host.ErrLimitExceeded) {
+		fmt.Fprintf(os.Stderr, "%v\nRe-run with --force to apply anyway, or raise the limits in the settings\n", err)
+		return 1
+	}
+	if err != nil && result == nil {
+		feed.Record(models.NewEvent(models.EventError, startupEventSource, map[string]interface{}{"operation": "apply", "error": err.Error()}))
+		fmt.Fprintf(os.Stderr, "failed to apply profile '%s': %v\n", target.Name, err)
+		return 1
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "profile '%s' was written but its state was not updated: %v\n", target.Name, err)
+		return 1
+	}
+
+	feed.Record(models.NewEvent(models.EventProfileActivated, startupEventSource, map[string]interface{}{
+		"profile_id":   result.ProfileID,
+		"profile_name": result.ProfileName,
+		"added":        len(result.Added),
+		"removed":      len(result.Removed),
+		"changed":      len(result.Changed),
+	}))
+	if err := host.NewHistory(host.DefaultHistoryPath(workspace.DataDir)).Record(result); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to record apply history: %v\n", err)
+	}
+
+	fmt.Printf("Applied profile '%s': %d added, %d removed, %d changed, %d unchanged\n",
+		result.ProfileName, len(result.Added), len(result.Removed), len(result.Changed), result.Unchanged)
+	if result.BackupPath != "" {
+		fmt.Printf("Backup: %s\n", result.BackupPath)
+	}
+	for _, warning := range result.Warnings {
+		fmt.Printf("Warning: %s\n", warning)
+	}
+	return 0
+}
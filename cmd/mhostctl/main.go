@@ -0,0 +1,27 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/flyhigher139/mhost/internal/command"
+)
+
+// main 程序入口点。mhostctl不启动GUI，与mhost共用同一套子命令，
+// 便于在脚本和SSH会话中应用、列出Profile并查看状态
+func main() {
+	if len(os.Args) < 2 {
+		command.PrintUsage("mhostctl")
+		os.Exit(2)
+	}
+	if !command.Has(os.Args[1]) {
+		if os.Args[1] == "help" || os.Args[1] == "-h" || os.Args[1] == "--help" {
+			command.PrintUsage("mhostctl")
+			os.Exit(0)
+		}
+		fmt.Fprintf(os.Stderr, "unknown command: %s\n", os.Args[1])
+		command.PrintUsage("mhostctl")
+		os.Exit(2)
+	}
+	os.Exit(command.Run("mhostctl", os.Args[1], os.Args[2:]))
+}
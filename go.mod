@@ -3,7 +3,15 @@ module github.com/flyhigher139/mhost
 go 1.24.6
 
 require (
-	fyne.io/fyne/v2 v2.6.3 // indirect
+	fyne.io/fyne/v2 v2.6.3
+	github.com/jmespath/go-jmespath v0.4.0
+	github.com/stretchr/testify v1.11.1
+	go.starlark.net v0.0.0-20231121155337-90ade8b19d09
+	golang.org/x/net v0.35.0
+	gopkg.in/yaml.v3 v3.0.1
+)
+
+require (
 	fyne.io/systray v1.11.0 // indirect
 	github.com/BurntSushi/toml v1.4.0 // indirect
 	github.com/davecgh/go-spew v1.1.1 // indirect
@@ -29,11 +37,8 @@ require (
 	github.com/srwiley/oksvg v0.0.0-20221011165216-be6e8873101c // indirect
 	github.com/srwiley/rasterx v0.0.0-20220730225603-2ab79fcdd4ef // indirect
 	github.com/stretchr/objx v0.5.2 // indirect
-	github.com/stretchr/testify v1.11.1 // indirect
 	github.com/yuin/goldmark v1.7.8 // indirect
 	golang.org/x/image v0.24.0 // indirect
-	golang.org/x/net v0.35.0 // indirect
 	golang.org/x/sys v0.30.0 // indirect
 	golang.org/x/text v0.22.0 // indirect
-	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
@@ -0,0 +1,184 @@
+// Package activity 按时间记录应用、编辑、备份、导入和漂移检测等操作，供最近活动面板按类别和时间筛选。
+package activity
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/flyhigher139/mhost/pkg/models"
+)
+
+// DefaultLimit 活动记录保留的最大条数
+const DefaultLimit = 500
+
+// Category 活动类别
+type Category string
+
+const (
+	CategoryAll     Category = ""        // 全部活动
+	CategoryProfile Category = "profile" // Profile的创建、修改、删除、激活和导入
+	CategoryEntry   Category = "entry"   // Host条目的修改
+	CategorySystem  Category = "system"  // hosts文件写入、备份、配置和漂移检测
+	CategoryError   Category = "error"   // 错误和警告
+)
+
+// CategoryOf 获取事件所属的类别
+func CategoryOf(event *models.Event) Category {
+	switch {
+	case event.IsProfileEvent():
+		return CategoryProfile
+	case event.IsHostEntryEvent():
+		return CategoryEntry
+	case event.IsSystemEvent():
+		return CategorySystem
+	case event.IsErrorEvent():
+		return CategoryError
+	}
+	return CategoryAll
+}
+
+// Filter 活动筛选条件，零值匹配全部活动
+type Filter struct {
+	Category Category  // 类别，为空时不限
+	Since    time.Time // 不早于该时间，零值时不限
+	Until    time.Time // 早于该时间，零值时不限
+	Query    string    // 事件类型或数据中包含的文本，不区分大小写
+}
+
+// Match 事件是否满足筛选条件
+func (f Filter) Match(event *models.Event) bool {
+	if f.Category != CategoryAll && CategoryOf(event) != f.Category {
+		return false
+	}
+	if !f.Since.IsZero() && event.Timestamp.Before(f.Since) {
+		return false
+	}
+	if !f.Until.IsZero() && !event.Timestamp.Before(f.Until) {
+		return false
+	}
+	if f.Query == "" {
+		return true
+	}
+
+	query := strings.ToLower(f.Query)
+	if strings.Contains(strings.ToLower(string(event.Type)), query) {
+		return true
+	}
+	for _, value := range event.Data {
+		if strings.Contains(strings.ToLower(fmt.Sprint(value)), query) {
+			return true
+		}
+	}
+	return false
+}
+
+// Day 获取某天（本地时间）的筛选条件
+func Day(day time.Time) Filter {
+	start := time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, day.Location())
+	return Filter{Since: start, Until: start.AddDate(0, 0, 1)}
+}
+
+// Feed 活动记录接口
+type Feed interface {
+	// Record 记录一次活动
+	Record(event *models.Event) error
+
+	// List 获取满足筛选条件的活动，最新的在前
+	List(filter Filter) ([]*models.Event, error)
+}
+
+// FeedImpl 活动记录实现，保存在JSON文件中；GUI和守护进程可以共用同一个文件
+type FeedImpl struct {
+	mu    sync.Mutex
+	path  string
+	limit int
+}
+
+// DefaultFeedPath 获取数据目录下的活动记录文件路径
+func DefaultFeedPath(dataDir string) string {
+	return filepath.Join(dataDir, "activity.json")
+}
+
+// NewFeed 创建活动记录
+func NewFeed(path string) *FeedImpl {
+	return &FeedImpl{path: path, limit: DefaultLimit}
+}
+
+// Record 记录一次活动，超过上限时丢弃最早的记录
+func (f *FeedImpl) Record(event *models.Event) error {
+	if event == nil {
+		return nil
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	events, err := f.load()
+	if err != nil {
+		return err
+	}
+
+	events = append([]*models.Event{event}, events...)
+	if len(events) > f.limit {
+		events = events[:f.limit]
+	}
+
+	data, err := json.MarshalIndent(events, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal activity feed: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(f.path), 0755); err != nil {
+		return fmt.Errorf("failed to create activity directory: %w", err)
+	}
+
+	// 先写入临时文件再替换，避免另一个进程读到写了一半的文件
+	tempPath := f.path + ".tmp"
+	if err := os.WriteFile(tempPath, data, 0644); err != nil {
+		return fmt.Errorf("failed to save activity feed: %w", err)
+	}
+	if err := os.Rename(tempPath, f.path); err != nil {
+		os.Remove(tempPath)
+		return fmt.Errorf("failed to replace activity feed: %w", err)
+	}
+	return nil
+}
+
+// List 获取满足筛选条件的活动，最新的在前
+func (f *FeedImpl) List(filter Filter) ([]*models.Event, error) {
+	f.mu.Lock()
+	events, err := f.load()
+	f.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	matched := events[:0]
+	for _, event := range events {
+		if filter.Match(event) {
+			matched = append(matched, event)
+		}
+	}
+	return matched, nil
+}
+
+// load 读取活动记录文件，文件不存在时返回空列表
+func (f *FeedImpl) load() ([]*models.Event, error) {
+	data, err := os.ReadFile(f.path)
+	if os.IsNotExist(err) {
+		return []*models.Event{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read activity feed: %w", err)
+	}
+
+	var events []*models.Event
+	if err := json.Unmarshal(data, &events); err != nil {
+		return nil, fmt.Errorf("failed to parse activity feed: %w", err)
+	}
+	return events, nil
+}
@@ -0,0 +1,74 @@
+package activity
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/flyhigher139/mhost/pkg/models"
+)
+
+// newEvent 创建指定时间的事件
+func newEvent(eventType models.EventType, at time.Time, data map[string]interface{}) *models.Event {
+	event := models.NewEvent(eventType, "test", data)
+	event.Timestamp = at
+	return event
+}
+
+// TestFeed 测试记录顺序、数量上限和文件不存在时的空列表
+func TestFeed(t *testing.T) {
+	feed := NewFeed(filepath.Join(t.TempDir(), "data", "activity.json"))
+	feed.limit = 2
+
+	events, err := feed.List(Filter{})
+	require.NoError(t, err)
+	assert.Empty(t, events)
+
+	now := time.Now()
+	require.NoError(t, feed.Record(newEvent(models.EventProfileCreated, now.Add(-2*time.Hour), nil)))
+	require.NoError(t, feed.Record(newEvent(models.EventHostEntryAdded, now.Add(-time.Hour), nil)))
+	require.NoError(t, feed.Record(newEvent(models.EventSystemBackupCreated, now, nil)))
+	require.NoError(t, feed.Record(nil))
+
+	events, err = feed.List(Filter{})
+	require.NoError(t, err)
+	require.Len(t, events, 2)
+	assert.Equal(t, models.EventSystemBackupCreated, events[0].Type)
+	assert.Equal(t, models.EventHostEntryAdded, events[1].Type)
+}
+
+// TestFilter 测试按类别、日期和文本筛选
+func TestFilter(t *testing.T) {
+	feed := NewFeed(filepath.Join(t.TempDir(), "activity.json"))
+	today := time.Now()
+	yesterday := today.AddDate(0, 0, -1)
+
+	require.NoError(t, feed.Record(newEvent(models.EventProfileImported, yesterday, map[string]interface{}{"profile": "Staging"})))
+	require.NoError(t, feed.Record(newEvent(models.EventSystemDriftDetected, yesterday, map[string]interface{}{"count": 2})))
+	require.NoError(t, feed.Record(newEvent(models.EventProfileActivated, today, map[string]interface{}{"profile": "Dev"})))
+
+	events, err := feed.List(Day(yesterday))
+	require.NoError(t, err)
+	assert.Len(t, events, 2)
+
+	events, err = feed.List(Filter{Category: CategoryProfile})
+	require.NoError(t, err)
+	assert.Len(t, events, 2)
+
+	filter := Day(yesterday)
+	filter.Category = CategorySystem
+	events, err = feed.List(filter)
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	assert.Equal(t, models.EventSystemDriftDetected, events[0].Type)
+
+	events, err = feed.List(Filter{Query: "staging"})
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	assert.Equal(t, models.EventProfileImported, events[0].Type)
+
+	assert.Equal(t, CategoryError, CategoryOf(models.NewEvent(models.EventWarning, "test", nil)))
+}
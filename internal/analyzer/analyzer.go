@@ -0,0 +1,303 @@
+// Package analyzer 分析Profile中的hosts条目，找出过期、不可达和重复的条目。
+package analyzer
+
+import (
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/flyhigher139/mhost/internal/dnsstats"
+	"github.com/flyhigher139/mhost/internal/profile"
+	"github.com/flyhigher139/mhost/pkg/hostsfile"
+	"github.com/flyhigher139/mhost/pkg/models"
+)
+
+// FindingKind 问题类型
+type FindingKind string
+
+const (
+	// FindingStale 长时间未修改且未被解析，或已超过注释中的expires日期
+	FindingStale FindingKind = "stale"
+	// FindingUnreachable IP不可达
+	FindingUnreachable FindingKind = "unreachable"
+	// FindingDuplicate 同一Profile中重复的主机名
+	FindingDuplicate FindingKind = "duplicate"
+)
+
+// Finding 分析发现的问题条目
+type Finding struct {
+	Kind        FindingKind       `json:"kind"`
+	ProfileID   string            `json:"profile_id"`
+	ProfileName string            `json:"profile_name"`
+	Entry       *models.HostEntry `json:"entry"`
+	Reason      string            `json:"reason"`
+	Owner       string            `json:"owner,omitempty"`  // 注释中的负责人
+	Ticket      string            `json:"ticket,omitempty"` // 注释中的关联工单
+}
+
+// Report 分析报告
+type Report struct {
+	GeneratedAt time.Time `json:"generated_at"`
+	Stale       []Finding `json:"stale"`
+	Unreachable []Finding `json:"unreachable"`
+	Duplicates  []Finding `json:"duplicates"`
+}
+
+// Total 问题条目总数
+func (r *Report) Total() int {
+	return len(r.Stale) + len(r.Unreachable) + len(r.Duplicates)
+}
+
+// Prober 检测IP是否可达
+type Prober func(ip string) bool
+
+// Options 分析选项
+type Options struct {
+	StaleAfter        time.Duration    // 超过该时长未修改且未被解析视为过期
+	CheckReachability bool             // 是否检测IP可达性
+	Prober            Prober           // IP可达性检测方法
+	HitCounter        dnsstats.Counter // DNS命中统计（可选）
+}
+
+// DefaultOptions 返回默认分析选项
+func DefaultOptions() Options {
+	return Options{
+		StaleAfter:        30 * 24 * time.Hour,
+		CheckReachability: false,
+		Prober:            TCPProber([]string{"80", "443"}, 2*time.Second),
+	}
+}
+
+// TCPProber 通过TCP连接检测IP可达性，任一端口可连接即视为可达
+func TCPProber(ports []string, timeout time.Duration) Prober {
+	return func(ip string) bool {
+		for _, port := range ports {
+			conn, err := net.DialTimeout("tcp", net.JoinHostPort(ip, port), timeout)
+			if err == nil {
+				conn.Close()
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// Analyzer 条目分析服务
+type Analyzer struct {
+	profileManager profile.Manager
+	options        Options
+	now            func() time.Time
+
+	mu         sync.RWMutex
+	lastReport *Report
+	stopChan   chan struct{}
+	nextRun    time.Time
+	onReport   func(*Report)
+}
+
+// NewAnalyzer 创建条目分析服务
+func NewAnalyzer(profileManager profile.Manager, options Options) *Analyzer {
+	if options.Prober == nil {
+		options.Prober = DefaultOptions().Prober
+	}
+
+	return &Analyzer{
+		profileManager: profileManager,
+		options:        options,
+		now:            time.Now,
+	}
+}
+
+// Analyze 分析所有Profile并生成报告
+func (a *Analyzer) Analyze() (*Report, error) {
+	summaries, err := a.profileManager.ListProfiles()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list profiles: %w", err)
+	}
+
+	now := a.now()
+	report := &Report{GeneratedAt: now}
+	reachability := make(map[string]bool)
+
+	for _, summary := range summaries {
+		p, err := a.profileManager.GetProfile(summary.ID)
+		if err != nil {
+			continue
+		}
+
+		seen := make(map[string]bool)
+		for _, entry := range p.Entries {
+			meta := hostsfile.ParseComment(entry.Comment)
+			newFinding := func(kind FindingKind, reason string) Finding {
+				return Finding{Kind: kind, ProfileID: p.ID, ProfileName: p.Name, Entry: entry, Reason: reason,
+					Owner: meta.Owner(), Ticket: meta.Ticket()}
+			}
+
+			// 重复条目：保留第一个，后续的视为重复
+			if seen[entry.Hostname] {
+				report.Duplicates = append(report.Duplicates, newFinding(FindingDuplicate, "duplicate hostname in profile"))
+				continue
+			}
+			seen[entry.Hostname] = true
+
+			// 注释中声明了过期日期时以其为准，未到期的条目不按修改时间判断
+			if expires, ok := meta.Expires(); ok {
+				if meta.Expired(now) {
+					report.Stale = append(report.Stale, newFinding(FindingStale, fmt.Sprintf("expired on %s", expires.Format(hostsfile.ExpiresLayout))))
+				}
+			} else if lastUsed := a.lastUsed(entry); a.options.StaleAfter > 0 && now.Sub(lastUsed) > a.options.StaleAfter {
+				days := int(now.Sub(lastUsed).Hours() / 24)
+				report.Stale = append(report.Stale, newFinding(FindingStale, fmt.Sprintf("not modified or resolved for %d days", days)))
+			}
+
+			if a.options.CheckReachability && entry.Enabled {
+				reachable, checked := reachability[entry.IP]
+				if !checked {
+					reachable = a.options.Prober(entry.IP)
+					reachability[entry.IP] = reachable
+				}
+				if !reachable {
+					report.Unreachable = append(report.Unreachable, newFinding(FindingUnreachable, "IP address does not respond"))
+				}
+			}
+		}
+	}
+
+	a.mu.Lock()
+	a.lastReport = report
+	onReport := a.onReport
+	a.mu.Unlock()
+
+	if onReport != nil {
+		onReport(report)
+	}
+
+	return report, nil
+}
+
+// lastUsed 条目最近一次修改或被解析的时间
+func (a *Analyzer) lastUsed(entry *models.HostEntry) time.Time {
+	lastUsed := entry.UpdatedAt
+	if a.options.HitCounter != nil {
+		if hit, ok := a.options.HitCounter.Get(entry.Hostname); ok && hit.LastSeen.After(lastUsed) {
+			lastUsed = hit.LastSeen
+		}
+	}
+	return lastUsed
+}
+
+// LastReport 获取最近一次的分析报告
+func (a *Analyzer) LastReport() *Report {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.lastReport
+}
+
+// OnReport 设置报告生成后的回调
+func (a *Analyzer) OnReport(callback func(*Report)) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.onReport = callback
+}
+
+// Start 按固定间隔定时运行分析
+func (a *Analyzer) Start(interval time.Duration) error {
+	if interval <= 0 {
+		return fmt.Errorf("invalid analyze interval: %v", interval)
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.stopChan != nil {
+		return fmt.Errorf("analyzer already started")
+	}
+	a.stopChan = make(chan struct{})
+	a.nextRun = a.now().Add(interval)
+
+	go func(stop chan struct{}) {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				a.scheduleNext(interval)
+				a.Analyze()
+			}
+		}
+	}(a.stopChan)
+
+	return nil
+}
+
+// NextRun 下一次定时分析的时间，未启动时返回false
+func (a *Analyzer) NextRun() (time.Time, bool) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.nextRun, !a.nextRun.IsZero()
+}
+
+// scheduleNext 记录下一次定时分析的时间
+func (a *Analyzer) scheduleNext(interval time.Duration) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.stopChan != nil {
+		a.nextRun = a.now().Add(interval)
+	}
+}
+
+// Stop 停止定时分析
+func (a *Analyzer) Stop() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.stopChan != nil {
+		close(a.stopChan)
+		a.stopChan = nil
+		a.nextRun = time.Time{}
+	}
+}
+
+// Cleanup 从各自的Profile中移除发现的问题条目，返回移除的条目数；
+// 同步资产清单的只读Profile中的条目由同步维护，不会被移除
+func (a *Analyzer) Cleanup(findings []Finding) (int, error) {
+	byProfile := make(map[string][]string)
+	for _, finding := range findings {
+		if finding.Entry == nil {
+			continue
+		}
+		byProfile[finding.ProfileID] = append(byProfile[finding.ProfileID], finding.Entry.ID)
+	}
+
+	removed := 0
+	for profileID, entryIDs := range byProfile {
+		p, err := a.profileManager.GetProfile(profileID)
+		if err != nil {
+			return removed, err
+		}
+		if p.ReadOnly() {
+			continue
+		}
+
+		count := 0
+		for _, entryID := range entryIDs {
+			if p.RemoveEntry(entryID) {
+				count++
+			}
+		}
+		if count == 0 {
+			continue
+		}
+
+		if err := a.profileManager.UpdateProfile(p); err != nil {
+			return removed, err
+		}
+		removed += count
+	}
+
+	return removed, nil
+}
@@ -0,0 +1,98 @@
+package analyzer
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/flyhigher139/mhost/internal/dnsstats"
+	"github.com/flyhigher139/mhost/internal/fakes"
+	"github.com/flyhigher139/mhost/pkg/models"
+)
+
+// TestAnalyzeAndCleanup 测试生成分析报告并清理问题条目
+func TestAnalyzeAndCleanup(t *testing.T) {
+	pm := fakes.NewProfileManager(nil)
+
+	p, err := pm.CreateProfile("Dev", "")
+	require.NoError(t, err)
+
+	now := time.Now()
+	old := models.NewHostEntry("10.0.0.1", "old.test", "")
+	old.UpdatedAt = now.Add(-60 * 24 * time.Hour)
+	resolved := models.NewHostEntry("10.0.0.2", "resolved.test", "")
+	resolved.UpdatedAt = now.Add(-60 * 24 * time.Hour)
+	down := models.NewHostEntry("10.0.0.3", "down.test", "")
+	// 启用的重复主机名无法保存，重复的条目作为禁用的备用地址
+	dup := models.NewHostEntry("10.0.0.4", "down.test", "")
+	dup.Enabled = false
+	p.AddEntry(old)
+	p.AddEntry(resolved)
+	p.AddEntry(down)
+	p.AddEntry(dup)
+	require.NoError(t, pm.UpdateProfile(p))
+
+	counter := dnsstats.NewCounter()
+	counter.Record("resolved.test", now.Add(-time.Hour))
+
+	a := NewAnalyzer(pm, Options{
+		StaleAfter:        30 * 24 * time.Hour,
+		CheckReachability: true,
+		Prober:            func(ip string) bool { return ip != "10.0.0.3" },
+		HitCounter:        counter,
+	})
+
+	var callbackReport *Report
+	a.OnReport(func(r *Report) { callbackReport = r })
+
+	report, err := a.Analyze()
+	require.NoError(t, err)
+	assert.Same(t, report, a.LastReport())
+	assert.Same(t, report, callbackReport)
+	assert.Equal(t, 3, report.Total())
+
+	require.Len(t, report.Stale, 1)
+	assert.Equal(t, "old.test", report.Stale[0].Entry.Hostname)
+	require.Len(t, report.Unreachable, 1)
+	assert.Equal(t, down.ID, report.Unreachable[0].Entry.ID)
+	require.Len(t, report.Duplicates, 1)
+	assert.Equal(t, dup.ID, report.Duplicates[0].Entry.ID)
+
+	removed, err := a.Cleanup(append(report.Stale, report.Duplicates...))
+	require.NoError(t, err)
+	assert.Equal(t, 2, removed)
+
+	updated, err := pm.GetProfile(p.ID)
+	require.NoError(t, err)
+	assert.Len(t, updated.Entries, 2)
+}
+
+// TestAnalyzeExpires 测试注释中的过期日期优先于修改时间
+func TestAnalyzeExpires(t *testing.T) {
+	pm := fakes.NewProfileManager(nil)
+
+	p, err := pm.CreateProfile("Dev", "")
+	require.NoError(t, err)
+
+	now := time.Date(2025, 7, 2, 9, 0, 0, 0, time.Local)
+	expired := models.NewHostEntry("10.0.0.1", "expired.test", "migration owner=alice ticket=OPS-123 expires=2025-07-01")
+	expired.UpdatedAt = now
+	pinned := models.NewHostEntry("10.0.0.2", "pinned.test", "owner=bob expires=2025-07-02")
+	pinned.UpdatedAt = now.AddDate(0, -6, 0)
+	p.AddEntry(expired)
+	p.AddEntry(pinned)
+	require.NoError(t, pm.UpdateProfile(p))
+
+	a := NewAnalyzer(pm, Options{StaleAfter: 30 * 24 * time.Hour})
+	a.now = func() time.Time { return now }
+
+	report, err := a.Analyze()
+	require.NoError(t, err)
+	require.Len(t, report.Stale, 1)
+	assert.Equal(t, expired.ID, report.Stale[0].Entry.ID)
+	assert.Equal(t, "expired on 2025-07-01", report.Stale[0].Reason)
+	assert.Equal(t, "alice", report.Stale[0].Owner)
+	assert.Equal(t, "OPS-123", report.Stale[0].Ticket)
+}
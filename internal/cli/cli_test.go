@@ -0,0 +1,117 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+
+	"github.com/flyhigher139/mhost/pkg/hostsfile"
+	"github.com/flyhigher139/mhost/pkg/models"
+)
+
+// TestParseFormat 测试输出格式解析
+func TestParseFormat(t *testing.T) {
+	cases := map[string]Format{
+		"":     FormatText,
+		"text": FormatText,
+		"JSON": FormatJSON,
+		"yaml": FormatYAML,
+		"yml":  FormatYAML,
+	}
+	for input, expected := range cases {
+		format, err := ParseFormat(input)
+		require.NoError(t, err, input)
+		assert.Equal(t, expected, format, input)
+	}
+
+	_, err := ParseFormat("xml")
+	assert.Error(t, err)
+
+	assert.False(t, FormatText.IsMachineReadable())
+	assert.True(t, FormatJSON.IsMachineReadable())
+	assert.True(t, FormatYAML.IsMachineReadable())
+}
+
+// TestEncodeProfile 测试JSON与YAML输出结构一致
+func TestEncodeProfile(t *testing.T) {
+	profile := models.NewProfile("dev", "development")
+	profile.IsActive = true
+	profile.Tags = nil
+	profile.Entries = append(profile.Entries, models.NewHostEntry("10.0.0.1", "api.test", "staging"))
+
+	doc := NewDocument(KindProfile, NewProfile(profile))
+
+	var jsonOut bytes.Buffer
+	require.NoError(t, Encode(&jsonOut, FormatJSON, doc))
+
+	var fromJSON map[string]interface{}
+	require.NoError(t, json.Unmarshal(jsonOut.Bytes(), &fromJSON))
+	assert.Equal(t, SchemaVersion, fromJSON["schema_version"])
+	assert.Equal(t, "profile", fromJSON["kind"])
+
+	data := fromJSON["data"].(map[string]interface{})
+	assert.Equal(t, "dev", data["name"])
+	assert.Equal(t, true, data["active"])
+	assert.Equal(t, []interface{}{}, data["tags"])
+	entries := data["entries"].([]interface{})
+	require.Len(t, entries, 1)
+	assert.Equal(t, "api.test", entries[0].(map[string]interface{})["hostname"])
+
+	var yamlOut bytes.Buffer
+	require.NoError(t, Encode(&yamlOut, FormatYAML, doc))
+	assert.Contains(t, yamlOut.String(), "schema_version: \"1\"\n")
+	assert.Contains(t, yamlOut.String(), "kind: profile\n")
+
+	var fromYAML map[string]interface{}
+	require.NoError(t, yaml.Unmarshal(yamlOut.Bytes(), &fromYAML))
+	yamlData := fromYAML["data"].(map[string]interface{})
+	assert.Equal(t, "dev", yamlData["name"])
+	assert.Equal(t, SchemaVersion, fromYAML["schema_version"])
+	assert.Len(t, yamlData["entries"], 1)
+
+	assert.Error(t, Encode(&bytes.Buffer{}, FormatText, doc))
+}
+
+// TestNewDiff 测试差异输出结构
+func TestNewDiff(t *testing.T) {
+	empty := NewDiff(nil)
+	assert.False(t, empty.HasChanges)
+	assert.NotNil(t, empty.Added)
+	assert.NotNil(t, empty.Changed)
+	assert.NotNil(t, empty.Removed)
+
+	drift := hostsfile.DetectDrift(
+		[]hostsfile.Entry{
+			{IP: "10.0.0.1", Hostname: "api.test", Enabled: true},
+			{IP: "10.0.0.2", Hostname: "web.test", Enabled: true},
+		},
+		[]hostsfile.Entry{
+			{IP: "10.0.0.9", Hostname: "api.test", Enabled: true},
+			{IP: "10.0.0.3", Hostname: "new.test", Enabled: true},
+		},
+	)
+
+	diff := NewDiff(drift)
+	assert.True(t, diff.HasChanges)
+	require.Len(t, diff.Added, 1)
+	assert.Equal(t, "new.test", diff.Added[0].Hostname)
+	require.Len(t, diff.Changed, 1)
+	assert.Equal(t, "api.test", diff.Changed[0].Hostname)
+	assert.Equal(t, "10.0.0.9", diff.Changed[0].Actual.IP)
+	require.Len(t, diff.Removed, 1)
+	assert.Equal(t, "web.test", diff.Removed[0].Hostname)
+}
+
+// TestNewConfigCheck 测试配置检查结果
+func TestNewConfigCheck(t *testing.T) {
+	assert.Equal(t, &ConfigCheck{Valid: true, Errors: []string{}}, NewConfigCheck(nil))
+
+	check := NewConfigCheck([]error{errors.New("bad path")})
+	assert.False(t, check.Valid)
+	assert.Equal(t, []string{"bad path"}, check.Errors)
+}
@@ -0,0 +1,93 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Format 命令行输出格式
+type Format string
+
+const (
+	// FormatText 面向人的文本输出
+	FormatText Format = "text"
+	// FormatJSON JSON输出
+	FormatJSON Format = "json"
+	// FormatYAML YAML输出
+	FormatYAML Format = "yaml"
+)
+
+// Formats 返回支持的输出格式
+func Formats() []Format {
+	return []Format{FormatText, FormatJSON, FormatYAML}
+}
+
+// ParseFormat 解析输出格式，空字符串视为文本输出
+func ParseFormat(value string) (Format, error) {
+	switch Format(strings.ToLower(strings.TrimSpace(value))) {
+	case "", FormatText:
+		return FormatText, nil
+	case FormatJSON:
+		return FormatJSON, nil
+	case FormatYAML, "yml":
+		return FormatYAML, nil
+	}
+	return "", fmt.Errorf("unsupported output format: %q (expected text, json or yaml)", value)
+}
+
+// IsMachineReadable 是否为机器可读格式
+func (f Format) IsMachineReadable() bool {
+	return f == FormatJSON || f == FormatYAML
+}
+
+// Encode 以指定格式输出文档，YAML与JSON使用相同的字段名和字段顺序
+func Encode(w io.Writer, format Format, doc *Document) error {
+	data, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal output: %w", err)
+	}
+
+	switch format {
+	case FormatJSON:
+		_, err = fmt.Fprintln(w, string(data))
+		return err
+	case FormatYAML:
+		return encodeYAML(w, data)
+	}
+	return fmt.Errorf("format %q is not machine readable", format)
+}
+
+// encodeYAML 将JSON文本转换为YAML输出，保留JSON中的键顺序
+func encodeYAML(w io.Writer, data []byte) error {
+	var node yaml.Node
+	if err := yaml.Unmarshal(data, &node); err != nil {
+		return fmt.Errorf("failed to convert output to yaml: %w", err)
+	}
+	resetStyle(&node)
+
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&node); err != nil {
+		return fmt.Errorf("failed to encode yaml: %w", err)
+	}
+	if err := encoder.Close(); err != nil {
+		return fmt.Errorf("failed to encode yaml: %w", err)
+	}
+
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// resetStyle 清除JSON解析得到的flow与引号风格，输出块风格YAML；标量标签保留，必要时由编码器加引号
+func resetStyle(node *yaml.Node) {
+	node.Style = 0
+	for _, child := range node.Content {
+		resetStyle(child)
+	}
+}
@@ -0,0 +1,334 @@
+package cli
+
+import (
+	"time"
+
+	apperrors "github.com/flyhigher139/mhost/pkg/errors"
+	"github.com/flyhigher139/mhost/pkg/hostsfile"
+	"github.com/flyhigher139/mhost/pkg/models"
+)
+
+// SchemaVersion 机器可读输出的结构版本，字段发生不兼容变化时递增
+const SchemaVersion = "1"
+
+// Kind 输出文档类型
+type Kind string
+
+const (
+	// KindVersion 版本信息
+	KindVersion Kind = "version"
+	// KindConfigCheck 配置检查结果
+	KindConfigCheck Kind = "config_check"
+	// KindHelperStatus Helper状态
+	KindHelperStatus Kind = "helper_status"
+	// KindProfile 单个Profile
+	KindProfile Kind = "profile"
+	// KindProfileList Profile列表
+	KindProfileList Kind = "profile_list"
+	// KindEntryList 条目列表
+	KindEntryList Kind = "entry_list"
+	// KindApplyResult 应用结果
+	KindApplyResult Kind = "apply_result"
+	// KindDiff 差异
+	KindDiff Kind = "diff"
+	// KindEvent 事件
+	KindEvent Kind = "event"
+	// KindBackup 单个备份
+	KindBackup Kind = "backup"
+	// KindBackupList 备份列表
+	KindBackupList Kind = "backup_list"
+	// KindSwitchResult 快速切换结果
+	KindSwitchResult Kind = "switch_result"
+	// KindHelperSignature Helper签名校验结果
+	KindHelperSignature Kind = "helper_signature"
+	// KindWorkspaceList 工作区列表
+	KindWorkspaceList Kind = "workspace_list"
+	// KindValidationResult hosts文件验证结果
+	KindValidationResult Kind = "validation_result"
+	// KindHostsStatus 不经守护进程读取的hosts文件与Profile状态
+	KindHostsStatus Kind = "hosts_status"
+	// KindDoctorReport 诊断检查结果
+	KindDoctorReport Kind = "doctor_report"
+	// KindError 错误
+	KindError Kind = "error"
+)
+
+// Document 机器可读输出的统一外层结构
+type Document struct {
+	SchemaVersion string      `json:"schema_version"`
+	Kind          Kind        `json:"kind"`
+	Data          interface{} `json:"data"`
+}
+
+// NewDocument 创建输出文档
+func NewDocument(kind Kind, data interface{}) *Document {
+	return &Document{
+		SchemaVersion: SchemaVersion,
+		Kind:          kind,
+		Data:          data,
+	}
+}
+
+// VersionInfo 版本信息
+type VersionInfo struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+// ConfigCheck 配置检查结果
+type ConfigCheck struct {
+	Valid  bool     `json:"valid"`
+	Errors []string `json:"errors"`
+}
+
+// NewConfigCheck 根据验证错误创建配置检查结果
+func NewConfigCheck(errs []error) *ConfigCheck {
+	check := &ConfigCheck{Valid: len(errs) == 0, Errors: make([]string, 0, len(errs))}
+	for _, err := range errs {
+		check.Errors = append(check.Errors, err.Error())
+	}
+	return check
+}
+
+// Entry hosts条目输出结构
+type Entry struct {
+	ID       string            `json:"id"`
+	IP       string            `json:"ip"`
+	Hostname string            `json:"hostname"`
+	Comment  string            `json:"comment"`
+	Enabled  bool              `json:"enabled"`
+	Metadata map[string]string `json:"metadata,omitempty"` // 注释中的结构化字段，例如owner、ticket、expires
+	Variants map[string]string `json:"variants,omitempty"` // 按环境区分的目标IP
+}
+
+// NewEntry 从HostEntry创建输出结构
+func NewEntry(entry *models.HostEntry) Entry {
+	return Entry{
+		ID:       entry.ID,
+		IP:       entry.IP,
+		Hostname: entry.Hostname,
+		Comment:  entry.Comment,
+		Enabled:  entry.Enabled,
+		Metadata: hostsfile.ParseComment(entry.Comment).Fields,
+		Variants: entry.Variants,
+	}
+}
+
+// NewEntries 从HostEntry列表创建输出结构，忽略nil条目
+func NewEntries(entries []*models.HostEntry) []Entry {
+	result := make([]Entry, 0, len(entries))
+	for _, entry := range entries {
+		if entry != nil {
+			result = append(result, NewEntry(entry))
+		}
+	}
+	return result
+}
+
+// Profile Profile输出结构
+type Profile struct {
+	ID          string    `json:"id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	Active      bool      `json:"active"`
+	Tags        []string  `json:"tags"`
+	UpdatedAt   time.Time `json:"updated_at"`
+	Entries     []Entry   `json:"entries"`
+	Environment string    `json:"environment,omitempty"` // 应用时选用的环境
+	Folder      string    `json:"folder,omitempty"`      // 所在文件夹
+}
+
+// NewProfile 从Profile创建输出结构
+func NewProfile(profile *models.Profile) Profile {
+	tags := profile.Tags
+	if tags == nil {
+		tags = []string{}
+	}
+	return Profile{
+		ID:          profile.ID,
+		Name:        profile.Name,
+		Description: profile.Description,
+		Active:      profile.IsActive,
+		Tags:        tags,
+		UpdatedAt:   profile.UpdatedAt,
+		Entries:     NewEntries(profile.Entries),
+		Environment: profile.Environment,
+		Folder:      profile.Folder,
+	}
+}
+
+// NewProfiles 从Profile列表创建输出结构
+func NewProfiles(profiles []*models.Profile) []Profile {
+	result := make([]Profile, 0, len(profiles))
+	for _, profile := range profiles {
+		if profile != nil {
+			result = append(result, NewProfile(profile))
+		}
+	}
+	return result
+}
+
+// ApplyResult 应用Profile的结果
+type ApplyResult struct {
+	ProfileID   string    `json:"profile_id"`
+	ProfileName string    `json:"profile_name"`
+	HostsPath   string    `json:"hosts_path"`
+	Changed     bool      `json:"changed"`
+	Entries     int       `json:"entries"`
+	AppliedAt   time.Time `json:"applied_at"`
+	Diff        Diff      `json:"diff"`
+
+	LinesWritten int      `json:"lines_written,omitempty"`
+	BackupPath   string   `json:"backup_path,omitempty"`
+	Warnings     []string `json:"warnings,omitempty"`
+	DurationMS   int64    `json:"duration_ms,omitempty"`
+}
+
+// ValidationResult hosts文件验证结果，Issues包含全部错误与警告
+type ValidationResult struct {
+	HostsPath string            `json:"hosts_path"`
+	Valid     bool              `json:"valid"`
+	Lines     int               `json:"lines"`
+	Entries   int               `json:"entries"`
+	Errors    int               `json:"errors"`
+	Warnings  int               `json:"warnings"`
+	Issues    []hostsfile.Issue `json:"issues"`
+}
+
+// HostsStatus 直接读取的hosts文件与Profile状态，Diff为激活Profile与hosts文件之间的差异
+type HostsStatus struct {
+	Workspace         string `json:"workspace"`
+	HostsPath         string `json:"hosts_path"`
+	Profiles          int    `json:"profiles"`
+	ActiveProfileID   string `json:"active_profile_id,omitempty"`
+	ActiveProfileName string `json:"active_profile_name,omitempty"`
+	ManagedSection    bool   `json:"managed_section"`
+	// Section 管理section头部记录的信息，没有管理section时为nil
+	Section *SectionInfo `json:"section,omitempty"`
+	Diff    Diff         `json:"diff"`
+	// Daemon 运行中的守护进程，未运行时为nil
+	Daemon *DaemonInfo `json:"daemon,omitempty"`
+}
+
+// DaemonInfo 运行中的守护进程
+type DaemonInfo struct {
+	PID        int       `json:"pid"`
+	StartTime  time.Time `json:"start_time"`
+	SocketPath string    `json:"socket_path"`
+}
+
+// SectionInfo 管理section头部记录的写入信息
+type SectionInfo struct {
+	ProfileName string    `json:"profile_name,omitempty"`
+	ProfileID   string    `json:"profile_id,omitempty"`
+	Author      string    `json:"author,omitempty"`
+	AppliedAt   time.Time `json:"applied_at,omitempty"`
+	Version     string    `json:"version,omitempty"`
+	// State 内容与头部校验和的比较结果：intact、modified或unverified
+	State string `json:"state"`
+}
+
+// NewSectionInfo 从管理section内部的行解析头部信息
+func NewSectionInfo(managed []string) *SectionInfo {
+	header, body := hostsfile.ParseSectionHeader(managed)
+	return &SectionInfo{
+		ProfileName: header.Profile,
+		ProfileID:   header.ProfileID,
+		Author:      header.Author,
+		AppliedAt:   header.AppliedAt,
+		Version:     header.Version,
+		State:       header.Check(body).String(),
+	}
+}
+
+// SwitchResult 快速切换Profile的结果
+type SwitchResult struct {
+	Query      string      `json:"query"`
+	Apply      ApplyResult `json:"apply"`
+	DurationMS int64       `json:"duration_ms"`
+}
+
+// ErrorInfo 机器可读的错误输出
+type ErrorInfo struct {
+	Message string                 `json:"message"`
+	Code    string                 `json:"code,omitempty"`
+	Details map[string]interface{} `json:"details,omitempty"`
+}
+
+// NewErrorInfo 转换错误，AppError附带错误代码与详情
+func NewErrorInfo(err error) *ErrorInfo {
+	info := &ErrorInfo{Message: err.Error()}
+	if appErr := apperrors.GetAppError(err); appErr != nil {
+		info.Code = appErr.Code()
+		info.Details = appErr.Details()
+	}
+	return info
+}
+
+// EntryChange 条目变化输出结构
+type EntryChange struct {
+	Hostname string `json:"hostname"`
+	Expected Entry  `json:"expected"`
+	Actual   Entry  `json:"actual"`
+}
+
+// Diff Profile与hosts文件管理section之间的差异
+type Diff struct {
+	HasChanges bool          `json:"has_changes"`
+	Added      []Entry       `json:"added"`
+	Changed    []EntryChange `json:"changed"`
+	Removed    []Entry       `json:"removed"`
+	// Modified 管理section在写入后被修改（与头部的校验和不一致）
+	Modified bool `json:"modified,omitempty"`
+}
+
+// NewDiff 从hostsfile.Drift创建差异输出结构，nil表示无差异
+func NewDiff(drift *hostsfile.Drift) Diff {
+	diff := Diff{
+		Added:   []Entry{},
+		Changed: []EntryChange{},
+		Removed: []Entry{},
+	}
+	if drift == nil {
+		return diff
+	}
+
+	diff.HasChanges = drift.HasDrift()
+	diff.Modified = drift.Modified
+	for _, entry := range drift.Added {
+		diff.Added = append(diff.Added, fromHostsEntry(entry))
+	}
+	for _, change := range drift.Changed {
+		diff.Changed = append(diff.Changed, EntryChange{
+			Hostname: change.Expected.Hostname,
+			Expected: fromHostsEntry(change.Expected),
+			Actual:   fromHostsEntry(change.Actual),
+		})
+	}
+	for _, entry := range drift.Removed {
+		diff.Removed = append(diff.Removed, fromHostsEntry(entry))
+	}
+	return diff
+}
+
+// fromHostsEntry 从hostsfile.Entry创建输出结构
+func fromHostsEntry(entry hostsfile.Entry) Entry {
+	return Entry{
+		IP:       entry.IP,
+		Hostname: entry.Hostname,
+		Comment:  entry.Comment,
+		Enabled:  entry.Enabled,
+	}
+}
+
+// Backup hosts文件备份输出结构
+type Backup struct {
+	Name      string    `json:"name"`
+	Path      string    `json:"path"`
+	Size      int64     `json:"size"`
+	CreatedAt time.Time `json:"created_at"`
+	Automatic bool      `json:"automatic"`
+	Delta     bool      `json:"delta,omitempty"`     // 是否为增量备份
+	Milestone string    `json:"milestone,omitempty"` // 里程碑名称，里程碑备份不会被自动清理
+	Tags      []string  `json:"tags,omitempty"`
+}
@@ -0,0 +1,302 @@
+// Package command 实现mhost和mhostctl共用的命令行子命令。两个程序的子命令、参数和输出完全相同：
+// 文本输出面向人，--output json|yaml输出internal/cli定义的稳定文档结构，便于脚本和配置管理工具使用
+package command
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/flyhigher139/mhost/internal/cli"
+	"github.com/flyhigher139/mhost/internal/config"
+	"github.com/flyhigher139/mhost/internal/controller"
+	"github.com/flyhigher139/mhost/internal/daemon"
+	"github.com/flyhigher139/mhost/internal/host"
+	"github.com/flyhigher139/mhost/internal/profile"
+	"github.com/flyhigher139/mhost/pkg/models"
+)
+
+// command 子命令
+type command struct {
+	usage   string
+	summary string
+	flags   func(fs *flag.FlagSet, ctx *commandContext)
+	run     func(ctx *commandContext) int
+	// standalone 不需要工作区的子命令
+	standalone bool
+}
+
+// commandContext 子命令运行上下文
+type commandContext struct {
+	program    string
+	usage      string
+	args       []string
+	socketPath string
+	output     cli.Format
+	// plain 文本输出不带表头、以制表符分隔，便于cut、awk等工具处理
+	plain      bool
+	workspaces config.WorkspaceManager
+	workspace  *config.Workspace
+
+	// 直接读写Profile和hosts文件的子命令通过openManagers创建
+	profileManager profile.Manager
+	hostManager    host.Manager
+
+	// list子命令选项
+	names bool
+
+	// apply子命令选项
+	force bool
+
+	// import子命令选项
+	name   string
+	report string
+	plugin string
+
+	// daemon子命令选项
+	webAddr       string
+	token         string
+	enforce       bool
+	watchInterval time.Duration
+
+	// Helper签名校验选项
+	identifier string
+	teamID     string
+}
+
+// commands 支持的子命令
+var commands = map[string]command{
+	"apply":  {usage: "apply <profile>", summary: "activate a profile and write it to the hosts file", flags: applyFlags, run: runApply},
+	"list":   {usage: "list", summary: "list profiles", flags: listFlags, run: runList},
+	"status": {usage: "status", summary: "show the active profile, hosts file drift and the daemon", run: runStatus},
+	"diff":   {usage: "diff", summary: "show the differences between the active profile and the hosts file", run: runDiff},
+
+	"backups": {usage: "backups", summary: "list hosts file backups", run: runBackups},
+	"import":  {usage: "import <file>", summary: "import a hosts file, or any format supported by a plugin, as a new profile", flags: importFlags, run: runImport},
+	"adopt":   {usage: "adopt", summary: "take over the managed section written by another mHost install", run: runAdopt},
+
+	"daemon": {usage: "daemon", summary: "run the daemon that serves quick switches and optionally keeps the active profile applied", flags: daemonFlags, run: runDaemon},
+	"switch": {usage: "switch <query>", summary: "fuzzy-match and apply a profile through the daemon", run: runSwitch},
+	"events": {usage: "events [type...]", summary: "stream daemon events", run: runEvents},
+
+	"validate": {usage: "validate [hosts-file]", summary: "validate a hosts file", run: runValidate},
+	"doctor":   {usage: "doctor", summary: "check the helper, permissions, data directory and configuration", run: runDoctor},
+
+	"verify-helper":    {usage: "verify-helper [path]", summary: "verify the code signature of the privileged helper", flags: signatureFlags, run: runVerifyHelper},
+	"install-helper":   {usage: "install-helper <path>", summary: "verify and install the privileged helper", run: runInstallHelper},
+	"uninstall-helper": {usage: "uninstall-helper", summary: "remove the installed privileged helper", run: runUninstallHelper},
+
+	"workspace": {usage: "workspace [list | create <name> | use <name> | delete <name>]", summary: "list, create, switch or delete workspaces", run: runWorkspace},
+}
+
+// Has 是否为支持的子命令
+func Has(name string) bool {
+	_, ok := commands[name]
+	return ok
+}
+
+// PrintUsage 输出全部子命令的用法
+func PrintUsage(program string) {
+	fmt.Fprintf(os.Stderr, "Usage: %s <command> [flags] [args]\n", program)
+	fmt.Fprintln(os.Stderr, "\nCommands:")
+	for _, name := range commandNames() {
+		fmt.Fprintf(os.Stderr, "  %-16s %s\n", name, commands[name].summary)
+	}
+	fmt.Fprintf(os.Stderr, "\nRun `%s <command> -h` for the flags of a command.\n", program)
+}
+
+// commandNames 按字母顺序排列的子命令名称
+func commandNames() []string {
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// globalOptions 所有子命令共用的参数
+type globalOptions struct {
+	workspace string
+	output    string
+	format    string
+	json      bool
+}
+
+// outputFormat 解析输出格式。--format和--json是--output的别名，--format另外接受table（即text）和plain；
+// 同时指定时--json优先，其次是--format。返回的bool表示是否为plain格式
+func (o *globalOptions) outputFormat() (cli.Format, bool, error) {
+	value := o.output
+	if o.format != "" {
+		value = o.format
+	}
+	if o.json {
+		value = string(cli.FormatJSON)
+	}
+
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "table":
+		return cli.FormatText, false, nil
+	case "plain":
+		return cli.FormatText, true, nil
+	}
+	format, err := cli.ParseFormat(value)
+	return format, false, err
+}
+
+// newFlagSet 创建子命令的参数集合，包含共用参数和子命令自己的参数
+func newFlagSet(program, name string, cmd command, ctx *commandContext, options *globalOptions) *flag.FlagSet {
+	fs := flag.NewFlagSet(program+" "+name, flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: %s %s [flags]\n", program, cmd.usage)
+		fs.PrintDefaults()
+	}
+	fs.StringVar(&ctx.socketPath, "socket", "", "daemon control socket path (defaults to the workspace socket)")
+	fs.StringVar(&options.workspace, "workspace", "", "workspace to use (defaults to $"+config.WorkspaceEnv+" or the active workspace)")
+	fs.StringVar(&options.output, "output", string(cli.FormatText), "output format (text, json, yaml)")
+	fs.StringVar(&options.format, "format", "", "alias for --output that also accepts table and plain (tab-separated rows without a header)")
+	fs.BoolVar(&options.json, "json", false, "shorthand for --output json")
+	if cmd.flags != nil {
+		cmd.flags(fs, ctx)
+	}
+	return fs
+}
+
+// Run 解析参数并执行子命令，返回进程退出码；program为输出用法时显示的程序名
+func Run(program, name string, args []string) int {
+	cmd := commands[name]
+
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to get user home directory: %v\n", err)
+		return 1
+	}
+	ctx := &commandContext{
+		program:    program,
+		usage:      cmd.usage,
+		workspaces: config.NewWorkspaceManager(filepath.Join(homeDir, ".mhost")),
+	}
+
+	var options globalOptions
+	fs := newFlagSet(program, name, cmd, ctx, &options)
+	if err := fs.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return 0
+		}
+		return 2
+	}
+	ctx.args = fs.Args()
+
+	ctx.output, ctx.plain, err = options.outputFormat()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	if cmd.standalone {
+		return cmd.run(ctx)
+	}
+
+	workspace, err := ctx.workspaces.ActiveWorkspace()
+	if options.workspace != "" {
+		workspace, err = ctx.workspaces.GetWorkspace(options.workspace)
+	}
+	if err != nil {
+		return writeError(ctx, fmt.Errorf("failed to resolve workspace: %w", err))
+	}
+	ctx.workspace = workspace
+	if ctx.socketPath == "" {
+		ctx.socketPath = daemon.DefaultSocketPath(workspace.DataDir)
+	}
+
+	return cmd.run(ctx)
+}
+
+// openManagers 创建直接读写工作区Profile和hosts文件的管理器，与GUI使用相同的工作区配置，配置无法加载时返回错误
+func (ctx *commandContext) openManagers() error {
+	// 未安装Helper时，在终端中通过sudo写入hosts文件
+	managers, err := controller.OpenWorkspace(ctx.workspace, host.DefaultElevator())
+	if err != nil {
+		return err
+	}
+	ctx.profileManager = managers.ProfileManager
+	ctx.hostManager = managers.HostManager
+	return nil
+}
+
+// captureBaseline 保存系统初始hosts文件，失败时只输出警告，不影响子命令继续执行
+func (ctx *commandContext) captureBaseline() {
+	if _, _, err := host.CaptureSharedBaseline(ctx.hostManager, ctx.workspaces); err != nil {
+		fmt.Fprintf(os.Stderr, "warning: failed to capture hosts baseline: %v\n", err)
+	}
+}
+
+// withClient 连接守护进程并执行操作
+func withClient(ctx *commandContext, fn func(context.Context, *daemon.Client) error) int {
+	client := daemon.NewClient(ctx.socketPath)
+	if !client.IsRunning() {
+		return writeError(ctx, fmt.Errorf("mHost daemon is not running on %s (start it with `%s daemon`)", ctx.socketPath, ctx.program))
+	}
+
+	c, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	if err := fn(c, client); err != nil {
+		return writeError(ctx, err)
+	}
+	return 0
+}
+
+// writeError 输出错误，机器可读格式输出包含错误代码与详情的文档
+func writeError(ctx *commandContext, err error) int {
+	if ctx.output.IsMachineReadable() {
+		cli.Encode(os.Stdout, ctx.output, cli.NewDocument(cli.KindError, cli.NewErrorInfo(err)))
+		return 1
+	}
+
+	fmt.Fprintln(os.Stderr, err)
+	details := cli.NewErrorInfo(err).Details
+	keys := make([]string, 0, len(details))
+	for key := range details {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		fmt.Fprintf(os.Stderr, "  %s: %v\n", key, details[key])
+	}
+	return 1
+}
+
+// usageError 输出子命令用法，返回参数错误的退出码
+func usageError(ctx *commandContext) int {
+	fmt.Fprintf(os.Stderr, "Usage: %s %s\n", ctx.program, ctx.usage)
+	return 2
+}
+
+// joinArgs 将剩余参数拼接为Profile名称，允许名称中包含空格而不加引号
+func joinArgs(args []string) string {
+	return strings.TrimSpace(strings.Join(args, " "))
+}
+
+// yesNo 将布尔值格式化为表格中的yes或no
+func yesNo(value bool) string {
+	if value {
+		return "yes"
+	}
+	return "no"
+}
+
+// countEnabled 统计启用的条目数
+func countEnabled(entries []*models.HostEntry) int {
+	count := 0
+	for _, entry := range entries {
+		if entry != nil && entry.Enabled {
+			count++
+		}
+	}
+	return count
+}
@@ -0,0 +1,92 @@
+package command
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/flyhigher139/mhost/internal/cli"
+)
+
+// TestCommonFlags 测试每个子命令都支持--output及其别名--format、--json，以及--workspace和--socket
+func TestCommonFlags(t *testing.T) {
+	for _, name := range commandNames() {
+		fs := newFlagSet("mhostctl", name, commands[name], &commandContext{}, &globalOptions{})
+		for _, common := range []string{"output", "format", "json", "workspace", "socket"} {
+			assert.NotNil(t, fs.Lookup(common), "%s --%s", name, common)
+		}
+	}
+
+	var options globalOptions
+	ctx := &commandContext{}
+	fs := newFlagSet("mhost", "daemon", commands["daemon"], ctx, &options)
+	fs.SetOutput(&strings.Builder{})
+	require.NoError(t, fs.Parse([]string{"--output", "yaml", "--enforce", "--web", "127.0.0.1:7878"}))
+	assert.Equal(t, "yaml", options.output)
+	assert.True(t, ctx.enforce)
+	assert.Equal(t, "127.0.0.1:7878", ctx.webAddr)
+}
+
+// TestOutputFormat 测试--format和--json映射到--output的输出格式
+func TestOutputFormat(t *testing.T) {
+	tests := []struct {
+		args   []string
+		format cli.Format
+		plain  bool
+	}{
+		{nil, cli.FormatText, false},
+		{[]string{"--output", "yaml"}, cli.FormatYAML, false},
+		{[]string{"--format", "json"}, cli.FormatJSON, false},
+		{[]string{"--format", "table"}, cli.FormatText, false},
+		{[]string{"--format", "plain"}, cli.FormatText, true},
+		{[]string{"--json"}, cli.FormatJSON, false},
+		{[]string{"--output", "yaml", "--format", "plain"}, cli.FormatText, true},
+		{[]string{"--format", "plain", "--json"}, cli.FormatJSON, false},
+	}
+	for _, tt := range tests {
+		var options globalOptions
+		fs := newFlagSet("mhostctl", "list", commands["list"], &commandContext{}, &options)
+		require.NoError(t, fs.Parse(tt.args))
+		format, plain, err := options.outputFormat()
+		require.NoError(t, err, tt.args)
+		assert.Equal(t, tt.format, format, tt.args)
+		assert.Equal(t, tt.plain, plain, tt.args)
+	}
+
+	options := globalOptions{format: "csv"}
+	_, _, err := options.outputFormat()
+	assert.ErrorContains(t, err, "unsupported output format")
+
+	// plain格式不输出表头
+	table := &table{header: []string{"NAME", "ACTIVE"}}
+	table.addRow("Dev", "yes")
+	var out strings.Builder
+	require.NoError(t, table.write(&out, true))
+	assert.Equal(t, "Dev\tyes\n", out.String())
+	out.Reset()
+	require.NoError(t, table.write(&out, false))
+	assert.Contains(t, out.String(), "NAME")
+}
+
+// TestCompletionScript 测试补全脚本使用程序名，包含全部子命令和输出格式
+func TestCompletionScript(t *testing.T) {
+	for _, shell := range completionShells {
+		for _, program := range []string{"mhost", "mhostctl"} {
+			script, err := completionScript(program, shell)
+			require.NoError(t, err)
+			assert.Contains(t, script, program+" list --names", shell)
+			assert.Contains(t, script, "yaml", shell)
+			assert.Contains(t, script, "plain", shell)
+			for _, name := range commandNames() {
+				assert.Contains(t, script, name, shell)
+			}
+		}
+	}
+
+	_, err := completionScript("mhost", "tcsh")
+	assert.ErrorContains(t, err, "unsupported shell")
+	assert.True(t, Has("completion"))
+	assert.False(t, Has("help"))
+}
@@ -0,0 +1,193 @@
+package command
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/flyhigher139/mhost/internal/cli"
+)
+
+// completionShells 支持生成补全脚本的shell
+var completionShells = []string{"bash", "zsh", "fish"}
+
+// init 注册completion子命令。补全脚本由commands生成，因此不能在commands的初始化中直接引用
+func init() {
+	commands["completion"] = command{
+		usage:      "completion bash|zsh|fish",
+		summary:    "print a shell completion script",
+		run:        runCompletion,
+		standalone: true,
+	}
+}
+
+// runCompletion 输出指定shell的补全脚本，子命令和参数取自命令定义，Profile名称在补全时动态读取
+func runCompletion(ctx *commandContext) int {
+	if len(ctx.args) != 1 {
+		return usageError(ctx)
+	}
+
+	script, err := completionScript(ctx.program, ctx.args[0])
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	fmt.Print(script)
+	return 0
+}
+
+// completionScript 生成program在指定shell下的补全脚本
+func completionScript(program, shell string) (string, error) {
+	switch shell {
+	case "bash":
+		return bashCompletion(program), nil
+	case "zsh":
+		return zshCompletion(program), nil
+	case "fish":
+		return fishCompletion(program), nil
+	}
+	return "", fmt.Errorf("unsupported shell: %q (expected %s)", shell, strings.Join(completionShells, ", "))
+}
+
+// completionFlag 补全脚本中的参数
+type completionFlag struct {
+	name  string
+	usage string
+}
+
+// commandFlagList 子命令支持的全部参数，按名称排序
+func commandFlagList(program, name string) []completionFlag {
+	var flags []completionFlag
+	fs := newFlagSet(program, name, commands[name], &commandContext{}, &globalOptions{})
+	fs.VisitAll(func(f *flag.Flag) {
+		flags = append(flags, completionFlag{name: f.Name, usage: f.Usage})
+	})
+	return flags
+}
+
+// profileNamesCommand 补全Profile名称时执行的命令，输出每行一个Profile名称
+func profileNamesCommand(program string) string {
+	return program + " list --names 2>/dev/null"
+}
+
+// quote 以单引号包裹字符串，用于shell脚本
+func quote(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", `'\''`) + "'"
+}
+
+// formatNames 输出格式名称，包含--format另外接受的table和plain，以空格分隔
+func formatNames() string {
+	formats := cli.Formats()
+	names := make([]string, 0, len(formats)+2)
+	for _, format := range formats {
+		names = append(names, string(format))
+	}
+	return strings.Join(append(names, "table", "plain"), " ")
+}
+
+// bashCompletion 生成bash补全脚本
+func bashCompletion(program string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# bash completion for %s, load with: source <(%s completion bash)\n", program, program)
+	fmt.Fprintf(&b, "_%s() {\n", program)
+	b.WriteString("    local cur prev cmd\n")
+	b.WriteString("    cur=\"${COMP_WORDS[COMP_CWORD]}\"\n")
+	b.WriteString("    prev=\"${COMP_WORDS[COMP_CWORD-1]}\"\n")
+	b.WriteString("    if [[ $COMP_CWORD -eq 1 ]]; then\n")
+	fmt.Fprintf(&b, "        COMPREPLY=($(compgen -W %s -- \"$cur\"))\n", quote(strings.Join(commandNames(), " ")))
+	b.WriteString("        return\n")
+	b.WriteString("    fi\n")
+	b.WriteString("    cmd=\"${COMP_WORDS[1]}\"\n")
+	b.WriteString("    case \"$prev\" in\n")
+	fmt.Fprintf(&b, "        -output|--output|-format|--format) COMPREPLY=($(compgen -W %s -- \"$cur\")); return ;;\n", quote(formatNames()))
+	b.WriteString("        -workspace|--workspace|-socket|--socket) return ;;\n")
+	b.WriteString("    esac\n")
+	b.WriteString("    if [[ \"$cur\" == -* ]]; then\n")
+	b.WriteString("        case \"$cmd\" in\n")
+	for _, name := range commandNames() {
+		var flags []string
+		for _, f := range commandFlagList(program, name) {
+			flags = append(flags, "--"+f.name)
+		}
+		fmt.Fprintf(&b, "            %s) COMPREPLY=($(compgen -W %s -- \"$cur\")) ;;\n", name, quote(strings.Join(flags, " ")))
+	}
+	b.WriteString("        esac\n")
+	b.WriteString("        return\n")
+	b.WriteString("    fi\n")
+	b.WriteString("    case \"$cmd\" in\n")
+	fmt.Fprintf(&b, "        apply|switch) local IFS=$'\\n'; COMPREPLY=($(compgen -W \"$(%s)\" -- \"$cur\")) ;;\n", profileNamesCommand(program))
+	fmt.Fprintf(&b, "        completion) COMPREPLY=($(compgen -W %s -- \"$cur\")) ;;\n", quote(strings.Join(completionShells, " ")))
+	b.WriteString("    esac\n")
+	b.WriteString("}\n")
+	fmt.Fprintf(&b, "complete -F _%s %s\n", program, program)
+	return b.String()
+}
+
+// zshCompletion 生成zsh补全脚本
+func zshCompletion(program string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "#compdef %s\n", program)
+	fmt.Fprintf(&b, "# zsh completion for %s, load with: source <(%s completion zsh)\n", program, program)
+	fmt.Fprintf(&b, "_%s() {\n", program)
+	b.WriteString("    local -a commands\n")
+	b.WriteString("    commands=(\n")
+	for _, name := range commandNames() {
+		fmt.Fprintf(&b, "        %s\n", quote(name+":"+strings.ReplaceAll(commands[name].summary, ":", `\:`)))
+	}
+	b.WriteString("    )\n")
+	b.WriteString("    if (( CURRENT == 2 )); then\n")
+	b.WriteString("        _describe 'command' commands\n")
+	b.WriteString("        return\n")
+	b.WriteString("    fi\n")
+	b.WriteString("    local cmd=${words[2]}\n")
+	b.WriteString("    case ${words[CURRENT-1]} in\n")
+	fmt.Fprintf(&b, "        -output|--output|-format|--format) compadd %s; return ;;\n", formatNames())
+	b.WriteString("        -workspace|--workspace|-socket|--socket) return ;;\n")
+	b.WriteString("    esac\n")
+	b.WriteString("    if [[ $PREFIX == -* ]]; then\n")
+	b.WriteString("        case $cmd in\n")
+	for _, name := range commandNames() {
+		var flags []string
+		for _, f := range commandFlagList(program, name) {
+			flags = append(flags, "--"+f.name)
+		}
+		fmt.Fprintf(&b, "            %s) compadd -- %s ;;\n", name, strings.Join(flags, " "))
+	}
+	b.WriteString("        esac\n")
+	b.WriteString("        return\n")
+	b.WriteString("    fi\n")
+	b.WriteString("    case $cmd in\n")
+	fmt.Fprintf(&b, "        apply|switch) local -a profiles; profiles=(\"${(@f)$(%s)}\"); compadd -a profiles ;;\n", profileNamesCommand(program))
+	fmt.Fprintf(&b, "        completion) compadd %s ;;\n", strings.Join(completionShells, " "))
+	b.WriteString("    esac\n")
+	b.WriteString("}\n")
+	fmt.Fprintf(&b, "compdef _%s %s\n", program, program)
+	return b.String()
+}
+
+// fishCompletion 生成fish补全脚本
+func fishCompletion(program string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# fish completion for %s, load with: %s completion fish | source\n", program, program)
+	fmt.Fprintf(&b, "complete -c %s -f\n", program)
+	for _, name := range commandNames() {
+		fmt.Fprintf(&b, "complete -c %s -n __fish_use_subcommand -a %s -d %s\n", program, name, quote(commands[name].summary))
+	}
+	for _, name := range commandNames() {
+		condition := quote("__fish_seen_subcommand_from " + name)
+		for _, f := range commandFlagList(program, name) {
+			switch f.name {
+			case "output", "format":
+				fmt.Fprintf(&b, "complete -c %s -n %s -l %s -xa %s -d %s\n", program, condition, f.name, quote(formatNames()), quote(f.usage))
+			case "workspace", "socket":
+				fmt.Fprintf(&b, "complete -c %s -n %s -l %s -x -d %s\n", program, condition, f.name, quote(f.usage))
+			default:
+				fmt.Fprintf(&b, "complete -c %s -n %s -l %s -d %s\n", program, condition, f.name, quote(f.usage))
+			}
+		}
+	}
+	fmt.Fprintf(&b, "complete -c %s -n %s -a %s\n", program, quote("__fish_seen_subcommand_from apply switch"), quote("("+profileNamesCommand(program)+")"))
+	fmt.Fprintf(&b, "complete -c %s -n %s -a %s\n", program, quote("__fish_seen_subcommand_from completion"), quote(strings.Join(completionShells, " ")))
+	return b.String()
+}
@@ -0,0 +1,172 @@
+package command
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/flyhigher139/mhost/internal/cli"
+	"github.com/flyhigher139/mhost/internal/daemon"
+	"github.com/flyhigher139/mhost/pkg/models"
+)
+
+// daemonFlags 注册daemon子命令选项
+func daemonFlags(fs *flag.FlagSet, ctx *commandContext) {
+	fs.StringVar(&ctx.webAddr, "web", "", "serve the web UI on this address (e.g. 127.0.0.1:7878)")
+	fs.StringVar(&ctx.token, "token", "", "API token for the web UI (defaults to the daemon_token file in the data directory)")
+	fs.BoolVar(&ctx.enforce, "enforce", false, "re-apply the active profile when the hosts file is changed externally")
+	fs.DurationVar(&ctx.watchInterval, "interval", 2*time.Second, "how often to check the profiles and the hosts file for changes")
+}
+
+// runDaemon 在前台运行守护进程：在控制socket上接受快速切换，指定--enforce时保持激活的Profile已应用，直到收到退出信号
+func runDaemon(ctx *commandContext) int {
+	if len(ctx.args) != 0 {
+		return usageError(ctx)
+	}
+	if err := ctx.openManagers(); err != nil {
+		return writeError(ctx, err)
+	}
+
+	options := daemon.DefaultOptions(ctx.workspace.DataDir)
+	options.SocketPath = ctx.socketPath
+	options.BackupDir = ctx.workspace.BackupDir
+	options.WatchInterval = ctx.watchInterval
+	options.Enforce = ctx.enforce
+	options.WebAddr = ctx.webAddr
+	options.APIToken = ctx.token
+	if options.WebAddr != "" && options.APIToken == "" {
+		tokenPath := filepath.Join(ctx.workspace.DataDir, daemon.TokenFileName)
+		token, err := daemon.LoadOrCreateToken(tokenPath)
+		if err != nil {
+			return writeError(ctx, fmt.Errorf("failed to load API token: %w", err))
+		}
+		options.APIToken = token
+		fmt.Printf("Web UI API token is stored in %s\n", tokenPath)
+	}
+
+	ctx.captureBaseline()
+
+	server := daemon.NewServer(ctx.profileManager, ctx.hostManager, options)
+	if err := server.Start(); err != nil {
+		return writeError(ctx, fmt.Errorf("failed to start daemon: %w", err))
+	}
+	mode := "watching"
+	if options.Enforce {
+		mode = "enforcing the active profile"
+	}
+	fmt.Printf("mHost daemon listening on %s (%s)\n", options.SocketPath, mode)
+	if options.WebAddr != "" {
+		fmt.Printf("Web UI available at http://%s/\n", options.WebAddr)
+	}
+
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+	<-sigChan
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := server.Stop(shutdownCtx); err != nil {
+		return writeError(ctx, fmt.Errorf("failed to stop daemon: %w", err))
+	}
+	return 0
+}
+
+// runSwitch 通过守护进程模糊匹配并应用Profile，供启动器集成使用；为减少延迟不预先探测守护进程
+func runSwitch(ctx *commandContext) int {
+	query := joinArgs(ctx.args)
+	if query == "" {
+		return usageError(ctx)
+	}
+
+	c, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	result, err := daemon.NewClient(ctx.socketPath).Switch(c, query)
+	if err != nil {
+		return writeError(ctx, err)
+	}
+
+	t := &table{header: []string{"FIELD", "VALUE"}}
+	t.addRow("profile", result.Apply.ProfileName)
+	t.addRow("changed", yesNo(result.Apply.Changed))
+	t.addRow("entries", strconv.Itoa(result.Apply.Entries))
+	t.addRow("duration_ms", strconv.FormatInt(result.DurationMS, 10))
+	return ctx.writeTable(cli.KindSwitchResult, result, t)
+}
+
+// runDiff 输出激活Profile与hosts文件之间的差异
+func runDiff(ctx *commandContext) int {
+	if len(ctx.args) != 0 {
+		return usageError(ctx)
+	}
+
+	return withClient(ctx, func(c context.Context, client *daemon.Client) error {
+		diff, err := client.Diff(c)
+		if err != nil {
+			return err
+		}
+		if ctx.output.IsMachineReadable() {
+			return cli.Encode(os.Stdout, ctx.output, cli.NewDocument(cli.KindDiff, diff))
+		}
+
+		if !diff.HasChanges {
+			fmt.Println("No differences")
+			return nil
+		}
+		for _, entry := range diff.Added {
+			fmt.Printf("+ %s\t%s\n", entry.IP, entry.Hostname)
+		}
+		for _, change := range diff.Changed {
+			fmt.Printf("~ %s\t%s -> %s\n", change.Hostname, change.Expected.IP, change.Actual.IP)
+		}
+		for _, entry := range diff.Removed {
+			fmt.Printf("- %s\t%s\n", entry.IP, entry.Hostname)
+		}
+		return nil
+	})
+}
+
+// runEvents 持续输出守护进程事件，JSON格式每行一个文档，YAML格式以---分隔
+func runEvents(ctx *commandContext) int {
+	client := daemon.NewClient(ctx.socketPath)
+	if !client.IsRunning() {
+		return writeError(ctx, fmt.Errorf("mHost daemon is not running on %s (start it with `%s daemon`)", ctx.socketPath, ctx.program))
+	}
+
+	types := make([]models.EventType, 0, len(ctx.args))
+	for _, arg := range ctx.args {
+		types = append(types, models.EventType(arg))
+	}
+
+	c, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+
+	events, err := client.Events(c, types...)
+	if err != nil {
+		return writeError(ctx, err)
+	}
+
+	for event := range events {
+		switch ctx.output {
+		case cli.FormatJSON:
+			data, err := json.Marshal(cli.NewDocument(cli.KindEvent, event))
+			if err != nil {
+				continue
+			}
+			fmt.Println(string(data))
+		case cli.FormatYAML:
+			fmt.Println("---")
+			cli.Encode(os.Stdout, cli.FormatYAML, cli.NewDocument(cli.KindEvent, event))
+		default:
+			data, _ := json.Marshal(event.Data)
+			fmt.Printf("%s %s %s\n", event.Timestamp.Format(time.RFC3339), event.Type, data)
+		}
+	}
+	return 0
+}
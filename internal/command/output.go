@@ -0,0 +1,58 @@
+package command
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/flyhigher139/mhost/internal/cli"
+)
+
+// table 文本输出的表格
+type table struct {
+	header []string
+	rows   [][]string
+}
+
+// addRow 追加一行
+func (t *table) addRow(columns ...string) {
+	t.rows = append(t.rows, columns)
+}
+
+// write 输出表格：默认包含表头并对齐各列，plain时只输出以制表符分隔的行
+func (t *table) write(w io.Writer, plain bool) error {
+	if plain {
+		for _, row := range t.rows {
+			if _, err := fmt.Fprintln(w, strings.Join(row, "\t")); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	if len(t.header) > 0 {
+		fmt.Fprintln(tw, strings.Join(t.header, "\t"))
+	}
+	for _, row := range t.rows {
+		fmt.Fprintln(tw, strings.Join(row, "\t"))
+	}
+	return tw.Flush()
+}
+
+// writeTable 按输出格式输出结果：机器可读格式输出internal/cli的统一文档，文本格式输出表格
+func (ctx *commandContext) writeTable(kind cli.Kind, data interface{}, t *table) int {
+	var err error
+	if ctx.output.IsMachineReadable() {
+		err = cli.Encode(os.Stdout, ctx.output, cli.NewDocument(kind, data))
+	} else {
+		err = t.write(os.Stdout, ctx.plain)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	return 0
+}
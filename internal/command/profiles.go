@@ -0,0 +1,485 @@
+package command
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/flyhigher139/mhost/internal/activity"
+	"github.com/flyhigher139/mhost/internal/cli"
+	"github.com/flyhigher139/mhost/internal/config"
+	"github.com/flyhigher139/mhost/internal/daemon"
+	"github.com/flyhigher139/mhost/internal/host"
+	"github.com/flyhigher139/mhost/internal/plugins"
+	"github.com/flyhigher139/mhost/internal/profile"
+	"github.com/flyhigher139/mhost/pkg/hostsfile"
+	"github.com/flyhigher139/mhost/pkg/models"
+)
+
+// listFlags 注册list子命令选项
+func listFlags(fs *flag.FlagSet, ctx *commandContext) {
+	fs.BoolVar(&ctx.names, "names", false, "print only the profile names, one per line")
+}
+
+// runList 列出所有Profile
+func runList(ctx *commandContext) int {
+	if len(ctx.args) != 0 {
+		return usageError(ctx)
+	}
+	if err := ctx.openManagers(); err != nil {
+		return writeError(ctx, err)
+	}
+	profiles, err := loadProfiles(ctx)
+	if err != nil {
+		return writeError(ctx, err)
+	}
+
+	if ctx.names && !ctx.output.IsMachineReadable() {
+		for _, p := range profiles {
+			fmt.Println(p.Name)
+		}
+		return 0
+	}
+	t := &table{header: []string{"NAME", "ACTIVE", "ENTRIES", "ENABLED", "UPDATED"}}
+	for _, p := range profiles {
+		t.addRow(p.Name, yesNo(p.IsActive), strconv.Itoa(len(p.Entries)), strconv.Itoa(countEnabled(p.Entries)),
+			p.UpdatedAt.Format(time.RFC3339))
+	}
+	return ctx.writeTable(cli.KindProfileList, cli.NewProfiles(profiles), t)
+}
+
+// importFlags 注册import子命令选项
+func importFlags(fs *flag.FlagSet, ctx *commandContext) {
+	fs.StringVar(&ctx.name, "name", "", "profile name (defaults to the file name)")
+	fs.StringVar(&ctx.plugin, "plugin", "", "parse the file with this import plugin from the plugins directory; the file is optional for plugins that fetch their own data")
+	fs.StringVar(&ctx.report, "report", "", "write a JSON import report to this path (by default one is written to the data directory only when the profile is renamed or duplicates are skipped)")
+}
+
+// runImport 将hosts格式的文件导入为新的Profile，被注释的条目以禁用状态保留；指定--plugin时由导入插件解析。
+// 与已有Profile重名或有重复的主机名时，导入报告记录实际导入的名称和跳过的条目
+func runImport(ctx *commandContext) int {
+	if ctx.plugin != "" {
+		if len(ctx.args) > 1 {
+			return usageError(ctx)
+		}
+	} else if len(ctx.args) != 1 {
+		return usageError(ctx)
+	}
+	if err := ctx.openManagers(); err != nil {
+		return writeError(ctx, err)
+	}
+
+	var path string
+	var data []byte
+	if len(ctx.args) == 1 {
+		path = ctx.args[0]
+		var err error
+		if data, err = os.ReadFile(path); err != nil {
+			return writeError(ctx, fmt.Errorf("failed to read file: %w", err))
+		}
+	}
+
+	var parsed *models.Profile
+	var err error
+	if ctx.plugin != "" {
+		parsed, err = importWithPlugin(ctx, data, path)
+	} else {
+		parsed, err = profile.ProfileFromHosts(data, path, ctx.name)
+	}
+	if err != nil {
+		return writeError(ctx, err)
+	}
+	if path == "" {
+		path = "plugin:" + ctx.plugin
+	}
+	report := profile.NewImportReport(path)
+	imported, err := report.Import(ctx.profileManager, parsed)
+	if reportPath, reportErr := writeImportReport(ctx, report); reportErr != nil {
+		fmt.Fprintf(os.Stderr, "warning: %v\n", reportErr)
+	} else if reportPath != "" {
+		fmt.Fprintf(os.Stderr, "import report written to %s\n", reportPath)
+	}
+	if err != nil {
+		return writeError(ctx, err)
+	}
+	activity.NewFeed(activity.DefaultFeedPath(ctx.workspace.DataDir)).Record(models.NewEvent(models.EventProfileImported, ctx.program, map[string]interface{}{
+		"profile_id":   imported.ID,
+		"profile_name": imported.Name,
+		"source":       path,
+		"entries":      len(imported.Entries),
+	}))
+
+	t := &table{header: []string{"FIELD", "VALUE"}}
+	t.addRow("profile", imported.Name)
+	t.addRow("id", imported.ID)
+	t.addRow("entries", strconv.Itoa(len(imported.Entries)))
+	t.addRow("enabled", strconv.Itoa(countEnabled(imported.Entries)))
+	if report.Renamed() > 0 {
+		t.addRow("renamed_from", parsed.Name)
+	}
+	t.addRow("skipped_duplicates", strconv.Itoa(report.SkippedDuplicates()))
+	return ctx.writeTable(cli.KindProfile, cli.NewProfile(imported), t)
+}
+
+// importWithPlugin 用--plugin指定的导入插件解析文件内容，Profile名称默认为文件名，没有文件时为插件名
+func importWithPlugin(ctx *commandContext, data []byte, path string) (*models.Profile, error) {
+	appConfig, err := config.NewWorkspaceConfigManager(ctx.workspace).LoadConfig()
+	if err != nil {
+		return nil, err
+	}
+	if !appConfig.Plugins.Enabled {
+		return nil, errors.New("plugins are disabled, set plugins.enabled in the config file")
+	}
+	discovered, err := plugins.LoadConfig(appConfig.Plugins, ctx.workspace.DataDir)
+	plugin := plugins.Find(plugins.Importers(discovered), ctx.plugin)
+	if plugin == nil {
+		if err != nil {
+			return nil, fmt.Errorf("import plugin %s not found: %w", ctx.plugin, err)
+		}
+		return nil, fmt.Errorf("import plugin %s not found", ctx.plugin)
+	}
+
+	name := strings.TrimSpace(ctx.name)
+	switch {
+	case name != "":
+	case path != "":
+		name = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	default:
+		name = plugin.Name
+	}
+	return plugin.ParseProfile(data, name, hostsfile.Normalizer{AllowUnderscores: appConfig.Hostnames.AllowUnderscores})
+}
+
+// writeImportReport 写入导入报告：指定了--report时写入该路径，否则只在有重命名、跳过的条目或导入失败时
+// 写入数据目录下的import-reports。返回写入的路径，未写入时为空
+func writeImportReport(ctx *commandContext, report *profile.ImportReport) (string, error) {
+	if ctx.report != "" {
+		return ctx.report, report.Write(ctx.report)
+	}
+	if !report.HasConflicts() && report.Error == "" {
+		return "", nil
+	}
+	return report.WriteToDir(profile.DefaultImportReportDir(ctx.workspace.DataDir))
+}
+
+// runAdopt 接管hosts文件中其他mHost安装写入的管理section：激活头部记录的本地Profile，
+// 本地没有这些Profile时以section中的条目新建Profile。不修改hosts文件
+func runAdopt(ctx *commandContext) int {
+	if len(ctx.args) != 0 {
+		return usageError(ctx)
+	}
+	if err := ctx.openManagers(); err != nil {
+		return writeError(ctx, err)
+	}
+	managed, err := ctx.hostManager.GetManagedSection()
+	if err != nil {
+		return writeError(ctx, err)
+	}
+	if len(managed) == 0 {
+		return writeError(ctx, hostsfile.ErrNoManagedSection)
+	}
+
+	adoption, err := profile.AdoptManagedSection(ctx.profileManager, managed)
+	if err != nil {
+		return writeError(ctx, err)
+	}
+	p := adoption.Profile
+	feed := activity.NewFeed(activity.DefaultFeedPath(ctx.workspace.DataDir))
+	profileData := map[string]interface{}{"profile_id": p.ID, "profile_name": p.Name}
+	if adoption.Created {
+		feed.Record(models.NewEvent(models.EventProfileImported, ctx.program, map[string]interface{}{
+			"profile_id":   p.ID,
+			"profile_name": p.Name,
+			"source":       ctx.hostManager.GetHostsFilePath(),
+			"entries":      len(p.Entries),
+		}))
+	}
+	if adoption.Activated {
+		feed.Record(models.NewEvent(models.EventProfileActivated, ctx.program, profileData))
+	}
+
+	t := &table{header: []string{"FIELD", "VALUE"}}
+	t.addRow("profile", p.Name)
+	t.addRow("id", p.ID)
+	t.addRow("created", yesNo(adoption.Created))
+	t.addRow("activated", yesNo(adoption.Activated))
+	t.addRow("written_by", adoption.Header.Version)
+	return ctx.writeTable(cli.KindProfile, cli.NewProfile(p), t)
+}
+
+// runBackups 列出hosts备份，最新的在前
+func runBackups(ctx *commandContext) int {
+	if len(ctx.args) != 0 {
+		return usageError(ctx)
+	}
+	files, err := host.ListBackupFiles(ctx.workspace.BackupDir)
+	if err != nil {
+		return writeError(ctx, err)
+	}
+
+	backups := make([]cli.Backup, 0, len(files))
+	t := &table{header: []string{"NAME", "CREATED", "TYPE", "SIZE", "MILESTONE", "TAGS"}}
+	for _, file := range files {
+		backups = append(backups, cli.Backup{
+			Name:      file.Name,
+			Path:      file.Path,
+			Size:      file.Size,
+			CreatedAt: file.ModTime,
+			Automatic: file.Automatic,
+			Delta:     file.Delta,
+			Milestone: file.Milestone,
+			Tags:      file.Tags,
+		})
+
+		kind := "manual"
+		if file.Automatic {
+			kind = "automatic"
+		}
+		if file.Delta {
+			kind += "+delta"
+		}
+		t.addRow(file.Name, file.ModTime.Format(time.RFC3339), kind, strconv.FormatInt(file.Size, 10),
+			file.Milestone, strings.Join(file.Tags, ","))
+	}
+	return ctx.writeTable(cli.KindBackupList, backups, t)
+}
+
+// runStatus 输出激活的Profile、hosts文件与其之间的差异，以及守护进程是否在运行
+func runStatus(ctx *commandContext) int {
+	if len(ctx.args) != 0 {
+		return usageError(ctx)
+	}
+	if err := ctx.openManagers(); err != nil {
+		return writeError(ctx, err)
+	}
+
+	status := &cli.HostsStatus{
+		Workspace: ctx.workspace.Name,
+		HostsPath: ctx.hostManager.GetHostsFilePath(),
+		Diff:      cli.NewDiff(nil),
+	}
+	summaries, err := ctx.profileManager.ListProfiles()
+	if err != nil {
+		return writeError(ctx, err)
+	}
+	status.Profiles = len(summaries)
+
+	lines, err := ctx.hostManager.ReadHostsFile()
+	if err != nil {
+		return writeError(ctx, err)
+	}
+	status.ManagedSection = hostsfile.HasManagedSection(lines)
+	if status.ManagedSection {
+		status.Section = cli.NewSectionInfo(hostsfile.ExtractManagedSection(lines))
+	}
+
+	if active, err := ctx.profileManager.GetActiveProfile(); err == nil {
+		status.ActiveProfileID = active.ID
+		status.ActiveProfileName = active.Name
+		layered, err := profile.LayeredActiveProfile(ctx.profileManager)
+		if err != nil {
+			return writeError(ctx, err)
+		}
+		drift, err := ctx.hostManager.DetectDrift(layered)
+		if err != nil {
+			return writeError(ctx, err)
+		}
+		status.Diff = cli.NewDiff(drift)
+	}
+	status.Daemon = daemonInfo(ctx)
+
+	active, drift := "none", "none"
+	if status.ActiveProfileName != "" {
+		active = status.ActiveProfileName
+	}
+	if status.Diff.HasChanges {
+		drift = fmt.Sprintf("%d added, %d changed, %d removed", len(status.Diff.Added), len(status.Diff.Changed), len(status.Diff.Removed))
+		if status.Diff.Modified {
+			drift += ", section modified"
+		}
+	}
+	running := "not running"
+	if status.Daemon != nil {
+		running = fmt.Sprintf("running (pid %d, since %s)", status.Daemon.PID, status.Daemon.StartTime.Format(time.RFC3339))
+	}
+	t := &table{header: []string{"FIELD", "VALUE"}}
+	t.addRow("workspace", status.Workspace)
+	t.addRow("hosts", status.HostsPath)
+	t.addRow("profiles", strconv.Itoa(status.Profiles))
+	t.addRow("active", active)
+	t.addRow("managed_section", yesNo(status.ManagedSection))
+	if status.Section != nil {
+		t.addRow("section_profile", status.Section.ProfileName)
+		t.addRow("section_version", status.Section.Version)
+		t.addRow("section_state", status.Section.State)
+	}
+	t.addRow("drift", drift)
+	t.addRow("daemon", running)
+	return ctx.writeTable(cli.KindHostsStatus, status, t)
+}
+
+// daemonInfo 查询守护进程状态，守护进程未运行或无法响应时返回nil
+func daemonInfo(ctx *commandContext) *cli.DaemonInfo {
+	client := daemon.NewClient(ctx.socketPath)
+	if !client.IsRunning() {
+		return nil
+	}
+
+	c, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	status, err := client.Status(c)
+	if err != nil {
+		return nil
+	}
+	return &cli.DaemonInfo{PID: status.PID, StartTime: status.StartTime, SocketPath: ctx.socketPath}
+}
+
+// applyFlags 注册apply子命令选项
+func applyFlags(fs *flag.FlagSet, ctx *commandContext) {
+	fs.BoolVar(&ctx.force, "force", false, "apply even if the hosts file exceeds the configured size limits")
+}
+
+// runApply 激活并应用Profile；守护进程在运行时由守护进程写入，否则直接写入。
+// Profile已激活且hosts文件无差异时不重复写入
+func runApply(ctx *commandContext) int {
+	query := joinArgs(ctx.args)
+	if query == "" {
+		return usageError(ctx)
+	}
+
+	var result *cli.ApplyResult
+	var err error
+	if client := daemon.NewClient(ctx.socketPath); client.IsRunning() {
+		result, err = applyWithDaemon(ctx, client, query)
+	} else if err = ctx.openManagers(); err == nil {
+		result, err = apply(ctx, query)
+	}
+	if errors.Is(err, host.ErrLimitExceeded) && !ctx.output.IsMachineReadable() {
+		err = fmt.Errorf("%w\nRe-run with --force to apply anyway, or raise the limits in the settings", err)
+	}
+	if err != nil {
+		return writeError(ctx, err)
+	}
+
+	t := &table{header: []string{"FIELD", "VALUE"}}
+	t.addRow("profile", result.ProfileName)
+	t.addRow("changed", yesNo(result.Changed))
+	t.addRow("entries", strconv.Itoa(result.Entries))
+	if result.Changed {
+		t.addRow("lines_written", strconv.Itoa(result.LinesWritten))
+		t.addRow("duration_ms", strconv.FormatInt(result.DurationMS, 10))
+	}
+	if result.BackupPath != "" {
+		t.addRow("backup", result.BackupPath)
+	}
+	for _, warning := range result.Warnings {
+		t.addRow("warning", warning)
+	}
+	return ctx.writeTable(cli.KindApplyResult, result, t)
+}
+
+// applyWithDaemon 通过守护进程激活并写入Profile，避免与守护进程同时写入hosts文件
+func applyWithDaemon(ctx *commandContext, client *daemon.Client, query string) (*cli.ApplyResult, error) {
+	c, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	if ctx.force {
+		return client.ForceApply(c, query)
+	}
+	return client.Apply(c, query)
+}
+
+// apply 激活并写入Profile，记录应用历史和活动，与GUI和守护进程共用同一份记录
+func apply(ctx *commandContext, query string) (*cli.ApplyResult, error) {
+	p, err := resolveProfile(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	drift, err := ctx.hostManager.DetectDrift(p)
+	if err != nil {
+		return nil, err
+	}
+	result := &cli.ApplyResult{
+		ProfileID:   p.ID,
+		ProfileName: p.Name,
+		HostsPath:   ctx.hostManager.GetHostsFilePath(),
+		Entries:     countEnabled(p.Entries),
+		AppliedAt:   time.Now(),
+		Diff:        cli.NewDiff(drift),
+	}
+	// 同时激活了多个Profile时应用单个Profile会取代全部激活的Profile
+	active := profile.IsActiveSet(ctx.profileManager, p.ID)
+	if active && !drift.HasDrift() {
+		return result, nil
+	}
+
+	ctx.captureBaseline()
+
+	feed := activity.NewFeed(activity.DefaultFeedPath(ctx.workspace.DataDir))
+	profileData := map[string]interface{}{"profile_id": p.ID, "profile_name": p.Name}
+	if !active {
+		if err := ctx.profileManager.ActivateProfile(p.ID); err != nil {
+			return nil, err
+		}
+		feed.Record(models.NewEvent(models.EventProfileActivated, ctx.program, profileData))
+	}
+
+	applied, err := ctx.hostManager.ApplyProfileWithOptions(p, host.ApplyOptions{IgnoreLimits: ctx.force})
+	if err != nil {
+		return nil, err
+	}
+	result.Changed = true
+	result.LinesWritten = applied.LinesWritten
+	result.BackupPath = applied.BackupPath
+	result.Warnings = applied.Warnings
+	result.DurationMS = applied.Duration.Milliseconds()
+
+	host.NewHistory(host.DefaultHistoryPath(ctx.workspace.DataDir)).Record(applied)
+	feed.Record(models.NewEvent(models.EventSystemHostsUpdated, ctx.program, map[string]interface{}{
+		"profile_id":   p.ID,
+		"profile_name": p.Name,
+		"entries":      result.Entries,
+		"hosts_path":   result.HostsPath,
+	}))
+	return result, nil
+}
+
+// loadProfiles 读取全部Profile的完整数据
+func loadProfiles(ctx *commandContext) ([]*models.Profile, error) {
+	summaries, err := ctx.profileManager.ListProfiles()
+	if err != nil {
+		return nil, err
+	}
+	profiles := make([]*models.Profile, 0, len(summaries))
+	for _, summary := range summaries {
+		if p, err := ctx.profileManager.GetProfile(summary.ID); err == nil {
+			profiles = append(profiles, p)
+		}
+	}
+	return profiles, nil
+}
+
+// resolveProfile 按ID或名称（不区分大小写）查找Profile
+func resolveProfile(ctx *commandContext, query string) (*models.Profile, error) {
+	if p, err := ctx.profileManager.GetProfile(query); err == nil {
+		return p, nil
+	}
+
+	summaries, err := ctx.profileManager.ListProfiles()
+	if err != nil {
+		return nil, err
+	}
+	for _, summary := range summaries {
+		if strings.EqualFold(summary.Name, query) {
+			return ctx.profileManager.GetProfile(summary.ID)
+		}
+	}
+	return nil, fmt.Errorf("%w: %s", models.ErrProfileNotFound, query)
+}
@@ -0,0 +1,202 @@
+package command
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/flyhigher139/mhost/internal/cli"
+	"github.com/flyhigher139/mhost/internal/config"
+	"github.com/flyhigher139/mhost/internal/doctor"
+	"github.com/flyhigher139/mhost/internal/helper"
+	"github.com/flyhigher139/mhost/internal/host"
+)
+
+// runValidate 验证hosts文件并输出全部错误与警告，不依赖守护进程；存在错误时退出码为1
+func runValidate(ctx *commandContext) int {
+	if len(ctx.args) > 1 {
+		return usageError(ctx)
+	}
+	hostsPath := ""
+	if len(ctx.args) == 1 {
+		hostsPath = ctx.args[0]
+	}
+
+	result, err := host.NewManager(hostsPath, "").ValidateHostsFile()
+	if err != nil {
+		return writeError(ctx, err)
+	}
+
+	output := &cli.ValidationResult{
+		HostsPath: result.HostsPath,
+		Valid:     result.Valid(),
+		Lines:     result.Lines,
+		Entries:   result.Entries,
+		Errors:    len(result.Errors()),
+		Warnings:  len(result.Warnings()),
+		Issues:    result.Issues,
+	}
+	if ctx.output.IsMachineReadable() {
+		cli.Encode(os.Stdout, ctx.output, cli.NewDocument(cli.KindValidationResult, output))
+	} else {
+		for _, issue := range output.Issues {
+			fmt.Printf("%s: %s\n", issue.Severity, issue)
+		}
+		fmt.Printf("%s: %d lines, %d entries, %d errors, %d warnings\n",
+			output.HostsPath, output.Lines, output.Entries, output.Errors, output.Warnings)
+	}
+
+	if !output.Valid {
+		return 1
+	}
+	return 0
+}
+
+// runDoctor 检查Helper、hosts文件权限、数据目录、备份空间、DNS缓存刷新和配置，输出问题与修复建议；存在错误时退出码为1
+func runDoctor(ctx *commandContext) int {
+	if len(ctx.args) != 0 {
+		return usageError(ctx)
+	}
+
+	report := doctor.New(doctor.WorkspaceOptions(ctx.workspace)).Run()
+	if ctx.output.IsMachineReadable() {
+		cli.Encode(os.Stdout, ctx.output, cli.NewDocument(cli.KindDoctorReport, report))
+	} else {
+		for _, check := range report.Checks {
+			fmt.Printf("[%s] %s: %s\n", check.Status, check.Name, check.Detail)
+			if check.Fix != "" {
+				fmt.Printf("    fix: %s\n", check.Fix)
+			}
+		}
+		if problems := report.Problems(); len(problems) > 0 {
+			fmt.Printf("%d of %d checks need attention\n", len(problems), len(report.Checks))
+		} else {
+			fmt.Println("All checks passed")
+		}
+	}
+
+	if report.HasErrors() {
+		return 1
+	}
+	return 0
+}
+
+// signatureFlags 注册Helper签名校验选项，只用于verify-helper；安装和卸载只使用构建时注入的签名标识和团队ID
+func signatureFlags(fs *flag.FlagSet, ctx *commandContext) {
+	fs.StringVar(&ctx.identifier, "identifier", helper.DefaultServiceName, "expected code signing identifier")
+	fs.StringVar(&ctx.teamID, "team-id", helper.TeamID, "expected Apple developer team ID")
+}
+
+// runVerifyHelper 校验Helper可执行文件的代码签名，默认校验已安装的Helper
+func runVerifyHelper(ctx *commandContext) int {
+	if len(ctx.args) > 1 {
+		return usageError(ctx)
+	}
+	path := helper.InstallPath(helper.DefaultServiceName)
+	if len(ctx.args) == 1 {
+		path = ctx.args[0]
+	}
+
+	info, err := helper.VerifyHelper(helper.NewCodesignVerifier(), path, ctx.identifier, ctx.teamID)
+	if err != nil {
+		return writeError(ctx, err)
+	}
+
+	if ctx.output.IsMachineReadable() {
+		cli.Encode(os.Stdout, ctx.output, cli.NewDocument(cli.KindHelperSignature, info))
+		return 0
+	}
+	fmt.Printf("Signature OK: %s\n", info.Path)
+	fmt.Printf("Identifier: %s\n", info.Identifier)
+	fmt.Printf("Team ID:    %s\n", info.TeamID)
+	for _, authority := range info.Authority {
+		fmt.Printf("Authority:  %s\n", authority)
+	}
+	return 0
+}
+
+// runInstallHelper 校验签名后安装或升级Helper
+func runInstallHelper(ctx *commandContext) int {
+	if len(ctx.args) != 1 {
+		return usageError(ctx)
+	}
+
+	info, err := helper.Install(ctx.args[0], helper.NewCodesignVerifier())
+	if err != nil {
+		return writeError(ctx, err)
+	}
+
+	if ctx.output.IsMachineReadable() {
+		cli.Encode(os.Stdout, ctx.output, cli.NewDocument(cli.KindHelperSignature, info))
+		return 0
+	}
+	fmt.Printf("Installed helper %s (team %s) to %s\n", info.Identifier, info.TeamID, info.Path)
+	return 0
+}
+
+// runUninstallHelper 校验已安装Helper的签名后将其删除
+func runUninstallHelper(ctx *commandContext) int {
+	if len(ctx.args) != 0 {
+		return usageError(ctx)
+	}
+	if err := helper.Uninstall(helper.DefaultServiceName, helper.TeamID, helper.NewCodesignVerifier()); err != nil {
+		return writeError(ctx, err)
+	}
+	if !ctx.output.IsMachineReadable() {
+		fmt.Println("Helper uninstalled")
+	}
+	return 0
+}
+
+// runWorkspace 列出、创建、切换或删除工作区
+func runWorkspace(ctx *commandContext) int {
+	action := "list"
+	if len(ctx.args) > 0 {
+		action = ctx.args[0]
+	}
+	if (action == "list" && len(ctx.args) > 1) || (action != "list" && len(ctx.args) != 2) {
+		return usageError(ctx)
+	}
+
+	var err error
+	switch action {
+	case "list":
+		var workspaces []*config.Workspace
+		if workspaces, err = ctx.workspaces.ListWorkspaces(); err == nil {
+			if ctx.output.IsMachineReadable() {
+				cli.Encode(os.Stdout, ctx.output, cli.NewDocument(cli.KindWorkspaceList, workspaces))
+				return 0
+			}
+			for _, workspace := range workspaces {
+				marker := " "
+				if workspace.Active {
+					marker = "*"
+				}
+				fmt.Printf("%s %s\t%s\n", marker, workspace.Name, workspace.DataDir)
+			}
+		}
+	case "create":
+		var workspace *config.Workspace
+		if workspace, err = ctx.workspaces.CreateWorkspace(ctx.args[1]); err == nil && !ctx.output.IsMachineReadable() {
+			fmt.Printf("Created workspace '%s' in %s\n", workspace.Name, workspace.DataDir)
+		}
+	case "use":
+		if _, err = ctx.workspaces.SwitchWorkspace(ctx.args[1]); err == nil && !ctx.output.IsMachineReadable() {
+			fmt.Printf("Switched to workspace '%s'\n", ctx.args[1])
+			if os.Getenv(config.WorkspaceEnv) != "" {
+				fmt.Printf("Note: $%s overrides the active workspace in this shell\n", config.WorkspaceEnv)
+			}
+		}
+	case "delete":
+		if err = ctx.workspaces.DeleteWorkspace(ctx.args[1]); err == nil && !ctx.output.IsMachineReadable() {
+			fmt.Printf("Deleted workspace '%s'\n", ctx.args[1])
+		}
+	default:
+		return usageError(ctx)
+	}
+
+	if err != nil {
+		return writeError(ctx, err)
+	}
+	return 0
+}
@@ -0,0 +1,141 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+var (
+	// ErrBackupPathNotDir 备份路径已存在但不是目录
+	ErrBackupPathNotDir = errors.New("backup path is not a directory")
+	// ErrBackupPathNotWritable 备份目录不可写，或不存在且无法创建
+	ErrBackupPathNotWritable = errors.New("backup path is not writable")
+)
+
+// temporaryDirs 系统会定期清理的临时目录
+var temporaryDirs = []string{"/tmp", "/var/tmp", "/private/tmp", "/private/var/tmp", "/private/var/folders", "/var/folders"}
+
+// cloudSyncedDirs 常见云同步服务的目录名称，备份放在其中会被同步到云端，并可能被按需下载机制移出本地
+var cloudSyncedDirs = map[string]string{
+	"Dropbox":          "Dropbox",
+	"OneDrive":         "OneDrive",
+	"Google Drive":     "Google Drive",
+	"iCloud Drive":     "iCloud Drive",
+	"Mobile Documents": "iCloud Drive",
+}
+
+// BackupPathCheck 备份路径的检查结果
+type BackupPathCheck struct {
+	// Path 展开~和环境变量并清理后的路径，相对路径相对于配置文件所在目录
+	Path string
+	// Exists 目录已存在；为false时目录会在第一次备份时创建
+	Exists bool
+	// Temporary 位于系统临时目录，可能被系统清理
+	Temporary bool
+	// CloudService 所在的云同步服务，不在云同步目录中时为空
+	CloudService string
+}
+
+// ExpandPath 展开路径开头的~和路径中的环境变量，并清理路径；路径为空时返回空字符串
+func ExpandPath(path string) (string, error) {
+	path = os.ExpandEnv(strings.TrimSpace(path))
+	if path == "" {
+		return "", nil
+	}
+	if path == "~" || strings.HasPrefix(path, "~/") {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("expand %s: %w", path, err)
+		}
+		path = filepath.Join(home, path[1:])
+	}
+	return filepath.Clean(path), nil
+}
+
+// CheckBackupPath 展开并检查备份路径：已存在时必须是可写的目录，不存在时必须能在最近的已存在的上级目录中创建。
+// 相对路径相对于baseDir；位于临时目录或云同步目录时通过检查结果提示，不作为错误
+func CheckBackupPath(path, baseDir string) (*BackupPathCheck, error) {
+	expanded, err := ExpandPath(path)
+	if err != nil {
+		return nil, err
+	}
+	if expanded == "" {
+		return nil, fmt.Errorf("%w: empty path", ErrBackupPathNotWritable)
+	}
+	if !filepath.IsAbs(expanded) && baseDir != "" {
+		expanded = filepath.Join(baseDir, expanded)
+	}
+
+	check := &BackupPathCheck{Path: expanded}
+	dir := expanded
+	for {
+		info, err := os.Stat(dir)
+		if err == nil {
+			if !info.IsDir() {
+				return nil, fmt.Errorf("%w: %s", ErrBackupPathNotDir, dir)
+			}
+			break
+		}
+		if errors.Is(err, os.ErrPermission) {
+			return nil, fmt.Errorf("%w: %v", ErrBackupPathNotWritable, err)
+		}
+		// 不存在，或上级路径不是目录（由上级路径的检查报告）
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return nil, fmt.Errorf("%w: %s", ErrBackupPathNotWritable, expanded)
+		}
+		dir = parent
+	}
+	check.Exists = dir == expanded
+
+	if err := checkWritable(dir); err != nil {
+		return nil, fmt.Errorf("%w: %s: %v", ErrBackupPathNotWritable, dir, err)
+	}
+
+	check.Temporary = isTemporaryPath(expanded)
+	check.CloudService = cloudService(expanded)
+	return check, nil
+}
+
+// checkWritable 在目录中创建并删除临时文件，确认当前用户可以写入
+func checkWritable(dir string) error {
+	f, err := os.CreateTemp(dir, ".mhost-write-test-*")
+	if err != nil {
+		return err
+	}
+	name := f.Name()
+	f.Close()
+	return os.Remove(name)
+}
+
+// isTemporaryPath 路径是否位于系统临时目录
+func isTemporaryPath(path string) bool {
+	dirs := append([]string{os.TempDir()}, temporaryDirs...)
+	for _, dir := range dirs {
+		if dir != "" && isWithinDir(filepath.Clean(dir), path) {
+			return true
+		}
+	}
+	return false
+}
+
+// cloudService 路径所在的云同步服务，包括macOS的~/Library/CloudStorage下的各类服务
+func cloudService(path string) string {
+	segments := strings.Split(filepath.ToSlash(path), "/")
+	for i, segment := range segments {
+		if service, ok := cloudSyncedDirs[segment]; ok {
+			return service
+		}
+		if strings.HasPrefix(segment, "OneDrive - ") {
+			return "OneDrive"
+		}
+		if segment == "CloudStorage" && i > 0 && segments[i-1] == "Library" && i+1 < len(segments) {
+			service, _, _ := strings.Cut(segments[i+1], "-")
+			return service
+		}
+	}
+	return ""
+}
@@ -0,0 +1,53 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+
+	"github.com/flyhigher139/mhost/pkg/models"
+)
+
+// 配置分区名称，与配置文件中的JSON键一致
+const (
+	SectionWindow      = "window"
+	SectionBackup      = "backup"
+	SectionLog         = "log"
+	SectionSecurity    = "security"
+	SectionUI          = "ui"
+	SectionDNSStats    = "dns_stats"
+	SectionNetwork     = "network"
+	SectionTelemetry   = "telemetry"
+	SectionLimits      = "limits"
+	SectionHostnames   = "hostnames"
+	SectionHealthCheck = "health_check"
+	SectionSnapshot    = "snapshot"
+	SectionXPC         = "xpc"
+	SectionRules       = "rules"
+	SectionHooks       = "hooks"
+	SectionPlugins     = "plugins"
+	SectionDNSFilter   = "dns_filter"
+)
+
+// ChangedSections 比较两份配置，按字段顺序返回内容发生变化的分区名称。
+// 任一配置为nil时视为所有分区都已变化
+func ChangedSections(old, updated *models.AppConfig) []string {
+	configType := reflect.TypeOf(models.AppConfig{})
+	var changed []string
+	for i := 0; i < configType.NumField(); i++ {
+		field := configType.Field(i)
+		if old == nil || updated == nil ||
+			!reflect.DeepEqual(reflect.ValueOf(old).Elem().Field(i).Interface(), reflect.ValueOf(updated).Elem().Field(i).Interface()) {
+			changed = append(changed, sectionName(field))
+		}
+	}
+	return changed
+}
+
+// sectionName 返回字段在配置文件中的名称
+func sectionName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	if name == "" {
+		return field.Name
+	}
+	return name
+}
@@ -55,6 +55,10 @@ type ManagerImpl struct {
 	mu            sync.RWMutex
 	watching      bool
 	stopChan      chan struct{}
+
+	// 工作区隔离：workspaceDir非空时配置中的路径必须位于其中，reservedDir非空时不得位于其中
+	workspaceDir string
+	reservedDir  string
 }
 
 // NewManager 创建新的配置管理器
@@ -129,6 +133,8 @@ func (m *ManagerImpl) loadConfigInternal() (*models.AppConfig, error) {
 		return nil, fmt.Errorf("failed to parse config file: %w", err)
 	}
 
+	clampLimits(&config)
+
 	// 验证配置
 	if err := m.validateConfigInternal(&config); err != nil {
 		return nil, fmt.Errorf("invalid config: %w", err)
@@ -264,6 +270,13 @@ func (m *ManagerImpl) ValidateConfig(config *models.AppConfig) error {
 	return m.validateConfigInternal(config)
 }
 
+// clampLimits 引入上限之前保存的备份设置可能超过上限，改为上限并输出警告，而不是拒绝加载
+func clampLimits(config *models.AppConfig) {
+	for _, warning := range config.Backup.ClampLimits() {
+		fmt.Printf("Warning: %s\n", warning)
+	}
+}
+
 // validateConfigInternal 内部验证配置方法
 func (m *ManagerImpl) validateConfigInternal(config *models.AppConfig) error {
 	if config == nil {
@@ -271,7 +284,36 @@ func (m *ManagerImpl) validateConfigInternal(config *models.AppConfig) error {
 	}
 
 	// 使用模型的验证方法
-	return config.Validate()
+	if err := config.Validate(); err != nil {
+		return err
+	}
+
+	return m.validateIsolation(config)
+}
+
+// validateIsolation 检查配置中的备份与日志路径没有越出所属工作区
+func (m *ManagerImpl) validateIsolation(config *models.AppConfig) error {
+	if m.workspaceDir == "" && m.reservedDir == "" {
+		return nil
+	}
+
+	for _, path := range []string{config.Backup.BackupPath, config.Log.FilePath} {
+		if path == "" {
+			continue
+		}
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(filepath.Dir(m.configPath), path)
+		}
+		path = filepath.Clean(path)
+
+		if m.workspaceDir != "" && !isWithinDir(m.workspaceDir, path) {
+			return fmt.Errorf("%w: %s", ErrOutsideWorkspace, path)
+		}
+		if m.reservedDir != "" && isWithinDir(m.reservedDir, path) {
+			return fmt.Errorf("%w: %s", ErrOutsideWorkspace, path)
+		}
+	}
+	return nil
 }
 
 // BackupConfig 备份当前配置
@@ -327,6 +369,7 @@ func (m *ManagerImpl) RestoreConfig(backupPath string) error {
 	if err := json.Unmarshal(data, &config); err != nil {
 		return fmt.Errorf("failed to parse backup config: %w", err)
 	}
+	clampLimits(&config)
 
 	// 验证配置
 	if err := m.ValidateConfig(&config); err != nil {
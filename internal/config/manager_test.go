@@ -1,10 +1,14 @@
 package config
 
 import (
+	"bytes"
 	"encoding/json"
 	"os"
 	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/flyhigher139/mhost/pkg/models"
 	"github.com/stretchr/testify/assert"
@@ -84,6 +88,25 @@ func (suite *ConfigManagerTestSuite) TestLoadConfigWithValidFile() {
 	assert.Equal(suite.T(), testConfig.Backup.MaxBackups, loadedConfig.Backup.MaxBackups)
 }
 
+// TestLoadConfigAboveBackupLimits 测试加载引入备份上限之前保存、超过上限的配置时改为上限
+func (suite *ConfigManagerTestSuite) TestLoadConfigAboveBackupLimits() {
+	oldConfig := models.DefaultAppConfig()
+	oldConfig.Backup.MaxBackups = 150
+	oldConfig.Backup.RetentionDays = 400
+	data, err := json.Marshal(oldConfig)
+	require.NoError(suite.T(), err)
+	require.NoError(suite.T(), os.WriteFile(suite.configPath, data, 0644))
+
+	loadedConfig, err := suite.manager.LoadConfig()
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), models.MaxBackupCount, loadedConfig.Backup.MaxBackups)
+	assert.Equal(suite.T(), models.MaxBackupRetentionDays, loadedConfig.Backup.RetentionDays)
+
+	// 保存时仍然拒绝超过上限的值
+	loadedConfig.Backup.MaxBackups = 150
+	assert.Error(suite.T(), suite.manager.SaveConfig(loadedConfig))
+}
+
 // TestLoadConfigWithInvalidFile 测试加载无效的配置文件
 func (suite *ConfigManagerTestSuite) TestLoadConfigWithInvalidFile() {
 	// 写入无效的JSON
@@ -196,6 +219,15 @@ func (suite *ConfigManagerTestSuite) TestValidateConfig() {
 	err = suite.manager.ValidateConfig(nil)
 	assert.Error(suite.T(), err)
 	assert.Equal(suite.T(), models.ErrInvalidConfig, err)
+
+	// 测试XPC超时配置
+	xpcConfig := models.DefaultAppConfig()
+	xpcConfig.XPC.OperationTimeouts = map[string]time.Duration{"write_hosts": 5 * time.Minute}
+	assert.NoError(suite.T(), suite.manager.ValidateConfig(xpcConfig))
+	assert.Equal(suite.T(), 5*time.Minute, xpcConfig.Clone().XPC.OperationTimeouts["write_hosts"])
+
+	xpcConfig.XPC.OperationTimeouts["get_status"] = 0
+	assert.Equal(suite.T(), models.ErrInvalidConfig, suite.manager.ValidateConfig(xpcConfig))
 }
 
 // TestBackupConfig 测试备份配置
@@ -339,3 +371,194 @@ func (suite *ConfigManagerTestSuite) TestWatchConfigAlreadyWatching() {
 func TestConfigManagerSuite(t *testing.T) {
 	suite.Run(t, new(ConfigManagerTestSuite))
 }
+
+// TestWorkspaces 测试工作区的创建、切换与删除
+func TestWorkspaces(t *testing.T) {
+	t.Setenv(WorkspaceEnv, "")
+	rootDir := t.TempDir()
+	workspaces := NewWorkspaceManager(rootDir)
+
+	active, err := workspaces.ActiveWorkspace()
+	require.NoError(t, err)
+	assert.Equal(t, DefaultWorkspace, active.Name)
+	assert.Equal(t, rootDir, active.DataDir)
+
+	work, err := workspaces.CreateWorkspace("work")
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(rootDir, "workspaces", "work"), work.DataDir)
+	assert.DirExists(t, work.BackupDir)
+
+	_, err = workspaces.CreateWorkspace("work")
+	assert.ErrorIs(t, err, ErrWorkspaceExists)
+	_, err = workspaces.CreateWorkspace("../escape")
+	assert.ErrorIs(t, err, ErrInvalidWorkspaceName)
+	_, err = workspaces.SwitchWorkspace("missing")
+	assert.ErrorIs(t, err, ErrWorkspaceNotFound)
+
+	_, err = workspaces.SwitchWorkspace("work")
+	require.NoError(t, err)
+	active, err = workspaces.ActiveWorkspace()
+	require.NoError(t, err)
+	assert.Equal(t, "work", active.Name)
+
+	list, err := workspaces.ListWorkspaces()
+	require.NoError(t, err)
+	require.Len(t, list, 2)
+	assert.Equal(t, DefaultWorkspace, list[0].Name)
+	assert.True(t, list[1].Active)
+
+	// 环境变量优先
+	t.Setenv(WorkspaceEnv, DefaultWorkspace)
+	active, err = workspaces.ActiveWorkspace()
+	require.NoError(t, err)
+	assert.Equal(t, DefaultWorkspace, active.Name)
+
+	assert.ErrorIs(t, workspaces.DeleteWorkspace(DefaultWorkspace), ErrActiveWorkspace)
+	require.NoError(t, workspaces.DeleteWorkspace("work"))
+	assert.NoDirExists(t, work.DataDir)
+}
+
+// TestWorkspaceConfigIsolation 测试工作区配置的路径隔离
+func TestWorkspaceConfigIsolation(t *testing.T) {
+	t.Setenv(WorkspaceEnv, "")
+	rootDir := t.TempDir()
+	workspaces := NewWorkspaceManager(rootDir)
+
+	personal, err := workspaces.CreateWorkspace("personal")
+	require.NoError(t, err)
+	work, err := workspaces.CreateWorkspace("work")
+	require.NoError(t, err)
+
+	manager := NewWorkspaceConfigManager(work)
+	_, err = manager.LoadConfig()
+	require.NoError(t, err)
+	assert.FileExists(t, work.ConfigPath)
+	assert.NoFileExists(t, personal.ConfigPath)
+
+	err = manager.UpdateConfig(func(c *models.AppConfig) {
+		c.Backup.BackupPath = personal.BackupDir
+	})
+	assert.ErrorIs(t, err, ErrOutsideWorkspace)
+
+	err = manager.UpdateConfig(func(c *models.AppConfig) {
+		c.Backup.BackupPath = "backups/archive"
+		c.Log.FilePath = filepath.Join(work.DataDir, "mhost.log")
+	})
+	assert.NoError(t, err)
+
+	// 默认工作区不能指向其他工作区
+	defaultWorkspace, err := workspaces.GetWorkspace(DefaultWorkspace)
+	require.NoError(t, err)
+	err = NewWorkspaceConfigManager(defaultWorkspace).UpdateConfig(func(c *models.AppConfig) {
+		c.Log.FilePath = filepath.Join(work.DataDir, "mhost.log")
+	})
+	assert.ErrorIs(t, err, ErrOutsideWorkspace)
+}
+
+// TestPreferences 测试界面偏好的导出、导入和验证
+func TestPreferences(t *testing.T) {
+	source := models.DefaultAppConfig()
+	source.UI.Theme = "dark"
+	source.UI.ProfileGrouping = models.ProfileGroupingTag
+	source.UI.ExpandedGroups = []string{"tag:dev"}
+	source.UI.ApplyOnSave = true
+	source.UI.EntryColumnWidths = map[string]float32{"hostname": 240}
+	source.Window.Width, source.Window.X = 1440, 200
+	source.Backup.BackupPath = "/Users/alice/backups"
+
+	var buf bytes.Buffer
+	require.NoError(t, WritePreferences(&buf, ExtractPreferences(source)))
+	assert.NotContains(t, buf.String(), "backups")
+	assert.NotContains(t, buf.String(), "tag:dev", "expanded groups are not exported")
+	assert.Contains(t, buf.String(), `"apply_on_save": false`)
+
+	preferences, err := ReadPreferences(&buf)
+	require.NoError(t, err)
+
+	target := models.DefaultAppConfig()
+	target.Window.X = 50
+	target.UI.ExpandedGroups = []string{"folder:ops"}
+	target.UI.ApplyOnSave = true
+	preferences.ApplyTo(target)
+	assert.Equal(t, "dark", target.UI.Theme)
+	assert.Equal(t, []string{"folder:ops"}, target.UI.ExpandedGroups, "local expanded groups are kept")
+	assert.True(t, target.UI.ApplyOnSave, "the local apply-on-save setting is kept")
+	assert.Equal(t, 1440, target.Window.Width)
+	assert.Equal(t, 50, target.Window.X)
+	assert.Equal(t, models.DefaultAppConfig().Backup, target.Backup)
+
+	// 导入后的配置与界面偏好不共享列宽
+	target.UI.EntryColumnWidths["hostname"] = 300
+	assert.Equal(t, float32(240), preferences.UI.EntryColumnWidths["hostname"])
+
+	// 缺少的字段使用默认值
+	preferences, err = ReadPreferences(strings.NewReader(`{"version": 1, "ui": {"theme": "light"}}`))
+	require.NoError(t, err)
+	assert.Equal(t, models.DefaultAppConfig().UI.FontSize, preferences.UI.FontSize)
+
+	_, err = ReadPreferences(strings.NewReader(`{"version": 2}`))
+	assert.Error(t, err)
+	_, err = ReadPreferences(strings.NewReader(`{"version": 1, "ui": {"theme": "neon"}}`))
+	assert.Error(t, err)
+}
+
+// TestChangedSections 测试比较配置时只返回变化的分区
+func TestChangedSections(t *testing.T) {
+	old := models.DefaultAppConfig()
+	updated := old.Clone()
+	assert.Empty(t, ChangedSections(old, updated))
+
+	updated.Log.Level = "debug"
+	updated.UI.Theme = "dark"
+	updated.Security.BlockedHosts = append(updated.Security.BlockedHosts, "ads.test")
+	assert.Equal(t, []string{SectionLog, SectionSecurity, SectionUI}, ChangedSections(old, updated))
+
+	assert.Len(t, ChangedSections(nil, updated), reflect.TypeOf(models.AppConfig{}).NumField())
+}
+
+// TestCheckBackupPath 测试备份路径的展开、可写检查以及临时目录和云同步目录的识别
+func TestCheckBackupPath(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("MHOST_TEST_DIR", "archive")
+
+	check, err := CheckBackupPath(" ~/$MHOST_TEST_DIR/hosts/ ", "")
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(home, "archive", "hosts"), check.Path)
+	assert.False(t, check.Exists)
+	assert.True(t, check.Temporary)
+
+	// 相对路径相对于配置文件所在目录
+	check, err = CheckBackupPath("backups", home)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(home, "backups"), check.Path)
+
+	require.NoError(t, os.MkdirAll(check.Path, 0755))
+	check, err = CheckBackupPath(check.Path, "")
+	require.NoError(t, err)
+	assert.True(t, check.Exists)
+
+	file := filepath.Join(home, "file")
+	require.NoError(t, os.WriteFile(file, nil, 0644))
+	_, err = CheckBackupPath(file, "")
+	assert.ErrorIs(t, err, ErrBackupPathNotDir)
+	_, err = CheckBackupPath(filepath.Join(file, "sub"), "")
+	assert.ErrorIs(t, err, ErrBackupPathNotDir)
+	_, err = CheckBackupPath("  ", "")
+	assert.ErrorIs(t, err, ErrBackupPathNotWritable)
+
+	if os.Geteuid() != 0 {
+		readOnly := filepath.Join(home, "readonly")
+		require.NoError(t, os.Mkdir(readOnly, 0555))
+		_, err = CheckBackupPath(filepath.Join(readOnly, "backups"), "")
+		assert.ErrorIs(t, err, ErrBackupPathNotWritable)
+	}
+
+	assert.Equal(t, "Dropbox", cloudService("/Users/alice/Dropbox/backups"))
+	assert.Equal(t, "iCloud Drive", cloudService("/Users/alice/Library/Mobile Documents/com~apple~CloudDocs/mhost"))
+	assert.Equal(t, "GoogleDrive", cloudService("/Users/alice/Library/CloudStorage/GoogleDrive-alice@example.com/My Drive"))
+	assert.Equal(t, "OneDrive", cloudService("/Users/alice/OneDrive - Example/backups"))
+	assert.Empty(t, cloudService("/Users/alice/Documents/backups"))
+	assert.True(t, isTemporaryPath("/tmp/mhost"))
+	assert.False(t, isTemporaryPath("/Users/alice/Documents"))
+}
@@ -0,0 +1,84 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+
+	"github.com/flyhigher139/mhost/pkg/models"
+)
+
+// PreferencesVersion 界面偏好文件格式的版本
+const PreferencesVersion = 1
+
+// Preferences 可单独导出的界面偏好，用于在团队中共享统一的界面设置。
+// 只包含界面和窗口布局，不包含备份目录、代理、Helper等与本机相关的设置，
+// 也不包含保存时自动应用和展开的分组等与个人使用习惯相关的界面设置
+type Preferences struct {
+	Version int                 `json:"version"`
+	UI      models.UIConfig     `json:"ui"`
+	Window  models.WindowConfig `json:"window"` // 窗口大小，导入时不改变窗口位置
+}
+
+// ExtractPreferences 从配置中提取界面偏好
+func ExtractPreferences(config *models.AppConfig) *Preferences {
+	cloned := config.Clone()
+	window := cloned.Window
+	window.X, window.Y = 0, 0
+	clearLocalUI(&cloned.UI)
+	return &Preferences{
+		Version: PreferencesVersion,
+		UI:      cloned.UI,
+		Window:  window,
+	}
+}
+
+// ApplyTo 将界面偏好应用到配置，保留配置中的窗口位置、自动应用设置和展开的分组
+func (p *Preferences) ApplyTo(config *models.AppConfig) {
+	ui := p.UI
+	ui.ApplyOnSave = config.UI.ApplyOnSave
+	ui.ApplyOnSaveDelay = config.UI.ApplyOnSaveDelay
+	ui.ExpandedGroups = config.UI.ExpandedGroups
+	ui.EntryColumnWidths = maps.Clone(p.UI.EntryColumnWidths)
+	config.UI = ui
+	config.Window.Width = p.Window.Width
+	config.Window.Height = p.Window.Height
+	config.Window.Maximized = p.Window.Maximized
+}
+
+// clearLocalUI 清除不导出的界面设置
+func clearLocalUI(ui *models.UIConfig) {
+	ui.ApplyOnSave = false
+	ui.ApplyOnSaveDelay = 0
+	ui.ExpandedGroups = nil
+}
+
+// WritePreferences 以JSON格式写出界面偏好
+func WritePreferences(w io.Writer, preferences *Preferences) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(preferences); err != nil {
+		return fmt.Errorf("failed to write preferences: %w", err)
+	}
+	return nil
+}
+
+// ReadPreferences 读取并验证界面偏好，缺少的字段使用默认值
+func ReadPreferences(r io.Reader) (*Preferences, error) {
+	preferences := ExtractPreferences(models.DefaultAppConfig())
+	preferences.Version = 0
+	if err := json.NewDecoder(r).Decode(preferences); err != nil {
+		return nil, fmt.Errorf("failed to parse preferences: %w", err)
+	}
+	if preferences.Version <= 0 || preferences.Version > PreferencesVersion {
+		return nil, fmt.Errorf("unsupported preferences version: %d", preferences.Version)
+	}
+
+	config := models.DefaultAppConfig()
+	preferences.ApplyTo(config)
+	if err := config.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid preferences: %w", err)
+	}
+	return preferences, nil
+}
@@ -0,0 +1,263 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+const (
+	// DefaultWorkspace 默认工作区，数据目录即根目录，兼容引入工作区之前的布局
+	DefaultWorkspace = "default"
+	// WorkspaceEnv 覆盖当前工作区的环境变量
+	WorkspaceEnv = "MHOST_WORKSPACE"
+
+	// workspacesDirName 非默认工作区所在的子目录
+	workspacesDirName = "workspaces"
+	// activeWorkspaceFile 记录当前工作区名称的文件
+	activeWorkspaceFile = "workspace"
+)
+
+var (
+	// ErrInvalidWorkspaceName 工作区名称无效
+	ErrInvalidWorkspaceName = errors.New("invalid workspace name")
+	// ErrWorkspaceNotFound 工作区不存在
+	ErrWorkspaceNotFound = errors.New("workspace not found")
+	// ErrWorkspaceExists 工作区已存在
+	ErrWorkspaceExists = errors.New("workspace already exists")
+	// ErrActiveWorkspace 不能删除当前或默认工作区
+	ErrActiveWorkspace = errors.New("cannot delete the active or default workspace")
+	// ErrOutsideWorkspace 配置中的路径超出工作区目录
+	ErrOutsideWorkspace = errors.New("path is outside the workspace")
+)
+
+// workspaceNamePattern 工作区名称格式，用作目录名
+var workspaceNamePattern = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_-]{0,63}$`)
+
+// Workspace 工作区，拥有独立的数据目录、备份目录和配置
+type Workspace struct {
+	Name       string `json:"name"`
+	DataDir    string `json:"data_dir"`
+	ConfigPath string `json:"config_path"`
+	BackupDir  string `json:"backup_dir"`
+	Active     bool   `json:"active"`
+}
+
+// WorkspaceManager 定义工作区管理器接口
+type WorkspaceManager interface {
+	// ListWorkspaces 列出所有工作区，默认工作区排在首位
+	ListWorkspaces() ([]*Workspace, error)
+
+	// GetWorkspace 获取指定工作区
+	GetWorkspace(name string) (*Workspace, error)
+
+	// CreateWorkspace 创建工作区
+	CreateWorkspace(name string) (*Workspace, error)
+
+	// DeleteWorkspace 删除工作区及其全部数据
+	DeleteWorkspace(name string) error
+
+	// ActiveWorkspace 获取当前工作区，环境变量优先于持久化的选择
+	ActiveWorkspace() (*Workspace, error)
+
+	// SwitchWorkspace 切换当前工作区
+	SwitchWorkspace(name string) (*Workspace, error)
+}
+
+// WorkspaceManagerImpl 工作区管理器实现
+type WorkspaceManagerImpl struct {
+	rootDir string
+	mu      sync.Mutex
+}
+
+// NewWorkspaceManager 创建工作区管理器，rootDir为空时使用~/.mhost
+func NewWorkspaceManager(rootDir string) WorkspaceManager {
+	if rootDir == "" {
+		rootDir = filepath.Dir(getDefaultConfigPath())
+	}
+	return &WorkspaceManagerImpl{rootDir: rootDir}
+}
+
+// NewWorkspaceConfigManager 创建绑定到工作区的配置管理器。非默认工作区配置中的路径被限制在工作区目录内；
+// 默认工作区兼容已有配置不限制路径，但不允许指向其他工作区
+func NewWorkspaceConfigManager(workspace *Workspace) Manager {
+	manager := NewManager(workspace.ConfigPath, workspace.BackupDir).(*ManagerImpl)
+	if workspace.Name == DefaultWorkspace {
+		manager.reservedDir = filepath.Join(workspace.DataDir, workspacesDirName)
+	} else {
+		manager.workspaceDir = workspace.DataDir
+	}
+	return manager
+}
+
+// ListWorkspaces 列出所有工作区，默认工作区排在首位
+func (m *WorkspaceManagerImpl) ListWorkspaces() ([]*Workspace, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	active := m.activeName()
+	workspaces := []*Workspace{m.workspace(DefaultWorkspace, active)}
+
+	entries, err := os.ReadDir(filepath.Join(m.rootDir, workspacesDirName))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read workspaces directory: %w", err)
+	}
+
+	var names []string
+	for _, entry := range entries {
+		if entry.IsDir() && workspaceNamePattern.MatchString(entry.Name()) && entry.Name() != DefaultWorkspace {
+			names = append(names, entry.Name())
+		}
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		workspaces = append(workspaces, m.workspace(name, active))
+	}
+	return workspaces, nil
+}
+
+// GetWorkspace 获取指定工作区
+func (m *WorkspaceManagerImpl) GetWorkspace(name string) (*Workspace, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.getWorkspace(name)
+}
+
+// CreateWorkspace 创建工作区
+func (m *WorkspaceManagerImpl) CreateWorkspace(name string) (*Workspace, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if !workspaceNamePattern.MatchString(name) {
+		return nil, ErrInvalidWorkspaceName
+	}
+	if m.exists(name) {
+		return nil, ErrWorkspaceExists
+	}
+
+	workspace := m.workspace(name, m.activeName())
+	if err := os.MkdirAll(workspace.BackupDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create workspace directory: %w", err)
+	}
+	return workspace, nil
+}
+
+// DeleteWorkspace 删除工作区及其全部数据
+func (m *WorkspaceManagerImpl) DeleteWorkspace(name string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	workspace, err := m.getWorkspace(name)
+	if err != nil {
+		return err
+	}
+	if workspace.Name == DefaultWorkspace || workspace.Active {
+		return ErrActiveWorkspace
+	}
+
+	if err := os.RemoveAll(workspace.DataDir); err != nil {
+		return fmt.Errorf("failed to delete workspace: %w", err)
+	}
+	return nil
+}
+
+// ActiveWorkspace 获取当前工作区，环境变量优先于持久化的选择
+func (m *WorkspaceManagerImpl) ActiveWorkspace() (*Workspace, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.getWorkspace(m.activeName())
+}
+
+// SwitchWorkspace 切换当前工作区
+func (m *WorkspaceManagerImpl) SwitchWorkspace(name string) (*Workspace, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, err := m.getWorkspace(name); err != nil {
+		return nil, err
+	}
+
+	if err := os.MkdirAll(m.rootDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create data directory: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(m.rootDir, activeWorkspaceFile), []byte(name+"\n"), 0644); err != nil {
+		return nil, fmt.Errorf("failed to save active workspace: %w", err)
+	}
+
+	workspace := m.workspace(name, name)
+	return workspace, nil
+}
+
+// getWorkspace 获取已存在的工作区（不加锁）
+func (m *WorkspaceManagerImpl) getWorkspace(name string) (*Workspace, error) {
+	if !workspaceNamePattern.MatchString(name) {
+		return nil, ErrInvalidWorkspaceName
+	}
+	if !m.exists(name) {
+		return nil, ErrWorkspaceNotFound
+	}
+	return m.workspace(name, m.activeName()), nil
+}
+
+// exists 检查工作区是否存在，默认工作区始终存在
+func (m *WorkspaceManagerImpl) exists(name string) bool {
+	if name == DefaultWorkspace {
+		return true
+	}
+	info, err := os.Stat(m.dataDir(name))
+	return err == nil && info.IsDir()
+}
+
+// activeName 获取当前工作区名称，记录无效时回退到默认工作区
+func (m *WorkspaceManagerImpl) activeName() string {
+	name := os.Getenv(WorkspaceEnv)
+	if name == "" {
+		data, err := os.ReadFile(filepath.Join(m.rootDir, activeWorkspaceFile))
+		if err != nil {
+			return DefaultWorkspace
+		}
+		name = strings.TrimSpace(string(data))
+	}
+
+	if !workspaceNamePattern.MatchString(name) || !m.exists(name) {
+		return DefaultWorkspace
+	}
+	return name
+}
+
+// dataDir 获取工作区数据目录
+func (m *WorkspaceManagerImpl) dataDir(name string) string {
+	if name == DefaultWorkspace {
+		return m.rootDir
+	}
+	return filepath.Join(m.rootDir, workspacesDirName, name)
+}
+
+// workspace 构造工作区描述
+func (m *WorkspaceManagerImpl) workspace(name, active string) *Workspace {
+	dataDir := m.dataDir(name)
+	return &Workspace{
+		Name:       name,
+		DataDir:    dataDir,
+		ConfigPath: filepath.Join(dataDir, "config.json"),
+		BackupDir:  filepath.Join(dataDir, "backups"),
+		Active:     name == active,
+	}
+}
+
+// isWithinDir 检查路径是否位于目录内
+func isWithinDir(dir, path string) bool {
+	rel, err := filepath.Rel(dir, path)
+	if err != nil {
+		return false
+	}
+	return rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) && !filepath.IsAbs(rel)
+}
@@ -0,0 +1,367 @@
+package controller
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/flyhigher139/mhost/internal/host"
+	"github.com/flyhigher139/mhost/internal/rules"
+	"github.com/flyhigher139/mhost/pkg/hostsfile"
+	"github.com/flyhigher139/mhost/pkg/models"
+)
+
+// EntryInput 编辑Host条目时输入的内容，主机名应已按配置规范化
+type EntryInput struct {
+	Hostname string
+	IP       string
+	Comment  string
+	Enabled  bool
+	Variants map[string]string
+}
+
+// ProfileInput 编辑Profile时输入的内容
+type ProfileInput struct {
+	Name        string
+	Description string
+	Folder      string
+	Tags        []string
+	// Author 和 DefaultComment 应用于新条目的默认值
+	Author         string
+	DefaultComment string
+}
+
+// normalize 去掉输入两端的空白，规范化文件夹路径和标签
+func (input ProfileInput) normalize() ProfileInput {
+	return ProfileInput{
+		Name:           strings.TrimSpace(input.Name),
+		Description:    strings.TrimSpace(input.Description),
+		Folder:         models.NormalizeFolder(input.Folder),
+		Tags:           normalizeTags(input.Tags),
+		Author:         strings.TrimSpace(input.Author),
+		DefaultComment: strings.TrimSpace(input.DefaultComment),
+	}
+}
+
+// ParseTags 解析以逗号分隔的标签
+func ParseTags(text string) []string {
+	return normalizeTags(strings.Split(text, ","))
+}
+
+// normalizeTags 去掉标签两端的空白，忽略空标签和重复的标签
+func normalizeTags(tags []string) []string {
+	normalized := make([]string, 0, len(tags))
+	seen := make(map[string]bool)
+	for _, tag := range tags {
+		tag = strings.TrimSpace(tag)
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		normalized = append(normalized, tag)
+	}
+	return normalized
+}
+
+// CreateProfile 验证并创建Profile，指定了文件夹、标签或新条目的默认值时随后保存
+func (c *Controller) CreateProfile(input ProfileInput) (*models.Profile, error) {
+	input = input.normalize()
+	if err := ValidateProfileInfo(input.Name, input.Description); err != nil {
+		return nil, err
+	}
+	if err := ValidateEntryDefaults(input.Author, input.DefaultComment); err != nil {
+		return nil, err
+	}
+
+	p, err := c.profileManager.CreateProfile(input.Name, input.Description)
+	if err != nil {
+		return nil, err
+	}
+	if input.Folder == "" && len(input.Tags) == 0 && input.Author == "" && input.DefaultComment == "" {
+		return p, nil
+	}
+
+	p.Folder = input.Folder
+	p.Tags = input.Tags
+	p.Author = input.Author
+	p.DefaultComment = input.DefaultComment
+	if err := c.profileManager.UpdateProfile(p); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// UpdateProfileInfo 验证并修改Profile的名称、描述、文件夹、标签和新条目的默认值
+func (c *Controller) UpdateProfileInfo(p *models.Profile, input ProfileInput) error {
+	input = input.normalize()
+	if err := ValidateProfileInfo(input.Name, input.Description); err != nil {
+		return err
+	}
+	if err := ValidateEntryDefaults(input.Author, input.DefaultComment); err != nil {
+		return err
+	}
+
+	p.Name = input.Name
+	p.Description = input.Description
+	p.Folder = input.Folder
+	p.Tags = input.Tags
+	p.Author = input.Author
+	p.DefaultComment = input.DefaultComment
+	return c.profileManager.UpdateProfile(p)
+}
+
+// RestoreProfile 将Profile的内容恢复为snapshot（例如修改前保存的副本），激活状态不变；
+// 随后重新加载Profile列表，保留当前选中的Profile和Host条目
+func (c *Controller) RestoreProfile(snapshot *models.Profile) error {
+	if snapshot == nil {
+		return models.ErrInvalidProfile
+	}
+	if err := c.profileManager.UpdateProfile(snapshot.Clone()); err != nil {
+		return err
+	}
+	return c.Refresh()
+}
+
+// DeleteCurrentProfile 删除选中的Profile并清空选择，返回被删除的Profile
+func (c *Controller) DeleteCurrentProfile() (*models.Profile, error) {
+	current := c.CurrentProfile()
+	if current == nil {
+		return nil, ErrNoProfileSelected
+	}
+
+	if err := c.profileManager.DeleteProfile(current.ID); err != nil {
+		return nil, err
+	}
+	c.ClearSelection()
+	return current, nil
+}
+
+// CopyCurrentProfile 以新名称复制选中的Profile及其全部条目
+func (c *Controller) CopyCurrentProfile(newName string) (*models.Profile, error) {
+	current := c.CurrentProfile()
+	if current == nil {
+		return nil, ErrNoProfileSelected
+	}
+
+	newName = strings.TrimSpace(newName)
+	if err := ValidateInput(newName, "Profile名称", true, MaxProfileNameLength); err != nil {
+		return nil, err
+	}
+	return c.profileManager.CloneProfile(current.ID, newName)
+}
+
+// SaveCurrentProfile 保存对选中Profile的修改
+func (c *Controller) SaveCurrentProfile() error {
+	current := c.CurrentProfile()
+	if current == nil {
+		return ErrNoProfileSelected
+	}
+	return c.profileManager.UpdateProfile(current)
+}
+
+// SaveEntry 验证输入并保存Host条目：existing为nil时在选中的Profile中新建条目并补充Profile的默认注释和作者，
+// 否则修改该条目。违反严重程度为error的自定义校验规则时返回*rules.ViolationError。返回保存的条目
+func (c *Controller) SaveEntry(existing *models.HostEntry, input EntryInput) (*models.HostEntry, error) {
+	current, err := c.editableProfile()
+	if err != nil {
+		return nil, err
+	}
+	if err := ValidateEntry(input); err != nil {
+		return nil, err
+	}
+	candidate := &models.HostEntry{IP: input.IP, Hostname: input.Hostname, Enabled: input.Enabled}
+	if existing != nil {
+		candidate.ID = existing.ID
+	}
+	if conflict := current.ConflictingEntry(candidate); conflict != nil {
+		return nil, fmt.Errorf("主机名%s已有启用的条目（%s），请先禁用或删除该条目", conflict.Hostname, conflict.IP)
+	}
+	comment := input.Comment
+	if existing == nil {
+		comment = EntryComment(current, input.Comment)
+	}
+	if _, err := c.hostManager.Rules().Enforce([]hostsfile.Entry{{IP: input.IP, Hostname: input.Hostname, Comment: comment, Enabled: input.Enabled}}); err != nil {
+		return nil, err
+	}
+
+	entry := existing
+	if entry == nil {
+		entry = models.NewHostEntry(input.IP, input.Hostname, comment)
+		entry.Enabled = input.Enabled
+		entry.Variants = input.Variants
+		current.AddEntry(entry)
+	} else {
+		entry.Hostname = input.Hostname
+		entry.IP = input.IP
+		entry.Comment = input.Comment
+		entry.Enabled = input.Enabled
+		entry.Variants = input.Variants
+		entry.UpdatedAt = time.Now()
+	}
+
+	if err := c.profileManager.UpdateProfile(current); err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
+
+// EntryRuleViolations 条目违反的自定义校验规则，保存后只会包含严重程度为warning的规则
+func (c *Controller) EntryRuleViolations(entry *models.HostEntry) []rules.Violation {
+	return c.hostManager.Rules().Check(hostsfile.FromModel(entry))
+}
+
+// EntryComment 新条目的注释：补充Profile的默认注释，并以Profile的作者作为负责人
+func EntryComment(p *models.Profile, comment string) string {
+	return hostsfile.ApplyCommentDefaults(comment, p.DefaultComment, p.Author)
+}
+
+// DeleteCurrentEntry 从选中的Profile中删除选中的Host条目，返回被删除的条目
+func (c *Controller) DeleteCurrentEntry() (*models.HostEntry, error) {
+	current, err := c.editableProfile()
+	if err != nil {
+		return nil, err
+	}
+	entry := c.CurrentEntry()
+	if entry == nil {
+		return nil, ErrNoEntrySelected
+	}
+
+	current.RemoveEntry(entry.ID)
+	if err := c.profileManager.UpdateProfile(current); err != nil {
+		return nil, err
+	}
+	c.SelectEntry(nil)
+	return entry, nil
+}
+
+// ToggleCurrentEntry 切换选中Host条目的启用状态并保存，返回该条目
+func (c *Controller) ToggleCurrentEntry() (*models.HostEntry, error) {
+	current, err := c.editableProfile()
+	if err != nil {
+		return nil, err
+	}
+	entry := c.CurrentEntry()
+	if entry == nil {
+		return nil, ErrNoEntrySelected
+	}
+
+	entry.Enabled = !entry.Enabled
+	entry.UpdatedAt = time.Now()
+	if err := c.profileManager.UpdateProfile(current); err != nil {
+		// 启用后与其他条目的主机名重复等情况下保存失败，恢复原来的状态
+		entry.Enabled = !entry.Enabled
+		return nil, err
+	}
+	return entry, nil
+}
+
+// RemoveDuplicateHostnames 选中的Profile中每个重复的主机名只保留最后一个启用的条目并保存，返回删除的条目
+func (c *Controller) RemoveDuplicateHostnames() ([]*models.HostEntry, error) {
+	current, err := c.editableProfile()
+	if err != nil {
+		return nil, err
+	}
+
+	removed := current.RemoveDuplicateHostnames()
+	if len(removed) == 0 {
+		return nil, nil
+	}
+	if err := c.profileManager.UpdateProfile(current); err != nil {
+		return nil, err
+	}
+	if entry := c.CurrentEntry(); entry != nil && slices.Contains(removed, entry) {
+		c.SelectEntry(nil)
+	}
+	return removed, nil
+}
+
+// PreviewApply 预览将选中的Profile写入hosts文件后的内容，不修改hosts文件
+func (c *Controller) PreviewApply() (*host.Preview, error) {
+	current := c.CurrentProfile()
+	if current == nil {
+		return nil, ErrNoProfileSelected
+	}
+	return c.hostManager.PreviewApply(current)
+}
+
+// Apply 按选项将选中的Profile写入hosts文件并将其设为激活。
+// 超过规模限制时返回*host.LimitError，由调用方确认后以IgnoreLimits重新应用
+func (c *Controller) Apply(options host.ApplyOptions) (*host.ApplyResult, error) {
+	current := c.CurrentProfile()
+	if current == nil {
+		return nil, ErrNoProfileSelected
+	}
+
+	result, err := c.hostManager.ApplyProfileWithOptions(current, options)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := c.profileManager.ActivateProfile(current.ID); err != nil {
+		return result, fmt.Errorf("failed to activate profile: %w", err)
+	}
+
+	c.mu.Lock()
+	for _, p := range c.profiles {
+		p.IsActive = p.ID == current.ID
+	}
+	current.IsActive = true
+	c.mu.Unlock()
+	return result, nil
+}
+
+// ApplyLayers 按优先级从低到高同时激活并叠加应用多个Profile，同一主机名以优先级最高的Profile为准。
+// 超过规模限制时返回*host.LimitError，由调用方确认后以IgnoreLimits重新应用
+func (c *Controller) ApplyLayers(ids []string, options host.ApplyOptions) (*host.ApplyResult, error) {
+	profiles := make([]*models.Profile, 0, len(ids))
+	for _, id := range ids {
+		p, err := c.profileManager.GetProfile(id)
+		if err != nil {
+			return nil, err
+		}
+		profiles = append(profiles, p)
+	}
+
+	result, err := c.hostManager.ApplyProfiles(profiles, options)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := c.profileManager.ActivateProfiles(ids); err != nil {
+		return result, fmt.Errorf("failed to activate profiles: %w", err)
+	}
+
+	c.mu.Lock()
+	for _, p := range c.profiles {
+		p.IsActive = slices.Contains(ids, p.ID)
+	}
+	if c.current != nil {
+		c.current.IsActive = slices.Contains(ids, c.current.ID)
+	}
+	c.mu.Unlock()
+	return result, nil
+}
+
+// PatchEntry 将选中Profile中单个条目的变化直接写入管理section，管理section不存在或加载了转换Hook时回退为完整应用；
+// 条目被同名的全局条目覆盖时不修改hosts文件。同时激活了多个Profile时条目可能被其他Profile覆盖，重新叠加应用
+func (c *Controller) PatchEntry(entry *models.HostEntry) error {
+	current := c.CurrentProfile()
+	if current == nil {
+		return ErrNoProfileSelected
+	}
+	if shadowed, err := c.shadowedByGlobal(entry); err != nil || shadowed {
+		return err
+	}
+	if active, err := c.profileManager.GetActiveProfiles(); err == nil && len(active) > 1 {
+		_, err := c.ReapplyActive()
+		return err
+	}
+
+	err := c.hostManager.PatchManagedEntry(current.ResolveEntry(entry))
+	if errors.Is(err, hostsfile.ErrNoManagedSection) || errors.Is(err, host.ErrPatchUnavailable) {
+		_, err = c.hostManager.ApplyProfile(current)
+	}
+	return err
+}
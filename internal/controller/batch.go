@@ -0,0 +1,249 @@
+package controller
+
+import (
+	"errors"
+	"slices"
+	"time"
+
+	"github.com/flyhigher139/mhost/pkg/hostsfile"
+	"github.com/flyhigher139/mhost/pkg/models"
+)
+
+// ErrSameProfile 移动条目的目标Profile就是条目所在的Profile
+var ErrSameProfile = errors.New("entries are already in the target profile")
+
+// ToggleEntrySelection 将条目加入或移出多选，加入时同时作为选中的条目，作为范围选择的起点
+func (c *Controller) ToggleEntrySelection(entry *models.HostEntry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.selected[entry.ID] {
+		delete(c.selected, entry.ID)
+		if c.currentEntry == entry {
+			c.currentEntry = nil
+		}
+		return
+	}
+	if c.selected == nil {
+		c.selected = make(map[string]bool)
+	}
+	c.selected[entry.ID] = true
+	c.currentEntry = entry
+}
+
+// SelectEntryRange 按entries中的顺序选中从选中的条目到entry之间的所有条目，
+// 选中的条目保持不变以便继续调整范围；没有选中的条目时只选中entry
+func (c *Controller) SelectEntryRange(entries []*models.HostEntry, entry *models.HostEntry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	from, to := -1, slices.Index(entries, entry)
+	if c.currentEntry != nil {
+		from = slices.Index(entries, c.currentEntry)
+	}
+	if from < 0 || to < 0 {
+		c.currentEntry = entry
+		c.selected = map[string]bool{entry.ID: true}
+		return
+	}
+	if from > to {
+		from, to = to, from
+	}
+	c.selected = make(map[string]bool, to-from+1)
+	for _, e := range entries[from : to+1] {
+		c.selected[e.ID] = true
+	}
+}
+
+// IsEntrySelected 条目是否在多选中
+func (c *Controller) IsEntrySelected(entry *models.HostEntry) bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.selected[entry.ID]
+}
+
+// SelectedEntries 多选的Host条目，按在选中Profile中的顺序排列
+func (c *Controller) SelectedEntries() []*models.HostEntry {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if c.current == nil {
+		return nil
+	}
+	var selected []*models.HostEntry
+	for _, entry := range c.current.Entries {
+		if c.selected[entry.ID] {
+			selected = append(selected, entry)
+		}
+	}
+	return selected
+}
+
+// selection 选中的Profile和多选的条目，没有选择或Profile只读时返回对应的错误
+func (c *Controller) selection() (*models.Profile, []*models.HostEntry, error) {
+	current, err := c.editableProfile()
+	if err != nil {
+		return nil, nil, err
+	}
+	selected := c.SelectedEntries()
+	if len(selected) == 0 {
+		return nil, nil, ErrNoEntrySelected
+	}
+	return current, selected, nil
+}
+
+// entryState 批量修改前条目的状态，保存失败时恢复
+type entryState struct {
+	entry     *models.HostEntry
+	ip        string
+	enabled   bool
+	updatedAt time.Time
+}
+
+// saveEntryStates 记录条目当前的状态
+func saveEntryStates(entries []*models.HostEntry) []entryState {
+	states := make([]entryState, 0, len(entries))
+	for _, entry := range entries {
+		states = append(states, entryState{entry: entry, ip: entry.IP, enabled: entry.Enabled, updatedAt: entry.UpdatedAt})
+	}
+	return states
+}
+
+// restoreEntryStates 恢复条目修改前的状态
+func restoreEntryStates(states []entryState) {
+	for _, state := range states {
+		state.entry.IP = state.ip
+		state.entry.Enabled = state.enabled
+		state.entry.UpdatedAt = state.updatedAt
+	}
+}
+
+// SetSelectedEntriesEnabled 启用或禁用所有多选的条目并保存一次Profile，保存失败时恢复原来的状态。
+// 返回状态发生变化的条目
+func (c *Controller) SetSelectedEntriesEnabled(enabled bool) ([]*models.HostEntry, error) {
+	current, selected, err := c.selection()
+	if err != nil {
+		return nil, err
+	}
+
+	states := saveEntryStates(selected)
+	var changed []*models.HostEntry
+	now := time.Now()
+	for _, entry := range selected {
+		if entry.Enabled != enabled {
+			entry.Enabled = enabled
+			entry.UpdatedAt = now
+			changed = append(changed, entry)
+		}
+	}
+	if len(changed) == 0 {
+		return nil, nil
+	}
+
+	if err := c.profileManager.UpdateProfile(current); err != nil {
+		restoreEntryStates(states)
+		return nil, err
+	}
+	return changed, nil
+}
+
+// ChangeSelectedEntriesIP 将所有多选条目的IP修改为ip并保存一次Profile，
+// 违反严重程度为error的自定义校验规则时返回*rules.ViolationError，保存失败时恢复原来的IP。返回修改的条目
+func (c *Controller) ChangeSelectedEntriesIP(ip string) ([]*models.HostEntry, error) {
+	current, selected, err := c.selection()
+	if err != nil {
+		return nil, err
+	}
+	if err := ValidateIPAddress(ip); err != nil {
+		return nil, err
+	}
+
+	candidates := make([]hostsfile.Entry, 0, len(selected))
+	for _, entry := range selected {
+		candidate := hostsfile.FromModel(entry)
+		candidate.IP = ip
+		candidates = append(candidates, candidate)
+	}
+	if _, err := c.hostManager.Rules().Enforce(candidates); err != nil {
+		return nil, err
+	}
+
+	states := saveEntryStates(selected)
+	now := time.Now()
+	for _, entry := range selected {
+		entry.IP = ip
+		entry.UpdatedAt = now
+	}
+
+	if err := c.profileManager.UpdateProfile(current); err != nil {
+		restoreEntryStates(states)
+		return nil, err
+	}
+	return selected, nil
+}
+
+// DeleteSelectedEntries 从选中的Profile中删除所有多选的条目并保存一次Profile，
+// 保存失败时不修改Profile。返回删除的条目
+func (c *Controller) DeleteSelectedEntries() ([]*models.HostEntry, error) {
+	current, selected, err := c.selection()
+	if err != nil {
+		return nil, err
+	}
+
+	entries := current.Entries
+	current.Entries = withoutEntries(entries, selected)
+	if err := c.profileManager.UpdateProfile(current); err != nil {
+		current.Entries = entries
+		return nil, err
+	}
+	c.SelectEntry(nil)
+	return selected, nil
+}
+
+// MoveSelectedEntries 将所有多选的条目移动到target：先保存target再保存选中的Profile，
+// 任何一步保存失败时恢复两个Profile。返回移动的条目
+func (c *Controller) MoveSelectedEntries(target *models.Profile) ([]*models.HostEntry, error) {
+	current, selected, err := c.selection()
+	if err != nil {
+		return nil, err
+	}
+	if target == nil {
+		return nil, ErrNoProfileSelected
+	}
+	if target.ID == current.ID {
+		return nil, ErrSameProfile
+	}
+	if target.ReadOnly() {
+		return nil, models.ErrReadOnlyProfile
+	}
+
+	targetEntries := target.Entries
+	target.Entries = append(targetEntries[:len(targetEntries):len(targetEntries)], selected...)
+	if err := c.profileManager.UpdateProfile(target); err != nil {
+		target.Entries = targetEntries
+		return nil, err
+	}
+
+	entries := current.Entries
+	current.Entries = withoutEntries(entries, selected)
+	if err := c.profileManager.UpdateProfile(current); err != nil {
+		current.Entries = entries
+		target.Entries = targetEntries
+		// 条目已保存到target，尽量撤销，避免两个Profile中出现相同的条目
+		_ = c.profileManager.UpdateProfile(target)
+		return nil, err
+	}
+	c.SelectEntry(nil)
+	return selected, nil
+}
+
+// withoutEntries 返回不包含removed中条目的新切片，不修改entries
+func withoutEntries(entries, removed []*models.HostEntry) []*models.HostEntry {
+	kept := make([]*models.HostEntry, 0, len(entries))
+	for _, entry := range entries {
+		if !slices.Contains(removed, entry) {
+			kept = append(kept, entry)
+		}
+	}
+	return kept
+}
@@ -0,0 +1,340 @@
+// Package controller 保存界面的选择状态并实现Profile和Host条目的编辑、验证与应用流程，
+// 不依赖任何界面库，供GUI、CLI等前端共用。
+package controller
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/flyhigher139/mhost/internal/host"
+	"github.com/flyhigher139/mhost/internal/profile"
+	"github.com/flyhigher139/mhost/pkg/models"
+)
+
+var (
+	// ErrNoProfileSelected 没有选中的Profile
+	ErrNoProfileSelected = errors.New("no profile selected")
+	// ErrNoEntrySelected 没有选中的Host条目
+	ErrNoEntrySelected = errors.New("no host entry selected")
+)
+
+// Controller 界面控制器，保存Profile列表、选中的Profile和Host条目。
+// 列表中的Profile是从profile.Manager加载的副本，修改后通过SaveCurrentProfile保存
+type Controller struct {
+	profileManager profile.Manager
+	hostManager    host.Manager
+
+	mu           sync.RWMutex
+	profiles     []*models.Profile
+	groups       []*models.ProfileGroup
+	tree         *ProfileTree
+	grouping     string
+	filter       models.ProfileQuery
+	current      *models.Profile
+	currentEntry *models.HostEntry
+	selected     map[string]bool
+	entrySort    EntrySort
+}
+
+// New 创建界面控制器
+func New(profileManager profile.Manager, hostManager host.Manager) *Controller {
+	return &Controller{
+		profileManager: profileManager,
+		hostManager:    hostManager,
+	}
+}
+
+// loadProfiles 加载所有Profile的完整内容和Profile文件夹，跳过无法加载的Profile
+func (c *Controller) loadProfiles() ([]*models.Profile, []*models.ProfileGroup, error) {
+	summaries, err := c.profileManager.ListProfiles()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list profiles: %w", err)
+	}
+	groups, err := c.profileManager.ListGroups()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list profile groups: %w", err)
+	}
+
+	profiles := make([]*models.Profile, 0, len(summaries))
+	for _, summary := range summaries {
+		p, err := c.profileManager.GetProfile(summary.ID)
+		if err != nil {
+			continue
+		}
+		profiles = append(profiles, p)
+	}
+	return profiles, groups, nil
+}
+
+// Load 重新加载Profile列表并选中激活的Profile，没有激活的Profile时清空选择
+func (c *Controller) Load() error {
+	profiles, groups, err := c.loadProfiles()
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.profiles = profiles
+	c.groups = groups
+	c.tree = c.buildTree()
+	c.current = nil
+	c.currentEntry = nil
+	for _, p := range profiles {
+		if p.IsActive {
+			c.current = p
+			break
+		}
+	}
+	return nil
+}
+
+// Refresh 重新加载Profile列表，保留当前选中的Profile和Host条目（按ID匹配）
+func (c *Controller) Refresh() error {
+	profiles, groups, err := c.loadProfiles()
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.profiles = profiles
+	c.groups = groups
+	c.tree = c.buildTree()
+	if c.current == nil {
+		return nil
+	}
+
+	var entryID string
+	if c.currentEntry != nil {
+		entryID = c.currentEntry.ID
+	}
+	c.current = findByID(profiles, c.current.ID)
+	c.currentEntry = nil
+	if c.current != nil && entryID != "" {
+		if entry, ok := c.current.GetEntry(entryID); ok {
+			c.currentEntry = entry
+		}
+	}
+	return nil
+}
+
+// findByID 按ID查找Profile
+func findByID(profiles []*models.Profile, id string) *models.Profile {
+	for _, p := range profiles {
+		if p.ID == id {
+			return p
+		}
+	}
+	return nil
+}
+
+// Profiles 获取已加载的Profile列表
+func (c *Controller) Profiles() []*models.Profile {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return append([]*models.Profile(nil), c.profiles...)
+}
+
+// SetGrouping 设置Profile树的分组方式并重新分组
+func (c *Controller) SetGrouping(grouping string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.grouping = grouping
+	c.tree = c.buildTree()
+}
+
+// SetFilter 设置Profile树的搜索条件（参见models.ParseProfileQuery），只显示符合条件的Profile；
+// query为空时显示全部Profile
+func (c *Controller) SetFilter(query string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.filter = models.ParseProfileQuery(query)
+	c.tree = c.buildTree()
+}
+
+// Filtering 是否设置了搜索条件
+func (c *Controller) Filtering() bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return !c.filter.Empty()
+}
+
+// buildTree 按分组方式和搜索条件构建Profile树，搜索时不显示空文件夹（需要持有锁）
+func (c *Controller) buildTree() *ProfileTree {
+	if c.filter.Empty() {
+		return BuildProfileTree(c.profiles, c.groups, c.grouping)
+	}
+	matched := make([]*models.Profile, 0, len(c.profiles))
+	for _, p := range c.profiles {
+		if c.filter.Matches(p) {
+			matched = append(matched, p)
+		}
+	}
+	return BuildProfileTree(matched, nil, c.grouping)
+}
+
+// Tree 获取按当前分组方式分组的Profile树
+func (c *Controller) Tree() *ProfileTree {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if c.tree == nil {
+		return BuildProfileTree(nil, nil, c.grouping)
+	}
+	return c.tree
+}
+
+// ProfileAt 获取列表中指定位置的Profile，越界时返回nil
+func (c *Controller) ProfileAt(index int) *models.Profile {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if index < 0 || index >= len(c.profiles) {
+		return nil
+	}
+	return c.profiles[index]
+}
+
+// ProfileIndex 获取指定ID的Profile在列表中的位置，不存在时返回-1
+func (c *Controller) ProfileIndex(id string) int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	for i, p := range c.profiles {
+		if p.ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// FindProfileByName 按名称查找已加载的Profile
+func (c *Controller) FindProfileByName(name string) *models.Profile {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	for _, p := range c.profiles {
+		if p.Name == name {
+			return p
+		}
+	}
+	return nil
+}
+
+// ResolveProfile 按ID或名称查找已加载的Profile，名称优先完全匹配，其次不区分大小写匹配
+func (c *Controller) ResolveProfile(query string) *models.Profile {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	var folded *models.Profile
+	for _, p := range c.profiles {
+		if p.ID == query || p.Name == query {
+			return p
+		}
+		if folded == nil && strings.EqualFold(p.Name, query) {
+			folded = p
+		}
+	}
+	return folded
+}
+
+// CurrentProfile 获取选中的Profile，未选中时返回nil
+func (c *Controller) CurrentProfile() *models.Profile {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.current
+}
+
+// editableProfile 可以修改条目的选中Profile：没有选中时返回ErrNoProfileSelected，
+// 同步资产清单的只读Profile返回models.ErrReadOnlyProfile
+func (c *Controller) editableProfile() (*models.Profile, error) {
+	current := c.CurrentProfile()
+	if current == nil {
+		return nil, ErrNoProfileSelected
+	}
+	if current.ReadOnly() {
+		return nil, models.ErrReadOnlyProfile
+	}
+	return current, nil
+}
+
+// CurrentEntry 获取选中的Host条目，未选中时返回nil
+func (c *Controller) CurrentEntry() *models.HostEntry {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.currentEntry
+}
+
+// Entries 获取选中Profile的Host条目
+func (c *Controller) Entries() []*models.HostEntry {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if c.current == nil {
+		return nil
+	}
+	return c.current.Entries
+}
+
+// EntryAt 获取选中Profile中指定位置的Host条目，越界时返回nil
+func (c *Controller) EntryAt(index int) *models.HostEntry {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if c.current == nil || index < 0 || index >= len(c.current.Entries) {
+		return nil
+	}
+	return c.current.Entries[index]
+}
+
+// SelectProfile 选中Profile并清空选中的Host条目，p为nil时清空选择
+func (c *Controller) SelectProfile(p *models.Profile) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.current = p
+	c.currentEntry = nil
+	c.selected = nil
+}
+
+// SelectProfileAt 选中列表中指定位置的Profile，越界时不改变选择并返回nil
+func (c *Controller) SelectProfileAt(index int) *models.Profile {
+	p := c.ProfileAt(index)
+	if p != nil {
+		c.SelectProfile(p)
+	}
+	return p
+}
+
+// SelectEntry 只选中一个Host条目，entry为nil时清空选择
+func (c *Controller) SelectEntry(entry *models.HostEntry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.currentEntry = entry
+	c.selected = nil
+	if entry != nil {
+		c.selected = map[string]bool{entry.ID: true}
+	}
+}
+
+// SelectEntryAt 选中当前Profile中指定位置的Host条目，越界时不改变选择并返回nil
+func (c *Controller) SelectEntryAt(index int) *models.HostEntry {
+	entry := c.EntryAt(index)
+	if entry != nil {
+		c.SelectEntry(entry)
+	}
+	return entry
+}
+
+// ClearSelection 清空选中的Profile和Host条目
+func (c *Controller) ClearSelection() {
+	c.SelectProfile(nil)
+}
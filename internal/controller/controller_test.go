@@ -0,0 +1,797 @@
+package controller
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/flyhigher139/mhost/internal/fakes"
+	"github.com/flyhigher139/mhost/internal/host"
+	"github.com/flyhigher139/mhost/internal/profile"
+	"github.com/flyhigher139/mhost/internal/rules"
+	"github.com/flyhigher139/mhost/pkg/hostsfile"
+	"github.com/flyhigher139/mhost/pkg/models"
+)
+
+// newTestController 创建使用内存Profile和hosts管理器的控制器
+func newTestController(t *testing.T) (*Controller, *fakes.ProfileManager, *fakes.HostManager) {
+	t.Helper()
+	profiles := fakes.NewProfileManager(nil)
+	hosts := fakes.NewHostManager(nil, "127.0.0.1 localhost")
+	return New(profiles, hosts), profiles, hosts
+}
+
+// TestLoadAndSelection 测试加载后选中激活的Profile，刷新时按ID保留选择
+func TestLoadAndSelection(t *testing.T) {
+	c, profiles, _ := newTestController(t)
+	dev, err := profiles.CreateProfile("Dev", "")
+	require.NoError(t, err)
+	_, err = profiles.CreateProfile("Test", "")
+	require.NoError(t, err)
+
+	require.NoError(t, c.Load())
+	assert.Len(t, c.Profiles(), 2)
+	require.NotNil(t, c.CurrentProfile())
+	assert.Equal(t, dev.ID, c.CurrentProfile().ID, "active profile is selected")
+	assert.Nil(t, c.ProfileAt(2))
+	assert.Nil(t, c.SelectProfileAt(-1))
+
+	test := c.FindProfileByName("Test")
+	require.NotNil(t, test)
+	c.SelectProfile(test)
+	_, err = c.SaveEntry(nil, EntryInput{Hostname: "api.test", IP: "10.0.0.1", Enabled: true})
+	require.NoError(t, err)
+	entry := c.SelectEntryAt(0)
+	require.NotNil(t, entry)
+
+	require.NoError(t, c.Refresh())
+	require.NotNil(t, c.CurrentProfile())
+	assert.Equal(t, test.ID, c.CurrentProfile().ID)
+	require.NotNil(t, c.CurrentEntry())
+	assert.Equal(t, entry.ID, c.CurrentEntry().ID)
+	assert.Equal(t, c.ProfileIndex(test.ID), c.ProfileIndex(c.CurrentProfile().ID))
+
+	c.SelectProfile(c.FindProfileByName("Dev"))
+	assert.Nil(t, c.CurrentEntry(), "selecting a profile clears the entry")
+	assert.Empty(t, c.Entries())
+}
+
+// TestProfileActions 测试创建、修改、复制和删除Profile时的验证
+func TestProfileActions(t *testing.T) {
+	c, profiles, _ := newTestController(t)
+
+	_, err := c.CreateProfile(ProfileInput{Name: "  "})
+	assert.Error(t, err)
+	p, err := c.CreateProfile(ProfileInput{Name: " Dev ", Description: "development", Folder: " work / / api ", Tags: []string{"dev", " dev", ""}})
+	require.NoError(t, err)
+	assert.Equal(t, "Dev", p.Name)
+	assert.Equal(t, "work/api", p.Folder)
+	assert.Equal(t, []string{"dev"}, p.Tags)
+
+	require.NoError(t, c.UpdateProfileInfo(p, ProfileInput{Name: "Development"}))
+	stored, err := profiles.GetProfile(p.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Development", stored.Name)
+	assert.Empty(t, stored.Folder)
+
+	_, err = c.CopyCurrentProfile("Copy")
+	assert.ErrorIs(t, err, ErrNoProfileSelected)
+
+	require.NoError(t, c.Load())
+	_, err = c.SaveEntry(nil, EntryInput{Hostname: "api.test", IP: "10.0.0.1", Enabled: true})
+	require.NoError(t, err)
+	copied, err := c.CopyCurrentProfile("Copy")
+	require.NoError(t, err)
+	assert.Len(t, copied.Entries, 1)
+
+	// 激活的Profile不能删除，删除失败时保留选择
+	_, err = c.DeleteCurrentProfile()
+	assert.ErrorIs(t, err, models.ErrActiveProfile)
+	assert.NotNil(t, c.CurrentProfile())
+
+	require.NoError(t, c.Refresh())
+	c.SelectProfile(c.FindProfileByName("Copy"))
+	deleted, err := c.DeleteCurrentProfile()
+	require.NoError(t, err)
+	assert.Equal(t, copied.ID, deleted.ID)
+	assert.Nil(t, c.CurrentProfile())
+}
+
+// TestEntryActions 测试保存、切换和删除Host条目
+func TestEntryActions(t *testing.T) {
+	c, profiles, _ := newTestController(t)
+	_, err := c.SaveEntry(nil, EntryInput{Hostname: "api.test", IP: "10.0.0.1"})
+	assert.ErrorIs(t, err, ErrNoProfileSelected)
+
+	_, err = profiles.CreateProfile("Dev", "")
+	require.NoError(t, err)
+	require.NoError(t, c.Load())
+
+	_, err = c.SaveEntry(nil, EntryInput{Hostname: "api.test", IP: "10.0.0.300"})
+	assert.Error(t, err)
+	entry, err := c.SaveEntry(nil, EntryInput{Hostname: "api.test", IP: "10.0.0.1", Enabled: true})
+	require.NoError(t, err)
+
+	_, err = c.ToggleCurrentEntry()
+	assert.ErrorIs(t, err, ErrNoEntrySelected)
+	c.SelectEntry(entry)
+	toggled, err := c.ToggleCurrentEntry()
+	require.NoError(t, err)
+	assert.False(t, toggled.Enabled)
+
+	stored, err := profiles.GetActiveProfile()
+	require.NoError(t, err)
+	require.Len(t, stored.Entries, 1)
+	assert.False(t, stored.Entries[0].Enabled)
+
+	// 保存失败时返回错误
+	profiles.Fail("UpdateProfile", os.ErrPermission)
+	_, err = c.DeleteCurrentEntry()
+	assert.ErrorIs(t, err, os.ErrPermission)
+	profiles.Fail("UpdateProfile", nil)
+
+	_, err = c.DeleteCurrentEntry()
+	require.NoError(t, err)
+	assert.Nil(t, c.CurrentEntry())
+	stored, err = profiles.GetActiveProfile()
+	require.NoError(t, err)
+	assert.Empty(t, stored.Entries)
+}
+
+// TestApply 测试应用后激活Profile、超过规模限制和单条目更新
+func TestApply(t *testing.T) {
+	c, profiles, hosts := newTestController(t)
+	_, err := c.Apply(host.ApplyOptions{})
+	assert.ErrorIs(t, err, ErrNoProfileSelected)
+
+	_, err = profiles.CreateProfile("Dev", "")
+	require.NoError(t, err)
+	test, err := profiles.CreateProfile("Test", "")
+	require.NoError(t, err)
+	require.NoError(t, c.Load())
+
+	assert.Equal(t, test.ID, c.ResolveProfile("test").ID)
+	assert.Equal(t, test.ID, c.ResolveProfile(test.ID).ID)
+	assert.Nil(t, c.ResolveProfile("Staging"))
+
+	c.SelectProfile(c.FindProfileByName("Test"))
+	entry, err := c.SaveEntry(nil, EntryInput{Hostname: "api.test", IP: "10.0.0.1", Enabled: true})
+	require.NoError(t, err)
+
+	hosts.SetLimits(models.LimitsConfig{MaxEntries: 1})
+	_, err = c.Apply(host.ApplyOptions{})
+	var limitErr *host.LimitError
+	require.True(t, errors.As(err, &limitErr))
+	assert.Equal(t, 0, hosts.Writes())
+
+	result, err := c.Apply(host.ApplyOptions{IgnoreLimits: true})
+	require.NoError(t, err)
+	assert.Len(t, result.Added, 1)
+	active, err := profiles.GetActiveProfile()
+	require.NoError(t, err)
+	assert.Equal(t, test.ID, active.ID)
+	for _, p := range c.Profiles() {
+		assert.Equal(t, p.ID == test.ID, p.IsActive, p.Name)
+	}
+
+	entry.IP = "10.0.0.2"
+	require.NoError(t, c.PatchEntry(entry))
+	drift, err := hosts.DetectDrift(c.CurrentProfile())
+	require.NoError(t, err)
+	assert.False(t, drift.HasDrift())
+}
+
+// TestApplyLayers 测试叠加应用多个Profile以及条目变化后重新叠加应用
+func TestApplyLayers(t *testing.T) {
+	c, profiles, hosts := newTestController(t)
+	base, err := profiles.CreateProfile("Base", "")
+	require.NoError(t, err)
+	project, err := profiles.CreateProfile("Project-A", "")
+	require.NoError(t, err)
+	require.NoError(t, c.Load())
+
+	c.SelectProfile(c.FindProfileByName("Base"))
+	_, err = c.SaveEntry(nil, EntryInput{Hostname: "api.test", IP: "10.0.0.1", Enabled: true})
+	require.NoError(t, err)
+	c.SelectProfile(c.FindProfileByName("Project-A"))
+	entry, err := c.SaveEntry(nil, EntryInput{Hostname: "api.test", IP: "10.1.0.1", Enabled: true})
+	require.NoError(t, err)
+
+	result, err := c.ApplyLayers([]string{base.ID, project.ID}, host.ApplyOptions{})
+	require.NoError(t, err)
+	assert.Len(t, result.Conflicts, 1)
+	for _, p := range c.Profiles() {
+		assert.True(t, p.IsActive, p.Name)
+	}
+	layered, err := profile.LayeredActiveProfile(profiles)
+	require.NoError(t, err)
+	drift, err := hosts.DetectDrift(layered)
+	require.NoError(t, err)
+	assert.False(t, drift.HasDrift())
+
+	// 同时激活多个Profile时修改条目会重新叠加应用，而不是只写入单个条目
+	entry.IP = "10.1.0.2"
+	require.NoError(t, profiles.UpdateProfile(c.CurrentProfile()))
+	require.NoError(t, c.PatchEntry(entry))
+	layered, err = profile.LayeredActiveProfile(profiles)
+	require.NoError(t, err)
+	drift, err = hosts.DetectDrift(layered)
+	require.NoError(t, err)
+	assert.False(t, drift.HasDrift())
+	managed, err := hosts.GetManagedSection()
+	require.NoError(t, err)
+	assert.Contains(t, managed, "10.1.0.2\tapi.test")
+}
+
+// TestEntryDefaults 测试Profile的作者和默认注释应用于新条目并记录在管理section头部
+func TestEntryDefaults(t *testing.T) {
+	c, _, hosts := newTestController(t)
+	p, err := c.CreateProfile(ProfileInput{Name: "Infra", Author: " alice ", DefaultComment: "[infra] team=ops"})
+	require.NoError(t, err)
+	assert.Equal(t, "alice", p.Author)
+	require.NoError(t, c.Load())
+	c.SelectProfile(c.FindProfileByName("Infra"))
+
+	entry, err := c.SaveEntry(nil, EntryInput{Hostname: "api.test", IP: "10.0.0.1", Comment: "gateway", Enabled: true})
+	require.NoError(t, err)
+	assert.Equal(t, "[infra] gateway owner=alice team=ops", entry.Comment)
+
+	// 修改已有条目时不再补充默认值
+	_, err = c.SaveEntry(entry, EntryInput{Hostname: "api.test", IP: "10.0.0.1", Comment: "gateway", Enabled: true})
+	require.NoError(t, err)
+	assert.Equal(t, "gateway", entry.Comment)
+
+	_, err = c.Apply(host.ApplyOptions{})
+	require.NoError(t, err)
+	content, err := hosts.ReadHostsFile()
+	require.NoError(t, err)
+	assert.Contains(t, content, "# Author: alice")
+
+	err = c.UpdateProfileInfo(c.CurrentProfile(), ProfileInput{Name: "Infra", Author: strings.Repeat("a", MaxAuthorLength+1)})
+	assert.Error(t, err)
+	err = c.UpdateProfileInfo(c.CurrentProfile(), ProfileInput{Name: "Infra", DefaultComment: "a\nb"})
+	assert.ErrorIs(t, err, models.ErrInvalidProfile)
+}
+
+// TestRestoreProfile 测试将Profile恢复为修改前的副本并保留当前选择
+func TestRestoreProfile(t *testing.T) {
+	c, profiles, _ := newTestController(t)
+	_, err := c.CreateProfile(ProfileInput{Name: "Dev"})
+	require.NoError(t, err)
+	require.NoError(t, c.Load())
+	c.SelectProfile(c.FindProfileByName("Dev"))
+
+	entry, err := c.SaveEntry(nil, EntryInput{Hostname: "api.test", IP: "10.0.0.1", Enabled: true})
+	require.NoError(t, err)
+	before := c.CurrentProfile().Clone()
+
+	c.SelectEntry(entry)
+	_, err = c.ToggleCurrentEntry()
+	require.NoError(t, err)
+	require.NoError(t, c.UpdateProfileInfo(c.CurrentProfile(), ProfileInput{Name: "Renamed"}))
+
+	require.NoError(t, c.RestoreProfile(before))
+	current := c.CurrentProfile()
+	require.NotNil(t, current)
+	assert.Equal(t, "Dev", current.Name)
+	require.Len(t, current.Entries, 1)
+	assert.True(t, current.Entries[0].Enabled)
+	require.NotNil(t, c.CurrentEntry())
+	assert.Equal(t, entry.ID, c.CurrentEntry().ID)
+
+	stored, err := profiles.GetProfile(before.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Dev", stored.Name)
+
+	// 恢复后修改副本不影响保存的Profile
+	before.Name = "Changed"
+	assert.Equal(t, "Dev", c.CurrentProfile().Name)
+
+	assert.ErrorIs(t, c.RestoreProfile(nil), models.ErrInvalidProfile)
+}
+
+// TestDuplicateHostnames 测试主机名重复的检测、保存时的拒绝和只保留最后一个条目的清理
+func TestDuplicateHostnames(t *testing.T) {
+	c, profiles, _ := newTestController(t)
+
+	// 旧版本保存的Profile可能已包含重复的主机名
+	legacy := models.NewProfile("Legacy", "")
+	first := models.NewHostEntry("10.0.0.1", "api.test", "")
+	second := models.NewHostEntry("10.0.0.2", "API.test", "")
+	v6 := models.NewHostEntry("fd00::1", "api.test", "")
+	spare := models.NewHostEntry("10.0.0.3", "api.test", "")
+	spare.Enabled = false
+	web := models.NewHostEntry("10.0.0.4", "web.test", "")
+	legacy.Entries = []*models.HostEntry{first, web, second, v6, spare}
+	profiles.Add(legacy, false)
+
+	duplicates := legacy.DuplicateHostnames()
+	require.Len(t, duplicates, 1, "IPv6 and disabled entries are not duplicates")
+	assert.Equal(t, "api.test", duplicates[0].Hostname)
+	assert.Equal(t, []*models.HostEntry{first, second}, duplicates[0].Entries)
+	err := legacy.Validate()
+	assert.ErrorIs(t, err, models.ErrDuplicateHostname)
+	assert.EqualError(t, err, "duplicate hostname: api.test")
+
+	require.NoError(t, c.Load())
+	c.SelectProfile(c.FindProfileByName("Legacy"))
+	c.SelectEntry(c.CurrentProfile().Entries[0])
+	removed, err := c.RemoveDuplicateHostnames()
+	require.NoError(t, err)
+	require.Len(t, removed, 1)
+	assert.Equal(t, first.ID, removed[0].ID)
+	assert.Nil(t, c.CurrentEntry())
+
+	stored, err := profiles.GetProfile(legacy.ID)
+	require.NoError(t, err)
+	require.Len(t, stored.Entries, 4)
+	assert.Equal(t, []string{web.ID, second.ID, v6.ID, spare.ID},
+		[]string{stored.Entries[0].ID, stored.Entries[1].ID, stored.Entries[2].ID, stored.Entries[3].ID})
+
+	removed, err = c.RemoveDuplicateHostnames()
+	require.NoError(t, err)
+	assert.Empty(t, removed)
+
+	// 新建或修改条目时拒绝重复的主机名，禁用的条目不受限制
+	_, err = c.SaveEntry(nil, EntryInput{Hostname: "web.test", IP: "10.0.0.9", Enabled: true})
+	assert.ErrorContains(t, err, "web.test")
+	_, err = c.SaveEntry(nil, EntryInput{Hostname: "web.test", IP: "10.0.0.9"})
+	require.NoError(t, err)
+	c.SelectEntry(c.CurrentProfile().Entries[1])
+	_, err = c.SaveEntry(c.CurrentEntry(), EntryInput{Hostname: "api.test", IP: "10.0.0.5", Enabled: true})
+	require.NoError(t, err, "an entry does not conflict with itself")
+
+	// 启用与其他条目重复的备用条目失败时保持禁用
+	c.SelectEntry(c.CurrentProfile().Entries[3])
+	_, err = c.ToggleCurrentEntry()
+	assert.ErrorIs(t, err, models.ErrDuplicateHostname)
+	assert.False(t, c.CurrentProfile().Entries[3].Enabled)
+}
+
+// TestPastedEntries 测试粘贴hosts文本批量添加条目
+func TestPastedEntries(t *testing.T) {
+	c, profiles, _ := newTestController(t)
+	dev, err := profiles.CreateProfile("Dev", "")
+	require.NoError(t, err)
+	require.NoError(t, c.Load())
+	c.SelectProfile(c.FindProfileByName("Dev"))
+	_, err = c.SaveEntry(nil, EntryInput{Hostname: "web.test", IP: "10.0.0.9", Enabled: true})
+	require.NoError(t, err)
+
+	text := "# staging\r\n" +
+		"10.0.0.1 API.test. admin.test # staging\n" +
+		"\n" +
+		"# 10.0.0.2 spare.test\n" +
+		"10.0.0.3 web.test\n" +
+		"10.0.0.4 api.test\n" +
+		"not-a-host-line\n" +
+		"999.0.0.1 bad.test\n"
+	pasted, err := c.ParsePastedEntries(text, hostsfile.DefaultNormalizer)
+	require.NoError(t, err)
+	require.Len(t, pasted, 7)
+
+	assert.Equal(t, 2, pasted[0].Line)
+	assert.Equal(t, EntryInput{Hostname: "api.test", IP: "10.0.0.1", Comment: "staging", Enabled: true}, pasted[0].Input)
+	assert.NoError(t, pasted[0].Err)
+	assert.Equal(t, "admin.test", pasted[1].Input.Hostname)
+	assert.NoError(t, pasted[1].Err)
+	assert.False(t, pasted[2].Input.Enabled, "commented-out entries are added disabled")
+	assert.NoError(t, pasted[2].Err)
+	assert.ErrorContains(t, pasted[3].Err, "web.test", "conflicts with an existing entry")
+	assert.ErrorContains(t, pasted[4].Err, "第2行", "conflicts with an earlier line")
+	assert.Equal(t, "not-a-host-line", pasted[5].Text)
+	assert.Error(t, pasted[5].Err)
+	assert.Error(t, pasted[6].Err)
+	assert.Equal(t, 3, ValidPastedEntries(pasted))
+
+	added, err := c.AddPastedEntries(pasted)
+	require.NoError(t, err)
+	require.Len(t, added, 3)
+	stored, err := profiles.GetProfile(dev.ID)
+	require.NoError(t, err)
+	require.Len(t, stored.Entries, 4)
+	assert.Equal(t, []string{"web.test", "api.test", "admin.test", "spare.test"},
+		[]string{stored.Entries[0].Hostname, stored.Entries[1].Hostname, stored.Entries[2].Hostname, stored.Entries[3].Hostname})
+
+	// 再次粘贴相同内容时所有条目都与已有条目重复
+	pasted, err = c.ParsePastedEntries(text, hostsfile.DefaultNormalizer)
+	require.NoError(t, err)
+	assert.Zero(t, ValidPastedEntries(pasted))
+	assert.ErrorContains(t, pasted[2].Err, "已存在", "disabled entries are not added twice")
+}
+
+// TestValidationRules 测试保存条目、粘贴和应用时检查自定义校验规则
+func TestValidationRules(t *testing.T) {
+	c, profiles, hosts := newTestController(t)
+	_, err := profiles.CreateProfile("Dev", "")
+	require.NoError(t, err)
+	require.NoError(t, c.Load())
+	c.SelectProfile(c.FindProfileByName("Dev"))
+
+	// 设置规则之前保存的条目在应用时检查
+	_, err = c.SaveEntry(nil, EntryInput{Hostname: "dns.test", IP: "8.8.8.8", Enabled: true})
+	require.NoError(t, err)
+
+	set, err := rules.Parse([]byte(`
+rules:
+  - name: no-public-dns
+    banned_ips: ["8.8.8.0/24"]
+  - name: ticket
+    severity: warning
+    comment: 'ticket='
+`))
+	require.NoError(t, err)
+	hosts.SetRules(set)
+
+	_, err = c.SaveEntry(nil, EntryInput{Hostname: "dns2.test", IP: "8.8.8.4", Enabled: true})
+	assert.ErrorIs(t, err, rules.ErrRuleViolation)
+	assert.Len(t, c.CurrentProfile().Entries, 1)
+	saved, err := c.SaveEntry(nil, EntryInput{Hostname: "api.test", IP: "10.0.0.1", Enabled: true})
+	require.NoError(t, err, "warnings do not block saving")
+	violations := c.EntryRuleViolations(saved)
+	require.Len(t, violations, 1)
+	assert.Equal(t, "ticket", violations[0].Rule)
+
+	pasted, err := c.ParsePastedEntries("8.8.8.5 dns3.test\n10.0.0.2 web.test # ticket=OPS-1\n", hostsfile.DefaultNormalizer)
+	require.NoError(t, err)
+	assert.ErrorIs(t, pasted[0].Err, rules.ErrRuleViolation)
+	assert.NoError(t, pasted[1].Err)
+
+	preview, err := c.PreviewApply()
+	require.NoError(t, err)
+	assert.Len(t, preview.RuleViolations, 3)
+
+	_, err = c.Apply(host.ApplyOptions{IgnoreLimits: true})
+	assert.ErrorIs(t, err, rules.ErrRuleViolation, "limits overrides do not bypass rules")
+	assert.Equal(t, 0, hosts.Writes())
+	assert.ErrorIs(t, c.PatchEntry(c.CurrentProfile().Entries[0]), rules.ErrRuleViolation)
+	assert.Equal(t, 0, hosts.Writes())
+
+	c.SelectEntry(c.CurrentProfile().Entries[0])
+	_, err = c.ToggleCurrentEntry()
+	require.NoError(t, err)
+	result, err := c.Apply(host.ApplyOptions{})
+	require.NoError(t, err)
+	assert.Contains(t, strings.Join(result.Warnings, "\n"), "[ticket]")
+}
+
+// TestGlobalEntries 测试全局条目在切换Profile后仍然写入hosts文件
+func TestGlobalEntries(t *testing.T) {
+	c, profiles, hosts := newTestController(t)
+	hosts.SetGlobalSource(profiles.GetGlobalProfile)
+
+	_, err := profiles.CreateProfile("Dev", "")
+	require.NoError(t, err)
+	_, err = profiles.CreateProfile("Test", "")
+	require.NoError(t, err)
+	require.NoError(t, c.Load())
+
+	_, err = c.MakeCurrentEntryGlobal()
+	assert.ErrorIs(t, err, ErrNoEntrySelected)
+
+	_, err = c.SaveEntry(nil, EntryInput{Hostname: "license.corp", IP: "10.0.0.9", Enabled: true})
+	require.NoError(t, err)
+	_, err = c.SaveEntry(nil, EntryInput{Hostname: "api.test", IP: "10.0.0.1", Enabled: true})
+	require.NoError(t, err)
+	c.SelectEntryAt(0)
+	entry, err := c.MakeCurrentEntryGlobal()
+	require.NoError(t, err)
+	assert.Equal(t, "license.corp", entry.Hostname)
+	assert.Len(t, c.Entries(), 1)
+	assert.Nil(t, c.CurrentEntry())
+
+	global, err := c.GlobalEntries()
+	require.NoError(t, err)
+	require.Len(t, global, 1)
+
+	// 切换到另一个Profile后全局条目仍然写入，Profile中同名的条目被全局条目覆盖
+	c.SelectProfile(c.FindProfileByName("Test"))
+	_, err = c.SaveEntry(nil, EntryInput{Hostname: "license.corp", IP: "10.9.9.9", Enabled: true})
+	require.NoError(t, err)
+	result, err := c.Apply(host.ApplyOptions{})
+	require.NoError(t, err)
+	require.Len(t, result.Added, 1)
+	assert.Equal(t, "10.0.0.9", result.Added[0].IP)
+
+	drift, err := hosts.DetectDrift(c.CurrentProfile())
+	require.NoError(t, err)
+	assert.False(t, drift.HasDrift())
+
+	removed, err := c.RemoveGlobalEntry(global[0].ID, false)
+	require.NoError(t, err)
+	assert.Equal(t, "license.corp", removed.Hostname)
+	_, err = c.RemoveGlobalEntry(global[0].ID, false)
+	assert.ErrorIs(t, err, models.ErrHostEntryNotFound)
+
+	result, err = c.ReapplyActive()
+	require.NoError(t, err)
+	require.Len(t, result.Changed, 1)
+	assert.Equal(t, "10.9.9.9", result.Changed[0].Expected.IP)
+}
+
+// TestProfileTree 测试按文件夹和标签分组的Profile树
+func TestProfileTree(t *testing.T) {
+	newProfile := func(id, folder string, tags ...string) *models.Profile {
+		p := models.NewProfile(id, "")
+		p.ID = id
+		p.Folder = folder
+		p.Tags = tags
+		return p
+	}
+	profiles := []*models.Profile{
+		newProfile("api", "work/clients", "dev", "shared"),
+		newProfile("local", ""),
+		newProfile("web", "work", "dev"),
+		newProfile("blog", "personal"),
+	}
+
+	tree := BuildProfileTree(profiles, nil, models.ProfileGroupingFolder)
+	assert.Equal(t, []string{"folder:personal", "folder:work", ">local"}, tree.Children(RootNodeID))
+	assert.Equal(t, []string{"folder:work/clients", "folder:work>web"}, tree.Children("folder:work"))
+	assert.Equal(t, "clients", tree.Label("folder:work/clients"))
+	assert.Equal(t, 2, tree.Count("folder:work"))
+	assert.Equal(t, 4, tree.Count(RootNodeID))
+	assert.True(t, tree.IsBranch("folder:work"))
+	assert.False(t, tree.IsBranch(">local"))
+	assert.Equal(t, []string{"folder:personal", "folder:work", "folder:work/clients"}, tree.Branches())
+
+	node, ok := tree.NodeFor("api")
+	require.True(t, ok)
+	assert.Equal(t, "api", tree.Profile(node).ID)
+	assert.Equal(t, []string{"folder:work", "folder:work/clients"}, tree.Ancestors(node))
+	_, ok = tree.NodeFor("missing")
+	assert.False(t, ok)
+
+	// 按标签分组时有多个标签的Profile出现在每个标签下
+	tree = BuildProfileTree(profiles, nil, models.ProfileGroupingTag)
+	assert.Equal(t, []string{"tag:dev", "tag:shared", ">local", ">blog"}, tree.Children(RootNodeID))
+	assert.Equal(t, []string{"tag:dev>api", "tag:dev>web"}, tree.Children("tag:dev"))
+	assert.Equal(t, []string{"tag:shared>api"}, tree.Children("tag:shared"))
+	assert.Equal(t, []string{"a", "b"}, ParseTags(" a, b ,,a"))
+}
+
+// TestProfileGroups 测试空文件夹显示在Profile树中，以及文件夹的新建、重命名和删除
+func TestProfileGroups(t *testing.T) {
+	c, profiles, _ := newTestController(t)
+	p, err := profiles.CreateProfile("API", "")
+	require.NoError(t, err)
+	_, err = c.CreateGroup("", "work")
+	require.NoError(t, err)
+	c.SelectProfile(p)
+	require.NoError(t, c.UpdateProfileInfo(p, ProfileInput{Name: "API", Folder: "work"}))
+
+	_, err = c.CreateGroup("work", "a/b")
+	assert.Error(t, err)
+	_, err = c.CreateGroup("work", "staging")
+	require.NoError(t, err)
+
+	tree := c.Tree()
+	assert.Equal(t, []string{"folder:work"}, tree.Children(RootNodeID))
+	assert.Equal(t, []string{"folder:work/staging", "folder:work>" + p.ID}, tree.Children("folder:work"))
+	folder, ok := tree.Folder("folder:work/staging")
+	assert.True(t, ok)
+	assert.Equal(t, "work/staging", folder)
+	_, ok = tree.Folder("folder:work>" + p.ID)
+	assert.False(t, ok)
+
+	newPath, err := c.RenameGroup("work", "projects")
+	require.NoError(t, err)
+	assert.Equal(t, "projects", newPath)
+	assert.Equal(t, "projects", c.CurrentProfile().Folder)
+	assert.True(t, c.Tree().IsBranch("folder:projects/staging"))
+
+	require.NoError(t, c.DeleteGroup("projects"))
+	assert.Equal(t, []string{"folder:staging", ">" + p.ID}, c.Tree().Children(RootNodeID))
+
+	// 保存的展开状态随文件夹移动
+	assert.Equal(t, []string{"tag:dev", "folder:projects", "folder:projects/staging"},
+		MoveFolderNodes([]string{"tag:dev", "folder:work", "folder:work/staging"}, "work", "projects"))
+	assert.Equal(t, []string{"folder:staging"}, MoveFolderNodes([]string{"folder:work", "folder:work/staging"}, "work", ""))
+}
+
+// TestProfileFilter 测试按搜索条件筛选Profile树以及标签的修改
+func TestProfileFilter(t *testing.T) {
+	c, profiles, _ := newTestController(t)
+	api, err := c.CreateProfile(ProfileInput{Name: "API", Folder: "work", Tags: []string{"prod", "backend"}})
+	require.NoError(t, err)
+	web, err := c.CreateProfile(ProfileInput{Name: "Web", Folder: "work", Tags: []string{"dev"}})
+	require.NoError(t, err)
+	_, err = profiles.CreateGroup("empty")
+	require.NoError(t, err)
+	require.NoError(t, c.Load())
+
+	c.SetFilter("tag:prod")
+	assert.True(t, c.Filtering())
+	assert.Equal(t, []string{"folder:work"}, c.Tree().Children(RootNodeID))
+	assert.Equal(t, []string{"folder:work>" + api.ID}, c.Tree().Children("folder:work"))
+
+	// 刷新后保留搜索条件
+	require.NoError(t, c.AddTag(web, "prod"))
+	assert.Len(t, c.Tree().Children("folder:work"), 2)
+	changed, err := c.RenameTag("prod", "production")
+	require.NoError(t, err)
+	assert.Equal(t, 2, changed)
+	assert.Empty(t, c.Tree().Children(RootNodeID))
+
+	c.SetFilter("")
+	assert.False(t, c.Filtering())
+	assert.Equal(t, []string{"folder:empty", "folder:work"}, c.Tree().Children(RootNodeID))
+
+	require.NoError(t, c.RemoveTag(api, "backend"))
+	changed, err = c.DeleteTag("production")
+	require.NoError(t, err)
+	assert.Equal(t, 2, changed)
+	tags, err := profiles.ListTags()
+	require.NoError(t, err)
+	assert.Equal(t, []*models.TagSummary{{Name: "dev", Count: 1}}, tags)
+}
+
+// TestSortedEntries 测试按列排序Host条目，不改变Profile中的顺序
+func TestSortedEntries(t *testing.T) {
+	c, profiles, _ := newTestController(t)
+	_, err := profiles.CreateProfile("Dev", "")
+	require.NoError(t, err)
+	require.NoError(t, c.Load())
+
+	base := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i, input := range []struct{ ip, hostname, comment string }{
+		{"10.0.0.10", "web.test", "b"},
+		{"10.0.0.9", "API.test", "a"},
+		{"::1", "v6.test", ""},
+		{"10.0.0.9", "db.test", "C"},
+	} {
+		entry := models.NewHostEntry(input.ip, input.hostname, input.comment)
+		entry.Enabled = i != 2
+		entry.UpdatedAt = base.Add(time.Duration(3-i) * time.Hour)
+		c.CurrentProfile().AddEntry(entry)
+	}
+	hostnames := func() []string {
+		var names []string
+		for _, entry := range c.SortedEntries() {
+			names = append(names, entry.Hostname)
+		}
+		return names
+	}
+
+	assert.Equal(t, []string{"web.test", "API.test", "v6.test", "db.test"}, hostnames(), "unsorted keeps profile order")
+
+	c.SetEntrySort(EntrySort{Column: EntryColumnIP})
+	assert.Equal(t, []string{"API.test", "db.test", "web.test", "v6.test"}, hostnames(), "IPs compare numerically and equal IPs keep their order")
+	c.SetEntrySort(EntrySort{Column: EntryColumnHostname, Descending: true})
+	assert.Equal(t, []string{"web.test", "v6.test", "db.test", "API.test"}, hostnames())
+	c.SetEntrySort(EntrySort{Column: EntryColumnComment})
+	assert.Equal(t, []string{"v6.test", "API.test", "web.test", "db.test"}, hostnames())
+	c.SetEntrySort(EntrySort{Column: EntryColumnEnabled})
+	assert.Equal(t, "v6.test", hostnames()[0])
+	c.SetEntrySort(EntrySort{Column: EntryColumnUpdated})
+	assert.Equal(t, []string{"db.test", "v6.test", "API.test", "web.test"}, hostnames())
+	assert.Equal(t, "web.test", c.CurrentProfile().Entries[0].Hostname, "profile order is unchanged")
+
+	// 点击同一列依次切换升序、降序和不排序
+	sort := EntrySort{}.Next(EntryColumnIP)
+	assert.Equal(t, EntrySort{Column: EntryColumnIP}, sort)
+	sort = sort.Next(EntryColumnIP)
+	assert.Equal(t, EntrySort{Column: EntryColumnIP, Descending: true}, sort)
+	assert.Equal(t, EntrySort{}, sort.Next(EntryColumnIP))
+	assert.Equal(t, EntrySort{Column: EntryColumnHostname}, sort.Next(EntryColumnHostname))
+}
+
+// TestBatchEntryActions 测试多选条目以及批量启用、修改IP、移动和删除
+func TestBatchEntryActions(t *testing.T) {
+	c, profiles, _ := newTestController(t)
+	_, err := profiles.CreateProfile("Dev", "")
+	require.NoError(t, err)
+	_, err = profiles.CreateProfile("Test", "")
+	require.NoError(t, err)
+	require.NoError(t, c.Load())
+
+	var entries []*models.HostEntry
+	for _, hostname := range []string{"a.test", "b.test", "c.test", "d.test"} {
+		entry, err := c.SaveEntry(nil, EntryInput{Hostname: hostname, IP: "10.0.0.1"})
+		require.NoError(t, err)
+		entries = append(entries, entry)
+	}
+	_, err = c.SetSelectedEntriesEnabled(true)
+	assert.ErrorIs(t, err, ErrNoEntrySelected)
+
+	// 按显示顺序选择范围，起点保持不变
+	view := []*models.HostEntry{entries[3], entries[2], entries[1], entries[0]}
+	c.SelectEntry(entries[2])
+	c.SelectEntryRange(view, entries[0])
+	assert.Equal(t, entries[:3], c.SelectedEntries())
+	c.SelectEntryRange(view, entries[3])
+	assert.Equal(t, entries[2:], c.SelectedEntries())
+	assert.Equal(t, entries[2], c.CurrentEntry())
+	c.ToggleEntrySelection(entries[0])
+	c.ToggleEntrySelection(entries[3])
+	assert.Equal(t, []*models.HostEntry{entries[0], entries[2]}, c.SelectedEntries())
+	assert.True(t, c.IsEntrySelected(entries[0]))
+	assert.False(t, c.IsEntrySelected(entries[1]))
+
+	changed, err := c.SetSelectedEntriesEnabled(true)
+	require.NoError(t, err)
+	assert.Len(t, changed, 2)
+	stored, err := profiles.GetActiveProfile()
+	require.NoError(t, err)
+	assert.True(t, stored.Entries[0].Enabled)
+	assert.False(t, stored.Entries[1].Enabled)
+
+	// 保存失败时恢复原来的IP
+	profiles.Fail("UpdateProfile", os.ErrPermission)
+	_, err = c.ChangeSelectedEntriesIP("10.0.0.2")
+	assert.ErrorIs(t, err, os.ErrPermission)
+	assert.Equal(t, "10.0.0.1", entries[0].IP)
+	profiles.Fail("UpdateProfile", nil)
+	_, err = c.ChangeSelectedEntriesIP("10.0.0.300")
+	assert.Error(t, err)
+	changed, err = c.ChangeSelectedEntriesIP("10.0.0.2")
+	require.NoError(t, err)
+	assert.Len(t, changed, 2)
+	assert.Equal(t, "10.0.0.2", entries[2].IP)
+
+	test := c.FindProfileByName("Test")
+	require.NotNil(t, test)
+	_, err = c.MoveSelectedEntries(c.CurrentProfile())
+	assert.ErrorIs(t, err, ErrSameProfile)
+	moved, err := c.MoveSelectedEntries(test)
+	require.NoError(t, err)
+	assert.Len(t, moved, 2)
+	assert.Empty(t, c.SelectedEntries())
+	assert.Equal(t, []*models.HostEntry{entries[1], entries[3]}, c.CurrentProfile().Entries)
+	storedTest, err := profiles.GetProfile(test.ID)
+	require.NoError(t, err)
+	require.Len(t, storedTest.Entries, 2)
+	assert.Equal(t, "a.test", storedTest.Entries[0].Hostname)
+
+	c.SelectEntry(entries[1])
+	c.ToggleEntrySelection(entries[3])
+	deleted, err := c.DeleteSelectedEntries()
+	require.NoError(t, err)
+	assert.Len(t, deleted, 2)
+	assert.Nil(t, c.CurrentEntry())
+	stored, err = profiles.GetActiveProfile()
+	require.NoError(t, err)
+	assert.Empty(t, stored.Entries)
+}
+
+// TestReadOnlyProfile 测试同步资产清单的Profile不能修改条目，也不能作为移动条目的目标
+func TestReadOnlyProfile(t *testing.T) {
+	c, profiles, _ := newTestController(t)
+	_, err := profiles.CreateProfile("Dev", "")
+	require.NoError(t, err)
+	cmdb, err := profiles.CreateProfile("CMDB", "")
+	require.NoError(t, err)
+	cmdb.AddEntry(models.NewHostEntry("10.0.0.1", "web-1.corp.test", ""))
+	cmdb.Inventory = &models.Inventory{URL: "http://cmdb.test/api/machines"}
+	require.NoError(t, profiles.SyncInventoryProfile(cmdb))
+	require.NoError(t, c.Load())
+
+	entry, err := c.SaveEntry(nil, EntryInput{Hostname: "api.test", IP: "10.0.0.2", Enabled: true})
+	require.NoError(t, err)
+	c.SelectEntry(entry)
+	_, err = c.MoveSelectedEntries(c.FindProfileByName("CMDB"))
+	assert.ErrorIs(t, err, models.ErrReadOnlyProfile)
+
+	c.SelectProfile(c.FindProfileByName("CMDB"))
+	_, err = c.SaveEntry(nil, EntryInput{Hostname: "api.test", IP: "10.0.0.2", Enabled: true})
+	assert.ErrorIs(t, err, models.ErrReadOnlyProfile)
+	c.SelectEntryAt(0)
+	_, err = c.ToggleCurrentEntry()
+	assert.ErrorIs(t, err, models.ErrReadOnlyProfile)
+	_, err = c.DeleteCurrentEntry()
+	assert.ErrorIs(t, err, models.ErrReadOnlyProfile)
+	_, err = c.DeleteSelectedEntries()
+	assert.ErrorIs(t, err, models.ErrReadOnlyProfile)
+	_, err = c.MakeCurrentEntryGlobal()
+	assert.ErrorIs(t, err, models.ErrReadOnlyProfile)
+
+	stored, err := profiles.GetProfile(cmdb.ID)
+	require.NoError(t, err)
+	require.Len(t, stored.Entries, 1)
+	assert.True(t, stored.Entries[0].Enabled)
+}
@@ -0,0 +1,129 @@
+package controller
+
+import (
+	"cmp"
+	"net/netip"
+	"slices"
+	"strings"
+
+	"github.com/flyhigher139/mhost/pkg/models"
+)
+
+// EntryColumn Host条目表格的列，取值同时用作配置中保存的列名
+type EntryColumn string
+
+const (
+	EntryColumnEnabled  EntryColumn = "enabled"
+	EntryColumnIP       EntryColumn = "ip"
+	EntryColumnHostname EntryColumn = "hostname"
+	EntryColumnComment  EntryColumn = "comment"
+	EntryColumnUpdated  EntryColumn = "updated"
+)
+
+// EntryColumns Host条目表格的列，按显示顺序排列
+var EntryColumns = []EntryColumn{EntryColumnEnabled, EntryColumnIP, EntryColumnHostname, EntryColumnComment, EntryColumnUpdated}
+
+// EntrySort Host条目的排序方式，Column为空时按Profile中的顺序显示
+type EntrySort struct {
+	Column     EntryColumn
+	Descending bool
+}
+
+// Next 点击column列标题后的排序方式：依次为升序、降序和Profile中的顺序，点击其他列时从升序开始
+func (s EntrySort) Next(column EntryColumn) EntrySort {
+	switch {
+	case s.Column != column:
+		return EntrySort{Column: column}
+	case !s.Descending:
+		return EntrySort{Column: column, Descending: true}
+	default:
+		return EntrySort{}
+	}
+}
+
+// SetEntrySort 设置Host条目的排序方式
+func (c *Controller) SetEntrySort(sort EntrySort) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entrySort = sort
+}
+
+// EntrySort 获取Host条目的排序方式
+func (c *Controller) EntrySort() EntrySort {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.entrySort
+}
+
+// SortedEntries 按排序方式排列的选中Profile的Host条目，返回新的切片，不改变Profile中的顺序
+func (c *Controller) SortedEntries() []*models.HostEntry {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if c.current == nil {
+		return nil
+	}
+	return SortEntries(c.current.Entries, c.entrySort)
+}
+
+// SortEntries 按排序方式排列条目的副本；值相同的条目保持原来的相对顺序
+func SortEntries(entries []*models.HostEntry, sort EntrySort) []*models.HostEntry {
+	sorted := slices.Clone(entries)
+	if sort.Column == "" {
+		return sorted
+	}
+	slices.SortStableFunc(sorted, func(a, b *models.HostEntry) int {
+		result := compareEntries(a, b, sort.Column)
+		if sort.Descending {
+			return -result
+		}
+		return result
+	})
+	return sorted
+}
+
+// compareEntries 按列比较两个条目：IP按地址数值比较，主机名和注释不区分大小写，禁用的条目排在启用的条目之前
+func compareEntries(a, b *models.HostEntry, column EntryColumn) int {
+	switch column {
+	case EntryColumnEnabled:
+		return compareBool(a.Enabled, b.Enabled)
+	case EntryColumnIP:
+		return compareIP(a.IP, b.IP)
+	case EntryColumnHostname:
+		return strings.Compare(strings.ToLower(a.Hostname), strings.ToLower(b.Hostname))
+	case EntryColumnComment:
+		return strings.Compare(strings.ToLower(a.Comment), strings.ToLower(b.Comment))
+	case EntryColumnUpdated:
+		return a.UpdatedAt.Compare(b.UpdatedAt)
+	default:
+		return 0
+	}
+}
+
+// compareBool false排在true之前
+func compareBool(a, b bool) int {
+	switch {
+	case a == b:
+		return 0
+	case a:
+		return 1
+	default:
+		return -1
+	}
+}
+
+// compareIP 按地址比较IP，IPv4排在IPv6之前；无法解析的地址排在最后并按文本比较
+func compareIP(a, b string) int {
+	addrA, errA := netip.ParseAddr(a)
+	addrB, errB := netip.ParseAddr(b)
+	switch {
+	case errA == nil && errB == nil:
+		return addrA.Unmap().Compare(addrB.Unmap())
+	case errA == nil:
+		return -1
+	case errB == nil:
+		return 1
+	default:
+		return cmp.Compare(a, b)
+	}
+}
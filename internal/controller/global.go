@@ -0,0 +1,111 @@
+package controller
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/flyhigher139/mhost/internal/host"
+	"github.com/flyhigher139/mhost/pkg/models"
+)
+
+// GlobalEntries 获取全局条目，全局条目在应用任何Profile时都会写入hosts文件
+func (c *Controller) GlobalEntries() ([]*models.HostEntry, error) {
+	global, err := c.profileManager.GetGlobalProfile()
+	if err != nil {
+		return nil, err
+	}
+	return global.Entries, nil
+}
+
+// MakeCurrentEntryGlobal 将选中的Host条目从选中的Profile移到全局条目，
+// 已有主机名相同的全局条目时替换该条目。返回移动的条目
+func (c *Controller) MakeCurrentEntryGlobal() (*models.HostEntry, error) {
+	current, err := c.editableProfile()
+	if err != nil {
+		return nil, err
+	}
+	entry := c.CurrentEntry()
+	if entry == nil {
+		return nil, ErrNoEntrySelected
+	}
+
+	global, err := c.profileManager.GetGlobalProfile()
+	if err != nil {
+		return nil, err
+	}
+	for _, existing := range global.Entries {
+		if strings.EqualFold(existing.Hostname, entry.Hostname) {
+			global.RemoveEntry(existing.ID)
+			break
+		}
+	}
+	global.AddEntry(entry)
+	if err := c.profileManager.UpdateGlobalProfile(global); err != nil {
+		return nil, err
+	}
+
+	current.RemoveEntry(entry.ID)
+	if err := c.profileManager.UpdateProfile(current); err != nil {
+		return nil, err
+	}
+	c.SelectEntry(nil)
+	return entry, nil
+}
+
+// RemoveGlobalEntry 删除全局条目；toCurrent为true时将其移回选中的Profile。返回删除的条目
+func (c *Controller) RemoveGlobalEntry(id string, toCurrent bool) (*models.HostEntry, error) {
+	var current *models.Profile
+	if toCurrent {
+		var err error
+		if current, err = c.editableProfile(); err != nil {
+			return nil, err
+		}
+	}
+
+	global, err := c.profileManager.GetGlobalProfile()
+	if err != nil {
+		return nil, err
+	}
+	entry, ok := global.GetEntry(id)
+	if !ok {
+		return nil, models.ErrHostEntryNotFound
+	}
+	global.RemoveEntry(id)
+	if err := c.profileManager.UpdateGlobalProfile(global); err != nil {
+		return nil, err
+	}
+
+	if toCurrent {
+		current.AddEntry(entry)
+		if err := c.profileManager.UpdateProfile(current); err != nil {
+			return nil, err
+		}
+	}
+	return entry, nil
+}
+
+// shadowedByGlobal 条目是否被同名的启用的全局条目覆盖
+func (c *Controller) shadowedByGlobal(entry *models.HostEntry) (bool, error) {
+	entries, err := c.GlobalEntries()
+	if err != nil {
+		return false, err
+	}
+	for _, global := range entries {
+		if global.Enabled && strings.EqualFold(global.Hostname, entry.Hostname) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// ReapplyActive 全局条目或激活Profile的内容变化后重新叠加应用激活的全部Profile，没有激活的Profile时返回nil
+func (c *Controller) ReapplyActive() (*host.ApplyResult, error) {
+	active, err := c.profileManager.GetActiveProfiles()
+	if errors.Is(err, models.ErrProfileNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return c.hostManager.ApplyProfiles(active, host.ApplyOptions{})
+}
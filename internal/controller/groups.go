@@ -0,0 +1,52 @@
+package controller
+
+import (
+	"strings"
+
+	"github.com/flyhigher139/mhost/pkg/models"
+)
+
+// CreateGroup 在parent文件夹下创建名为name的空文件夹，parent为空时创建在顶层
+func (c *Controller) CreateGroup(parent, name string) (*models.ProfileGroup, error) {
+	name = strings.TrimSpace(name)
+	if err := ValidateGroupName(name); err != nil {
+		return nil, err
+	}
+
+	group, err := c.profileManager.CreateGroup(joinFolder(parent, name))
+	if err != nil {
+		return nil, err
+	}
+	return group, c.Refresh()
+}
+
+// RenameGroup 重命名文件夹，保持其所在的上级文件夹不变，返回新的路径
+func (c *Controller) RenameGroup(path, name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if err := ValidateGroupName(name); err != nil {
+		return "", err
+	}
+
+	path = models.NormalizeFolder(path)
+	newPath := joinFolder(models.ParentFolder(path), name)
+	if newPath == path {
+		return path, nil
+	}
+	if err := c.profileManager.RenameGroup(path, newPath); err != nil {
+		return "", err
+	}
+	return newPath, c.Refresh()
+}
+
+// DeleteGroup 删除文件夹，其中的Profile和下级文件夹移到上级文件夹
+func (c *Controller) DeleteGroup(path string) error {
+	if err := c.profileManager.DeleteGroup(path); err != nil {
+		return err
+	}
+	return c.Refresh()
+}
+
+// joinFolder 拼接上级文件夹路径和文件夹名称
+func joinFolder(parent, name string) string {
+	return models.NormalizeFolder(parent + "/" + name)
+}
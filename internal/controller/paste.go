@@ -0,0 +1,145 @@
+package controller
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/flyhigher139/mhost/pkg/hostsfile"
+	"github.com/flyhigher139/mhost/pkg/models"
+)
+
+// errUnparsedLine 粘贴的行既不是条目也不是注释
+var errUnparsedLine = errors.New("无法识别为hosts条目，格式应为\"IP 主机名 [# 注释]\"")
+
+// PastedEntry 从粘贴的hosts文本中解析出的一个条目
+type PastedEntry struct {
+	Line  int    // 在粘贴文本中的行号，从1开始
+	Text  string // 该行的原始内容
+	Input EntryInput
+	// Err 条目无法添加的原因，例如主机名或IP无效、与已有条目或前面的行重复；为nil时确认后添加
+	Err error
+}
+
+// ParsePastedEntries 将粘贴的hosts格式文本解析为待添加到选中Profile的条目。
+// 一行多个主机名时展开为多个条目，行尾注释作为条目注释，被注释掉的条目以禁用状态添加，空行和普通注释忽略。
+// 主机名按normalizer规范化，已存在或与选中Profile中已启用的条目、前面的行重复的条目标记为无法添加，重复粘贴同一内容不会产生重复的条目
+func (c *Controller) ParsePastedEntries(text string, normalizer hostsfile.Normalizer) ([]PastedEntry, error) {
+	current := c.CurrentProfile()
+	if current == nil {
+		return nil, ErrNoProfileSelected
+	}
+
+	// pending 已接受的粘贴条目，用于检查粘贴内容内部的重复；lines 记录这些条目所在的行号
+	pending := &models.Profile{}
+	lines := make(map[*models.HostEntry]int)
+	var pasted []PastedEntry
+	for i, raw := range hostsfile.SplitLines(text) {
+		line, ok := hostsfile.ParseLine(raw)
+		if !ok {
+			if trimmed := strings.TrimSpace(raw); trimmed != "" && !strings.HasPrefix(trimmed, "#") {
+				pasted = append(pasted, PastedEntry{Line: i + 1, Text: trimmed, Err: errUnparsedLine})
+			}
+			continue
+		}
+
+		for _, hostname := range line.Hostnames {
+			entry := PastedEntry{
+				Line: i + 1,
+				Text: strings.TrimSpace(raw),
+				Input: EntryInput{
+					Hostname: hostname,
+					IP:       line.IP,
+					Comment:  line.Comment,
+					Enabled:  !line.Disabled,
+				},
+			}
+			entry.Err = c.checkPastedEntry(current, pending, lines, &entry.Input, normalizer)
+			if entry.Err == nil {
+				candidate := models.NewHostEntry(entry.Input.IP, entry.Input.Hostname, "")
+				candidate.Enabled = entry.Input.Enabled
+				pending.Entries = append(pending.Entries, candidate)
+				lines[candidate] = entry.Line
+			}
+			pasted = append(pasted, entry)
+		}
+	}
+	return pasted, nil
+}
+
+// checkPastedEntry 规范化并验证粘贴的条目，检查是否已存在（包括禁用的条目）、是否与已启用的条目或前面的行重复，
+// 以及是否违反严重程度为error的自定义校验规则
+func (c *Controller) checkPastedEntry(current, pending *models.Profile, lines map[*models.HostEntry]int, input *EntryInput, normalizer hostsfile.Normalizer) error {
+	if err := ValidateHostname(input.Hostname); err != nil {
+		return err
+	}
+	normalized, err := normalizer.Normalize(input.Hostname)
+	if err != nil {
+		return fmt.Errorf("主机名无效: %v", err)
+	}
+	input.Hostname = normalized
+	if err := ValidateEntry(*input); err != nil {
+		return err
+	}
+
+	for _, existing := range slices.Concat(current.Entries, pending.Entries) {
+		if existing != nil && existing.IP == input.IP && strings.EqualFold(existing.Hostname, input.Hostname) {
+			return fmt.Errorf("条目%s %s已存在", input.IP, input.Hostname)
+		}
+	}
+	candidate := &models.HostEntry{IP: input.IP, Hostname: input.Hostname, Enabled: input.Enabled}
+	if conflict := current.ConflictingEntry(candidate); conflict != nil {
+		return fmt.Errorf("主机名%s已有启用的条目（%s）", conflict.Hostname, conflict.IP)
+	}
+	if conflict := pending.ConflictingEntry(candidate); conflict != nil {
+		return fmt.Errorf("与第%d行的%s重复", lines[conflict], conflict.Hostname)
+	}
+	comment := EntryComment(current, input.Comment)
+	if _, err := c.hostManager.Rules().Enforce([]hostsfile.Entry{{IP: input.IP, Hostname: input.Hostname, Comment: comment, Enabled: input.Enabled}}); err != nil {
+		return err
+	}
+	return nil
+}
+
+// ValidPastedEntries 统计可以添加的条目数
+func ValidPastedEntries(pasted []PastedEntry) int {
+	count := 0
+	for _, entry := range pasted {
+		if entry.Err == nil {
+			count++
+		}
+	}
+	return count
+}
+
+// AddPastedEntries 将粘贴的条目中可以添加的条目加入选中的Profile并保存，补充Profile的默认注释和作者，
+// 跳过Err不为nil的条目。保存失败时不修改Profile。返回添加的条目
+func (c *Controller) AddPastedEntries(pasted []PastedEntry) ([]*models.HostEntry, error) {
+	current, err := c.editableProfile()
+	if err != nil {
+		return nil, err
+	}
+
+	var added []*models.HostEntry
+	for _, p := range pasted {
+		if p.Err != nil {
+			continue
+		}
+		entry := models.NewHostEntry(p.Input.IP, p.Input.Hostname, EntryComment(current, p.Input.Comment))
+		entry.Enabled = p.Input.Enabled
+		added = append(added, entry)
+	}
+	if len(added) == 0 {
+		return nil, nil
+	}
+
+	entries := current.Entries
+	current.Entries = append(entries[:len(entries):len(entries)], added...)
+	current.UpdateTimestamp()
+	if err := c.profileManager.UpdateProfile(current); err != nil {
+		current.Entries = entries
+		return nil, err
+	}
+	return added, nil
+}
@@ -0,0 +1,37 @@
+package controller
+
+import "github.com/flyhigher139/mhost/pkg/models"
+
+// AddTag 为Profile添加标签并重新加载Profile列表
+func (c *Controller) AddTag(p *models.Profile, tag string) error {
+	if err := c.profileManager.AddTag(p.ID, tag); err != nil {
+		return err
+	}
+	return c.Refresh()
+}
+
+// RemoveTag 移除Profile的标签并重新加载Profile列表
+func (c *Controller) RemoveTag(p *models.Profile, tag string) error {
+	if err := c.profileManager.RemoveTag(p.ID, tag); err != nil {
+		return err
+	}
+	return c.Refresh()
+}
+
+// RenameTag 在所有Profile中重命名标签，返回修改的Profile数量
+func (c *Controller) RenameTag(tag, newTag string) (int, error) {
+	changed, err := c.profileManager.RenameTag(tag, newTag)
+	if err != nil {
+		return 0, err
+	}
+	return changed, c.Refresh()
+}
+
+// DeleteTag 从所有Profile中移除标签，返回修改的Profile数量
+func (c *Controller) DeleteTag(tag string) (int, error) {
+	changed, err := c.profileManager.DeleteTag(tag)
+	if err != nil {
+		return 0, err
+	}
+	return changed, c.Refresh()
+}
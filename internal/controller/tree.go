@@ -0,0 +1,217 @@
+package controller
+
+import (
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/flyhigher139/mhost/pkg/models"
+)
+
+// RootNodeID Profile树根节点的ID，与widget.Tree的根节点ID一致
+const RootNodeID = ""
+
+// 分组节点ID的前缀，区分不同分组方式保存的展开状态
+const (
+	folderNodePrefix = "folder:"
+	tagNodePrefix    = "tag:"
+)
+
+// ProfileTree 按文件夹层级或标签分组的Profile树。
+// 分组节点在前并按名称排序，Profile节点保持列表中的顺序；
+// 按标签分组时有多个标签的Profile出现在每个标签下，没有文件夹或标签的Profile位于顶层
+type ProfileTree struct {
+	children map[string][]string
+	parents  map[string]string
+	labels   map[string]string
+	profiles map[string]*models.Profile
+	counts   map[string]int
+}
+
+// BuildProfileTree 按分组方式构建Profile树，grouping为空时按文件夹分组；
+// 按文件夹分组时groups中的空文件夹也显示在树中
+func BuildProfileTree(profiles []*models.Profile, groups []*models.ProfileGroup, grouping string) *ProfileTree {
+	t := &ProfileTree{
+		children: make(map[string][]string),
+		parents:  make(map[string]string),
+		labels:   make(map[string]string),
+		profiles: make(map[string]*models.Profile),
+		counts:   make(map[string]int),
+	}
+
+	if grouping != models.ProfileGroupingTag {
+		for _, group := range groups {
+			t.addFolder(group.Path)
+		}
+	}
+	for _, p := range profiles {
+		for _, parent := range t.groupsFor(p, grouping) {
+			id := parent + ">" + p.ID
+			t.children[parent] = append(t.children[parent], id)
+			t.parents[id] = parent
+			t.profiles[id] = p
+			for group := parent; group != RootNodeID; group = t.parents[group] {
+				t.counts[group]++
+			}
+		}
+	}
+
+	// 分组节点排在Profile节点之前
+	for parent, children := range t.children {
+		sort.SliceStable(children, func(i, j int) bool {
+			bi, bj := t.IsBranch(children[i]), t.IsBranch(children[j])
+			if bi != bj {
+				return bi
+			}
+			if bi {
+				return t.labels[children[i]] < t.labels[children[j]]
+			}
+			return false
+		})
+		t.children[parent] = children
+	}
+	return t
+}
+
+// groupsFor 获取Profile所在的分组节点，按需创建分组节点
+func (t *ProfileTree) groupsFor(p *models.Profile, grouping string) []string {
+	if grouping == models.ProfileGroupingTag {
+		var groups []string
+		seen := make(map[string]bool)
+		for _, tag := range p.Tags {
+			tag = strings.TrimSpace(tag)
+			if tag == "" || seen[tag] {
+				continue
+			}
+			seen[tag] = true
+			groups = append(groups, t.addGroup(RootNodeID, tagNodePrefix+tag, tag))
+		}
+		if len(groups) == 0 {
+			return []string{RootNodeID}
+		}
+		return groups
+	}
+
+	return []string{t.addFolder(p.Folder)}
+}
+
+// addFolder 按层级添加文件夹路径对应的分组节点，返回最内层的节点；路径为空时返回根节点
+func (t *ProfileTree) addFolder(folder string) string {
+	folder = models.NormalizeFolder(folder)
+	if folder == "" {
+		return RootNodeID
+	}
+	parent := RootNodeID
+	path := ""
+	for _, segment := range strings.Split(folder, "/") {
+		if path != "" {
+			path += "/"
+		}
+		path += segment
+		parent = t.addGroup(parent, folderNodePrefix+path, segment)
+	}
+	return parent
+}
+
+// addGroup 在parent下添加分组节点，已存在时直接返回
+func (t *ProfileTree) addGroup(parent, id, label string) string {
+	if _, ok := t.labels[id]; ok {
+		return id
+	}
+	t.labels[id] = label
+	t.parents[id] = parent
+	t.children[parent] = append(t.children[parent], id)
+	return id
+}
+
+// Children 获取节点的子节点ID
+func (t *ProfileTree) Children(id string) []string {
+	return t.children[id]
+}
+
+// IsBranch 是否为分组节点（根节点也是分组节点）
+func (t *ProfileTree) IsBranch(id string) bool {
+	if id == RootNodeID {
+		return true
+	}
+	_, ok := t.labels[id]
+	return ok
+}
+
+// Label 获取分组节点显示的名称
+func (t *ProfileTree) Label(id string) string {
+	return t.labels[id]
+}
+
+// Count 获取分组节点下（包括子分组）的Profile数量
+func (t *ProfileTree) Count(id string) int {
+	if id == RootNodeID {
+		return len(t.profiles)
+	}
+	return t.counts[id]
+}
+
+// Folder 获取文件夹节点对应的文件夹路径，不是文件夹节点时返回false
+func (t *ProfileTree) Folder(id string) (string, bool) {
+	if !t.IsBranch(id) || !strings.HasPrefix(id, folderNodePrefix) {
+		return "", false
+	}
+	return strings.TrimPrefix(id, folderNodePrefix), true
+}
+
+// FolderNode 获取文件夹路径对应的节点ID
+func FolderNode(folder string) string {
+	return folderNodePrefix + models.NormalizeFolder(folder)
+}
+
+// MoveFolderNodes 将节点ID中位于from文件夹（包括下级文件夹）的文件夹节点改为移动到to之后的ID，
+// to为空时这些节点被移除；用于文件夹重命名或删除后保持展开状态
+func MoveFolderNodes(ids []string, from, to string) []string {
+	moved := make([]string, 0, len(ids))
+	for _, id := range ids {
+		folder, ok := strings.CutPrefix(id, folderNodePrefix)
+		if ok && models.InFolder(folder, from) {
+			if folder = models.MoveFolder(folder, from, to); folder == "" || slices.Contains(moved, FolderNode(folder)) {
+				continue
+			}
+			id = FolderNode(folder)
+		}
+		moved = append(moved, id)
+	}
+	return moved
+}
+
+// Profile 获取Profile节点对应的Profile，分组节点返回nil
+func (t *ProfileTree) Profile(id string) *models.Profile {
+	return t.profiles[id]
+}
+
+// Branches 获取所有分组节点的ID（不包括根节点），按ID排序
+func (t *ProfileTree) Branches() []string {
+	branches := make([]string, 0, len(t.labels))
+	for id := range t.labels {
+		branches = append(branches, id)
+	}
+	sort.Strings(branches)
+	return branches
+}
+
+// NodeFor 获取Profile对应的节点ID，Profile出现在多个分组下时返回第一个；不存在时返回false
+func (t *ProfileTree) NodeFor(profileID string) (string, bool) {
+	var found string
+	for id, p := range t.profiles {
+		if p.ID == profileID && (found == "" || id < found) {
+			found = id
+		}
+	}
+	return found, found != ""
+}
+
+// Ancestors 获取节点的所有上级分组节点（不包括根节点），由外到内排列
+func (t *ProfileTree) Ancestors(id string) []string {
+	var ancestors []string
+	for parent := t.parents[id]; parent != RootNodeID; parent = t.parents[parent] {
+		ancestors = append([]string{parent}, ancestors...)
+	}
+	return ancestors
+}
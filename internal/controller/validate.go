@@ -0,0 +1,123 @@
+package controller
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// 界面输入的长度限制
+const (
+	MaxProfileNameLength        = 50
+	MaxProfileDescriptionLength = 500
+	MaxEntryCommentLength       = 200
+	MaxHostnameLength           = 253
+	MaxGroupNameLength          = 50
+	MaxAuthorLength             = 50
+)
+
+// ValidateInput 验证用户输入，required时不能为空，maxLength大于0时限制长度
+func ValidateInput(input string, fieldName string, required bool, maxLength int) error {
+	if required && strings.TrimSpace(input) == "" {
+		return fmt.Errorf("%s不能为空", fieldName)
+	}
+
+	if maxLength > 0 && len(input) > maxLength {
+		return fmt.Errorf("%s长度不能超过%d个字符", fieldName, maxLength)
+	}
+
+	return nil
+}
+
+// ValidateIPAddress 验证IPv4地址格式
+func ValidateIPAddress(ip string) error {
+	if ip == "" {
+		return errors.New("IP地址不能为空")
+	}
+
+	// 简单的IP地址格式验证
+	parts := strings.Split(ip, ".")
+	if len(parts) != 4 {
+		return errors.New("IP地址格式不正确")
+	}
+
+	for _, part := range parts {
+		if part == "" {
+			return errors.New("IP地址格式不正确")
+		}
+
+		// 检查是否为数字
+		for _, char := range part {
+			if char < '0' || char > '9' {
+				return errors.New("IP地址只能包含数字和点")
+			}
+		}
+
+		// 检查范围
+		var num int
+		if _, err := fmt.Sscanf(part, "%d", &num); err != nil || num < 0 || num > 255 {
+			return errors.New("IP地址每段必须在0-255之间")
+		}
+	}
+
+	return nil
+}
+
+// ValidateHostname 验证主机名格式
+func ValidateHostname(hostname string) error {
+	if hostname == "" {
+		return errors.New("主机名不能为空")
+	}
+
+	if len(hostname) > MaxHostnameLength {
+		return fmt.Errorf("主机名长度不能超过%d个字符", MaxHostnameLength)
+	}
+
+	// 检查是否包含非法字符
+	for _, char := range hostname {
+		if !((char >= 'a' && char <= 'z') || (char >= 'A' && char <= 'Z') ||
+			(char >= '0' && char <= '9') || char == '.' || char == '-' || char == '_') {
+			return errors.New("主机名只能包含字母、数字、点、连字符和下划线")
+		}
+	}
+
+	return nil
+}
+
+// ValidateProfileInfo 验证Profile的名称和描述
+func ValidateProfileInfo(name, description string) error {
+	if err := ValidateInput(name, "Profile名称", true, MaxProfileNameLength); err != nil {
+		return err
+	}
+	return ValidateInput(description, "描述", false, MaxProfileDescriptionLength)
+}
+
+// ValidateEntryDefaults 验证Profile中新条目的默认作者和默认注释
+func ValidateEntryDefaults(author, defaultComment string) error {
+	if err := ValidateInput(author, "作者", false, MaxAuthorLength); err != nil {
+		return err
+	}
+	return ValidateInput(defaultComment, "默认注释", false, MaxEntryCommentLength)
+}
+
+// ValidateGroupName 验证文件夹名称，名称中不能包含"/"
+func ValidateGroupName(name string) error {
+	if err := ValidateInput(name, "文件夹名称", true, MaxGroupNameLength); err != nil {
+		return err
+	}
+	if strings.Contains(name, "/") {
+		return errors.New("文件夹名称不能包含\"/\"")
+	}
+	return nil
+}
+
+// ValidateEntry 验证Host条目的主机名、IP地址和注释
+func ValidateEntry(input EntryInput) error {
+	if err := ValidateHostname(input.Hostname); err != nil {
+		return err
+	}
+	if err := ValidateIPAddress(input.IP); err != nil {
+		return err
+	}
+	return ValidateInput(input.Comment, "注释", false, MaxEntryCommentLength)
+}
@@ -0,0 +1,127 @@
+package controller
+
+import "testing"
+
+// TestValidateIPAddress 测试IP地址验证
+func TestValidateIPAddress(t *testing.T) {
+	// 测试用例
+	testCases := []struct {
+		name     string
+		ip       string
+		expected bool
+	}{
+		{"Valid IP", "192.168.1.1", true},
+		{"Valid IP 2", "10.0.0.1", true},
+		{"Valid IP 3", "127.0.0.1", true},
+		{"Empty IP", "", false},
+		{"Invalid format 1", "192.168.1", false},
+		{"Invalid format 2", "192.168.1.1.1", false},
+		{"Invalid range", "256.1.1.1", false},
+		{"Invalid characters", "192.168.a.1", false},
+		{"Negative number", "192.168.-1.1", false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := ValidateIPAddress(tc.ip)
+			if tc.expected && err != nil {
+				t.Errorf("Expected valid IP %s, but got error: %v", tc.ip, err)
+			}
+			if !tc.expected && err == nil {
+				t.Errorf("Expected invalid IP %s, but got no error", tc.ip)
+			}
+		})
+	}
+}
+
+// TestValidateInput 测试输入验证
+func TestValidateInput(t *testing.T) {
+	// 测试用例
+	testCases := []struct {
+		name      string
+		input     string
+		fieldName string
+		required  bool
+		maxLength int
+		expected  bool
+	}{
+		{"Valid input", "test", "field", true, 10, true},
+		{"Empty required", "", "field", true, 10, false},
+		{"Empty not required", "", "field", false, 10, true},
+		{"Too long", "very long text", "field", false, 5, false},
+		{"Exact length", "12345", "field", false, 5, true},
+		{"Whitespace only required", "   ", "field", true, 10, false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := ValidateInput(tc.input, tc.fieldName, tc.required, tc.maxLength)
+			if tc.expected && err != nil {
+				t.Errorf("Expected valid input, but got error: %v", err)
+			}
+			if !tc.expected && err == nil {
+				t.Errorf("Expected invalid input, but got no error")
+			}
+		})
+	}
+}
+
+// TestValidateHostname 测试主机名验证
+func TestValidateHostname(t *testing.T) {
+	// 测试用例
+	testCases := []struct {
+		name     string
+		hostname string
+		expected bool
+	}{
+		{"Valid hostname", "example.com", true},
+		{"Valid subdomain", "www.example.com", true},
+		{"Valid localhost", "localhost", true},
+		{"Empty hostname", "", false},
+		{"Whitespace only", "   ", false},
+		{"Too long hostname", string(make([]byte, 300)), false},
+		{"Invalid characters", "test@example.com", false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := ValidateHostname(tc.hostname)
+			if tc.expected && err != nil {
+				t.Errorf("Expected valid hostname %s, but got error: %v", tc.hostname, err)
+			}
+			if !tc.expected && err == nil {
+				t.Errorf("Expected invalid hostname %s, but got no error", tc.hostname)
+			}
+		})
+	}
+}
+
+// BenchmarkValidateIPAddress 性能测试IP地址验证
+func BenchmarkValidateIPAddress(b *testing.B) {
+	testIP := "192.168.1.1"
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		ValidateIPAddress(testIP)
+	}
+}
+
+// BenchmarkValidateInput 性能测试输入验证
+func BenchmarkValidateInput(b *testing.B) {
+	testInput := "test input"
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		ValidateInput(testInput, "field", true, 100)
+	}
+}
+
+// BenchmarkValidateHostname 性能测试主机名验证
+func BenchmarkValidateHostname(b *testing.B) {
+	testHostname := "www.example.com"
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		ValidateHostname(testHostname)
+	}
+}
@@ -0,0 +1,63 @@
+package controller
+
+import (
+	"fmt"
+
+	"github.com/flyhigher139/mhost/internal/config"
+	"github.com/flyhigher139/mhost/internal/hooks"
+	"github.com/flyhigher139/mhost/internal/host"
+	"github.com/flyhigher139/mhost/internal/profile"
+	"github.com/flyhigher139/mhost/internal/rules"
+	"github.com/flyhigher139/mhost/pkg/models"
+)
+
+// Managers 按工作区配置创建的配置、Profile与hosts管理器
+type Managers struct {
+	ConfigManager  config.Manager
+	Config         *models.AppConfig
+	ProfileManager profile.Manager
+	HostManager    host.Manager
+}
+
+// OpenWorkspace 为工作区创建配置、Profile与hosts管理器，GUI、CLI和守护进程共用，
+// 保证自动备份、规模限制、校验规则、钩子和备份保留等设置在各前端一致生效。
+// elevator为没有写入权限时使用的提权方式，nil表示不提权；配置无法加载时返回错误
+func OpenWorkspace(workspace *config.Workspace, elevator host.Elevator) (*Managers, error) {
+	configManager := config.NewWorkspaceConfigManager(workspace)
+	appConfig, err := configManager.LoadConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config: %w", err)
+	}
+
+	profileManager, err := profile.NewManager(workspace.DataDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create profile manager: %w", err)
+	}
+
+	hostManager := host.NewManager("", workspace.BackupDir)
+	hostManager.SetBackupOnApply(appConfig.Security.BackupBeforeChange)
+	hostManager.SetLimits(appConfig.Limits)
+	ruleSet, err := rules.LoadConfig(appConfig.Rules, workspace.DataDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load validation rules: %w", err)
+	}
+	hostManager.SetRules(ruleSet)
+	hookRunner, err := hooks.LoadConfig(appConfig.Hooks, workspace.DataDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load hooks: %w", err)
+	}
+	hostManager.SetHooks(hookRunner)
+	hostManager.SetDeltaBackups(appConfig.Backup.DeltaMinLines, appConfig.Backup.FullBackupEvery)
+	hostManager.SetBackupRetention(appConfig.Backup.MaxBackups, appConfig.Backup.Retention())
+	hostManager.SetGlobalSource(profileManager.GetGlobalProfile)
+	if elevator != nil {
+		hostManager.SetElevator(elevator)
+	}
+
+	return &Managers{
+		ConfigManager:  configManager,
+		Config:         appConfig,
+		ProfileManager: profileManager,
+		HostManager:    hostManager,
+	}, nil
+}
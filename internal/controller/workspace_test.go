@@ -0,0 +1,42 @@
+package controller
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/flyhigher139/mhost/internal/config"
+	"github.com/flyhigher139/mhost/internal/host"
+)
+
+// TestOpenWorkspace 测试按工作区配置创建管理器，配置无法加载时返回错误
+func TestOpenWorkspace(t *testing.T) {
+	workspace, err := config.NewWorkspaceManager(t.TempDir()).ActiveWorkspace()
+	require.NoError(t, err)
+
+	configManager := config.NewWorkspaceConfigManager(workspace)
+	appConfig, err := configManager.LoadConfig()
+	require.NoError(t, err)
+	appConfig.Security.BackupBeforeChange = false
+	require.NoError(t, configManager.SaveConfig(appConfig))
+
+	managers, err := OpenWorkspace(workspace, nil)
+	require.NoError(t, err)
+	assert.False(t, managers.Config.Security.BackupBeforeChange)
+	assert.False(t, managers.HostManager.BackupOnApply())
+	assert.Nil(t, managers.HostManager.GetElevator())
+
+	appConfig.Security.BackupBeforeChange = true
+	require.NoError(t, configManager.SaveConfig(appConfig))
+	elevator := host.NewSudoElevator()
+	managers, err = OpenWorkspace(workspace, elevator)
+	require.NoError(t, err)
+	assert.True(t, managers.HostManager.BackupOnApply())
+	assert.Equal(t, elevator, managers.HostManager.GetElevator())
+
+	require.NoError(t, os.WriteFile(workspace.ConfigPath, []byte("{"), 0644))
+	_, err = OpenWorkspace(workspace, nil)
+	assert.ErrorContains(t, err, "failed to load config")
+}
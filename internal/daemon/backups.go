@@ -0,0 +1,53 @@
+package daemon
+
+import (
+	"path/filepath"
+
+	"github.com/flyhigher139/mhost/internal/cli"
+	"github.com/flyhigher139/mhost/internal/host"
+	"github.com/flyhigher139/mhost/pkg/models"
+)
+
+// ListBackups 列出备份目录中的hosts备份，最新的在前
+func (s *Server) ListBackups() ([]cli.Backup, error) {
+	files, err := host.ListBackupFiles(s.options.BackupDir)
+	if err != nil {
+		return nil, err
+	}
+
+	backups := make([]cli.Backup, 0, len(files))
+	for _, file := range files {
+		backups = append(backups, cli.Backup{
+			Name:      file.Name,
+			Path:      file.Path,
+			Size:      file.Size,
+			CreatedAt: file.ModTime,
+			Automatic: file.Automatic,
+			Delta:     file.Delta,
+			Milestone: file.Milestone,
+			Tags:      file.Tags,
+		})
+	}
+	return backups, nil
+}
+
+// CreateBackup 备份当前hosts文件
+func (s *Server) CreateBackup() (*cli.Backup, error) {
+	backup, err := s.hostManager.BackupHostsFile()
+	if err != nil {
+		s.publishError("backup", err)
+		return nil, err
+	}
+
+	s.publish(models.EventSystemBackupCreated, map[string]interface{}{
+		"path": backup.FilePath,
+		"size": backup.Size,
+	})
+
+	return &cli.Backup{
+		Name:      filepath.Base(backup.FilePath),
+		Path:      backup.FilePath,
+		Size:      backup.Size,
+		CreatedAt: backup.CreatedAt,
+	}, nil
+}
@@ -0,0 +1,199 @@
+package daemon
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/flyhigher139/mhost/internal/cli"
+	"github.com/flyhigher139/mhost/internal/host"
+)
+
+// Client 守护进程控制socket客户端
+type Client struct {
+	socketPath string
+	httpClient *http.Client
+}
+
+// NewClient 创建守护进程客户端
+func NewClient(socketPath string) *Client {
+	dialer := &net.Dialer{Timeout: time.Second}
+	return &Client{
+		socketPath: socketPath,
+		httpClient: &http.Client{
+			Timeout: 30 * time.Second,
+			Transport: &http.Transport{
+				DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+					return dialer.DialContext(ctx, "unix", socketPath)
+				},
+			},
+		},
+	}
+}
+
+// SocketPath 获取控制socket路径
+func (c *Client) SocketPath() string {
+	return c.socketPath
+}
+
+// IsRunning 守护进程是否在运行
+func (c *Client) IsRunning() bool {
+	conn, err := net.DialTimeout("unix", c.socketPath, 200*time.Millisecond)
+	if err != nil {
+		return false
+	}
+	conn.Close()
+	return true
+}
+
+// Status 获取守护进程状态
+func (c *Client) Status(ctx context.Context) (*Status, error) {
+	var status Status
+	if err := c.do(ctx, http.MethodGet, "/v1/status", &status); err != nil {
+		return nil, err
+	}
+	return &status, nil
+}
+
+// ListProfiles 获取所有Profile
+func (c *Client) ListProfiles(ctx context.Context) ([]cli.Profile, error) {
+	var profiles []cli.Profile
+	if err := c.do(ctx, http.MethodGet, "/v1/profiles", &profiles); err != nil {
+		return nil, err
+	}
+	return profiles, nil
+}
+
+// GetProfile 按ID或名称获取Profile
+func (c *Client) GetProfile(ctx context.Context, query string) (*cli.Profile, error) {
+	var p cli.Profile
+	if err := c.do(ctx, http.MethodGet, "/v1/profiles/"+url.PathEscape(query), &p); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// Apply 激活并应用Profile
+func (c *Client) Apply(ctx context.Context, query string) (*cli.ApplyResult, error) {
+	var result cli.ApplyResult
+	if err := c.do(ctx, http.MethodPost, "/v1/profiles/"+url.PathEscape(query)+"/apply", &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// ForceApply 忽略hosts文件规模限制激活并应用Profile
+func (c *Client) ForceApply(ctx context.Context, query string) (*cli.ApplyResult, error) {
+	var result cli.ApplyResult
+	if err := c.do(ctx, http.MethodPost, "/v1/profiles/"+url.PathEscape(query)+"/apply?force=true", &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// Switch 模糊匹配并应用Profile
+func (c *Client) Switch(ctx context.Context, query string) (*cli.SwitchResult, error) {
+	var result cli.SwitchResult
+	if err := c.do(ctx, http.MethodPost, "/v1/switch/"+url.PathEscape(query), &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// Diff 获取激活Profile与hosts文件之间的差异
+func (c *Client) Diff(ctx context.Context) (*cli.Diff, error) {
+	var diff cli.Diff
+	if err := c.do(ctx, http.MethodGet, "/v1/diff", &diff); err != nil {
+		return nil, err
+	}
+	return &diff, nil
+}
+
+// ListBackups 获取hosts备份列表
+func (c *Client) ListBackups(ctx context.Context) ([]cli.Backup, error) {
+	var backups []cli.Backup
+	if err := c.do(ctx, http.MethodGet, "/v1/backups", &backups); err != nil {
+		return nil, err
+	}
+	return backups, nil
+}
+
+// CreateBackup 备份当前hosts文件
+func (c *Client) CreateBackup(ctx context.Context) (*cli.Backup, error) {
+	var backup cli.Backup
+	if err := c.do(ctx, http.MethodPost, "/v1/backups", &backup); err != nil {
+		return nil, err
+	}
+	return &backup, nil
+}
+
+// Reload 通知守护进程从磁盘重新加载Profile
+func (c *Client) Reload(ctx context.Context) error {
+	return c.do(ctx, http.MethodPost, "/v1/reload", nil)
+}
+
+// do 发送请求并解析文档中的数据
+func (c *Client) do(ctx context.Context, method, path string, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, method, "http://mhostd"+path, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to connect to daemon at %s: %w", c.socketPath, err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read daemon response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		var errResp errorResponse
+		if err := json.Unmarshal(body, &errResp); err == nil && errResp.Error != "" {
+			if resp.StatusCode == http.StatusUnprocessableEntity {
+				return &limitExceededError{message: errResp.Error}
+			}
+			return fmt.Errorf("daemon error: %s", errResp.Error)
+		}
+		return fmt.Errorf("daemon error: %s", resp.Status)
+	}
+
+	if out == nil {
+		return nil
+	}
+
+	doc := struct {
+		SchemaVersion string          `json:"schema_version"`
+		Data          json.RawMessage `json:"data"`
+	}{}
+	if err := json.Unmarshal(body, &doc); err != nil {
+		return fmt.Errorf("failed to decode daemon response: %w", err)
+	}
+	if doc.SchemaVersion != cli.SchemaVersion {
+		return fmt.Errorf("unsupported daemon schema version: %s", doc.SchemaVersion)
+	}
+	return json.Unmarshal(doc.Data, out)
+}
+
+// limitExceededError 守护进程因hosts文件规模限制拒绝应用，支持errors.Is(err, host.ErrLimitExceeded)
+type limitExceededError struct {
+	message string
+}
+
+// Error 实现error接口
+func (e *limitExceededError) Error() string {
+	return "daemon error: " + e.message
+}
+
+// Unwrap 返回host.ErrLimitExceeded
+func (e *limitExceededError) Unwrap() error {
+	return host.ErrLimitExceeded
+}
@@ -0,0 +1,557 @@
+package daemon
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/flyhigher139/mhost/internal/activity"
+	"github.com/flyhigher139/mhost/internal/analyzer"
+	"github.com/flyhigher139/mhost/internal/cli"
+	"github.com/flyhigher139/mhost/internal/host"
+	"github.com/flyhigher139/mhost/internal/profile"
+	"github.com/flyhigher139/mhost/pkg/logger"
+	"github.com/flyhigher139/mhost/pkg/models"
+)
+
+// SocketFileName 默认控制socket文件名
+const SocketFileName = "mhostd.sock"
+
+// DefaultSocketPath 获取数据目录下的默认控制socket路径
+func DefaultSocketPath(dataDir string) string {
+	return filepath.Join(dataDir, SocketFileName)
+}
+
+// Options 守护进程选项
+type Options struct {
+	SocketPath      string        // 控制socket路径
+	ProfileFile     string        // 监听的Profile数据文件
+	WatchInterval   time.Duration // 文件变化轮询间隔
+	AnalyzeInterval time.Duration // 条目分析间隔，0表示不运行
+	BackupDir       string        // hosts文件备份目录
+	WebAddr         string        // Web界面监听地址，空表示不启用
+	APIToken        string        // Web界面访问令牌
+	HistoryFile     string        // 应用历史文件，空表示不记录
+	ActivityFile    string        // 活动记录文件，与GUI的最近活动面板共用，空表示不记录
+	Enforce         bool          // 启动时以及hosts文件被外部修改后重新应用激活的Profile
+	Logger          logger.Logger // 后台任务的日志器，nil时输出到标准输出
+}
+
+// DefaultOptions 获取默认选项
+func DefaultOptions(dataDir string) Options {
+	return Options{
+		SocketPath:      DefaultSocketPath(dataDir),
+		ProfileFile:     filepath.Join(dataDir, "profiles.json"),
+		WatchInterval:   2 * time.Second,
+		AnalyzeInterval: 24 * time.Hour,
+		BackupDir:       filepath.Join(dataDir, "backups"),
+		HistoryFile:     host.DefaultHistoryPath(dataDir),
+		ActivityFile:    activity.DefaultFeedPath(dataDir),
+	}
+}
+
+// ProfileRef Profile引用
+type ProfileRef struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// Status 守护进程状态
+type Status struct {
+	PID           int         `json:"pid"`
+	StartTime     time.Time   `json:"start_time"`
+	SocketPath    string      `json:"socket_path"`
+	HostsPath     string      `json:"hosts_path"`
+	Profiles      int         `json:"profiles"`
+	ActiveProfile *ProfileRef `json:"active_profile"`
+	LastReload    time.Time   `json:"last_reload"`
+	Drift         cli.Diff    `json:"drift"`
+	LastAnalysis  *time.Time  `json:"last_analysis"`
+}
+
+// Server 守护进程，在内存中保持Profile状态并通过Unix socket接受命令
+type Server struct {
+	profileManager profile.Manager
+	hostManager    host.Manager
+	analyzer       *analyzer.Analyzer
+	history        host.History
+	activity       activity.Feed
+	options        Options
+	events         *eventBroker
+	logger         logger.Logger
+
+	mu         sync.RWMutex
+	applyMu    sync.Mutex
+	startTime  time.Time
+	lastReload time.Time
+	drift      cli.Diff
+	appliedAt  time.Time // 守护进程最近一次写入后hosts文件的修改时间
+	listener   net.Listener
+	httpServer *http.Server
+	webServer  *http.Server
+	stopChan   chan struct{}
+	wg         sync.WaitGroup
+}
+
+// NewServer 创建守护进程
+func NewServer(profileManager profile.Manager, hostManager host.Manager, options Options) *Server {
+	if options.WatchInterval <= 0 {
+		options.WatchInterval = 2 * time.Second
+	}
+	if options.Logger == nil {
+		options.Logger = logger.NewEnhancedLogger(logger.LogLevelInfo, false)
+	}
+
+	s := &Server{
+		profileManager: profileManager,
+		hostManager:    hostManager,
+		options:        options,
+		events:         newEventBroker(),
+		logger:         options.Logger,
+		drift:          cli.NewDiff(nil),
+	}
+	if options.HistoryFile != "" {
+		s.history = host.NewHistory(options.HistoryFile)
+	}
+	if options.ActivityFile != "" {
+		s.activity = activity.NewFeed(options.ActivityFile)
+	}
+	if options.AnalyzeInterval > 0 {
+		s.analyzer = analyzer.NewAnalyzer(profileManager, analyzer.DefaultOptions())
+	}
+	s.httpServer = &http.Server{Handler: s.routes()}
+	return s
+}
+
+// Start 监听控制socket并启动文件监听与定时任务
+func (s *Server) Start() error {
+	listener, err := listenUnix(s.options.SocketPath)
+	if err != nil {
+		return err
+	}
+	if err := s.Serve(listener); err != nil {
+		return err
+	}
+
+	if s.options.WebAddr == "" {
+		return nil
+	}
+
+	webListener, err := net.Listen("tcp", s.options.WebAddr)
+	if err != nil {
+		s.Stop(context.Background())
+		return fmt.Errorf("failed to listen on %s: %w", s.options.WebAddr, err)
+	}
+	if err := s.ServeWeb(webListener); err != nil {
+		webListener.Close()
+		s.Stop(context.Background())
+		return err
+	}
+	return nil
+}
+
+// Serve 使用给定监听器提供服务，非阻塞
+func (s *Server) Serve(listener net.Listener) error {
+	s.mu.Lock()
+	if s.listener != nil {
+		s.mu.Unlock()
+		return fmt.Errorf("daemon is already running")
+	}
+	s.listener = listener
+	s.startTime = time.Now()
+	s.lastReload = s.startTime
+	s.stopChan = make(chan struct{})
+	s.mu.Unlock()
+
+	s.refreshDrift()
+	if s.options.Enforce {
+		s.enforce()
+	}
+
+	if s.analyzer != nil {
+		if err := s.analyzer.Start(s.options.AnalyzeInterval); err != nil {
+			return err
+		}
+	}
+
+	// 在启动监听前记录文件状态，避免遗漏启动期间发生的修改
+	profileModTime := modTime(s.options.ProfileFile)
+	hostsModTime := modTime(s.hostManager.GetHostsFilePath())
+
+	s.wg.Add(2)
+	go func() {
+		defer s.wg.Done()
+		if err := s.httpServer.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			s.logger.Error("Daemon server error", "socket", s.options.SocketPath, "error", err)
+		}
+	}()
+	go func() {
+		defer s.wg.Done()
+		s.watch(profileModTime, hostsModTime)
+	}()
+
+	return nil
+}
+
+// Stop 停止服务并删除控制socket
+func (s *Server) Stop(ctx context.Context) error {
+	s.mu.Lock()
+	if s.listener == nil {
+		s.mu.Unlock()
+		return nil
+	}
+	close(s.stopChan)
+	s.listener = nil
+	webServer := s.webServer
+	s.mu.Unlock()
+
+	if s.analyzer != nil {
+		s.analyzer.Stop()
+	}
+
+	// 先关闭事件订阅，使事件流连接结束
+	s.events.Close()
+
+	err := s.httpServer.Shutdown(ctx)
+	if webServer != nil {
+		if webErr := webServer.Shutdown(ctx); err == nil {
+			err = webErr
+		}
+	}
+	s.wg.Wait()
+
+	if s.options.SocketPath != "" {
+		os.Remove(s.options.SocketPath)
+	}
+	return err
+}
+
+// Status 获取守护进程状态
+func (s *Server) Status() *Status {
+	s.mu.RLock()
+	status := &Status{
+		PID:        os.Getpid(),
+		StartTime:  s.startTime,
+		SocketPath: s.options.SocketPath,
+		HostsPath:  s.hostManager.GetHostsFilePath(),
+		LastReload: s.lastReload,
+		Drift:      s.drift,
+	}
+	s.mu.RUnlock()
+
+	if summaries, err := s.profileManager.ListProfiles(); err == nil {
+		status.Profiles = len(summaries)
+	}
+	if active, err := s.profileManager.GetActiveProfile(); err == nil && active != nil {
+		status.ActiveProfile = &ProfileRef{ID: active.ID, Name: active.Name}
+	}
+	if s.analyzer != nil {
+		if report := s.analyzer.LastReport(); report != nil {
+			generatedAt := report.GeneratedAt
+			status.LastAnalysis = &generatedAt
+		}
+	}
+	return status
+}
+
+// Reload 从磁盘重新加载Profile并重新检测差异
+func (s *Server) Reload() error {
+	if err := s.profileManager.Reload(); err != nil {
+		s.publishError("reload", err)
+		return err
+	}
+
+	s.mu.Lock()
+	s.lastReload = time.Now()
+	s.mu.Unlock()
+
+	data := map[string]interface{}{}
+	if summaries, err := s.profileManager.ListProfiles(); err == nil {
+		data["profiles"] = len(summaries)
+	}
+	s.publish(models.EventProfileUpdated, data)
+
+	s.refreshDrift()
+	return nil
+}
+
+// ResolveProfile 按ID或名称（不区分大小写）查找Profile
+func (s *Server) ResolveProfile(query string) (*models.Profile, error) {
+	if p, err := s.profileManager.GetProfile(query); err == nil {
+		return p, nil
+	}
+
+	summaries, err := s.profileManager.ListProfiles()
+	if err != nil {
+		return nil, err
+	}
+	for _, summary := range summaries {
+		if strings.EqualFold(summary.Name, query) {
+			return s.profileManager.GetProfile(summary.ID)
+		}
+	}
+	return nil, models.ErrProfileNotFound
+}
+
+// Apply 激活并应用Profile；Profile已激活且hosts文件无差异时不重复写入
+func (s *Server) Apply(query string) (*cli.ApplyResult, error) {
+	return s.applyWithOptions(query, host.ApplyOptions{})
+}
+
+// ForceApply 忽略hosts文件规模限制应用Profile，超限项作为警告返回
+func (s *Server) ForceApply(query string) (*cli.ApplyResult, error) {
+	return s.applyWithOptions(query, host.ApplyOptions{IgnoreLimits: true})
+}
+
+// applyWithOptions 按选项应用并在失败时发布错误事件
+func (s *Server) applyWithOptions(query string, options host.ApplyOptions) (*cli.ApplyResult, error) {
+	result, err := s.apply(query, options)
+	if err != nil {
+		s.publishError("apply", err)
+		return nil, err
+	}
+	return result, nil
+}
+
+// apply 执行应用
+func (s *Server) apply(query string, options host.ApplyOptions) (*cli.ApplyResult, error) {
+	s.applyMu.Lock()
+	defer s.applyMu.Unlock()
+
+	p, err := s.ResolveProfile(query)
+	if err != nil {
+		return nil, err
+	}
+	return s.applyProfiles([]*models.Profile{p}, options)
+}
+
+// applyProfiles 激活profiles并按优先级从低到高叠加应用，profiles已经激活且hosts文件没有差异时不写入（需要持有applyMu）
+func (s *Server) applyProfiles(profiles []*models.Profile, options host.ApplyOptions) (*cli.ApplyResult, error) {
+	p, _ := models.LayerProfiles(profiles)
+	drift, err := s.hostManager.DetectDrift(p)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &cli.ApplyResult{
+		ProfileID:   p.ID,
+		ProfileName: p.Name,
+		HostsPath:   s.hostManager.GetHostsFilePath(),
+		Entries:     countEnabled(p.Entries),
+		AppliedAt:   time.Now(),
+		Diff:        cli.NewDiff(drift),
+	}
+
+	ids := make([]string, 0, len(profiles))
+	for _, layer := range profiles {
+		ids = append(ids, layer.ID)
+	}
+	active := profile.IsActiveSet(s.profileManager, ids...)
+	if active && !drift.HasDrift() {
+		return result, nil
+	}
+
+	profileData := map[string]interface{}{"profile_id": p.ID, "profile_name": p.Name}
+	if !active {
+		if err := s.profileManager.ActivateProfiles(ids); err != nil {
+			return nil, err
+		}
+		s.publish(models.EventProfileActivated, profileData)
+	}
+	applied, err := s.hostManager.ApplyProfiles(profiles, options)
+	if err != nil {
+		return nil, err
+	}
+	result.Changed = true
+	result.LinesWritten = applied.LinesWritten
+	result.BackupPath = applied.BackupPath
+	result.Warnings = applied.Warnings
+	result.DurationMS = applied.Duration.Milliseconds()
+	if s.history != nil {
+		if err := s.history.Record(applied); err != nil {
+			s.publishError("history", err)
+		}
+	}
+
+	s.mu.Lock()
+	s.appliedAt = modTime(result.HostsPath)
+	s.mu.Unlock()
+
+	s.publish(models.EventSystemHostsUpdated, map[string]interface{}{
+		"profile_id":   p.ID,
+		"profile_name": p.Name,
+		"entries":      result.Entries,
+		"hosts_path":   result.HostsPath,
+	})
+
+	s.refreshDrift()
+	return result, nil
+}
+
+// Diff 获取激活Profile（同时激活多个时为叠加后的内容）与hosts文件管理section之间的差异
+func (s *Server) Diff() (cli.Diff, error) {
+	active, err := profile.LayeredActiveProfile(s.profileManager)
+	if err != nil {
+		return cli.Diff{}, err
+	}
+
+	drift, err := s.hostManager.DetectDrift(active)
+	if err != nil {
+		return cli.Diff{}, err
+	}
+	return cli.NewDiff(drift), nil
+}
+
+// refreshDrift 重新计算激活Profile的差异，出现新的差异时发布事件
+func (s *Server) refreshDrift() {
+	diff, err := s.Diff()
+	if err != nil {
+		diff = cli.NewDiff(nil)
+	}
+
+	s.mu.Lock()
+	previous := s.drift
+	s.drift = diff
+	s.mu.Unlock()
+
+	if diff.HasChanges && !sameDriftCounts(previous, diff) {
+		s.publish(models.EventSystemDriftDetected, map[string]interface{}{
+			"added":   len(diff.Added),
+			"changed": len(diff.Changed),
+			"removed": len(diff.Removed),
+		})
+	}
+}
+
+// sameDriftCounts 两次差异的数量是否一致
+func sameDriftCounts(a, b cli.Diff) bool {
+	return a.HasChanges == b.HasChanges &&
+		len(a.Added) == len(b.Added) &&
+		len(a.Changed) == len(b.Changed) &&
+		len(a.Removed) == len(b.Removed)
+}
+
+// watch 轮询Profile数据文件与hosts文件的变化
+func (s *Server) watch(profileModTime, hostsModTime time.Time) {
+	ticker := time.NewTicker(s.options.WatchInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.stopChan:
+			return
+		case <-ticker.C:
+			if current := modTime(s.options.ProfileFile); !current.Equal(profileModTime) {
+				profileModTime = current
+				if err := s.Reload(); err != nil {
+					s.logger.Error("Failed to reload profiles", "profile_file", s.options.ProfileFile, "error", err)
+				}
+			}
+			if current := modTime(s.hostManager.GetHostsFilePath()); !current.Equal(hostsModTime) {
+				hostsModTime = current
+
+				s.mu.RLock()
+				external := !current.Equal(s.appliedAt)
+				s.mu.RUnlock()
+				if external {
+					s.publish(models.EventSystemHostsUpdated, map[string]interface{}{
+						"hosts_path": s.hostManager.GetHostsFilePath(),
+						"external":   true,
+					})
+				}
+				s.refreshDrift()
+				if external && s.options.Enforce {
+					s.enforce()
+				}
+			}
+		}
+	}
+}
+
+// enforce 激活的Profile与hosts文件存在差异时重新应用，使hosts文件保持为激活Profile的内容
+func (s *Server) enforce() {
+	s.mu.RLock()
+	drift := s.drift
+	s.mu.RUnlock()
+	if !drift.HasChanges {
+		return
+	}
+
+	active, err := s.profileManager.GetActiveProfiles()
+	if err != nil {
+		return
+	}
+
+	s.applyMu.Lock()
+	result, err := s.applyProfiles(active, host.ApplyOptions{})
+	s.applyMu.Unlock()
+	if err != nil {
+		s.publishError("apply", err)
+		s.logger.Error("Failed to re-apply active profiles", "hosts_path", s.hostManager.GetHostsFilePath(), "error", err)
+		return
+	}
+	s.logger.Info("Re-applied active profiles after the hosts file was changed", "profile", result.ProfileName, "hosts_path", result.HostsPath)
+}
+
+// countEnabled 统计启用的条目数
+func countEnabled(entries []*models.HostEntry) int {
+	count := 0
+	for _, entry := range entries {
+		if entry != nil && entry.Enabled {
+			count++
+		}
+	}
+	return count
+}
+
+// modTime 获取文件修改时间，文件不存在时返回零值
+func modTime(path string) time.Time {
+	if path == "" {
+		return time.Time{}
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return time.Time{}
+	}
+	return info.ModTime()
+}
+
+// listenUnix 监听Unix socket，清理上次异常退出遗留的socket文件
+func listenUnix(socketPath string) (net.Listener, error) {
+	if socketPath == "" {
+		return nil, fmt.Errorf("socket path cannot be empty")
+	}
+
+	if err := os.MkdirAll(filepath.Dir(socketPath), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create socket directory: %w", err)
+	}
+
+	if _, err := os.Stat(socketPath); err == nil {
+		if conn, err := net.DialTimeout("unix", socketPath, time.Second); err == nil {
+			conn.Close()
+			return nil, fmt.Errorf("daemon is already running on %s", socketPath)
+		}
+		if err := os.Remove(socketPath); err != nil {
+			return nil, fmt.Errorf("failed to remove stale socket: %w", err)
+		}
+	}
+
+	listener, err := net.Listen("unix", socketPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on %s: %w", socketPath, err)
+	}
+
+	if err := os.Chmod(socketPath, 0600); err != nil {
+		listener.Close()
+		return nil, fmt.Errorf("failed to set socket permissions: %w", err)
+	}
+
+	return listener, nil
+}
@@ -0,0 +1,362 @@
+package daemon
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/flyhigher139/mhost/internal/activity"
+	"github.com/flyhigher139/mhost/internal/host"
+	"github.com/flyhigher139/mhost/internal/profile"
+	"github.com/flyhigher139/mhost/pkg/hostsfile"
+	"github.com/flyhigher139/mhost/pkg/logger"
+	"github.com/flyhigher139/mhost/pkg/models"
+)
+
+// newTestDaemon 创建并启动测试用守护进程，configure可修改默认选项
+func newTestDaemon(t *testing.T, configure ...func(*Options)) (*Server, *Client, string, string) {
+	dataDir, err := os.MkdirTemp("", "mhostd")
+	require.NoError(t, err)
+	t.Cleanup(func() { os.RemoveAll(dataDir) })
+
+	hostsPath := filepath.Join(dataDir, "hosts")
+	require.NoError(t, os.WriteFile(hostsPath, []byte("127.0.0.1\tlocalhost\n"), 0644))
+
+	profileManager, err := profile.NewManager(dataDir)
+	require.NoError(t, err)
+
+	options := DefaultOptions(dataDir)
+	options.WatchInterval = 20 * time.Millisecond
+	options.AnalyzeInterval = 0
+	for _, fn := range configure {
+		fn(&options)
+	}
+
+	server := NewServer(profileManager, host.NewManager(hostsPath, filepath.Join(dataDir, "backups")), options)
+	require.NoError(t, server.Start())
+	t.Cleanup(func() { server.Stop(context.Background()) })
+
+	return server, NewClient(options.SocketPath), dataDir, hostsPath
+}
+
+// TestDaemonApply 测试通过socket列出并应用Profile
+func TestDaemonApply(t *testing.T) {
+	_, client, dataDir, hostsPath := newTestDaemon(t)
+	ctx := context.Background()
+	assert.True(t, client.IsRunning())
+
+	// 其他进程（例如GUI）写入的Profile在reload后可见
+	writer, err := profile.NewManager(dataDir)
+	require.NoError(t, err)
+	_, err = writer.CreateProfile("Base", "")
+	require.NoError(t, err)
+	dev, err := writer.CreateProfile("Dev", "")
+	require.NoError(t, err)
+	dev.AddEntry(models.NewHostEntry("10.0.0.1", "api.test", "staging"))
+	require.NoError(t, writer.UpdateProfile(dev))
+	require.NoError(t, client.Reload(ctx))
+
+	profiles, err := client.ListProfiles(ctx)
+	require.NoError(t, err)
+	assert.Len(t, profiles, 2)
+
+	result, err := client.Apply(ctx, "dev")
+	require.NoError(t, err)
+	assert.True(t, result.Changed)
+	assert.Equal(t, dev.ID, result.ProfileID)
+	assert.Equal(t, 1, result.Entries)
+	assert.Len(t, result.Diff.Removed, 1)
+
+	data, err := os.ReadFile(hostsPath)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "10.0.0.1\tapi.test\t# staging")
+
+	// 再次应用时无需写入
+	result, err = client.Apply(ctx, dev.ID)
+	require.NoError(t, err)
+	assert.False(t, result.Changed)
+
+	status, err := client.Status(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, status.ActiveProfile)
+	assert.Equal(t, "Dev", status.ActiveProfile.Name)
+	assert.Equal(t, 2, status.Profiles)
+	assert.False(t, status.Drift.HasChanges)
+
+	_, err = client.Apply(ctx, "missing")
+	assert.Error(t, err)
+}
+
+// TestDaemonApplyLimits 测试超过规模限制时拒绝应用，force时强制应用
+func TestDaemonApplyLimits(t *testing.T) {
+	server, client, dataDir, hostsPath := newTestDaemon(t)
+	ctx := context.Background()
+	server.hostManager.SetLimits(models.LimitsConfig{MaxEntries: 1})
+
+	writer, err := profile.NewManager(dataDir)
+	require.NoError(t, err)
+	p, err := writer.CreateProfile("Dev", "")
+	require.NoError(t, err)
+	p.AddEntry(models.NewHostEntry("10.0.0.1", "api.test", ""))
+	require.NoError(t, writer.UpdateProfile(p))
+	require.NoError(t, client.Reload(ctx))
+
+	_, err = client.Apply(ctx, "Dev")
+	assert.ErrorIs(t, err, host.ErrLimitExceeded)
+	data, err := os.ReadFile(hostsPath)
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "api.test")
+
+	result, err := client.ForceApply(ctx, "Dev")
+	require.NoError(t, err)
+	assert.True(t, result.Changed)
+	assert.Contains(t, strings.Join(result.Warnings, "\n"), "limit overridden: 2 entries (limit 1)")
+}
+
+// TestDaemonWatch 测试检测外部修改
+func TestDaemonWatch(t *testing.T) {
+	server, client, dataDir, hostsPath := newTestDaemon(t)
+	ctx := context.Background()
+
+	writer, err := profile.NewManager(dataDir)
+	require.NoError(t, err)
+	p, err := writer.CreateProfile("Dev", "")
+	require.NoError(t, err)
+	p.AddEntry(models.NewHostEntry("10.0.0.1", "api.test", ""))
+	require.NoError(t, writer.UpdateProfile(p))
+
+	// Profile文件变化后自动重新加载
+	assert.Eventually(t, func() bool {
+		_, err := server.ResolveProfile("Dev")
+		return err == nil
+	}, 2*time.Second, 10*time.Millisecond)
+
+	_, err = client.Apply(ctx, "Dev")
+	require.NoError(t, err)
+
+	// 手动编辑hosts文件后差异被检测到
+	data, err := os.ReadFile(hostsPath)
+	require.NoError(t, err)
+	edited := strings.Replace(string(data), hostsfile.EndMarker, "10.0.0.9\tmanual.test\n"+hostsfile.EndMarker, 1)
+	time.Sleep(10 * time.Millisecond)
+	require.NoError(t, os.WriteFile(hostsPath, []byte(edited), 0644))
+
+	assert.Eventually(t, func() bool {
+		return server.Status().Drift.HasChanges
+	}, 2*time.Second, 10*time.Millisecond)
+
+	diff, err := client.Diff(ctx)
+	require.NoError(t, err)
+	require.Len(t, diff.Added, 1)
+	assert.Equal(t, "manual.test", diff.Added[0].Hostname)
+}
+
+// TestDaemonEnforce 测试强制模式下hosts文件被外部修改后重新应用激活的Profile，并记录在日志中
+func TestDaemonEnforce(t *testing.T) {
+	logPath := filepath.Join(t.TempDir(), "daemon.log")
+	log, err := logger.NewFileLogger(logPath, logger.LogLevelInfo, false)
+	require.NoError(t, err)
+	server, client, dataDir, hostsPath := newTestDaemon(t, func(options *Options) {
+		options.Enforce = true
+		options.Logger = log
+	})
+	ctx := context.Background()
+
+	writer, err := profile.NewManager(dataDir)
+	require.NoError(t, err)
+	p, err := writer.CreateProfile("Dev", "")
+	require.NoError(t, err)
+	p.AddEntry(models.NewHostEntry("10.0.0.1", "api.test", ""))
+	require.NoError(t, writer.UpdateProfile(p))
+	require.NoError(t, client.Reload(ctx))
+	_, err = client.Apply(ctx, "Dev")
+	require.NoError(t, err)
+
+	time.Sleep(10 * time.Millisecond)
+	require.NoError(t, os.WriteFile(hostsPath, []byte("127.0.0.1\tlocalhost\n10.0.0.9\tmanual.test\n"), 0644))
+
+	assert.Eventually(t, func() bool {
+		data, err := os.ReadFile(hostsPath)
+		return err == nil && strings.Contains(string(data), "api.test") && !server.Status().Drift.HasChanges
+	}, 2*time.Second, 10*time.Millisecond)
+	data, err := os.ReadFile(hostsPath)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "manual.test", "lines outside the managed section are kept")
+
+	// 重新应用记录在守护进程的日志中
+	assert.Eventually(t, func() bool {
+		data, err := os.ReadFile(logPath)
+		return err == nil && strings.Contains(string(data), "Re-applied active profiles after the hosts file was changed")
+	}, 2*time.Second, 10*time.Millisecond)
+}
+
+// waitForEvents 等待指定类型的事件（不限顺序），跳过其他事件
+func waitForEvents(t *testing.T, events <-chan models.Event, eventTypes ...models.EventType) map[models.EventType]models.Event {
+	received := make(map[models.EventType]models.Event, len(eventTypes))
+	timeout := time.After(2 * time.Second)
+	for len(received) < len(eventTypes) {
+		select {
+		case event, ok := <-events:
+			require.True(t, ok, "event stream closed")
+			for _, eventType := range eventTypes {
+				if event.Type == eventType {
+					received[eventType] = event
+				}
+			}
+		case <-timeout:
+			t.Fatalf("timed out waiting for %v events", eventTypes)
+		}
+	}
+	return received
+}
+
+// TestDaemonEvents 测试事件流
+func TestDaemonEvents(t *testing.T) {
+	server, client, dataDir, hostsPath := newTestDaemon(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	events, err := client.Events(ctx, models.EventSystemHostsUpdated, models.EventSystemDriftDetected, models.EventError)
+	require.NoError(t, err)
+
+	writer, err := profile.NewManager(dataDir)
+	require.NoError(t, err)
+	p, err := writer.CreateProfile("Dev", "")
+	require.NoError(t, err)
+	p.AddEntry(models.NewHostEntry("10.0.0.1", "api.test", ""))
+	require.NoError(t, writer.UpdateProfile(p))
+	require.NoError(t, server.Reload())
+
+	_, err = client.Apply(ctx, "Dev")
+	require.NoError(t, err)
+
+	event := waitForEvents(t, events, models.EventSystemHostsUpdated)[models.EventSystemHostsUpdated]
+	assert.Equal(t, "Dev", event.Data["profile_name"])
+	assert.Equal(t, "daemon", event.Source)
+
+	_, err = client.Apply(ctx, "missing")
+	assert.Error(t, err)
+	event = waitForEvents(t, events, models.EventError)[models.EventError]
+	assert.Equal(t, "apply", event.Data["operation"])
+
+	// 外部修改触发hosts更新与差异事件
+	time.Sleep(10 * time.Millisecond)
+	require.NoError(t, os.WriteFile(hostsPath, []byte("127.0.0.1\tlocalhost\n"), 0644))
+
+	received := waitForEvents(t, events, models.EventSystemHostsUpdated, models.EventSystemDriftDetected)
+	assert.Equal(t, true, received[models.EventSystemHostsUpdated].Data["external"])
+	assert.Equal(t, float64(1), received[models.EventSystemDriftDetected].Data["removed"])
+
+	// 发布的事件同时写入最近活动面板使用的活动记录
+	recorded, err := activity.NewFeed(activity.DefaultFeedPath(dataDir)).List(activity.Filter{Category: activity.CategorySystem})
+	require.NoError(t, err)
+	var types []models.EventType
+	for _, event := range recorded {
+		types = append(types, event.Type)
+	}
+	assert.Contains(t, types, models.EventSystemDriftDetected)
+	assert.Contains(t, types, models.EventSystemHostsUpdated)
+
+	// 停止守护进程后事件流关闭
+	require.NoError(t, server.Stop(context.Background()))
+	assert.Eventually(t, func() bool {
+		_, ok := <-events
+		return !ok
+	}, 2*time.Second, 10*time.Millisecond)
+}
+
+// TestEventBroker 测试事件分发与过滤
+func TestEventBroker(t *testing.T) {
+	broker := newEventBroker()
+
+	all, cancelAll := broker.Subscribe(nil)
+	filtered, cancelFiltered := broker.Subscribe([]models.EventType{models.EventError})
+	defer cancelFiltered()
+
+	broker.Publish(models.NewEvent(models.EventProfileUpdated, "test", nil))
+	broker.Publish(models.NewEvent(models.EventError, "test", nil))
+
+	assert.Equal(t, models.EventProfileUpdated, (<-all).Type)
+	assert.Equal(t, models.EventError, (<-all).Type)
+	assert.Equal(t, models.EventError, (<-filtered).Type)
+
+	cancelAll()
+	_, ok := <-all
+	assert.False(t, ok)
+
+	broker.Close()
+	_, ok = <-filtered
+	assert.False(t, ok)
+
+	closed, _ := broker.Subscribe(nil)
+	_, ok = <-closed
+	assert.False(t, ok)
+}
+
+// TestDaemonWeb 测试Web界面与令牌校验
+func TestDaemonWeb(t *testing.T) {
+	server, client, _, _ := newTestDaemon(t)
+	server.options.APIToken = "secret"
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	require.NoError(t, server.ServeWeb(listener))
+	baseURL := "http://" + listener.Addr().String()
+
+	get := func(path, token string) *http.Response {
+		req, err := http.NewRequest(http.MethodGet, baseURL+path, nil)
+		require.NoError(t, err)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		resp.Body.Close()
+		return resp
+	}
+
+	resp := get("/", "")
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Contains(t, resp.Header.Get("Content-Type"), "text/html")
+
+	assert.Equal(t, http.StatusUnauthorized, get("/v1/status", "").StatusCode)
+	assert.Equal(t, http.StatusUnauthorized, get("/v1/status", "wrong").StatusCode)
+	assert.Equal(t, http.StatusOK, get("/v1/status", "secret").StatusCode)
+	assert.Equal(t, http.StatusOK, get("/v1/status?token=secret", "").StatusCode)
+
+	// 备份通过控制socket同样可用
+	ctx := context.Background()
+	backup, err := client.CreateBackup(ctx)
+	require.NoError(t, err)
+	assert.FileExists(t, backup.Path)
+
+	backups, err := client.ListBackups(ctx)
+	require.NoError(t, err)
+	require.Len(t, backups, 1)
+	assert.Equal(t, backup.Name, backups[0].Name)
+}
+
+// TestLoadOrCreateToken 测试令牌生成与复用
+func TestLoadOrCreateToken(t *testing.T) {
+	path := filepath.Join(t.TempDir(), TokenFileName)
+
+	token, err := LoadOrCreateToken(path)
+	require.NoError(t, err)
+	assert.Len(t, token, 64)
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	again, err := LoadOrCreateToken(path)
+	require.NoError(t, err)
+	assert.Equal(t, token, again)
+}
@@ -0,0 +1,235 @@
+package daemon
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/flyhigher139/mhost/pkg/models"
+)
+
+// eventSource 守护进程发布事件时使用的事件源
+const eventSource = "daemon"
+
+// eventBufferSize 每个订阅者的事件缓冲大小，缓冲满时丢弃事件而不阻塞发布者
+const eventBufferSize = 64
+
+// heartbeatInterval 事件流心跳间隔
+const heartbeatInterval = 15 * time.Second
+
+// eventBroker 事件分发器
+type eventBroker struct {
+	mu          sync.Mutex
+	nextID      int
+	subscribers map[int]*subscriber
+	closed      bool
+}
+
+// subscriber 事件订阅者
+type subscriber struct {
+	events chan models.Event
+	types  map[models.EventType]bool
+}
+
+// newEventBroker 创建事件分发器
+func newEventBroker() *eventBroker {
+	return &eventBroker{subscribers: make(map[int]*subscriber)}
+}
+
+// Subscribe 订阅事件，types为空时订阅全部类型；返回事件通道与取消函数
+func (b *eventBroker) Subscribe(types []models.EventType) (<-chan models.Event, func()) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	sub := &subscriber{events: make(chan models.Event, eventBufferSize)}
+	if len(types) > 0 {
+		sub.types = make(map[models.EventType]bool, len(types))
+		for _, t := range types {
+			sub.types[t] = true
+		}
+	}
+
+	if b.closed {
+		close(sub.events)
+		return sub.events, func() {}
+	}
+
+	id := b.nextID
+	b.nextID++
+	b.subscribers[id] = sub
+
+	return sub.events, func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		if s, ok := b.subscribers[id]; ok {
+			delete(b.subscribers, id)
+			close(s.events)
+		}
+	}
+}
+
+// Publish 向所有匹配的订阅者发布事件
+func (b *eventBroker) Publish(event *models.Event) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for _, sub := range b.subscribers {
+		if sub.types != nil && !sub.types[event.Type] {
+			continue
+		}
+		select {
+		case sub.events <- *event.Clone():
+		default:
+		}
+	}
+}
+
+// Close 关闭所有订阅
+func (b *eventBroker) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.closed = true
+	for id, sub := range b.subscribers {
+		close(sub.events)
+		delete(b.subscribers, id)
+	}
+}
+
+// publish 发布守护进程事件，并写入活动记录
+func (s *Server) publish(eventType models.EventType, data map[string]interface{}) {
+	event := models.NewEvent(eventType, eventSource, data)
+	s.events.Publish(event)
+	if s.activity != nil {
+		if err := s.activity.Record(event); err != nil {
+			s.logger.Error("Failed to record activity", "event", eventType, "error", err)
+		}
+	}
+}
+
+// publishError 发布错误事件
+func (s *Server) publishError(operation string, err error) {
+	s.publish(models.EventError, map[string]interface{}{
+		"operation": operation,
+		"error":     err.Error(),
+	})
+}
+
+// Subscribe 订阅守护进程事件
+func (s *Server) Subscribe(types ...models.EventType) (<-chan models.Event, func()) {
+	return s.events.Subscribe(types)
+}
+
+// handleEvents 以Server-Sent Events推送事件，可通过type参数按逗号分隔过滤事件类型
+func (s *Server) handleEvents(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, fmt.Errorf("streaming is not supported"))
+		return
+	}
+
+	events, cancel := s.events.Subscribe(parseEventTypes(r.URL.Query().Get("type")))
+	defer cancel()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprint(w, ": connected\n\n")
+	flusher.Flush()
+
+	heartbeat := time.NewTicker(heartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-heartbeat.C:
+			fmt.Fprint(w, ": ping\n\n")
+			flusher.Flush()
+		case event, ok := <-events:
+			if !ok {
+				return
+			}
+			data, err := json.Marshal(event)
+			if err != nil {
+				continue
+			}
+			fmt.Fprintf(w, "id: %s\nevent: %s\ndata: %s\n\n", event.ID, event.Type, data)
+			flusher.Flush()
+		}
+	}
+}
+
+// parseEventTypes 解析逗号分隔的事件类型
+func parseEventTypes(value string) []models.EventType {
+	var types []models.EventType
+	for _, part := range strings.Split(value, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			types = append(types, models.EventType(part))
+		}
+	}
+	return types
+}
+
+// Events 订阅守护进程事件流，ctx取消或连接断开时关闭返回的通道
+func (c *Client) Events(ctx context.Context, types ...models.EventType) (<-chan models.Event, error) {
+	names := make([]string, 0, len(types))
+	for _, t := range types {
+		names = append(names, string(t))
+	}
+
+	path := "http://mhostd/v1/events"
+	if len(names) > 0 {
+		path += "?type=" + url.QueryEscape(strings.Join(names, ","))
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, path, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// 事件流是长连接，不使用普通请求的超时
+	streamClient := &http.Client{Transport: c.httpClient.Transport}
+	resp, err := streamClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to daemon at %s: %w", c.socketPath, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("daemon error: %s", resp.Status)
+	}
+
+	events := make(chan models.Event)
+	go func() {
+		defer close(events)
+		defer resp.Body.Close()
+
+		scanner := bufio.NewScanner(resp.Body)
+		scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+		for scanner.Scan() {
+			line := scanner.Text()
+			if !strings.HasPrefix(line, "data: ") {
+				continue
+			}
+
+			var event models.Event
+			if err := json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &event); err != nil {
+				continue
+			}
+			select {
+			case events <- event:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return events, nil
+}
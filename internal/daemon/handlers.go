@@ -0,0 +1,159 @@
+package daemon
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/flyhigher139/mhost/internal/cli"
+	"github.com/flyhigher139/mhost/internal/host"
+	"github.com/flyhigher139/mhost/pkg/models"
+)
+
+// KindDaemonStatus 守护进程状态文档类型
+const KindDaemonStatus cli.Kind = "daemon_status"
+
+// errorResponse 错误响应
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+// routes 注册控制接口
+func (s *Server) routes() *http.ServeMux {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /v1/status", s.handleStatus)
+	mux.HandleFunc("GET /v1/profiles", s.handleListProfiles)
+	mux.HandleFunc("GET /v1/profiles/{profile}", s.handleGetProfile)
+	mux.HandleFunc("POST /v1/profiles/{profile}/apply", s.handleApply)
+	mux.HandleFunc("GET /v1/diff", s.handleDiff)
+	mux.HandleFunc("POST /v1/reload", s.handleReload)
+	mux.HandleFunc("GET /v1/events", s.handleEvents)
+	mux.HandleFunc("GET /v1/backups", s.handleListBackups)
+	mux.HandleFunc("POST /v1/backups", s.handleCreateBackup)
+	mux.HandleFunc("POST "+rpcPath, s.handleRPC)
+	mux.HandleFunc("POST /v1/switch/{query}", s.handleSwitch)
+	return mux
+}
+
+// handleStatus 返回守护进程状态
+func (s *Server) handleStatus(w http.ResponseWriter, r *http.Request) {
+	writeDocument(w, KindDaemonStatus, s.Status())
+}
+
+// handleListProfiles 返回所有Profile
+func (s *Server) handleListProfiles(w http.ResponseWriter, r *http.Request) {
+	summaries, err := s.profileManager.ListProfiles()
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	profiles := make([]*models.Profile, 0, len(summaries))
+	for _, summary := range summaries {
+		if p, err := s.profileManager.GetProfile(summary.ID); err == nil {
+			profiles = append(profiles, p)
+		}
+	}
+	writeDocument(w, cli.KindProfileList, cli.NewProfiles(profiles))
+}
+
+// handleGetProfile 按ID或名称返回Profile
+func (s *Server) handleGetProfile(w http.ResponseWriter, r *http.Request) {
+	p, err := s.ResolveProfile(r.PathValue("profile"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeDocument(w, cli.KindProfile, cli.NewProfile(p))
+}
+
+// handleApply 激活并应用Profile
+func (s *Server) handleApply(w http.ResponseWriter, r *http.Request) {
+	apply := s.Apply
+	if r.URL.Query().Get("force") == "true" {
+		apply = s.ForceApply
+	}
+	result, err := apply(r.PathValue("profile"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeDocument(w, cli.KindApplyResult, result)
+}
+
+// handleSwitch 模糊匹配并应用Profile
+func (s *Server) handleSwitch(w http.ResponseWriter, r *http.Request) {
+	result, err := s.Switch(r.PathValue("query"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeDocument(w, cli.KindSwitchResult, result)
+}
+
+// handleDiff 返回激活Profile与hosts文件之间的差异
+func (s *Server) handleDiff(w http.ResponseWriter, r *http.Request) {
+	diff, err := s.Diff()
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeDocument(w, cli.KindDiff, diff)
+}
+
+// handleReload 从磁盘重新加载Profile
+func (s *Server) handleReload(w http.ResponseWriter, r *http.Request) {
+	if err := s.Reload(); err != nil {
+		writeError(w, err)
+		return
+	}
+	writeDocument(w, KindDaemonStatus, s.Status())
+}
+
+// handleListBackups 返回hosts备份列表
+func (s *Server) handleListBackups(w http.ResponseWriter, r *http.Request) {
+	backups, err := s.ListBackups()
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeDocument(w, cli.KindBackupList, backups)
+}
+
+// handleCreateBackup 备份当前hosts文件
+func (s *Server) handleCreateBackup(w http.ResponseWriter, r *http.Request) {
+	backup, err := s.CreateBackup()
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeDocument(w, cli.KindBackup, backup)
+}
+
+// writeDocument 输出JSON文档
+func writeDocument(w http.ResponseWriter, kind cli.Kind, data interface{}) {
+	writeJSON(w, http.StatusOK, cli.NewDocument(kind, data))
+}
+
+// writeError 根据错误类型输出对应状态码
+func writeError(w http.ResponseWriter, err error) {
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, models.ErrProfileNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, models.ErrInvalidProfile):
+		status = http.StatusBadRequest
+	case errors.Is(err, ErrAmbiguousProfile):
+		status = http.StatusConflict
+	case errors.Is(err, host.ErrLimitExceeded):
+		status = http.StatusUnprocessableEntity
+	}
+	writeJSON(w, status, &errorResponse{Error: err.Error()})
+}
+
+// writeJSON 输出JSON响应
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
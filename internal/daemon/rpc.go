@@ -0,0 +1,369 @@
+package daemon
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/flyhigher139/mhost/internal/cli"
+	"github.com/flyhigher139/mhost/internal/host"
+	"github.com/flyhigher139/mhost/pkg/hostsfile"
+	"github.com/flyhigher139/mhost/pkg/models"
+)
+
+// RPCSchemaVersion 编辑器集成JSON-RPC接口的结构版本，方法或字段发生不兼容变化时递增
+const RPCSchemaVersion = "1"
+
+// rpcPath JSON-RPC端点，路径中包含结构版本
+const rpcPath = "/rpc/v" + RPCSchemaVersion
+
+// JSON-RPC 2.0错误码
+const (
+	RPCParseError     = -32700
+	RPCInvalidRequest = -32600
+	RPCMethodNotFound = -32601
+	RPCInvalidParams  = -32602
+	RPCInternalError  = -32603
+	RPCNotFound       = -32004
+	RPCReadOnly       = -32005
+)
+
+// RPC方法名
+const (
+	RPCMethodDescribe = "rpc.describe"
+	RPCMethodResolve  = "hosts.resolve"
+	RPCMethodToggle   = "entries.toggle"
+)
+
+// RPCRequest JSON-RPC请求
+type RPCRequest struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+// RPCResponse JSON-RPC响应
+type RPCResponse struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  interface{}     `json:"result,omitempty"`
+	Error   *RPCError       `json:"error,omitempty"`
+}
+
+// RPCError JSON-RPC错误
+type RPCError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// Error 实现error接口
+func (e *RPCError) Error() string {
+	return fmt.Sprintf("rpc error %d: %s", e.Code, e.Message)
+}
+
+// RPCDescription rpc.describe的结果
+type RPCDescription struct {
+	SchemaVersion string   `json:"schema_version"`
+	Methods       []string `json:"methods"`
+}
+
+// ResolveParams hosts.resolve参数
+type ResolveParams struct {
+	Hostname string `json:"hostname"`
+}
+
+// HostsMatch hosts文件中匹配主机名的一行
+type HostsMatch struct {
+	IP      string `json:"ip"`
+	Line    int    `json:"line"`
+	Source  string `json:"source"` // managed 或 system
+	Comment string `json:"comment"`
+}
+
+// ResolveResult hosts.resolve结果
+type ResolveResult struct {
+	Hostname      string       `json:"hostname"`
+	Resolved      bool         `json:"resolved"`
+	IP            string       `json:"ip"`
+	Source        string       `json:"source"` // managed、system，未匹配时为空
+	Matches       []HostsMatch `json:"matches"`
+	ActiveProfile *ProfileRef  `json:"active_profile"`
+	ProfileEntry  *cli.Entry   `json:"profile_entry"`
+}
+
+// ToggleParams entries.toggle参数
+type ToggleParams struct {
+	Hostname string `json:"hostname"`
+	Profile  string `json:"profile,omitempty"` // Profile ID或名称，默认为激活的Profile
+	Enabled  *bool  `json:"enabled,omitempty"` // 指定目标状态，未指定时取反
+}
+
+// ToggleResult entries.toggle结果
+type ToggleResult struct {
+	Profile ProfileRef `json:"profile"`
+	Entry   cli.Entry  `json:"entry"`
+	Applied bool       `json:"applied"`
+}
+
+// Hosts匹配来源
+const (
+	SourceManaged = "managed"
+	SourceSystem  = "system"
+)
+
+// rpcMethods 支持的方法
+var rpcMethods = []string{RPCMethodDescribe, RPCMethodResolve, RPCMethodToggle}
+
+// handleRPC 处理JSON-RPC请求
+func (s *Server) handleRPC(w http.ResponseWriter, r *http.Request) {
+	var req RPCRequest
+	if err := json.NewDecoder(io.LimitReader(r.Body, 1<<20)).Decode(&req); err != nil {
+		writeJSON(w, http.StatusOK, &RPCResponse{JSONRPC: "2.0", ID: json.RawMessage("null"), Error: &RPCError{Code: RPCParseError, Message: err.Error()}})
+		return
+	}
+
+	id := req.ID
+	if len(id) == 0 {
+		id = json.RawMessage("null")
+	}
+
+	resp := &RPCResponse{JSONRPC: "2.0", ID: id}
+	if req.JSONRPC != "2.0" || req.Method == "" {
+		resp.Error = &RPCError{Code: RPCInvalidRequest, Message: "invalid JSON-RPC 2.0 request"}
+	} else {
+		resp.Result, resp.Error = s.dispatchRPC(req.Method, req.Params)
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// dispatchRPC 调用RPC方法
+func (s *Server) dispatchRPC(method string, params json.RawMessage) (interface{}, *RPCError) {
+	switch method {
+	case RPCMethodDescribe:
+		return &RPCDescription{SchemaVersion: RPCSchemaVersion, Methods: rpcMethods}, nil
+
+	case RPCMethodResolve:
+		var p ResolveParams
+		if err := decodeParams(params, &p); err != nil || strings.TrimSpace(p.Hostname) == "" {
+			return nil, &RPCError{Code: RPCInvalidParams, Message: "hostname is required"}
+		}
+		result, err := s.Resolve(p.Hostname)
+		return result, toRPCError(err)
+
+	case RPCMethodToggle:
+		var p ToggleParams
+		if err := decodeParams(params, &p); err != nil || strings.TrimSpace(p.Hostname) == "" {
+			return nil, &RPCError{Code: RPCInvalidParams, Message: "hostname is required"}
+		}
+		result, err := s.ToggleEntry(p)
+		return result, toRPCError(err)
+	}
+
+	return nil, &RPCError{Code: RPCMethodNotFound, Message: fmt.Sprintf("method not found: %s", method)}
+}
+
+// decodeParams 解析参数
+func decodeParams(params json.RawMessage, v interface{}) error {
+	if len(params) == 0 {
+		return fmt.Errorf("missing params")
+	}
+	return json.Unmarshal(params, v)
+}
+
+// toRPCError 转换业务错误
+func toRPCError(err error) *RPCError {
+	if err == nil {
+		return nil
+	}
+	if errors.Is(err, models.ErrProfileNotFound) || errors.Is(err, models.ErrHostEntryNotFound) {
+		return &RPCError{Code: RPCNotFound, Message: err.Error()}
+	}
+	if errors.Is(err, models.ErrReadOnlyProfile) {
+		return &RPCError{Code: RPCReadOnly, Message: err.Error()}
+	}
+	return &RPCError{Code: RPCInternalError, Message: err.Error()}
+}
+
+// Resolve 查询主机名当前在hosts文件中的解析结果（以第一条匹配为准）
+func (s *Server) Resolve(hostname string) (*ResolveResult, error) {
+	hostname = hostsfile.NormalizeHostname(hostname)
+
+	lines, err := s.hostManager.ReadHostsFile()
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ResolveResult{Hostname: hostname, Matches: []HostsMatch{}}
+	inManaged := false
+	for i, raw := range lines {
+		switch strings.TrimSpace(raw) {
+		case hostsfile.StartMarker:
+			inManaged = true
+			continue
+		case hostsfile.EndMarker:
+			inManaged = false
+			continue
+		}
+
+		line, ok := hostsfile.ParseLine(raw)
+		if !ok || line.Disabled {
+			continue
+		}
+		for _, name := range line.Hostnames {
+			if !strings.EqualFold(name, hostname) {
+				continue
+			}
+			source := SourceSystem
+			if inManaged {
+				source = SourceManaged
+			}
+			result.Matches = append(result.Matches, HostsMatch{IP: line.IP, Line: i + 1, Source: source, Comment: line.Comment})
+			break
+		}
+	}
+
+	if len(result.Matches) > 0 {
+		result.Resolved = true
+		result.IP = result.Matches[0].IP
+		result.Source = result.Matches[0].Source
+	}
+
+	if active, err := s.profileManager.GetActiveProfile(); err == nil && active != nil {
+		result.ActiveProfile = &ProfileRef{ID: active.ID, Name: active.Name}
+		if entry := findEntry(active, hostname); entry != nil {
+			e := cli.NewEntry(entry)
+			result.ProfileEntry = &e
+		}
+	}
+
+	return result, nil
+}
+
+// ToggleEntry 切换Profile中主机名对应条目的启用状态；Profile已激活时同步更新hosts文件
+func (s *Server) ToggleEntry(params ToggleParams) (*ToggleResult, error) {
+	s.applyMu.Lock()
+	defer s.applyMu.Unlock()
+
+	var p *models.Profile
+	var err error
+	if params.Profile == "" {
+		p, err = s.profileManager.GetActiveProfile()
+	} else {
+		p, err = s.ResolveProfile(params.Profile)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	entry := findEntry(p, params.Hostname)
+	if entry == nil {
+		return nil, models.ErrHostEntryNotFound
+	}
+
+	enabled := !entry.Enabled
+	if params.Enabled != nil {
+		enabled = *params.Enabled
+	}
+
+	result := &ToggleResult{Profile: ProfileRef{ID: p.ID, Name: p.Name}}
+	if entry.Enabled != enabled {
+		entry.Enabled = enabled
+		entry.UpdatedAt = time.Now()
+		if err := s.profileManager.UpdateProfile(p); err != nil {
+			return nil, err
+		}
+
+		if p.IsActive {
+			var err error
+			if active, activeErr := s.profileManager.GetActiveProfiles(); activeErr == nil && len(active) > 1 {
+				// 同时激活多个Profile时条目可能被其他Profile覆盖，重新叠加应用
+				_, err = s.hostManager.ApplyProfiles(active, host.ApplyOptions{})
+			} else {
+				err = s.hostManager.PatchManagedEntry(p.ResolveEntry(entry))
+				if errors.Is(err, hostsfile.ErrNoManagedSection) || errors.Is(err, host.ErrPatchUnavailable) {
+					_, err = s.hostManager.ApplyProfile(p)
+				}
+			}
+			if err != nil {
+				s.publishError("toggle", err)
+				return nil, err
+			}
+			result.Applied = true
+		}
+
+		s.publish(models.EventHostEntryToggled, map[string]interface{}{
+			"profile_id": p.ID,
+			"hostname":   entry.Hostname,
+			"enabled":    entry.Enabled,
+		})
+	}
+
+	result.Entry = cli.NewEntry(entry)
+	return result, nil
+}
+
+// findEntry 按主机名（不区分大小写）查找Profile中的第一个条目
+func findEntry(p *models.Profile, hostname string) *models.HostEntry {
+	hostname = strings.TrimSpace(hostname)
+	for _, entry := range p.Entries {
+		if entry != nil && strings.EqualFold(entry.Hostname, hostname) {
+			return entry
+		}
+	}
+	return nil
+}
+
+// rpcRequestID 客户端请求ID计数器
+var rpcRequestID int64
+
+// Call 调用JSON-RPC方法并将结果解析到result
+func (c *Client) Call(ctx context.Context, method string, params, result interface{}) error {
+	req := map[string]interface{}{
+		"jsonrpc": "2.0",
+		"id":      atomic.AddInt64(&rpcRequestID, 1),
+		"method":  method,
+	}
+	if params != nil {
+		req["params"] = params
+	}
+
+	body, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, "http://mhostd"+rpcPath, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.httpClient.Do(httpReq)
+	if err != nil {
+		return fmt.Errorf("failed to connect to daemon at %s: %w", c.socketPath, err)
+	}
+	defer resp.Body.Close()
+
+	var rpcResp struct {
+		Result json.RawMessage `json:"result"`
+		Error  *RPCError       `json:"error"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&rpcResp); err != nil {
+		return fmt.Errorf("failed to decode rpc response: %w", err)
+	}
+	if rpcResp.Error != nil {
+		return rpcResp.Error
+	}
+	if result == nil {
+		return nil
+	}
+	return json.Unmarshal(rpcResp.Result, result)
+}
@@ -0,0 +1,115 @@
+package daemon
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/flyhigher139/mhost/pkg/models"
+)
+
+// rpcErrorCode 获取RPC错误码
+func rpcErrorCode(t *testing.T, err error) int {
+	var rpcErr *RPCError
+	require.True(t, errors.As(err, &rpcErr), "expected rpc error, got %v", err)
+	return rpcErr.Code
+}
+
+// TestRPCResolveAndToggle 测试解析查询与条目切换
+func TestRPCResolveAndToggle(t *testing.T) {
+	server, client, _, hostsPath := newTestDaemon(t)
+	ctx := context.Background()
+
+	p, err := server.profileManager.CreateProfile("Dev", "")
+	require.NoError(t, err)
+	p.AddEntry(models.NewHostEntry("10.0.0.1", "api.test", "staging"))
+	p.AddEntry(models.NewHostEntry("10.0.0.2", "localhost", "shadowed"))
+	require.NoError(t, server.profileManager.UpdateProfile(p))
+	_, err = server.Apply("Dev")
+	require.NoError(t, err)
+
+	var desc RPCDescription
+	require.NoError(t, client.Call(ctx, RPCMethodDescribe, nil, &desc))
+	assert.Equal(t, RPCSchemaVersion, desc.SchemaVersion)
+	assert.Contains(t, desc.Methods, RPCMethodResolve)
+
+	var resolved ResolveResult
+	require.NoError(t, client.Call(ctx, RPCMethodResolve, ResolveParams{Hostname: "API.test"}, &resolved))
+	assert.True(t, resolved.Resolved)
+	assert.Equal(t, "10.0.0.1", resolved.IP)
+	assert.Equal(t, SourceManaged, resolved.Source)
+	require.NotNil(t, resolved.ProfileEntry)
+	assert.Equal(t, "staging", resolved.ProfileEntry.Comment)
+	assert.Equal(t, "Dev", resolved.ActiveProfile.Name)
+
+	// 系统条目先于管理section出现，优先生效
+	require.NoError(t, client.Call(ctx, RPCMethodResolve, ResolveParams{Hostname: "localhost"}, &resolved))
+	assert.Equal(t, "127.0.0.1", resolved.IP)
+	assert.Equal(t, SourceSystem, resolved.Source)
+	require.Len(t, resolved.Matches, 2)
+	assert.Equal(t, SourceManaged, resolved.Matches[1].Source)
+
+	require.NoError(t, client.Call(ctx, RPCMethodResolve, ResolveParams{Hostname: "unknown.test"}, &resolved))
+	assert.False(t, resolved.Resolved)
+	assert.Empty(t, resolved.Matches)
+	assert.Nil(t, resolved.ProfileEntry)
+
+	// 切换激活Profile中的条目会同步更新hosts文件
+	var toggled ToggleResult
+	require.NoError(t, client.Call(ctx, RPCMethodToggle, ToggleParams{Hostname: "api.test"}, &toggled))
+	assert.False(t, toggled.Entry.Enabled)
+	assert.True(t, toggled.Applied)
+
+	data, err := os.ReadFile(hostsPath)
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "api.test")
+
+	enabled := true
+	require.NoError(t, client.Call(ctx, RPCMethodToggle, ToggleParams{Hostname: "api.test", Profile: "dev", Enabled: &enabled}, &toggled))
+	assert.True(t, toggled.Entry.Enabled)
+
+	// 已是目标状态时不写入
+	require.NoError(t, client.Call(ctx, RPCMethodToggle, ToggleParams{Hostname: "api.test", Enabled: &enabled}, &toggled))
+	assert.False(t, toggled.Applied)
+
+	stored, err := server.profileManager.GetProfile(p.ID)
+	require.NoError(t, err)
+	assert.True(t, stored.Entries[0].Enabled)
+}
+
+// TestRPCErrors 测试RPC错误码
+func TestRPCErrors(t *testing.T) {
+	server, client, _, _ := newTestDaemon(t)
+	ctx := context.Background()
+
+	_, err := server.profileManager.CreateProfile("Dev", "")
+	require.NoError(t, err)
+
+	err = client.Call(ctx, "hosts.unknown", nil, nil)
+	assert.Equal(t, RPCMethodNotFound, rpcErrorCode(t, err))
+
+	err = client.Call(ctx, RPCMethodResolve, nil, nil)
+	assert.Equal(t, RPCInvalidParams, rpcErrorCode(t, err))
+
+	err = client.Call(ctx, RPCMethodToggle, ToggleParams{Hostname: "missing.test"}, nil)
+	assert.Equal(t, RPCNotFound, rpcErrorCode(t, err))
+
+	err = client.Call(ctx, RPCMethodToggle, ToggleParams{Hostname: "api.test", Profile: "missing"}, nil)
+	assert.Equal(t, RPCNotFound, rpcErrorCode(t, err))
+
+	// 同步资产清单的只读Profile中的条目不能切换
+	p, err := server.profileManager.CreateProfile("CMDB", "")
+	require.NoError(t, err)
+	p.AddEntry(models.NewHostEntry("10.0.0.1", "web.test", ""))
+	p.Inventory = &models.Inventory{URL: "https://cmdb.test/machines"}
+	require.NoError(t, server.profileManager.SyncInventoryProfile(p))
+	err = client.Call(ctx, RPCMethodToggle, ToggleParams{Hostname: "web.test", Profile: "CMDB"}, nil)
+	assert.Equal(t, RPCReadOnly, rpcErrorCode(t, err))
+	stored, err := server.profileManager.GetProfile(p.ID)
+	require.NoError(t, err)
+	assert.True(t, stored.Entries[0].Enabled)
+}
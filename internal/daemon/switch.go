@@ -0,0 +1,119 @@
+package daemon
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/flyhigher139/mhost/internal/cli"
+	"github.com/flyhigher139/mhost/pkg/models"
+)
+
+// 模糊匹配得分，越高越优先
+const (
+	scoreExactID     = 1000
+	scoreExactName   = 900
+	scorePrefix      = 700
+	scoreSubstring   = 500
+	scoreSubsequence = 300
+)
+
+// ErrAmbiguousProfile 模糊匹配到多个同分Profile
+var ErrAmbiguousProfile = errors.New("ambiguous profile name")
+
+// ProfileMatch Profile模糊匹配结果
+type ProfileMatch struct {
+	ID    string `json:"id"`
+	Name  string `json:"name"`
+	Score int    `json:"score"`
+}
+
+// MatchProfiles 按模糊匹配得分降序返回与查询匹配的Profile
+func MatchProfiles(query string, summaries []*models.ProfileSummary) []ProfileMatch {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return nil
+	}
+
+	var matches []ProfileMatch
+	for _, summary := range summaries {
+		if score := matchScore(query, summary); score > 0 {
+			matches = append(matches, ProfileMatch{ID: summary.ID, Name: summary.Name, Score: score})
+		}
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		if matches[i].Score != matches[j].Score {
+			return matches[i].Score > matches[j].Score
+		}
+		return matches[i].Name < matches[j].Name
+	})
+	return matches
+}
+
+// matchScore 计算查询与Profile的匹配得分，0表示不匹配
+func matchScore(query string, summary *models.ProfileSummary) int {
+	if summary.ID == query {
+		return scoreExactID
+	}
+
+	q := strings.ToLower(query)
+	name := strings.ToLower(summary.Name)
+	switch {
+	case name == q:
+		return scoreExactName
+	case strings.HasPrefix(name, q):
+		return scorePrefix - min(len(name)-len(q), 100)
+	case strings.Contains(name, q):
+		return scoreSubstring - min(strings.Index(name, q), 100)
+	}
+
+	// 子序列匹配，例如 "stg" 匹配 "staging"，间隔越少得分越高
+	gaps, pos := 0, 0
+	for _, r := range q {
+		idx := strings.IndexRune(name[pos:], r)
+		if idx < 0 {
+			return 0
+		}
+		gaps += idx
+		pos += idx + len(string(r))
+	}
+	return scoreSubsequence - min(gaps, 100)
+}
+
+// Switch 模糊匹配Profile名称并应用，存在多个同分候选时返回错误
+func (s *Server) Switch(query string) (*cli.SwitchResult, error) {
+	start := time.Now()
+
+	summaries, err := s.profileManager.ListProfiles()
+	if err != nil {
+		return nil, err
+	}
+
+	matches := MatchProfiles(query, summaries)
+	if len(matches) == 0 {
+		return nil, fmt.Errorf("%w: %q", models.ErrProfileNotFound, query)
+	}
+	if len(matches) > 1 && matches[0].Score == matches[1].Score {
+		var names []string
+		for _, m := range matches {
+			if m.Score == matches[0].Score {
+				names = append(names, m.Name)
+			}
+		}
+		return nil, fmt.Errorf("%w %q: matches %s", ErrAmbiguousProfile, query, strings.Join(names, ", "))
+	}
+
+	result, err := s.Apply(matches[0].ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &cli.SwitchResult{
+		Query:      query,
+		Apply:      *result,
+		DurationMS: time.Since(start).Milliseconds(),
+	}, nil
+}
@@ -0,0 +1,87 @@
+package daemon
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/flyhigher139/mhost/pkg/models"
+)
+
+// TestMatchProfiles 测试模糊匹配排序
+func TestMatchProfiles(t *testing.T) {
+	summaries := []*models.ProfileSummary{
+		{ID: "1", Name: "Staging"},
+		{ID: "2", Name: "Production"},
+		{ID: "3", Name: "Stage Two"},
+		{ID: "4", Name: "prod-eu"},
+	}
+
+	names := func(matches []ProfileMatch) []string {
+		var result []string
+		for _, m := range matches {
+			result = append(result, m.Name)
+		}
+		return result
+	}
+
+	assert.Equal(t, []string{"Production"}, names(MatchProfiles("2", summaries)))
+	assert.Equal(t, "Staging", MatchProfiles("staging", summaries)[0].Name)
+	assert.Equal(t, []string{"prod-eu", "Production"}, names(MatchProfiles("prod", summaries)))
+	assert.Equal(t, "Production", MatchProfiles("duct", summaries)[0].Name)
+	assert.Equal(t, []string{"Staging"}, names(MatchProfiles("stgng", summaries)))
+	assert.Empty(t, MatchProfiles("xyz", summaries))
+	assert.Empty(t, MatchProfiles(" ", summaries))
+}
+
+// TestDaemonSwitch 测试快速切换
+func TestDaemonSwitch(t *testing.T) {
+	server, client, _, _ := newTestDaemon(t)
+	ctx := context.Background()
+
+	for _, name := range []string{"Staging", "Production", "Prod EU"} {
+		p, err := server.profileManager.CreateProfile(name, "")
+		require.NoError(t, err)
+		p.AddEntry(models.NewHostEntry("10.0.0.1", "api.test", name))
+		require.NoError(t, server.profileManager.UpdateProfile(p))
+	}
+
+	result, err := client.Switch(ctx, "product")
+	require.NoError(t, err)
+	assert.Equal(t, "product", result.Query)
+	assert.Equal(t, "Production", result.Apply.ProfileName)
+	assert.True(t, result.Apply.Changed)
+	assert.GreaterOrEqual(t, result.DurationMS, int64(0))
+
+	result, err = client.Switch(ctx, "stg")
+	require.NoError(t, err)
+	assert.Equal(t, "Staging", result.Apply.ProfileName)
+
+	active, err := server.profileManager.GetActiveProfile()
+	require.NoError(t, err)
+	assert.Equal(t, "Staging", active.Name)
+
+	// "pro" 同时是Production与Prod EU的前缀，较短名称得分更高
+	result, err = client.Switch(ctx, "pro")
+	require.NoError(t, err)
+	assert.Equal(t, "Prod EU", result.Apply.ProfileName)
+
+	_, err = client.Switch(ctx, "missing")
+	assert.ErrorContains(t, err, "profile not found")
+}
+
+// TestDaemonSwitchAmbiguous 测试同分候选
+func TestDaemonSwitchAmbiguous(t *testing.T) {
+	server, _, _, _ := newTestDaemon(t)
+
+	_, err := server.profileManager.CreateProfile("dev-a", "")
+	require.NoError(t, err)
+	_, err = server.profileManager.CreateProfile("dev-b", "")
+	require.NoError(t, err)
+
+	_, err = server.Switch("dev")
+	assert.ErrorIs(t, err, ErrAmbiguousProfile)
+	assert.ErrorContains(t, err, "dev-a, dev-b")
+}
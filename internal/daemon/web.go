@@ -0,0 +1,105 @@
+package daemon
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"embed"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// TokenFileName 默认访问令牌文件名
+const TokenFileName = "daemon_token"
+
+//go:embed web
+var webFiles embed.FS
+
+// LoadOrCreateToken 读取访问令牌文件，不存在时生成随机令牌并以0600权限保存
+func LoadOrCreateToken(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err == nil {
+		if token := strings.TrimSpace(string(data)); token != "" {
+			return token, nil
+		}
+	} else if !os.IsNotExist(err) {
+		return "", fmt.Errorf("failed to read token file: %w", err)
+	}
+
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate token: %w", err)
+	}
+	token := hex.EncodeToString(buf)
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return "", fmt.Errorf("failed to create token directory: %w", err)
+	}
+	if err := os.WriteFile(path, []byte(token+"\n"), 0600); err != nil {
+		return "", fmt.Errorf("failed to write token file: %w", err)
+	}
+	return token, nil
+}
+
+// ServeWeb 在给定监听器上提供Web界面与需要令牌的API，非阻塞
+func (s *Server) ServeWeb(listener net.Listener) error {
+	if s.options.APIToken == "" {
+		return fmt.Errorf("web interface requires an API token")
+	}
+
+	static, err := fs.Sub(webFiles, "web")
+	if err != nil {
+		return err
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("GET /{$}", http.FileServer(http.FS(static)))
+	api := s.requireToken(s.routes())
+	mux.Handle("/v1/", api)
+	mux.Handle(rpcPath, api)
+
+	server := &http.Server{Handler: mux}
+
+	s.mu.Lock()
+	if s.listener == nil {
+		s.mu.Unlock()
+		return fmt.Errorf("daemon is not running")
+	}
+	if s.webServer != nil {
+		s.mu.Unlock()
+		return fmt.Errorf("web interface is already running")
+	}
+	s.webServer = server
+	s.mu.Unlock()
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		if err := server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			s.logger.Error("Daemon web server error", "addr", listener.Addr().String(), "error", err)
+		}
+	}()
+	return nil
+}
+
+// requireToken 校验Authorization: Bearer令牌；事件流无法设置请求头，允许使用token查询参数
+func (s *Server) requireToken(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if token == "" || token == r.Header.Get("Authorization") {
+			token = r.URL.Query().Get("token")
+		}
+
+		if subtle.ConstantTimeCompare([]byte(token), []byte(s.options.APIToken)) != 1 {
+			writeJSON(w, http.StatusUnauthorized, &errorResponse{Error: "invalid or missing API token"})
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
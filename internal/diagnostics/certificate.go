@@ -0,0 +1,98 @@
+package diagnostics
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"strconv"
+	"time"
+)
+
+// CertExpiryWarningDays 证书剩余有效期低于该天数时给出警告
+const CertExpiryWarningDays = 14
+
+// CertificateReport 证书检查结果
+type CertificateReport struct {
+	Hostname      string    `json:"hostname"`
+	IP            string    `json:"ip"`
+	CommonName    string    `json:"common_name"`
+	SANs          []string  `json:"sans"`
+	Issuer        string    `json:"issuer"`
+	NotBefore     time.Time `json:"not_before"`
+	NotAfter      time.Time `json:"not_after"`
+	DaysRemaining int       `json:"days_remaining"`
+	HostnameMatch bool      `json:"hostname_match"`
+	Expired       bool      `json:"expired"`
+	VerifyError   string    `json:"verify_error,omitempty"`
+	Warnings      []string  `json:"warnings,omitempty"`
+}
+
+// HasWarnings 访问时浏览器是否会给出警告
+func (r *CertificateReport) HasWarnings() bool {
+	return len(r.Warnings) > 0
+}
+
+// CheckCertificate 以hostname作为SNI连接IP，检查证书的主机名匹配、有效期和信任链；roots为nil时使用系统根证书
+func CheckCertificate(ctx context.Context, ip, hostname string, port int, roots *x509.CertPool) (*CertificateReport, error) {
+	if net.ParseIP(ip) == nil {
+		return nil, fmt.Errorf("invalid IP address: %q", ip)
+	}
+	if port <= 0 {
+		port = 443
+	}
+
+	dialer := &tls.Dialer{
+		Config: &tls.Config{
+			ServerName: hostname,
+			// 由下面手动验证，以便报告具体问题而不是直接失败
+			InsecureSkipVerify: true,
+		},
+	}
+	conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(ip, strconv.Itoa(port)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect: %w", err)
+	}
+	defer conn.Close()
+
+	state := conn.(*tls.Conn).ConnectionState()
+	if len(state.PeerCertificates) == 0 {
+		return nil, fmt.Errorf("no certificate presented by %s", ip)
+	}
+	leaf := state.PeerCertificates[0]
+
+	now := time.Now()
+	report := &CertificateReport{
+		Hostname:      hostname,
+		IP:            ip,
+		CommonName:    leaf.Subject.CommonName,
+		SANs:          leaf.DNSNames,
+		Issuer:        leaf.Issuer.CommonName,
+		NotBefore:     leaf.NotBefore,
+		NotAfter:      leaf.NotAfter,
+		DaysRemaining: int(leaf.NotAfter.Sub(now).Hours() / 24),
+		HostnameMatch: leaf.VerifyHostname(hostname) == nil,
+		Expired:       now.After(leaf.NotAfter) || now.Before(leaf.NotBefore),
+	}
+
+	if !report.HostnameMatch {
+		report.Warnings = append(report.Warnings, fmt.Sprintf("certificate does not match hostname %s", hostname))
+	}
+	if report.Expired {
+		report.Warnings = append(report.Warnings, "certificate is expired or not yet valid")
+	} else if report.DaysRemaining < CertExpiryWarningDays {
+		report.Warnings = append(report.Warnings, fmt.Sprintf("certificate expires in %d days", report.DaysRemaining))
+	}
+
+	intermediates := x509.NewCertPool()
+	for _, cert := range state.PeerCertificates[1:] {
+		intermediates.AddCert(cert)
+	}
+	if _, err := leaf.Verify(x509.VerifyOptions{Roots: roots, Intermediates: intermediates, CurrentTime: now}); err != nil {
+		report.VerifyError = err.Error()
+		report.Warnings = append(report.Warnings, "certificate is not trusted")
+	}
+
+	return report, nil
+}
@@ -0,0 +1,306 @@
+package diagnostics
+
+import (
+	"context"
+	"crypto/x509"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/dns/dnsmessage"
+
+	"github.com/flyhigher139/mhost/pkg/models"
+)
+
+// newTestIPInfoService 创建使用模拟查询的IP信息服务
+func newTestIPInfoService(t *testing.T, online *bool) *IPInfoService {
+	s := NewIPInfoService(t.TempDir())
+	s.lookupAddr = func(ctx context.Context, ip string) ([]string, error) {
+		if !*online {
+			return nil, errors.New("offline")
+		}
+		return []string{"host.example.com."}, nil
+	}
+	s.whois = func(ctx context.Context, ip string) (string, error) {
+		if !*online {
+			return "", errors.New("offline")
+		}
+		return "Example Org", nil
+	}
+	s.geo = func(ctx context.Context, ip string) (*GeoInfo, error) {
+		if !*online {
+			return nil, errors.New("offline")
+		}
+		return &GeoInfo{Country: "Japan", City: "Tokyo"}, nil
+	}
+	return s
+}
+
+// TestIPInfoLookup 测试IP信息查询与离线回退
+func TestIPInfoLookup(t *testing.T) {
+	online := true
+	s := newTestIPInfoService(t, &online)
+
+	info, err := s.Lookup(context.Background(), "8.8.8.8")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"host.example.com"}, info.ReverseDNS)
+	assert.Equal(t, "Example Org", info.WhoisOwner)
+	assert.Equal(t, "Japan", info.Country)
+	assert.False(t, info.Stale)
+
+	// 缓存过期后离线查询回退到缓存
+	s.ttl = 0
+	online = false
+	info, err = s.Lookup(context.Background(), "8.8.8.8")
+	require.NoError(t, err)
+	assert.True(t, info.Stale)
+	assert.Equal(t, "Example Org", info.WhoisOwner)
+
+	// 缓存持久化到磁盘
+	reloaded := NewIPInfoService(filepath.Dir(s.cacheFile))
+	assert.Contains(t, reloaded.cache, "8.8.8.8")
+
+	// 私有地址不查询whois和地理信息
+	online = true
+	info, err = s.Lookup(context.Background(), "192.168.1.1")
+	require.NoError(t, err)
+	assert.True(t, info.Private)
+	assert.Empty(t, info.WhoisOwner)
+
+	_, err = s.Lookup(context.Background(), "not-an-ip")
+	assert.Error(t, err)
+}
+
+// TestParseWhoisOwner 测试解析whois所有者
+func TestParseWhoisOwner(t *testing.T) {
+	response := "% comment\nrefer:        whois.arin.net\n\nNetName:  GOGL\nOrgName:  Google LLC\n"
+	assert.Equal(t, "whois.arin.net", parseWhoisField(response, "refer"))
+	assert.Equal(t, "Google LLC", parseWhoisOwner(response))
+	assert.Equal(t, "", parseWhoisOwner("% nothing here"))
+}
+
+// TestProbePorts 测试端口探测
+func TestProbePorts(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	openPort := listener.Addr().(*net.TCPAddr).Port
+
+	// 关闭另一个监听器得到一个未使用的端口
+	closed, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	closedPort := closed.Addr().(*net.TCPAddr).Port
+	closed.Close()
+	defer listener.Close()
+
+	results, err := ProbePorts(context.Background(), "127.0.0.1", []int{closedPort, openPort}, time.Second)
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+	for _, result := range results {
+		if result.Port == openPort {
+			assert.True(t, result.Open)
+		} else {
+			assert.False(t, result.Open)
+			assert.NotEmpty(t, result.Error)
+		}
+	}
+
+	_, err = ProbePorts(context.Background(), "bad-ip", nil, time.Second)
+	assert.Error(t, err)
+}
+
+// TestParsePorts 测试解析端口列表
+func TestParsePorts(t *testing.T) {
+	ports, err := ParsePorts("80, 443,80,,8080")
+	require.NoError(t, err)
+	assert.Equal(t, []int{80, 443, 8080}, ports)
+
+	_, err = ParsePorts("80,70000")
+	assert.Error(t, err)
+}
+
+// TestCheckCertificate 测试证书检查
+func TestCheckCertificate(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	addr := server.Listener.Addr().(*net.TCPAddr)
+	roots := x509.NewCertPool()
+	roots.AddCert(server.Certificate())
+
+	report, err := CheckCertificate(context.Background(), "127.0.0.1", "example.com", addr.Port, roots)
+	require.NoError(t, err)
+	assert.True(t, report.HostnameMatch)
+	assert.False(t, report.Expired)
+	assert.Empty(t, report.VerifyError)
+	assert.False(t, report.HasWarnings())
+
+	// 主机名不匹配且不受信任
+	report, err = CheckCertificate(context.Background(), "127.0.0.1", "api.test", addr.Port, x509.NewCertPool())
+	require.NoError(t, err)
+	assert.False(t, report.HostnameMatch)
+	assert.NotEmpty(t, report.VerifyError)
+	assert.Len(t, report.Warnings, 2)
+}
+
+// TestCheckHTTP 测试带Host头的HTTP检查
+func TestCheckHTTP(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host, _, _ := net.SplitHostPort(r.Host)
+		if host == "old.test" {
+			http.Redirect(w, r, "https://new.test/", http.StatusMovedPermanently)
+			return
+		}
+		w.WriteHeader(http.StatusTeapot)
+	}))
+	defer server.Close()
+
+	options := HTTPCheckOptions{Scheme: "http", Port: server.Listener.Addr().(*net.TCPAddr).Port, Timeout: time.Second}
+
+	result, err := CheckHTTP(context.Background(), "127.0.0.1", "api.test", options)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusTeapot, result.StatusCode)
+	assert.Contains(t, result.URL, "api.test")
+
+	// 不跟随重定向，返回重定向目标
+	result, err = CheckHTTP(context.Background(), "127.0.0.1", "old.test", options)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusMovedPermanently, result.StatusCode)
+	assert.Equal(t, "https://new.test/", result.RedirectTarget)
+
+	_, err = CheckHTTP(context.Background(), "127.0.0.1", "api.test", HTTPCheckOptions{Scheme: "ftp"})
+	assert.Error(t, err)
+}
+
+// startTestDNSServer 启动只应答固定记录的UDP DNS服务器
+func startTestDNSServer(t *testing.T, records map[string][]net.IP) string {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	go func() {
+		buf := make([]byte, 512)
+		for {
+			n, addr, err := conn.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			var req dnsmessage.Message
+			if err := req.Unpack(buf[:n]); err != nil || len(req.Questions) == 0 {
+				continue
+			}
+			q := req.Questions[0]
+			resp := dnsmessage.Message{
+				Header:    dnsmessage.Header{ID: req.ID, Response: true, RecursionAvailable: true},
+				Questions: req.Questions,
+			}
+			ips, ok := records[q.Name.String()]
+			if !ok {
+				resp.RCode = dnsmessage.RCodeNameError
+			}
+			for _, ip := range ips {
+				header := dnsmessage.ResourceHeader{Name: q.Name, Class: dnsmessage.ClassINET, TTL: 60}
+				if v4 := ip.To4(); v4 != nil && q.Type == dnsmessage.TypeA {
+					header.Type = dnsmessage.TypeA
+					resource := &dnsmessage.AResource{}
+					copy(resource.A[:], v4)
+					resp.Answers = append(resp.Answers, dnsmessage.Resource{Header: header, Body: resource})
+				} else if ip.To4() == nil && q.Type == dnsmessage.TypeAAAA {
+					header.Type = dnsmessage.TypeAAAA
+					resource := &dnsmessage.AAAAResource{}
+					copy(resource.AAAA[:], ip.To16())
+					resp.Answers = append(resp.Answers, dnsmessage.Resource{Header: header, Body: resource})
+				}
+			}
+			packet, err := resp.Pack()
+			if err == nil {
+				_, _ = conn.WriteTo(packet, addr)
+			}
+		}
+	}()
+
+	return conn.LocalAddr().String()
+}
+
+// TestDNSResolver 测试直接向DNS服务器查询
+func TestDNSResolver(t *testing.T) {
+	server := startTestDNSServer(t, map[string][]net.IP{
+		"api.example.com.": {net.ParseIP("2001:db8::1"), net.ParseIP("203.0.113.10")},
+	})
+	resolver := &DNSResolver{Nameservers: []string{server}, Timeout: time.Second}
+
+	ips, err := resolver.LookupIP(context.Background(), "API.Example.com.")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"203.0.113.10", "2001:db8::1"}, ips)
+
+	_, err = resolver.LookupIP(context.Background(), "missing.example.com")
+	assert.Error(t, err)
+
+	// 从resolv.conf读取DNS服务器
+	conf := filepath.Join(t.TempDir(), "resolv.conf")
+	require.NoError(t, os.WriteFile(conf, []byte("# test\nsearch example.com\nnameserver 127.0.0.1\nnameserver fe80::1%eth0\n"), 0644))
+	servers, err := (&DNSResolver{ResolvConf: conf}).nameservers()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"127.0.0.1:53", "[fe80::1]:53"}, servers)
+
+	empty := filepath.Join(t.TempDir(), "empty.conf")
+	require.NoError(t, os.WriteFile(empty, nil, 0644))
+	_, err = (&DNSResolver{ResolvConf: empty}).LookupIP(context.Background(), "api.example.com")
+	assert.ErrorIs(t, err, ErrNoNameservers)
+}
+
+// fakeResolver 返回固定结果的解析器
+type fakeResolver map[string][]string
+
+// LookupIP 实现IPResolver接口
+func (f fakeResolver) LookupIP(ctx context.Context, hostname string) ([]string, error) {
+	ips, ok := f[hostname]
+	if !ok {
+		return nil, ErrNoAnswer
+	}
+	return ips, nil
+}
+
+// TestRefreshPins 测试批量解析与重新解析固定条目
+func TestRefreshPins(t *testing.T) {
+	resolver := fakeResolver{
+		"api.test": {"203.0.113.10", "203.0.113.11"},
+		"web.test": {"198.51.100.1"},
+	}
+
+	results := ResolveAll(context.Background(), resolver, []string{"API.test", "api.test", "", "missing.test"})
+	require.Len(t, results, 2)
+	assert.Equal(t, "203.0.113.10", results[0].IP())
+	assert.NotEmpty(t, results[1].Error)
+	assert.Empty(t, results[1].IP())
+
+	profile := models.NewProfile("pins", "")
+	api := models.NewHostEntry("203.0.113.11", "api.test", "pinned=2025-07-01")
+	web := models.NewHostEntry("198.51.100.9", "web.test", "pinned=2025-07-01")
+	gone := models.NewHostEntry("192.0.2.1", "missing.test", "pinned=2025-07-01")
+	manual := models.NewHostEntry("127.0.0.1", "api.test", "manual")
+	for _, entry := range []*models.HostEntry{api, web, gone, manual} {
+		profile.AddEntry(entry)
+	}
+
+	changes := RefreshPins(context.Background(), resolver, profile)
+	require.Len(t, changes, 3)
+	assert.False(t, changes[0].Changed, "current IP is still among the answers")
+	assert.True(t, changes[1].Changed)
+	assert.Equal(t, "198.51.100.9", changes[1].OldIP)
+	assert.Equal(t, "198.51.100.1", changes[1].Result.IP())
+	assert.False(t, changes[2].Changed)
+	assert.NotEmpty(t, changes[2].Result.Error)
+
+	// 按当前环境比较变体IP
+	web.Variants = models.EntryVariants{"staging": "198.51.100.1"}
+	profile.Environment = "staging"
+	changes = RefreshPins(context.Background(), resolver, profile)
+	assert.False(t, changes[1].Changed)
+}
@@ -0,0 +1,266 @@
+package diagnostics
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net"
+	"os"
+	"strings"
+	"time"
+
+	"golang.org/x/net/dns/dnsmessage"
+
+	"github.com/flyhigher139/mhost/internal/httpclient"
+	"github.com/flyhigher139/mhost/pkg/hostsfile"
+)
+
+// DefaultResolvConf 默认的DNS配置文件
+const DefaultResolvConf = "/etc/resolv.conf"
+
+// ErrNoNameservers 没有可用的DNS服务器
+var ErrNoNameservers = errors.New("no nameservers configured")
+
+// ErrNoAnswer DNS服务器没有返回地址记录
+var ErrNoAnswer = errors.New("no A/AAAA records found")
+
+// DNSResolver 直接向DNS服务器查询主机名，结果不受hosts文件影响
+type DNSResolver struct {
+	Nameservers []string      // DNS服务器地址（host或host:port），为空时从ResolvConf读取
+	ResolvConf  string        // DNS配置文件路径
+	Timeout     time.Duration // 单个服务器的查询超时
+}
+
+// NewDNSResolver 创建使用系统DNS配置的解析器
+func NewDNSResolver() *DNSResolver {
+	return &DNSResolver{
+		ResolvConf: DefaultResolvConf,
+		Timeout:    3 * time.Second,
+	}
+}
+
+// LookupIP 查询主机名的A和AAAA记录，IPv4地址在前；依次尝试各DNS服务器
+func (r *DNSResolver) LookupIP(ctx context.Context, hostname string) ([]string, error) {
+	if httpclient.Default().Offline() {
+		return nil, httpclient.ErrOffline
+	}
+
+	hostname = hostsfile.NormalizeHostname(hostname)
+	if hostname == "" {
+		return nil, fmt.Errorf("hostname is required")
+	}
+
+	servers, err := r.nameservers()
+	if err != nil {
+		return nil, err
+	}
+
+	var lastErr error
+	for _, server := range servers {
+		var ips []string
+		for _, qtype := range []dnsmessage.Type{dnsmessage.TypeA, dnsmessage.TypeAAAA} {
+			answers, err := r.query(ctx, server, hostname, qtype)
+			if err != nil {
+				lastErr = err
+				continue
+			}
+			ips = append(ips, answers...)
+		}
+		if len(ips) > 0 {
+			return ips, nil
+		}
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+	}
+
+	if lastErr != nil {
+		return nil, fmt.Errorf("failed to resolve %s: %w", hostname, lastErr)
+	}
+	return nil, fmt.Errorf("failed to resolve %s: %w", hostname, ErrNoAnswer)
+}
+
+// nameservers 获取DNS服务器列表，补全默认端口
+func (r *DNSResolver) nameservers() ([]string, error) {
+	servers := r.Nameservers
+	if len(servers) == 0 {
+		path := r.ResolvConf
+		if path == "" {
+			path = DefaultResolvConf
+		}
+		parsed, err := readResolvConf(path)
+		if err != nil {
+			return nil, err
+		}
+		servers = parsed
+	}
+	if len(servers) == 0 {
+		return nil, ErrNoNameservers
+	}
+
+	result := make([]string, 0, len(servers))
+	for _, server := range servers {
+		if _, _, err := net.SplitHostPort(server); err != nil {
+			server = net.JoinHostPort(server, "53")
+		}
+		result = append(result, server)
+	}
+	return result, nil
+}
+
+// readResolvConf 读取resolv.conf中的nameserver
+func readResolvConf(path string) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	defer file.Close()
+
+	var servers []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) >= 2 && fields[0] == "nameserver" {
+			// 去掉IPv6链路本地地址的zone
+			servers = append(servers, strings.SplitN(fields[1], "%", 2)[0])
+		}
+	}
+	return servers, scanner.Err()
+}
+
+// query 向DNS服务器发送单个查询，响应被截断时改用TCP重试
+func (r *DNSResolver) query(ctx context.Context, server, hostname string, qtype dnsmessage.Type) ([]string, error) {
+	name, err := dnsmessage.NewName(hostname + ".")
+	if err != nil {
+		return nil, fmt.Errorf("invalid hostname %q: %w", hostname, err)
+	}
+
+	id := uint16(rand.Intn(1 << 16))
+	msg := dnsmessage.Message{
+		Header:    dnsmessage.Header{ID: id, RecursionDesired: true},
+		Questions: []dnsmessage.Question{{Name: name, Type: qtype, Class: dnsmessage.ClassINET}},
+	}
+	packet, err := msg.Pack()
+	if err != nil {
+		return nil, err
+	}
+
+	timeout := r.Timeout
+	if timeout <= 0 {
+		timeout = 3 * time.Second
+	}
+
+	response, err := exchange(ctx, "udp", server, packet, timeout)
+	if err != nil {
+		return nil, err
+	}
+	answers, truncated, err := parseAnswers(response, id, qtype)
+	if err == nil && truncated {
+		if response, err = exchange(ctx, "tcp", server, packet, timeout); err != nil {
+			return nil, err
+		}
+		answers, _, err = parseAnswers(response, id, qtype)
+	}
+	return answers, err
+}
+
+// exchange 发送DNS报文并读取响应，TCP报文带两字节长度前缀
+func exchange(ctx context.Context, network, server string, packet []byte, timeout time.Duration) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	dialer := &net.Dialer{}
+	conn, err := dialer.DialContext(ctx, network, server)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+
+	if network == "tcp" {
+		framed := make([]byte, 2+len(packet))
+		framed[0], framed[1] = byte(len(packet)>>8), byte(len(packet))
+		copy(framed[2:], packet)
+		if _, err := conn.Write(framed); err != nil {
+			return nil, err
+		}
+		var length [2]byte
+		if _, err := io.ReadFull(conn, length[:]); err != nil {
+			return nil, err
+		}
+		response := make([]byte, int(length[0])<<8|int(length[1]))
+		if _, err := io.ReadFull(conn, response); err != nil {
+			return nil, err
+		}
+		return response, nil
+	}
+
+	if _, err := conn.Write(packet); err != nil {
+		return nil, err
+	}
+	response := make([]byte, 4096)
+	n, err := conn.Read(response)
+	if err != nil {
+		return nil, err
+	}
+	return response[:n], nil
+}
+
+// parseAnswers 解析DNS响应中的地址记录
+func parseAnswers(response []byte, id uint16, qtype dnsmessage.Type) ([]string, bool, error) {
+	var parser dnsmessage.Parser
+	header, err := parser.Start(response)
+	if err != nil {
+		return nil, false, fmt.Errorf("invalid DNS response: %w", err)
+	}
+	if header.ID != id {
+		return nil, false, fmt.Errorf("DNS response ID mismatch")
+	}
+	if header.Truncated {
+		return nil, true, nil
+	}
+	if header.RCode != dnsmessage.RCodeSuccess {
+		return nil, false, fmt.Errorf("DNS server returned %s", header.RCode)
+	}
+	if err := parser.SkipAllQuestions(); err != nil {
+		return nil, false, fmt.Errorf("invalid DNS response: %w", err)
+	}
+
+	var ips []string
+	for {
+		h, err := parser.AnswerHeader()
+		if errors.Is(err, dnsmessage.ErrSectionDone) {
+			break
+		}
+		if err != nil {
+			return nil, false, fmt.Errorf("invalid DNS response: %w", err)
+		}
+
+		switch {
+		case h.Type == dnsmessage.TypeA && qtype == dnsmessage.TypeA:
+			a, err := parser.AResource()
+			if err != nil {
+				return nil, false, err
+			}
+			ips = append(ips, net.IP(a.A[:]).String())
+		case h.Type == dnsmessage.TypeAAAA && qtype == dnsmessage.TypeAAAA:
+			aaaa, err := parser.AAAAResource()
+			if err != nil {
+				return nil, false, err
+			}
+			ips = append(ips, net.IP(aaaa.AAAA[:]).String())
+		default:
+			// CNAME等记录由递归服务器展开，这里只取地址
+			if err := parser.SkipAnswer(); err != nil {
+				return nil, false, err
+			}
+		}
+	}
+	return ips, false, nil
+}
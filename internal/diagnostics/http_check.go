@@ -0,0 +1,101 @@
+package diagnostics
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// HTTPCheckOptions HTTP检查选项
+type HTTPCheckOptions struct {
+	Scheme   string        // http 或 https，默认https
+	Port     int           // 端口，默认按scheme选择
+	Path     string        // 请求路径，默认 "/"
+	Timeout  time.Duration // 请求超时
+	Insecure bool          // 是否跳过证书验证（类似 curl -k）
+}
+
+// HTTPCheckResult HTTP检查结果
+type HTTPCheckResult struct {
+	URL            string        `json:"url"`
+	StatusCode     int           `json:"status_code"`
+	Status         string        `json:"status"`
+	RedirectTarget string        `json:"redirect_target,omitempty"`
+	Server         string        `json:"server,omitempty"`
+	Latency        time.Duration `json:"latency"`
+}
+
+// CheckHTTP 直接连接IP，以hostname作为Host头（HTTPS时同时作为SNI）发送GET请求，不跟随重定向
+func CheckHTTP(ctx context.Context, ip, hostname string, options HTTPCheckOptions) (*HTTPCheckResult, error) {
+	if net.ParseIP(ip) == nil {
+		return nil, fmt.Errorf("invalid IP address: %q", ip)
+	}
+
+	if options.Scheme == "" {
+		options.Scheme = "https"
+	}
+	if options.Scheme != "http" && options.Scheme != "https" {
+		return nil, fmt.Errorf("unsupported scheme: %q", options.Scheme)
+	}
+	if options.Port <= 0 {
+		options.Port = 443
+		if options.Scheme == "http" {
+			options.Port = 80
+		}
+	}
+	if options.Path == "" {
+		options.Path = "/"
+	}
+	if options.Timeout <= 0 {
+		options.Timeout = 10 * time.Second
+	}
+
+	target := url.URL{
+		Scheme: options.Scheme,
+		Host:   net.JoinHostPort(hostname, strconv.Itoa(options.Port)),
+		Path:   options.Path,
+	}
+	address := net.JoinHostPort(ip, strconv.Itoa(options.Port))
+
+	dialer := &net.Dialer{Timeout: options.Timeout}
+	client := &http.Client{
+		Timeout: options.Timeout,
+		Transport: &http.Transport{
+			// 忽略URL中的主机名，始终连接到条目的IP
+			DialContext: func(ctx context.Context, network, _ string) (net.Conn, error) {
+				return dialer.DialContext(ctx, network, address)
+			},
+			TLSClientConfig:   &tls.Config{ServerName: hostname, InsecureSkipVerify: options.Insecure},
+			DisableKeepAlives: true,
+		},
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	start := time.Now()
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	return &HTTPCheckResult{
+		URL:            target.String(),
+		StatusCode:     resp.StatusCode,
+		Status:         resp.Status,
+		RedirectTarget: resp.Header.Get("Location"),
+		Server:         resp.Header.Get("Server"),
+		Latency:        time.Since(start),
+	}, nil
+}
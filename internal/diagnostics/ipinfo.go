@@ -0,0 +1,273 @@
+// Package diagnostics 提供hosts条目目标IP的诊断工具：IP信息查询、端口探测和证书检查。
+package diagnostics
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/flyhigher139/mhost/internal/httpclient"
+)
+
+// IPInfo IP地址信息
+type IPInfo struct {
+	IP         string            `json:"ip"`
+	ReverseDNS []string          `json:"reverse_dns"`
+	WhoisOwner string            `json:"whois_owner"`
+	Country    string            `json:"country"`
+	Region     string            `json:"region"`
+	City       string            `json:"city"`
+	Org        string            `json:"org"`
+	Private    bool              `json:"private"`
+	FetchedAt  time.Time         `json:"fetched_at"`
+	Errors     map[string]string `json:"errors,omitempty"`
+	Stale      bool              `json:"-"` // 查询失败时返回的过期缓存
+}
+
+// GeoInfo 地理位置信息
+type GeoInfo struct {
+	Country string
+	Region  string
+	City    string
+	Org     string
+}
+
+// IPInfoService IP信息查询服务，结果缓存到内存和磁盘，离线时返回已缓存的结果
+type IPInfoService struct {
+	cacheFile string
+	ttl       time.Duration
+
+	lookupAddr func(ctx context.Context, ip string) ([]string, error)
+	whois      func(ctx context.Context, ip string) (string, error)
+	geo        func(ctx context.Context, ip string) (*GeoInfo, error)
+
+	mu    sync.Mutex
+	cache map[string]*IPInfo
+}
+
+// NewIPInfoService 创建IP信息查询服务，cacheDir为空时仅使用内存缓存
+func NewIPInfoService(cacheDir string) *IPInfoService {
+	s := &IPInfoService{
+		ttl:        7 * 24 * time.Hour,
+		lookupAddr: net.DefaultResolver.LookupAddr,
+		whois:      lookupWhoisOwner,
+		geo:        lookupGeo,
+		cache:      make(map[string]*IPInfo),
+	}
+	if cacheDir != "" {
+		s.cacheFile = filepath.Join(cacheDir, "ipinfo_cache.json")
+		s.loadCache()
+	}
+	return s
+}
+
+// Lookup 查询IP信息；缓存未过期时直接返回，全部查询失败时回退到过期缓存
+func (s *IPInfoService) Lookup(ctx context.Context, ip string) (*IPInfo, error) {
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return nil, fmt.Errorf("invalid IP address: %q", ip)
+	}
+
+	s.mu.Lock()
+	cached, hasCached := s.cache[ip]
+	s.mu.Unlock()
+	if hasCached && time.Since(cached.FetchedAt) < s.ttl {
+		result := *cached
+		return &result, nil
+	}
+
+	info := &IPInfo{
+		IP:        ip,
+		Private:   parsed.IsPrivate() || parsed.IsLoopback() || parsed.IsLinkLocalUnicast(),
+		FetchedAt: time.Now(),
+		Errors:    make(map[string]string),
+	}
+
+	if names, err := s.lookupAddr(ctx, ip); err != nil {
+		info.Errors["reverse_dns"] = err.Error()
+	} else {
+		for _, name := range names {
+			info.ReverseDNS = append(info.ReverseDNS, strings.TrimSuffix(name, "."))
+		}
+	}
+
+	// 私有地址没有公开的whois和地理信息
+	if !info.Private {
+		if owner, err := s.whois(ctx, ip); err != nil {
+			info.Errors["whois"] = err.Error()
+		} else {
+			info.WhoisOwner = owner
+		}
+
+		if geo, err := s.geo(ctx, ip); err != nil {
+			info.Errors["geo"] = err.Error()
+		} else {
+			info.Country, info.Region, info.City, info.Org = geo.Country, geo.Region, geo.City, geo.Org
+		}
+	}
+
+	// 离线时所有公网查询都失败，使用已有缓存
+	if hasCached && !info.Private && len(info.Errors) == 3 {
+		result := *cached
+		result.Stale = true
+		return &result, nil
+	}
+
+	s.mu.Lock()
+	s.cache[ip] = info
+	s.mu.Unlock()
+	s.saveCache()
+
+	result := *info
+	return &result, nil
+}
+
+// loadCache 从磁盘加载缓存
+func (s *IPInfoService) loadCache() {
+	data, err := os.ReadFile(s.cacheFile)
+	if err != nil {
+		return
+	}
+
+	var cache map[string]*IPInfo
+	if err := json.Unmarshal(data, &cache); err == nil {
+		s.cache = cache
+	}
+}
+
+// saveCache 将缓存写入磁盘
+func (s *IPInfoService) saveCache() {
+	if s.cacheFile == "" {
+		return
+	}
+
+	s.mu.Lock()
+	data, err := json.MarshalIndent(s.cache, "", "  ")
+	s.mu.Unlock()
+	if err != nil {
+		return
+	}
+
+	if err := os.MkdirAll(filepath.Dir(s.cacheFile), 0755); err != nil {
+		return
+	}
+	os.WriteFile(s.cacheFile, data, 0644)
+}
+
+// whoisQuery 向whois服务器发送查询
+func whoisQuery(ctx context.Context, server, query string) (string, error) {
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(server, "43"))
+	if err != nil {
+		return "", fmt.Errorf("failed to connect whois server: %w", err)
+	}
+	defer conn.Close()
+
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	if _, err := fmt.Fprintf(conn, "%s\r\n", query); err != nil {
+		return "", fmt.Errorf("failed to send whois query: %w", err)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(conn, 1<<20))
+	if err != nil {
+		return "", fmt.Errorf("failed to read whois response: %w", err)
+	}
+	return string(data), nil
+}
+
+// lookupWhoisOwner 通过IANA查询负责的RIR，再查询IP的所有者
+func lookupWhoisOwner(ctx context.Context, ip string) (string, error) {
+	if httpclient.Default().Offline() {
+		return "", httpclient.ErrOffline
+	}
+
+	response, err := whoisQuery(ctx, "whois.iana.org", ip)
+	if err != nil {
+		return "", err
+	}
+
+	if refer := parseWhoisField(response, "refer"); refer != "" {
+		if response, err = whoisQuery(ctx, refer, ip); err != nil {
+			return "", err
+		}
+	}
+
+	owner := parseWhoisOwner(response)
+	if owner == "" {
+		return "", fmt.Errorf("owner not found in whois response")
+	}
+	return owner, nil
+}
+
+// parseWhoisOwner 从whois响应中提取所有者
+func parseWhoisOwner(response string) string {
+	for _, key := range []string{"OrgName", "org-name", "owner", "descr", "NetName", "netname"} {
+		if value := parseWhoisField(response, key); value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// parseWhoisField 提取whois响应中第一个指定字段的值
+func parseWhoisField(response, key string) string {
+	scanner := bufio.NewScanner(strings.NewReader(response))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		name, value, found := strings.Cut(line, ":")
+		if found && strings.EqualFold(strings.TrimSpace(name), key) {
+			if value = strings.TrimSpace(value); value != "" {
+				return value
+			}
+		}
+	}
+	return ""
+}
+
+// lookupGeo 查询IP的地理位置，使用应用的网络代理设置
+func lookupGeo(ctx context.Context, ip string) (*GeoInfo, error) {
+	url := fmt.Sprintf("http://ip-api.com/json/%s?fields=status,message,country,regionName,city,org", ip)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := httpclient.Default().Client(0)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query geo info: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		Status     string `json:"status"`
+		Message    string `json:"message"`
+		Country    string `json:"country"`
+		RegionName string `json:"regionName"`
+		City       string `json:"city"`
+		Org        string `json:"org"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("invalid geo response: %w", err)
+	}
+	if result.Status != "success" {
+		return nil, fmt.Errorf("geo lookup failed: %s", result.Message)
+	}
+
+	return &GeoInfo{Country: result.Country, Region: result.RegionName, City: result.City, Org: result.Org}, nil
+}
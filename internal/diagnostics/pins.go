@@ -0,0 +1,120 @@
+package diagnostics
+
+import (
+	"context"
+	"sync"
+
+	"github.com/flyhigher139/mhost/pkg/hostsfile"
+	"github.com/flyhigher139/mhost/pkg/models"
+)
+
+// pinConcurrency 批量解析时的最大并发数
+const pinConcurrency = 8
+
+// IPResolver 查询主机名的真实IP，DNSResolver实现了该接口
+type IPResolver interface {
+	LookupIP(ctx context.Context, hostname string) ([]string, error)
+}
+
+// PinResult 单个主机名的解析结果
+type PinResult struct {
+	Hostname string   `json:"hostname"`
+	IPs      []string `json:"ips"`
+	Error    string   `json:"error,omitempty"`
+}
+
+// IP 固定时使用的地址，即第一个解析结果
+func (r PinResult) IP() string {
+	if len(r.IPs) == 0 {
+		return ""
+	}
+	return r.IPs[0]
+}
+
+// ResolveAll 并发解析主机名列表，结果顺序与输入一致，重复和空主机名会被忽略
+func ResolveAll(ctx context.Context, resolver IPResolver, hostnames []string) []PinResult {
+	seen := make(map[string]bool)
+	var unique []string
+	for _, hostname := range hostnames {
+		hostname = hostsfile.NormalizeHostname(hostname)
+		if hostname != "" && !seen[hostname] {
+			seen[hostname] = true
+			unique = append(unique, hostname)
+		}
+	}
+
+	results := make([]PinResult, len(unique))
+	sem := make(chan struct{}, pinConcurrency)
+	var wg sync.WaitGroup
+	for i, hostname := range unique {
+		wg.Add(1)
+		go func(i int, hostname string) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			result := PinResult{Hostname: hostname}
+			ips, err := resolver.LookupIP(ctx, hostname)
+			if err != nil {
+				result.Error = err.Error()
+			} else {
+				result.IPs = ips
+			}
+			results[i] = result
+		}(i, hostname)
+	}
+	wg.Wait()
+	return results
+}
+
+// PinChange 重新解析固定条目的结果
+type PinChange struct {
+	Entry   *models.HostEntry `json:"-"`
+	OldIP   string            `json:"old_ip"`
+	Result  PinResult         `json:"result"`
+	Changed bool              `json:"changed"` // 当前IP已不在解析结果中
+}
+
+// RefreshPins 重新解析Profile中注释带pinned标记的条目，按Profile当前环境比较生效IP
+func RefreshPins(ctx context.Context, resolver IPResolver, profile *models.Profile) []PinChange {
+	var pinned []*models.HostEntry
+	var hostnames []string
+	for _, entry := range profile.Entries {
+		if entry == nil {
+			continue
+		}
+		if _, ok := hostsfile.ParseComment(entry.Comment).Pinned(); ok {
+			pinned = append(pinned, entry)
+			hostnames = append(hostnames, entry.Hostname)
+		}
+	}
+
+	byHostname := make(map[string]PinResult)
+	for _, result := range ResolveAll(ctx, resolver, hostnames) {
+		byHostname[result.Hostname] = result
+	}
+
+	changes := make([]PinChange, 0, len(pinned))
+	for _, entry := range pinned {
+		change := PinChange{
+			Entry:  entry,
+			OldIP:  entry.IPFor(profile.Environment),
+			Result: byHostname[hostsfile.NormalizeHostname(entry.Hostname)],
+		}
+		if change.Result.Error == "" {
+			change.Changed = !containsIP(change.Result.IPs, change.OldIP)
+		}
+		changes = append(changes, change)
+	}
+	return changes
+}
+
+// containsIP 解析结果中是否包含指定IP
+func containsIP(ips []string, ip string) bool {
+	for _, candidate := range ips {
+		if candidate == ip {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,81 @@
+package diagnostics
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultProbePorts 默认探测的端口
+var DefaultProbePorts = []int{80, 443, 22}
+
+// PortResult 端口探测结果
+type PortResult struct {
+	Port    int           `json:"port"`
+	Open    bool          `json:"open"`
+	Latency time.Duration `json:"latency"`
+	Error   string        `json:"error,omitempty"`
+}
+
+// ProbePorts 并发探测IP的TCP端口，结果按端口号排序
+func ProbePorts(ctx context.Context, ip string, ports []int, timeout time.Duration) ([]PortResult, error) {
+	if net.ParseIP(ip) == nil {
+		return nil, fmt.Errorf("invalid IP address: %q", ip)
+	}
+	if len(ports) == 0 {
+		ports = DefaultProbePorts
+	}
+
+	results := make([]PortResult, len(ports))
+	var wg sync.WaitGroup
+	for i, port := range ports {
+		wg.Add(1)
+		go func(i, port int) {
+			defer wg.Done()
+			results[i] = probePort(ctx, ip, port, timeout)
+		}(i, port)
+	}
+	wg.Wait()
+
+	sort.Slice(results, func(i, j int) bool { return results[i].Port < results[j].Port })
+	return results, nil
+}
+
+// probePort 探测单个端口
+func probePort(ctx context.Context, ip string, port int, timeout time.Duration) PortResult {
+	dialer := net.Dialer{Timeout: timeout}
+	start := time.Now()
+	conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(ip, strconv.Itoa(port)))
+	latency := time.Since(start)
+	if err != nil {
+		return PortResult{Port: port, Latency: latency, Error: err.Error()}
+	}
+	conn.Close()
+	return PortResult{Port: port, Open: true, Latency: latency}
+}
+
+// ParsePorts 解析逗号分隔的端口列表，忽略重复端口
+func ParsePorts(input string) ([]int, error) {
+	var ports []int
+	seen := make(map[int]bool)
+	for _, field := range strings.Split(input, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		port, err := strconv.Atoi(field)
+		if err != nil || port < 1 || port > 65535 {
+			return nil, fmt.Errorf("invalid port: %q", field)
+		}
+		if !seen[port] {
+			seen[port] = true
+			ports = append(ports, port)
+		}
+	}
+	return ports, nil
+}
@@ -0,0 +1,94 @@
+package dnsfilter
+
+import (
+	"context"
+	"net/http"
+	"strings"
+)
+
+// AdGuard AdGuard Home控制API的客户端，通过HTTP Basic认证。
+// 拦截域名保存为自定义过滤规则中"||example.com^"形式的规则
+type AdGuard struct {
+	baseURL  string
+	username string
+	password string
+	client   *http.Client
+}
+
+// Blocked 获取自定义过滤规则中按域名拦截的域名，忽略例外规则、注释和其他语法的规则
+func (a *AdGuard) Blocked(ctx context.Context) ([]string, error) {
+	rules, err := a.userRules(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var domains []string
+	for _, rule := range rules {
+		if domain, ok := parseBlockRule(rule); ok {
+			domains = append(domains, domain)
+		}
+	}
+	return domains, nil
+}
+
+// Block 将域名的拦截规则追加到自定义过滤规则。AdGuard Home只能整体替换规则，先读取已有规则再写回
+func (a *AdGuard) Block(ctx context.Context, domains []string) error {
+	rules, err := a.userRules(ctx)
+	if err != nil {
+		return err
+	}
+	for _, domain := range domains {
+		rules = append(rules, blockRule(domain))
+	}
+
+	req, err := a.newRequest(ctx, http.MethodPost, "/control/filtering/set_rules", map[string][]string{"rules": rules})
+	if err != nil {
+		return err
+	}
+	return doJSON(a.client, req, nil)
+}
+
+// userRules 读取自定义过滤规则
+func (a *AdGuard) userRules(ctx context.Context) ([]string, error) {
+	req, err := a.newRequest(ctx, http.MethodGet, "/control/filtering/status", nil)
+	if err != nil {
+		return nil, err
+	}
+	var status struct {
+		UserRules []string `json:"user_rules"`
+	}
+	if err := doJSON(a.client, req, &status); err != nil {
+		return nil, err
+	}
+	return status.UserRules, nil
+}
+
+// newRequest 创建带Basic认证的API请求
+func (a *AdGuard) newRequest(ctx context.Context, method, path string, body interface{}) (*http.Request, error) {
+	req, err := newJSONRequest(ctx, method, a.baseURL+path, body)
+	if err != nil {
+		return nil, err
+	}
+	if a.username != "" {
+		req.SetBasicAuth(a.username, a.password)
+	}
+	return req, nil
+}
+
+// blockRule 拦截域名及其子域名的过滤规则
+func blockRule(domain string) string {
+	return "||" + domain + "^"
+}
+
+// parseBlockRule 解析"||example.com^"形式的规则，其他规则返回false
+func parseBlockRule(rule string) (string, bool) {
+	rule = strings.TrimSpace(rule)
+	domain, ok := strings.CutPrefix(rule, "||")
+	if !ok {
+		return "", false
+	}
+	domain, ok = strings.CutSuffix(domain, "^")
+	if !ok || domain == "" || strings.ContainsAny(domain, "*|^$/ ") {
+		return "", false
+	}
+	return domain, true
+}
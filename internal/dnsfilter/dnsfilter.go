@@ -0,0 +1,201 @@
+// Package dnsfilter 与Pi-hole、AdGuard Home等网络级DNS过滤服务同步拦截条目：
+// 将Profile中的拦截条目（启用且指向0.0.0.0或::的条目）推送为服务的拦截域名，
+// 或将服务中已有的拦截域名拉取为Profile。推送只添加服务中缺少的域名，不会删除服务中已有的规则。
+package dnsfilter
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/netip"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/flyhigher139/mhost/internal/importer"
+	"github.com/flyhigher139/mhost/pkg/hostsfile"
+	"github.com/flyhigher139/mhost/pkg/models"
+)
+
+// DefaultTimeout 访问DNS过滤服务的默认时限
+const DefaultTimeout = 15 * time.Second
+
+// MaxResponseSize 服务响应的大小上限，拦截列表可能包含数十万条规则
+const MaxResponseSize = 32 << 20
+
+// BlockIP 拉取的拦截域名在Profile中使用的IP
+const BlockIP = "0.0.0.0"
+
+var (
+	// ErrNotConfigured 没有设置DNS过滤服务
+	ErrNotConfigured = errors.New("dns filter service is not configured")
+	// ErrUnsupportedType 不支持的服务类型
+	ErrUnsupportedType = errors.New("unsupported dns filter service type")
+	// ErrUnauthorized 服务拒绝了认证信息
+	ErrUnauthorized = errors.New("dns filter service rejected the credentials")
+)
+
+// Client DNS过滤服务的客户端
+type Client interface {
+	// Blocked 获取服务中按域名精确拦截的域名
+	Blocked(ctx context.Context) ([]string, error)
+	// Block 将域名加入服务的拦截列表
+	Block(ctx context.Context, domains []string) error
+}
+
+// New 按配置创建客户端，httpClient为nil时使用默认时限的http.Client
+func New(config models.DNSFilterConfig, httpClient *http.Client) (Client, error) {
+	if config.Type == "" || config.URL == "" {
+		return nil, ErrNotConfigured
+	}
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: DefaultTimeout}
+	}
+	base := strings.TrimRight(config.URL, "/")
+
+	switch config.Type {
+	case models.DNSFilterPiHole:
+		return &PiHole{baseURL: base, password: config.Token, client: httpClient}, nil
+	case models.DNSFilterAdGuard:
+		username, password, _ := strings.Cut(config.Token, ":")
+		return &AdGuard{baseURL: base, username: username, password: password, client: httpClient}, nil
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedType, config.Type)
+	}
+}
+
+// IsBlockEntry 条目是否为拦截条目：启用且IP为0.0.0.0或::
+func IsBlockEntry(entry *models.HostEntry) bool {
+	if !entry.Enabled {
+		return false
+	}
+	addr, err := netip.ParseAddr(entry.IP)
+	return err == nil && addr.IsUnspecified()
+}
+
+// BlockedHostnames Profile中拦截条目的主机名，转为小写、去重并排序
+func BlockedHostnames(p *models.Profile) []string {
+	var hostnames []string
+	for _, entry := range p.Entries {
+		if IsBlockEntry(entry) {
+			hostnames = append(hostnames, strings.ToLower(entry.Hostname))
+		}
+	}
+	slices.Sort(hostnames)
+	return slices.Compact(hostnames)
+}
+
+// PushResult 一次推送的结果
+type PushResult struct {
+	Added    []string // 新加入服务拦截列表的域名
+	Existing int      // 服务中已经拦截的域名数
+}
+
+// Push 将Profile中的拦截条目推送到服务，只添加服务中缺少的域名
+func Push(ctx context.Context, client Client, p *models.Profile) (*PushResult, error) {
+	blocked, err := client.Blocked(ctx)
+	if err != nil {
+		return nil, err
+	}
+	existing := make(map[string]bool, len(blocked))
+	for _, domain := range blocked {
+		existing[strings.ToLower(domain)] = true
+	}
+
+	result := &PushResult{}
+	for _, hostname := range BlockedHostnames(p) {
+		if existing[hostname] {
+			result.Existing++
+		} else {
+			result.Added = append(result.Added, hostname)
+		}
+	}
+	if len(result.Added) == 0 {
+		return result, nil
+	}
+	if err := client.Block(ctx, result.Added); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// Pull 将服务中的拦截域名拉取为以name命名的Profile，条目指向BlockIP。
+// 不是有效主机名的规则（例如通配符）会被跳过，返回跳过的数量
+func Pull(ctx context.Context, client Client, name string, normalizer hostsfile.Normalizer) (*models.Profile, int, error) {
+	blocked, err := client.Blocked(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var entries []hostsfile.Entry
+	skipped := 0
+	for _, domain := range blocked {
+		hostname, err := normalizer.Normalize(domain)
+		if err != nil {
+			skipped++
+			continue
+		}
+		entries = append(entries, hostsfile.Entry{IP: BlockIP, Hostname: hostname, Enabled: true})
+	}
+	profile, err := importer.ProfileFromEntries(entries, name, normalizer)
+	if err != nil {
+		return nil, skipped, err
+	}
+	return profile, skipped, nil
+}
+
+// newJSONRequest 创建请求，body不为nil时以JSON发送
+func newJSONRequest(ctx context.Context, method, url string, body interface{}) (*http.Request, error) {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, url, reader)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	return req, nil
+}
+
+// doJSON 发送请求并将响应解析到result，result为nil时忽略响应内容
+func doJSON(client *http.Client, req *http.Request, result interface{}) error {
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to reach dns filter service: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, MaxResponseSize+1))
+	if err != nil {
+		return fmt.Errorf("failed to read dns filter response: %w", err)
+	}
+	if len(body) > MaxResponseSize {
+		return importer.ErrResponseTooLarge
+	}
+	switch {
+	case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden:
+		return ErrUnauthorized
+	case resp.StatusCode < 200 || resp.StatusCode >= 300:
+		return fmt.Errorf("dns filter service returned %s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+	if result == nil {
+		return nil
+	}
+	if err := json.Unmarshal(body, result); err != nil {
+		return fmt.Errorf("invalid dns filter response: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,193 @@
+package dnsfilter
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/flyhigher139/mhost/pkg/hostsfile"
+	"github.com/flyhigher139/mhost/pkg/models"
+)
+
+// fakePiHole 模拟Pi-hole v6 API：需要应用密码认证，保存精确拦截列表
+type fakePiHole struct {
+	mu       sync.Mutex
+	password string
+	domains  []string
+	comments []string
+	logouts  int
+}
+
+func (f *fakePiHole) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if r.URL.Path == "/api/auth" {
+		switch r.Method {
+		case http.MethodPost:
+			var body struct {
+				Password string `json:"password"`
+			}
+			_ = json.NewDecoder(r.Body).Decode(&body)
+			if body.Password != f.password {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			_, _ = w.Write([]byte(`{"session": {"valid": true, "sid": "sid-1"}}`))
+		case http.MethodDelete:
+			f.logouts++
+			w.WriteHeader(http.StatusNoContent)
+		}
+		return
+	}
+	if r.Header.Get("X-FTL-SID") != "sid-1" {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		type domain struct {
+			Domain  string `json:"domain"`
+			Enabled bool   `json:"enabled"`
+		}
+		result := struct {
+			Domains []domain `json:"domains"`
+		}{Domains: []domain{{Domain: "disabled.test"}}}
+		for _, d := range f.domains {
+			result.Domains = append(result.Domains, domain{Domain: d, Enabled: true})
+		}
+		_ = json.NewEncoder(w).Encode(result)
+	case http.MethodPost:
+		var body struct {
+			Domain  []string `json:"domain"`
+			Comment string   `json:"comment"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		f.domains = append(f.domains, body.Domain...)
+		f.comments = append(f.comments, body.Comment)
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{}`))
+	}
+}
+
+// fakeAdGuard 模拟AdGuard Home控制API：需要Basic认证，保存自定义过滤规则
+type fakeAdGuard struct {
+	mu    sync.Mutex
+	rules []string
+}
+
+func (f *fakeAdGuard) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if user, password, ok := r.BasicAuth(); !ok || user != "admin" || password != "secret" {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	switch r.URL.Path {
+	case "/control/filtering/status":
+		_ = json.NewEncoder(w).Encode(map[string][]string{"user_rules": f.rules})
+	case "/control/filtering/set_rules":
+		var body struct {
+			Rules []string `json:"rules"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		f.rules = body.Rules
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+// blockProfile 包含拦截条目和普通条目的Profile
+func blockProfile() *models.Profile {
+	p := models.NewProfile("Ads", "")
+	p.AddEntry(models.NewHostEntry("0.0.0.0", "ads.test", ""))
+	p.AddEntry(models.NewHostEntry("::", "ADS.test", ""))
+	p.AddEntry(models.NewHostEntry("0.0.0.0", "tracker.test", ""))
+	p.AddEntry(models.NewHostEntry("10.0.0.1", "api.test", ""))
+	disabled := models.NewHostEntry("0.0.0.0", "off.test", "")
+	disabled.Enabled = false
+	p.AddEntry(disabled)
+	return p
+}
+
+// TestBlockedHostnames 测试只有启用且指向0.0.0.0或::的条目是拦截条目
+func TestBlockedHostnames(t *testing.T) {
+	assert.Equal(t, []string{"ads.test", "tracker.test"}, BlockedHostnames(blockProfile()))
+}
+
+// TestNew 测试未设置服务和不支持的服务类型
+func TestNew(t *testing.T) {
+	_, err := New(models.DNSFilterConfig{}, nil)
+	assert.ErrorIs(t, err, ErrNotConfigured)
+	_, err = New(models.DNSFilterConfig{Type: "bind", URL: "http://dns.test"}, nil)
+	assert.ErrorIs(t, err, ErrUnsupportedType)
+}
+
+// TestPiHole 测试推送时只添加缺少的域名、拉取时跳过禁用的域名，以及认证失败
+func TestPiHole(t *testing.T) {
+	server := &fakePiHole{password: "app-password", domains: []string{"ads.test"}}
+	ts := httptest.NewServer(server)
+	defer ts.Close()
+
+	client, err := New(models.DNSFilterConfig{Type: models.DNSFilterPiHole, URL: ts.URL + "/", Token: "app-password"}, ts.Client())
+	require.NoError(t, err)
+	result, err := Push(context.Background(), client, blockProfile())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"tracker.test"}, result.Added)
+	assert.Equal(t, 1, result.Existing)
+	assert.Equal(t, []string{"ads.test", "tracker.test"}, server.domains)
+	assert.Equal(t, []string{piholeComment}, server.comments)
+	assert.Equal(t, 2, server.logouts, "each call logs out its session")
+
+	result, err = Push(context.Background(), client, blockProfile())
+	require.NoError(t, err)
+	assert.Empty(t, result.Added)
+
+	profile, skipped, err := Pull(context.Background(), client, "Pi-hole", hostsfile.DefaultNormalizer)
+	require.NoError(t, err)
+	assert.Zero(t, skipped)
+	assert.Equal(t, "Pi-hole", profile.Name)
+	require.Len(t, profile.Entries, 2)
+	assert.Equal(t, BlockIP, profile.Entries[1].IP)
+	assert.Equal(t, "tracker.test", profile.Entries[1].Hostname)
+
+	client, err = New(models.DNSFilterConfig{Type: models.DNSFilterPiHole, URL: ts.URL, Token: "wrong"}, ts.Client())
+	require.NoError(t, err)
+	_, err = client.Blocked(context.Background())
+	assert.ErrorIs(t, err, ErrUnauthorized)
+}
+
+// TestAdGuard 测试推送时保留已有规则、拉取时只解析域名拦截规则
+func TestAdGuard(t *testing.T) {
+	server := &fakeAdGuard{rules: []string{"! comment", "@@||allowed.test^", "||ads.test^", "||*.wild.test^", "/regex/"}}
+	ts := httptest.NewServer(server)
+	defer ts.Close()
+
+	client, err := New(models.DNSFilterConfig{Type: models.DNSFilterAdGuard, URL: ts.URL, Token: "admin:secret"}, ts.Client())
+	require.NoError(t, err)
+	blocked, err := client.Blocked(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"ads.test"}, blocked)
+
+	result, err := Push(context.Background(), client, blockProfile())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"tracker.test"}, result.Added)
+	assert.Equal(t, []string{"! comment", "@@||allowed.test^", "||ads.test^", "||*.wild.test^", "/regex/", "||tracker.test^"}, server.rules)
+
+	profile, _, err := Pull(context.Background(), client, "AdGuard", hostsfile.DefaultNormalizer)
+	require.NoError(t, err)
+	assert.Len(t, profile.Entries, 2)
+
+	client, err = New(models.DNSFilterConfig{Type: models.DNSFilterAdGuard, URL: ts.URL, Token: "admin"}, ts.Client())
+	require.NoError(t, err)
+	_, err = client.Blocked(context.Background())
+	assert.ErrorIs(t, err, ErrUnauthorized)
+}
@@ -0,0 +1,99 @@
+package dnsfilter
+
+import (
+	"context"
+	"net/http"
+)
+
+// piholeComment 推送到Pi-hole的域名的注释，便于在Pi-hole中识别来源
+const piholeComment = "added by mHost"
+
+// PiHole Pi-hole v6 REST API的客户端。设置了应用密码时先通过/api/auth获取会话，请求完成后注销会话
+type PiHole struct {
+	baseURL  string
+	password string
+	client   *http.Client
+}
+
+// Blocked 获取Pi-hole精确拦截列表中的域名
+func (p *PiHole) Blocked(ctx context.Context) ([]string, error) {
+	var domains []string
+	err := p.withSession(ctx, func(sid string) error {
+		req, err := p.newRequest(ctx, http.MethodGet, "/api/domains/deny/exact", sid, nil)
+		if err != nil {
+			return err
+		}
+		var result struct {
+			Domains []struct {
+				Domain  string `json:"domain"`
+				Enabled bool   `json:"enabled"`
+			} `json:"domains"`
+		}
+		if err := doJSON(p.client, req, &result); err != nil {
+			return err
+		}
+		for _, domain := range result.Domains {
+			if domain.Enabled {
+				domains = append(domains, domain.Domain)
+			}
+		}
+		return nil
+	})
+	return domains, err
+}
+
+// Block 将域名加入Pi-hole的精确拦截列表
+func (p *PiHole) Block(ctx context.Context, domains []string) error {
+	return p.withSession(ctx, func(sid string) error {
+		body := map[string]interface{}{"domain": domains, "comment": piholeComment, "enabled": true}
+		req, err := p.newRequest(ctx, http.MethodPost, "/api/domains/deny/exact", sid, body)
+		if err != nil {
+			return err
+		}
+		return doJSON(p.client, req, nil)
+	})
+}
+
+// withSession 在会话中执行fn；没有设置应用密码时不认证，sid为空
+func (p *PiHole) withSession(ctx context.Context, fn func(sid string) error) error {
+	if p.password == "" {
+		return fn("")
+	}
+
+	req, err := p.newRequest(ctx, http.MethodPost, "/api/auth", "", map[string]string{"password": p.password})
+	if err != nil {
+		return err
+	}
+	var auth struct {
+		Session struct {
+			Valid bool   `json:"valid"`
+			SID   string `json:"sid"`
+		} `json:"session"`
+	}
+	if err := doJSON(p.client, req, &auth); err != nil {
+		return err
+	}
+	if !auth.Session.Valid {
+		return ErrUnauthorized
+	}
+
+	// Pi-hole限制同时存在的会话数，用完后注销
+	defer func() {
+		if logout, err := p.newRequest(context.WithoutCancel(ctx), http.MethodDelete, "/api/auth", auth.Session.SID, nil); err == nil {
+			_ = doJSON(p.client, logout, nil)
+		}
+	}()
+	return fn(auth.Session.SID)
+}
+
+// newRequest 创建API请求，sid不为空时附带会话
+func (p *PiHole) newRequest(ctx context.Context, method, path, sid string, body interface{}) (*http.Request, error) {
+	req, err := newJSONRequest(ctx, method, p.baseURL+path, body)
+	if err != nil {
+		return nil, err
+	}
+	if sid != "" {
+		req.Header.Set("X-FTL-SID", sid)
+	}
+	return req, nil
+}
@@ -0,0 +1,126 @@
+// Package dnsstats 统计被覆盖主机名的实际解析次数，帮助清理不再使用的hosts条目。
+package dnsstats
+
+import (
+	"sync"
+	"time"
+
+	"github.com/flyhigher139/mhost/pkg/hostsfile"
+)
+
+// Hit 主机名命中统计
+type Hit struct {
+	Hostname string    `json:"hostname"`
+	Count    int64     `json:"count"`
+	LastSeen time.Time `json:"last_seen"`
+}
+
+// Counter 命中计数器接口
+type Counter interface {
+	// Record 记录一次解析
+	Record(hostname string, at time.Time)
+
+	// Get 获取主机名的命中统计
+	Get(hostname string) (Hit, bool)
+
+	// Snapshot 获取所有命中统计的快照
+	Snapshot() map[string]Hit
+
+	// SetTracked 设置需要统计的主机名，为空时统计全部
+	SetTracked(hostnames []string)
+
+	// Reset 清空统计
+	Reset()
+}
+
+// CounterImpl 命中计数器实现
+type CounterImpl struct {
+	mu      sync.RWMutex
+	hits    map[string]*Hit
+	tracked map[string]bool
+}
+
+// NewCounter 创建命中计数器
+func NewCounter() *CounterImpl {
+	return &CounterImpl{
+		hits: make(map[string]*Hit),
+	}
+}
+
+// normalizeHostname 规范化主机名，与Profile条目使用相同的规则
+func normalizeHostname(hostname string) string {
+	return hostsfile.NormalizeHostname(hostname)
+}
+
+// Record 记录一次解析
+func (c *CounterImpl) Record(hostname string, at time.Time) {
+	hostname = normalizeHostname(hostname)
+	if hostname == "" {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.tracked != nil && !c.tracked[hostname] {
+		return
+	}
+
+	hit, exists := c.hits[hostname]
+	if !exists {
+		hit = &Hit{Hostname: hostname}
+		c.hits[hostname] = hit
+	}
+	hit.Count++
+	if at.After(hit.LastSeen) {
+		hit.LastSeen = at
+	}
+}
+
+// Get 获取主机名的命中统计
+func (c *CounterImpl) Get(hostname string) (Hit, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	hit, exists := c.hits[normalizeHostname(hostname)]
+	if !exists {
+		return Hit{}, false
+	}
+	return *hit, true
+}
+
+// Snapshot 获取所有命中统计的快照
+func (c *CounterImpl) Snapshot() map[string]Hit {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	snapshot := make(map[string]Hit, len(c.hits))
+	for hostname, hit := range c.hits {
+		snapshot[hostname] = *hit
+	}
+	return snapshot
+}
+
+// SetTracked 设置需要统计的主机名，为空时统计全部
+func (c *CounterImpl) SetTracked(hostnames []string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if len(hostnames) == 0 {
+		c.tracked = nil
+		return
+	}
+
+	c.tracked = make(map[string]bool, len(hostnames))
+	for _, hostname := range hostnames {
+		c.tracked[normalizeHostname(hostname)] = true
+	}
+}
+
+// Reset 清空统计
+func (c *CounterImpl) Reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.hits = make(map[string]*Hit)
+}
@@ -0,0 +1,84 @@
+package dnsstats
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestParseQueryLine 测试解析DNS查询日志行
+func TestParseQueryLine(t *testing.T) {
+	testCases := []struct {
+		line     string
+		hostname string
+		ok       bool
+	}{
+		{"Jan  1 10:00:00 dnsmasq[123]: query[A] API.test from 127.0.0.1", "api.test", true},
+		{"[1700000000] unbound[1:0] info: 127.0.0.1 web.test. A IN", "web.test", true},
+		{"mDNSResponder: Query for db.test. (Addr)", "db.test", true},
+		{"dnsmasq[123]: reply api.test is 10.0.0.1", "", false},
+	}
+
+	for _, tc := range testCases {
+		hostname, ok := ParseQueryLine(tc.line)
+		assert.Equal(t, tc.ok, ok, tc.line)
+		assert.Equal(t, tc.hostname, hostname, tc.line)
+	}
+}
+
+// TestCounterTracked 测试只统计被跟踪的主机名
+func TestCounterTracked(t *testing.T) {
+	counter := NewCounter()
+	counter.SetTracked([]string{"api.test"})
+
+	now := time.Now()
+	counter.Record("API.test.", now)
+	counter.Record("api.test", now.Add(time.Second))
+	counter.Record("other.test", now)
+
+	hit, ok := counter.Get("api.test")
+	require.True(t, ok)
+	assert.Equal(t, int64(2), hit.Count)
+	assert.Equal(t, now.Add(time.Second), hit.LastSeen)
+
+	_, ok = counter.Get("other.test")
+	assert.False(t, ok)
+
+	counter.Reset()
+	assert.Empty(t, counter.Snapshot())
+}
+
+// TestQueryLogTailerPoll 测试读取新增的日志行
+func TestQueryLogTailerPoll(t *testing.T) {
+	logPath := filepath.Join(t.TempDir(), "query.log")
+	require.NoError(t, os.WriteFile(logPath, []byte("query[A] old.test from 127.0.0.1\n"), 0644))
+
+	counter := NewCounter()
+	tailer := NewQueryLogTailer(logPath, counter, time.Hour)
+	require.NoError(t, tailer.Start())
+	defer tailer.Stop()
+
+	file, err := os.OpenFile(logPath, os.O_APPEND|os.O_WRONLY, 0644)
+	require.NoError(t, err)
+	_, err = file.WriteString("query[A] api.test from 127.0.0.1\nquery[AAAA] api.test from 127.0.0.1\nquery[A] partial")
+	require.NoError(t, err)
+	require.NoError(t, file.Close())
+
+	require.NoError(t, tailer.Poll())
+
+	hit, ok := counter.Get("api.test")
+	require.True(t, ok)
+	assert.Equal(t, int64(2), hit.Count)
+	_, ok = counter.Get("old.test")
+	assert.False(t, ok, "existing lines before Start should be skipped")
+
+	// 日志截断后从头读取
+	require.NoError(t, os.WriteFile(logPath, []byte("query[A] new.test from 127.0.0.1\n"), 0644))
+	require.NoError(t, tailer.Poll())
+	_, ok = counter.Get("new.test")
+	assert.True(t, ok)
+}
@@ -0,0 +1,156 @@
+package dnsstats
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"sync"
+	"time"
+)
+
+// queryPatterns 常见DNS查询日志格式
+var queryPatterns = []*regexp.Regexp{
+	// dnsmasq: "query[A] example.com from 127.0.0.1"
+	regexp.MustCompile(`query\[[A-Z]+\]\s+(\S+)\s+from`),
+	// unbound (log-queries): "info: 127.0.0.1 example.com. A IN"
+	regexp.MustCompile(`info:\s+\S+\s+(\S+)\.\s+[A-Z]+\s+IN`),
+	// mDNSResponder: "... Query ... for example.com. (Addr)"
+	regexp.MustCompile(`for\s+([A-Za-z0-9\-\.]+)\.\s+\((?:Addr|AAAA)\)`),
+}
+
+// ParseQueryLine 从DNS查询日志行中提取被查询的主机名
+func ParseQueryLine(line string) (string, bool) {
+	for _, pattern := range queryPatterns {
+		if match := pattern.FindStringSubmatch(line); match != nil {
+			return normalizeHostname(match[1]), true
+		}
+	}
+	return "", false
+}
+
+// QueryLogTailer 持续读取DNS查询日志并累计命中次数
+type QueryLogTailer struct {
+	path     string
+	counter  Counter
+	interval time.Duration
+	offset   int64
+
+	pollMu sync.Mutex
+	mu     sync.Mutex
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewQueryLogTailer 创建DNS查询日志读取器
+func NewQueryLogTailer(path string, counter Counter, interval time.Duration) *QueryLogTailer {
+	if interval <= 0 {
+		interval = 5 * time.Second
+	}
+
+	return &QueryLogTailer{
+		path:     path,
+		counter:  counter,
+		interval: interval,
+	}
+}
+
+// Start 从日志末尾开始读取新的查询记录
+func (t *QueryLogTailer) Start() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.cancel != nil {
+		return fmt.Errorf("query log tailer already started")
+	}
+
+	info, err := os.Stat(t.path)
+	if err != nil {
+		return fmt.Errorf("failed to stat query log: %w", err)
+	}
+	t.pollMu.Lock()
+	t.offset = info.Size()
+	t.pollMu.Unlock()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.cancel = cancel
+	t.done = make(chan struct{})
+
+	go t.run(ctx)
+	return nil
+}
+
+// Stop 停止读取
+func (t *QueryLogTailer) Stop() {
+	t.mu.Lock()
+	cancel, done := t.cancel, t.done
+	t.cancel = nil
+	t.mu.Unlock()
+
+	if cancel != nil {
+		cancel()
+		<-done
+	}
+}
+
+// run 定期轮询日志文件
+func (t *QueryLogTailer) run(ctx context.Context) {
+	defer close(t.done)
+
+	ticker := time.NewTicker(t.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			_ = t.Poll()
+		}
+	}
+}
+
+// Poll 读取自上次以来新增的日志行
+func (t *QueryLogTailer) Poll() error {
+	t.pollMu.Lock()
+	defer t.pollMu.Unlock()
+
+	file, err := os.Open(t.path)
+	if err != nil {
+		return fmt.Errorf("failed to open query log: %w", err)
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return fmt.Errorf("failed to stat query log: %w", err)
+	}
+
+	// 日志被轮转或截断时从头读取
+	if info.Size() < t.offset {
+		t.offset = 0
+	}
+
+	if _, err := file.Seek(t.offset, io.SeekStart); err != nil {
+		return fmt.Errorf("failed to seek query log: %w", err)
+	}
+
+	reader := bufio.NewReader(file)
+	now := time.Now()
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			// 不完整的最后一行留到下次读取
+			break
+		}
+		t.offset += int64(len(line))
+
+		if hostname, ok := ParseQueryLine(line); ok {
+			t.counter.Record(hostname, now)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,424 @@
+// Package doctor 自动完成支持排查清单：检查Helper安装、hosts文件权限、数据目录、备份目录空间、
+// DNS缓存刷新能力和配置文件，并为每个问题给出可执行的修复建议。检查不修改任何文件。
+package doctor
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/flyhigher139/mhost/internal/config"
+	"github.com/flyhigher139/mhost/internal/helper"
+	"github.com/flyhigher139/mhost/internal/host"
+	"github.com/flyhigher139/mhost/pkg/models"
+)
+
+// DefaultMinFreeSpace 备份目录所在磁盘的最小剩余空间，低于该值时提示清理
+const DefaultMinFreeSpace = host.LowBackupSpace
+
+// profileFileName Profile数据文件名，与profile.Manager一致
+const profileFileName = "profiles.json"
+
+// Status 检查结果的状态
+type Status string
+
+const (
+	// StatusOK 检查通过
+	StatusOK Status = "ok"
+	// StatusWarning 可以使用，但部分功能受限或存在隐患
+	StatusWarning Status = "warning"
+	// StatusError 需要修复才能正常使用
+	StatusError Status = "error"
+	// StatusSkipped 当前平台不适用
+	StatusSkipped Status = "skipped"
+)
+
+// 检查项名称
+const (
+	CheckHelper      = "helper"
+	CheckHostsFile   = "hosts_permissions"
+	CheckDataDir     = "data_dir"
+	CheckBackupSpace = "backup_space"
+	CheckDNSFlush    = "dns_flush"
+	CheckConfig      = "config"
+)
+
+// Check 一项检查的结果
+type Check struct {
+	Name   string `json:"name"`
+	Status Status `json:"status"`
+	Detail string `json:"detail"`
+	// Fix 可执行的修复建议，检查通过时为空
+	Fix string `json:"fix,omitempty"`
+}
+
+// Report 全部检查的结果
+type Report struct {
+	GeneratedAt time.Time `json:"generated_at"`
+	Checks      []Check   `json:"checks"`
+}
+
+// Problems 未通过的检查
+func (r *Report) Problems() []Check {
+	var problems []Check
+	for _, check := range r.Checks {
+		if check.Status == StatusWarning || check.Status == StatusError {
+			problems = append(problems, check)
+		}
+	}
+	return problems
+}
+
+// HasErrors 是否存在需要修复的问题
+func (r *Report) HasErrors() bool {
+	for _, check := range r.Checks {
+		if check.Status == StatusError {
+			return true
+		}
+	}
+	return false
+}
+
+// Options 检查的对象
+type Options struct {
+	// HostsPath 为空时使用系统hosts文件
+	HostsPath  string
+	DataDir    string
+	BackupDir  string
+	ConfigPath string
+	// Config 验证配置内容的配置管理器（包括工作区路径限制），为nil时只使用AppConfig.Validate
+	Config config.Manager
+	// HelperService Helper服务名称，为空时使用helper.DefaultServiceName
+	HelperService string
+	// MinFreeSpace 备份目录所在磁盘的最小剩余字节数，为0时使用DefaultMinFreeSpace
+	MinFreeSpace uint64
+}
+
+// WorkspaceOptions 检查工作区的数据目录、备份目录和配置文件以及系统hosts文件
+func WorkspaceOptions(workspace *config.Workspace) Options {
+	return Options{
+		DataDir:    workspace.DataDir,
+		BackupDir:  workspace.BackupDir,
+		ConfigPath: workspace.ConfigPath,
+		Config:     config.NewWorkspaceConfigManager(workspace),
+	}
+}
+
+// Doctor 诊断检查
+type Doctor struct {
+	options Options
+	now     func() time.Time
+	goos    string
+
+	helperInstalled func() bool
+	freeSpace       func(dir string) (uint64, error)
+	lookPath        func(file string) (string, error)
+}
+
+// New 创建诊断检查
+func New(options Options) *Doctor {
+	if options.HostsPath == "" {
+		options.HostsPath = host.NewManager("", "").GetHostsFilePath()
+	}
+	if options.HelperService == "" {
+		options.HelperService = helper.DefaultServiceName
+	}
+	if options.MinFreeSpace == 0 {
+		options.MinFreeSpace = DefaultMinFreeSpace
+	}
+	return &Doctor{
+		options:         options,
+		now:             time.Now,
+		goos:            runtime.GOOS,
+		helperInstalled: func() bool { return helper.IsInstalled(options.HelperService) },
+		freeSpace:       host.FreeSpace,
+		lookPath:        exec.LookPath,
+	}
+}
+
+// Run 依次运行全部检查
+func (d *Doctor) Run() *Report {
+	helperInstalled := d.goos == "darwin" && d.helperInstalled()
+	return &Report{
+		GeneratedAt: d.now(),
+		Checks: []Check{
+			d.checkHelper(helperInstalled),
+			d.checkHostsFile(helperInstalled),
+			d.checkDataDir(),
+			d.checkBackupSpace(),
+			d.checkDNSFlush(),
+			d.checkConfig(),
+		},
+	}
+}
+
+// checkHelper 检查特权Helper是否已安装，Helper只在macOS上使用
+func (d *Doctor) checkHelper(installed bool) Check {
+	check := Check{Name: CheckHelper}
+	switch {
+	case d.goos != "darwin":
+		check.Status = StatusSkipped
+		check.Detail = "the privileged helper is only used on macOS; hosts file writes use sudo"
+	case installed:
+		check.Status = StatusOK
+		check.Detail = "installed at " + helper.InstallPath(d.options.HelperService)
+	default:
+		check.Status = StatusWarning
+		check.Detail = "not installed; every apply will ask for an administrator password"
+		check.Fix = "install the helper from the mHost menu, or run `mhost install-helper <path-to-helper>`"
+	}
+	return check
+}
+
+// checkHostsFile 检查hosts文件是否可读以及能否写入
+func (d *Doctor) checkHostsFile(helperInstalled bool) Check {
+	check := Check{Name: CheckHostsFile}
+	path := d.options.HostsPath
+	if _, err := os.ReadFile(path); err != nil {
+		check.Status = StatusError
+		check.Detail = fmt.Sprintf("cannot read %s: %v", path, err)
+		if errors.Is(err, os.ErrNotExist) {
+			check.Fix = "restore the hosts file from a backup in mHost, or create it with `sudo touch " + path + "`"
+		} else {
+			check.Fix = fmt.Sprintf("restore read access with `sudo chmod 644 %s`", path)
+		}
+		return check
+	}
+
+	result := host.CheckPreflight(path, helperInstalled)
+	switch {
+	case result.CanApply():
+		check.Status = StatusOK
+		check.Detail = path + " is writable by the current user"
+	case helperInstalled:
+		check.Status = StatusOK
+		check.Detail = path + " is written through the privileged helper"
+	default:
+		check.Status = StatusWarning
+		check.Detail = fmt.Sprintf("%s is not writable by the current user (%s)", path, result.Detail)
+		var fixes []string
+		for _, remediation := range result.Remediations {
+			switch remediation {
+			case host.RemediationInstallHelper:
+				fixes = append(fixes, "install the privileged helper")
+			case host.RemediationRunWithSudo:
+				fixes = append(fixes, "run `sudo mhostctl apply <profile>`")
+			case host.RemediationFixPermissions:
+				fixes = append(fixes, fmt.Sprintf("check ownership with `ls -l %s` (expected root, mode 644)", path))
+			}
+		}
+		check.Fix = strings.Join(fixes, ", or ")
+	}
+	return check
+}
+
+// checkDataDir 检查数据目录可写且Profile数据文件可以解析
+func (d *Doctor) checkDataDir() Check {
+	check := Check{Name: CheckDataDir}
+	dir := d.options.DataDir
+	info, err := os.Stat(dir)
+	if err != nil {
+		check.Status = StatusError
+		check.Detail = fmt.Sprintf("cannot access %s: %v", dir, err)
+		check.Fix = "start mHost once to create the data directory, or run `mkdir -p " + dir + "`"
+		return check
+	}
+	if !info.IsDir() {
+		check.Status = StatusError
+		check.Detail = dir + " is not a directory"
+		check.Fix = "move the file away so mHost can recreate the data directory"
+		return check
+	}
+	if err := checkWritable(dir); err != nil {
+		check.Status = StatusError
+		check.Detail = fmt.Sprintf("%s is not writable: %v", dir, err)
+		check.Fix = fmt.Sprintf("take ownership with `sudo chown -R $(whoami) %s`", dir)
+		return check
+	}
+
+	path := filepath.Join(dir, profileFileName)
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		check.Status = StatusOK
+		check.Detail = dir + " is writable; no profiles saved yet"
+		return check
+	}
+	var profiles struct {
+		Profiles map[string]*models.Profile `json:"profiles"`
+	}
+	if err == nil {
+		err = json.Unmarshal(data, &profiles)
+	}
+	if err != nil {
+		check.Status = StatusError
+		check.Detail = fmt.Sprintf("cannot load %s: %v", path, err)
+		check.Fix = "move the damaged file aside and restore profiles from a snapshot or exported bundle"
+		return check
+	}
+	check.Status = StatusOK
+	check.Detail = fmt.Sprintf("%s is writable; %d profiles", dir, len(profiles.Profiles))
+	return check
+}
+
+// checkBackupSpace 检查备份目录可写且所在磁盘有足够的剩余空间
+func (d *Doctor) checkBackupSpace() Check {
+	check := Check{Name: CheckBackupSpace}
+	path, err := config.CheckBackupPath(d.options.BackupDir, "")
+	if err != nil {
+		check.Status = StatusError
+		check.Detail = err.Error()
+		check.Fix = "choose a writable backup directory in the settings"
+		return check
+	}
+
+	// 目录尚不存在时检查最近的已存在的上级目录所在的磁盘
+	dir := path.Path
+	for !path.Exists {
+		if _, err := os.Stat(dir); err == nil {
+			break
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			break
+		}
+		dir = parent
+	}
+
+	free, err := d.freeSpace(dir)
+	switch {
+	case err != nil:
+		check.Status = StatusWarning
+		check.Detail = fmt.Sprintf("cannot determine free space for %s: %v", path.Path, err)
+	case free < d.options.MinFreeSpace:
+		check.Status = StatusWarning
+		check.Detail = fmt.Sprintf("only %s free on the disk holding %s", formatBytes(free), path.Path)
+		check.Fix = "free up disk space, lower the maximum number of backups or retention days, or move the backup directory in the settings"
+	default:
+		check.Status = StatusOK
+		check.Detail = fmt.Sprintf("%s free on the disk holding %s", formatBytes(free), path.Path)
+	}
+
+	if check.Status == StatusOK && (path.Temporary || path.CloudService != "") {
+		check.Status = StatusWarning
+		if path.Temporary {
+			check.Detail += "; the directory is in a temporary location that the system may clean up"
+		} else {
+			check.Detail += "; the directory is synced by " + path.CloudService
+		}
+		check.Fix = "move the backup directory to a local, persistent location in the settings"
+	}
+	return check
+}
+
+// dnsFlushCommands 各平台刷新DNS缓存所需的命令，任一组全部存在即可刷新
+var dnsFlushCommands = map[string][][]string{
+	"darwin": {{"dscacheutil", "killall"}},
+	"linux":  {{"resolvectl"}, {"systemd-resolve"}},
+}
+
+// checkDNSFlush 检查刷新系统DNS缓存所需的命令是否存在，修改hosts文件后需要刷新缓存才能立即生效
+func (d *Doctor) checkDNSFlush() Check {
+	check := Check{Name: CheckDNSFlush}
+	candidates, ok := dnsFlushCommands[d.goos]
+	if !ok {
+		check.Status = StatusSkipped
+		check.Detail = "DNS cache flushing is not supported on " + d.goos
+		return check
+	}
+
+	// 报告首选的一组命令中缺少的命令
+	var missing []string
+	for i, commands := range candidates {
+		var absent []string
+		for _, name := range commands {
+			if _, err := d.lookPath(name); err != nil {
+				absent = append(absent, name)
+			}
+		}
+		if len(absent) == 0 {
+			check.Status = StatusOK
+			check.Detail = "DNS cache can be flushed with " + strings.Join(commands, " and ")
+			return check
+		}
+		if i == 0 {
+			missing = absent
+		}
+	}
+	check.Status = StatusWarning
+	check.Detail = "missing " + strings.Join(missing, ", ") + "; hosts changes may not take effect until cached lookups expire"
+	if d.goos == "darwin" {
+		check.Fix = "flush manually with `sudo dscacheutil -flushcache; sudo killall -HUP mDNSResponder`"
+	} else {
+		check.Fix = "install systemd-resolved, or restart the local DNS cache (e.g. nscd or dnsmasq) after applying"
+	}
+	return check
+}
+
+// checkConfig 检查配置文件可以解析并通过验证，不存在时使用默认配置
+func (d *Doctor) checkConfig() Check {
+	check := Check{Name: CheckConfig}
+	path := d.options.ConfigPath
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		check.Status = StatusOK
+		check.Detail = "no config file; defaults are used"
+		return check
+	}
+	if err != nil {
+		check.Status = StatusError
+		check.Detail = fmt.Sprintf("cannot read %s: %v", path, err)
+		check.Fix = fmt.Sprintf("restore read access with `chmod 644 %s`", path)
+		return check
+	}
+
+	var appConfig models.AppConfig
+	if err := json.Unmarshal(data, &appConfig); err != nil {
+		check.Status = StatusError
+		check.Detail = fmt.Sprintf("cannot parse %s: %v", path, err)
+		check.Fix = "fix the JSON syntax, or delete the file to restore the default settings"
+		return check
+	}
+	if d.options.Config != nil {
+		err = d.options.Config.ValidateConfig(&appConfig)
+	} else {
+		err = appConfig.Validate()
+	}
+	if err != nil {
+		check.Status = StatusError
+		check.Detail = fmt.Sprintf("%s is invalid: %v", path, err)
+		check.Fix = "correct the values in the settings and save, or delete the file to restore the default settings"
+		return check
+	}
+	check.Status = StatusOK
+	check.Detail = path + " is valid"
+	return check
+}
+
+// checkWritable 在目录中创建并删除临时文件，确认当前用户可以写入
+func checkWritable(dir string) error {
+	f, err := os.CreateTemp(dir, ".mhost-doctor-*")
+	if err != nil {
+		return err
+	}
+	name := f.Name()
+	f.Close()
+	return os.Remove(name)
+}
+
+// formatBytes 将字节数格式化为KB、MB或GB
+func formatBytes(size uint64) string {
+	switch {
+	case size < 1<<20:
+		return fmt.Sprintf("%.1f KB", float64(size)/(1<<10))
+	case size < 1<<30:
+		return fmt.Sprintf("%.1f MB", float64(size)/(1<<20))
+	default:
+		return fmt.Sprintf("%.1f GB", float64(size)/(1<<30))
+	}
+}
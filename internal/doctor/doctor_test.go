@@ -0,0 +1,150 @@
+package doctor
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/flyhigher139/mhost/internal/config"
+	"github.com/flyhigher139/mhost/pkg/models"
+)
+
+// newTestDoctor 创建使用临时目录的诊断检查，Helper、磁盘空间和命令查找均可由测试控制
+func newTestDoctor(t *testing.T) (*Doctor, Options) {
+	dir := t.TempDir()
+	options := Options{
+		HostsPath:  filepath.Join(dir, "hosts"),
+		DataDir:    filepath.Join(dir, "data"),
+		BackupDir:  filepath.Join(dir, "data", "backups"),
+		ConfigPath: filepath.Join(dir, "data", "config.json"),
+	}
+	require.NoError(t, os.WriteFile(options.HostsPath, []byte("127.0.0.1 localhost\n"), 0644))
+	require.NoError(t, os.MkdirAll(options.DataDir, 0755))
+
+	d := New(options)
+	d.now = func() time.Time { return time.Date(2025, 7, 1, 12, 0, 0, 0, time.UTC) }
+	d.goos = "darwin"
+	d.helperInstalled = func() bool { return true }
+	d.freeSpace = func(string) (uint64, error) { return 10 << 30, nil }
+	d.lookPath = func(file string) (string, error) { return "/usr/bin/" + file, nil }
+	return d, options
+}
+
+// checkNamed 按名称查找检查结果
+func checkNamed(t *testing.T, report *Report, name string) Check {
+	for _, check := range report.Checks {
+		if check.Name == name {
+			return check
+		}
+	}
+	t.Fatalf("check %s not found", name)
+	return Check{}
+}
+
+// TestRun 测试各项检查均通过时的报告
+func TestRun(t *testing.T) {
+	d, options := newTestDoctor(t)
+	require.NoError(t, os.WriteFile(filepath.Join(options.DataDir, profileFileName), []byte(`{"profiles":{"p1":{"id":"p1","name":"Dev"}}}`), 0644))
+
+	report := d.Run()
+	require.Len(t, report.Checks, 6)
+	assert.False(t, report.HasErrors())
+	assert.Contains(t, checkNamed(t, report, CheckDataDir).Detail, "1 profiles")
+	assert.Equal(t, "no config file; defaults are used", checkNamed(t, report, CheckConfig).Detail)
+
+	// 测试目录位于系统临时目录，备份目录会被提示可能被清理
+	problems := report.Problems()
+	require.Len(t, problems, 1)
+	assert.Equal(t, CheckBackupSpace, problems[0].Name)
+	assert.Contains(t, problems[0].Detail, "10.0 GB free")
+	assert.Contains(t, problems[0].Detail, "temporary location")
+	for _, check := range report.Checks {
+		if check.Name != CheckBackupSpace {
+			assert.Empty(t, check.Fix, check.Name)
+		}
+	}
+}
+
+// TestRunProblems 测试各项检查发现问题时的状态和修复建议
+func TestRunProblems(t *testing.T) {
+	d, options := newTestDoctor(t)
+	d.helperInstalled = func() bool { return false }
+	d.freeSpace = func(string) (uint64, error) { return 1 << 20, nil }
+	d.lookPath = func(file string) (string, error) {
+		if file == "killall" {
+			return "", errors.New("not found")
+		}
+		return "/usr/bin/" + file, nil
+	}
+	require.NoError(t, os.WriteFile(filepath.Join(options.DataDir, profileFileName), []byte("{broken"), 0644))
+	require.NoError(t, os.WriteFile(options.ConfigPath, []byte(`{"window":{"width":0}}`), 0644))
+
+	report := d.Run()
+	assert.True(t, report.HasErrors())
+
+	helper := checkNamed(t, report, CheckHelper)
+	assert.Equal(t, StatusWarning, helper.Status)
+	assert.Contains(t, helper.Fix, "install-helper")
+
+	backup := checkNamed(t, report, CheckBackupSpace)
+	assert.Equal(t, StatusWarning, backup.Status)
+	assert.Contains(t, backup.Detail, "1.0 MB free")
+	assert.NotEmpty(t, backup.Fix)
+
+	dns := checkNamed(t, report, CheckDNSFlush)
+	assert.Equal(t, StatusWarning, dns.Status)
+	assert.Contains(t, dns.Detail, "missing killall")
+	assert.Contains(t, dns.Fix, "mDNSResponder")
+
+	data := checkNamed(t, report, CheckDataDir)
+	assert.Equal(t, StatusError, data.Status)
+	assert.Contains(t, data.Detail, profileFileName)
+
+	cfg := checkNamed(t, report, CheckConfig)
+	assert.Equal(t, StatusError, cfg.Status)
+	assert.Contains(t, cfg.Detail, "invalid")
+	assert.Len(t, report.Problems(), 5)
+
+	// 数据目录不存在
+	require.NoError(t, os.RemoveAll(options.DataDir))
+	data = checkNamed(t, d.Run(), CheckDataDir)
+	assert.Equal(t, StatusError, data.Status)
+	assert.Contains(t, data.Fix, "mkdir -p")
+}
+
+// TestCheckConfigWorkspace 测试配置文件按工作区的路径限制验证
+func TestCheckConfigWorkspace(t *testing.T) {
+	root := t.TempDir()
+	workspaces := config.NewWorkspaceManager(root)
+	workspace, err := workspaces.CreateWorkspace("work")
+	require.NoError(t, err)
+
+	appConfig := models.DefaultAppConfig()
+	appConfig.Backup.BackupPath = filepath.Join(root, "elsewhere")
+	data, err := json.Marshal(appConfig)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(workspace.ConfigPath, data, 0644))
+
+	options := WorkspaceOptions(workspace)
+	assert.Equal(t, workspace.DataDir, options.DataDir)
+	check := New(options).checkConfig()
+	assert.Equal(t, StatusError, check.Status)
+	assert.Contains(t, check.Detail, "outside the workspace")
+}
+
+// TestCheckHelperOtherPlatforms 测试非macOS平台跳过Helper检查，未知平台跳过DNS缓存检查
+func TestCheckHelperOtherPlatforms(t *testing.T) {
+	d, _ := newTestDoctor(t)
+	d.goos = "freebsd"
+
+	report := d.Run()
+	assert.Equal(t, StatusSkipped, checkNamed(t, report, CheckHelper).Status)
+	assert.Equal(t, StatusSkipped, checkNamed(t, report, CheckDNSFlush).Status)
+	assert.Equal(t, StatusOK, checkNamed(t, report, CheckHostsFile).Status)
+}
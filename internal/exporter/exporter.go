@@ -0,0 +1,173 @@
+// Package exporter 将Profile转换为其他工具使用的hosts覆盖格式。
+package exporter
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/flyhigher139/mhost/pkg/hostsfile"
+	"github.com/flyhigher139/mhost/pkg/models"
+)
+
+// Format 导出格式
+type Format string
+
+const (
+	// FormatHosts hosts文件格式
+	FormatHosts Format = "hosts"
+	// FormatCompose docker-compose extra_hosts
+	FormatCompose Format = "compose"
+	// FormatKubernetes Kubernetes hostAliases补丁
+	FormatKubernetes Format = "kubernetes"
+)
+
+// Exporter 导出器接口
+type Exporter interface {
+	// Format 导出格式
+	Format() Format
+
+	// FileExtension 建议的文件扩展名
+	FileExtension() string
+
+	// Export 导出Profile中启用的条目
+	Export(profile *models.Profile) ([]byte, error)
+}
+
+// Formats 返回所有支持的导出格式
+func Formats() []Format {
+	return []Format{FormatHosts, FormatCompose, FormatKubernetes}
+}
+
+// New 根据格式创建导出器
+func New(format Format) (Exporter, error) {
+	switch format {
+	case FormatHosts:
+		return &HostsExporter{}, nil
+	case FormatCompose:
+		return &ComposeExporter{}, nil
+	case FormatKubernetes:
+		return &KubernetesExporter{}, nil
+	default:
+		return nil, fmt.Errorf("unsupported export format: %s", format)
+	}
+}
+
+// EnabledEntries 返回启用的条目，IP按Profile当前环境解析，导出器和导出插件都只导出这些条目
+func EnabledEntries(profile *models.Profile) ([]*models.HostEntry, error) {
+	if profile == nil {
+		return nil, models.ErrInvalidProfile
+	}
+
+	var entries []*models.HostEntry
+	for _, entry := range profile.ResolvedEntries() {
+		if entry.Enabled {
+			entries = append(entries, entry)
+		}
+	}
+	return entries, nil
+}
+
+// HostsExporter 导出为hosts文件格式
+type HostsExporter struct{}
+
+// Format 导出格式
+func (e *HostsExporter) Format() Format { return FormatHosts }
+
+// FileExtension 建议的文件扩展名
+func (e *HostsExporter) FileExtension() string { return ".hosts" }
+
+// Export 导出为hosts文件行
+func (e *HostsExporter) Export(profile *models.Profile) ([]byte, error) {
+	entries, err := EnabledEntries(profile)
+	if err != nil {
+		return nil, err
+	}
+
+	lines := append([]string{fmt.Sprintf("# Profile: %s", profile.Name)}, hostsfile.RenderEntries(hostsfile.FromModels(entries), false)...)
+	return []byte(strings.Join(lines, "\n") + "\n"), nil
+}
+
+// ComposeExporter 导出为docker-compose extra_hosts，ServiceName为空时只输出extra_hosts片段
+type ComposeExporter struct {
+	ServiceName string
+}
+
+// Format 导出格式
+func (e *ComposeExporter) Format() Format { return FormatCompose }
+
+// FileExtension 建议的文件扩展名
+func (e *ComposeExporter) FileExtension() string { return ".yaml" }
+
+// Export 导出为extra_hosts YAML
+func (e *ComposeExporter) Export(profile *models.Profile) ([]byte, error) {
+	entries, err := EnabledEntries(profile)
+	if err != nil {
+		return nil, err
+	}
+
+	extraHosts := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		// compose使用 "hostname:ip" 格式，IPv6地址同样适用
+		extraHosts = append(extraHosts, fmt.Sprintf("%s:%s", entry.Hostname, entry.IP))
+	}
+
+	var doc interface{} = map[string][]string{"extra_hosts": extraHosts}
+	if e.ServiceName != "" {
+		doc = map[string]interface{}{
+			"services": map[string]interface{}{
+				e.ServiceName: doc,
+			},
+		}
+	}
+
+	return yaml.Marshal(doc)
+}
+
+// hostAlias Kubernetes hostAliases条目
+type hostAlias struct {
+	IP        string   `yaml:"ip"`
+	Hostnames []string `yaml:"hostnames"`
+}
+
+// KubernetesExporter 导出为Pod模板的hostAliases补丁
+type KubernetesExporter struct{}
+
+// Format 导出格式
+func (e *KubernetesExporter) Format() Format { return FormatKubernetes }
+
+// FileExtension 建议的文件扩展名
+func (e *KubernetesExporter) FileExtension() string { return ".yaml" }
+
+// Export 导出为可用于 kubectl patch 的hostAliases补丁，按IP分组
+func (e *KubernetesExporter) Export(profile *models.Profile) ([]byte, error) {
+	entries, err := EnabledEntries(profile)
+	if err != nil {
+		return nil, err
+	}
+
+	byIP := make(map[string][]string)
+	for _, entry := range entries {
+		byIP[entry.IP] = append(byIP[entry.IP], entry.Hostname)
+	}
+
+	aliases := make([]hostAlias, 0, len(byIP))
+	for ip, hostnames := range byIP {
+		aliases = append(aliases, hostAlias{IP: ip, Hostnames: hostnames})
+	}
+	sort.Slice(aliases, func(i, j int) bool { return aliases[i].IP < aliases[j].IP })
+
+	patch := map[string]interface{}{
+		"spec": map[string]interface{}{
+			"template": map[string]interface{}{
+				"spec": map[string]interface{}{
+					"hostAliases": aliases,
+				},
+			},
+		},
+	}
+
+	return yaml.Marshal(patch)
+}
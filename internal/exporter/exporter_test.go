@@ -0,0 +1,72 @@
+package exporter
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/flyhigher139/mhost/pkg/models"
+)
+
+// newTestProfile 创建测试用Profile
+func newTestProfile() *models.Profile {
+	profile := models.NewProfile("Staging", "")
+	profile.AddEntry(models.NewHostEntry("10.0.0.1", "api.test", "api"))
+	profile.AddEntry(models.NewHostEntry("10.0.0.1", "web.test", ""))
+	profile.AddEntry(models.NewHostEntry("10.0.0.2", "db.test", ""))
+	disabled := models.NewHostEntry("10.0.0.3", "off.test", "")
+	disabled.Enabled = false
+	profile.AddEntry(disabled)
+	return profile
+}
+
+// TestComposeExporter 测试导出docker-compose extra_hosts
+func TestComposeExporter(t *testing.T) {
+	data, err := (&ComposeExporter{}).Export(newTestProfile())
+	require.NoError(t, err)
+	assert.Equal(t, "extra_hosts:\n    - api.test:10.0.0.1\n    - web.test:10.0.0.1\n    - db.test:10.0.0.2\n", string(data))
+
+	data, err = (&ComposeExporter{ServiceName: "app"}).Export(newTestProfile())
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "services:\n    app:\n        extra_hosts:\n")
+}
+
+// TestKubernetesExporter 测试导出Kubernetes hostAliases
+func TestKubernetesExporter(t *testing.T) {
+	data, err := (&KubernetesExporter{}).Export(newTestProfile())
+	require.NoError(t, err)
+
+	expected := `spec:
+    template:
+        spec:
+            hostAliases:
+                - ip: 10.0.0.1
+                  hostnames:
+                    - api.test
+                    - web.test
+                - ip: 10.0.0.2
+                  hostnames:
+                    - db.test
+`
+	assert.Equal(t, expected, string(data))
+}
+
+// TestNew 测试创建导出器
+func TestNew(t *testing.T) {
+	for _, format := range Formats() {
+		exporter, err := New(format)
+		require.NoError(t, err)
+		assert.Equal(t, format, exporter.Format())
+	}
+
+	_, err := New("unknown")
+	assert.Error(t, err)
+
+	_, err = (&HostsExporter{}).Export(nil)
+	assert.Equal(t, models.ErrInvalidProfile, err)
+
+	data, err := (&HostsExporter{}).Export(newTestProfile())
+	require.NoError(t, err)
+	assert.Equal(t, "# Profile: Staging\n10.0.0.1\tapi.test\t# api\n10.0.0.1\tweb.test\n10.0.0.2\tdb.test\n", string(data))
+}
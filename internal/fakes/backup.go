@@ -0,0 +1,256 @@
+package fakes
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"path"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/flyhigher139/mhost/internal/helper"
+	"github.com/flyhigher139/mhost/internal/host"
+	"github.com/flyhigher139/mhost/pkg/errors"
+)
+
+// BackupManager helper.BackupManager的内存实现，源文件和备份内容都保存在FS中
+type BackupManager struct {
+	failures
+
+	FS         *FS
+	BackupDir  string
+	MaxBackups int
+
+	mu      sync.RWMutex
+	backups map[string]*helper.BackupInfo
+	seq     int
+}
+
+var _ helper.BackupManager = (*BackupManager)(nil)
+
+// NewBackupManager 创建内存备份管理器，fs为nil时创建新的FS；maxBackups不大于0时不限制数量
+func NewBackupManager(fs *FS, maxBackups int) *BackupManager {
+	if fs == nil {
+		fs = NewFS()
+	}
+	return &BackupManager{
+		FS:         fs,
+		BackupDir:  DefaultBackupDir,
+		MaxBackups: maxBackups,
+		backups:    make(map[string]*helper.BackupInfo),
+	}
+}
+
+// checksum 计算内容的SHA-256校验和
+func checksum(data []byte) string {
+	return fmt.Sprintf("%x", sha256.Sum256(data))
+}
+
+// CreateBackup 将FS中的源文件复制为备份；超过MaxBackups时删除最旧的备份
+func (m *BackupManager) CreateBackup(sourcePath, name, description string, tags []string, automatic bool) (*helper.BackupInfo, error) {
+	if err := m.failure("CreateBackup"); err != nil {
+		return nil, err
+	}
+
+	data, err := m.FS.ReadFile(sourcePath)
+	if err != nil {
+		return nil, errors.NewFileSystemError(errors.ErrCodeFileNotFound, fmt.Sprintf("source file does not exist: %s", sourcePath), err)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.seq++
+	// 序号保证同一时刻创建的备份按创建顺序排列
+	createdAt := time.Now().Add(time.Duration(m.seq) * time.Nanosecond)
+	info := &helper.BackupInfo{
+		ID:           fmt.Sprintf("backup-%d", m.seq),
+		Name:         name,
+		OriginalPath: sourcePath,
+		CreatedAt:    createdAt,
+		Size:         int64(len(data)),
+		Checksum:     checksum(data),
+		Description:  description,
+		Tags:         tags,
+		Automatic:    automatic,
+	}
+	info.Path = path.Join(m.BackupDir, info.ID+".backup")
+	m.FS.WriteFile(info.Path, data)
+	m.backups[info.ID] = info
+
+	m.cleanup()
+	return info, nil
+}
+
+// RestoreBackup 校验备份后写回targetPath，targetPath为空时写回原始路径
+func (m *BackupManager) RestoreBackup(backupID, targetPath string) error {
+	if err := m.failure("RestoreBackup"); err != nil {
+		return err
+	}
+
+	m.mu.RLock()
+	info, ok := m.backups[backupID]
+	m.mu.RUnlock()
+	if !ok {
+		return errors.NewValidationError(errors.ErrCodeBackupNotFound, fmt.Sprintf("backup not found: %s", backupID), nil)
+	}
+
+	data, err := m.FS.ReadFile(info.Path)
+	if err != nil {
+		return errors.NewFileSystemError(errors.ErrCodeFileNotFound, fmt.Sprintf("backup file does not exist: %s", info.Path), err)
+	}
+	if actual := checksum(data); actual != info.Checksum {
+		return errors.NewValidationError(errors.ErrCodeBackupCorrupted, "backup file corrupted: checksum mismatch", map[string]interface{}{
+			"expected_checksum": info.Checksum,
+			"actual_checksum":   actual,
+		})
+	}
+
+	if targetPath == "" {
+		targetPath = info.OriginalPath
+	}
+	return m.FS.WriteFile(targetPath, data)
+}
+
+// DeleteBackup 删除备份
+func (m *BackupManager) DeleteBackup(backupID string) error {
+	if err := m.failure("DeleteBackup"); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	info, ok := m.backups[backupID]
+	if !ok {
+		return fmt.Errorf("backup not found: %s", backupID)
+	}
+	m.FS.Remove(info.Path)
+	delete(m.backups, backupID)
+	return nil
+}
+
+// ListBackups 列出所有备份，最新的在前
+func (m *BackupManager) ListBackups() []*helper.BackupInfo {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.sorted()
+}
+
+// sorted 按创建时间排序的备份列表，最新的在前（需要持有锁）
+func (m *BackupManager) sorted() []*helper.BackupInfo {
+	backups := make([]*helper.BackupInfo, 0, len(m.backups))
+	for _, info := range m.backups {
+		backups = append(backups, info)
+	}
+	sort.Slice(backups, func(i, j int) bool {
+		return backups[i].CreatedAt.After(backups[j].CreatedAt)
+	})
+	return backups
+}
+
+// GetBackup 获取指定备份信息
+func (m *BackupManager) GetBackup(backupID string) (*helper.BackupInfo, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	info, ok := m.backups[backupID]
+	if !ok {
+		return nil, fmt.Errorf("backup not found: %s", backupID)
+	}
+	return info, nil
+}
+
+// GetBackupStats 获取备份统计信息
+func (m *BackupManager) GetBackupStats() *helper.BackupStats {
+	summary := m.GetBackupHistory().Summary
+	return &helper.BackupStats{
+		TotalBackups:     summary.TotalBackups,
+		TotalSize:        summary.TotalSize,
+		OldestBackup:     summary.OldestBackup,
+		NewestBackup:     summary.NewestBackup,
+		AutomaticBackups: summary.AutomaticBackups,
+		ManualBackups:    summary.ManualBackups,
+		ValidBackups:     summary.ValidBackups,
+		InvalidBackups:   summary.InvalidBackups,
+	}
+}
+
+// GetBackupHistory 获取可导出的备份历史，每个备份附带验证结果
+func (m *BackupManager) GetBackupHistory() *host.BackupHistory {
+	backups := m.ListBackups()
+
+	records := make([]host.BackupRecord, 0, len(backups))
+	for _, info := range backups {
+		record := host.BackupRecord{
+			ID:        info.ID,
+			Name:      info.Name,
+			Path:      info.Path,
+			CreatedAt: info.CreatedAt,
+			Size:      info.Size,
+			Automatic: info.Automatic,
+			Valid:     true,
+		}
+		if err := m.validate(info); err != nil {
+			record.Valid = false
+			record.Error = err.Error()
+		}
+		records = append(records, record)
+	}
+	return host.NewBackupHistory(records)
+}
+
+// CleanupOldBackups 删除超过MaxBackups的最旧备份
+func (m *BackupManager) CleanupOldBackups() error {
+	if err := m.failure("CleanupOldBackups"); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.cleanup()
+	return nil
+}
+
+// cleanup 删除超过MaxBackups的最旧备份，里程碑备份不计入数量（需要持有锁）
+func (m *BackupManager) cleanup() {
+	if m.MaxBackups <= 0 {
+		return
+	}
+	kept := 0
+	for _, info := range m.sorted() {
+		if info.Protected() {
+			continue
+		}
+		if kept < m.MaxBackups {
+			kept++
+			continue
+		}
+		m.FS.Remove(info.Path)
+		delete(m.backups, info.ID)
+	}
+}
+
+// ValidateBackup 检查备份内容是否存在且与记录的大小和校验和一致
+func (m *BackupManager) ValidateBackup(backupID string) error {
+	info, err := m.GetBackup(backupID)
+	if err != nil {
+		return err
+	}
+	return m.validate(info)
+}
+
+// validate 检查备份内容是否存在且与记录的大小和校验和一致
+func (m *BackupManager) validate(info *helper.BackupInfo) error {
+	data, err := m.FS.ReadFile(info.Path)
+	if err != nil {
+		return fmt.Errorf("backup file does not exist: %s", info.Path)
+	}
+	if int64(len(data)) != info.Size {
+		return fmt.Errorf("backup file size mismatch: expected %d, got %d", info.Size, len(data))
+	}
+	if checksum(data) != info.Checksum {
+		return fmt.Errorf("backup file corrupted: checksum mismatch")
+	}
+	return nil
+}
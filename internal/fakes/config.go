@@ -0,0 +1,194 @@
+package fakes
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"sync"
+
+	"github.com/flyhigher139/mhost/internal/config"
+	"github.com/flyhigher139/mhost/pkg/models"
+)
+
+// DefaultConfigPath ConfigManager默认使用的配置文件路径，仅作为FS中的键
+const DefaultConfigPath = "/config/config.json"
+
+// ConfigManager config.Manager的内存实现，配置及其备份以JSON保存在FS中
+type ConfigManager struct {
+	failures
+
+	FS         *FS
+	ConfigPath string
+	BackupDir  string
+
+	mu        sync.Mutex
+	current   *models.AppConfig
+	backupSeq int
+	watcher   func(*models.AppConfig)
+}
+
+var _ config.Manager = (*ConfigManager)(nil)
+
+// NewConfigManager 创建内存配置管理器，fs为nil时创建新的FS；cfg为nil时使用默认配置
+func NewConfigManager(fs *FS, cfg *models.AppConfig) *ConfigManager {
+	if fs == nil {
+		fs = NewFS()
+	}
+	if cfg == nil {
+		cfg = models.DefaultAppConfig()
+	}
+	return &ConfigManager{
+		FS:         fs,
+		ConfigPath: DefaultConfigPath,
+		BackupDir:  path.Join(path.Dir(DefaultConfigPath), "backups"),
+		current:    cfg.Clone(),
+	}
+}
+
+// LoadConfig 从FS加载配置，配置文件不存在时保存并返回当前配置
+func (m *ConfigManager) LoadConfig() (*models.AppConfig, error) {
+	if err := m.failure("LoadConfig"); err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	data, err := m.FS.ReadFile(m.ConfigPath)
+	if err != nil {
+		return m.current.Clone(), m.save(m.current)
+	}
+
+	var cfg models.AppConfig
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse config file: %w", err)
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid config: %w", err)
+	}
+	m.current = &cfg
+	return cfg.Clone(), nil
+}
+
+// save 将配置以JSON写入FS（需要持有锁）
+func (m *ConfigManager) save(cfg *models.AppConfig) error {
+	data, err := json.MarshalIndent(cfg, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal config: %w", err)
+	}
+	return m.FS.WriteFile(m.ConfigPath, data)
+}
+
+// SaveConfig 验证并保存配置，通知通过WatchConfig注册的回调
+func (m *ConfigManager) SaveConfig(cfg *models.AppConfig) error {
+	if err := m.failure("SaveConfig"); err != nil {
+		return err
+	}
+	if cfg == nil {
+		return models.ErrInvalidConfig
+	}
+	if err := cfg.Validate(); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	if err := m.save(cfg); err != nil {
+		m.mu.Unlock()
+		return err
+	}
+	m.current = cfg.Clone()
+	watcher := m.watcher
+	m.mu.Unlock()
+
+	if watcher != nil {
+		watcher(cfg.Clone())
+	}
+	return nil
+}
+
+// GetConfig 获取当前配置的副本
+func (m *ConfigManager) GetConfig() *models.AppConfig {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.current.Clone()
+}
+
+// UpdateConfig 在当前配置的副本上应用修改，验证通过后保存
+func (m *ConfigManager) UpdateConfig(updater func(*models.AppConfig)) error {
+	cfg := m.GetConfig()
+	updater(cfg)
+	return m.SaveConfig(cfg)
+}
+
+// ResetToDefault 重置为默认配置
+func (m *ConfigManager) ResetToDefault() error {
+	return m.SaveConfig(models.DefaultAppConfig())
+}
+
+// GetConfigPath 获取配置文件路径
+func (m *ConfigManager) GetConfigPath() string {
+	return m.ConfigPath
+}
+
+// ValidateConfig 验证配置有效性
+func (m *ConfigManager) ValidateConfig(cfg *models.AppConfig) error {
+	if cfg == nil {
+		return models.ErrInvalidConfig
+	}
+	return cfg.Validate()
+}
+
+// BackupConfig 将配置文件复制到FS中的备份目录
+func (m *ConfigManager) BackupConfig() error {
+	if err := m.failure("BackupConfig"); err != nil {
+		return err
+	}
+
+	data, err := m.FS.ReadFile(m.ConfigPath)
+	if err != nil {
+		return models.ErrConfigNotFound
+	}
+
+	m.mu.Lock()
+	m.backupSeq++
+	backupPath := path.Join(m.BackupDir, fmt.Sprintf("config_backup_%d.json", m.backupSeq))
+	m.mu.Unlock()
+
+	return m.FS.WriteFile(backupPath, data)
+}
+
+// RestoreConfig 从FS中的备份恢复配置
+func (m *ConfigManager) RestoreConfig(backupPath string) error {
+	data, err := m.FS.ReadFile(backupPath)
+	if err != nil {
+		return models.ErrFileNotFound
+	}
+
+	var cfg models.AppConfig
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return fmt.Errorf("failed to parse backup config: %w", err)
+	}
+	if err := cfg.Validate(); err != nil {
+		return fmt.Errorf("invalid backup config: %w", err)
+	}
+	return m.SaveConfig(&cfg)
+}
+
+// WatchConfig 注册配置变化回调；内存实现在每次保存后同步调用回调
+func (m *ConfigManager) WatchConfig(callback func(*models.AppConfig)) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.watcher != nil {
+		return fmt.Errorf("already watching config file")
+	}
+	m.watcher = callback
+	return nil
+}
+
+// StopWatching 停止通知配置变化
+func (m *ConfigManager) StopWatching() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.watcher = nil
+}
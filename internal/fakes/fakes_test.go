@@ -0,0 +1,209 @@
+package fakes
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/flyhigher139/mhost/internal/helper"
+	"github.com/flyhigher139/mhost/internal/host"
+	"github.com/flyhigher139/mhost/pkg/errors"
+	"github.com/flyhigher139/mhost/pkg/hostsfile/hostsfiletest"
+	"github.com/flyhigher139/mhost/pkg/models"
+)
+
+// TestHostManagerGolden 测试内存hosts管理器和Helper客户端的写入结果与真实实现共用的golden用例一致
+func TestHostManagerGolden(t *testing.T) {
+	for name, apply := range map[string]func(m *HostManager, c hostsfiletest.Case) error{
+		"apply": func(m *HostManager, c hostsfiletest.Case) error {
+			_, err := m.ApplyProfile(c.Profile)
+			return err
+		},
+		"helper": func(m *HostManager, c hostsfiletest.Case) error {
+			client := NewXPCClient(m)
+			if err := client.Connect(); err != nil {
+				return err
+			}
+			return client.WriteHosts(context.Background(), c.Entries())
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			hostsfiletest.Run(t, func(t *testing.T, c hostsfiletest.Case, hostsPath string) {
+				m := NewHostManager(nil)
+				require.NoError(t, m.FS.WriteFile(m.HostsPath, c.Input))
+				require.NoError(t, apply(m, c))
+
+				data, err := m.FS.ReadFile(m.HostsPath)
+				require.NoError(t, err)
+				require.NoError(t, os.WriteFile(hostsPath, data, 0644))
+			})
+		})
+	}
+}
+
+// TestHostManager 测试应用、漂移、规模限制、备份恢复和错误注入
+func TestHostManager(t *testing.T) {
+	m := NewHostManager(nil, "127.0.0.1 localhost")
+	p := models.NewProfile("Dev", "")
+	p.AddEntry(models.NewHostEntry("10.0.0.1", "api.test", ""))
+
+	m.SetBackupOnApply(true)
+	result, err := m.ApplyProfile(p)
+	require.NoError(t, err)
+	assert.Len(t, result.Added, 1)
+	assert.True(t, m.FS.Exists(result.BackupPath))
+	assert.Equal(t, 1, m.Writes())
+
+	drift, err := m.DetectDrift(p)
+	require.NoError(t, err)
+	assert.False(t, drift.HasDrift())
+
+	backup, err := m.BackupHostsFile()
+	require.NoError(t, err)
+	require.NoError(t, m.FS.WriteFile(m.HostsPath, []byte("127.0.0.1 localhost\n")))
+	drift, err = m.DetectDrift(p)
+	require.NoError(t, err)
+	assert.Len(t, drift.Removed, 1)
+	require.NoError(t, m.RestoreFromBackup(backup))
+	drift, err = m.DetectDrift(p)
+	require.NoError(t, err)
+	assert.False(t, drift.HasDrift())
+
+	m.SetLimits(models.LimitsConfig{MaxEntries: 1})
+	_, err = m.ApplyProfile(p)
+	assert.ErrorIs(t, err, host.ErrLimitExceeded)
+
+	failure := os.ErrPermission
+	m.Fail("WriteHostsFile", failure)
+	_, err = m.ApplyProfileWithOptions(p, host.ApplyOptions{IgnoreLimits: true})
+	assert.ErrorIs(t, err, failure)
+	m.Fail("WriteHostsFile", nil)
+	_, err = m.ApplyProfileWithOptions(p, host.ApplyOptions{IgnoreLimits: true})
+	assert.NoError(t, err)
+}
+
+// TestProfileManager 测试与真实实现一致的激活、名称冲突和导入导出规则
+func TestProfileManager(t *testing.T) {
+	m := NewProfileManager(nil)
+
+	dev, err := m.CreateProfile("Dev", "development")
+	require.NoError(t, err)
+	assert.True(t, dev.IsActive, "first profile is activated")
+	_, err = m.CreateProfile("Dev", "")
+	assert.ErrorIs(t, err, models.ErrProfileExists)
+
+	test, err := m.CreateProfile("Test", "")
+	require.NoError(t, err)
+	assert.False(t, test.IsActive)
+	assert.ErrorIs(t, m.DeleteProfile(dev.ID), models.ErrActiveProfile)
+
+	// 返回的是副本，修改后需要UpdateProfile才会保存
+	dev.AddEntry(models.NewHostEntry("10.0.0.1", "api.test", ""))
+	active, err := m.GetActiveProfile()
+	require.NoError(t, err)
+	assert.Empty(t, active.Entries)
+	require.NoError(t, m.UpdateProfile(dev))
+	active, err = m.GetActiveProfile()
+	require.NoError(t, err)
+	assert.Len(t, active.Entries, 1)
+
+	require.NoError(t, m.ActivateProfile(test.ID))
+	require.NoError(t, m.DeleteProfile(dev.ID))
+	require.NoError(t, m.ActivateProfile(test.ID))
+
+	require.NoError(t, m.ExportProfile(test.ID, "/export/test.json"))
+	imported, err := m.ImportProfile("/export/test.json")
+	require.NoError(t, err)
+	assert.Equal(t, "Test (1)", imported.Name)
+	assert.NotEqual(t, test.ID, imported.ID)
+
+	results, err := m.SearchProfiles("test")
+	require.NoError(t, err)
+	assert.Len(t, results, 2)
+
+	m.Fail("ListProfiles", os.ErrClosed)
+	_, err = m.ListProfiles()
+	assert.ErrorIs(t, err, os.ErrClosed)
+}
+
+// TestBackupManager 测试备份hosts管理器写入的内容、校验、恢复和数量限制
+func TestBackupManager(t *testing.T) {
+	hosts := NewHostManager(nil, "127.0.0.1 localhost")
+	m := NewBackupManager(hosts.FS, 2)
+
+	first, err := m.CreateBackup(hosts.HostsPath, "first", "", nil, false)
+	require.NoError(t, err)
+	require.NoError(t, m.ValidateBackup(first.ID))
+
+	require.NoError(t, hosts.FS.WriteFile(hosts.HostsPath, []byte("10.0.0.1 changed.test\n")))
+	require.NoError(t, m.RestoreBackup(first.ID, ""))
+	lines, err := hosts.ReadHostsFile()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"127.0.0.1 localhost"}, lines)
+
+	// 篡改备份内容后校验失败，恢复返回结构化错误
+	require.NoError(t, hosts.FS.WriteFile(first.Path, []byte("tampered\n")))
+	assert.Error(t, m.ValidateBackup(first.ID))
+	err = m.RestoreBackup(first.ID, "")
+	appErr := errors.GetAppError(err)
+	require.NotNil(t, appErr)
+	assert.Equal(t, errors.ErrCodeBackupCorrupted, appErr.Code())
+	assert.Equal(t, 1, m.GetBackupStats().InvalidBackups)
+
+	_, err = m.CreateBackup(hosts.HostsPath, "second", "", nil, true)
+	require.NoError(t, err)
+	third, err := m.CreateBackup(hosts.HostsPath, "third", "", nil, false)
+	require.NoError(t, err)
+
+	backups := m.ListBackups()
+	require.Len(t, backups, 2)
+	assert.Equal(t, third.ID, backups[0].ID)
+	assert.False(t, hosts.FS.Exists(first.Path), "oldest backup is removed")
+}
+
+// TestXPCClient 测试Helper不可用、指定失败响应和访问控制
+func TestXPCClient(t *testing.T) {
+	ctx := context.Background()
+	hosts := NewHostManager(nil, "127.0.0.1 localhost")
+	client := NewXPCClient(hosts)
+
+	_, err := client.SendRequest(ctx, "get_status", nil)
+	assert.ErrorIs(t, err, helper.ErrNotConnected)
+
+	client.SetAvailable(false)
+	assert.Error(t, client.Connect())
+	client.SetAvailable(true)
+	require.NoError(t, client.Connect())
+
+	backupPath, err := client.BackupHosts(ctx)
+	require.NoError(t, err)
+	assert.True(t, hosts.FS.Exists(backupPath))
+
+	content, err := client.ReadHosts(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"127.0.0.1 localhost"}, content.Lines)
+	assert.False(t, content.HasManagedSection)
+
+	client.Respond("write_hosts", &helper.XPCResponse{
+		Error:     "permission denied",
+		ErrorCode: errors.ErrCodePermissionDenied,
+		ErrorType: errors.ErrorTypePermission,
+	})
+	err = client.WriteHosts(ctx, nil)
+	appErr := errors.GetAppError(err)
+	require.NotNil(t, appErr)
+	assert.Equal(t, errors.ErrCodePermissionDenied, appErr.Code())
+	assert.Equal(t, 0, hosts.Writes())
+
+	access, err := client.UpdateClientAccess(ctx, helper.ActionBlacklistAdd, "client-1")
+	require.NoError(t, err)
+	require.Len(t, access.Blacklist, 1)
+	access, err = client.UpdateClientAccess(ctx, helper.ActionClearBlacklist, "")
+	require.NoError(t, err)
+	assert.Empty(t, access.Blacklist)
+
+	assert.Equal(t, []string{"backup_hosts", "read_hosts", "write_hosts", "update_client_access", "update_client_access"}, client.Operations())
+}
@@ -0,0 +1,128 @@
+// Package fakes 提供各管理器接口的内存实现，测试和UI可以在不读写文件系统的情况下运行。
+//
+// hosts、备份和Profile导入导出的文件内容都保存在共享的FS中，同一个FS可以同时传给多个fake，
+// 例如BackupManager备份的正是HostManager写入的hosts内容。
+package fakes
+
+import (
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/flyhigher139/mhost/pkg/hostsfile"
+)
+
+// FS 内存文件系统，按路径保存文件内容
+type FS struct {
+	mu    sync.RWMutex
+	files map[string][]byte
+}
+
+// NewFS 创建空的内存文件系统
+func NewFS() *FS {
+	return &FS{files: make(map[string][]byte)}
+}
+
+// ReadFile 读取文件内容，文件不存在时返回的错误满足os.IsNotExist
+func (fs *FS) ReadFile(path string) ([]byte, error) {
+	fs.mu.RLock()
+	defer fs.mu.RUnlock()
+
+	data, ok := fs.files[path]
+	if !ok {
+		return nil, &os.PathError{Op: "open", Path: path, Err: os.ErrNotExist}
+	}
+	return append([]byte(nil), data...), nil
+}
+
+// WriteFile 写入文件内容，已存在时覆盖
+func (fs *FS) WriteFile(path string, data []byte) error {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	fs.files[path] = append([]byte(nil), data...)
+	return nil
+}
+
+// Remove 删除文件，文件不存在时返回的错误满足os.IsNotExist
+func (fs *FS) Remove(path string) error {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	if _, ok := fs.files[path]; !ok {
+		return &os.PathError{Op: "remove", Path: path, Err: os.ErrNotExist}
+	}
+	delete(fs.files, path)
+	return nil
+}
+
+// Exists 文件是否存在
+func (fs *FS) Exists(path string) bool {
+	fs.mu.RLock()
+	defer fs.mu.RUnlock()
+
+	_, ok := fs.files[path]
+	return ok
+}
+
+// Paths 按字典序列出所有文件路径
+func (fs *FS) Paths() []string {
+	fs.mu.RLock()
+	defer fs.mu.RUnlock()
+
+	paths := make([]string, 0, len(fs.files))
+	for path := range fs.files {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// readLines 按hosts文件的规则读取文件的各行
+func (fs *FS) readLines(path string) ([]string, error) {
+	data, err := fs.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return hostsfile.SplitLines(string(data)), nil
+}
+
+// writeLines 以换行结尾写入各行，返回写入的字节数
+func (fs *FS) writeLines(path string, lines []string) int64 {
+	var content strings.Builder
+	for _, line := range lines {
+		content.WriteString(line + "\n")
+	}
+	fs.WriteFile(path, []byte(content.String()))
+	return int64(content.Len())
+}
+
+// failures 按方法名注入的错误，供各fake复用
+type failures struct {
+	mu   sync.RWMutex
+	errs map[string]error
+}
+
+// Fail 让名为method的方法之后的调用返回err，err为nil时恢复正常
+func (f *failures) Fail(method string, err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.errs == nil {
+		f.errs = make(map[string]error)
+	}
+	if err == nil {
+		delete(f.errs, method)
+		return
+	}
+	f.errs[method] = err
+}
+
+// failure 获取为方法注入的错误
+func (f *failures) failure(method string) error {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	return f.errs[method]
+}
@@ -0,0 +1,477 @@
+package fakes
+
+import (
+	"fmt"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/flyhigher139/mhost/internal/hooks"
+	"github.com/flyhigher139/mhost/internal/host"
+	"github.com/flyhigher139/mhost/internal/rules"
+	"github.com/flyhigher139/mhost/pkg/hostsfile"
+	"github.com/flyhigher139/mhost/pkg/models"
+)
+
+// DefaultHostsPath HostManager默认使用的hosts文件路径，仅作为FS中的键
+const DefaultHostsPath = "/etc/hosts"
+
+// DefaultBackupDir HostManager默认使用的备份目录，仅作为FS中的键
+const DefaultBackupDir = "/backups"
+
+// HostManager host.Manager的内存实现，hosts文件和备份保存在FS中；
+// 通过Fail按方法名注入错误，ApplyProfileWithOptions与ApplyProfile共用"ApplyProfile"
+type HostManager struct {
+	failures
+
+	FS        *FS
+	HostsPath string
+	BackupDir string
+
+	mu            sync.Mutex
+	elevator      host.Elevator
+	backupOnApply bool
+	limits        models.LimitsConfig
+	rules         *rules.Set
+	hooks         *hooks.Runner
+	globalSource  host.GlobalSource
+	writes        int
+	backupSeq     int
+}
+
+var _ host.Manager = (*HostManager)(nil)
+
+// NewHostManager 创建内存hosts管理器，fs为nil时创建新的FS；hosts文件的初始内容为lines
+func NewHostManager(fs *FS, lines ...string) *HostManager {
+	if fs == nil {
+		fs = NewFS()
+	}
+	m := &HostManager{
+		FS:        fs,
+		HostsPath: DefaultHostsPath,
+		BackupDir: DefaultBackupDir,
+		limits:    models.DefaultAppConfig().Limits,
+	}
+	fs.writeLines(m.HostsPath, lines)
+	return m
+}
+
+// Writes 获取写入hosts文件的次数
+func (m *HostManager) Writes() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.writes
+}
+
+// ReadHostsFile 读取hosts文件内容
+func (m *HostManager) ReadHostsFile() ([]string, error) {
+	if err := m.failure("ReadHostsFile"); err != nil {
+		return nil, err
+	}
+	lines, err := m.FS.readLines(m.HostsPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read hosts file: %w", err)
+	}
+	return lines, nil
+}
+
+// WriteHostsFile 写入hosts文件内容
+func (m *HostManager) WriteHostsFile(lines []string) (*host.WriteResult, error) {
+	if err := m.failure("WriteHostsFile"); err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	m.writes++
+	m.mu.Unlock()
+
+	return &host.WriteResult{
+		HostsPath:    m.HostsPath,
+		LinesWritten: len(lines),
+		BytesWritten: m.FS.writeLines(m.HostsPath, lines),
+	}, nil
+}
+
+// ApplyProfile 应用Profile到hosts文件，返回本次改动的条目
+func (m *HostManager) ApplyProfile(profile *models.Profile) (*host.ApplyResult, error) {
+	return m.ApplyProfileWithOptions(profile, host.ApplyOptions{})
+}
+
+// ApplyProfileWithOptions 按选项应用Profile，超过规模限制时返回*host.LimitError
+func (m *HostManager) ApplyProfileWithOptions(profile *models.Profile, options host.ApplyOptions) (*host.ApplyResult, error) {
+	if err := m.failure("ApplyProfile"); err != nil {
+		return nil, err
+	}
+	if profile == nil {
+		return nil, models.ErrInvalidProfile
+	}
+
+	appliedAt := time.Now()
+	lines, newLines, entries, err := m.render(profile, appliedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	limits, ruleSet, backupOnApply := m.limits, m.rules, m.backupOnApply
+	m.mu.Unlock()
+
+	violations := host.CheckLimits(newLines, limits)
+	if len(violations) > 0 && !options.IgnoreLimits {
+		return nil, &host.LimitError{Violations: violations}
+	}
+	ruleWarnings, err := ruleSet.Enforce(entries)
+	if err != nil {
+		return nil, err
+	}
+
+	var backupPath string
+	if backupOnApply {
+		backup, err := m.backupHostsFile(true, host.BackupOptions{})
+		if err != nil {
+			return nil, err
+		}
+		backupPath = backup.FilePath
+	}
+
+	written, err := m.WriteHostsFile(newLines)
+	if err != nil {
+		return nil, err
+	}
+
+	result := host.NewApplyResult(hostsfile.Parse(hostsfile.ExtractManagedSection(lines)), entries)
+	result.ProfileID = profile.ID
+	result.ProfileName = profile.Name
+	result.HostsPath = m.HostsPath
+	result.BackupPath = backupPath
+	result.AppliedAt = appliedAt
+	result.LinesWritten = written.LinesWritten
+	for _, issue := range hostsfile.Check(newLines).Warnings() {
+		result.Warnings = append(result.Warnings, issue.String())
+	}
+	for _, violation := range violations {
+		result.Warnings = append(result.Warnings, "limit overridden: "+violation)
+	}
+	for _, violation := range ruleWarnings {
+		result.Warnings = append(result.Warnings, "rule: "+violation.String())
+	}
+	return result, nil
+}
+
+// render 返回当前内容、应用Profile后的内容以及写入管理section的条目
+func (m *HostManager) render(profile *models.Profile, appliedAt time.Time) ([]string, []string, []hostsfile.Entry, error) {
+	lines, err := m.ReadHostsFile()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	entries, err := m.managedEntries(profile)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return lines, hostsfile.ReplaceManagedSection(lines, host.NewSectionHeader(profile, appliedAt).Build(entries)), entries, nil
+}
+
+// PreviewApply 预览应用Profile后的hosts文件内容，不写入
+func (m *HostManager) PreviewApply(profile *models.Profile) (*host.Preview, error) {
+	if err := m.failure("PreviewApply"); err != nil {
+		return nil, err
+	}
+	if profile == nil {
+		return nil, models.ErrInvalidProfile
+	}
+
+	lines, newLines, entries, err := m.render(profile, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	limits, ruleSet := m.limits, m.rules
+	m.mu.Unlock()
+	preview := host.NewPreview(profile, m.HostsPath, lines, newLines, entries, limits)
+	preview.RuleViolations = ruleSet.CheckEntries(entries)
+	return preview, nil
+}
+
+// ApplyProfiles 按优先级从低到高叠加多个Profile后应用
+func (m *HostManager) ApplyProfiles(profiles []*models.Profile, options host.ApplyOptions) (*host.ApplyResult, error) {
+	return host.ApplyLayered(profiles, options, m.ApplyProfileWithOptions)
+}
+
+// BackupHostsFile 备份当前hosts文件
+func (m *HostManager) BackupHostsFile() (*models.Backup, error) {
+	if err := m.failure("BackupHostsFile"); err != nil {
+		return nil, err
+	}
+	return m.backupHostsFile(false, host.BackupOptions{})
+}
+
+// BackupHostsFileWithOptions 备份当前hosts文件，里程碑名称和标签只记录在返回的备份元数据中
+func (m *HostManager) BackupHostsFileWithOptions(options host.BackupOptions) (*models.Backup, error) {
+	if err := m.failure("BackupHostsFileWithOptions"); err != nil {
+		return nil, err
+	}
+	return m.backupHostsFile(false, options)
+}
+
+// backupHostsFile 将hosts文件复制到备份目录，文件名按序号递增以免同一秒内的备份互相覆盖
+func (m *HostManager) backupHostsFile(automatic bool, options host.BackupOptions) (*models.Backup, error) {
+	data, err := m.FS.ReadFile(m.HostsPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open hosts file: %w", err)
+	}
+
+	m.mu.Lock()
+	m.backupSeq++
+	seq := m.backupSeq
+	m.mu.Unlock()
+
+	prefix, backupType := host.BackupFilePrefix, models.BackupTypeManual
+	if automatic {
+		prefix, backupType = host.AutoBackupFilePrefix, models.BackupTypeAutomatic
+	}
+	backupPath := path.Join(m.BackupDir, fmt.Sprintf("%s%06d.txt", prefix, seq))
+	m.FS.WriteFile(backupPath, data)
+
+	metadata := models.BackupMetadata{
+		Version: "1.0",
+		Tags:    append([]string{string(backupType), "hosts"}, host.NormalizeTags(options.Tags)...),
+	}
+	if milestone := strings.TrimSpace(options.Milestone); milestone != "" {
+		metadata.Description = milestone
+		metadata.Tags = append(metadata.Tags, host.MilestoneTag)
+	}
+
+	return &models.Backup{
+		ID:           fmt.Sprintf("backup_%d", seq),
+		Type:         backupType,
+		FilePath:     backupPath,
+		OriginalPath: m.HostsPath,
+		Size:         int64(len(data)),
+		CreatedAt:    time.Now(),
+		Metadata:     metadata,
+	}, nil
+}
+
+// RestoreFromBackup 从备份恢复hosts文件
+func (m *HostManager) RestoreFromBackup(backup *models.Backup) error {
+	if err := m.failure("RestoreFromBackup"); err != nil {
+		return err
+	}
+	if backup == nil {
+		return models.ErrInvalidBackup
+	}
+
+	data, err := m.FS.ReadFile(backup.FilePath)
+	if err != nil {
+		return models.ErrBackupNotFound
+	}
+	return m.FS.WriteFile(m.HostsPath, data)
+}
+
+// GetHostsFilePath 获取hosts文件路径
+func (m *HostManager) GetHostsFilePath() string {
+	return m.HostsPath
+}
+
+// ValidateHostsFile 验证hosts文件，返回包含全部错误与警告的报告
+func (m *HostManager) ValidateHostsFile() (*host.ValidationResult, error) {
+	lines, err := m.ReadHostsFile()
+	if err != nil {
+		return nil, err
+	}
+
+	return &host.ValidationResult{
+		HostsPath: m.HostsPath,
+		Lines:     len(lines),
+		Entries:   len(hostsfile.Parse(lines)),
+		Issues:    hostsfile.Check(lines).Issues,
+	}, nil
+}
+
+// ParseHostsFile 解析hosts文件为HostEntry列表
+func (m *HostManager) ParseHostsFile() ([]*models.HostEntry, error) {
+	lines, err := m.ReadHostsFile()
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []*models.HostEntry
+	for i, parsed := range hostsfile.Parse(lines) {
+		entry := parsed.ToModel()
+		entry.ID = fmt.Sprintf("%s_%s_%d", parsed.IP, parsed.Hostname, i)
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// GetManagedSection 获取mHost管理的section
+func (m *HostManager) GetManagedSection() ([]string, error) {
+	lines, err := m.ReadHostsFile()
+	if err != nil {
+		return nil, err
+	}
+	return hostsfile.ExtractManagedSection(lines), nil
+}
+
+// UpdateManagedSection 更新mHost管理的section
+func (m *HostManager) UpdateManagedSection(entries []*models.HostEntry) error {
+	lines, err := m.ReadHostsFile()
+	if err != nil {
+		return err
+	}
+
+	header := []string{fmt.Sprintf("# Updated at: %s", time.Now().Format(time.RFC3339))}
+	section := hostsfile.BuildSignedSection(header, hostsfile.FromModels(entries))
+	_, err = m.WriteHostsFile(hostsfile.ReplaceManagedSection(lines, section))
+	return err
+}
+
+// DetectDrift 检测管理section与Profile之间的差异
+func (m *HostManager) DetectDrift(profile *models.Profile) (*hostsfile.Drift, error) {
+	if err := m.failure("DetectDrift"); err != nil {
+		return nil, err
+	}
+	if profile == nil {
+		return nil, models.ErrInvalidProfile
+	}
+
+	entries, err := m.managedEntries(profile)
+	if err != nil {
+		return nil, err
+	}
+
+	managed, err := m.GetManagedSection()
+	if err != nil {
+		return nil, err
+	}
+	return hostsfile.DetectSectionDrift(entries, managed), nil
+}
+
+// managedEntries 合并全局条目并运行转换Hook，得到写入管理section的条目
+func (m *HostManager) managedEntries(profile *models.Profile) ([]hostsfile.Entry, error) {
+	merged, err := m.withGlobal(profile)
+	if err != nil {
+		return nil, err
+	}
+	return m.Hooks().Transform(profile, hostsfile.FromModels(merged.ResolvedEntries()))
+}
+
+// withGlobal 合并全局条目，没有设置全局条目来源时返回profile本身
+func (m *HostManager) withGlobal(profile *models.Profile) (*models.Profile, error) {
+	m.mu.Lock()
+	source := m.globalSource
+	m.mu.Unlock()
+
+	if source == nil {
+		return profile, nil
+	}
+	global, err := source()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load global entries: %w", err)
+	}
+	return profile.WithGlobal(global), nil
+}
+
+// PatchManagedEntry 在管理section中就地更新单个条目
+func (m *HostManager) PatchManagedEntry(entry *models.HostEntry) error {
+	if entry == nil {
+		return models.ErrHostEntryNotFound
+	}
+	if m.Hooks().Len() > 0 {
+		return host.ErrPatchUnavailable
+	}
+	if _, err := m.Rules().Enforce([]hostsfile.Entry{hostsfile.FromModel(entry)}); err != nil {
+		return err
+	}
+
+	lines, err := m.ReadHostsFile()
+	if err != nil {
+		return err
+	}
+
+	newLines, err := hostsfile.PatchEntry(lines, hostsfile.FromModel(entry))
+	if err != nil {
+		return err
+	}
+	_, err = m.WriteHostsFile(newLines)
+	return err
+}
+
+// SetElevator 设置提权方式；内存实现不会用到，仅供GetElevator返回
+func (m *HostManager) SetElevator(elevator host.Elevator) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.elevator = elevator
+}
+
+// GetElevator 获取当前的提权方式
+func (m *HostManager) GetElevator() host.Elevator {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.elevator
+}
+
+// SetBackupOnApply 设置应用Profile前是否自动备份hosts文件
+func (m *HostManager) SetBackupOnApply(enabled bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.backupOnApply = enabled
+}
+
+// BackupOnApply 应用Profile前是否自动备份hosts文件
+func (m *HostManager) BackupOnApply() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.backupOnApply
+}
+
+// SetDeltaBackups 设置增量备份；内存实现总是保存完整备份，忽略该设置
+func (m *HostManager) SetDeltaBackups(minLines, fullEvery int) {}
+
+// SetBackupRetention 设置备份的保留条件；内存文件系统不会空间不足，忽略该设置
+func (m *HostManager) SetBackupRetention(maxBackups int, maxAge time.Duration) {}
+
+// SetLimits 设置应用Profile时检查的规模限制
+func (m *HostManager) SetLimits(limits models.LimitsConfig) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.limits = limits
+}
+
+// SetRules 设置应用Profile时检查的自定义校验规则
+func (m *HostManager) SetRules(set *rules.Set) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.rules = set
+}
+
+// Rules 获取当前的自定义校验规则
+func (m *HostManager) Rules() *rules.Set {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.rules
+}
+
+// SetHooks 设置应用Profile时运行的转换Hook
+func (m *HostManager) SetHooks(runner *hooks.Runner) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.hooks = runner
+}
+
+// Hooks 获取当前的转换Hook
+func (m *HostManager) Hooks() *hooks.Runner {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.hooks
+}
+
+// SetGlobalSource 设置全局条目的来源
+func (m *HostManager) SetGlobalSource(source host.GlobalSource) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.globalSource = source
+}
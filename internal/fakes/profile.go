@@ -0,0 +1,699 @@
+package fakes
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/flyhigher139/mhost/internal/exporter"
+	"github.com/flyhigher139/mhost/internal/profile"
+	"github.com/flyhigher139/mhost/pkg/hostsfile"
+	"github.com/flyhigher139/mhost/pkg/models"
+)
+
+// ProfileManager profile.Manager的内存实现，导入导出的文件保存在FS中；
+// 与真实实现一样，第一个创建的Profile自动激活，不能删除激活的Profile，只读Profile只能通过资产清单同步修改和删除。
+// 通过Fail按方法名注入错误，ImportParsedProfile与ImportProfile共用"ImportProfile"
+type ProfileManager struct {
+	failures
+
+	FS *FS
+
+	mu       sync.RWMutex
+	profiles map[string]*models.Profile
+	global   *models.Profile
+	groups   map[string]*models.ProfileGroup
+	nextID   int
+
+	// activeIDs 激活的Profile，按优先级从低到高排列
+	activeIDs []string
+}
+
+var _ profile.Manager = (*ProfileManager)(nil)
+
+// NewProfileManager 创建内存Profile管理器，fs为nil时创建新的FS
+func NewProfileManager(fs *FS) *ProfileManager {
+	if fs == nil {
+		fs = NewFS()
+	}
+	return &ProfileManager{
+		FS:       fs,
+		profiles: make(map[string]*models.Profile),
+		groups:   make(map[string]*models.ProfileGroup),
+	}
+}
+
+// Add 直接加入一个Profile（保留其ID和时间戳），用于准备测试数据；active为true时同时激活
+func (m *ProfileManager) Add(p *models.Profile, active bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	p = p.Clone()
+	p.IsActive = false
+	m.profiles[p.ID] = p
+	if active {
+		m.activate(p.ID)
+	}
+}
+
+// newID 生成递增的Profile ID，保证同一测试中的ID唯一且可预测
+func (m *ProfileManager) newID() string {
+	m.nextID++
+	return fmt.Sprintf("profile-%d", m.nextID)
+}
+
+// nameExists 名称是否已被其他Profile使用
+func (m *ProfileManager) nameExists(name, exceptID string) bool {
+	for id, p := range m.profiles {
+		if id != exceptID && p.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// activate 按顺序激活指定的Profile并取消之前激活的Profile（需要持有锁）
+func (m *ProfileManager) activate(ids ...string) {
+	for _, id := range m.activeIDs {
+		if current, ok := m.profiles[id]; ok {
+			current.IsActive = false
+		}
+	}
+	for _, id := range ids {
+		m.profiles[id].IsActive = true
+	}
+	m.activeIDs = append([]string(nil), ids...)
+}
+
+// CreateProfile 创建新的Profile
+func (m *ProfileManager) CreateProfile(name, description string) (*models.Profile, error) {
+	if err := m.failure("CreateProfile"); err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.nameExists(name, "") {
+		return nil, models.ErrProfileExists
+	}
+
+	p := models.NewProfile(name, description)
+	p.ID = m.newID()
+	m.profiles[p.ID] = p
+	if len(m.profiles) == 1 {
+		m.activate(p.ID)
+	}
+	return p.Clone(), nil
+}
+
+// ListProfiles 获取Profile列表，按更新时间排序
+func (m *ProfileManager) ListProfiles() ([]*models.ProfileSummary, error) {
+	if err := m.failure("ListProfiles"); err != nil {
+		return nil, err
+	}
+	return m.summaries(func(*models.Profile) bool { return true }), nil
+}
+
+// summaries 获取满足条件的Profile摘要，按更新时间排序
+func (m *ProfileManager) summaries(match func(*models.Profile) bool) []*models.ProfileSummary {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	summaries := make([]*models.ProfileSummary, 0, len(m.profiles))
+	for _, p := range m.profiles {
+		if match(p) {
+			summary := p.ToSummary()
+			summaries = append(summaries, &summary)
+		}
+	}
+	sort.Slice(summaries, func(i, j int) bool {
+		if summaries[i].UpdatedAt.Equal(summaries[j].UpdatedAt) {
+			return summaries[i].ID < summaries[j].ID
+		}
+		return summaries[i].UpdatedAt.After(summaries[j].UpdatedAt)
+	})
+	return summaries
+}
+
+// GetProfile 根据ID获取Profile的副本
+func (m *ProfileManager) GetProfile(id string) (*models.Profile, error) {
+	if err := m.failure("GetProfile"); err != nil {
+		return nil, err
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	p, ok := m.profiles[id]
+	if !ok {
+		return nil, models.ErrProfileNotFound
+	}
+	return p.Clone(), nil
+}
+
+// UpdateProfile 更新Profile
+func (m *ProfileManager) UpdateProfile(p *models.Profile) error {
+	if err := m.failure("UpdateProfile"); err != nil {
+		return err
+	}
+	return m.updateProfile(p, false)
+}
+
+// SyncInventoryProfile 保存资产清单同步的结果，可以设置资产清单和修改只读Profile的条目
+func (m *ProfileManager) SyncInventoryProfile(p *models.Profile) error {
+	if err := m.failure("SyncInventoryProfile"); err != nil {
+		return err
+	}
+	return m.updateProfile(p, true)
+}
+
+// updateProfile 更新Profile，syncing为false时拒绝修改资产清单和只读Profile的条目
+func (m *ProfileManager) updateProfile(p *models.Profile, syncing bool) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	existing, ok := m.profiles[p.ID]
+	if !ok {
+		return models.ErrProfileNotFound
+	}
+	if err := p.Validate(); err != nil {
+		return err
+	}
+	if !syncing && (!models.EqualInventory(existing.Inventory, p.Inventory) ||
+		existing.ReadOnly() && !models.EqualEntries(existing.Entries, p.Entries)) {
+		return models.ErrReadOnlyProfile
+	}
+	if m.nameExists(p.Name, p.ID) {
+		return models.ErrProfileExists
+	}
+
+	p.UpdateTimestamp()
+	stored := p.Clone()
+	stored.IsActive = slices.Contains(m.activeIDs, p.ID)
+	m.profiles[p.ID] = stored
+	return nil
+}
+
+// DeleteProfile 删除Profile，不能删除激活的Profile
+func (m *ProfileManager) DeleteProfile(id string) error {
+	if err := m.failure("DeleteProfile"); err != nil {
+		return err
+	}
+	return m.deleteProfile(id, false)
+}
+
+// DeleteInventoryProfile 删除同步资产清单的只读Profile
+func (m *ProfileManager) DeleteInventoryProfile(id string) error {
+	if err := m.failure("DeleteInventoryProfile"); err != nil {
+		return err
+	}
+	return m.deleteProfile(id, true)
+}
+
+// deleteProfile 删除Profile，syncing为false时拒绝删除只读Profile
+func (m *ProfileManager) deleteProfile(id string, syncing bool) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	p, ok := m.profiles[id]
+	if !ok {
+		return models.ErrProfileNotFound
+	}
+	if !syncing && p.ReadOnly() {
+		return models.ErrReadOnlyProfile
+	}
+	if p.IsActive {
+		return models.ErrActiveProfile
+	}
+	delete(m.profiles, id)
+	return nil
+}
+
+// ActivateProfile 激活Profile
+func (m *ProfileManager) ActivateProfile(id string) error {
+	if err := m.failure("ActivateProfile"); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.profiles[id]; !ok {
+		return models.ErrProfileNotFound
+	}
+	m.activate(id)
+	return nil
+}
+
+// GetActiveProfile 获取当前激活的Profile的副本
+func (m *ProfileManager) GetActiveProfile() (*models.Profile, error) {
+	if err := m.failure("GetActiveProfile"); err != nil {
+		return nil, err
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if len(m.activeIDs) == 0 {
+		return nil, models.ErrProfileNotFound
+	}
+	p, ok := m.profiles[m.activeIDs[0]]
+	if !ok {
+		return nil, models.ErrProfileNotFound
+	}
+	return p.Clone(), nil
+}
+
+// ActivateProfiles 按优先级从低到高同时激活多个Profile
+func (m *ProfileManager) ActivateProfiles(ids []string) error {
+	if err := m.failure("ActivateProfile"); err != nil {
+		return err
+	}
+	if len(ids) == 0 {
+		return models.ErrInvalidProfile
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	seen := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		if _, ok := m.profiles[id]; !ok {
+			return models.ErrProfileNotFound
+		}
+		if seen[id] {
+			return models.ErrInvalidProfile
+		}
+		seen[id] = true
+	}
+	m.activate(ids...)
+	return nil
+}
+
+// GetActiveProfiles 获取当前激活的全部Profile的副本，按优先级从低到高排列
+func (m *ProfileManager) GetActiveProfiles() ([]*models.Profile, error) {
+	if err := m.failure("GetActiveProfile"); err != nil {
+		return nil, err
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var profiles []*models.Profile
+	for _, id := range m.activeIDs {
+		if p, ok := m.profiles[id]; ok {
+			profiles = append(profiles, p.Clone())
+		}
+	}
+	if len(profiles) == 0 {
+		return nil, models.ErrProfileNotFound
+	}
+	return profiles, nil
+}
+
+// ImportProfile 从FS中的JSON文件导入Profile
+func (m *ProfileManager) ImportProfile(filePath string) (*models.Profile, error) {
+	data, err := m.FS.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+
+	var p models.Profile
+	if err := json.Unmarshal(data, &p); err != nil {
+		return nil, fmt.Errorf("failed to parse profile: %w", err)
+	}
+	return m.ImportParsedProfile(&p)
+}
+
+// ImportParsedProfile 导入已解析的Profile，重新生成ID，名称冲突时添加序号后缀
+func (m *ProfileManager) ImportParsedProfile(imported *models.Profile) (*models.Profile, error) {
+	if err := m.failure("ImportProfile"); err != nil {
+		return nil, err
+	}
+	if imported == nil {
+		return nil, models.ErrInvalidProfile
+	}
+
+	p := imported.Clone()
+	if err := p.Validate(); err != nil {
+		return nil, err
+	}
+	hostsfile.NormalizeEntries(p.Entries)
+	for i, entry := range p.Entries {
+		if err := hostsfile.ValidateEntry(hostsfile.FromModel(entry)); err != nil {
+			return nil, fmt.Errorf("invalid entry %d: %w", i+1, err)
+		}
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	p.ID = m.newID()
+	now := time.Now()
+	p.CreatedAt = now
+	p.UpdatedAt = now
+	p.IsActive = false
+	for counter, name := 1, p.Name; m.nameExists(p.Name, ""); counter++ {
+		p.Name = fmt.Sprintf("%s (%d)", name, counter)
+	}
+
+	m.profiles[p.ID] = p
+	return p.Clone(), nil
+}
+
+// ImportFromHostsFile 将FS中的hosts格式文件导入为新的Profile
+func (m *ProfileManager) ImportFromHostsFile(filePath, name string) (*models.Profile, error) {
+	data, err := m.FS.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+
+	p, err := profile.ProfileFromHosts(data, filePath, name)
+	if err != nil {
+		return nil, err
+	}
+	p.RemoveDuplicateHostnames()
+	return m.ImportParsedProfile(p)
+}
+
+// ExportProfile 将Profile以JSON导出到FS
+func (m *ProfileManager) ExportProfile(id, filePath string) error {
+	if err := m.failure("ExportProfile"); err != nil {
+		return err
+	}
+
+	m.mu.RLock()
+	p, ok := m.profiles[id]
+	var data []byte
+	var err error
+	if ok {
+		data, err = json.MarshalIndent(p, "", "  ")
+	}
+	m.mu.RUnlock()
+
+	if !ok {
+		return models.ErrProfileNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("failed to marshal profile: %w", err)
+	}
+	return m.FS.WriteFile(filePath, data)
+}
+
+// ExportProfileAsHosts 以hosts格式将Profile中启用的条目导出到FS
+func (m *ProfileManager) ExportProfileAsHosts(id, filePath string) error {
+	if err := m.failure("ExportProfile"); err != nil {
+		return err
+	}
+
+	m.mu.RLock()
+	p, ok := m.profiles[id]
+	var data []byte
+	var err error
+	if ok {
+		data, err = (&exporter.HostsExporter{}).Export(p)
+	}
+	m.mu.RUnlock()
+
+	if !ok {
+		return models.ErrProfileNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("failed to render profile: %w", err)
+	}
+	return m.FS.WriteFile(filePath, data)
+}
+
+// CloneProfile 复制Profile
+func (m *ProfileManager) CloneProfile(id, newName string) (*models.Profile, error) {
+	if err := m.failure("CloneProfile"); err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	original, ok := m.profiles[id]
+	if !ok {
+		return nil, models.ErrProfileNotFound
+	}
+	if m.nameExists(newName, "") {
+		return nil, models.ErrProfileExists
+	}
+
+	cloned := original.Clone()
+	cloned.ID = m.newID()
+	cloned.Name = newName
+	now := time.Now()
+	cloned.CreatedAt = now
+	cloned.UpdatedAt = now
+	cloned.IsActive = false
+
+	m.profiles[cloned.ID] = cloned
+	return cloned.Clone(), nil
+}
+
+// SearchProfiles 按名称、描述和标签不区分大小写地搜索Profile，支持"tag:prod"形式的标签筛选
+func (m *ProfileManager) SearchProfiles(query string) ([]*models.ProfileSummary, error) {
+	if err := m.failure("SearchProfiles"); err != nil {
+		return nil, err
+	}
+	q := models.ParseProfileQuery(query)
+	if q.Empty() {
+		return nil, nil
+	}
+	return m.summaries(q.Matches), nil
+}
+
+// MergeEntries 合并条目到Profile，按主机名更新已有条目或追加新条目，返回变更的条目数
+func (m *ProfileManager) MergeEntries(id string, entries []*models.HostEntry) (int, error) {
+	if err := m.failure("MergeEntries"); err != nil {
+		return 0, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	p, ok := m.profiles[id]
+	if !ok {
+		return 0, models.ErrProfileNotFound
+	}
+	if p.ReadOnly() {
+		return 0, models.ErrReadOnlyProfile
+	}
+	for _, entry := range entries {
+		if entry == nil {
+			continue
+		}
+		if err := entry.Validate(); err != nil {
+			return 0, err
+		}
+	}
+
+	merged := 0
+	for _, entry := range entries {
+		if entry == nil {
+			continue
+		}
+
+		hostname := hostsfile.NormalizeHostname(entry.Hostname)
+		var existing *models.HostEntry
+		for _, current := range p.Entries {
+			if hostsfile.NormalizeHostname(current.Hostname) == hostname {
+				existing = current
+				break
+			}
+		}
+
+		if existing == nil {
+			p.Entries = append(p.Entries, models.NewHostEntry(entry.IP, hostname, entry.Comment))
+			merged++
+			continue
+		}
+		if existing.IP != entry.IP || existing.Comment != entry.Comment || !existing.Enabled {
+			existing.IP = entry.IP
+			existing.Comment = entry.Comment
+			existing.Enabled = true
+			existing.UpdatedAt = time.Now()
+			merged++
+		}
+	}
+
+	if merged > 0 {
+		p.UpdateTimestamp()
+	}
+	return merged, nil
+}
+
+// Reload 内存实现没有需要重新加载的数据
+func (m *ProfileManager) Reload() error {
+	return m.failure("Reload")
+}
+
+// NormalizeHostnames 规范化所有Profile中已有条目的主机名，返回修改的条目数
+func (m *ProfileManager) NormalizeHostnames() (int, error) {
+	if err := m.failure("NormalizeHostnames"); err != nil {
+		return 0, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	changed := 0
+	for _, p := range m.profiles {
+		if n := hostsfile.NormalizeEntries(p.Entries); n > 0 {
+			p.UpdateTimestamp()
+			changed += n
+		}
+	}
+	return changed, nil
+}
+
+// GetGlobalProfile 获取全局条目所在的隐式Profile的副本，尚未添加全局条目时返回空的Profile
+func (m *ProfileManager) GetGlobalProfile() (*models.Profile, error) {
+	if err := m.failure("GetGlobalProfile"); err != nil {
+		return nil, err
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if m.global == nil {
+		return models.NewGlobalProfile(), nil
+	}
+	return m.global.Clone(), nil
+}
+
+// UpdateGlobalProfile 保存全局条目
+func (m *ProfileManager) UpdateGlobalProfile(p *models.Profile) error {
+	if err := m.failure("UpdateGlobalProfile"); err != nil {
+		return err
+	}
+	if p == nil || p.ID != models.GlobalProfileID {
+		return models.ErrInvalidProfile
+	}
+	if err := p.Validate(); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	p.UpdateTimestamp()
+	m.global = p.Clone()
+	return nil
+}
+
+// ListGroups 获取所有Profile文件夹
+func (m *ProfileManager) ListGroups() ([]*models.ProfileGroup, error) {
+	if err := m.failure("ListGroups"); err != nil {
+		return nil, err
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return profile.CollectGroups(m.groups, m.profiles), nil
+}
+
+// CreateGroup 创建空的Profile文件夹
+func (m *ProfileManager) CreateGroup(path string) (*models.ProfileGroup, error) {
+	if err := m.failure("CreateGroup"); err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return profile.AddGroup(m.groups, m.profiles, path)
+}
+
+// RenameGroup 重命名或移动Profile文件夹
+func (m *ProfileManager) RenameGroup(path, newPath string) error {
+	if err := m.failure("RenameGroup"); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return profile.MoveGroup(m.groups, m.profiles, path, newPath)
+}
+
+// DeleteGroup 删除Profile文件夹，其中的Profile和下级文件夹移到上级文件夹
+func (m *ProfileManager) DeleteGroup(path string) error {
+	if err := m.failure("DeleteGroup"); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return profile.RemoveGroup(m.groups, m.profiles, path)
+}
+
+// ListTags 获取所有Profile使用的标签及其Profile数量
+func (m *ProfileManager) ListTags() ([]*models.TagSummary, error) {
+	if err := m.failure("ListTags"); err != nil {
+		return nil, err
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return profile.CollectTags(m.profiles), nil
+}
+
+// AddTag 为Profile添加标签
+func (m *ProfileManager) AddTag(id, tag string) error {
+	if err := m.failure("AddTag"); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	p, ok := m.profiles[id]
+	if !ok {
+		return models.ErrProfileNotFound
+	}
+	_, err := profile.TagProfile(p, tag)
+	return err
+}
+
+// RemoveTag 移除Profile的标签
+func (m *ProfileManager) RemoveTag(id, tag string) error {
+	if err := m.failure("RemoveTag"); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	p, ok := m.profiles[id]
+	if !ok {
+		return models.ErrProfileNotFound
+	}
+	return profile.UntagProfile(p, tag)
+}
+
+// RenameTag 在所有Profile中重命名标签
+func (m *ProfileManager) RenameTag(tag, newTag string) (int, error) {
+	if err := m.failure("RenameTag"); err != nil {
+		return 0, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return profile.RenameTags(m.profiles, tag, newTag)
+}
+
+// DeleteTag 从所有Profile中移除标签
+func (m *ProfileManager) DeleteTag(tag string) (int, error) {
+	if err := m.failure("DeleteTag"); err != nil {
+		return 0, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return profile.DeleteTags(m.profiles, tag)
+}
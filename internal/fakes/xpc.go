@@ -0,0 +1,374 @@
+package fakes
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/flyhigher139/mhost/internal/helper"
+	"github.com/flyhigher139/mhost/pkg/hostsfile"
+)
+
+// SentRequest XPCClient收到的一次请求
+type SentRequest struct {
+	Operation string
+	Params    map[string]interface{}
+}
+
+// XPCClient helper.Client的内存实现，模拟已安装的Helper。
+// SetAvailable(false)模拟Helper不可用；Respond可为操作指定响应（例如结构化的失败）；
+// Hosts非nil时hosts相关操作作用于该HostManager，否则只返回成功
+type XPCClient struct {
+	Hosts *HostManager
+
+	mu                sync.Mutex
+	available         bool
+	connected         bool
+	sent              []SentRequest
+	responses         map[string]*helper.XPCResponse
+	access            helper.ClientAccess
+	timeout           time.Duration
+	operationTimeouts map[string]time.Duration
+}
+
+var _ helper.Client = (*XPCClient)(nil)
+
+// NewXPCClient 创建可用的内存Helper客户端，hosts为nil时不模拟hosts文件
+func NewXPCClient(hosts *HostManager) *XPCClient {
+	return &XPCClient{
+		Hosts:             hosts,
+		available:         true,
+		responses:         make(map[string]*helper.XPCResponse),
+		timeout:           30 * time.Second,
+		operationTimeouts: helper.DefaultOperationTimeouts(),
+	}
+}
+
+// SetAvailable 设置Helper是否可用，不可用时断开连接
+func (c *XPCClient) SetAvailable(available bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.available = available
+	if !available {
+		c.connected = false
+	}
+}
+
+// Respond 为操作指定之后返回的响应，resp为nil时恢复默认行为
+func (c *XPCClient) Respond(operation string, resp *helper.XPCResponse) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if resp == nil {
+		delete(c.responses, operation)
+		return
+	}
+	c.responses[operation] = resp
+}
+
+// Sent 获取已发送的请求
+func (c *XPCClient) Sent() []SentRequest {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]SentRequest(nil), c.sent...)
+}
+
+// Operations 按发送顺序获取已发送请求的操作名
+func (c *XPCClient) Operations() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	operations := make([]string, 0, len(c.sent))
+	for _, req := range c.sent {
+		operations = append(operations, req.Operation)
+	}
+	return operations
+}
+
+// Connect 连接到Helper，Helper不可用时返回错误
+func (c *XPCClient) Connect() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.available {
+		return fmt.Errorf("helper unavailable")
+	}
+	c.connected = true
+	return nil
+}
+
+// Disconnect 断开连接
+func (c *XPCClient) Disconnect() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.connected = false
+	return nil
+}
+
+// IsConnected 是否已连接
+func (c *XPCClient) IsConnected() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.connected
+}
+
+// SendRequest 记录请求并返回为该操作指定的响应，默认返回成功
+func (c *XPCClient) SendRequest(ctx context.Context, operation string, params map[string]interface{}) (*helper.XPCResponse, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.connected {
+		return nil, helper.ErrNotConnected
+	}
+	c.sent = append(c.sent, SentRequest{Operation: operation, Params: params})
+
+	if resp, ok := c.responses[operation]; ok {
+		copied := *resp
+		copied.Timestamp = time.Now()
+		return &copied, nil
+	}
+	return &helper.XPCResponse{Success: true, Timestamp: time.Now()}, nil
+}
+
+// call 发送请求，失败的响应转换为结构化错误
+func (c *XPCClient) call(ctx context.Context, operation string, params map[string]interface{}) error {
+	resp, err := c.SendRequest(ctx, operation, params)
+	if err != nil {
+		return err
+	}
+	if !resp.Success {
+		return fmt.Errorf("%s failed: %w", operation, resp.Err())
+	}
+	return nil
+}
+
+// WriteHosts 写入管理section的条目
+func (c *XPCClient) WriteHosts(ctx context.Context, entries []helper.HostEntry) error {
+	if err := c.call(ctx, "write_hosts", map[string]interface{}{"entries": entries}); err != nil {
+		return err
+	}
+	if c.Hosts == nil {
+		return nil
+	}
+
+	lines, err := c.Hosts.ReadHostsFile()
+	if err != nil {
+		return err
+	}
+	header := []string{fmt.Sprintf("# Updated by helper at: %s", time.Now().Format(time.RFC3339))}
+	section := hostsfile.BuildSignedSection(header, entries)
+	_, err = c.Hosts.WriteHostsFile(hostsfile.ReplaceManagedSection(lines, section))
+	return err
+}
+
+// BackupHosts 备份hosts文件，返回备份路径
+func (c *XPCClient) BackupHosts(ctx context.Context) (string, error) {
+	if err := c.call(ctx, "backup_hosts", nil); err != nil {
+		return "", err
+	}
+	if c.Hosts == nil {
+		return "", nil
+	}
+
+	backup, err := c.Hosts.BackupHostsFile()
+	if err != nil {
+		return "", err
+	}
+	return backup.FilePath, nil
+}
+
+// RestoreHosts 从备份恢复hosts文件
+func (c *XPCClient) RestoreHosts(ctx context.Context, backupPath string) error {
+	if err := c.call(ctx, "restore_hosts", map[string]interface{}{"backup_path": backupPath}); err != nil {
+		return err
+	}
+	if c.Hosts == nil {
+		return nil
+	}
+
+	data, err := c.Hosts.FS.ReadFile(backupPath)
+	if err != nil {
+		return err
+	}
+	return c.Hosts.FS.WriteFile(c.Hosts.HostsPath, data)
+}
+
+// ValidateHosts 验证hosts文件，存在错误时返回失败
+func (c *XPCClient) ValidateHosts(ctx context.Context) error {
+	if err := c.call(ctx, "validate_hosts", nil); err != nil {
+		return err
+	}
+	if c.Hosts == nil {
+		return nil
+	}
+
+	result, err := c.Hosts.ValidateHostsFile()
+	if err != nil {
+		return err
+	}
+	if errs := result.Errors(); len(errs) > 0 {
+		return fmt.Errorf("validate hosts failed: %s", errs[0])
+	}
+	return nil
+}
+
+// ReadHosts 读取hosts文件内容及管理section
+func (c *XPCClient) ReadHosts(ctx context.Context) (*helper.HostsContent, error) {
+	if err := c.call(ctx, "read_hosts", nil); err != nil {
+		return nil, err
+	}
+	if c.Hosts == nil {
+		return &helper.HostsContent{}, nil
+	}
+
+	lines, err := c.Hosts.ReadHostsFile()
+	if err != nil {
+		return nil, err
+	}
+	managed := hostsfile.ExtractManagedSection(lines)
+	return &helper.HostsContent{
+		HostsPath:         c.Hosts.HostsPath,
+		Lines:             lines,
+		HasManagedSection: hostsfile.HasManagedSection(lines),
+		ManagedSection:    managed,
+		ManagedEntries:    hostsfile.ParseWithDisabled(managed),
+	}, nil
+}
+
+// GetStatus 获取Helper状态
+func (c *XPCClient) GetStatus(ctx context.Context) (map[string]interface{}, error) {
+	resp, err := c.SendRequest(ctx, "get_status", nil)
+	if err != nil {
+		return nil, err
+	}
+	if !resp.Success {
+		return nil, fmt.Errorf("get status failed: %w", resp.Err())
+	}
+	if resp.Data == nil {
+		return map[string]interface{}{"running": true}, nil
+	}
+	return resp.Data, nil
+}
+
+// GetClientAccess 获取模拟的白名单、黑名单和速率限制状态
+func (c *XPCClient) GetClientAccess(ctx context.Context) (*helper.ClientAccess, error) {
+	if err := c.call(ctx, "get_client_access", nil); err != nil {
+		return nil, err
+	}
+	return c.clientAccess(), nil
+}
+
+// UpdateClientAccess 修改模拟的白名单或黑名单，返回修改后的状态
+func (c *XPCClient) UpdateClientAccess(ctx context.Context, action helper.ClientAccessAction, clientID string) (*helper.ClientAccess, error) {
+	params := map[string]interface{}{
+		"action":    string(action),
+		"client_id": clientID,
+	}
+	if err := c.call(ctx, "update_client_access", params); err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	switch action {
+	case helper.ActionWhitelistAdd:
+		c.access.Whitelist = append(removeString(c.access.Whitelist, clientID), clientID)
+	case helper.ActionWhitelistRemove:
+		c.access.Whitelist = removeString(c.access.Whitelist, clientID)
+	case helper.ActionBlacklistAdd:
+		c.access.Blacklist = append(removeBlocked(c.access.Blacklist, clientID), helper.BlockedClient{ClientID: clientID, Expiry: time.Now().Add(time.Hour)})
+	case helper.ActionBlacklistRemove:
+		c.access.Blacklist = removeBlocked(c.access.Blacklist, clientID)
+	case helper.ActionClearBlacklist:
+		c.access.Blacklist = nil
+	default:
+		c.mu.Unlock()
+		return nil, fmt.Errorf("unknown client access action: %s", action)
+	}
+	c.mu.Unlock()
+
+	return c.clientAccess(), nil
+}
+
+// SetRateLimited 设置模拟的速率限制状态
+func (c *XPCClient) SetRateLimited(clients []helper.RateLimitedClient) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.access.RateLimited = append([]helper.RateLimitedClient(nil), clients...)
+}
+
+// clientAccess 复制当前的访问控制状态
+func (c *XPCClient) clientAccess() *helper.ClientAccess {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return &helper.ClientAccess{
+		Whitelist:   append([]string{}, c.access.Whitelist...),
+		Blacklist:   append([]helper.BlockedClient{}, c.access.Blacklist...),
+		RateLimited: append([]helper.RateLimitedClient{}, c.access.RateLimited...),
+	}
+}
+
+// removeString 移除列表中的指定值
+func removeString(values []string, value string) []string {
+	kept := values[:0:0]
+	for _, v := range values {
+		if v != value {
+			kept = append(kept, v)
+		}
+	}
+	return kept
+}
+
+// removeBlocked 移除黑名单中的指定客户端
+func removeBlocked(blocked []helper.BlockedClient, clientID string) []helper.BlockedClient {
+	kept := blocked[:0:0]
+	for _, b := range blocked {
+		if b.ClientID != clientID {
+			kept = append(kept, b)
+		}
+	}
+	return kept
+}
+
+// SetOperationTimeout 设置单个操作的超时时间，timeout不大于0时恢复使用通用超时
+func (c *XPCClient) SetOperationTimeout(operation string, timeout time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if timeout <= 0 {
+		delete(c.operationTimeouts, operation)
+		return
+	}
+	c.operationTimeouts[operation] = timeout
+}
+
+// TimeoutFor 获取操作的超时时间
+func (c *XPCClient) TimeoutFor(operation string) time.Duration {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if timeout, ok := c.operationTimeouts[operation]; ok {
+		return timeout
+	}
+	return c.timeout
+}
+
+// SetTimeout 设置通用的请求超时时间
+func (c *XPCClient) SetTimeout(timeout time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.timeout = timeout
+}
+
+// GetTimeout 获取通用的请求超时时间
+func (c *XPCClient) GetTimeout() time.Duration {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.timeout
+}
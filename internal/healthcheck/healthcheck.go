@@ -0,0 +1,227 @@
+// Package healthcheck 在后台定期重新验证hosts文件：语法、重复主机名、与激活Profile的漂移以及备份完整性。
+package healthcheck
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/flyhigher139/mhost/internal/host"
+	"github.com/flyhigher139/mhost/internal/profile"
+	"github.com/flyhigher139/mhost/pkg/hostsfile"
+	"github.com/flyhigher139/mhost/pkg/models"
+)
+
+// BackupProblem 备份文件的完整性问题
+type BackupProblem struct {
+	Path   string `json:"path"`
+	Reason string `json:"reason"`
+}
+
+// Report 汇总的验证报告
+type Report struct {
+	GeneratedAt  time.Time         `json:"generated_at"`
+	HostsPath    string            `json:"hosts_path"`
+	Syntax       []hostsfile.Issue `json:"syntax"`     // hosts文件中的错误
+	Duplicates   []hostsfile.Issue `json:"duplicates"` // 指向多个IP的主机名
+	DriftProfile string            `json:"drift_profile,omitempty"`
+	Drift        *hostsfile.Drift  `json:"drift,omitempty"` // 管理section与激活Profile的差异
+	Backups      []BackupProblem   `json:"backups"`
+	Failures     []string          `json:"failures"` // 无法完成的检查
+}
+
+// DriftCount 漂移的条目数
+func (r *Report) DriftCount() int {
+	if r.Drift == nil {
+		return 0
+	}
+	return len(r.Drift.Added) + len(r.Drift.Changed) + len(r.Drift.Removed)
+}
+
+// Total 问题总数
+func (r *Report) Total() int {
+	return len(r.Syntax) + len(r.Duplicates) + r.DriftCount() + len(r.Backups) + len(r.Failures)
+}
+
+// Checker 定期验证服务
+type Checker struct {
+	hostManager    host.Manager
+	profileManager profile.Manager
+	backupDir      string
+	now            func() time.Time
+
+	mu         sync.RWMutex
+	lastReport *Report
+	stopChan   chan struct{}
+	nextRun    time.Time
+	onReport   func(*Report)
+}
+
+// NewChecker 创建验证服务，backupDir为空时跳过备份检查
+func NewChecker(hostManager host.Manager, profileManager profile.Manager, backupDir string) *Checker {
+	return &Checker{
+		hostManager:    hostManager,
+		profileManager: profileManager,
+		backupDir:      backupDir,
+		now:            time.Now,
+	}
+}
+
+// Check 运行所有检查并生成报告；单项检查失败记录在Failures中，不影响其他检查
+func (c *Checker) Check() *Report {
+	report := &Report{
+		GeneratedAt: c.now(),
+		HostsPath:   c.hostManager.GetHostsFilePath(),
+	}
+
+	c.checkHostsFile(report)
+	c.checkDrift(report)
+	c.checkBackups(report)
+
+	c.mu.Lock()
+	c.lastReport = report
+	onReport := c.onReport
+	c.mu.Unlock()
+
+	if onReport != nil {
+		onReport(report)
+	}
+	return report
+}
+
+// checkHostsFile 检查hosts文件的语法错误和重复主机名
+func (c *Checker) checkHostsFile(report *Report) {
+	result, err := c.hostManager.ValidateHostsFile()
+	if err != nil {
+		report.Failures = append(report.Failures, fmt.Sprintf("validate hosts file: %v", err))
+		return
+	}
+
+	report.Syntax = result.Errors()
+	for _, issue := range result.Warnings() {
+		if issue.Code == hostsfile.IssueDuplicateHost {
+			report.Duplicates = append(report.Duplicates, issue)
+		}
+	}
+}
+
+// checkDrift 检查管理section与激活Profile之间的差异，没有激活的Profile时跳过
+func (c *Checker) checkDrift(report *Report) {
+	active, err := profile.LayeredActiveProfile(c.profileManager)
+	if err != nil {
+		if !errors.Is(err, models.ErrProfileNotFound) {
+			report.Failures = append(report.Failures, fmt.Sprintf("load active profile: %v", err))
+		}
+		return
+	}
+
+	drift, err := c.hostManager.DetectDrift(active)
+	if err != nil {
+		report.Failures = append(report.Failures, fmt.Sprintf("detect drift: %v", err))
+		return
+	}
+	if drift.HasDrift() {
+		report.DriftProfile = active.Name
+		report.Drift = drift
+	}
+}
+
+// checkBackups 检查备份文件是否可读、非空且没有语法错误
+func (c *Checker) checkBackups(report *Report) {
+	if c.backupDir == "" {
+		return
+	}
+
+	backups, err := host.ListBackupFiles(c.backupDir)
+	if err != nil {
+		report.Failures = append(report.Failures, fmt.Sprintf("list backups: %v", err))
+		return
+	}
+
+	for _, backup := range backups {
+		if err := host.VerifyBackupFile(backup.Path); err != nil {
+			report.Backups = append(report.Backups, BackupProblem{Path: backup.Path, Reason: err.Error()})
+		}
+	}
+	sort.Slice(report.Backups, func(i, j int) bool {
+		return report.Backups[i].Path < report.Backups[j].Path
+	})
+}
+
+// LastReport 获取最近一次的验证报告
+func (c *Checker) LastReport() *Report {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.lastReport
+}
+
+// OnReport 设置报告生成后的回调
+func (c *Checker) OnReport(callback func(*Report)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.onReport = callback
+}
+
+// Start 立即运行一次验证，然后按固定间隔定时运行
+func (c *Checker) Start(interval time.Duration) error {
+	if interval <= 0 {
+		return fmt.Errorf("invalid check interval: %v", interval)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.stopChan != nil {
+		return fmt.Errorf("checker already started")
+	}
+	c.stopChan = make(chan struct{})
+	c.nextRun = c.now().Add(interval)
+
+	go func(stop chan struct{}) {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		c.Check()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				c.scheduleNext(interval)
+				c.Check()
+			}
+		}
+	}(c.stopChan)
+
+	return nil
+}
+
+// NextRun 下一次定时验证的时间，未启动时返回false
+func (c *Checker) NextRun() (time.Time, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.nextRun, !c.nextRun.IsZero()
+}
+
+// scheduleNext 记录下一次定时验证的时间
+func (c *Checker) scheduleNext(interval time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.stopChan != nil {
+		c.nextRun = c.now().Add(interval)
+	}
+}
+
+// Stop 停止定时验证
+func (c *Checker) Stop() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.stopChan != nil {
+		close(c.stopChan)
+		c.stopChan = nil
+		c.nextRun = time.Time{}
+	}
+}
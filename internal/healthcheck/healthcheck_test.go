@@ -0,0 +1,101 @@
+package healthcheck
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/flyhigher139/mhost/internal/fakes"
+	"github.com/flyhigher139/mhost/internal/host"
+	"github.com/flyhigher139/mhost/internal/profile"
+	"github.com/flyhigher139/mhost/pkg/models"
+)
+
+// TestCheck 测试汇总语法、重复、漂移和备份问题
+func TestCheck(t *testing.T) {
+	dir := t.TempDir()
+	hostsPath := filepath.Join(dir, "hosts")
+	backupDir := filepath.Join(dir, "backups")
+	require.NoError(t, os.WriteFile(hostsPath, []byte("127.0.0.1 localhost\n"), 0644))
+	require.NoError(t, os.MkdirAll(backupDir, 0755))
+
+	hm := host.NewManager(hostsPath, backupDir)
+	pm, err := profile.NewManager(filepath.Join(dir, "profiles"))
+	require.NoError(t, err)
+
+	p, err := pm.CreateProfile("Dev", "")
+	require.NoError(t, err)
+	p.AddEntry(models.NewHostEntry("10.0.0.1", "api.test", ""))
+	require.NoError(t, pm.UpdateProfile(p))
+	require.NoError(t, pm.ActivateProfile(p.ID))
+
+	_, err = hm.ApplyProfile(p)
+	require.NoError(t, err)
+
+	checker := NewChecker(hm, pm, backupDir)
+	var callbackReport *Report
+	checker.OnReport(func(r *Report) { callbackReport = r })
+
+	report := checker.Check()
+	assert.Same(t, report, checker.LastReport())
+	assert.Same(t, report, callbackReport)
+	assert.Equal(t, 0, report.Total(), "freshly applied profile has no problems: %+v", report)
+
+	// 手动编辑hosts文件：语法错误、重复主机名和管理section漂移
+	data, err := os.ReadFile(hostsPath)
+	require.NoError(t, err)
+	edited := string(data) + "not-an-ip broken.test\n10.0.0.9 localhost\n"
+	edited = strings.Replace(edited, "10.0.0.1", "10.0.0.2", 1)
+	require.NoError(t, os.WriteFile(hostsPath, []byte(edited), 0644))
+
+	require.NoError(t, os.WriteFile(filepath.Join(backupDir, "hosts_backup_empty.txt"), nil, 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(backupDir, "hosts_backup_ok.txt"), []byte("127.0.0.1 localhost\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(backupDir, "unrelated.txt"), nil, 0644))
+
+	report = checker.Check()
+	assert.Len(t, report.Syntax, 1)
+	assert.Len(t, report.Duplicates, 1)
+	assert.Equal(t, "Dev", report.DriftProfile)
+	assert.Equal(t, 1, report.DriftCount())
+	require.Len(t, report.Backups, 1)
+	assert.Contains(t, report.Backups[0].Path, "hosts_backup_empty.txt")
+	assert.Empty(t, report.Failures)
+	assert.Equal(t, 4, report.Total())
+}
+
+// TestStartStop 测试启动时立即验证并可停止
+func TestStartStop(t *testing.T) {
+	checker := NewChecker(fakes.NewHostManager(nil, "127.0.0.1 localhost"), fakes.NewProfileManager(nil), "")
+	reports := make(chan *Report, 1)
+	checker.OnReport(func(r *Report) {
+		select {
+		case reports <- r:
+		default:
+		}
+	})
+
+	assert.Error(t, checker.Start(0))
+	require.NoError(t, checker.Start(time.Hour))
+	assert.Error(t, checker.Start(time.Hour))
+	defer checker.Stop()
+
+	next, ok := checker.NextRun()
+	assert.True(t, ok)
+	assert.WithinDuration(t, time.Now().Add(time.Hour), next, time.Minute)
+
+	select {
+	case report := <-reports:
+		assert.Equal(t, 0, report.Total())
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected an initial report")
+	}
+
+	checker.Stop()
+	_, ok = checker.NextRun()
+	assert.False(t, ok)
+}
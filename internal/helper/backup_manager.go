@@ -11,6 +11,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/flyhigher139/mhost/internal/host"
 	"github.com/flyhigher139/mhost/pkg/errors"
 	"github.com/flyhigher139/mhost/pkg/logger"
 )
@@ -38,6 +39,16 @@ type BackupInfo struct {
 	Automatic   bool      `json:"automatic"`
 }
 
+// Protected 是否为带有里程碑标签的备份，里程碑备份不会被自动清理
+func (b *BackupInfo) Protected() bool {
+	for _, tag := range b.Tags {
+		if tag == host.MilestoneTag {
+			return true
+		}
+	}
+	return false
+}
+
 // BackupConfig 备份配置
 type BackupConfig struct {
 	BackupDir       string        `json:"backup_dir"`
@@ -55,6 +66,9 @@ type BackupStats struct {
 	NewestBackup    *time.Time `json:"newest_backup,omitempty"`
 	AutomaticBackups int   `json:"automatic_backups"`
 	ManualBackups   int   `json:"manual_backups"`
+	ValidBackups    int   `json:"valid_backups"`
+	InvalidBackups  int   `json:"invalid_backups"`
+	FreeSpace       uint64 `json:"free_space,omitempty"` // 备份目录所在磁盘的剩余字节数，无法查询时为0
 }
 
 // NewBackupManagerImpl 创建备份管理器实现
@@ -93,7 +107,8 @@ func (bm *BackupManagerImpl) CreateBackup(sourcePath, name, description string,
 	defer bm.mu.Unlock()
 
 	// 验证源文件
-	if _, err := os.Stat(sourcePath); os.IsNotExist(err) {
+	sourceInfo, err := os.Stat(sourcePath)
+	if os.IsNotExist(err) {
 		return nil, errors.NewFileSystemError(errors.ErrCodeFileNotFound, fmt.Sprintf("source file does not exist: %s", sourcePath), err)
 	}
 
@@ -107,8 +122,17 @@ func (bm *BackupManagerImpl) CreateBackup(sourcePath, name, description string,
 		return existing, nil
 	}
 
-	// 复制文件
+	// 检查剩余空间，避免写满磁盘产生被截断的备份
+	if sourceInfo != nil {
+		if err := bm.ensureSpace(sourceInfo.Size()); err != nil {
+			bm.logger.Error("Insufficient disk space for backup", "backup_dir", bm.backupDir, "error", err)
+			return nil, errors.NewFileSystemError(errors.ErrCodeInsufficientSpace, err.Error(), err)
+		}
+	}
+
+	// 复制文件，失败时删除不完整的备份
 	if err := bm.copyFile(sourcePath, backupPath); err != nil {
+		os.Remove(backupPath)
 		bm.logger.ErrorWithContext(nil, err, "Failed to copy file for backup", "source", sourcePath, "backup", backupPath)
 		return nil, errors.NewFileSystemError(errors.ErrCodeBackupFailed, "failed to copy file", err)
 	}
@@ -289,6 +313,12 @@ func (bm *BackupManagerImpl) GetBackupStats() *BackupStats {
 			manualCount++
 		}
 
+		if bm.validateInfo(backup) == nil {
+			stats.ValidBackups++
+		} else {
+			stats.InvalidBackups++
+		}
+
 		if oldestTime == nil || backup.CreatedAt.Before(*oldestTime) {
 			oldestTime = &backup.CreatedAt
 		}
@@ -303,10 +333,31 @@ func (bm *BackupManagerImpl) GetBackupStats() *BackupStats {
 	stats.NewestBackup = newestTime
 	stats.AutomaticBackups = automaticCount
 	stats.ManualBackups = manualCount
+	if free, err := host.FreeSpace(bm.backupDir); err == nil {
+		stats.FreeSpace = free
+	}
 
 	return stats
 }
 
+// ensureSpace 检查备份目录是否有足够空间保存size字节的备份，空间不足时先按最大备份数清理旧备份（需要持有锁）
+func (bm *BackupManagerImpl) ensureSpace(size int64) error {
+	required := uint64(size) + host.BackupSpaceMargin
+	available, err := host.FreeSpace(bm.backupDir)
+	if err != nil || available >= required {
+		return nil
+	}
+
+	bm.logger.Warn("Low disk space, cleaning up old backups", "available", available, "required", required)
+	if err := bm.cleanupOldBackups(); err != nil {
+		bm.logger.Warn("Failed to cleanup old backups", "error", err)
+	}
+	if available, err = host.FreeSpace(bm.backupDir); err != nil || available >= required {
+		return nil
+	}
+	return &host.SpaceError{Dir: bm.backupDir, Required: required, Available: available}
+}
+
 // CleanupOldBackups 清理旧备份
 func (bm *BackupManagerImpl) CleanupOldBackups() error {
 	bm.mu.Lock()
@@ -317,14 +368,15 @@ func (bm *BackupManagerImpl) CleanupOldBackups() error {
 
 // cleanupOldBackups 内部清理方法（需要持有锁）
 func (bm *BackupManagerImpl) cleanupOldBackups() error {
-	if len(bm.backupIndex) <= bm.maxBackups {
-		return nil
-	}
-
-	// 获取所有备份并按时间排序
+	// 获取所有非里程碑备份并按时间排序，里程碑备份不计入数量
 	backups := make([]*BackupInfo, 0, len(bm.backupIndex))
 	for _, backup := range bm.backupIndex {
-		backups = append(backups, backup)
+		if !backup.Protected() {
+			backups = append(backups, backup)
+		}
+	}
+	if len(backups) <= bm.maxBackups {
+		return nil
 	}
 
 	sort.Slice(backups, func(i, j int) bool {
@@ -480,6 +532,40 @@ func (bm *BackupManagerImpl) ValidateBackup(backupID string) error {
 		return fmt.Errorf("backup not found: %s", backupID)
 	}
 
+	if err := bm.validateInfo(backupInfo); err != nil {
+		return err
+	}
+
+	bm.logger.Debug("Backup validation passed", "id", backupID)
+	return nil
+}
+
+// GetBackupHistory 获取可导出的备份历史，每个备份附带验证结果
+func (bm *BackupManagerImpl) GetBackupHistory() *host.BackupHistory {
+	backups := bm.ListBackups()
+
+	records := make([]host.BackupRecord, 0, len(backups))
+	for _, backup := range backups {
+		record := host.BackupRecord{
+			ID:        backup.ID,
+			Name:      backup.Name,
+			Path:      backup.Path,
+			CreatedAt: backup.CreatedAt,
+			Size:      backup.Size,
+			Automatic: backup.Automatic,
+			Valid:     true,
+		}
+		if err := bm.validateInfo(backup); err != nil {
+			record.Valid = false
+			record.Error = err.Error()
+		}
+		records = append(records, record)
+	}
+	return host.NewBackupHistory(records)
+}
+
+// validateInfo 检查备份文件是否存在、大小和校验和是否与记录一致
+func (bm *BackupManagerImpl) validateInfo(backupInfo *BackupInfo) error {
 	// 检查文件是否存在
 	fileInfo, err := os.Stat(backupInfo.Path)
 	if os.IsNotExist(err) {
@@ -505,6 +591,5 @@ func (bm *BackupManagerImpl) ValidateBackup(backupID string) error {
 		}
 	}
 
-	bm.logger.Debug("Backup validation passed", "id", backupID)
 	return nil
 }
\ No newline at end of file
@@ -0,0 +1,131 @@
+package helper
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// DefaultCoalesceWindow 写入请求的默认合并窗口
+const DefaultCoalesceWindow = 100 * time.Millisecond
+
+// CoalesceStats 写入合并的统计信息
+type CoalesceStats struct {
+	WindowMS  int64 `json:"window_ms"`
+	Requests  int64 `json:"requests"`
+	Writes    int64 `json:"writes"`
+	Coalesced int64 `json:"coalesced"`
+}
+
+// WriteCoalescer 合并短时间内连续到达的写入请求（例如自动保存与频繁切换条目），
+// 只有最后一个请求写入hosts文件，减少磁盘写入和备份数量。
+// 每个请求先通过Enter获得序号，等待合并窗口后仍是最新的请求才继续，
+// 写入前再通过Claim确认没有更新的请求已经写入，避免旧状态覆盖新状态。
+// 继续执行的请求结束后通过Finish公布结果，被取代的请求通过Result等待并返回该结果
+type WriteCoalescer struct {
+	window time.Duration
+
+	mu       sync.Mutex
+	latest   uint64
+	written  uint64
+	finished uint64
+	outcome  XPCResponse
+	// done 每次Finish时关闭并替换，唤醒等待结果的请求
+	done  chan struct{}
+	stats CoalesceStats
+}
+
+// NewWriteCoalescer 创建写入合并器，window不大于0时不等待，但仍不会用旧状态覆盖新状态
+func NewWriteCoalescer(window time.Duration) *WriteCoalescer {
+	if window < 0 {
+		window = 0
+	}
+	return &WriteCoalescer{
+		window: window,
+		done:   make(chan struct{}),
+		stats:  CoalesceStats{WindowMS: window.Milliseconds()},
+	}
+}
+
+// Enter 登记一个写入请求，返回其序号
+func (c *WriteCoalescer) Enter() uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.latest++
+	c.stats.Requests++
+	return c.latest
+}
+
+// Wait 等待合并窗口，返回请求是否仍需写入；窗口内有更新的请求到达时返回false。
+// ctx结束时不再等待
+func (c *WriteCoalescer) Wait(ctx context.Context, ticket uint64) bool {
+	if c.window > 0 {
+		timer := time.NewTimer(c.window)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+		}
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if ticket < c.latest {
+		c.stats.Coalesced++
+		return false
+	}
+	return true
+}
+
+// Claim 在写入前调用，确认没有更新的请求已经写入；返回false时应放弃写入
+func (c *WriteCoalescer) Claim(ticket uint64) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if ticket < c.written {
+		c.stats.Coalesced++
+		return false
+	}
+	c.written = ticket
+	c.stats.Writes++
+	return true
+}
+
+// Finish 公布继续执行的请求的结果，包括写入失败、被限流等没有写入的情况
+func (c *WriteCoalescer) Finish(ticket uint64, response *XPCResponse) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if ticket > c.finished {
+		c.finished = ticket
+		c.outcome = *response
+	}
+	close(c.done)
+	c.done = make(chan struct{})
+}
+
+// Result 等待比ticket更新的请求结束并返回其结果的副本；ctx结束时返回ctx的错误
+func (c *WriteCoalescer) Result(ctx context.Context, ticket uint64) (*XPCResponse, error) {
+	for {
+		c.mu.Lock()
+		if c.finished > ticket {
+			outcome := c.outcome
+			c.mu.Unlock()
+			return &outcome, nil
+		}
+		done := c.done
+		c.mu.Unlock()
+
+		select {
+		case <-done:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// Stats 获取写入合并的统计信息
+func (c *WriteCoalescer) Stats() *CoalesceStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	stats := c.stats
+	return &stats
+}
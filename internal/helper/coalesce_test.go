@@ -0,0 +1,110 @@
+package helper
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/flyhigher139/mhost/pkg/logger"
+)
+
+// newCoalescingHelper 创建合并窗口为50ms、使用临时hosts文件的Helper
+func newCoalescingHelper(t *testing.T) (*HostsHelper, string) {
+	dir := t.TempDir()
+	hostsPath := filepath.Join(dir, "hosts")
+	require.NoError(t, os.WriteFile(hostsPath, []byte("127.0.0.1\tlocalhost\n"), 0644))
+	h, err := NewHostsHelperWithOptions(&HelperOptions{
+		ServiceName:    DefaultServiceName,
+		HostsPath:      hostsPath,
+		BackupDir:      filepath.Join(dir, "backups"),
+		MaxBackups:     3,
+		CoalesceWindow: 50 * time.Millisecond,
+	}, logger.NewEnhancedLogger(logger.LogLevelError, false))
+	require.NoError(t, err)
+	h.securityMgr.(*SecurityManagerImpl).AddToWhitelist("client")
+	return h, hostsPath
+}
+
+// writeConcurrently 依次间隔5ms并发发送写入请求，每个请求写入一个条目
+func writeConcurrently(h *HostsHelper, entries []map[string]interface{}) []*XPCResponse {
+	responses := make([]*XPCResponse, len(entries))
+	var wg sync.WaitGroup
+	for i, entry := range entries {
+		wg.Add(1)
+		go func(i int, entry map[string]interface{}) {
+			defer wg.Done()
+			responses[i] = h.handleXPCRequest(newTestRequest("client", "write_hosts", map[string]interface{}{
+				"entries": []interface{}{entry},
+			}))
+		}(i, entry)
+		time.Sleep(5 * time.Millisecond)
+	}
+	wg.Wait()
+	return responses
+}
+
+// TestWriteCoalescing 测试合并窗口内连续到达的写入只执行最后一个，且旧请求不会覆盖已写入的新状态
+func TestWriteCoalescing(t *testing.T) {
+	h, hostsPath := newCoalescingHelper(t)
+
+	hostnames := []string{"first.test", "second.test", "third.test"}
+	var entries []map[string]interface{}
+	for _, hostname := range hostnames {
+		entries = append(entries, map[string]interface{}{"ip": "10.0.0.1", "hostname": hostname, "enabled": true})
+	}
+	responses := writeConcurrently(h, entries)
+
+	for i, resp := range responses {
+		require.True(t, resp.Success, resp.Error)
+		assert.Equal(t, i < len(hostnames)-1, resp.Data["coalesced"] == true, "response %d", i)
+	}
+	data, err := os.ReadFile(hostsPath)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "third.test")
+	assert.NotContains(t, string(data), "first.test")
+	assert.Equal(t, &CoalesceStats{WindowMS: 50, Requests: 3, Writes: 1, Coalesced: 2}, h.coalescer.Stats())
+
+	// 等待结束后更新的请求先写入时，旧请求放弃写入并得到新请求的结果
+	coalescer := NewWriteCoalescer(0)
+	older, newer := coalescer.Enter(), coalescer.Enter()
+	assert.True(t, coalescer.Claim(newer))
+	assert.False(t, coalescer.Claim(older))
+	coalescer.Finish(newer, &XPCResponse{Success: true})
+	result, err := coalescer.Result(context.Background(), older)
+	require.NoError(t, err)
+	assert.True(t, result.Success)
+
+	// 新请求没有结束时，等待结果最多到请求的截止时间
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err = coalescer.Result(ctx, coalescer.Enter())
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+// TestWriteCoalescingFailure 测试取代旧请求的写入失败时，被合并的请求同样返回失败
+func TestWriteCoalescingFailure(t *testing.T) {
+	h, hostsPath := newCoalescingHelper(t)
+	// hosts文件无法读取，通过安全验证的写入在写入时失败
+	require.NoError(t, os.Remove(hostsPath))
+	require.NoError(t, os.Mkdir(hostsPath, 0755))
+
+	responses := writeConcurrently(h, []map[string]interface{}{
+		{"ip": "10.0.0.1", "hostname": "first.test", "enabled": true},
+		{"ip": "10.0.0.1", "hostname": "second.test", "enabled": true},
+		{"ip": "10.0.0.1", "hostname": "third.test", "enabled": true},
+	})
+
+	for i, resp := range responses {
+		assert.False(t, resp.Success, "response %d", i)
+		assert.Equal(t, responses[2].Error, resp.Error, "response %d", i)
+	}
+	assert.Equal(t, true, responses[0].Data["coalesced"])
+	assert.Equal(t, true, responses[1].Data["coalesced"])
+	assert.Equal(t, &CoalesceStats{WindowMS: 50, Requests: 3, Writes: 1, Coalesced: 2}, h.coalescer.Stats())
+}
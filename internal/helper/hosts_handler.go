@@ -0,0 +1,184 @@
+package helper
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/flyhigher139/mhost/internal/host"
+	"github.com/flyhigher139/mhost/pkg/errors"
+	"github.com/flyhigher139/mhost/pkg/hostsfile"
+)
+
+// HostsHandler hosts文件处理器
+type HostsHandler struct {
+	hostsPath   string
+	backupDir   string
+	logger      Logger
+	hostManager host.Manager
+	dryRun      bool
+	mu          sync.Mutex
+}
+
+// HostsContent hosts文件内容快照
+type HostsContent struct {
+	HostsPath         string      `json:"hosts_path"`
+	Lines             []string    `json:"lines"`
+	HasManagedSection bool        `json:"has_managed_section"`
+	ManagedSection    []string    `json:"managed_section"`
+	ManagedEntries    []HostEntry `json:"managed_entries"`
+}
+
+// NewHostsHandler 创建hosts处理器
+func NewHostsHandler(hostsPath, backupDir string, logger Logger) (*HostsHandler, error) {
+	if hostsPath == "" {
+		return nil, fmt.Errorf("hosts path cannot be empty")
+	}
+
+	return &HostsHandler{
+		hostsPath:   hostsPath,
+		backupDir:   backupDir,
+		logger:      logger,
+		hostManager: host.NewManager(hostsPath, backupDir),
+	}, nil
+}
+
+// WriteHosts 使用给定条目重写hosts文件中的mHost管理section
+func (h *HostsHandler) WriteHosts(entries []HostEntry) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.logger.Info("Writing hosts file", "entries", len(entries), "dry_run", h.dryRun)
+
+	// 写入前规范化主机名并验证所有条目；规范化副本，不修改调用方（例如合并写入时保存的请求）的条目
+	normalized := make([]HostEntry, len(entries))
+	for i, entry := range entries {
+		entry.Hostname = hostsfile.NormalizeHostname(entry.Hostname)
+		normalized[i] = entry
+		if err := hostsfile.ValidateEntry(entry); err != nil {
+			return errors.NewValidationError(errors.ErrCodeHostsValidationFailed,
+				fmt.Sprintf("invalid entry at index %d: %v", i, err), map[string]interface{}{
+					"index":    i,
+					"ip":       entry.IP,
+					"hostname": entry.Hostname,
+				})
+		}
+	}
+
+	// 仅替换mHost管理section，保留hosts文件中的非管理部分
+	current, err := h.hostManager.ReadHostsFile()
+	if err != nil {
+		return errors.NewFileSystemError(errors.ErrCodeFileReadFailed, "failed to read hosts file", err)
+	}
+
+	header := []string{fmt.Sprintf("# Updated by helper at: %s", time.Now().Format(time.RFC3339))}
+	lines := hostsfile.ReplaceManagedSection(current, hostsfile.BuildSignedSection(header, normalized))
+
+	if h.dryRun {
+		h.logger.Info("Dry-run: skipping hosts file write", "lines", len(lines))
+		return nil
+	}
+
+	// 原子性写入（临时文件 + rename）
+	if _, err := h.hostManager.WriteHostsFile(lines); err != nil {
+		return errors.NewFileSystemError(errors.ErrCodeFileWriteFailed, "failed to write hosts file", err)
+	}
+
+	return nil
+}
+
+// ReadHosts 读取hosts文件内容及mHost管理section
+func (h *HostsHandler) ReadHosts() (*HostsContent, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	lines, err := h.hostManager.ReadHostsFile()
+	if err != nil {
+		return nil, errors.NewFileSystemError(errors.ErrCodeFileReadFailed, "failed to read hosts file", err)
+	}
+
+	managed := hostsfile.ExtractManagedSection(lines)
+	return &HostsContent{
+		HostsPath:         h.hostsPath,
+		Lines:             lines,
+		HasManagedSection: hostsfile.HasManagedSection(lines),
+		ManagedSection:    managed,
+		ManagedEntries:    hostsfile.Parse(managed),
+	}, nil
+}
+
+// BackupHosts 备份hosts文件
+func (h *HostsHandler) BackupHosts() (string, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.logger.Info("Backing up hosts file", "backup_dir", h.backupDir)
+
+	backup, err := h.hostManager.BackupHostsFile()
+	if err != nil {
+		return "", errors.NewFileSystemError(errors.ErrCodeBackupFailed, "failed to backup hosts file", err)
+	}
+
+	return backup.FilePath, nil
+}
+
+// RestoreHosts 恢复hosts文件
+func (h *HostsHandler) RestoreHosts(backupPath string) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.logger.Info("Restoring hosts file", "backup", backupPath, "dry_run", h.dryRun)
+
+	data, err := os.ReadFile(backupPath)
+	if err != nil {
+		return errors.NewFileSystemError(errors.ErrCodeFileReadFailed, "failed to read backup file", err)
+	}
+
+	lines := hostsfile.SplitLines(string(data))
+
+	if h.dryRun {
+		h.logger.Info("Dry-run: skipping hosts file restore", "lines", len(lines))
+		return nil
+	}
+
+	if _, err := h.hostManager.WriteHostsFile(lines); err != nil {
+		return errors.NewFileSystemError(errors.ErrCodeRestoreFailed, "failed to restore hosts file", err)
+	}
+
+	return nil
+}
+
+// ValidateHosts 验证hosts文件
+func (h *HostsHandler) ValidateHosts() error {
+	h.logger.Info("Validating hosts file")
+
+	result, err := h.hostManager.ValidateHostsFile()
+	if err != nil {
+		return errors.NewFileSystemError(errors.ErrCodeFileReadFailed, "failed to read hosts file", err)
+	}
+	if issues := result.Errors(); len(issues) > 0 {
+		details := make(map[string]interface{}, len(issues))
+		for _, issue := range issues {
+			details[fmt.Sprintf("line_%d", issue.Line)] = issue.Message
+		}
+		return errors.NewValidationError(errors.ErrCodeHostsValidationFailed, issues[0].String(), details)
+	}
+
+	return nil
+}
+
+// SetDryRun 设置是否仅模拟写入
+func (h *HostsHandler) SetDryRun(dryRun bool) {
+	h.dryRun = dryRun
+}
+
+// IsDryRun 是否处于模拟写入模式
+func (h *HostsHandler) IsDryRun() bool {
+	return h.dryRun
+}
+
+// GetHostsPath 获取hosts文件路径
+func (h *HostsHandler) GetHostsPath() string {
+	return h.hostsPath
+}
@@ -0,0 +1,136 @@
+package helper
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/flyhigher139/mhost/pkg/hostsfile"
+	"github.com/flyhigher139/mhost/pkg/hostsfile/hostsfiletest"
+	"github.com/flyhigher139/mhost/pkg/logger"
+)
+
+// newTestHostsHandler 创建测试用的hosts处理器
+func newTestHostsHandler(t *testing.T, content string) (*HostsHandler, string) {
+	tempDir := t.TempDir()
+	hostsPath := filepath.Join(tempDir, "hosts")
+	require.NoError(t, os.WriteFile(hostsPath, []byte(content), 0644))
+
+	handler, err := NewHostsHandler(hostsPath, filepath.Join(tempDir, "backups"), logger.NewEnhancedLogger(logger.LogLevelError, false))
+	require.NoError(t, err)
+	return handler, hostsPath
+}
+
+// readTestHostsLines 读取测试hosts文件的所有行
+func readTestHostsLines(t *testing.T, hostsPath string) []string {
+	data, err := os.ReadFile(hostsPath)
+	require.NoError(t, err)
+	return strings.Split(strings.TrimRight(string(data), "\n"), "\n")
+}
+
+// TestHostsHandlerWriteHosts 测试写入hosts文件仅替换管理section
+func TestHostsHandlerWriteHosts(t *testing.T) {
+	handler, hostsPath := newTestHostsHandler(t, "127.0.0.1\tlocalhost\n")
+
+	err := handler.WriteHosts([]HostEntry{
+		{IP: "10.0.0.1", Hostname: "api.test", Comment: "staging", Enabled: true},
+		{IP: "10.0.0.2", Hostname: "web.test", Enabled: false},
+	})
+	require.NoError(t, err)
+
+	lines := readTestHostsLines(t, hostsPath)
+	assert.Equal(t, "127.0.0.1\tlocalhost", lines[0])
+	assert.Equal(t, hostsfile.StartMarker, lines[2])
+	assert.Contains(t, lines, "10.0.0.1\tapi.test\t# staging")
+	assert.NotContains(t, lines, "10.0.0.2\tweb.test")
+	assert.NoError(t, handler.ValidateHosts())
+
+	// 再次写入替换原有section，非管理部分保持不变；主机名写入前规范化，调用方的条目保持不变
+	entries := []HostEntry{{IP: "10.0.0.3", Hostname: "New.Test.", Enabled: true}}
+	require.NoError(t, handler.WriteHosts(entries))
+	assert.Equal(t, "New.Test.", entries[0].Hostname)
+
+	lines = readTestHostsLines(t, hostsPath)
+	assert.Equal(t, "127.0.0.1\tlocalhost", lines[0])
+	assert.Contains(t, lines, "10.0.0.3\tnew.test")
+	assert.NotContains(t, lines, "10.0.0.1\tapi.test\t# staging")
+
+	// 空条目移除管理section
+	require.NoError(t, handler.WriteHosts(nil))
+	assert.Equal(t, []string{"127.0.0.1\tlocalhost"}, readTestHostsLines(t, hostsPath))
+}
+
+// TestHostsHandlerWriteHostsInvalid 测试写入无效条目
+func TestHostsHandlerWriteHostsInvalid(t *testing.T) {
+	original := "127.0.0.1\tlocalhost\n"
+	handler, hostsPath := newTestHostsHandler(t, original)
+
+	err := handler.WriteHosts([]HostEntry{{IP: "999.0.0.1", Hostname: "bad.test", Enabled: true}})
+	assert.Error(t, err)
+
+	data, err := os.ReadFile(hostsPath)
+	require.NoError(t, err)
+	assert.Equal(t, original, string(data))
+}
+
+// TestHostsHandlerDryRun 测试模拟写入模式
+func TestHostsHandlerDryRun(t *testing.T) {
+	original := "127.0.0.1\tlocalhost\n"
+	handler, hostsPath := newTestHostsHandler(t, original)
+	handler.SetDryRun(true)
+
+	require.NoError(t, handler.WriteHosts([]HostEntry{{IP: "10.0.0.1", Hostname: "api.test", Enabled: true}}))
+
+	data, err := os.ReadFile(hostsPath)
+	require.NoError(t, err)
+	assert.Equal(t, original, string(data))
+}
+
+// TestHostsHandlerBackupRestore 测试备份与恢复
+func TestHostsHandlerBackupRestore(t *testing.T) {
+	original := "127.0.0.1\tlocalhost\n"
+	handler, hostsPath := newTestHostsHandler(t, original)
+
+	backupPath, err := handler.BackupHosts()
+	require.NoError(t, err)
+	assert.FileExists(t, backupPath)
+
+	require.NoError(t, handler.WriteHosts([]HostEntry{{IP: "10.0.0.1", Hostname: "api.test", Enabled: true}}))
+	require.NoError(t, handler.RestoreHosts(backupPath))
+
+	data, err := os.ReadFile(hostsPath)
+	require.NoError(t, err)
+	assert.Equal(t, original, string(data))
+}
+
+// TestHostsHandlerReadHosts 测试读取hosts文件及管理section
+func TestHostsHandlerReadHosts(t *testing.T) {
+	handler, hostsPath := newTestHostsHandler(t, "127.0.0.1\tlocalhost\n")
+
+	content, err := handler.ReadHosts()
+	require.NoError(t, err)
+	assert.Equal(t, hostsPath, content.HostsPath)
+	assert.False(t, content.HasManagedSection)
+	assert.Empty(t, content.ManagedEntries)
+
+	require.NoError(t, handler.WriteHosts([]HostEntry{{IP: "10.0.0.1", Hostname: "api.test", Enabled: true}}))
+
+	content, err = handler.ReadHosts()
+	require.NoError(t, err)
+	assert.True(t, content.HasManagedSection)
+	assert.Equal(t, "127.0.0.1\tlocalhost", content.Lines[0])
+	assert.Equal(t, []HostEntry{{IP: "10.0.0.1", Hostname: "api.test", Enabled: true}}, content.ManagedEntries)
+}
+
+// TestHostsHandlerGolden 测试Helper写入的hosts文件与testdata中的golden文件一致
+func TestHostsHandlerGolden(t *testing.T) {
+	hostsfiletest.Run(t, func(t *testing.T, c hostsfiletest.Case, hostsPath string) {
+		handler, err := NewHostsHandler(hostsPath, "", logger.NewEnhancedLogger(logger.LogLevelError, false))
+		require.NoError(t, err)
+		require.NoError(t, handler.WriteHosts(c.Entries()))
+	})
+}
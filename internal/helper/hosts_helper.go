@@ -3,11 +3,13 @@ package helper
 import (
 	"context"
 	"fmt"
+	"maps"
 	"path/filepath"
 	"strings"
 	"sync"
 	"time"
 
+	"github.com/flyhigher139/mhost/internal/host"
 	"github.com/flyhigher139/mhost/pkg/errors"
 	"github.com/flyhigher139/mhost/pkg/logger"
 )
@@ -21,14 +23,59 @@ type HostsHelper struct {
 	hostsHandler *HostsHandler
 	auditLogger *AuditLogger
 	backupMgr   BackupManager
+	supervisor  *Supervisor
+	limiter     *RequestLimiter
+	coalescer   *WriteCoalescer
 	mu          sync.RWMutex
 	running     bool
+	startTime   time.Time
+	lastError   *StatusError
 	ctx         context.Context
 	cancel      context.CancelFunc
 }
 
+// HelperOptions Helper Tool运行选项
+type HelperOptions struct {
+	ServiceName    string // XPC服务名称
+	HostsPath      string // hosts文件路径
+	AuditLogPath   string // 审计日志路径
+	BackupDir      string // 备份目录
+	MaxBackups     int    // 最大备份数量
+	DryRun         bool   // 仅模拟写入，不修改hosts文件
+	RestartOnPanic bool   // 后台组件panic后自动重启
+	MaxRestarts    int    // 每个组件最多自动重启的次数
+	MaxConcurrent  int    // 同时处理的最大请求数
+	// CoalesceWindow 写入请求的合并窗口，窗口内连续到达的写入只执行最后一个；为0时不等待
+	CoalesceWindow time.Duration
+}
+
+// DefaultHelperOptions 返回默认的Helper Tool运行选项
+func DefaultHelperOptions(serviceName string) *HelperOptions {
+	return &HelperOptions{
+		ServiceName:    serviceName,
+		HostsPath:      "/etc/hosts",
+		AuditLogPath:   "/var/log/mhost-helper-audit.log",
+		BackupDir:      "/tmp/mhost-backups",
+		MaxBackups:     10,
+		RestartOnPanic: true,
+		MaxRestarts:    DefaultMaxRestarts,
+		MaxConcurrent:  DefaultMaxConcurrent,
+		CoalesceWindow: DefaultCoalesceWindow,
+	}
+}
+
 // NewHostsHelper 创建新的HostsHelper实例
 func NewHostsHelper(serviceName string, logger logger.Logger) (*HostsHelper, error) {
+	return NewHostsHelperWithOptions(DefaultHelperOptions(serviceName), logger)
+}
+
+// NewHostsHelperWithOptions 使用指定选项创建HostsHelper实例
+func NewHostsHelperWithOptions(opts *HelperOptions, logger logger.Logger) (*HostsHelper, error) {
+	if opts == nil {
+		return nil, errors.NewValidationError(errors.ErrCodeValidationFailed, "helper options cannot be nil", nil)
+	}
+
+	serviceName := opts.ServiceName
 	if serviceName == "" {
 		return nil, errors.NewValidationError(errors.ErrCodeValidationFailed, "service name cannot be empty", nil)
 	}
@@ -37,10 +84,8 @@ func NewHostsHelper(serviceName string, logger logger.Logger) (*HostsHelper, err
 		return nil, errors.NewValidationError(errors.ErrCodeValidationFailed, "logger cannot be nil", nil)
 	}
 
-	ctx, cancel := context.WithCancel(context.Background())
-
 	// 创建审计日志器
-	auditLogger, err := NewAuditLogger("/var/log/mhost-helper-audit.log", logger)
+	auditLogger, err := NewAuditLogger(opts.AuditLogPath, logger)
 	if err != nil {
 		logger.ErrorWithContext(nil, err, "Failed to create audit logger")
 		return nil, errors.NewSystemError(errors.ErrCodeAuditLogFailed, "failed to create audit logger", err)
@@ -50,24 +95,34 @@ func NewHostsHelper(serviceName string, logger logger.Logger) (*HostsHelper, err
 	securityMgr := NewSecurityManager(auditLogger, logger)
 
 	// 创建hosts文件处理器
-	hostsHandler, err := NewHostsHandler("/etc/hosts", logger)
+	hostsHandler, err := NewHostsHandler(opts.HostsPath, opts.BackupDir, logger)
 	if err != nil {
 		logger.ErrorWithContext(nil, err, "Failed to create hosts handler")
 		return nil, errors.NewFileSystemError(errors.ErrCodeFileReadFailed, "failed to create hosts handler", err)
 	}
+	hostsHandler.SetDryRun(opts.DryRun)
+
+	// 创建后台组件的Supervisor，崩溃记录写入审计日志
+	supervisor := NewSupervisor(logger, auditLogger)
+	supervisor.SetRestartPolicy(opts.RestartOnPanic, opts.MaxRestarts, DefaultRestartBackoff)
 
 	// 创建XPC服务器
 	xpcServer, err := NewXPCServer(serviceName, logger)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create XPC server: %w", err)
 	}
+	if supervised, ok := xpcServer.(interface{ SetSupervisor(*Supervisor) }); ok {
+		supervised.SetSupervisor(supervisor)
+	}
 
 	// 创建备份管理器
-	backupMgr, err := NewBackupManager(logger, "/tmp/mhost-backups", 10)
+	backupMgr, err := NewBackupManager(logger, opts.BackupDir, opts.MaxBackups)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create backup manager: %w", err)
 	}
 
+	ctx, cancel := context.WithCancel(context.Background())
+
 	return &HostsHelper{
 		serviceName:  serviceName,
 		logger:       logger,
@@ -76,6 +131,9 @@ func NewHostsHelper(serviceName string, logger logger.Logger) (*HostsHelper, err
 		hostsHandler: hostsHandler,
 		auditLogger:  auditLogger,
 		backupMgr:    backupMgr,
+		supervisor:   supervisor,
+		limiter:      NewRequestLimiter(opts.MaxConcurrent),
+		coalescer:    NewWriteCoalescer(opts.CoalesceWindow),
 		running:      false,
 		ctx:          ctx,
 		cancel:       cancel,
@@ -99,6 +157,7 @@ func (h *HostsHelper) Start() error {
 	}
 
 	h.running = true
+	h.startTime = time.Now()
 	h.logger.Info("HostsHelper started successfully")
 
 	return nil
@@ -141,90 +200,159 @@ func (h *HostsHelper) IsRunning() bool {
 	return h.running
 }
 
-// handleXPCRequest 处理XPC请求
+// handleXPCRequest 处理XPC请求，响应中带回请求ID
 func (h *HostsHelper) handleXPCRequest(req *XPCRequest) *XPCResponse {
+	if req.RequestID == "" {
+		req.RequestID = logger.NewRequestID()
+	}
+	response := h.processXPCRequest(req)
+	response.RequestID = req.RequestID
+	return response
+}
+
+// requestLogger 返回带有请求ID的日志器，便于在GUI和Helper的日志中查找同一个请求
+func (h *HostsHelper) requestLogger(req *XPCRequest) Logger {
+	return h.logger.WithContext(logger.WithRequestID(h.ctx, req.RequestID))
+}
+
+// processXPCRequest 验证请求并分发到具体操作
+func (h *HostsHelper) processXPCRequest(req *XPCRequest) *XPCResponse {
 	start := time.Now()
+	log := h.requestLogger(req)
 
 	// 记录请求开始
-	h.logger.Debug("Handling XPC request", "operation", req.Operation, "client", req.ClientID)
+	log.Debug("Handling XPC request", "operation", req.Operation, "client", req.ClientID)
 
 	// 安全验证
 	if err := h.securityMgr.ValidateRequest(req); err != nil {
-		h.logger.Error("Security validation failed", "error", err, "client", req.ClientID)
-		h.auditLogger.LogFailedOperation(req.Operation, req.ClientID, err.Error())
-		return &XPCResponse{
-			Success: false,
-			Error:   fmt.Sprintf("Security validation failed: %v", err),
+		log.Error("Security validation failed", "error", err, "client", req.ClientID)
+		h.auditLogger.LogFailedOperation(req.Operation, req.ClientID, req.RequestID, err.Error())
+		h.recordError(req.Operation, err.Error())
+		return NewErrorResponse(fmt.Errorf("Security validation failed: %w", err), errors.ErrCodeSecurityViolation, errors.ErrorTypePermission)
+	}
+
+	ctx, cancel := h.requestContext(req)
+	defer cancel()
+
+	// 合并短时间内连续到达的写入请求，在合并窗口内被更新的请求取代时不再写入，而是等待取代它的请求的结果
+	var ticket uint64
+	if req.Operation == "write_hosts" {
+		ticket = h.coalescer.Enter()
+		if !h.coalescer.Wait(ctx, ticket) {
+			log.Info("Write request coalesced", "client", req.ClientID, "duration", time.Since(start))
+			return h.awaitCoalescedWrite(ctx, ticket)
 		}
 	}
 
+	// 限制并发请求数，修改hosts文件的操作独占执行，避免写入交错
+	release, err := h.limiter.Acquire(ctx, req.Operation)
+	if err != nil {
+		log.Error("Request rejected", "error", err, "operation", req.Operation, "client", req.ClientID)
+		h.auditLogger.LogFailedOperation(req.Operation, req.ClientID, req.RequestID, err.Error())
+		h.recordError(req.Operation, err.Error())
+		response := NewErrorResponse(err, errors.ErrCodeXPCServiceUnavailable, errors.ErrorTypeSystem)
+		if ticket != 0 {
+			h.coalescer.Finish(ticket, response)
+		}
+		return response
+	}
+	defer release()
+
 	// 处理具体操作
 	var response *XPCResponse
 	switch req.Operation {
 	case "write_hosts":
-		response = h.handleWriteHosts(req)
+		// 等待期间更新的请求可能已经写入，不能再用旧状态覆盖
+		if h.coalescer.Claim(ticket) {
+			response = h.handleWriteHosts(req)
+			h.coalescer.Finish(ticket, response)
+		} else {
+			return h.awaitCoalescedWrite(ctx, ticket)
+		}
 	case "backup_hosts":
 		response = h.handleBackupHosts(req)
 	case "restore_hosts":
 		response = h.handleRestoreHosts(req)
 	case "validate_hosts":
 		response = h.handleValidateHosts(req)
+	case "read_hosts":
+		response = h.handleReadHosts(req)
 	case "get_status":
 		response = h.handleGetStatus(req)
+	case "get_client_access":
+		response = h.handleGetClientAccess(req)
+	case "update_client_access":
+		response = h.handleUpdateClientAccess(req)
 	default:
-		response = &XPCResponse{
-			Success: false,
-			Error:   fmt.Sprintf("Unknown operation: %s", req.Operation),
-		}
+		response = newValidationResponse("Unknown operation: %s", req.Operation)
 	}
 
 	// 记录操作结果
 	duration := time.Since(start)
 	if response.Success {
-		h.logger.Info("XPC request completed", "operation", req.Operation, "duration", duration)
-		h.auditLogger.LogSuccessfulOperation(req.Operation, req.ClientID, req.Parameters)
+		log.Info("XPC request completed", "operation", req.Operation, "duration", duration)
+		h.auditLogger.LogSuccessfulOperation(req.Operation, req.ClientID, req.RequestID, req.Parameters)
 	} else {
-		h.logger.Error("XPC request failed", "operation", req.Operation, "error", response.Error, "duration", duration)
-		h.auditLogger.LogFailedOperation(req.Operation, req.ClientID, response.Error)
+		log.Error("XPC request failed", "operation", req.Operation, "error", response.Error, "duration", duration)
+		h.auditLogger.LogFailedOperation(req.Operation, req.ClientID, req.RequestID, response.Error)
+		h.recordError(req.Operation, response.Error)
 	}
 
 	return response
 }
 
+// requestContext 请求等待名额和合并结果的时限：请求的截止时间，没有截止时间时为DefaultQueueTimeout
+func (h *HostsHelper) requestContext(req *XPCRequest) (context.Context, context.CancelFunc) {
+	deadline := req.Deadline
+	if deadline.IsZero() {
+		deadline = time.Now().Add(DefaultQueueTimeout)
+	}
+	return context.WithDeadline(h.ctx, deadline)
+}
+
+// awaitCoalescedWrite 等待取代该请求的写入完成，返回其实际结果；写入失败时被合并的请求同样失败
+func (h *HostsHelper) awaitCoalescedWrite(ctx context.Context, ticket uint64) *XPCResponse {
+	result, err := h.coalescer.Result(ctx, ticket)
+	if err != nil {
+		return NewErrorResponse(fmt.Errorf("coalesced write did not finish: %w", err), errors.ErrCodeXPCRequestTimeout, errors.ErrorTypeSystem)
+	}
+	return newCoalescedResponse(result)
+}
+
+// newCoalescedResponse 被合并的写入请求的响应：取代它的请求的结果，带有coalesced标记
+func newCoalescedResponse(result *XPCResponse) *XPCResponse {
+	result.RequestID = ""
+	result.Data = maps.Clone(result.Data)
+	if result.Data == nil {
+		result.Data = make(map[string]interface{})
+	}
+	result.Data["coalesced"] = true
+	result.ErrorDetails = maps.Clone(result.ErrorDetails)
+	return result
+}
+
 // handleWriteHosts 处理写入hosts文件请求
 func (h *HostsHelper) handleWriteHosts(req *XPCRequest) *XPCResponse {
 	entries, ok := req.Parameters["entries"]
 	if !ok {
-		return &XPCResponse{
-			Success: false,
-			Error:   "missing entries parameter",
-		}
+		return newValidationResponse("missing entries parameter")
 	}
 
 	// 类型断言和转换
 	entriesData, ok := entries.([]interface{})
 	if !ok {
-		return &XPCResponse{
-			Success: false,
-			Error:   "invalid entries format",
-		}
+		return newValidationResponse("invalid entries format")
 	}
 
 	// 转换为HostEntry结构
 	hostEntries, err := h.convertToHostEntries(entriesData)
 	if err != nil {
-		return &XPCResponse{
-			Success: false,
-			Error:   fmt.Sprintf("failed to convert entries: %v", err),
-		}
+		return newValidationResponse("failed to convert entries: %v", err)
 	}
 
 	// 写入hosts文件
 	if err := h.hostsHandler.WriteHosts(hostEntries); err != nil {
-		return &XPCResponse{
-			Success: false,
-			Error:   fmt.Sprintf("failed to write hosts file: %v", err),
-		}
+		return NewErrorResponse(fmt.Errorf("failed to write hosts file: %w", err), errors.ErrCodeFileWriteFailed, errors.ErrorTypeFileSystem)
 	}
 
 	return &XPCResponse{
@@ -235,7 +363,8 @@ func (h *HostsHelper) handleWriteHosts(req *XPCRequest) *XPCResponse {
 
 // handleBackupHosts 处理备份hosts文件请求
 func (h *HostsHelper) handleBackupHosts(req *XPCRequest) *XPCResponse {
-	h.logger.Info("Handling backup hosts request")
+	log := h.requestLogger(req)
+	log.Info("Handling backup hosts request")
 
 	// 获取备份参数
 	name := "hosts-backup"
@@ -247,14 +376,29 @@ func (h *HostsHelper) handleBackupHosts(req *XPCRequest) *XPCResponse {
 		description = descParam
 	}
 
+	// 里程碑备份使用里程碑名称作为描述，并且不会被自动清理
+	tags := []string{"hosts"}
+	switch rawTags := req.Parameters["tags"].(type) {
+	case []string:
+		tags = append(tags, rawTags...)
+	case []interface{}:
+		for _, tag := range rawTags {
+			if tag, ok := tag.(string); ok {
+				tags = append(tags, tag)
+			}
+		}
+	}
+	tags = host.NormalizeTags(tags)
+	if milestone, ok := req.Parameters["milestone"].(string); ok && strings.TrimSpace(milestone) != "" {
+		description = strings.TrimSpace(milestone)
+		tags = append(tags, host.MilestoneTag)
+	}
+
 	// 创建备份
-	backupInfo, err := h.backupMgr.CreateBackup("/etc/hosts", name, description, []string{"hosts"}, true)
+	backupInfo, err := h.backupMgr.CreateBackup(h.hostsHandler.GetHostsPath(), name, description, tags, true)
 	if err != nil {
-		h.logger.Error("Failed to create backup", "error", err)
-		return &XPCResponse{
-			Success: false,
-			Error:   fmt.Sprintf("Failed to create backup: %v", err),
-		}
+		log.Error("Failed to create backup", "error", err)
+		return NewErrorResponse(fmt.Errorf("Failed to create backup: %w", err), errors.ErrCodeBackupFailed, errors.ErrorTypeFileSystem)
 	}
 
 	return &XPCResponse{
@@ -270,7 +414,8 @@ func (h *HostsHelper) handleBackupHosts(req *XPCRequest) *XPCResponse {
 
 // handleRestoreHosts 处理恢复hosts文件请求
 func (h *HostsHelper) handleRestoreHosts(req *XPCRequest) *XPCResponse {
-	h.logger.Info("Handling restore hosts request")
+	log := h.requestLogger(req)
+	log.Info("Handling restore hosts request")
 
 	// 获取备份ID
 	backupID, ok := req.Parameters["backup_id"].(string)
@@ -278,29 +423,23 @@ func (h *HostsHelper) handleRestoreHosts(req *XPCRequest) *XPCResponse {
 		// 兼容旧的backup_path参数
 		backupPath, pathOk := req.Parameters["backup_path"].(string)
 		if !pathOk {
-			return &XPCResponse{
-				Success: false,
-				Error:   "backup_id or backup_path parameter is required",
-			}
+			return newValidationResponse("backup_id or backup_path parameter is required")
 		}
 		// 如果提供的是路径，尝试从路径中提取ID
 		backupID = filepath.Base(strings.TrimSuffix(backupPath, ".backup"))
 	}
 
-	// 获取目标路径（可选）
-	targetPath := "/etc/hosts" // 默认恢复到原位置
-	if target, ok := req.Parameters["target_path"].(string); ok && target != "" {
-		targetPath = target
+	// 只能恢复到Helper管理的hosts文件，Helper以root运行，任意目标路径会覆盖系统中的其他文件
+	targetPath := h.hostsHandler.GetHostsPath()
+	if target, ok := req.Parameters["target_path"].(string); ok && target != "" && filepath.Clean(target) != filepath.Clean(targetPath) {
+		return newValidationResponse("target_path must be the hosts file: %s", targetPath)
 	}
 
 	// 恢复备份
 	err := h.backupMgr.RestoreBackup(backupID, targetPath)
 	if err != nil {
-		h.logger.Error("Failed to restore backup", "backup_id", backupID, "error", err)
-		return &XPCResponse{
-			Success: false,
-			Error:   fmt.Sprintf("Failed to restore backup: %v", err),
-		}
+		log.Error("Failed to restore backup", "backup_id", backupID, "error", err)
+		return NewErrorResponse(fmt.Errorf("Failed to restore backup: %w", err), errors.ErrCodeRestoreFailed, errors.ErrorTypeFileSystem)
 	}
 
 	return &XPCResponse{
@@ -316,10 +455,7 @@ func (h *HostsHelper) handleRestoreHosts(req *XPCRequest) *XPCResponse {
 // handleValidateHosts 处理验证hosts文件请求
 func (h *HostsHelper) handleValidateHosts(req *XPCRequest) *XPCResponse {
 	if err := h.hostsHandler.ValidateHosts(); err != nil {
-		return &XPCResponse{
-			Success: false,
-			Error:   fmt.Sprintf("hosts file validation failed: %v", err),
-		}
+		return NewErrorResponse(fmt.Errorf("hosts file validation failed: %w", err), errors.ErrCodeHostsValidationFailed, errors.ErrorTypeValidation)
 	}
 
 	return &XPCResponse{
@@ -328,19 +464,57 @@ func (h *HostsHelper) handleValidateHosts(req *XPCRequest) *XPCResponse {
 	}
 }
 
+// handleReadHosts 处理读取hosts文件请求
+func (h *HostsHelper) handleReadHosts(req *XPCRequest) *XPCResponse {
+	content, err := h.hostsHandler.ReadHosts()
+	if err != nil {
+		return NewErrorResponse(fmt.Errorf("failed to read hosts file: %w", err), errors.ErrCodeFileReadFailed, errors.ErrorTypeFileSystem)
+	}
+
+	return &XPCResponse{
+		Success: true,
+		Data: map[string]interface{}{
+			"hosts_path":          content.HostsPath,
+			"lines":               content.Lines,
+			"has_managed_section": content.HasManagedSection,
+			"managed_section":     content.ManagedSection,
+			"managed_entries":     content.ManagedEntries,
+		},
+	}
+}
+
 // handleGetStatus 处理获取状态请求
 func (h *HostsHelper) handleGetStatus(req *XPCRequest) *XPCResponse {
-	status := map[string]interface{}{
-		"running":     h.IsRunning(),
-		"service":     h.serviceName,
-		"uptime":      time.Since(time.Now()).String(), // 这里应该记录启动时间
-		"hosts_path":  h.hostsHandler.GetHostsPath(),
+	return &XPCResponse{
+		Success: true,
+		Data:    h.Status().ToMap(),
 	}
+}
 
+// handleGetClientAccess 处理获取白名单、黑名单和速率限制状态请求
+func (h *HostsHelper) handleGetClientAccess(req *XPCRequest) *XPCResponse {
+	access := h.securityMgr.ClientAccess()
 	return &XPCResponse{
 		Success: true,
-		Data:    status,
+		Data: map[string]interface{}{
+			"whitelist":    access.Whitelist,
+			"blacklist":    access.Blacklist,
+			"rate_limited": access.RateLimited,
+		},
+	}
+}
+
+// handleUpdateClientAccess 处理修改白名单或黑名单请求，无需重启Helper即可解封客户端
+func (h *HostsHelper) handleUpdateClientAccess(req *XPCRequest) *XPCResponse {
+	action, _ := req.Parameters["action"].(string)
+	clientID, _ := req.Parameters["client_id"].(string)
+
+	if err := h.securityMgr.UpdateClientAccess(ClientAccessAction(action), clientID); err != nil {
+		return NewErrorResponse(err, errors.ErrCodeXPCInvalidRequest, errors.ErrorTypeValidation)
 	}
+
+	h.requestLogger(req).Info("Client access updated", "action", action, "client_id", clientID, "by", req.ClientID)
+	return h.handleGetClientAccess(req)
 }
 
 // convertToHostEntries 转换接口数据为HostEntry结构
@@ -0,0 +1,108 @@
+package helper
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/flyhigher139/mhost/pkg/errors"
+)
+
+const (
+	// DefaultServiceName 默认的Helper服务名称
+	DefaultServiceName = "com.mhost.helper"
+	// InstallDir 特权Helper安装目录
+	InstallDir = "/Library/PrivilegedHelperTools"
+)
+
+// InstallPath 获取Helper可执行文件的安装路径
+func InstallPath(serviceName string) string {
+	return filepath.Join(InstallDir, serviceName)
+}
+
+// IsInstalled 检查Helper是否已安装（安装路径存在可执行文件）
+func IsInstalled(serviceName string) bool {
+	info, err := os.Stat(InstallPath(serviceName))
+	if err != nil || info.IsDir() {
+		return false
+	}
+	return info.Mode().Perm()&0111 != 0
+}
+
+// Install 校验Helper签名后安装或升级到特权目录，签名标识和团队ID只使用构建时注入的值，
+// 构建时没有注入TeamID时拒绝安装；签名不符合预期时不做任何修改
+func Install(sourcePath string, verifier SignatureVerifier) (*SignatureInfo, error) {
+	return install(sourcePath, InstallPath(DefaultServiceName), DefaultServiceName, TeamID, verifier)
+}
+
+// install 先将Helper复制到目标目录的临时文件并校验该副本，再原子替换目标文件，
+// 避免校验后、复制前源文件被替换
+func install(sourcePath, dst, identifier, teamID string, verifier SignatureVerifier) (*SignatureInfo, error) {
+	if teamID == "" {
+		return nil, errors.NewSecurityError(errors.ErrCodeHelperInstallFailed,
+			"helper team ID was not set at build time, refusing to install", map[string]interface{}{"path": sourcePath}, nil)
+	}
+
+	tempPath, err := stageExecutable(sourcePath, filepath.Dir(dst))
+	if err != nil {
+		return nil, errors.NewSystemError(errors.ErrCodeHelperInstallFailed, "failed to install helper", err)
+	}
+	defer os.Remove(tempPath)
+
+	info, err := VerifyHelper(verifier, tempPath, identifier, teamID)
+	if err != nil {
+		return info, err
+	}
+
+	if err := os.Rename(tempPath, dst); err != nil {
+		return info, errors.NewSystemError(errors.ErrCodeHelperInstallFailed, "failed to install helper",
+			fmt.Errorf("failed to replace %s: %w", dst, err))
+	}
+	info.Path = dst
+	return info, nil
+}
+
+// Uninstall 校验已安装文件确为mHost Helper后将其删除，避免误删同名的其他程序
+func Uninstall(serviceName, teamID string, verifier SignatureVerifier) error {
+	path := InstallPath(serviceName)
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return errors.NewSystemError(errors.ErrCodeHelperNotInstalled, "helper is not installed", err)
+	}
+
+	if _, err := VerifyHelper(verifier, path, serviceName, teamID); err != nil {
+		return err
+	}
+
+	if err := os.Remove(path); err != nil {
+		return errors.NewSystemError(errors.ErrCodeHelperUninstallFailed, "failed to remove helper", err)
+	}
+	return nil
+}
+
+// stageExecutable 将可执行文件复制到dir中的临时文件，返回临时文件路径
+func stageExecutable(src, dir string) (string, error) {
+	srcFile, err := os.Open(src)
+	if err != nil {
+		return "", err
+	}
+	defer srcFile.Close()
+
+	tempFile, err := os.CreateTemp(dir, ".helper-*")
+	if err != nil {
+		return "", err
+	}
+
+	_, err = io.Copy(tempFile, srcFile)
+	if err == nil {
+		err = tempFile.Chmod(0544)
+	}
+	if closeErr := tempFile.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(tempFile.Name())
+		return "", err
+	}
+	return tempFile.Name(), nil
+}
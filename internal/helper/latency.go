@@ -0,0 +1,105 @@
+package helper
+
+import (
+	"math"
+	"time"
+)
+
+// 按操作统计延迟时最多跟踪的操作数，其余操作计入otherOperations
+const (
+	maxTrackedOperations = 32
+	otherOperations      = "other"
+)
+
+// latencyBuckets 延迟直方图各桶的上限（毫秒），超过最后一个上限的请求计入溢出桶
+var latencyBuckets = []float64{0.5, 1, 2, 5, 10, 25, 50, 100, 250, 500, 1000, 2500, 5000, 10000, 30000}
+
+// OperationStats 单个操作的请求数和延迟分位数
+type OperationStats struct {
+	Count          int64   `json:"count"`
+	Failed         int64   `json:"failed"`
+	AverageLatency float64 `json:"average_latency_ms"`
+	P50Latency     float64 `json:"p50_latency_ms"`
+	P95Latency     float64 `json:"p95_latency_ms"`
+	P99Latency     float64 `json:"p99_latency_ms"`
+	MaxLatency     float64 `json:"max_latency_ms"`
+}
+
+// latencyHistogram 固定分桶的延迟直方图，内存占用与请求数无关。
+// 分位数取所在桶的上限，并且不超过观测到的最大值
+type latencyHistogram struct {
+	counts []int64
+	count  int64
+	failed int64
+	sum    float64
+	max    float64
+}
+
+// newLatencyHistogram 创建延迟直方图
+func newLatencyHistogram() *latencyHistogram {
+	return &latencyHistogram{counts: make([]int64, len(latencyBuckets)+1)}
+}
+
+// observe 记录一次请求的延迟
+func (h *latencyHistogram) observe(latency time.Duration, failed bool) {
+	ms := float64(latency.Nanoseconds()) / 1e6
+	bucket := len(latencyBuckets)
+	for i, bound := range latencyBuckets {
+		if ms <= bound {
+			bucket = i
+			break
+		}
+	}
+	h.counts[bucket]++
+	h.count++
+	h.sum += ms
+	if ms > h.max {
+		h.max = ms
+	}
+	if failed {
+		h.failed++
+	}
+}
+
+// percentile 计算分位数（0-1），没有记录时返回0
+func (h *latencyHistogram) percentile(p float64) float64 {
+	if h.count == 0 {
+		return 0
+	}
+	rank := int64(math.Ceil(p * float64(h.count)))
+	if rank < 1 {
+		rank = 1
+	}
+	var cumulative int64
+	for i, n := range h.counts {
+		cumulative += n
+		if cumulative >= rank {
+			if i < len(latencyBuckets) && latencyBuckets[i] < h.max {
+				return latencyBuckets[i]
+			}
+			return h.max
+		}
+	}
+	return h.max
+}
+
+// average 平均延迟，没有记录时返回0
+func (h *latencyHistogram) average() float64 {
+	if h.count == 0 {
+		return 0
+	}
+	return h.sum / float64(h.count)
+}
+
+// stats 汇总为操作统计
+func (h *latencyHistogram) stats() *OperationStats {
+	return &OperationStats{
+		Count:          h.count,
+		Failed:         h.failed,
+		AverageLatency: h.average(),
+		P50Latency:     h.percentile(0.50),
+		P95Latency:     h.percentile(0.95),
+		P99Latency:     h.percentile(0.99),
+		MaxLatency:     h.max,
+	}
+}
@@ -0,0 +1,82 @@
+package helper
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/flyhigher139/mhost/pkg/logger"
+)
+
+// TestLatencyHistogram 测试分位数取所在桶的上限且不超过最大值
+func TestLatencyHistogram(t *testing.T) {
+	h := newLatencyHistogram()
+	assert.Equal(t, 0.0, h.percentile(0.5))
+
+	for i := 0; i < 90; i++ {
+		h.observe(3*time.Millisecond, false)
+	}
+	for i := 0; i < 9; i++ {
+		h.observe(80*time.Millisecond, false)
+	}
+	h.observe(40*time.Second, true)
+
+	stats := h.stats()
+	assert.Equal(t, int64(100), stats.Count)
+	assert.Equal(t, int64(1), stats.Failed)
+	assert.Equal(t, 5.0, stats.P50Latency)
+	assert.Equal(t, 100.0, stats.P95Latency)
+	assert.Equal(t, 100.0, stats.P99Latency)
+	assert.Equal(t, 40000.0, stats.MaxLatency)
+	assert.Equal(t, 40000.0, h.percentile(1))
+	assert.InDelta(t, (90*3+9*80+40000)/100.0, stats.AverageLatency, 0.001)
+}
+
+// TestXPCServerLatencyStats 测试按操作统计请求数和延迟，并可从get_status响应中还原
+func TestXPCServerLatencyStats(t *testing.T) {
+	server, err := NewXPCServerImpl(DefaultServiceName, logger.NewEnhancedLogger(logger.LogLevelError, false))
+	require.NoError(t, err)
+	server.handler = func(req *XPCRequest) *XPCResponse {
+		time.Sleep(time.Millisecond)
+		if req.Operation == "write_hosts" {
+			return &XPCResponse{Success: false, Error: "denied"}
+		}
+		return &XPCResponse{Success: true}
+	}
+
+	send := func(operation string) {
+		message, err := json.Marshal(newTestRequest("client", operation, nil))
+		require.NoError(t, err)
+		server.handleMessage(message)
+	}
+	for i := 0; i < 3; i++ {
+		send("get_status")
+	}
+	send("write_hosts")
+	for i := 0; i < maxTrackedOperations+5; i++ {
+		send(fmt.Sprintf("op_%d", i))
+	}
+
+	stats := server.GetStats()
+	assert.Equal(t, int64(3+1+maxTrackedOperations+5), stats.TotalRequests)
+	require.Contains(t, stats.Operations, "get_status")
+	assert.Equal(t, int64(3), stats.Operations["get_status"].Count)
+	assert.Equal(t, int64(1), stats.Operations["write_hosts"].Failed)
+	assert.Len(t, stats.Operations, maxTrackedOperations+1)
+	assert.Equal(t, int64(7), stats.Operations[otherOperations].Count)
+	assert.Greater(t, stats.P99Latency, 0.0)
+	assert.GreaterOrEqual(t, stats.P99Latency, stats.P50Latency)
+
+	decoded, err := DecodeXPCStats((&HelperStatus{XPC: stats}).ToMap())
+	require.NoError(t, err)
+	assert.Equal(t, stats.Operations["get_status"], decoded.Operations["get_status"])
+	assert.Equal(t, stats.P95Latency, decoded.P95Latency)
+
+	legacy, err := DecodeXPCStats(map[string]interface{}{"running": true})
+	assert.NoError(t, err)
+	assert.Nil(t, legacy)
+}
@@ -0,0 +1,144 @@
+package helper
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// 请求并发限制的默认参数
+const (
+	DefaultMaxConcurrent = 4
+	// DefaultQueueTimeout 请求没有截止时间时等待执行的最长时间
+	DefaultQueueTimeout = 30 * time.Second
+)
+
+// exclusiveOperations 修改hosts文件或备份的操作，需要独占执行，不能与其他请求交错
+var exclusiveOperations = map[string]bool{
+	"write_hosts":   true,
+	"restore_hosts": true,
+	"backup_hosts":  true,
+}
+
+// IsExclusiveOperation 判断操作是否需要独占执行
+func IsExclusiveOperation(operation string) bool {
+	return exclusiveOperations[operation]
+}
+
+// RequestLimiterStats 请求并发限制的统计信息
+type RequestLimiterStats struct {
+	MaxConcurrent int   `json:"max_concurrent"`
+	Active        int   `json:"active"`
+	Waiting       int   `json:"waiting"`
+	Rejected      int64 `json:"rejected"`
+}
+
+// RequestLimiter 限制同时处理的XPC请求数。只读请求可以并发执行，
+// 修改hosts文件的请求独占执行，等待所有正在处理的请求完成后才开始
+type RequestLimiter struct {
+	slots     chan struct{}
+	exclusive sync.RWMutex
+
+	mu       sync.Mutex
+	active   int
+	waiting  int
+	rejected int64
+}
+
+// NewRequestLimiter 创建请求并发限制，maxConcurrent不大于0时使用默认值
+func NewRequestLimiter(maxConcurrent int) *RequestLimiter {
+	if maxConcurrent <= 0 {
+		maxConcurrent = DefaultMaxConcurrent
+	}
+	return &RequestLimiter{slots: make(chan struct{}, maxConcurrent)}
+}
+
+// Acquire 等待执行请求的名额，ctx结束前未获得名额时返回错误；成功时返回释放名额的函数
+func (l *RequestLimiter) Acquire(ctx context.Context, operation string) (func(), error) {
+	l.updateWaiting(1)
+	defer l.updateWaiting(-1)
+
+	select {
+	case l.slots <- struct{}{}:
+	case <-ctx.Done():
+		l.reject()
+		return nil, fmt.Errorf("helper is busy: %w", ctx.Err())
+	}
+
+	// sync.RWMutex不支持取消，在单独的goroutine中加锁，超时后由该goroutine负责释放
+	exclusive := IsExclusiveOperation(operation)
+	locked := make(chan struct{})
+	abandoned := make(chan struct{})
+	go func() {
+		if exclusive {
+			l.exclusive.Lock()
+		} else {
+			l.exclusive.RLock()
+		}
+		select {
+		case locked <- struct{}{}:
+		case <-abandoned:
+			l.unlock(exclusive)
+		}
+	}()
+
+	select {
+	case <-locked:
+	case <-ctx.Done():
+		close(abandoned)
+		<-l.slots
+		l.reject()
+		return nil, fmt.Errorf("helper is busy: %w", ctx.Err())
+	}
+
+	l.mu.Lock()
+	l.active++
+	l.mu.Unlock()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			l.mu.Lock()
+			l.active--
+			l.mu.Unlock()
+			l.unlock(exclusive)
+			<-l.slots
+		})
+	}, nil
+}
+
+// Stats 获取并发限制的统计信息
+func (l *RequestLimiter) Stats() *RequestLimiterStats {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return &RequestLimiterStats{
+		MaxConcurrent: cap(l.slots),
+		Active:        l.active,
+		Waiting:       l.waiting,
+		Rejected:      l.rejected,
+	}
+}
+
+// unlock 释放读锁或独占锁
+func (l *RequestLimiter) unlock(exclusive bool) {
+	if exclusive {
+		l.exclusive.Unlock()
+	} else {
+		l.exclusive.RUnlock()
+	}
+}
+
+// updateWaiting 更新等待中的请求数
+func (l *RequestLimiter) updateWaiting(delta int) {
+	l.mu.Lock()
+	l.waiting += delta
+	l.mu.Unlock()
+}
+
+// reject 记录一次因等待超时被拒绝的请求
+func (l *RequestLimiter) reject() {
+	l.mu.Lock()
+	l.rejected++
+	l.mu.Unlock()
+}
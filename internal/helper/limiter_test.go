@@ -0,0 +1,74 @@
+package helper
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestRequestLimiter 测试并发上限、写操作独占执行以及等待超时的请求被拒绝
+func TestRequestLimiter(t *testing.T) {
+	t.Run("concurrency", func(t *testing.T) {
+		limiter := NewRequestLimiter(2)
+
+		var current, peak int32
+		var wg sync.WaitGroup
+		for i := 0; i < 8; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				release, err := limiter.Acquire(context.Background(), "read_hosts")
+				require.NoError(t, err)
+				defer release()
+
+				n := atomic.AddInt32(&current, 1)
+				for {
+					p := atomic.LoadInt32(&peak)
+					if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+						break
+					}
+				}
+				time.Sleep(5 * time.Millisecond)
+				atomic.AddInt32(&current, -1)
+			}()
+		}
+		wg.Wait()
+
+		assert.Equal(t, int32(2), atomic.LoadInt32(&peak))
+		assert.Equal(t, 0, limiter.Stats().Active)
+	})
+
+	t.Run("exclusive", func(t *testing.T) {
+		limiter := NewRequestLimiter(4)
+
+		release, err := limiter.Acquire(context.Background(), "read_hosts")
+		require.NoError(t, err)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+		_, err = limiter.Acquire(ctx, "write_hosts")
+		assert.Error(t, err, "write must wait for running requests")
+		assert.Equal(t, int64(1), limiter.Stats().Rejected)
+
+		release()
+		release() // 重复释放无副作用
+
+		releaseWrite, err := limiter.Acquire(context.Background(), "write_hosts")
+		require.NoError(t, err)
+		ctx, cancel = context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+		_, err = limiter.Acquire(ctx, "get_status")
+		assert.Error(t, err, "requests must wait for a running write")
+		releaseWrite()
+
+		releaseRead, err := limiter.Acquire(context.Background(), "get_status")
+		require.NoError(t, err)
+		releaseRead()
+		assert.Equal(t, &RequestLimiterStats{MaxConcurrent: 4, Rejected: 2}, limiter.Stats())
+	})
+}
@@ -0,0 +1,135 @@
+package helper
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+	"sync"
+	"time"
+)
+
+// 自动重启的默认参数
+const (
+	DefaultMaxRestarts    = 5
+	DefaultRestartBackoff = time.Second
+	// maxCrashRecords 保留的崩溃记录数量上限
+	maxCrashRecords = 20
+)
+
+// CrashRecord 后台组件的一次panic记录
+type CrashRecord struct {
+	Component string    `json:"component"`
+	Panic     string    `json:"panic"`
+	Stack     string    `json:"stack,omitempty"`
+	Time      time.Time `json:"time"`
+	// Restarted 崩溃后是否自动重启了该组件
+	Restarted bool `json:"restarted"`
+}
+
+// Supervisor 运行Helper的后台goroutine：恢复panic以免特权守护进程整体退出，
+// 将崩溃写入审计日志，并可按策略自动重启崩溃的组件
+type Supervisor struct {
+	logger      Logger
+	auditLogger *AuditLogger
+
+	mu          sync.Mutex
+	restart     bool
+	maxRestarts int
+	backoff     time.Duration
+	crashes     []CrashRecord
+}
+
+// NewSupervisor 创建Supervisor，auditLogger为nil时只写入普通日志；默认不自动重启
+func NewSupervisor(logger Logger, auditLogger *AuditLogger) *Supervisor {
+	return &Supervisor{
+		logger:      logger,
+		auditLogger: auditLogger,
+		maxRestarts: DefaultMaxRestarts,
+		backoff:     DefaultRestartBackoff,
+	}
+}
+
+// SetRestartPolicy 设置自动重启策略：每个组件最多重启maxRestarts次，每次重启前等待backoff且逐次加倍
+func (s *Supervisor) SetRestartPolicy(enabled bool, maxRestarts int, backoff time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.restart = enabled
+	s.maxRestarts = maxRestarts
+	if backoff > 0 {
+		s.backoff = backoff
+	}
+}
+
+// Go 在新的goroutine中运行组件。fn正常返回时结束；panic时恢复并记录，
+// 启用自动重启且未达到次数上限时在退避后重新运行，ctx取消后不再重启
+func (s *Supervisor) Go(ctx context.Context, component string, fn func(ctx context.Context)) {
+	go func() {
+		restarts := 0
+		for {
+			value, stack, crashed := s.run(ctx, fn)
+			if !crashed {
+				return
+			}
+
+			s.mu.Lock()
+			restart := s.restart && restarts < s.maxRestarts
+			backoff := s.backoff << restarts
+			s.mu.Unlock()
+			s.recordCrash(component, value, stack, restart)
+			if !restart {
+				s.logger.Error("Component stopped after panic", "component", component, "restarts", restarts)
+				return
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(backoff):
+			}
+			restarts++
+			s.logger.Info("Restarting component after panic", "component", component, "restart", restarts)
+		}
+	}()
+}
+
+// run 运行一次组件，发生panic时返回panic的值和调用栈
+func (s *Supervisor) run(ctx context.Context, fn func(ctx context.Context)) (value interface{}, stack []byte, crashed bool) {
+	defer func() {
+		if r := recover(); r != nil {
+			value, stack, crashed = r, debug.Stack(), true
+		}
+	}()
+	fn(ctx)
+	return nil, nil, false
+}
+
+// recordCrash 保存崩溃记录并写入审计日志
+func (s *Supervisor) recordCrash(component string, value interface{}, stack []byte, restarted bool) {
+	record := CrashRecord{
+		Component: component,
+		Panic:     fmt.Sprint(value),
+		Stack:     string(stack),
+		Time:      time.Now(),
+		Restarted: restarted,
+	}
+
+	s.mu.Lock()
+	s.crashes = append(s.crashes, record)
+	if len(s.crashes) > maxCrashRecords {
+		s.crashes = s.crashes[len(s.crashes)-maxCrashRecords:]
+	}
+	s.mu.Unlock()
+
+	s.logger.Error("Recovered from panic", "component", component, "panic", record.Panic, "stack", record.Stack)
+	if s.auditLogger != nil {
+		s.auditLogger.LogCrash(component, record.Panic, restarted)
+	}
+}
+
+// Crashes 获取最近的崩溃记录，按时间先后排列
+func (s *Supervisor) Crashes() []CrashRecord {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]CrashRecord(nil), s.crashes...)
+}
@@ -0,0 +1,73 @@
+package helper
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/flyhigher139/mhost/pkg/logger"
+)
+
+// TestSupervisor 测试组件panic后被恢复、记录，并按策略自动重启或停止
+func TestSupervisor(t *testing.T) {
+	log := logger.NewEnhancedLogger(logger.LogLevelError, false)
+	auditLogger, err := NewAuditLogger("", log)
+	require.NoError(t, err)
+
+	t.Run("restart", func(t *testing.T) {
+		supervisor := NewSupervisor(log, auditLogger)
+		supervisor.SetRestartPolicy(true, 2, time.Millisecond)
+
+		var runs int32
+		done := make(chan struct{})
+		supervisor.Go(context.Background(), "worker", func(context.Context) {
+			if atomic.AddInt32(&runs, 1) < 3 {
+				panic("boom")
+			}
+			close(done)
+		})
+
+		select {
+		case <-done:
+		case <-time.After(time.Second):
+			t.Fatal("component was not restarted")
+		}
+		crashes := supervisor.Crashes()
+		require.Len(t, crashes, 2)
+		assert.Equal(t, "worker", crashes[0].Component)
+		assert.Equal(t, "boom", crashes[0].Panic)
+		assert.NotEmpty(t, crashes[0].Stack)
+		assert.True(t, crashes[1].Restarted)
+	})
+
+	t.Run("limit", func(t *testing.T) {
+		supervisor := NewSupervisor(log, nil)
+		supervisor.SetRestartPolicy(true, 1, time.Millisecond)
+
+		var runs int32
+		supervisor.Go(context.Background(), "worker", func(context.Context) {
+			atomic.AddInt32(&runs, 1)
+			panic("always")
+		})
+
+		require.Eventually(t, func() bool { return len(supervisor.Crashes()) == 2 }, time.Second, time.Millisecond)
+		time.Sleep(20 * time.Millisecond)
+		assert.Equal(t, int32(2), atomic.LoadInt32(&runs))
+		crashes := supervisor.Crashes()
+		assert.True(t, crashes[0].Restarted)
+		assert.False(t, crashes[1].Restarted)
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		supervisor := NewSupervisor(log, nil)
+
+		supervisor.Go(context.Background(), "worker", func(context.Context) { panic("once") })
+
+		require.Eventually(t, func() bool { return len(supervisor.Crashes()) == 1 }, time.Second, time.Millisecond)
+		assert.False(t, supervisor.Crashes()[0].Restarted)
+	})
+}
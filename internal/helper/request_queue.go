@@ -0,0 +1,233 @@
+package helper
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/flyhigher139/mhost/pkg/logger"
+)
+
+// DefaultQueueSize 请求队列默认最多缓存的请求数
+const DefaultQueueSize = 50
+
+// ErrNotQueueable 操作不允许在Helper不可用时排队
+var ErrNotQueueable = errors.New("operation cannot be queued")
+
+// QueueableOperations 可以在Helper不可用时排队、连接恢复后再发送的非紧急操作
+var QueueableOperations = map[string]bool{
+	"backup_hosts": true,
+	"get_status":   true, // 审计心跳
+}
+
+// requestSender 发送XPC请求，XPCClient实现了该接口
+type requestSender interface {
+	Connect() error
+	IsConnected() bool
+	SendRequest(ctx context.Context, operation string, params map[string]interface{}) (*XPCResponse, error)
+}
+
+// QueuedRequest 等待发送的请求
+type QueuedRequest struct {
+	Operation  string                 `json:"operation"`
+	Parameters map[string]interface{} `json:"parameters,omitempty"`
+	QueuedAt   time.Time              `json:"queued_at"`
+	LastError  string                 `json:"last_error"`
+	// RequestID 首次发送时的请求ID，重试时沿用，便于在Helper日志中关联
+	RequestID string `json:"request_id,omitempty"`
+}
+
+// RequestQueue 客户端请求队列：Helper暂时不可用时缓存非紧急操作，连接恢复后按顺序发送
+type RequestQueue struct {
+	client  requestSender
+	maxSize int
+
+	mu       sync.Mutex
+	pending  []QueuedRequest
+	dropped  int
+	onChange func(pending int)
+	stopChan chan struct{}
+	flushing bool
+}
+
+// NewRequestQueue 创建请求队列，maxSize不大于0时使用DefaultQueueSize
+func NewRequestQueue(client requestSender, maxSize int) *RequestQueue {
+	if maxSize <= 0 {
+		maxSize = DefaultQueueSize
+	}
+	return &RequestQueue{
+		client:  client,
+		maxSize: maxSize,
+	}
+}
+
+// Submit 立即发送请求；Helper不可用且操作可排队时缓存请求并返回queued为true
+func (q *RequestQueue) Submit(ctx context.Context, operation string, params map[string]interface{}) (resp *XPCResponse, queued bool, err error) {
+	requestID := logger.RequestIDFromContext(ctx)
+	if requestID == "" {
+		requestID = logger.NewRequestID()
+		ctx = logger.WithRequestID(ctx, requestID)
+	}
+
+	resp, err = q.send(ctx, operation, params)
+	if err == nil {
+		return resp, false, nil
+	}
+	if !QueueableOperations[operation] {
+		return nil, false, fmt.Errorf("%w: %s: %v", ErrNotQueueable, operation, err)
+	}
+
+	q.enqueue(QueuedRequest{
+		Operation:  operation,
+		Parameters: params,
+		QueuedAt:   time.Now(),
+		LastError:  err.Error(),
+		RequestID:  requestID,
+	})
+	return nil, true, nil
+}
+
+// send 在需要时重新连接并发送请求
+func (q *RequestQueue) send(ctx context.Context, operation string, params map[string]interface{}) (*XPCResponse, error) {
+	if !q.client.IsConnected() {
+		if err := q.client.Connect(); err != nil {
+			return nil, err
+		}
+	}
+	return q.client.SendRequest(ctx, operation, params)
+}
+
+// enqueue 缓存请求，队列已满时丢弃最早的请求
+func (q *RequestQueue) enqueue(req QueuedRequest) {
+	q.mu.Lock()
+	q.pending = append(q.pending, req)
+	if len(q.pending) > q.maxSize {
+		q.dropped += len(q.pending) - q.maxSize
+		q.pending = q.pending[len(q.pending)-q.maxSize:]
+	}
+	pending, onChange := len(q.pending), q.onChange
+	q.mu.Unlock()
+
+	if onChange != nil {
+		onChange(pending)
+	}
+}
+
+// Flush 按顺序发送缓存的请求，遇到Helper不可用时停止并保留剩余请求，返回成功发送的请求数
+func (q *RequestQueue) Flush(ctx context.Context) (int, error) {
+	q.mu.Lock()
+	if q.flushing || len(q.pending) == 0 {
+		q.mu.Unlock()
+		return 0, nil
+	}
+	q.flushing = true
+	q.mu.Unlock()
+
+	defer func() {
+		q.mu.Lock()
+		q.flushing = false
+		q.mu.Unlock()
+	}()
+
+	sent := 0
+	for {
+		q.mu.Lock()
+		if len(q.pending) == 0 {
+			q.mu.Unlock()
+			return sent, nil
+		}
+		req := q.pending[0]
+		q.mu.Unlock()
+
+		sendCtx := ctx
+		if req.RequestID != "" {
+			sendCtx = logger.WithRequestID(ctx, req.RequestID)
+		}
+		if _, err := q.send(sendCtx, req.Operation, req.Parameters); err != nil {
+			q.mu.Lock()
+			if len(q.pending) > 0 {
+				q.pending[0].LastError = err.Error()
+			}
+			q.mu.Unlock()
+			return sent, err
+		}
+
+		// Helper已收到请求，无论处理成功与否都不再重试
+		q.mu.Lock()
+		q.pending = q.pending[1:]
+		pending, onChange := len(q.pending), q.onChange
+		q.mu.Unlock()
+		sent++
+
+		if onChange != nil {
+			onChange(pending)
+		}
+	}
+}
+
+// Pending 获取等待发送的请求
+func (q *RequestQueue) Pending() []QueuedRequest {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return append([]QueuedRequest(nil), q.pending...)
+}
+
+// Dropped 因队列已满而丢弃的请求数
+func (q *RequestQueue) Dropped() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.dropped
+}
+
+// OnChange 设置等待发送的请求数变化时的回调
+func (q *RequestQueue) OnChange(callback func(pending int)) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.onChange = callback
+}
+
+// Start 按固定间隔检查连接，恢复后发送缓存的请求
+func (q *RequestQueue) Start(interval time.Duration) error {
+	if interval <= 0 {
+		return fmt.Errorf("invalid flush interval: %v", interval)
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.stopChan != nil {
+		return fmt.Errorf("request queue already started")
+	}
+	q.stopChan = make(chan struct{})
+
+	go func(stop chan struct{}) {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				ctx, cancel := context.WithTimeout(context.Background(), interval)
+				q.Flush(ctx)
+				cancel()
+			}
+		}
+	}(q.stopChan)
+
+	return nil
+}
+
+// Stop 停止定时发送
+func (q *RequestQueue) Stop() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.stopChan != nil {
+		close(q.stopChan)
+		q.stopChan = nil
+	}
+}
@@ -0,0 +1,86 @@
+package helper_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/flyhigher139/mhost/internal/fakes"
+	"github.com/flyhigher139/mhost/internal/helper"
+)
+
+// TestRequestQueue 测试Helper不可用时缓存非紧急操作、队列有界以及恢复后按顺序发送
+func TestRequestQueue(t *testing.T) {
+	sender := fakes.NewXPCClient(nil)
+	sender.SetAvailable(false)
+	queue := helper.NewRequestQueue(sender, 2)
+
+	var changes []int
+	queue.OnChange(func(pending int) { changes = append(changes, pending) })
+
+	_, queued, err := queue.Submit(context.Background(), "write_hosts", nil)
+	assert.ErrorIs(t, err, helper.ErrNotQueueable)
+	assert.False(t, queued)
+
+	for _, operation := range []string{"get_status", "backup_hosts", "backup_hosts"} {
+		_, queued, err = queue.Submit(context.Background(), operation, nil)
+		require.NoError(t, err)
+		assert.True(t, queued)
+	}
+
+	pending := queue.Pending()
+	require.Len(t, pending, 2)
+	assert.Equal(t, "backup_hosts", pending[0].Operation)
+	assert.Equal(t, "helper unavailable", pending[0].LastError)
+	assert.NotEmpty(t, pending[0].RequestID)
+	assert.NotEqual(t, pending[0].RequestID, pending[1].RequestID)
+	assert.Equal(t, 1, queue.Dropped())
+
+	sent, err := queue.Flush(context.Background())
+	assert.Error(t, err)
+	assert.Equal(t, 0, sent)
+
+	sender.SetAvailable(true)
+	sent, err = queue.Flush(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 2, sent)
+	assert.Empty(t, queue.Pending())
+	assert.Equal(t, []string{"backup_hosts", "backup_hosts"}, sender.Operations())
+	assert.Equal(t, []int{1, 2, 2, 1, 0}, changes)
+
+	resp, queued, err := queue.Submit(context.Background(), "write_hosts", nil)
+	require.NoError(t, err)
+	assert.False(t, queued)
+	assert.True(t, resp.Success)
+}
+
+// TestRequestQueueStart 测试定时检查连接并发送缓存的请求
+func TestRequestQueueStart(t *testing.T) {
+	sender := fakes.NewXPCClient(nil)
+	sender.SetAvailable(false)
+	queue := helper.NewRequestQueue(sender, 0)
+	_, _, err := queue.Submit(context.Background(), "backup_hosts", nil)
+	require.NoError(t, err)
+
+	flushed := make(chan struct{})
+	queue.OnChange(func(pending int) {
+		if pending == 0 {
+			close(flushed)
+		}
+	})
+
+	assert.Error(t, queue.Start(0))
+	sender.SetAvailable(true)
+	require.NoError(t, queue.Start(10*time.Millisecond))
+	assert.Error(t, queue.Start(time.Second))
+	defer queue.Stop()
+
+	select {
+	case <-flushed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected queued request to be flushed")
+	}
+}
@@ -5,12 +5,13 @@ import (
 	"fmt"
 	"net"
 	"os"
-	"regexp"
+	"sort"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/flyhigher139/mhost/pkg/errors"
+	"github.com/flyhigher139/mhost/pkg/hostsfile"
 	"github.com/flyhigher139/mhost/pkg/logger"
 )
 
@@ -45,6 +46,43 @@ type RateLimiter struct {
 	mu        sync.Mutex
 }
 
+// ClientAccessAction 修改白名单/黑名单的操作
+type ClientAccessAction string
+
+const (
+	// ActionWhitelistAdd 加入白名单，跳过速率限制
+	ActionWhitelistAdd ClientAccessAction = "whitelist_add"
+	// ActionWhitelistRemove 移出白名单
+	ActionWhitelistRemove ClientAccessAction = "whitelist_remove"
+	// ActionBlacklistAdd 加入黑名单
+	ActionBlacklistAdd ClientAccessAction = "blacklist_add"
+	// ActionBlacklistRemove 移出黑名单（解封）
+	ActionBlacklistRemove ClientAccessAction = "blacklist_remove"
+	// ActionClearBlacklist 清空黑名单
+	ActionClearBlacklist ClientAccessAction = "blacklist_clear"
+)
+
+// BlockedClient 黑名单中的客户端
+type BlockedClient struct {
+	ClientID string    `json:"client_id"`
+	Expiry   time.Time `json:"expiry"`
+}
+
+// RateLimitedClient 当前时间窗口内有请求的客户端
+type RateLimitedClient struct {
+	ClientID string `json:"client_id"`
+	Requests int    `json:"requests"`
+	Limit    int    `json:"limit"`
+	Limited  bool   `json:"limited"` // 是否已达到速率限制
+}
+
+// ClientAccess 客户端访问控制状态
+type ClientAccess struct {
+	Whitelist   []string            `json:"whitelist"`
+	Blacklist   []BlockedClient     `json:"blacklist"`
+	RateLimited []RateLimitedClient `json:"rate_limited"`
+}
+
 // SecurityViolation 安全违规记录
 type SecurityViolation struct {
 	ClientID    string    `json:"client_id"`
@@ -66,7 +104,10 @@ func NewSecurityManagerImpl(auditLogger *AuditLogger, logger logger.Logger) *Sec
 			"backup_hosts",
 			"restore_hosts",
 			"validate_hosts",
+			"read_hosts",
 			"get_status",
+			"get_client_access",
+			"update_client_access",
 		},
 		TrustedClients:    []string{},
 		MaxHostEntries:    1000,
@@ -91,20 +132,20 @@ func (s *SecurityManagerImpl) ValidateRequest(req *XPCRequest) error {
 
 	// 基本验证
 	if err := s.validateBasicRequest(req); err != nil {
-		s.logSecurityViolation(req.ClientID, "basic_validation", req.Operation, "high", err.Error())
-		return fmt.Errorf("basic validation failed: %w", err)
+		s.logSecurityViolation(req.ClientID, req.RequestID, "basic_validation", req.Operation, "high", err.Error())
+		return errors.WrapError(errors.ErrCodeXPCInvalidRequest, errors.ErrorTypeValidation, "basic validation failed", err)
 	}
 
 	// 检查黑名单
 	if s.isBlacklisted(req.ClientID) {
-		s.logSecurityViolation(req.ClientID, "blacklisted", req.Operation, "high", "Client is blacklisted")
+		s.logSecurityViolation(req.ClientID, req.RequestID, "blacklisted", req.Operation, "high", "Client is blacklisted")
 		s.logger.Warn("Client is blacklisted", "client_id", req.ClientID)
 		return errors.NewPermissionError(errors.ErrCodeClientBlacklisted, "client is blacklisted")
 	}
 
 	// 速率限制检查
 	if !s.checkRateLimit(req.ClientID) {
-		s.logSecurityViolation(req.ClientID, "rate_limit", req.Operation, "medium", "Rate limit exceeded")
+		s.logSecurityViolation(req.ClientID, req.RequestID, "rate_limit", req.Operation, "medium", "Rate limit exceeded")
 		s.addToBlacklist(req.ClientID)
 		s.logger.Warn("Rate limit exceeded", "client_id", req.ClientID)
 		return errors.NewPermissionError(errors.ErrCodeRateLimitExceeded, "rate limit exceeded")
@@ -112,15 +153,15 @@ func (s *SecurityManagerImpl) ValidateRequest(req *XPCRequest) error {
 
 	// 操作权限检查
 	if !s.isOperationAllowed(req.Operation) {
-		s.logSecurityViolation(req.ClientID, "unauthorized_operation", req.Operation, "high", "Operation not allowed")
+		s.logSecurityViolation(req.ClientID, req.RequestID, "unauthorized_operation", req.Operation, "high", "Operation not allowed")
 		s.logger.Warn("Operation not allowed", "operation", req.Operation, "client_id", req.ClientID)
 		return errors.NewPermissionError(errors.ErrCodeOperationNotAllowed, fmt.Sprintf("operation not allowed: %s", req.Operation))
 	}
 
 	// 参数验证
 	if err := s.validateParameters(req); err != nil {
-		s.logSecurityViolation(req.ClientID, "parameter_validation", req.Operation, "medium", err.Error())
-		return fmt.Errorf("parameter validation failed: %w", err)
+		s.logSecurityViolation(req.ClientID, req.RequestID, "parameter_validation", req.Operation, "medium", err.Error())
+		return errors.WrapError(errors.ErrCodeXPCInvalidRequest, errors.ErrorTypeValidation, "parameter validation failed", err)
 	}
 
 	s.logger.Debug("Request validation passed", "client", req.ClientID, "operation", req.Operation)
@@ -213,7 +254,9 @@ func (s *SecurityManagerImpl) validateParameters(req *XPCRequest) error {
 		return s.validateWriteHostsParams(req.Parameters)
 	case "restore_hosts":
 		return s.validateRestoreHostsParams(req.Parameters)
-	case "backup_hosts", "validate_hosts", "get_status":
+	case "update_client_access":
+		return s.validateClientAccessParams(req.Parameters)
+	case "backup_hosts", "validate_hosts", "read_hosts", "get_status", "get_client_access":
 		// 这些操作不需要特殊参数验证
 		return nil
 	default:
@@ -221,6 +264,31 @@ func (s *SecurityManagerImpl) validateParameters(req *XPCRequest) error {
 	}
 }
 
+// validateClientAccessParams 验证修改白名单/黑名单的参数
+func (s *SecurityManagerImpl) validateClientAccessParams(params map[string]interface{}) error {
+	action, ok := params["action"].(string)
+	if !ok {
+		return fmt.Errorf("missing or invalid action parameter")
+	}
+
+	switch ClientAccessAction(action) {
+	case ActionClearBlacklist:
+		return nil
+	case ActionWhitelistAdd, ActionWhitelistRemove, ActionBlacklistAdd, ActionBlacklistRemove:
+	default:
+		return fmt.Errorf("unknown client access action: %s", action)
+	}
+
+	clientID, ok := params["client_id"].(string)
+	if !ok || strings.TrimSpace(clientID) == "" {
+		return fmt.Errorf("missing or invalid client_id parameter")
+	}
+	if len(clientID) > 256 {
+		return fmt.Errorf("client_id too long: %d characters", len(clientID))
+	}
+	return nil
+}
+
 // validateWriteHostsParams 验证写入hosts参数
 func (s *SecurityManagerImpl) validateWriteHostsParams(params map[string]interface{}) error {
 	entries, ok := params["entries"]
@@ -325,14 +393,16 @@ func (s *SecurityManagerImpl) validateIPAddress(ip string) error {
 
 // validateHostname 验证主机名
 func (s *SecurityManagerImpl) validateHostname(hostname string) error {
+	// 按写入时相同的规则规范化后再校验，允许末尾带点的完全限定名
+	hostname = hostsfile.NormalizeHostname(hostname)
+
 	// 基本长度检查
 	if len(hostname) > 253 {
 		return fmt.Errorf("hostname too long (max 253 characters)")
 	}
 
-	// 正则表达式验证
-	hostnameRegex := regexp.MustCompile(`^[a-zA-Z0-9]([a-zA-Z0-9\-]{0,61}[a-zA-Z0-9])?(\.[a-zA-Z0-9]([a-zA-Z0-9\-]{0,61}[a-zA-Z0-9])?)*$`)
-	if !hostnameRegex.MatchString(hostname) {
+	// 格式验证
+	if err := hostsfile.ValidateHostname(hostname); err != nil {
 		return fmt.Errorf("invalid hostname format")
 	}
 
@@ -394,16 +464,17 @@ func (s *SecurityManagerImpl) isDangerousHostname(hostname string) bool {
 }
 
 // logSecurityViolation 记录安全违规
-func (s *SecurityManagerImpl) logSecurityViolation(clientID, violation, operation, severity, description string) {
+func (s *SecurityManagerImpl) logSecurityViolation(clientID, requestID, violation, operation, severity, description string) {
 	s.logger.Error("Security violation detected",
 		"client", clientID,
+		"request_id", requestID,
 		"violation", violation,
 		"operation", operation,
 		"severity", severity,
 		"description", description)
 
 	// 记录到审计日志
-	s.auditLogger.LogFailedOperation(operation, clientID, fmt.Sprintf("%s: %s", violation, description))
+	s.auditLogger.LogFailedOperation(operation, clientID, requestID, fmt.Sprintf("%s: %s", violation, description))
 }
 
 // Allow 速率限制器允许请求
@@ -433,6 +504,21 @@ func (r *RateLimiter) Allow() bool {
 	return true
 }
 
+// Count 统计时间窗口内的请求数，不记录新的请求
+func (r *RateLimiter) Count(now time.Time) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	cutoff := now.Add(-r.window)
+	count := 0
+	for _, reqTime := range r.requests {
+		if reqTime.After(cutoff) {
+			count++
+		}
+	}
+	return count
+}
+
 // GetSecurityStats 获取安全统计信息
 func (s *SecurityManagerImpl) GetSecurityStats() map[string]interface{} {
 	s.mu.RLock()
@@ -470,9 +556,88 @@ func (s *SecurityManagerImpl) ClearBlacklist() {
 	defer s.mu.Unlock()
 
 	s.blacklist = make(map[string]time.Time)
+	s.rateLimit = make(map[string]*RateLimiter)
 	s.logger.Info("Blacklist cleared")
 }
 
+// AddToBlacklist 手动将客户端加入黑名单，时长与超过速率限制时相同
+func (s *SecurityManagerImpl) AddToBlacklist(clientID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.addToBlacklist(clientID)
+}
+
+// RemoveFromBlacklist 将客户端移出黑名单，同时重置其速率限制，避免解封后立即再次被封
+func (s *SecurityManagerImpl) RemoveFromBlacklist(clientID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.blacklist, clientID)
+	delete(s.rateLimit, clientID)
+	s.logger.Info("Client removed from blacklist", "client", clientID)
+}
+
+// ClientAccess 获取白名单、未过期的黑名单和当前时间窗口内有请求的客户端
+func (s *SecurityManagerImpl) ClientAccess() *ClientAccess {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	now := time.Now()
+	access := &ClientAccess{
+		Whitelist:   []string{},
+		Blacklist:   []BlockedClient{},
+		RateLimited: []RateLimitedClient{},
+	}
+
+	for clientID := range s.whitelist {
+		access.Whitelist = append(access.Whitelist, clientID)
+	}
+	for clientID, expiry := range s.blacklist {
+		if now.Before(expiry) {
+			access.Blacklist = append(access.Blacklist, BlockedClient{ClientID: clientID, Expiry: expiry})
+		}
+	}
+	for clientID, limiter := range s.rateLimit {
+		if count := limiter.Count(now); count > 0 {
+			access.RateLimited = append(access.RateLimited, RateLimitedClient{
+				ClientID: clientID,
+				Requests: count,
+				Limit:    limiter.maxReqs,
+				Limited:  count >= limiter.maxReqs,
+			})
+		}
+	}
+
+	sort.Strings(access.Whitelist)
+	sort.Slice(access.Blacklist, func(i, j int) bool {
+		return access.Blacklist[i].ClientID < access.Blacklist[j].ClientID
+	})
+	sort.Slice(access.RateLimited, func(i, j int) bool {
+		return access.RateLimited[i].ClientID < access.RateLimited[j].ClientID
+	})
+	return access
+}
+
+// UpdateClientAccess 按操作修改白名单或黑名单
+func (s *SecurityManagerImpl) UpdateClientAccess(action ClientAccessAction, clientID string) error {
+	switch action {
+	case ActionWhitelistAdd:
+		s.AddToWhitelist(clientID)
+	case ActionWhitelistRemove:
+		s.RemoveFromWhitelist(clientID)
+	case ActionBlacklistAdd:
+		s.AddToBlacklist(clientID)
+	case ActionBlacklistRemove:
+		s.RemoveFromBlacklist(clientID)
+	case ActionClearBlacklist:
+		s.ClearBlacklist()
+	default:
+		return fmt.Errorf("unknown client access action: %s", action)
+	}
+	return nil
+}
+
 // GenerateClientHash 生成客户端哈希
 func (s *SecurityManagerImpl) GenerateClientHash(clientInfo string) string {
 	hash := sha256.Sum256([]byte(clientInfo + time.Now().String()))
@@ -0,0 +1,56 @@
+package helper
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/flyhigher139/mhost/pkg/logger"
+)
+
+// newTestRequest 创建测试用的XPC请求
+func newTestRequest(clientID, operation string, params map[string]interface{}) *XPCRequest {
+	return &XPCRequest{Operation: operation, ClientID: clientID, Parameters: params, Timestamp: time.Now()}
+}
+
+// TestClientAccess 测试被限速的客户端被封禁后，可以通过XPC操作解封并加入白名单
+func TestClientAccess(t *testing.T) {
+	log := logger.NewEnhancedLogger(logger.LogLevelError, false)
+	auditLogger, err := NewAuditLogger("", log)
+	require.NoError(t, err)
+	sm := NewSecurityManagerImpl(auditLogger, log)
+	sm.config.MaxRequestsPerMinute = 5
+
+	for i := 0; i < 5; i++ {
+		require.NoError(t, sm.ValidateRequest(newTestRequest("noisy", "get_status", nil)))
+	}
+	assert.Error(t, sm.ValidateRequest(newTestRequest("noisy", "get_status", nil)))
+	require.NoError(t, sm.ValidateRequest(newTestRequest("admin", "get_status", nil)))
+
+	access := sm.ClientAccess()
+	require.Len(t, access.Blacklist, 1)
+	assert.Equal(t, "noisy", access.Blacklist[0].ClientID)
+	assert.Equal(t, []RateLimitedClient{
+		{ClientID: "admin", Requests: 1, Limit: 5},
+		{ClientID: "noisy", Requests: 5, Limit: 5, Limited: true},
+	}, access.RateLimited)
+
+	assert.Error(t, sm.ValidateRequest(newTestRequest("admin", "update_client_access", map[string]interface{}{"action": "unban"})))
+	assert.Error(t, sm.ValidateRequest(newTestRequest("admin", "update_client_access", map[string]interface{}{"action": "blacklist_remove"})))
+
+	update := newTestRequest("admin", "update_client_access", map[string]interface{}{"action": "blacklist_remove", "client_id": "noisy"})
+	require.NoError(t, sm.ValidateRequest(update))
+	require.NoError(t, sm.UpdateClientAccess(ActionBlacklistRemove, "noisy"))
+	require.NoError(t, sm.UpdateClientAccess(ActionWhitelistAdd, "noisy"))
+	assert.Error(t, sm.UpdateClientAccess("unban", "noisy"))
+
+	for i := 0; i < 6; i++ {
+		assert.NoError(t, sm.ValidateRequest(newTestRequest("noisy", "get_status", nil)), "whitelisted clients skip rate limiting")
+	}
+
+	access = sm.ClientAccess()
+	assert.Equal(t, []string{"noisy"}, access.Whitelist)
+	assert.Empty(t, access.Blacklist)
+}
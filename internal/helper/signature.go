@@ -0,0 +1,129 @@
+package helper
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/flyhigher139/mhost/pkg/errors"
+)
+
+// TeamID 发布版本签名使用的Apple开发者团队ID，构建时通过-ldflags "-X" 注入
+var TeamID = ""
+
+// SignatureInfo Helper可执行文件的代码签名信息
+type SignatureInfo struct {
+	Path       string   `json:"path"`
+	Identifier string   `json:"identifier"`
+	TeamID     string   `json:"team_id"`
+	Authority  []string `json:"authority"`
+	Adhoc      bool     `json:"adhoc"`
+	Verified   bool     `json:"verified"`
+}
+
+// SignatureVerifier 代码签名校验器接口
+type SignatureVerifier interface {
+	// Verify 校验文件签名有效并返回签名信息
+	Verify(path string) (*SignatureInfo, error)
+}
+
+// CodesignVerifier 基于macOS codesign命令的签名校验器
+type CodesignVerifier struct {
+	run func(name string, args ...string) ([]byte, error)
+}
+
+// NewCodesignVerifier 创建codesign签名校验器
+func NewCodesignVerifier() *CodesignVerifier {
+	return &CodesignVerifier{
+		run: func(name string, args ...string) ([]byte, error) {
+			return exec.Command(name, args...).CombinedOutput()
+		},
+	}
+}
+
+// Verify 使用codesign校验签名完整性并读取签名标识与团队ID
+func (v *CodesignVerifier) Verify(path string) (*SignatureInfo, error) {
+	if output, err := v.run("codesign", "--verify", "--strict", "--verbose=2", path); err != nil {
+		return nil, signatureError(path, "code signature is invalid", strings.TrimSpace(string(output)), err)
+	}
+
+	output, err := v.run("codesign", "--display", "--verbose=2", path)
+	if err != nil {
+		return nil, signatureError(path, "failed to read code signature", strings.TrimSpace(string(output)), err)
+	}
+
+	info := parseCodesignOutput(output)
+	info.Path = path
+	info.Verified = true
+	return info, nil
+}
+
+// parseCodesignOutput 解析codesign --display的输出
+func parseCodesignOutput(output []byte) *SignatureInfo {
+	info := &SignatureInfo{Authority: []string{}}
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(scanner.Text(), "=")
+		if !ok {
+			continue
+		}
+		switch key {
+		case "Identifier":
+			info.Identifier = value
+		case "TeamIdentifier":
+			if value != "not set" {
+				info.TeamID = value
+			}
+		case "Authority":
+			info.Authority = append(info.Authority, value)
+		case "Signature":
+			info.Adhoc = value == "adhoc"
+		}
+	}
+	return info
+}
+
+// VerifyHelper 校验Helper签名有效且签名标识与团队ID符合预期，expectedTeamID为空时仅拒绝ad-hoc签名
+func VerifyHelper(verifier SignatureVerifier, path, expectedIdentifier, expectedTeamID string) (*SignatureInfo, error) {
+	info, err := verifier.Verify(path)
+	if err != nil {
+		if errors.IsAppError(err) {
+			return nil, err
+		}
+		return nil, signatureError(path, "failed to verify code signature", "", err)
+	}
+
+	details := map[string]interface{}{
+		"path":       path,
+		"identifier": info.Identifier,
+		"team_id":    info.TeamID,
+	}
+
+	if info.Adhoc {
+		return info, errors.NewSecurityError(errors.ErrCodeSignatureVerificationFailed,
+			"helper is ad-hoc signed and has no team identity", details, nil)
+	}
+	if expectedIdentifier != "" && info.Identifier != expectedIdentifier {
+		details["expected_identifier"] = expectedIdentifier
+		return info, errors.NewSecurityError(errors.ErrCodeSignatureVerificationFailed,
+			fmt.Sprintf("helper identifier mismatch: got %q, want %q", info.Identifier, expectedIdentifier), details, nil)
+	}
+	if expectedTeamID != "" && info.TeamID != expectedTeamID {
+		details["expected_team_id"] = expectedTeamID
+		return info, errors.NewSecurityError(errors.ErrCodeSignatureVerificationFailed,
+			fmt.Sprintf("helper team ID mismatch: got %q, want %q", info.TeamID, expectedTeamID), details, nil)
+	}
+
+	return info, nil
+}
+
+// signatureError 创建签名校验失败错误
+func signatureError(path, message, output string, cause error) error {
+	details := map[string]interface{}{"path": path}
+	if output != "" {
+		details["output"] = output
+	}
+	return errors.NewSecurityError(errors.ErrCodeSignatureVerificationFailed, message, details, cause)
+}
@@ -0,0 +1,123 @@
+package helper
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/flyhigher139/mhost/pkg/errors"
+)
+
+// codesignDisplayOutput codesign --display --verbose=2 的示例输出
+const codesignDisplayOutput = `Executable=/Library/PrivilegedHelperTools/com.mhost.helper
+Identifier=com.mhost.helper
+Format=Mach-O thin (arm64)
+CodeDirectory v=20500 size=1234 flags=0x10000(runtime) hashes=28+7 location=embedded
+Signature size=8979
+Authority=Developer ID Application: mHost (ABCDE12345)
+Authority=Developer ID Certification Authority
+Authority=Apple Root CA
+TeamIdentifier=ABCDE12345
+`
+
+// newFakeCodesign 创建返回固定输出的codesign校验器
+func newFakeCodesign(verifyErr error, display string) *CodesignVerifier {
+	return &CodesignVerifier{
+		run: func(name string, args ...string) ([]byte, error) {
+			if args[0] == "--verify" {
+				if verifyErr != nil {
+					return []byte("a sealed resource is missing or invalid"), verifyErr
+				}
+				return nil, nil
+			}
+			return []byte(display), nil
+		},
+	}
+}
+
+// TestVerifyHelper 测试签名标识与团队ID校验
+func TestVerifyHelper(t *testing.T) {
+	info, err := VerifyHelper(newFakeCodesign(nil, codesignDisplayOutput), "/tmp/helper", DefaultServiceName, "ABCDE12345")
+	require.NoError(t, err)
+	assert.True(t, info.Verified)
+	assert.Equal(t, "ABCDE12345", info.TeamID)
+	assert.Len(t, info.Authority, 3)
+
+	_, err = VerifyHelper(newFakeCodesign(nil, codesignDisplayOutput), "/tmp/helper", DefaultServiceName, "OTHER00000")
+	appErr := errors.GetAppError(err)
+	require.NotNil(t, appErr)
+	assert.Equal(t, errors.ErrCodeSignatureVerificationFailed, appErr.Code())
+	assert.Equal(t, "OTHER00000", appErr.Details()["expected_team_id"])
+
+	_, err = VerifyHelper(newFakeCodesign(nil, codesignDisplayOutput), "/tmp/helper", "com.example.other", "")
+	require.Error(t, err)
+	assert.Equal(t, errors.ErrCodeSignatureVerificationFailed, errors.GetAppError(err).Code())
+
+	// ad-hoc签名没有团队身份
+	adhoc := "Identifier=com.mhost.helper\nSignature=adhoc\nTeamIdentifier=not set\n"
+	info, err = VerifyHelper(newFakeCodesign(nil, adhoc), "/tmp/helper", DefaultServiceName, "")
+	require.Error(t, err)
+	assert.True(t, info.Adhoc)
+	assert.Empty(t, info.TeamID)
+
+	// 签名无效时附带codesign输出
+	_, err = VerifyHelper(newFakeCodesign(fmt.Errorf("exit status 1"), ""), "/tmp/helper", DefaultServiceName, "")
+	appErr = errors.GetAppError(err)
+	require.NotNil(t, appErr)
+	assert.Equal(t, "a sealed resource is missing or invalid", appErr.Details()["output"])
+}
+
+// swappingVerifier 校验时记录被校验的路径，并模拟校验期间源文件被替换
+type swappingVerifier struct {
+	*CodesignVerifier
+	source   string
+	verified string
+}
+
+func (v *swappingVerifier) Verify(path string) (*SignatureInfo, error) {
+	v.verified = path
+	if err := os.WriteFile(v.source, []byte("swapped"), 0755); err != nil {
+		return nil, err
+	}
+	return v.CodesignVerifier.Verify(path)
+}
+
+// TestInstall 测试安装校验并使用目标目录中的副本，以及没有团队ID时拒绝安装
+func TestInstall(t *testing.T) {
+	dir := t.TempDir()
+	source := filepath.Join(dir, "helper")
+	require.NoError(t, os.WriteFile(source, []byte("signed"), 0755))
+	installDir := filepath.Join(dir, "install")
+	require.NoError(t, os.Mkdir(installDir, 0755))
+	dst := filepath.Join(installDir, DefaultServiceName)
+
+	verifier := &swappingVerifier{CodesignVerifier: newFakeCodesign(nil, codesignDisplayOutput), source: source}
+	info, err := install(source, dst, DefaultServiceName, "ABCDE12345", verifier)
+	require.NoError(t, err)
+	assert.Equal(t, dst, info.Path)
+	assert.Equal(t, installDir, filepath.Dir(verifier.verified), "the staged copy is verified")
+	data, err := os.ReadFile(dst)
+	require.NoError(t, err)
+	assert.Equal(t, "signed", string(data), "a source swapped after staging is not installed")
+
+	// 团队ID不符时不替换已安装的文件，也不留下临时文件
+	verifier.source = filepath.Join(dir, "other")
+	_, err = install(source, dst, DefaultServiceName, "OTHER00000", verifier)
+	require.Error(t, err)
+	entries, err := os.ReadDir(installDir)
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+	data, err = os.ReadFile(dst)
+	require.NoError(t, err)
+	assert.Equal(t, "signed", string(data))
+
+	// 构建时没有注入团队ID
+	_, err = install(source, filepath.Join(installDir, "unsigned"), DefaultServiceName, "", verifier)
+	require.Error(t, err)
+	assert.Equal(t, errors.ErrCodeHelperInstallFailed, errors.GetAppError(err).Code())
+	assert.NoFileExists(t, filepath.Join(installDir, "unsigned"))
+}
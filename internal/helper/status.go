@@ -0,0 +1,164 @@
+package helper
+
+import (
+	"os"
+	"time"
+)
+
+// ComponentState 组件健康状态
+type ComponentState string
+
+const (
+	ComponentHealthy   ComponentState = "healthy"   // 正常
+	ComponentDegraded  ComponentState = "degraded"  // 可用但存在问题
+	ComponentUnhealthy ComponentState = "unhealthy" // 不可用
+)
+
+// ComponentHealth 单个组件的健康信息
+type ComponentHealth struct {
+	Name    string         `json:"name"`
+	State   ComponentState `json:"state"`
+	Message string         `json:"message,omitempty"`
+}
+
+// StatusError 最近一次错误记录
+type StatusError struct {
+	Operation string    `json:"operation"`
+	Message   string    `json:"message"`
+	Time      time.Time `json:"time"`
+}
+
+// HelperStatus Helper Tool运行状态快照
+type HelperStatus struct {
+	ServiceName string                      `json:"service_name"`
+	Running     bool                        `json:"running"`
+	StartTime   time.Time                   `json:"start_time"`
+	Uptime      time.Duration               `json:"uptime"`
+	HostsPath   string                      `json:"hosts_path"`
+	Healthy     bool                        `json:"healthy"`
+	Components  map[string]*ComponentHealth `json:"components"`
+	LastError   *StatusError                `json:"last_error,omitempty"`
+	Backup      *BackupStats                `json:"backup,omitempty"`
+	XPC         *XPCServerStats             `json:"xpc,omitempty"`
+	Security    map[string]interface{}      `json:"security,omitempty"`
+	Crashes     []CrashRecord               `json:"crashes,omitempty"`
+	Requests    *RequestLimiterStats        `json:"requests,omitempty"`
+	Coalescing  *CoalesceStats              `json:"coalescing,omitempty"`
+}
+
+// Status 获取Helper Tool当前状态
+func (h *HostsHelper) Status() *HelperStatus {
+	h.mu.RLock()
+	running := h.running
+	startTime := h.startTime
+	var lastError *StatusError
+	if h.lastError != nil {
+		errCopy := *h.lastError
+		lastError = &errCopy
+	}
+	h.mu.RUnlock()
+
+	status := &HelperStatus{
+		ServiceName: h.serviceName,
+		Running:     running,
+		StartTime:   startTime,
+		HostsPath:   h.hostsHandler.GetHostsPath(),
+		Components:  make(map[string]*ComponentHealth),
+		LastError:   lastError,
+		Backup:      h.backupMgr.GetBackupStats(),
+		XPC:         h.xpcServer.GetStats(),
+		Security:    h.securityMgr.GetSecurityStats(),
+		Crashes:     h.supervisor.Crashes(),
+		Requests:    h.limiter.Stats(),
+		Coalescing:  h.coalescer.Stats(),
+	}
+
+	if running && !startTime.IsZero() {
+		status.Uptime = time.Since(startTime)
+	}
+
+	for _, component := range h.checkComponents() {
+		status.Components[component.Name] = component
+	}
+
+	status.Healthy = true
+	for _, component := range status.Components {
+		if component.State == ComponentUnhealthy {
+			status.Healthy = false
+			break
+		}
+	}
+
+	return status
+}
+
+// ToMap 转换为XPC响应使用的map
+func (s *HelperStatus) ToMap() map[string]interface{} {
+	data := map[string]interface{}{
+		"running":    s.Running,
+		"service":    s.ServiceName,
+		"start_time": s.StartTime,
+		"uptime":     s.Uptime.String(),
+		"hosts_path": s.HostsPath,
+		"healthy":    s.Healthy,
+		"components": s.Components,
+		"backup":     s.Backup,
+		"xpc":        s.XPC,
+		"security":   s.Security,
+		"requests":   s.Requests,
+		"coalescing": s.Coalescing,
+	}
+	if s.LastError != nil {
+		data["last_error"] = s.LastError
+	}
+	if len(s.Crashes) > 0 {
+		data["crashes"] = s.Crashes
+	}
+	return data
+}
+
+// checkComponents 检查各组件健康状态
+func (h *HostsHelper) checkComponents() []*ComponentHealth {
+	components := make([]*ComponentHealth, 0, 3)
+
+	xpcHealth := &ComponentHealth{Name: "xpc_server", State: ComponentHealthy}
+	if !h.xpcServer.IsRunning() {
+		xpcHealth.State = ComponentUnhealthy
+		xpcHealth.Message = "XPC server is not running"
+	}
+	components = append(components, xpcHealth)
+
+	hostsHealth := &ComponentHealth{Name: "hosts_file", State: ComponentHealthy}
+	if info, err := os.Stat(h.hostsHandler.GetHostsPath()); err != nil {
+		hostsHealth.State = ComponentUnhealthy
+		hostsHealth.Message = err.Error()
+	} else if info.IsDir() {
+		hostsHealth.State = ComponentUnhealthy
+		hostsHealth.Message = "hosts path is a directory"
+	}
+	components = append(components, hostsHealth)
+
+	backupHealth := &ComponentHealth{Name: "backup_manager", State: ComponentHealthy}
+	if stats := h.backupMgr.GetBackupStats(); stats == nil {
+		backupHealth.State = ComponentDegraded
+		backupHealth.Message = "backup statistics unavailable"
+	} else if stats.TotalBackups == 0 {
+		backupHealth.State = ComponentDegraded
+		backupHealth.Message = "no backups available"
+	}
+	components = append(components, backupHealth)
+
+	return components
+}
+
+// recordError 记录最近一次错误
+func (h *HostsHelper) recordError(operation, message string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.lastError = &StatusError{
+		Operation: operation,
+		Message:   message,
+		Time:      time.Now(),
+	}
+}
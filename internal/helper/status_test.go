@@ -0,0 +1,83 @@
+package helper
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/flyhigher139/mhost/pkg/logger"
+)
+
+// newStatusHelper 创建使用临时hosts文件和备份目录的Helper
+func newStatusHelper(t *testing.T) (*HostsHelper, string) {
+	dir := t.TempDir()
+	hostsPath := filepath.Join(dir, "hosts")
+	require.NoError(t, os.WriteFile(hostsPath, []byte("127.0.0.1\tlocalhost\n"), 0644))
+	h, err := NewHostsHelperWithOptions(&HelperOptions{
+		ServiceName: DefaultServiceName,
+		HostsPath:   hostsPath,
+		BackupDir:   filepath.Join(dir, "backups"),
+		MaxBackups:  3,
+	}, logger.NewEnhancedLogger(logger.LogLevelError, false))
+	require.NoError(t, err)
+	return h, hostsPath
+}
+
+// TestStatus 测试状态快照包含各组件的健康状态，任一组件不可用时整体不健康
+func TestStatus(t *testing.T) {
+	h, hostsPath := newStatusHelper(t)
+
+	status := h.Status()
+	assert.Equal(t, DefaultServiceName, status.ServiceName)
+	assert.False(t, status.Running)
+	assert.Zero(t, status.Uptime)
+	assert.Equal(t, hostsPath, status.HostsPath)
+	assert.Nil(t, status.LastError)
+	require.Len(t, status.Components, 3)
+	assert.Equal(t, ComponentUnhealthy, status.Components["xpc_server"].State)
+	assert.Equal(t, ComponentHealthy, status.Components["hosts_file"].State)
+	assert.Equal(t, ComponentDegraded, status.Components["backup_manager"].State)
+	assert.Equal(t, "no backups available", status.Components["backup_manager"].Message)
+	assert.False(t, status.Healthy, "the XPC server is not running")
+
+	_, err := h.backupMgr.CreateBackup(hostsPath, "status", "", nil, true)
+	require.NoError(t, err)
+	require.NoError(t, os.Remove(hostsPath))
+	status = h.Status()
+	assert.Equal(t, ComponentHealthy, status.Components["backup_manager"].State)
+	assert.Equal(t, ComponentUnhealthy, status.Components["hosts_file"].State)
+	assert.NotEmpty(t, status.Components["hosts_file"].Message)
+
+	require.NoError(t, os.Mkdir(hostsPath, 0755))
+	status = h.Status()
+	assert.Equal(t, "hosts path is a directory", status.Components["hosts_file"].Message)
+}
+
+// TestStatusLastError 测试状态快照返回最近一次错误的副本，并写入get_status响应
+func TestStatusLastError(t *testing.T) {
+	h, _ := newStatusHelper(t)
+
+	data := h.Status().ToMap()
+	assert.NotContains(t, data, "last_error")
+	assert.NotContains(t, data, "crashes")
+	assert.Equal(t, false, data["running"])
+	assert.Equal(t, DefaultServiceName, data["service"])
+
+	h.recordError("write_hosts", "permission denied")
+	h.recordError("restore_hosts", "backup not found")
+	status := h.Status()
+	require.NotNil(t, status.LastError)
+	assert.Equal(t, "restore_hosts", status.LastError.Operation)
+	assert.Equal(t, "backup not found", status.LastError.Message)
+	assert.False(t, status.LastError.Time.IsZero())
+
+	status.LastError.Message = "changed"
+	assert.Equal(t, "backup not found", h.Status().LastError.Message)
+
+	data = h.Status().ToMap()
+	require.Contains(t, data, "last_error")
+	assert.Equal(t, "restore_hosts", data["last_error"].(*StatusError).Operation)
+}
@@ -4,6 +4,9 @@ import (
 	"context"
 	"time"
 
+	"github.com/flyhigher139/mhost/internal/host"
+	"github.com/flyhigher139/mhost/pkg/errors"
+	"github.com/flyhigher139/mhost/pkg/hostsfile"
 	"github.com/flyhigher139/mhost/pkg/logger"
 )
 
@@ -16,6 +19,9 @@ type XPCRequest struct {
 	ClientID   string                 `json:"client_id"`
 	Parameters map[string]interface{} `json:"parameters"`
 	Timestamp  time.Time              `json:"timestamp"`
+	Deadline   time.Time              `json:"deadline,omitempty"` // 客户端放弃等待的时间，过期的请求不再处理
+	// RequestID 客户端生成的请求ID，贯穿GUI、Helper日志和审计日志
+	RequestID string `json:"request_id,omitempty"`
 }
 
 // XPCResponse XPC响应结构
@@ -24,21 +30,43 @@ type XPCResponse struct {
 	Data      map[string]interface{} `json:"data,omitempty"`
 	Error     string                 `json:"error,omitempty"`
 	Timestamp time.Time              `json:"timestamp"`
-}
+	RequestID string                 `json:"request_id,omitempty"` // 对应请求的ID
 
-// HostEntry hosts文件条目
-type HostEntry struct {
-	IP       string `json:"ip"`
-	Hostname string `json:"hostname"`
-	Comment  string `json:"comment,omitempty"`
-	Enabled  bool   `json:"enabled"`
+	// 失败时的结构化错误信息，与pkg/errors的AppError对应
+	ErrorCode    string                 `json:"error_code,omitempty"`
+	ErrorType    errors.ErrorType       `json:"error_type,omitempty"`
+	ErrorDetails map[string]interface{} `json:"error_details,omitempty"`
 }
 
+// HostEntry hosts文件条目，与pkg/hostsfile共用
+type HostEntry = hostsfile.Entry
+
 // XPCServer XPC服务器接口
 type XPCServer interface {
 	Start(ctx context.Context, handler XPCRequestHandler) error
 	Stop() error
 	IsRunning() bool
+	GetStats() *XPCServerStats
+}
+
+// Client Helper客户端接口，由XPCClient实现；UI和测试可以替换为内存实现
+type Client interface {
+	Connect() error
+	Disconnect() error
+	IsConnected() bool
+	SendRequest(ctx context.Context, operation string, params map[string]interface{}) (*XPCResponse, error)
+	WriteHosts(ctx context.Context, entries []HostEntry) error
+	BackupHosts(ctx context.Context) (string, error)
+	RestoreHosts(ctx context.Context, backupPath string) error
+	ValidateHosts(ctx context.Context) error
+	ReadHosts(ctx context.Context) (*HostsContent, error)
+	GetStatus(ctx context.Context) (map[string]interface{}, error)
+	GetClientAccess(ctx context.Context) (*ClientAccess, error)
+	UpdateClientAccess(ctx context.Context, action ClientAccessAction, clientID string) (*ClientAccess, error)
+	SetOperationTimeout(operation string, timeout time.Duration)
+	TimeoutFor(operation string) time.Duration
+	SetTimeout(timeout time.Duration)
+	GetTimeout() time.Duration
 }
 
 // SecurityManager 安全管理器接口
@@ -47,16 +75,14 @@ type SecurityManager interface {
 	GetSecurityStats() map[string]interface{}
 	AddToWhitelist(clientID string)
 	RemoveFromWhitelist(clientID string)
+	AddToBlacklist(clientID string)
+	RemoveFromBlacklist(clientID string)
 	ClearBlacklist()
+	ClientAccess() *ClientAccess
+	UpdateClientAccess(action ClientAccessAction, clientID string) error
 	GenerateClientHash(clientInfo string) string
 }
 
-// HostsHandler hosts文件处理器
-type HostsHandler struct {
-	hostsPath string
-	logger    Logger
-}
-
 // AuditLogger 审计日志器
 type AuditLogger struct {
 	logPath string
@@ -73,43 +99,6 @@ func NewSecurityManager(auditLogger *AuditLogger, logger Logger) SecurityManager
 	return NewSecurityManagerImpl(auditLogger, logger)
 }
 
-// NewHostsHandler 创建hosts处理器
-func NewHostsHandler(hostsPath string, logger Logger) (*HostsHandler, error) {
-	return &HostsHandler{
-		hostsPath: hostsPath,
-		logger:    logger,
-	}, nil
-}
-
-// WriteHosts 写入hosts文件
-func (h *HostsHandler) WriteHosts(entries []HostEntry) error {
-	h.logger.Info("Writing hosts file", "entries", len(entries))
-	return nil
-}
-
-// BackupHosts 备份hosts文件
-func (h *HostsHandler) BackupHosts() (string, error) {
-	h.logger.Info("Backing up hosts file")
-	return "/tmp/hosts.backup", nil
-}
-
-// RestoreHosts 恢复hosts文件
-func (h *HostsHandler) RestoreHosts(backupPath string) error {
-	h.logger.Info("Restoring hosts file", "backup", backupPath)
-	return nil
-}
-
-// ValidateHosts 验证hosts文件
-func (h *HostsHandler) ValidateHosts() error {
-	h.logger.Info("Validating hosts file")
-	return nil
-}
-
-// GetHostsPath 获取hosts文件路径
-func (h *HostsHandler) GetHostsPath() string {
-	return h.hostsPath
-}
-
 // NewAuditLogger 创建审计日志器
 func NewAuditLogger(logPath string, logger Logger) (*AuditLogger, error) {
 	return &AuditLogger{
@@ -119,13 +108,18 @@ func NewAuditLogger(logPath string, logger Logger) (*AuditLogger, error) {
 }
 
 // LogSuccessfulOperation 记录成功操作
-func (a *AuditLogger) LogSuccessfulOperation(operation, clientID string, params map[string]interface{}) {
-	a.logger.Info("Audit: successful operation", "operation", operation, "client", clientID)
+func (a *AuditLogger) LogSuccessfulOperation(operation, clientID, requestID string, params map[string]interface{}) {
+	a.logger.Info("Audit: successful operation", "operation", operation, "client", clientID, "request_id", requestID)
 }
 
 // LogFailedOperation 记录失败操作
-func (a *AuditLogger) LogFailedOperation(operation, clientID, error string) {
-	a.logger.Error("Audit: failed operation", "operation", operation, "client", clientID, "error", error)
+func (a *AuditLogger) LogFailedOperation(operation, clientID, requestID, error string) {
+	a.logger.Error("Audit: failed operation", "operation", operation, "client", clientID, "request_id", requestID, "error", error)
+}
+
+// LogCrash 记录后台组件的panic及是否自动重启
+func (a *AuditLogger) LogCrash(component, panicValue string, restarted bool) {
+	a.logger.Error("Audit: component crashed", "component", component, "panic", panicValue, "restarted", restarted)
 }
 
 // Close 关闭审计日志器
@@ -142,6 +136,7 @@ type BackupManager interface {
 	ListBackups() []*BackupInfo
 	GetBackup(backupID string) (*BackupInfo, error)
 	GetBackupStats() *BackupStats
+	GetBackupHistory() *host.BackupHistory
 	CleanupOldBackups() error
 	ValidateBackup(backupID string) error
 }
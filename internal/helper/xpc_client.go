@@ -6,24 +6,49 @@ import (
 	"fmt"
 	"sync"
 	"time"
+
+	"github.com/flyhigher139/mhost/pkg/errors"
+	"github.com/flyhigher139/mhost/pkg/logger"
 )
 
+// ErrNotConnected 客户端尚未连接到Helper
+var ErrNotConnected = errors.NewNetworkError(errors.ErrCodeXPCConnectionFailed, "XPC client is not connected", nil)
+
+// DefaultOperationTimeouts 各操作的默认超时时间，未列出的操作使用客户端的通用超时
+func DefaultOperationTimeouts() map[string]time.Duration {
+	return map[string]time.Duration{
+		"get_status":           5 * time.Second,
+		"get_client_access":    5 * time.Second,
+		"update_client_access": 5 * time.Second,
+		"read_hosts":           10 * time.Second,
+		"validate_hosts":       15 * time.Second,
+		"backup_hosts":         time.Minute,
+		"restore_hosts":        time.Minute,
+		"write_hosts":          2 * time.Minute,
+	}
+}
+
+// writeHostsTimeoutPerThousand 写入hosts时每1000个条目额外增加的超时时间
+const writeHostsTimeoutPerThousand = 10 * time.Second
+
 // XPCClient XPC客户端，用于与Helper Tool通信
 type XPCClient struct {
-	serviceName string
-	logger      Logger
-	connected   bool
-	mu          sync.RWMutex
-	timeout     time.Duration
+	serviceName       string
+	logger            Logger
+	connected         bool
+	mu                sync.RWMutex
+	timeout           time.Duration
+	operationTimeouts map[string]time.Duration
 }
 
 // NewXPCClient 创建新的XPC客户端
 func NewXPCClient(serviceName string, logger Logger) *XPCClient {
 	return &XPCClient{
-		serviceName: serviceName,
-		logger:      logger,
-		connected:   false,
-		timeout:     30 * time.Second,
+		serviceName:       serviceName,
+		logger:            logger,
+		connected:         false,
+		timeout:           30 * time.Second,
+		operationTimeouts: DefaultOperationTimeouts(),
 	}
 }
 
@@ -69,21 +94,40 @@ func (c *XPCClient) IsConnected() bool {
 	return c.connected
 }
 
-// SendRequest 发送请求到Helper Tool
+// SendRequest 发送请求到Helper Tool，使用该操作的超时时间；ctx中更早的截止时间优先
 func (c *XPCClient) SendRequest(ctx context.Context, operation string, params map[string]interface{}) (*XPCResponse, error) {
+	return c.sendRequest(ctx, operation, params, c.TimeoutFor(operation))
+}
+
+// sendRequest 以指定超时发送请求，截止时间随请求一起传给Helper。
+// 请求ID优先使用ctx中的ID（见logger.WithRequestID），没有时生成新的ID
+func (c *XPCClient) sendRequest(ctx context.Context, operation string, params map[string]interface{}, timeout time.Duration) (*XPCResponse, error) {
 	if !c.IsConnected() {
-		return nil, fmt.Errorf("XPC client is not connected")
+		return nil, ErrNotConnected
 	}
 
+	requestID := logger.RequestIDFromContext(ctx)
+	if requestID == "" {
+		requestID = logger.NewRequestID()
+		ctx = logger.WithRequestID(ctx, requestID)
+	}
+	log := c.logger.WithContext(ctx)
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	deadline, _ := ctx.Deadline()
+
 	// 创建请求
 	req := &XPCRequest{
 		Operation:  operation,
 		ClientID:   c.generateClientID(),
 		Parameters: params,
 		Timestamp:  time.Now(),
+		Deadline:   deadline,
+		RequestID:  requestID,
 	}
 
-	c.logger.Debug("Sending XPC request", "operation", operation, "client_id", req.ClientID)
+	log.Debug("Sending XPC request", "operation", operation, "client_id", req.ClientID)
 
 	// 序列化请求
 	reqData, err := json.Marshal(req)
@@ -94,7 +138,15 @@ func (c *XPCClient) SendRequest(ctx context.Context, operation string, params ma
 	// 发送请求并等待响应
 	respData, err := c.sendXPCMessage(ctx, reqData)
 	if err != nil {
-		return nil, fmt.Errorf("failed to send XPC message: %w", err)
+		log.Warn("XPC request not delivered", "operation", operation, "error", err)
+		if ctx.Err() == context.DeadlineExceeded {
+			return nil, errors.NewNetworkError(errors.ErrCodeXPCRequestTimeout, fmt.Sprintf("%s timed out after %v", operation, timeout), err)
+		}
+		// 传输失败说明Helper不可用，下次发送前重新连接
+		c.mu.Lock()
+		c.connected = false
+		c.mu.Unlock()
+		return nil, errors.NewNetworkError(errors.ErrCodeXPCServiceUnavailable, "failed to send XPC message", err)
 	}
 
 	// 反序列化响应
@@ -104,7 +156,14 @@ func (c *XPCClient) SendRequest(ctx context.Context, operation string, params ma
 	}
 
 	resp.Timestamp = time.Now()
-	c.logger.Debug("Received XPC response", "success", resp.Success, "client_id", req.ClientID)
+	if resp.RequestID == "" {
+		resp.RequestID = requestID
+	}
+	if resp.Success {
+		log.Debug("Received XPC response", "success", resp.Success, "client_id", req.ClientID)
+	} else {
+		log.Warn("XPC request failed", "operation", operation, "error", resp.Error, "error_code", resp.ErrorCode)
+	}
 
 	return &resp, nil
 }
@@ -115,13 +174,15 @@ func (c *XPCClient) WriteHosts(ctx context.Context, entries []HostEntry) error {
 		"entries": entries,
 	}
 
-	resp, err := c.SendRequest(ctx, "write_hosts", params)
+	// 条目越多Helper写入和校验越慢，按条目数延长超时
+	timeout := c.TimeoutFor("write_hosts") + time.Duration(len(entries)/1000)*writeHostsTimeoutPerThousand
+	resp, err := c.sendRequest(ctx, "write_hosts", params, timeout)
 	if err != nil {
 		return err
 	}
 
 	if !resp.Success {
-		return fmt.Errorf("write hosts failed: %s", resp.Error)
+		return fmt.Errorf("write hosts failed: %w", resp.Err())
 	}
 
 	return nil
@@ -135,7 +196,7 @@ func (c *XPCClient) BackupHosts(ctx context.Context) (string, error) {
 	}
 
 	if !resp.Success {
-		return "", fmt.Errorf("backup hosts failed: %s", resp.Error)
+		return "", fmt.Errorf("backup hosts failed: %w", resp.Err())
 	}
 
 	backupPath, ok := resp.Data["backup_path"].(string)
@@ -158,7 +219,7 @@ func (c *XPCClient) RestoreHosts(ctx context.Context, backupPath string) error {
 	}
 
 	if !resp.Success {
-		return fmt.Errorf("restore hosts failed: %s", resp.Error)
+		return fmt.Errorf("restore hosts failed: %w", resp.Err())
 	}
 
 	return nil
@@ -172,12 +233,37 @@ func (c *XPCClient) ValidateHosts(ctx context.Context) error {
 	}
 
 	if !resp.Success {
-		return fmt.Errorf("validate hosts failed: %s", resp.Error)
+		return fmt.Errorf("validate hosts failed: %w", resp.Err())
 	}
 
 	return nil
 }
 
+// ReadHosts 读取Helper视角下的hosts文件内容及管理section
+func (c *XPCClient) ReadHosts(ctx context.Context) (*HostsContent, error) {
+	resp, err := c.SendRequest(ctx, "read_hosts", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if !resp.Success {
+		return nil, fmt.Errorf("read hosts failed: %w", resp.Err())
+	}
+
+	// 响应数据经过JSON传输，重新编码为结构体
+	data, err := json.Marshal(resp.Data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal read hosts response: %w", err)
+	}
+
+	var content HostsContent
+	if err := json.Unmarshal(data, &content); err != nil {
+		return nil, fmt.Errorf("invalid read hosts response: %w", err)
+	}
+
+	return &content, nil
+}
+
 // GetStatus 获取Helper Tool状态
 func (c *XPCClient) GetStatus(ctx context.Context) (map[string]interface{}, error) {
 	resp, err := c.SendRequest(ctx, "get_status", nil)
@@ -186,13 +272,101 @@ func (c *XPCClient) GetStatus(ctx context.Context) (map[string]interface{}, erro
 	}
 
 	if !resp.Success {
-		return nil, fmt.Errorf("get status failed: %s", resp.Error)
+		return nil, fmt.Errorf("get status failed: %w", resp.Err())
 	}
 
 	return resp.Data, nil
 }
 
-// SetTimeout 设置请求超时时间
+// GetClientAccess 获取Helper的白名单、黑名单和速率限制状态
+func (c *XPCClient) GetClientAccess(ctx context.Context) (*ClientAccess, error) {
+	resp, err := c.SendRequest(ctx, "get_client_access", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if !resp.Success {
+		return nil, fmt.Errorf("get client access failed: %w", resp.Err())
+	}
+
+	return decodeClientAccess(resp.Data)
+}
+
+// UpdateClientAccess 修改Helper的白名单或黑名单，返回修改后的状态
+func (c *XPCClient) UpdateClientAccess(ctx context.Context, action ClientAccessAction, clientID string) (*ClientAccess, error) {
+	params := map[string]interface{}{
+		"action":    string(action),
+		"client_id": clientID,
+	}
+
+	resp, err := c.SendRequest(ctx, "update_client_access", params)
+	if err != nil {
+		return nil, err
+	}
+
+	if !resp.Success {
+		return nil, fmt.Errorf("update client access failed: %w", resp.Err())
+	}
+
+	return decodeClientAccess(resp.Data)
+}
+
+// decodeClientAccess 将经过JSON传输的响应数据重新编码为结构体
+func decodeClientAccess(data map[string]interface{}) (*ClientAccess, error) {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal client access response: %w", err)
+	}
+
+	var access ClientAccess
+	if err := json.Unmarshal(raw, &access); err != nil {
+		return nil, fmt.Errorf("invalid client access response: %w", err)
+	}
+	return &access, nil
+}
+
+// DecodeXPCStats 从get_status响应中读取XPC请求统计，旧版本Helper没有统计时返回nil
+func DecodeXPCStats(status map[string]interface{}) (*XPCServerStats, error) {
+	data, ok := status["xpc"]
+	if !ok || data == nil {
+		return nil, nil
+	}
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal xpc stats: %w", err)
+	}
+
+	var stats XPCServerStats
+	if err := json.Unmarshal(raw, &stats); err != nil {
+		return nil, fmt.Errorf("invalid xpc stats: %w", err)
+	}
+	return &stats, nil
+}
+
+// SetOperationTimeout 设置单个操作的超时时间，timeout不大于0时恢复使用通用超时
+func (c *XPCClient) SetOperationTimeout(operation string, timeout time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if timeout <= 0 {
+		delete(c.operationTimeouts, operation)
+		return
+	}
+	c.operationTimeouts[operation] = timeout
+}
+
+// TimeoutFor 获取操作的超时时间
+func (c *XPCClient) TimeoutFor(operation string) time.Duration {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if timeout, ok := c.operationTimeouts[operation]; ok {
+		return timeout
+	}
+	return c.timeout
+}
+
+// SetTimeout 设置请求超时时间，用于没有单独设置超时的操作
 func (c *XPCClient) SetTimeout(timeout time.Duration) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
@@ -0,0 +1,240 @@
+package helper
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/flyhigher139/mhost/internal/host"
+	apperrors "github.com/flyhigher139/mhost/pkg/errors"
+	"github.com/flyhigher139/mhost/pkg/logger"
+)
+
+// TestXPCClientTimeouts 测试按操作选择超时，并在超时后放弃请求
+func TestXPCClientTimeouts(t *testing.T) {
+	client := NewXPCClient(DefaultServiceName, logger.NewEnhancedLogger(logger.LogLevelError, false))
+	client.SetTimeout(20 * time.Second)
+
+	assert.Equal(t, 5*time.Second, client.TimeoutFor("get_status"))
+	assert.Equal(t, 2*time.Minute, client.TimeoutFor("write_hosts"))
+	assert.Equal(t, 20*time.Second, client.TimeoutFor("unknown"))
+
+	client.SetOperationTimeout("get_status", 10*time.Millisecond)
+	assert.Equal(t, 10*time.Millisecond, client.TimeoutFor("get_status"))
+	client.SetOperationTimeout("read_hosts", 0)
+	assert.Equal(t, 20*time.Second, client.TimeoutFor("read_hosts"))
+
+	require.NoError(t, client.Connect())
+	_, err := client.GetStatus(context.Background())
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+	assert.Contains(t, err.Error(), "get_status timed out after 10ms")
+
+	// 调用方更早的截止时间优先于操作的超时
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err = client.SendRequest(ctx, "validate_hosts", nil)
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+
+	_, err = client.SendRequest(context.Background(), "validate_hosts", nil)
+	assert.NoError(t, err)
+}
+
+// TestXPCServerRejectsExpiredDeadline 测试Helper不再处理客户端已放弃的请求
+func TestXPCServerRejectsExpiredDeadline(t *testing.T) {
+	server, err := NewXPCServerImpl(DefaultServiceName, logger.NewEnhancedLogger(logger.LogLevelError, false))
+	require.NoError(t, err)
+
+	req := &XPCRequest{Operation: "get_status", ClientID: "client", Timestamp: time.Now()}
+	assert.NoError(t, server.validateRequest(req))
+
+	req.Deadline = time.Now().Add(time.Minute)
+	assert.NoError(t, server.validateRequest(req))
+
+	req.Deadline = time.Now().Add(-time.Second)
+	assert.EqualError(t, server.validateRequest(req), "request deadline exceeded")
+}
+
+// TestXPCResponseErr 测试失败响应携带的错误代码在客户端还原为AppError
+func TestXPCResponseErr(t *testing.T) {
+	assert.NoError(t, (&XPCResponse{Success: true}).Err())
+
+	blocked := apperrors.NewSecurityError(apperrors.ErrCodeClientBlacklisted, "client is blacklisted", map[string]interface{}{"client_id": "client_1"}, nil)
+	resp := NewErrorResponse(fmt.Errorf("security validation failed: %w", blocked), apperrors.ErrCodeHelperOperationFailed, apperrors.ErrorTypeSystem)
+	assert.Equal(t, apperrors.ErrCodeClientBlacklisted, resp.ErrorCode)
+	assert.Equal(t, apperrors.ErrorTypePermission, resp.ErrorType)
+
+	err := resp.Err()
+	appErr := apperrors.GetAppError(err)
+	require.NotNil(t, appErr)
+	assert.Equal(t, apperrors.ErrCodeClientBlacklisted, appErr.Code())
+	assert.Equal(t, apperrors.ErrorTypePermission, appErr.Type())
+	assert.Equal(t, "client_1", appErr.Details()["client_id"])
+	assert.Contains(t, err.Error(), "client is blacklisted")
+
+	// 旧版本Helper的响应没有错误代码
+	legacy := (&XPCResponse{Error: "boom"}).Err()
+	require.NotNil(t, apperrors.GetAppError(legacy))
+	assert.Equal(t, apperrors.ErrCodeHelperOperationFailed, apperrors.GetAppError(legacy).Code())
+	assert.Equal(t, apperrors.ErrorTypeSystem, apperrors.GetAppError(legacy).Type())
+}
+
+// TestRequestIDPropagation 测试请求ID随响应返回，并出现在Helper日志、审计日志和客户端的错误中
+func TestRequestIDPropagation(t *testing.T) {
+	dir := t.TempDir()
+	hostsPath := filepath.Join(dir, "hosts")
+	require.NoError(t, os.WriteFile(hostsPath, []byte("127.0.0.1\tlocalhost\n"), 0644))
+	logPath := filepath.Join(dir, "helper.log")
+	log, err := logger.NewFileLogger(logPath, logger.LogLevelInfo, false)
+	require.NoError(t, err)
+
+	h, err := NewHostsHelperWithOptions(&HelperOptions{ServiceName: DefaultServiceName, HostsPath: hostsPath, BackupDir: filepath.Join(dir, "backups"), MaxBackups: 3}, log)
+	require.NoError(t, err)
+	h.securityMgr.(*SecurityManagerImpl).AddToWhitelist("client")
+
+	// 备份文件存在但不在备份索引中，请求通过安全验证后在恢复时失败
+	backupPath := filepath.Join(dir, "backups", "unknown.backup")
+	require.NoError(t, os.WriteFile(backupPath, []byte("127.0.0.1\tlocalhost\n"), 0644))
+	req := newTestRequest("client", "restore_hosts", map[string]interface{}{"backup_id": "unknown", "backup_path": backupPath})
+	req.RequestID = "req-1234"
+	resp := h.handleXPCRequest(req)
+	assert.False(t, resp.Success)
+	assert.Equal(t, "req-1234", resp.RequestID)
+
+	err = fmt.Errorf("restore hosts failed: %w", resp.Err())
+	assert.Equal(t, "req-1234", RequestIDOf(err))
+	assert.Empty(t, RequestIDOf(errors.New("plain error")))
+
+	data, err := os.ReadFile(logPath)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "Failed to restore backup")
+	assert.Contains(t, string(data), "Audit: failed operation")
+	for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+		if strings.Contains(line, "restore") || strings.Contains(line, "Audit") {
+			assert.Contains(t, line, "request_id=req-1234")
+		}
+	}
+
+	// 旧版本客户端没有请求ID时由Helper生成
+	server, err := NewXPCServerImpl(DefaultServiceName, log)
+	require.NoError(t, err)
+	server.handler = h.handleXPCRequest
+	message, err := json.Marshal(newTestRequest("client", "get_status", nil))
+	require.NoError(t, err)
+	var generated XPCResponse
+	require.NoError(t, json.Unmarshal(server.handleMessage(message), &generated))
+	assert.True(t, generated.Success)
+	assert.Len(t, generated.RequestID, 16)
+}
+
+// TestRestoreHostsTargetPath 测试恢复备份只能写入Helper管理的hosts文件
+func TestRestoreHostsTargetPath(t *testing.T) {
+	dir := t.TempDir()
+	hostsPath := filepath.Join(dir, "hosts")
+	require.NoError(t, os.WriteFile(hostsPath, []byte("127.0.0.1\tlocalhost\n"), 0644))
+	h, err := NewHostsHelperWithOptions(&HelperOptions{ServiceName: DefaultServiceName, HostsPath: hostsPath, BackupDir: filepath.Join(dir, "backups"), MaxBackups: 3}, logger.NewEnhancedLogger(logger.LogLevelError, false))
+	require.NoError(t, err)
+
+	resp := h.handleRestoreHosts(newTestRequest("client", "restore_hosts", map[string]interface{}{"backup_id": "any", "target_path": filepath.Join(dir, "other")}))
+	assert.False(t, resp.Success)
+	assert.Equal(t, apperrors.ErrCodeXPCInvalidRequest, resp.ErrorCode)
+	assert.NoFileExists(t, filepath.Join(dir, "other"))
+}
+
+// TestMilestoneBackups 测试里程碑备份带有标签且不会被自动清理
+func TestMilestoneBackups(t *testing.T) {
+	dir := t.TempDir()
+	hostsPath := filepath.Join(dir, "hosts")
+	require.NoError(t, os.WriteFile(hostsPath, []byte("127.0.0.1\tlocalhost\n"), 0644))
+	h, err := NewHostsHelperWithOptions(&HelperOptions{ServiceName: DefaultServiceName, HostsPath: hostsPath, BackupDir: filepath.Join(dir, "backups"), MaxBackups: 1}, logger.NewEnhancedLogger(logger.LogLevelError, false))
+	require.NoError(t, err)
+
+	resp := h.handleBackupHosts(newTestRequest("client", "backup_hosts", map[string]interface{}{
+		"name":      "milestone",
+		"milestone": "before DNS migration",
+		"tags":      []interface{}{"dns", " dns "},
+	}))
+	require.True(t, resp.Success)
+	for _, name := range []string{"first", "second"} {
+		resp := h.handleBackupHosts(newTestRequest("client", "backup_hosts", map[string]interface{}{"name": name}))
+		require.True(t, resp.Success)
+	}
+
+	backups := h.backupMgr.ListBackups()
+	require.Len(t, backups, 2)
+	var milestones []*BackupInfo
+	for _, backup := range backups {
+		if backup.Protected() {
+			milestones = append(milestones, backup)
+		}
+	}
+	require.Len(t, milestones, 1)
+	assert.Equal(t, "before DNS migration", milestones[0].Description)
+	assert.Equal(t, []string{"hosts", "dns", host.MilestoneTag}, milestones[0].Tags)
+}
+
+// FuzzXPCRequest 测试Helper解码和处理任意XPC消息不会panic，并总是返回带错误代码的合法响应
+func FuzzXPCRequest(f *testing.F) {
+	f.Add([]byte(`{"operation":"get_status","client_id":"c","timestamp":"2025-07-01T12:00:00Z"}`), "write_hosts", []byte(`{"entries":[{"ip":"10.0.0.1","hostname":"api.test","enabled":true}]}`))
+	f.Add([]byte(`{"operation":1}`), "write_hosts", []byte(`{"entries":[{"ip":"10.0.0.1","hostname":["a"]},null,7]}`))
+	f.Add([]byte(`not json`), "restore_hosts", []byte(`{"backup_id":"x","backup_path":"/etc/hosts","target_path":"/etc/passwd"}`))
+	f.Add([]byte(`{}`), "update_client_access", []byte(`{"action":"blacklist_add","client_id":"fuzz"}`))
+	f.Add([]byte(`null`), "read_hosts", []byte(`null`))
+
+	dir := f.TempDir()
+	log := logger.NewEnhancedLogger(logger.LogLevelError, false)
+	hostsPath := filepath.Join(dir, "hosts")
+	require.NoError(f, os.WriteFile(hostsPath, []byte("127.0.0.1\tlocalhost\n"), 0644))
+	h, err := NewHostsHelperWithOptions(&HelperOptions{
+		ServiceName: DefaultServiceName,
+		HostsPath:   hostsPath,
+		BackupDir:   filepath.Join(dir, "backups"),
+		MaxBackups:  3,
+	}, log)
+	require.NoError(f, err)
+	h.securityMgr.(*SecurityManagerImpl).AddToWhitelist("fuzz")
+
+	server, err := NewXPCServerImpl(DefaultServiceName, log)
+	require.NoError(f, err)
+	server.handler = h.handleXPCRequest
+
+	checkResponse := func(t *testing.T, resp *XPCResponse) {
+		require.NotNil(t, resp)
+		if !resp.Success {
+			assert.NotEmpty(t, resp.ErrorCode, resp.Error)
+			assert.NotEmpty(t, resp.ErrorType, resp.Error)
+		}
+	}
+
+	f.Fuzz(func(t *testing.T, message []byte, operation string, params []byte) {
+		var resp XPCResponse
+		require.NoError(t, json.Unmarshal(server.handleMessage(message), &resp))
+		checkResponse(t, &resp)
+
+		var parameters map[string]interface{}
+		if json.Unmarshal(params, &parameters) != nil {
+			return
+		}
+		req := &XPCRequest{Operation: operation, ClientID: "fuzz", Parameters: parameters, Timestamp: time.Now()}
+		direct := h.handleXPCRequest(req)
+		checkResponse(t, direct)
+		_, err := json.Marshal(direct)
+		require.NoError(t, err)
+
+		// 无论请求内容如何，hosts文件之外的文件都不会被修改
+		entries, err := os.ReadDir(dir)
+		require.NoError(t, err)
+		for _, entry := range entries {
+			assert.Contains(t, []string{"hosts", "backups"}, entry.Name())
+		}
+	})
+}
@@ -0,0 +1,65 @@
+package helper
+
+import (
+	"fmt"
+
+	"github.com/flyhigher139/mhost/pkg/errors"
+)
+
+// NewErrorResponse 创建失败响应；错误链中有AppError时使用其代码、类型和详情，否则使用给定的代码和类型
+func NewErrorResponse(err error, code string, errType errors.ErrorType) *XPCResponse {
+	resp := &XPCResponse{
+		Success:   false,
+		Error:     err.Error(),
+		ErrorCode: code,
+		ErrorType: errType,
+	}
+	if appErr := errors.GetAppError(err); appErr != nil {
+		resp.ErrorCode = appErr.Code()
+		resp.ErrorType = appErr.Type()
+		resp.ErrorDetails = appErr.Details()
+	}
+	return resp
+}
+
+// newValidationResponse 创建参数验证失败的响应
+func newValidationResponse(format string, args ...interface{}) *XPCResponse {
+	return NewErrorResponse(fmt.Errorf(format, args...), errors.ErrCodeXPCInvalidRequest, errors.ErrorTypeValidation)
+}
+
+// requestIDDetail 错误详细信息中请求ID的键
+const requestIDDetail = "request_id"
+
+// Err 将失败响应还原为AppError，详细信息中带有请求ID，成功时返回nil；旧版本Helper没有错误代码时视为系统错误
+func (r *XPCResponse) Err() error {
+	if r.Success {
+		return nil
+	}
+
+	code, errType := r.ErrorCode, r.ErrorType
+	if code == "" {
+		code = errors.ErrCodeHelperOperationFailed
+	}
+	if errType == "" {
+		errType = errors.ErrorTypeSystem
+	}
+	details := r.ErrorDetails
+	if r.RequestID != "" {
+		details = make(map[string]interface{}, len(r.ErrorDetails)+1)
+		for key, value := range r.ErrorDetails {
+			details[key] = value
+		}
+		details[requestIDDetail] = r.RequestID
+	}
+	return errors.NewError(code, errType, r.Error, details)
+}
+
+// RequestIDOf 获取Helper返回的错误对应的请求ID，用于在Helper日志和审计日志中查找该请求；没有时返回空字符串
+func RequestIDOf(err error) string {
+	appErr := errors.GetAppError(err)
+	if appErr == nil {
+		return ""
+	}
+	requestID, _ := appErr.Details()[requestIDDetail].(string)
+	return requestID
+}
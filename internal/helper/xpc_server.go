@@ -6,6 +6,9 @@ import (
 	"fmt"
 	"sync"
 	"time"
+
+	"github.com/flyhigher139/mhost/pkg/errors"
+	"github.com/flyhigher139/mhost/pkg/logger"
 )
 
 // XPCRequestHandler XPC请求处理函数类型
@@ -21,6 +24,7 @@ type XPCServerImpl struct {
 	cancel      context.CancelFunc
 	mu          sync.RWMutex
 	stats       *XPCServerStats
+	supervisor  *Supervisor
 }
 
 // XPCServerStats XPC服务器统计信息
@@ -31,7 +35,14 @@ type XPCServerStats struct {
 	StartTime        time.Time `json:"start_time"`
 	LastRequestTime  time.Time `json:"last_request_time"`
 	AverageLatency   float64   `json:"average_latency_ms"`
+	P50Latency       float64   `json:"p50_latency_ms"`
+	P95Latency       float64   `json:"p95_latency_ms"`
+	P99Latency       float64   `json:"p99_latency_ms"`
+	// Operations 按操作统计的请求数和延迟分位数
+	Operations       map[string]*OperationStats `json:"operations,omitempty"`
 	mu               sync.RWMutex
+	latency          *latencyHistogram
+	operations       map[string]*latencyHistogram
 }
 
 // NewXPCServerImpl 创建新的XPC服务器实现
@@ -53,11 +64,23 @@ func NewXPCServerImpl(serviceName string, logger Logger) (*XPCServerImpl, error)
 		ctx:         ctx,
 		cancel:      cancel,
 		stats: &XPCServerStats{
-			StartTime: time.Now(),
+			StartTime:  time.Now(),
+			latency:    newLatencyHistogram(),
+			operations: make(map[string]*latencyHistogram),
 		},
+		supervisor: NewSupervisor(logger, nil),
 	}, nil
 }
 
+// SetSupervisor 设置运行消息处理循环的Supervisor，需在Start之前调用
+func (s *XPCServerImpl) SetSupervisor(supervisor *Supervisor) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if supervisor != nil {
+		s.supervisor = supervisor
+	}
+}
+
 // Start 启动XPC服务器
 func (s *XPCServerImpl) Start(ctx context.Context, handler XPCRequestHandler) error {
 	s.mu.Lock()
@@ -83,8 +106,8 @@ func (s *XPCServerImpl) Start(ctx context.Context, handler XPCRequestHandler) er
 	s.running = true
 	s.stats.StartTime = time.Now()
 
-	// 启动消息处理循环
-	go s.messageLoop()
+	// 启动消息处理循环，panic时由Supervisor恢复并按策略重启
+	s.supervisor.Go(s.ctx, "xpc_message_loop", func(context.Context) { s.messageLoop() })
 
 	s.logger.Info("XPC server started successfully", "service", s.serviceName)
 	return nil
@@ -128,14 +151,22 @@ func (s *XPCServerImpl) GetStats() *XPCServerStats {
 	defer s.stats.mu.RUnlock()
 
 	// 返回统计信息的副本
-	return &XPCServerStats{
+	stats := &XPCServerStats{
 		TotalRequests:   s.stats.TotalRequests,
 		SuccessRequests: s.stats.SuccessRequests,
 		FailedRequests:  s.stats.FailedRequests,
 		StartTime:       s.stats.StartTime,
 		LastRequestTime: s.stats.LastRequestTime,
-		AverageLatency:  s.stats.AverageLatency,
+		AverageLatency:  s.stats.latency.average(),
+		P50Latency:      s.stats.latency.percentile(0.50),
+		P95Latency:      s.stats.latency.percentile(0.95),
+		P99Latency:      s.stats.latency.percentile(0.99),
+		Operations:      make(map[string]*OperationStats, len(s.stats.operations)),
 	}
+	for operation, histogram := range s.stats.operations {
+		stats.Operations[operation] = histogram.stats()
+	}
+	return stats
 }
 
 // messageLoop 消息处理循环
@@ -160,52 +191,59 @@ func (s *XPCServerImpl) handleMessage(messageData []byte) []byte {
 	start := time.Now()
 
 	// 更新统计信息
-	s.updateStats(true, false, 0)
+	s.updateStats("", true, false, 0)
 
 	// 反序列化请求
 	var req XPCRequest
 	if err := json.Unmarshal(messageData, &req); err != nil {
 		s.logger.Error("Failed to unmarshal XPC request", "error", err)
-		s.updateStats(false, true, time.Since(start))
-		return s.createErrorResponse("Invalid request format")
+		s.updateStats("", false, true, time.Since(start))
+		return s.createErrorResponse(fmt.Errorf("Invalid request format"), errors.ErrorTypeValidation, "")
+	}
+
+	// 旧版本客户端不发送请求ID，由服务端生成，保证Helper日志和审计日志中的记录可以关联
+	if req.RequestID == "" {
+		req.RequestID = logger.NewRequestID()
 	}
+	log := s.logger.WithContext(logger.WithRequestID(s.ctx, req.RequestID))
 
 	// 验证请求
 	if err := s.validateRequest(&req); err != nil {
-		s.logger.Error("Invalid XPC request", "error", err, "operation", req.Operation)
-		s.updateStats(false, true, time.Since(start))
-		return s.createErrorResponse(fmt.Sprintf("Invalid request: %v", err))
+		log.Error("Invalid XPC request", "error", err, "operation", req.Operation)
+		s.updateStats(req.Operation, false, true, time.Since(start))
+		return s.createErrorResponse(fmt.Errorf("Invalid request: %w", err), errors.ErrorTypeValidation, req.RequestID)
 	}
 
-	s.logger.Debug("Processing XPC request", "operation", req.Operation, "client", req.ClientID)
+	log.Debug("Processing XPC request", "operation", req.Operation, "client", req.ClientID)
 
 	// 调用处理函数
 	resp := s.handler(&req)
 	if resp == nil {
-		s.logger.Error("Handler returned nil response", "operation", req.Operation)
-		s.updateStats(false, true, time.Since(start))
-		return s.createErrorResponse("Internal server error")
+		log.Error("Handler returned nil response", "operation", req.Operation)
+		s.updateStats(req.Operation, false, true, time.Since(start))
+		return s.createErrorResponse(fmt.Errorf("Internal server error"), errors.ErrorTypeInternal, req.RequestID)
 	}
 
-	// 设置响应时间戳
+	// 设置响应时间戳和请求ID
 	resp.Timestamp = time.Now()
+	resp.RequestID = req.RequestID
 
 	// 序列化响应
 	respData, err := json.Marshal(resp)
 	if err != nil {
-		s.logger.Error("Failed to marshal XPC response", "error", err)
-		s.updateStats(false, true, time.Since(start))
-		return s.createErrorResponse("Failed to serialize response")
+		log.Error("Failed to marshal XPC response", "error", err)
+		s.updateStats(req.Operation, false, true, time.Since(start))
+		return s.createErrorResponse(fmt.Errorf("Failed to serialize response"), errors.ErrorTypeInternal, req.RequestID)
 	}
 
 	// 更新统计信息
 	latency := time.Since(start)
 	if resp.Success {
-		s.updateStats(false, false, latency)
-		s.logger.Debug("XPC request completed successfully", "operation", req.Operation, "latency", latency)
+		s.updateStats(req.Operation, false, false, latency)
+		log.Debug("XPC request completed successfully", "operation", req.Operation, "latency", latency)
 	} else {
-		s.updateStats(false, true, latency)
-		s.logger.Warn("XPC request failed", "operation", req.Operation, "error", resp.Error, "latency", latency)
+		s.updateStats(req.Operation, false, true, latency)
+		log.Warn("XPC request failed", "operation", req.Operation, "error", resp.Error, "latency", latency)
 	}
 
 	return respData
@@ -227,26 +265,33 @@ func (s *XPCServerImpl) validateRequest(req *XPCRequest) error {
 
 	// 检查请求是否过期（5分钟）
 	if time.Since(req.Timestamp) > 5*time.Minute {
-		return fmt.Errorf("request expired")
+		return errors.NewValidationError(errors.ErrCodeRequestExpired, "request expired", nil)
+	}
+
+	// 客户端已经超时放弃的请求不再处理
+	if !req.Deadline.IsZero() && time.Now().After(req.Deadline) {
+		return errors.NewNetworkError(errors.ErrCodeXPCRequestTimeout, "request deadline exceeded", nil)
 	}
 
 	return nil
 }
 
-// createErrorResponse 创建错误响应
-func (s *XPCServerImpl) createErrorResponse(errorMsg string) []byte {
-	resp := &XPCResponse{
-		Success:   false,
-		Error:     errorMsg,
-		Timestamp: time.Now(),
+// createErrorResponse 创建错误响应，错误中没有AppError时按类型使用通用的请求或响应错误代码
+func (s *XPCServerImpl) createErrorResponse(err error, errType errors.ErrorType, requestID string) []byte {
+	code := errors.ErrCodeXPCInvalidRequest
+	if errType != errors.ErrorTypeValidation {
+		code = errors.ErrCodeXPCInvalidResponse
 	}
+	resp := NewErrorResponse(err, code, errType)
+	resp.Timestamp = time.Now()
+	resp.RequestID = requestID
 
 	data, _ := json.Marshal(resp)
 	return data
 }
 
-// updateStats 更新统计信息
-func (s *XPCServerImpl) updateStats(isNew, isFailed bool, latency time.Duration) {
+// updateStats 更新统计信息，operation为空（请求无法解析）时只计入总体统计
+func (s *XPCServerImpl) updateStats(operation string, isNew, isFailed bool, latency time.Duration) {
 	s.stats.mu.Lock()
 	defer s.stats.mu.Unlock()
 
@@ -262,12 +307,19 @@ func (s *XPCServerImpl) updateStats(isNew, isFailed bool, latency time.Duration)
 	}
 
 	if latency > 0 {
-		// 计算平均延迟（简单移动平均）
-		latencyMs := float64(latency.Nanoseconds()) / 1e6
-		if s.stats.AverageLatency == 0 {
-			s.stats.AverageLatency = latencyMs
-		} else {
-			s.stats.AverageLatency = (s.stats.AverageLatency + latencyMs) / 2
+		// 记录到总体和按操作的延迟直方图
+		s.stats.latency.observe(latency, isFailed)
+		if operation != "" {
+			// 操作名称来自客户端，超过上限的其他名称合并统计，避免无限增长
+			if _, ok := s.stats.operations[operation]; !ok && len(s.stats.operations) >= maxTrackedOperations {
+				operation = otherOperations
+			}
+			histogram, ok := s.stats.operations[operation]
+			if !ok {
+				histogram = newLatencyHistogram()
+				s.stats.operations[operation] = histogram
+			}
+			histogram.observe(latency, isFailed)
 		}
 	}
 }
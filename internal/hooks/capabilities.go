@@ -0,0 +1,84 @@
+package hooks
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"time"
+
+	"go.starlark.net/lib/json"
+	"go.starlark.net/starlark"
+)
+
+// 可以授予脚本的能力，未授予时对应的内置函数不存在
+const (
+	CapabilityEnv  = "env"  // env(name, default="")：读取环境变量
+	CapabilityDNS  = "dns"  // lookup(hostname)：解析主机名，返回IP地址列表
+	CapabilityTime = "time" // now()：当前时间的Unix秒数
+)
+
+// Capabilities 全部能力，按名称排序
+var Capabilities = []string{CapabilityDNS, CapabilityEnv, CapabilityTime}
+
+// contextKey 线程中保存运行时限context的键，DNS解析随脚本超时取消
+const contextKey = "mhost.context"
+
+// predeclared 脚本可以使用的全局名称：json模块始终可用，其余内置函数按授予的能力添加
+func predeclared(capabilities []string) (starlark.StringDict, error) {
+	dict := starlark.StringDict{"json": json.Module}
+	for _, capability := range capabilities {
+		switch capability {
+		case CapabilityEnv:
+			dict["env"] = starlark.NewBuiltin("env", env)
+		case CapabilityDNS:
+			dict["lookup"] = starlark.NewBuiltin("lookup", lookup)
+		case CapabilityTime:
+			dict["now"] = starlark.NewBuiltin("now", now)
+		default:
+			return nil, fmt.Errorf("unknown capability %q", capability)
+		}
+	}
+	return dict, nil
+}
+
+// env 读取环境变量，未设置时返回default
+func env(_ *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var name, def string
+	if err := starlark.UnpackArgs(b.Name(), args, kwargs, "name", &name, "default?", &def); err != nil {
+		return nil, err
+	}
+	if value, ok := os.LookupEnv(name); ok {
+		return starlark.String(value), nil
+	}
+	return starlark.String(def), nil
+}
+
+// lookup 解析主机名，解析失败时返回错误并终止脚本
+func lookup(thread *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var hostname string
+	if err := starlark.UnpackArgs(b.Name(), args, kwargs, "hostname", &hostname); err != nil {
+		return nil, err
+	}
+	ctx, ok := thread.Local(contextKey).(context.Context)
+	if !ok {
+		ctx = context.Background()
+	}
+	addrs, err := net.DefaultResolver.LookupHost(ctx, hostname)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", b.Name(), err)
+	}
+	values := make([]starlark.Value, 0, len(addrs))
+	for _, addr := range addrs {
+		values = append(values, starlark.String(addr))
+	}
+	return starlark.NewList(values), nil
+}
+
+// now 当前时间的Unix秒数
+func now(_ *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	if err := starlark.UnpackPositionalArgs(b.Name(), args, kwargs, 0); err != nil {
+		return nil, err
+	}
+	return starlark.MakeInt64(time.Now().Unix()), nil
+}
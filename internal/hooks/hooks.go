@@ -0,0 +1,200 @@
+// Package hooks 转换Hook：高级用户可以在hooks目录中用Starlark编写脚本，在应用Profile时改写、删除或生成条目。
+// 脚本在沙箱中运行：不能读写文件、不能load其他模块，执行步数和时间受限，
+// 环境变量、DNS解析和当前时间等能力需要在配置中按脚本显式授予
+package hooks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"go.starlark.net/starlark"
+	"go.starlark.net/syntax"
+
+	"github.com/flyhigher139/mhost/pkg/hostsfile"
+	"github.com/flyhigher139/mhost/pkg/models"
+)
+
+// DefaultDirName 数据目录下默认的脚本目录名
+const DefaultDirName = "hooks"
+
+// ScriptExt 脚本文件的扩展名
+const ScriptExt = ".star"
+
+// DefaultTimeout 单个脚本默认的运行时限，加载和每次转换分别计时
+const DefaultTimeout = 2 * time.Second
+
+// MaxSteps 单个脚本每次运行最多执行的Starlark步数
+const MaxSteps = 10_000_000
+
+// TransformFunc 脚本中必须定义的转换函数名：transform(profile, entries)返回新的条目列表
+const TransformFunc = "transform"
+
+// fileOptions 脚本可以使用的语言特性；执行步数和时间已受限，允许while和递归
+var fileOptions = &syntax.FileOptions{Set: true, While: true, TopLevelControl: true, Recursion: true}
+
+// DefaultDir 获取数据目录下默认的脚本目录
+func DefaultDir(dataDir string) string {
+	return filepath.Join(dataDir, DefaultDirName)
+}
+
+// Hook 一个已加载的脚本
+type Hook struct {
+	Name         string   // 脚本文件名，例如corp.star
+	Path         string   // 脚本路径
+	Capabilities []string // 授予的能力
+	transform    starlark.Callable
+	predeclared  starlark.StringDict
+}
+
+// Runner 按文件名顺序运行一组脚本，前一个脚本的输出是后一个脚本的输入。nil表示没有脚本
+type Runner struct {
+	hooks   []*Hook
+	timeout time.Duration
+	print   func(hook, message string)
+}
+
+// Load 加载目录下的全部脚本，grants按脚本文件名授予能力，timeout为0时使用DefaultTimeout。
+// 目录不存在时返回没有脚本的Runner；任一脚本无法加载或未定义transform函数时返回错误
+func Load(dir string, grants map[string][]string, timeout time.Duration) (*Runner, error) {
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	runner := &Runner{timeout: timeout}
+
+	files, err := os.ReadDir(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return runner, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read hooks directory: %w", err)
+	}
+
+	var names []string
+	for _, file := range files {
+		if !file.IsDir() && strings.HasSuffix(file.Name(), ScriptExt) {
+			names = append(names, file.Name())
+		}
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		hook, err := runner.load(filepath.Join(dir, name), grants[name])
+		if err != nil {
+			return nil, fmt.Errorf("hook %s: %w", name, err)
+		}
+		runner.hooks = append(runner.hooks, hook)
+	}
+	return runner, nil
+}
+
+// LoadConfig 按配置加载脚本，未启用时返回nil；未设置目录时使用数据目录下的hooks
+func LoadConfig(config models.HooksConfig, dataDir string) (*Runner, error) {
+	if !config.Enabled {
+		return nil, nil
+	}
+	dir := config.Dir
+	if dir == "" {
+		dir = DefaultDir(dataDir)
+	}
+	return Load(dir, config.Grants, config.Timeout)
+}
+
+// load 执行脚本的顶层代码并取出transform函数
+func (r *Runner) load(path string, capabilities []string) (*Hook, error) {
+	src, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read script: %w", err)
+	}
+
+	hook := &Hook{Name: filepath.Base(path), Path: path, Capabilities: slices.Clone(capabilities)}
+	if hook.predeclared, err = predeclared(capabilities); err != nil {
+		return nil, err
+	}
+
+	thread, done := r.newThread(hook)
+	defer done()
+	globals, err := starlark.ExecFileOptions(fileOptions, thread, path, src, hook.predeclared)
+	if err != nil {
+		return nil, err
+	}
+
+	transform, ok := globals[TransformFunc].(starlark.Callable)
+	if !ok {
+		return nil, fmt.Errorf("script must define %s(profile, entries)", TransformFunc)
+	}
+	hook.transform = transform
+	return hook, nil
+}
+
+// newThread 创建运行脚本的线程：禁用load，限制执行步数，超时后取消。返回的函数在运行结束后调用
+func (r *Runner) newThread(hook *Hook) (*starlark.Thread, func()) {
+	ctx, cancel := context.WithTimeout(context.Background(), r.timeout)
+	thread := &starlark.Thread{
+		Name: hook.Name,
+		Print: func(_ *starlark.Thread, message string) {
+			if r.print != nil {
+				r.print(hook.Name, message)
+			}
+		},
+	}
+	thread.SetMaxExecutionSteps(MaxSteps)
+	thread.SetLocal(contextKey, ctx)
+	timer := time.AfterFunc(r.timeout, func() {
+		thread.Cancel(fmt.Sprintf("timed out after %s", r.timeout))
+	})
+	return thread, func() {
+		timer.Stop()
+		cancel()
+	}
+}
+
+// SetPrint 设置接收脚本中print输出的函数，默认丢弃。需要在开始转换之前设置
+func (r *Runner) SetPrint(print func(hook, message string)) {
+	if r != nil {
+		r.print = print
+	}
+}
+
+// Hooks 已加载的脚本，按运行顺序排列
+func (r *Runner) Hooks() []*Hook {
+	if r == nil {
+		return nil
+	}
+	return r.hooks
+}
+
+// Len 脚本数
+func (r *Runner) Len() int {
+	return len(r.Hooks())
+}
+
+// Transform 依次运行每个脚本的transform函数，返回最后得到的条目。
+// 脚本返回的条目逐一验证，任一脚本出错时返回错误，不使用部分转换的结果。没有脚本时原样返回entries
+func (r *Runner) Transform(profile *models.Profile, entries []hostsfile.Entry) ([]hostsfile.Entry, error) {
+	for _, hook := range r.Hooks() {
+		var err error
+		if entries, err = r.run(hook, profile, entries); err != nil {
+			return nil, fmt.Errorf("hook %s: %w", hook.Name, err)
+		}
+	}
+	return entries, nil
+}
+
+// run 运行一个脚本的transform函数
+func (r *Runner) run(hook *Hook, profile *models.Profile, entries []hostsfile.Entry) ([]hostsfile.Entry, error) {
+	thread, done := r.newThread(hook)
+	defer done()
+
+	result, err := starlark.Call(thread, hook.transform, starlark.Tuple{profileValue(profile), entriesValue(entries)}, nil)
+	if err != nil {
+		return nil, err
+	}
+	return toEntries(result)
+}
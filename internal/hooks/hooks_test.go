@@ -0,0 +1,167 @@
+package hooks
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/flyhigher139/mhost/pkg/hostsfile"
+	"github.com/flyhigher139/mhost/pkg/models"
+)
+
+// writeScript 在目录中写入脚本
+func writeScript(t *testing.T, dir, name, src string) {
+	t.Helper()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(src), 0644))
+}
+
+// TestTransform 测试脚本按文件名顺序改写、删除和生成条目
+func TestTransform(t *testing.T) {
+	dir := t.TempDir()
+	writeScript(t, dir, "10-rewrite.star", `
+def transform(profile, entries):
+    out = []
+    for e in entries:
+        if e["hostname"].startswith("old."):
+            continue
+        e["ip"] = e["ip"].replace("10.0.0.", "10.1.0.")
+        out.append(e)
+    return out
+`)
+	writeScript(t, dir, "20-generate.star", `
+def transform(profile, entries):
+    for i in range(2):
+        entries.append({"ip": "10.2.0.%d" % (i + 1), "hostname": "node%d.%s" % (i + 1, profile.environment)})
+    return entries
+`)
+	writeScript(t, dir, "README.md", "not a script")
+
+	runner, err := Load(dir, nil, 0)
+	require.NoError(t, err)
+	assert.Equal(t, 2, runner.Len())
+	assert.Equal(t, "10-rewrite.star", runner.Hooks()[0].Name)
+
+	profile := models.NewProfile("dev", "")
+	profile.Environment = "dev"
+	entries, err := runner.Transform(profile, []hostsfile.Entry{
+		{IP: "10.0.0.1", Hostname: "api.test", Comment: "api", Enabled: true},
+		{IP: "10.0.0.2", Hostname: "old.test", Enabled: true},
+		{IP: "10.0.0.3", Hostname: "web.test"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []hostsfile.Entry{
+		{IP: "10.1.0.1", Hostname: "api.test", Comment: "api", Enabled: true},
+		{IP: "10.1.0.3", Hostname: "web.test"},
+		{IP: "10.2.0.1", Hostname: "node1.dev", Enabled: true},
+		{IP: "10.2.0.2", Hostname: "node2.dev", Enabled: true},
+	}, entries)
+}
+
+// TestTransformInvalidResult 测试脚本返回的条目逐一验证
+func TestTransformInvalidResult(t *testing.T) {
+	for name, body := range map[string]string{
+		"not a list":  `return "x"`,
+		"invalid ip":  `return [{"ip": "300.0.0.1", "hostname": "a.test"}]`,
+		"unknown key": `return [{"ip": "10.0.0.1", "hostname": "a.test", "ttl": 60}]`,
+		"wrong type":  `return [{"ip": "10.0.0.1", "hostname": "a.test", "enabled": "yes"}]`,
+		"runtime":     `return entries[5]`,
+	} {
+		t.Run(name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeScript(t, dir, "bad.star", "def transform(profile, entries):\n    "+body+"\n")
+			runner, err := Load(dir, nil, 0)
+			require.NoError(t, err)
+
+			_, err = runner.Transform(nil, nil)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), "hook bad.star")
+		})
+	}
+}
+
+// TestLoadErrors 测试无法加载的脚本
+func TestLoadErrors(t *testing.T) {
+	dir := t.TempDir()
+	writeScript(t, dir, "empty.star", "x = 1\n")
+	_, err := Load(dir, nil, 0)
+	assert.ErrorContains(t, err, "must define transform")
+
+	dir = t.TempDir()
+	writeScript(t, dir, "load.star", "load('other.star', 'f')\ndef transform(profile, entries):\n    return entries\n")
+	_, err = Load(dir, nil, 0)
+	assert.Error(t, err, "load is not available")
+
+	dir = t.TempDir()
+	writeScript(t, dir, "x.star", "def transform(profile, entries):\n    return entries\n")
+	_, err = Load(dir, map[string][]string{"x.star": {"files"}}, 0)
+	assert.ErrorContains(t, err, `unknown capability "files"`)
+
+	// 目录不存在时没有脚本
+	runner, err := Load(filepath.Join(t.TempDir(), "missing"), nil, 0)
+	require.NoError(t, err)
+	assert.Equal(t, 0, runner.Len())
+}
+
+// TestCapabilities 测试只有授予的能力可以在脚本中使用
+func TestCapabilities(t *testing.T) {
+	t.Setenv("MHOST_HOOK_IP", "10.9.9.9")
+	src := `
+def transform(profile, entries):
+    return [{"ip": env("MHOST_HOOK_IP"), "hostname": "env.test", "comment": "at %d" % now()}]
+`
+	dir := t.TempDir()
+	writeScript(t, dir, "env.star", src)
+
+	_, err := Load(dir, nil, 0)
+	assert.ErrorContains(t, err, "undefined: env")
+
+	runner, err := Load(dir, map[string][]string{"env.star": {CapabilityEnv, CapabilityTime}}, 0)
+	require.NoError(t, err)
+	entries, err := runner.Transform(nil, nil)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "10.9.9.9", entries[0].IP)
+	assert.Contains(t, entries[0].Comment, "at ")
+}
+
+// TestTimeout 测试超时的脚本被取消
+func TestTimeout(t *testing.T) {
+	dir := t.TempDir()
+	writeScript(t, dir, "loop.star", `
+def transform(profile, entries):
+    while True:
+        pass
+`)
+	runner, err := Load(dir, nil, 50*time.Millisecond)
+	require.NoError(t, err)
+
+	_, err = runner.Transform(nil, nil)
+	assert.Error(t, err)
+}
+
+// TestLoadConfig 测试未启用时不加载脚本，print的输出交给SetPrint设置的函数
+func TestLoadConfig(t *testing.T) {
+	dataDir := t.TempDir()
+	require.NoError(t, os.MkdirAll(DefaultDir(dataDir), 0755))
+	writeScript(t, DefaultDir(dataDir), "log.star", "def transform(profile, entries):\n    print('n=%d' % len(entries))\n    return entries\n")
+
+	runner, err := LoadConfig(models.HooksConfig{}, dataDir)
+	require.NoError(t, err)
+	assert.Nil(t, runner)
+	entries := []hostsfile.Entry{{IP: "10.0.0.1", Hostname: "a.test", Enabled: true}}
+	transformed, err := runner.Transform(nil, entries)
+	require.NoError(t, err)
+	assert.Equal(t, entries, transformed)
+
+	runner, err = LoadConfig(models.HooksConfig{Enabled: true}, dataDir)
+	require.NoError(t, err)
+	var output []string
+	runner.SetPrint(func(hook, message string) { output = append(output, hook+": "+message) })
+	_, err = runner.Transform(nil, entries)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"log.star: n=1"}, output)
+}
@@ -0,0 +1,118 @@
+package hooks
+
+import (
+	"fmt"
+
+	"go.starlark.net/starlark"
+	"go.starlark.net/starlarkstruct"
+
+	"github.com/flyhigher139/mhost/pkg/hostsfile"
+	"github.com/flyhigher139/mhost/pkg/models"
+)
+
+// 条目在脚本中表示为字典，键与hosts条目的字段对应
+const (
+	keyIP       = "ip"
+	keyHostname = "hostname"
+	keyComment  = "comment"
+	keyEnabled  = "enabled"
+)
+
+// profileValue 将Profile转换为脚本中只读的profile参数，包含id、name、environment和tags
+func profileValue(profile *models.Profile) starlark.Value {
+	fields := starlark.StringDict{
+		"id":          starlark.String(""),
+		"name":        starlark.String(""),
+		"environment": starlark.String(""),
+		"tags":        starlark.Tuple{},
+	}
+	if profile != nil {
+		tags := make(starlark.Tuple, 0, len(profile.Tags))
+		for _, tag := range profile.Tags {
+			tags = append(tags, starlark.String(tag))
+		}
+		fields["id"] = starlark.String(profile.ID)
+		fields["name"] = starlark.String(profile.Name)
+		fields["environment"] = starlark.String(profile.Environment)
+		fields["tags"] = tags
+	}
+	return starlarkstruct.FromStringDict(starlark.String("profile"), fields)
+}
+
+// entriesValue 将条目转换为脚本中的字典列表，脚本可以直接修改
+func entriesValue(entries []hostsfile.Entry) *starlark.List {
+	values := make([]starlark.Value, 0, len(entries))
+	for _, entry := range entries {
+		dict := starlark.NewDict(4)
+		dict.SetKey(starlark.String(keyIP), starlark.String(entry.IP))
+		dict.SetKey(starlark.String(keyHostname), starlark.String(entry.Hostname))
+		dict.SetKey(starlark.String(keyComment), starlark.String(entry.Comment))
+		dict.SetKey(starlark.String(keyEnabled), starlark.Bool(entry.Enabled))
+		values = append(values, dict)
+	}
+	return starlark.NewList(values)
+}
+
+// toEntries 将transform函数的返回值转换为条目并逐一验证。
+// 返回值必须是字典的列表，ip和hostname必填，comment默认为空，enabled默认为True
+func toEntries(value starlark.Value) ([]hostsfile.Entry, error) {
+	var items []starlark.Value
+	switch list := value.(type) {
+	case *starlark.List:
+		for i := 0; i < list.Len(); i++ {
+			items = append(items, list.Index(i))
+		}
+	case starlark.Tuple:
+		items = list
+	default:
+		return nil, fmt.Errorf("%s must return a list of entries, got %s", TransformFunc, value.Type())
+	}
+
+	entries := make([]hostsfile.Entry, 0, len(items))
+	for i, item := range items {
+		entry, err := toEntry(item)
+		if err != nil {
+			return nil, fmt.Errorf("entry %d: %w", i+1, err)
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// toEntry 将一个字典转换为条目，拒绝未知的键和无效的IP、主机名
+func toEntry(value starlark.Value) (hostsfile.Entry, error) {
+	dict, ok := value.(*starlark.Dict)
+	if !ok {
+		return hostsfile.Entry{}, fmt.Errorf("expected dict, got %s", value.Type())
+	}
+
+	entry := hostsfile.Entry{Enabled: true}
+	for _, item := range dict.Items() {
+		key, ok := starlark.AsString(item[0])
+		if !ok {
+			return hostsfile.Entry{}, fmt.Errorf("key %s is not a string", item[0])
+		}
+		switch key {
+		case keyIP:
+			entry.IP, ok = starlark.AsString(item[1])
+		case keyHostname:
+			entry.Hostname, ok = starlark.AsString(item[1])
+		case keyComment:
+			entry.Comment, ok = starlark.AsString(item[1])
+		case keyEnabled:
+			var enabled starlark.Bool
+			enabled, ok = item[1].(starlark.Bool)
+			entry.Enabled = bool(enabled)
+		default:
+			return hostsfile.Entry{}, fmt.Errorf("unknown key %q", key)
+		}
+		if !ok {
+			return hostsfile.Entry{}, fmt.Errorf("%s has invalid type %s", key, item[1].Type())
+		}
+	}
+
+	if err := hostsfile.ValidateEntry(entry); err != nil {
+		return hostsfile.Entry{}, err
+	}
+	return entry, nil
+}
@@ -0,0 +1,202 @@
+package host
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/flyhigher139/mhost/pkg/hostsfile"
+)
+
+// ExportFormat 备份历史的导出格式
+type ExportFormat string
+
+const (
+	// ExportCSV CSV导出，每个备份一行
+	ExportCSV ExportFormat = "csv"
+	// ExportJSON JSON导出，包含统计信息
+	ExportJSON ExportFormat = "json"
+)
+
+// ParseExportFormat 解析导出格式
+func ParseExportFormat(value string) (ExportFormat, error) {
+	switch ExportFormat(strings.ToLower(strings.TrimSpace(value))) {
+	case ExportCSV:
+		return ExportCSV, nil
+	case ExportJSON:
+		return ExportJSON, nil
+	}
+	return "", fmt.Errorf("unsupported export format: %q (expected csv or json)", value)
+}
+
+// BackupRecord 备份历史中的一条记录
+type BackupRecord struct {
+	ID        string    `json:"id,omitempty"`
+	Name      string    `json:"name"`
+	Path      string    `json:"path"`
+	CreatedAt time.Time `json:"created_at"`
+	Size      int64     `json:"size"`
+	Automatic bool      `json:"automatic"`
+	Valid     bool      `json:"valid"`
+	Error     string    `json:"error,omitempty"` // 验证失败的原因
+	Delta     bool      `json:"delta,omitempty"` // 是否为增量备份
+	BackupLabel
+}
+
+// BackupSummary 备份历史的汇总统计
+type BackupSummary struct {
+	TotalBackups     int        `json:"total_backups"`
+	TotalSize        int64      `json:"total_size"`
+	AutomaticBackups int        `json:"automatic_backups"`
+	ManualBackups    int        `json:"manual_backups"`
+	ValidBackups     int        `json:"valid_backups"`
+	InvalidBackups   int        `json:"invalid_backups"`
+	OldestBackup     *time.Time `json:"oldest_backup,omitempty"`
+	NewestBackup     *time.Time `json:"newest_backup,omitempty"`
+}
+
+// BackupHistory 可导出的备份历史，用于容量和合规报告
+type BackupHistory struct {
+	ExportedAt time.Time      `json:"exported_at"`
+	Summary    BackupSummary  `json:"summary"`
+	Records    []BackupRecord `json:"records"`
+}
+
+// NewBackupHistory 根据备份记录创建备份历史并计算汇总统计
+func NewBackupHistory(records []BackupRecord) *BackupHistory {
+	history := &BackupHistory{
+		ExportedAt: time.Now(),
+		Records:    records,
+	}
+	if history.Records == nil {
+		history.Records = []BackupRecord{}
+	}
+
+	summary := &history.Summary
+	for i := range history.Records {
+		record := &history.Records[i]
+		summary.TotalBackups++
+		summary.TotalSize += record.Size
+		if record.Automatic {
+			summary.AutomaticBackups++
+		} else {
+			summary.ManualBackups++
+		}
+		if record.Valid {
+			summary.ValidBackups++
+		} else {
+			summary.InvalidBackups++
+		}
+		if summary.OldestBackup == nil || record.CreatedAt.Before(*summary.OldestBackup) {
+			summary.OldestBackup = &record.CreatedAt
+		}
+		if summary.NewestBackup == nil || record.CreatedAt.After(*summary.NewestBackup) {
+			summary.NewestBackup = &record.CreatedAt
+		}
+	}
+	return history
+}
+
+// LoadBackupHistory 列出备份目录中的hosts备份并逐个验证
+func LoadBackupHistory(dir string) (*BackupHistory, error) {
+	backups, err := ListBackupFiles(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	records := make([]BackupRecord, 0, len(backups))
+	for _, backup := range backups {
+		record := BackupRecord{
+			Name:        backup.Name,
+			Path:        backup.Path,
+			CreatedAt:   backup.ModTime,
+			Size:        backup.Size,
+			Automatic:   backup.Automatic,
+			Delta:       backup.Delta,
+			Valid:       true,
+			BackupLabel: backup.BackupLabel,
+		}
+		if err := VerifyBackupFile(backup.Path); err != nil {
+			record.Valid = false
+			record.Error = err.Error()
+		}
+		records = append(records, record)
+	}
+	return NewBackupHistory(records), nil
+}
+
+// VerifyBackupFile 检查备份文件是否可读、非空且没有语法错误；增量备份还会验证备份链的完整性
+func VerifyBackupFile(path string) error {
+	data, err := ReadBackupFile(path)
+	if err != nil {
+		return fmt.Errorf("unreadable: %w", err)
+	}
+	if len(strings.TrimSpace(string(data))) == 0 {
+		return fmt.Errorf("empty backup file")
+	}
+
+	lines := hostsfile.SplitLines(string(data))
+	if errs := hostsfile.Check(lines).Errors(); len(errs) > 0 {
+		return fmt.Errorf("%d syntax errors, first: %s", len(errs), errs[0])
+	}
+	return nil
+}
+
+// Export 以指定格式导出备份历史
+func (h *BackupHistory) Export(w io.Writer, format ExportFormat) error {
+	switch format {
+	case ExportCSV:
+		return h.WriteCSV(w)
+	case ExportJSON:
+		return h.WriteJSON(w)
+	}
+	return fmt.Errorf("unsupported export format: %q", format)
+}
+
+// WriteCSV 以CSV导出备份记录，时间使用RFC3339格式
+func (h *BackupHistory) WriteCSV(w io.Writer) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write([]string{"id", "name", "path", "created_at", "size", "type", "valid", "error"}); err != nil {
+		return fmt.Errorf("failed to write csv: %w", err)
+	}
+
+	for _, record := range h.Records {
+		backupType := "manual"
+		if record.Automatic {
+			backupType = "automatic"
+		}
+		row := []string{
+			record.ID,
+			record.Name,
+			record.Path,
+			record.CreatedAt.Format(time.RFC3339),
+			strconv.FormatInt(record.Size, 10),
+			backupType,
+			strconv.FormatBool(record.Valid),
+			record.Error,
+		}
+		if err := writer.Write(row); err != nil {
+			return fmt.Errorf("failed to write csv: %w", err)
+		}
+	}
+
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return fmt.Errorf("failed to write csv: %w", err)
+	}
+	return nil
+}
+
+// WriteJSON 以JSON导出备份历史和汇总统计
+func (h *BackupHistory) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(h); err != nil {
+		return fmt.Errorf("failed to write json: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,265 @@
+package host
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// BackupFilePrefix BackupHostsFile生成的备份文件名前缀
+const BackupFilePrefix = "hosts_backup_"
+
+// AutoBackupFilePrefix 应用Profile前自动备份的文件名前缀
+const AutoBackupFilePrefix = BackupFilePrefix + "auto_"
+
+// MilestoneTag 里程碑备份的标签，用于与Helper的备份保持一致
+const MilestoneTag = "milestone"
+
+// BackupLabelsFileName 备份目录中保存备份里程碑名称和标签的文件名
+const BackupLabelsFileName = "backup_labels.json"
+
+// BackupOptions 创建备份时的选项
+type BackupOptions struct {
+	// Milestone 里程碑名称（例如"before DNS migration"），里程碑备份不会被自动清理
+	Milestone string
+	Tags      []string
+}
+
+// BackupLabel 备份的里程碑名称和标签，按备份文件名保存在备份目录中
+type BackupLabel struct {
+	Milestone string   `json:"milestone,omitempty"`
+	Tags      []string `json:"tags,omitempty"`
+}
+
+// IsZero 是否既没有里程碑名称也没有标签
+func (l BackupLabel) IsZero() bool {
+	return l.Milestone == "" && len(l.Tags) == 0
+}
+
+// BackupFile 备份目录中的hosts备份文件
+type BackupFile struct {
+	Name    string
+	Path    string
+	Size    int64
+	ModTime time.Time
+	// Automatic 是否为应用Profile前的自动备份
+	Automatic bool
+	// Delta 是否为相对于完整备份的增量备份
+	Delta bool
+	BackupLabel
+}
+
+// Protected 是否为不会被自动清理的里程碑备份
+func (b BackupFile) Protected() bool {
+	return b.Milestone != ""
+}
+
+// HasTag 备份是否带有指定标签，不区分大小写
+func (l BackupLabel) HasTag(tag string) bool {
+	for _, t := range l.Tags {
+		if strings.EqualFold(t, tag) {
+			return true
+		}
+	}
+	return false
+}
+
+// NormalizeTags 去掉标签两端的空白、空标签和重复的标签
+func NormalizeTags(tags []string) []string {
+	var result []string
+	for _, tag := range tags {
+		tag = strings.TrimSpace(tag)
+		if tag == "" || (BackupLabel{Tags: result}).HasTag(tag) {
+			continue
+		}
+		result = append(result, tag)
+	}
+	return result
+}
+
+// LoadBackupLabels 读取备份目录中的里程碑名称和标签，文件不存在时返回空表
+func LoadBackupLabels(dir string) (map[string]BackupLabel, error) {
+	labels := make(map[string]BackupLabel)
+	data, err := os.ReadFile(filepath.Join(dir, BackupLabelsFileName))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return labels, nil
+		}
+		return nil, fmt.Errorf("failed to read backup labels: %w", err)
+	}
+	if err := json.Unmarshal(data, &labels); err != nil {
+		return nil, fmt.Errorf("failed to parse backup labels: %w", err)
+	}
+	return labels, nil
+}
+
+// SetBackupLabel 设置备份的里程碑名称和标签，label为空时删除记录
+func SetBackupLabel(dir, name string, label BackupLabel) error {
+	labels, err := LoadBackupLabels(dir)
+	if err != nil {
+		return err
+	}
+	label.Milestone = strings.TrimSpace(label.Milestone)
+	label.Tags = NormalizeTags(label.Tags)
+	if label.IsZero() {
+		delete(labels, name)
+	} else {
+		labels[name] = label
+	}
+	return saveBackupLabels(dir, labels)
+}
+
+// saveBackupLabels 原子地写入备份标签文件
+func saveBackupLabels(dir string, labels map[string]BackupLabel) error {
+	data, err := json.MarshalIndent(labels, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal backup labels: %w", err)
+	}
+	path := filepath.Join(dir, BackupLabelsFileName)
+	if err := os.WriteFile(path+".tmp", data, 0644); err != nil {
+		return fmt.Errorf("failed to save backup labels: %w", err)
+	}
+	if err := os.Rename(path+".tmp", path); err != nil {
+		os.Remove(path + ".tmp")
+		return fmt.Errorf("failed to save backup labels: %w", err)
+	}
+	return nil
+}
+
+// ListBackupFiles 列出备份目录中的hosts备份，最新的在前；目录不存在时返回空列表
+func ListBackupFiles(dir string) ([]BackupFile, error) {
+	if dir == "" {
+		return nil, nil
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read backup directory: %w", err)
+	}
+
+	labels, err := LoadBackupLabels(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var backups []BackupFile
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasPrefix(entry.Name(), BackupFilePrefix) {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		backups = append(backups, BackupFile{
+			Name:        entry.Name(),
+			Path:        filepath.Join(dir, entry.Name()),
+			Size:        info.Size(),
+			ModTime:     info.ModTime(),
+			Automatic:   strings.HasPrefix(entry.Name(), AutoBackupFilePrefix),
+			Delta:       strings.HasSuffix(entry.Name(), DeltaBackupSuffix),
+			BackupLabel: labels[entry.Name()],
+		})
+	}
+
+	sort.Slice(backups, func(i, j int) bool {
+		return backups[i].ModTime.After(backups[j].ModTime)
+	})
+	return backups, nil
+}
+
+// CleanupBackups 删除超过保留天数或超出最大数量的最旧备份，里程碑备份不会被删除且不计入数量，
+// 保留的增量备份所依赖的完整备份也不会被删除。
+// maxBackups和maxAge不大于0时不按对应条件清理。返回删除的备份
+func CleanupBackups(dir string, maxBackups int, maxAge time.Duration, now time.Time) ([]BackupFile, error) {
+	backups, err := ListBackupFiles(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var candidates, remaining []BackupFile
+	kept := 0
+	for _, backup := range backups {
+		expired := maxAge > 0 && now.Sub(backup.ModTime) > maxAge
+		if backup.Protected() || (!expired && (maxBackups <= 0 || kept < maxBackups)) {
+			if !backup.Protected() {
+				kept++
+			}
+			remaining = append(remaining, backup)
+			continue
+		}
+		candidates = append(candidates, backup)
+	}
+	return removeBackups(dir, candidates, remaining)
+}
+
+// DeleteBackups 删除指定名称的备份，包括里程碑备份；未删除的增量备份所依赖的完整备份会被保留。
+// 返回实际删除的备份
+func DeleteBackups(dir string, names []string) ([]BackupFile, error) {
+	backups, err := ListBackupFiles(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	selected := make(map[string]bool, len(names))
+	for _, name := range names {
+		selected[name] = true
+	}
+	var candidates, remaining []BackupFile
+	for _, backup := range backups {
+		if selected[backup.Name] {
+			candidates = append(candidates, backup)
+		} else {
+			remaining = append(remaining, backup)
+		}
+	}
+	return removeBackups(dir, candidates, remaining)
+}
+
+// removeBackups 删除候选备份并清理其标签，跳过remaining中增量备份所依赖的完整备份
+func removeBackups(dir string, candidates, remaining []BackupFile) ([]BackupFile, error) {
+	// 保留的增量备份所依赖的完整备份不能删除，否则无法恢复
+	bases := make(map[string]bool)
+	for _, backup := range remaining {
+		if backup.Delta {
+			if base, err := deltaBase(backup.Path); err == nil {
+				bases[base] = true
+			}
+		}
+	}
+	var removed []BackupFile
+	for _, backup := range candidates {
+		if bases[backup.Name] {
+			continue
+		}
+		if err := os.Remove(backup.Path); err != nil && !os.IsNotExist(err) {
+			return removed, fmt.Errorf("failed to remove backup: %w", err)
+		}
+		removed = append(removed, backup)
+	}
+
+	if len(removed) > 0 {
+		labels, err := LoadBackupLabels(dir)
+		if err != nil {
+			return removed, err
+		}
+		changed := false
+		for _, backup := range removed {
+			if _, ok := labels[backup.Name]; ok {
+				delete(labels, backup.Name)
+				changed = true
+			}
+		}
+		if changed {
+			return removed, saveBackupLabels(dir, labels)
+		}
+	}
+	return removed, nil
+}
@@ -0,0 +1,132 @@
+package host
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/flyhigher139/mhost/internal/config"
+	"github.com/flyhigher139/mhost/pkg/hostsfile"
+	"github.com/flyhigher139/mhost/pkg/models"
+)
+
+// BaselineProfileID 系统初始hosts伪Profile的ID
+const BaselineProfileID = "system-baseline"
+
+// BaselineProfileName 系统初始hosts伪Profile的名称
+const BaselineProfileName = "System baseline"
+
+// Baseline 首次运行时保存的系统初始hosts文件。只读保存，与滚动备份相互独立，
+// 备份被清理后仍可用于恢复出厂状态
+type Baseline struct {
+	Path       string
+	CapturedAt time.Time
+	Lines      []string
+}
+
+// DefaultBaselinePath 获取数据目录下的系统初始hosts文件路径
+func DefaultBaselinePath(dataDir string) string {
+	return filepath.Join(dataDir, "hosts_baseline.txt")
+}
+
+// SharedBaselinePath 获取系统初始hosts文件路径。hosts文件由所有工作区共享，因此保存在默认工作区的数据目录中
+func SharedBaselinePath(workspaces config.WorkspaceManager) (string, error) {
+	workspace, err := workspaces.GetWorkspace(config.DefaultWorkspace)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve hosts baseline path: %w", err)
+	}
+	return DefaultBaselinePath(workspace.DataDir), nil
+}
+
+// LoadBaseline 读取保存的系统初始hosts文件
+func LoadBaseline(path string) (*Baseline, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read hosts baseline: %w", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read hosts baseline: %w", err)
+	}
+	return &Baseline{Path: path, CapturedAt: info.ModTime(), Lines: hostsfile.SplitLines(string(data))}, nil
+}
+
+// CaptureBaseline 首次运行时保存当前hosts文件作为系统初始状态，已保存时直接读取且不再覆盖。
+// hosts文件中已有mHost管理section时去掉该section。返回的bool表示本次是否新保存
+func CaptureBaseline(manager Manager, path string) (*Baseline, bool, error) {
+	if _, err := os.Stat(path); err == nil {
+		baseline, err := LoadBaseline(path)
+		return baseline, false, err
+	}
+
+	lines, err := manager.ReadHostsFile()
+	if err != nil {
+		return nil, false, err
+	}
+	lines = hostsfile.RemoveManagedSection(lines)
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, false, fmt.Errorf("failed to create baseline directory: %w", err)
+	}
+	var content strings.Builder
+	for _, line := range lines {
+		content.WriteString(line + "\n")
+	}
+	// 以只读权限写入临时文件后重命名，避免中断时留下不完整的基线
+	tempFile := path + ".tmp"
+	os.Remove(tempFile)
+	if err := os.WriteFile(tempFile, []byte(content.String()), 0444); err != nil {
+		return nil, false, fmt.Errorf("failed to save hosts baseline: %w", err)
+	}
+	if err := os.Rename(tempFile, path); err != nil {
+		os.Remove(tempFile)
+		return nil, false, fmt.Errorf("failed to save hosts baseline: %w", err)
+	}
+
+	baseline, err := LoadBaseline(path)
+	return baseline, err == nil, err
+}
+
+// Profile 将系统初始hosts文件中的条目表示为只读的伪Profile，用于预览
+func (b *Baseline) Profile() *models.Profile {
+	profile := models.NewProfile(BaselineProfileName, fmt.Sprintf("hosts file captured at %s", b.CapturedAt.Format(time.RFC3339)))
+	profile.ID = BaselineProfileID
+	profile.CreatedAt = b.CapturedAt
+	profile.UpdatedAt = b.CapturedAt
+	for _, entry := range hostsfile.ParseWithDisabled(b.Lines) {
+		profile.Entries = append(profile.Entries, entry.ToModel())
+	}
+	return profile
+}
+
+// RestoreBaseline 将hosts文件恢复为系统初始状态，移除mHost管理section；没有写入权限时通过提权方式写入
+func RestoreBaseline(manager Manager, baseline *Baseline) (*WriteResult, error) {
+	if baseline == nil {
+		return nil, models.ErrInvalidBackup
+	}
+	return manager.WriteHostsFile(baseline.Lines)
+}
+
+// ClearManagedSection 移除hosts文件中的mHost管理section，保留其他内容；没有管理section时不写入并返回nil
+func ClearManagedSection(manager Manager) (*WriteResult, error) {
+	lines, err := manager.ReadHostsFile()
+	if err != nil {
+		return nil, err
+	}
+	if !hostsfile.HasManagedSection(lines) {
+		return nil, nil
+	}
+	return manager.WriteHostsFile(hostsfile.RemoveManagedSection(lines))
+}
+
+// CaptureSharedBaseline 首次运行时在修改hosts文件之前保存系统初始状态，基线保存在SharedBaselinePath中。
+// 返回的bool表示本次是否新保存
+func CaptureSharedBaseline(manager Manager, workspaces config.WorkspaceManager) (*Baseline, bool, error) {
+	path, err := SharedBaselinePath(workspaces)
+	if err != nil {
+		return nil, false, err
+	}
+	return CaptureBaseline(manager, path)
+}
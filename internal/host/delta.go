@@ -0,0 +1,184 @@
+package host
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// DeltaBackupSuffix 增量备份的文件名后缀，完整备份使用.txt
+const DeltaBackupSuffix = ".delta"
+
+// deltaVersion 增量备份文件格式的版本
+const deltaVersion = 1
+
+// backupDelta 相对于一个完整备份的增量备份。按行记录从完整备份复制的行范围和新增的行，
+// 并保存完整备份和恢复后内容的校验和，用于验证备份链的完整性
+type backupDelta struct {
+	Version    int       `json:"version"`
+	Base       string    `json:"base"` // 完整备份的文件名，与增量备份位于同一目录
+	BaseSHA256 string    `json:"base_sha256"`
+	SHA256     string    `json:"sha256"`
+	Size       int64     `json:"size"`
+	Ops        []deltaOp `json:"ops"`
+}
+
+// deltaOp 增量操作：Lines不为空时追加这些行，否则从完整备份复制[Start, Start+Count)行
+type deltaOp struct {
+	Start int      `json:"s,omitempty"`
+	Count int      `json:"n,omitempty"`
+	Lines []string `json:"l,omitempty"`
+}
+
+// checksum 计算内容的SHA-256校验和
+func checksum(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// splitRawLines 按行拆分并保留换行符，拼接后与原内容完全一致
+func splitRawLines(data []byte) []string {
+	return strings.SplitAfter(string(data), "\n")
+}
+
+// diffLines 计算由base得到content的增量操作。依次匹配content中的每一行：能接续上一次复制时延长复制范围，
+// 否则从该行在base中第一次出现的位置开始复制，base中没有的行作为新增行
+func diffLines(base, content []string) []deltaOp {
+	index := make(map[string]int, len(base))
+	for i, line := range base {
+		if _, ok := index[line]; !ok {
+			index[line] = i
+		}
+	}
+
+	var ops []deltaOp
+	for _, line := range content {
+		if line == "" {
+			continue
+		}
+		if n := len(ops); n > 0 && ops[n-1].Lines == nil {
+			last := &ops[n-1]
+			if next := last.Start + last.Count; next < len(base) && base[next] == line {
+				last.Count++
+				continue
+			}
+		}
+		if start, ok := index[line]; ok {
+			ops = append(ops, deltaOp{Start: start, Count: 1})
+			continue
+		}
+		if n := len(ops); n > 0 && ops[n-1].Lines != nil {
+			ops[n-1].Lines = append(ops[n-1].Lines, line)
+			continue
+		}
+		ops = append(ops, deltaOp{Lines: []string{line}})
+	}
+	return ops
+}
+
+// applyDelta 按增量操作由base重建内容
+func applyDelta(base []string, ops []deltaOp) ([]byte, error) {
+	var content strings.Builder
+	for _, op := range ops {
+		if op.Lines != nil {
+			for _, line := range op.Lines {
+				content.WriteString(line)
+			}
+			continue
+		}
+		if op.Start < 0 || op.Count <= 0 || op.Start+op.Count > len(base) {
+			return nil, fmt.Errorf("delta copies lines outside the base backup")
+		}
+		for _, line := range base[op.Start : op.Start+op.Count] {
+			content.WriteString(line)
+		}
+	}
+	return []byte(content.String()), nil
+}
+
+// encodeDelta 生成相对于完整备份的增量备份内容
+func encodeDelta(baseName string, base, content []byte) ([]byte, error) {
+	delta := backupDelta{
+		Version:    deltaVersion,
+		Base:       baseName,
+		BaseSHA256: checksum(base),
+		SHA256:     checksum(content),
+		Size:       int64(len(content)),
+		Ops:        diffLines(splitRawLines(base), splitRawLines(content)),
+	}
+	data, err := json.Marshal(delta)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode delta backup: %w", err)
+	}
+	return data, nil
+}
+
+// ReadBackupFile 读取备份内容。增量备份会由其完整备份重建，并验证完整备份和重建结果的校验和
+func ReadBackupFile(path string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil || !strings.HasSuffix(path, DeltaBackupSuffix) {
+		return data, err
+	}
+
+	var delta backupDelta
+	if err := json.Unmarshal(data, &delta); err != nil {
+		return nil, fmt.Errorf("failed to parse delta backup: %w", err)
+	}
+	if delta.Version <= 0 || delta.Version > deltaVersion {
+		return nil, fmt.Errorf("unsupported delta backup version: %d", delta.Version)
+	}
+	if delta.Base == "" || filepath.Base(delta.Base) != delta.Base || strings.HasSuffix(delta.Base, DeltaBackupSuffix) {
+		return nil, fmt.Errorf("invalid delta base: %q", delta.Base)
+	}
+
+	base, err := os.ReadFile(filepath.Join(filepath.Dir(path), delta.Base))
+	if err != nil {
+		return nil, fmt.Errorf("delta base %s is missing: %w", delta.Base, err)
+	}
+	if checksum(base) != delta.BaseSHA256 {
+		return nil, fmt.Errorf("delta base %s has been modified: checksum mismatch", delta.Base)
+	}
+
+	content, err := applyDelta(splitRawLines(base), delta.Ops)
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(content)) != delta.Size || checksum(content) != delta.SHA256 {
+		return nil, fmt.Errorf("delta backup is corrupted: checksum mismatch")
+	}
+	return content, nil
+}
+
+// deltaBase 读取增量备份所依赖的完整备份的文件名
+func deltaBase(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	var delta backupDelta
+	if err := json.Unmarshal(data, &delta); err != nil {
+		return "", fmt.Errorf("failed to parse delta backup: %w", err)
+	}
+	return delta.Base, nil
+}
+
+// chooseDeltaBase 选择增量备份的基准：最新的完整备份，且之后的增量备份少于fullEvery个。
+// 没有合适的完整备份时返回false，应保存完整备份
+func chooseDeltaBase(dir string, fullEvery int) (BackupFile, bool) {
+	backups, err := ListBackupFiles(dir)
+	if err != nil {
+		return BackupFile{}, false
+	}
+	deltas := 0
+	for _, backup := range backups {
+		if !backup.Delta {
+			return backup, fullEvery <= 0 || deltas < fullEvery
+		}
+		deltas++
+	}
+	return BackupFile{}, false
+}
@@ -0,0 +1,54 @@
+package host
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+const (
+	// BackupSpaceMargin 创建备份时在备份大小之外至少保留的剩余空间，避免写满磁盘导致备份被截断
+	BackupSpaceMargin = 1 << 20
+	// LowBackupSpace 备份目录所在磁盘的剩余空间低于该值时提示清理
+	LowBackupSpace = 100 << 20
+)
+
+// ErrInsufficientSpace 备份目录所在磁盘的剩余空间不足
+var ErrInsufficientSpace = errors.New("insufficient disk space for backup")
+
+// SpaceError 剩余空间不足的详细信息
+type SpaceError struct {
+	Dir       string
+	Required  uint64
+	Available uint64
+}
+
+// Error 实现error接口
+func (e *SpaceError) Error() string {
+	return fmt.Sprintf("%s: %s needs %d bytes, %d bytes available", ErrInsufficientSpace, e.Dir, e.Required, e.Available)
+}
+
+// Unwrap 支持errors.Is(err, ErrInsufficientSpace)
+func (e *SpaceError) Unwrap() error {
+	return ErrInsufficientSpace
+}
+
+// CheckBackupSpace 检查dir所在磁盘是否有足够空间保存size字节的备份。
+// 空间不足时若设置了清理条件（见CleanupBackups），先按条件清理旧备份再检查一次；仍然不足时返回*SpaceError。
+// 无法查询剩余空间时（例如不支持的平台）不阻止备份
+func CheckBackupSpace(dir string, size int64, maxBackups int, maxAge time.Duration, freeSpace func(string) (uint64, error)) error {
+	required := uint64(max(size, 0)) + BackupSpaceMargin
+	available, err := freeSpace(dir)
+	if err != nil || available >= required {
+		return nil
+	}
+
+	if maxBackups > 0 || maxAge > 0 {
+		if removed, err := CleanupBackups(dir, maxBackups, maxAge, time.Now()); err == nil && len(removed) > 0 {
+			if available, err = freeSpace(dir); err != nil || available >= required {
+				return nil
+			}
+		}
+	}
+	return &SpaceError{Dir: dir, Required: required, Available: available}
+}
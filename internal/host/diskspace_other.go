@@ -0,0 +1,10 @@
+//go:build !unix
+
+package host
+
+import "errors"
+
+// FreeSpace 当前平台不支持查询剩余空间
+func FreeSpace(dir string) (uint64, error) {
+	return 0, errors.New("free space is not available on this platform")
+}
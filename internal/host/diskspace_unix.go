@@ -0,0 +1,14 @@
+//go:build unix
+
+package host
+
+import "syscall"
+
+// FreeSpace 目录所在磁盘上当前用户可用的字节数
+func FreeSpace(dir string) (uint64, error) {
+	var stat syscall.Statfs_t
+	if err := syscall.Statfs(dir, &stat); err != nil {
+		return 0, err
+	}
+	return stat.Bavail * uint64(stat.Bsize), nil
+}
@@ -0,0 +1,132 @@
+package host
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// Elevator 以提升的权限写入文件，用于未安装Helper时的降级方案
+type Elevator interface {
+	// Name 提权方式名称
+	Name() string
+
+	// Available 当前环境是否可以使用该提权方式
+	Available() bool
+
+	// WriteFile 以提升的权限将内容写入目标文件
+	WriteFile(path string, content []byte) error
+}
+
+// commandElevator 通过外部命令复制临时文件实现提权写入
+type commandElevator struct {
+	name        string
+	binary      string
+	args        func(src, dst string) []string
+	available   func() bool
+	interactive bool
+}
+
+// NewOsascriptElevator 创建通过osascript管理员权限对话框写入的提权器（macOS）
+func NewOsascriptElevator() Elevator {
+	return &commandElevator{
+		name:   "osascript",
+		binary: "osascript",
+		args: func(src, dst string) []string {
+			script := fmt.Sprintf(`do shell script "/bin/cp " & quoted form of %s & " " & quoted form of %s with prompt %s with administrator privileges`,
+				appleScriptString(src), appleScriptString(dst), appleScriptString("mHost需要管理员权限以更新hosts文件"))
+			return []string{"-e", script}
+		},
+		available: func() bool {
+			return runtime.GOOS == "darwin"
+		},
+	}
+}
+
+// NewSudoElevator 创建通过sudo写入的提权器，仅在终端中可用
+func NewSudoElevator() Elevator {
+	return &commandElevator{
+		name:   "sudo",
+		binary: "sudo",
+		args: func(src, dst string) []string {
+			return []string{"/bin/cp", src, dst}
+		},
+		available:   isTerminal,
+		interactive: true,
+	}
+}
+
+// DefaultElevator 获取当前环境中第一个可用的提权方式，均不可用时返回nil
+func DefaultElevator() Elevator {
+	for _, elevator := range []Elevator{NewOsascriptElevator(), NewSudoElevator()} {
+		if elevator.Available() {
+			return elevator
+		}
+	}
+	return nil
+}
+
+// Name 提权方式名称
+func (e *commandElevator) Name() string {
+	return e.name
+}
+
+// Available 检查命令是否存在且环境满足要求
+func (e *commandElevator) Available() bool {
+	if e.available != nil && !e.available() {
+		return false
+	}
+	_, err := exec.LookPath(e.binary)
+	return err == nil
+}
+
+// WriteFile 先写入当前用户可写的临时文件，再以提升的权限复制到目标位置
+func (e *commandElevator) WriteFile(path string, content []byte) error {
+	tempFile, err := os.CreateTemp("", "mhost-hosts-*")
+	if err != nil {
+		return fmt.Errorf("failed to create temp file: %w", err)
+	}
+	defer os.Remove(tempFile.Name())
+
+	if _, err := tempFile.Write(content); err != nil {
+		tempFile.Close()
+		return fmt.Errorf("failed to write temp file: %w", err)
+	}
+	if err := tempFile.Close(); err != nil {
+		return fmt.Errorf("failed to close temp file: %w", err)
+	}
+
+	cmd := exec.Command(e.binary, e.args(tempFile.Name(), path)...)
+	if e.interactive {
+		cmd.Stdin = os.Stdin
+		cmd.Stdout = os.Stdout
+		cmd.Stderr = os.Stderr
+		if err := cmd.Run(); err != nil {
+			return fmt.Errorf("%s elevation failed: %w", e.name, err)
+		}
+		return nil
+	}
+
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("%s elevation failed: %w: %s", e.name, err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// appleScriptString 转换为AppleScript字符串字面量
+func appleScriptString(value string) string {
+	value = strings.ReplaceAll(value, `\`, `\\`)
+	value = strings.ReplaceAll(value, `"`, `\"`)
+	return `"` + value + `"`
+}
+
+// isTerminal 检查标准输入是否为终端
+func isTerminal() bool {
+	info, err := os.Stdin.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,23 @@
+package host
+
+import (
+	"time"
+
+	"github.com/flyhigher139/mhost/pkg/hostsfile"
+	"github.com/flyhigher139/mhost/pkg/models"
+)
+
+// AppVersion 写入管理section头部的mHost版本，构建时通过-ldflags "-X" 注入
+var AppVersion = "dev"
+
+// NewSectionHeader 应用profile时写入管理section的头部，包含Profile ID和版本，
+// 供其他mHost安装识别和接管section；校验和在构建section时计算
+func NewSectionHeader(profile *models.Profile, appliedAt time.Time) hostsfile.SectionHeader {
+	return hostsfile.SectionHeader{
+		Profile:   profile.Name,
+		ProfileID: profile.ID,
+		Author:    profile.Author,
+		AppliedAt: appliedAt,
+		Version:   AppVersion,
+	}
+}
@@ -0,0 +1,94 @@
+package host
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// DefaultHistoryLimit 应用历史保留的最大记录数
+const DefaultHistoryLimit = 100
+
+// History 应用历史记录接口
+type History interface {
+	// Record 记录一次应用结果
+	Record(result *ApplyResult) error
+
+	// List 获取应用历史，最新的记录在前
+	List() ([]*ApplyResult, error)
+}
+
+// HistoryImpl 应用历史记录实现，保存在JSON文件中
+type HistoryImpl struct {
+	mu    sync.Mutex
+	path  string
+	limit int
+}
+
+// DefaultHistoryPath 获取数据目录下的应用历史文件路径
+func DefaultHistoryPath(dataDir string) string {
+	return filepath.Join(dataDir, "apply_history.json")
+}
+
+// NewHistory 创建应用历史记录
+func NewHistory(path string) *HistoryImpl {
+	return &HistoryImpl{path: path, limit: DefaultHistoryLimit}
+}
+
+// Record 记录一次应用结果，超过上限时丢弃最早的记录
+func (h *HistoryImpl) Record(result *ApplyResult) error {
+	if result == nil {
+		return nil
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	history, err := h.load()
+	if err != nil {
+		return err
+	}
+
+	history = append([]*ApplyResult{result}, history...)
+	if len(history) > h.limit {
+		history = history[:h.limit]
+	}
+
+	data, err := json.MarshalIndent(history, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal apply history: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(h.path), 0755); err != nil {
+		return fmt.Errorf("failed to create history directory: %w", err)
+	}
+	if err := os.WriteFile(h.path, data, 0644); err != nil {
+		return fmt.Errorf("failed to save apply history: %w", err)
+	}
+	return nil
+}
+
+// List 获取应用历史，最新的记录在前
+func (h *HistoryImpl) List() ([]*ApplyResult, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.load()
+}
+
+// load 读取历史文件，文件不存在时返回空列表
+func (h *HistoryImpl) load() ([]*ApplyResult, error) {
+	data, err := os.ReadFile(h.path)
+	if os.IsNotExist(err) {
+		return []*ApplyResult{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read apply history: %w", err)
+	}
+
+	var history []*ApplyResult
+	if err := json.Unmarshal(data, &history); err != nil {
+		return nil, fmt.Errorf("failed to parse apply history: %w", err)
+	}
+	return history, nil
+}
@@ -0,0 +1,36 @@
+package host
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/flyhigher139/mhost/pkg/models"
+)
+
+// ApplyLayered 按优先级从低到高叠加多个Profile，通过apply生成一个管理section；
+// 结果中记录各层的名称，主机名冲突同时记录为警告。Manager的实现使用它实现ApplyProfiles
+func ApplyLayered(profiles []*models.Profile, options ApplyOptions, apply func(*models.Profile, ApplyOptions) (*ApplyResult, error)) (*ApplyResult, error) {
+	if len(profiles) == 0 {
+		return nil, models.ErrInvalidProfile
+	}
+	for _, profile := range profiles {
+		if profile == nil {
+			return nil, models.ErrInvalidProfile
+		}
+	}
+
+	layered, conflicts := models.LayerProfiles(profiles)
+	result, err := apply(layered, options)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, profile := range profiles {
+		result.Layers = append(result.Layers, profile.Name)
+	}
+	result.Conflicts = conflicts
+	for _, conflict := range conflicts {
+		result.Warnings = append(result.Warnings, fmt.Sprintf("%s: %s overrides %s", conflict.Hostname, conflict.Winner, strings.Join(conflict.Overridden, ", ")))
+	}
+	return result, nil
+}
@@ -0,0 +1,88 @@
+package host
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/flyhigher139/mhost/pkg/models"
+)
+
+// ErrLimitExceeded 写入内容超过配置的规模限制
+var ErrLimitExceeded = errors.New("hosts file exceeds configured limits")
+
+// LimitError 超过规模限制的详细信息，可通过ApplyOptions.IgnoreLimits强制应用
+type LimitError struct {
+	Violations []string
+}
+
+// Error 实现error接口
+func (e *LimitError) Error() string {
+	return fmt.Sprintf("%s: %s", ErrLimitExceeded, strings.Join(e.Violations, "; "))
+}
+
+// Unwrap 支持errors.Is(err, ErrLimitExceeded)
+func (e *LimitError) Unwrap() error {
+	return ErrLimitExceeded
+}
+
+// ApplyOptions 应用Profile的选项
+type ApplyOptions struct {
+	IgnoreLimits bool // 超过规模限制时仍然写入，超限项记录为警告
+}
+
+// HostsStats hosts文件的规模
+type HostsStats struct {
+	Size    int64 `json:"size"`    // 字节数
+	Entries int   `json:"entries"` // 生效的条目数，每个主机名计一条
+	// WidestLine 主机名最多的行号（从1开始），WidestHostnames为该行的主机名数
+	WidestLine      int `json:"widest_line"`
+	WidestHostnames int `json:"widest_hostnames"`
+}
+
+// MeasureHosts 统计hosts内容的规模，被注释的条目不计入
+func MeasureHosts(lines []string) HostsStats {
+	var stats HostsStats
+	for i, raw := range lines {
+		stats.Size += int64(len(raw) + 1)
+
+		text := strings.TrimSpace(raw)
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		if idx := strings.Index(text, "#"); idx >= 0 {
+			text = text[:idx]
+		}
+		fields := strings.Fields(text)
+		if len(fields) < 2 {
+			continue
+		}
+
+		hostnames := len(fields) - 1
+		stats.Entries += hostnames
+		if hostnames > stats.WidestHostnames {
+			stats.WidestHostnames, stats.WidestLine = hostnames, i+1
+		}
+	}
+	return stats
+}
+
+// Violations 超过规模限制的说明
+func (s HostsStats) Violations(limits models.LimitsConfig) []string {
+	var violations []string
+	if limits.MaxHostnamesPerLine > 0 && s.WidestHostnames > limits.MaxHostnamesPerLine {
+		violations = append(violations, fmt.Sprintf("line %d has %d hostnames (limit %d)", s.WidestLine, s.WidestHostnames, limits.MaxHostnamesPerLine))
+	}
+	if limits.MaxEntries > 0 && s.Entries > limits.MaxEntries {
+		violations = append(violations, fmt.Sprintf("%d entries (limit %d)", s.Entries, limits.MaxEntries))
+	}
+	if limits.MaxFileSize > 0 && s.Size > limits.MaxFileSize {
+		violations = append(violations, fmt.Sprintf("%d bytes (limit %d)", s.Size, limits.MaxFileSize))
+	}
+	return violations
+}
+
+// CheckLimits 检查hosts内容是否超过规模限制，返回超限说明
+func CheckLimits(lines []string, limits models.LimitsConfig) []string {
+	return MeasureHosts(lines).Violations(limits)
+}
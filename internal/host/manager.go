@@ -1,15 +1,16 @@
 package host
 
 import (
-	"bufio"
+	"errors"
 	"fmt"
-	"io"
 	"os"
 	"path/filepath"
-	"regexp"
 	"strings"
 	"time"
 
+	"github.com/flyhigher139/mhost/internal/hooks"
+	"github.com/flyhigher139/mhost/internal/rules"
+	"github.com/flyhigher139/mhost/pkg/hostsfile"
 	"github.com/flyhigher139/mhost/pkg/models"
 )
 
@@ -19,22 +20,31 @@ type Manager interface {
 	ReadHostsFile() ([]string, error)
 
 	// WriteHostsFile 写入hosts文件内容
-	WriteHostsFile(lines []string) error
+	WriteHostsFile(lines []string) (*WriteResult, error)
 
-	// ApplyProfile 应用Profile到hosts文件
-	ApplyProfile(profile *models.Profile) error
+	// ApplyProfile 应用Profile到hosts文件，返回本次改动的条目
+	ApplyProfile(profile *models.Profile) (*ApplyResult, error)
+
+	// ApplyProfileWithOptions 按选项应用Profile，超过规模限制时返回*LimitError，违反严重程度为error的校验规则时返回*rules.ViolationError
+	ApplyProfileWithOptions(profile *models.Profile, options ApplyOptions) (*ApplyResult, error)
+
+	// ApplyProfiles 按优先级从低到高叠加多个Profile，生成一个管理section；同一主机名以优先级最高的层为准
+	ApplyProfiles(profiles []*models.Profile, options ApplyOptions) (*ApplyResult, error)
 
 	// BackupHostsFile 备份当前hosts文件
 	BackupHostsFile() (*models.Backup, error)
 
+	// BackupHostsFileWithOptions 备份当前hosts文件并设置里程碑名称和标签
+	BackupHostsFileWithOptions(options BackupOptions) (*models.Backup, error)
+
 	// RestoreFromBackup 从备份恢复hosts文件
 	RestoreFromBackup(backup *models.Backup) error
 
 	// GetHostsFilePath 获取hosts文件路径
 	GetHostsFilePath() string
 
-	// ValidateHostsFile 验证hosts文件格式
-	ValidateHostsFile() error
+	// ValidateHostsFile 验证hosts文件，返回包含全部错误与警告的报告；仅读取失败时返回error
+	ValidateHostsFile() (*ValidationResult, error)
 
 	// ParseHostsFile 解析hosts文件为HostEntry列表
 	ParseHostsFile() ([]*models.HostEntry, error)
@@ -44,13 +54,80 @@ type Manager interface {
 
 	// UpdateManagedSection 更新mHost管理的section
 	UpdateManagedSection(entries []*models.HostEntry) error
+
+	// DetectDrift 检测管理section与Profile之间的差异（例如手动编辑）
+	DetectDrift(profile *models.Profile) (*hostsfile.Drift, error)
+
+	// PreviewApply 预览应用Profile后的hosts文件内容及其与当前文件的差异，不修改hosts文件
+	PreviewApply(profile *models.Profile) (*Preview, error)
+
+	// PatchManagedEntry 在管理section中就地更新单个条目，违反严重程度为error的校验规则时返回*rules.ViolationError，
+	// 加载了转换Hook时返回ErrPatchUnavailable，调用方需要完整应用Profile
+	PatchManagedEntry(entry *models.HostEntry) error
+
+	// SetElevator 设置无写入权限时使用的提权方式，nil表示不提权
+	SetElevator(elevator Elevator)
+
+	// GetElevator 获取当前的提权方式
+	GetElevator() Elevator
+
+	// SetBackupOnApply 设置应用Profile前是否自动备份hosts文件
+	SetBackupOnApply(enabled bool)
+
+	// BackupOnApply 应用Profile前是否自动备份hosts文件
+	BackupOnApply() bool
+
+	// SetDeltaBackups 设置增量备份：hosts文件达到minLines行时以增量方式备份，每fullEvery个增量备份保存一次完整备份；minLines为0时不使用增量备份
+	SetDeltaBackups(minLines, fullEvery int)
+
+	// SetBackupRetention 设置备份的保留条件，备份目录空间不足时按该条件清理旧备份；均为0时空间不足直接返回错误
+	SetBackupRetention(maxBackups int, maxAge time.Duration)
+
+	// SetLimits 设置应用Profile时检查的规模限制
+	SetLimits(limits models.LimitsConfig)
+
+	// SetRules 设置应用Profile时检查的自定义校验规则，nil表示没有规则
+	SetRules(set *rules.Set)
+
+	// Rules 获取当前的自定义校验规则，保存条目时使用同一组规则
+	Rules() *rules.Set
+
+	// SetHooks 设置应用Profile时依次运行的转换Hook，nil表示没有Hook
+	SetHooks(runner *hooks.Runner)
+
+	// Hooks 获取当前的转换Hook
+	Hooks() *hooks.Runner
+
+	// SetGlobalSource 设置全局条目的来源，应用Profile和检测漂移时合并其中的条目；nil表示没有全局条目
+	SetGlobalSource(source GlobalSource)
 }
 
+// ErrPatchUnavailable 加载了转换Hook，单个条目的变化可能影响Hook生成的其他条目，无法就地更新
+var ErrPatchUnavailable = errors.New("entries are rewritten by hooks, apply the whole profile instead")
+
+// GlobalSource 获取全局条目所在的隐式Profile
+type GlobalSource func() (*models.Profile, error)
+
 // ManagerImpl hosts文件管理器实现
 type ManagerImpl struct {
-	hostsPath   string
-	backupDir   string
-	managedMark string
+	hostsPath     string
+	backupDir     string
+	managedMark   string
+	elevator      Elevator
+	backupOnApply bool
+	limits        models.LimitsConfig
+	rules         *rules.Set
+	hooks         *hooks.Runner
+	globalSource  GlobalSource
+
+	// 增量备份：hosts文件达到deltaMinLines行时以增量方式备份，每fullBackupEvery个增量备份保存一次完整备份
+	deltaMinLines   int
+	fullBackupEvery int
+
+	// 备份目录空间不足时按保留条件清理旧备份
+	maxBackups   int
+	maxBackupAge time.Duration
+	freeSpace    func(dir string) (uint64, error)
 }
 
 // NewManager 创建新的hosts文件管理器
@@ -59,10 +136,15 @@ func NewManager(hostsPath, backupDir string) Manager {
 		hostsPath = getDefaultHostsPath()
 	}
 
+	defaults := models.DefaultAppConfig()
 	return &ManagerImpl{
-		hostsPath:   hostsPath,
-		backupDir:   backupDir,
-		managedMark: "# mHost managed section",
+		hostsPath:       hostsPath,
+		backupDir:       backupDir,
+		managedMark:     hostsfile.ManagedMark,
+		limits:          defaults.Limits,
+		deltaMinLines:   defaults.Backup.DeltaMinLines,
+		fullBackupEvery: defaults.Backup.FullBackupEvery,
+		freeSpace:       FreeSpace,
 	}
 }
 
@@ -73,47 +155,56 @@ func getDefaultHostsPath() string {
 
 // ReadHostsFile 读取hosts文件内容
 func (m *ManagerImpl) ReadHostsFile() ([]string, error) {
-	file, err := os.Open(m.hostsPath)
+	// 一次读取整个文件，超长的行（例如异常的超长主机名）不会导致读取失败
+	data, err := os.ReadFile(m.hostsPath)
 	if err != nil {
-		return nil, fmt.Errorf("failed to open hosts file: %w", err)
-	}
-	defer file.Close()
-
-	var lines []string
-	scanner := bufio.NewScanner(file)
-	for scanner.Scan() {
-		lines = append(lines, scanner.Text())
-	}
-
-	if err := scanner.Err(); err != nil {
 		return nil, fmt.Errorf("failed to read hosts file: %w", err)
 	}
 
-	return lines, nil
+	return hostsfile.SplitLines(string(data)), nil
 }
 
 // WriteHostsFile 写入hosts文件内容
-func (m *ManagerImpl) WriteHostsFile(lines []string) error {
+func (m *ManagerImpl) WriteHostsFile(lines []string) (*WriteResult, error) {
+	start := time.Now()
+	result := &WriteResult{HostsPath: m.hostsPath, LinesWritten: len(lines)}
+
 	// 创建临时文件
 	tempFile := m.hostsPath + ".tmp"
 	file, err := os.Create(tempFile)
 	if err != nil {
-		return fmt.Errorf("failed to create temp file: %w", err)
+		// 没有写入权限时通过提权方式写入
+		if os.IsPermission(err) && m.elevator != nil && m.elevator.Available() {
+			var content strings.Builder
+			for _, line := range lines {
+				content.WriteString(line + "\n")
+			}
+			if err := m.elevator.WriteFile(m.hostsPath, []byte(content.String())); err != nil {
+				return nil, err
+			}
+			result.BytesWritten = int64(content.Len())
+			result.Elevated = m.elevator.Name()
+			result.Duration = time.Since(start)
+			return result, nil
+		}
+		return nil, fmt.Errorf("failed to create temp file: %w", err)
 	}
 	defer file.Close()
 
 	// 写入内容
 	for _, line := range lines {
-		if _, err := file.WriteString(line + "\n"); err != nil {
+		n, err := file.WriteString(line + "\n")
+		if err != nil {
 			os.Remove(tempFile)
-			return fmt.Errorf("failed to write to temp file: %w", err)
+			return nil, fmt.Errorf("failed to write to temp file: %w", err)
 		}
+		result.BytesWritten += int64(n)
 	}
 
 	// 同步到磁盘
 	if err := file.Sync(); err != nil {
 		os.Remove(tempFile)
-		return fmt.Errorf("failed to sync temp file: %w", err)
+		return nil, fmt.Errorf("failed to sync temp file: %w", err)
 	}
 
 	file.Close()
@@ -121,53 +212,126 @@ func (m *ManagerImpl) WriteHostsFile(lines []string) error {
 	// 原子性替换
 	if err := os.Rename(tempFile, m.hostsPath); err != nil {
 		os.Remove(tempFile)
-		return fmt.Errorf("failed to replace hosts file: %w", err)
+		return nil, fmt.Errorf("failed to replace hosts file: %w", err)
 	}
 
-	return nil
+	result.Duration = time.Since(start)
+	return result, nil
+}
+
+// ApplyProfile 应用Profile到hosts文件，返回本次改动的条目
+func (m *ManagerImpl) ApplyProfile(profile *models.Profile) (*ApplyResult, error) {
+	return m.ApplyProfileWithOptions(profile, ApplyOptions{})
 }
 
-// ApplyProfile 应用Profile到hosts文件
-func (m *ManagerImpl) ApplyProfile(profile *models.Profile) error {
+// ApplyProfileWithOptions 按选项应用Profile，超过规模限制时返回*LimitError。
+// 违反严重程度为error的校验规则时返回*rules.ViolationError，IgnoreLimits不影响校验规则
+func (m *ManagerImpl) ApplyProfileWithOptions(profile *models.Profile, options ApplyOptions) (*ApplyResult, error) {
 	if profile == nil {
-		return models.ErrInvalidProfile
+		return nil, models.ErrInvalidProfile
 	}
 
-	// 读取当前hosts文件
-	lines, err := m.ReadHostsFile()
+	start := time.Now()
+	appliedAt := time.Now()
+	lines, newLines, entries, err := m.renderApply(profile, appliedAt)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	// 移除现有的mHost管理section
-	newLines := m.removeManagedSection(lines)
+	// 检查规模限制
+	violations := CheckLimits(newLines, m.limits)
+	if len(violations) > 0 && !options.IgnoreLimits {
+		return nil, &LimitError{Violations: violations}
+	}
 
-	// 添加新的mHost管理section
-	if len(profile.Entries) > 0 {
-		newLines = append(newLines, "")
-		newLines = append(newLines, m.managedMark+" START")
-		newLines = append(newLines, fmt.Sprintf("# Profile: %s", profile.Name))
-		newLines = append(newLines, fmt.Sprintf("# Applied at: %s", time.Now().Format(time.RFC3339)))
+	// 检查自定义校验规则
+	ruleWarnings, err := m.rules.Enforce(entries)
+	if err != nil {
+		return nil, err
+	}
 
-		for _, entry := range profile.Entries {
-			if entry.Enabled {
-				line := fmt.Sprintf("%s\t%s", entry.IP, entry.Hostname)
-				if entry.Comment != "" {
-					line += fmt.Sprintf("\t# %s", entry.Comment)
-				}
-				newLines = append(newLines, line)
-			}
+	// 应用前备份
+	var backupPath string
+	if m.backupOnApply && m.backupDir != "" {
+		backup, err := m.backupHostsFile(true, BackupOptions{})
+		if err != nil {
+			return nil, err
 		}
-
-		newLines = append(newLines, m.managedMark+" END")
+		backupPath = backup.FilePath
 	}
 
 	// 写入hosts文件
-	return m.WriteHostsFile(newLines)
+	written, err := m.WriteHostsFile(newLines)
+	if err != nil {
+		return nil, err
+	}
+
+	result := NewApplyResult(hostsfile.Parse(hostsfile.ExtractManagedSection(lines)), entries)
+	result.ProfileID = profile.ID
+	result.ProfileName = profile.Name
+	result.HostsPath = m.hostsPath
+	result.BackupPath = backupPath
+	result.AppliedAt = appliedAt
+	result.LinesWritten = written.LinesWritten
+	result.Elevated = written.Elevated
+	result.Warnings = issueMessages(hostsfile.Check(newLines).Warnings())
+	for _, violation := range violations {
+		result.Warnings = append(result.Warnings, "limit overridden: "+violation)
+	}
+	for _, violation := range ruleWarnings {
+		result.Warnings = append(result.Warnings, "rule: "+violation.String())
+	}
+	result.Duration = time.Since(start)
+	return result, nil
+}
+
+// renderApply 读取当前hosts文件并替换mHost管理section，返回当前内容、应用后的内容以及写入管理section的条目
+func (m *ManagerImpl) renderApply(profile *models.Profile, appliedAt time.Time) ([]string, []string, []hostsfile.Entry, error) {
+	lines, err := m.ReadHostsFile()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	entries, err := m.managedEntries(profile)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	section := NewSectionHeader(profile, appliedAt).Build(entries)
+	return lines, hostsfile.ReplaceManagedSection(lines, section), entries, nil
+}
+
+// PreviewApply 预览应用Profile后的hosts文件内容及其与当前文件的差异，不修改hosts文件
+func (m *ManagerImpl) PreviewApply(profile *models.Profile) (*Preview, error) {
+	if profile == nil {
+		return nil, models.ErrInvalidProfile
+	}
+
+	lines, newLines, entries, err := m.renderApply(profile, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	preview := NewPreview(profile, m.hostsPath, lines, newLines, entries, m.limits)
+	preview.RuleViolations = m.rules.CheckEntries(entries)
+	return preview, nil
+}
+
+// ApplyProfiles 按优先级从低到高叠加多个Profile，生成一个管理section
+func (m *ManagerImpl) ApplyProfiles(profiles []*models.Profile, options ApplyOptions) (*ApplyResult, error) {
+	return ApplyLayered(profiles, options, m.ApplyProfileWithOptions)
 }
 
 // BackupHostsFile 备份当前hosts文件
 func (m *ManagerImpl) BackupHostsFile() (*models.Backup, error) {
+	return m.backupHostsFile(false, BackupOptions{})
+}
+
+// BackupHostsFileWithOptions 备份当前hosts文件，里程碑名称和标签保存在备份目录的标签文件中
+func (m *ManagerImpl) BackupHostsFileWithOptions(options BackupOptions) (*models.Backup, error) {
+	return m.backupHostsFile(false, options)
+}
+
+// backupHostsFile 备份当前hosts文件，自动备份使用单独的文件名前缀以便与手动备份区分
+func (m *ManagerImpl) backupHostsFile(automatic bool, options BackupOptions) (*models.Backup, error) {
 	// 确保备份目录存在
 	if err := os.MkdirAll(m.backupDir, 0755); err != nil {
 		return nil, fmt.Errorf("failed to create backup directory: %w", err)
@@ -175,45 +339,89 @@ func (m *ManagerImpl) BackupHostsFile() (*models.Backup, error) {
 
 	// 生成备份文件名
 	timestamp := time.Now().Format("20060102_150405")
-	backupFileName := fmt.Sprintf("hosts_backup_%s.txt", timestamp)
-	backupPath := filepath.Join(m.backupDir, backupFileName)
-
-	// 复制hosts文件
-	srcFile, err := os.Open(m.hostsPath)
+	prefix := BackupFilePrefix
+	if automatic {
+		prefix = AutoBackupFilePrefix
+	}
+	content, err := os.ReadFile(m.hostsPath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open hosts file: %w", err)
 	}
-	defer srcFile.Close()
 
-	dstFile, err := os.Create(backupPath)
-	if err != nil {
+	// 超大的hosts文件以增量方式保存，相对于最新的完整备份只记录变化的行
+	backupFileName := fmt.Sprintf("%s%s.txt", prefix, timestamp)
+	data := content
+	if delta, ok := m.deltaBackup(content); ok {
+		backupFileName = fmt.Sprintf("%s%s%s", prefix, timestamp, DeltaBackupSuffix)
+		data = delta
+	}
+	// 空间不足时写入会得到被截断的备份，先检查剩余空间
+	if err := CheckBackupSpace(m.backupDir, int64(len(data)), m.maxBackups, m.maxBackupAge, m.freeSpace); err != nil {
+		return nil, err
+	}
+	backupPath := filepath.Join(m.backupDir, backupFileName)
+	if err := os.WriteFile(backupPath, data, 0644); err != nil {
+		os.Remove(backupPath)
 		return nil, fmt.Errorf("failed to create backup file: %w", err)
 	}
-	defer dstFile.Close()
+	size := int64(len(data))
 
-	size, err := io.Copy(dstFile, srcFile)
-	if err != nil {
-		return nil, fmt.Errorf("failed to copy hosts file: %w", err)
+	// 创建备份记录
+	backupType, description := models.BackupTypeManual, "Manual hosts file backup"
+	if automatic {
+		backupType, description = models.BackupTypeAutomatic, "Automatic hosts file backup before apply"
+	}
+	// 保存里程碑名称和标签
+	label := BackupLabel{Milestone: strings.TrimSpace(options.Milestone), Tags: NormalizeTags(options.Tags)}
+	if !label.IsZero() {
+		if err := SetBackupLabel(m.backupDir, backupFileName, label); err != nil {
+			return nil, err
+		}
+	}
+	tags := append([]string{string(backupType), "hosts"}, label.Tags...)
+	if label.Milestone != "" {
+		description = label.Milestone
+		tags = append(tags, MilestoneTag)
 	}
 
-	// 创建备份记录
 	backup := &models.Backup{
 		ID:           fmt.Sprintf("backup_%d", time.Now().Unix()),
-		Type:         models.BackupTypeManual,
+		Type:         backupType,
 		FilePath:     backupPath,
 		OriginalPath: m.hostsPath,
 		Size:         size,
 		CreatedAt:    time.Now(),
 		Metadata: models.BackupMetadata{
 			Version:     "1.0",
-			Description: "Manual hosts file backup",
-			Tags:        []string{"manual", "hosts"},
+			Description: description,
+			Tags:        tags,
 		},
 	}
 
 	return backup, nil
 }
 
+// deltaBackup hosts文件达到增量备份的行数时，生成相对于最新完整备份的增量备份；
+// 没有可用的完整备份、已连续保存了足够多的增量备份或增量不比完整备份小时返回false
+func (m *ManagerImpl) deltaBackup(content []byte) ([]byte, bool) {
+	if m.deltaMinLines <= 0 || strings.Count(string(content), "\n") < m.deltaMinLines {
+		return nil, false
+	}
+	base, ok := chooseDeltaBase(m.backupDir, m.fullBackupEvery)
+	if !ok {
+		return nil, false
+	}
+	baseContent, err := os.ReadFile(base.Path)
+	if err != nil {
+		return nil, false
+	}
+	delta, err := encodeDelta(base.Name, baseContent, content)
+	if err != nil || len(delta) >= len(content)/2 {
+		return nil, false
+	}
+	return delta, true
+}
+
 // RestoreFromBackup 从备份恢复hosts文件
 func (m *ManagerImpl) RestoreFromBackup(backup *models.Backup) error {
 	if backup == nil {
@@ -225,21 +433,13 @@ func (m *ManagerImpl) RestoreFromBackup(backup *models.Backup) error {
 		return models.ErrBackupNotFound
 	}
 
-	// 复制备份文件到hosts文件
-	srcFile, err := os.Open(backup.FilePath)
+	// 读取备份内容，增量备份由其完整备份重建并验证
+	content, err := ReadBackupFile(backup.FilePath)
 	if err != nil {
 		return fmt.Errorf("failed to open backup file: %w", err)
 	}
-	defer srcFile.Close()
-
-	dstFile, err := os.Create(m.hostsPath)
-	if err != nil {
-		return fmt.Errorf("failed to create hosts file: %w", err)
-	}
-	defer dstFile.Close()
 
-	_, err = io.Copy(dstFile, srcFile)
-	if err != nil {
+	if err := os.WriteFile(m.hostsPath, content, 0644); err != nil {
 		return fmt.Errorf("failed to restore hosts file: %w", err)
 	}
 
@@ -251,47 +451,19 @@ func (m *ManagerImpl) GetHostsFilePath() string {
 	return m.hostsPath
 }
 
-// ValidateHostsFile 验证hosts文件格式
-func (m *ManagerImpl) ValidateHostsFile() error {
+// ValidateHostsFile 验证hosts文件，返回包含全部错误与警告的报告；仅读取失败时返回error
+func (m *ManagerImpl) ValidateHostsFile() (*ValidationResult, error) {
 	lines, err := m.ReadHostsFile()
 	if err != nil {
-		return err
-	}
-
-	// IP地址正则表达式
-	ipv4Regex := regexp.MustCompile(`^((25[0-5]|2[0-4][0-9]|[01]?[0-9][0-9]?)\.){3}(25[0-5]|2[0-4][0-9]|[01]?[0-9][0-9]?)$`)
-	ipv6Regex := regexp.MustCompile(`^([0-9a-fA-F]{1,4}:){7}[0-9a-fA-F]{1,4}$|^::1$|^::$`)
-	hostnameRegex := regexp.MustCompile(`^[a-zA-Z0-9]([a-zA-Z0-9\-]{0,61}[a-zA-Z0-9])?(\.([a-zA-Z0-9]([a-zA-Z0-9\-]{0,61}[a-zA-Z0-9])?))*$`)
-
-	for i, line := range lines {
-		line = strings.TrimSpace(line)
-
-		// 跳过空行和注释行
-		if line == "" || strings.HasPrefix(line, "#") {
-			continue
-		}
-
-		// 分割IP和hostname
-		fields := strings.Fields(line)
-		if len(fields) < 2 {
-			return fmt.Errorf("invalid hosts entry at line %d: %s", i+1, line)
-		}
-
-		ip := fields[0]
-		hostname := fields[1]
-
-		// 验证IP地址
-		if !ipv4Regex.MatchString(ip) && !ipv6Regex.MatchString(ip) {
-			return fmt.Errorf("invalid IP address at line %d: %s", i+1, ip)
-		}
-
-		// 验证hostname
-		if !hostnameRegex.MatchString(hostname) {
-			return fmt.Errorf("invalid hostname at line %d: %s", i+1, hostname)
-		}
+		return nil, err
 	}
 
-	return nil
+	return &ValidationResult{
+		HostsPath: m.hostsPath,
+		Lines:     len(lines),
+		Entries:   len(hostsfile.Parse(lines)),
+		Issues:    hostsfile.Check(lines).Issues,
+	}, nil
 }
 
 // ParseHostsFile 解析hosts文件为HostEntry列表
@@ -302,42 +474,10 @@ func (m *ManagerImpl) ParseHostsFile() ([]*models.HostEntry, error) {
 	}
 
 	var entries []*models.HostEntry
-
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-
-		// 跳过空行和注释行
-		if line == "" || strings.HasPrefix(line, "#") {
-			continue
-		}
-
-		// 分割IP、hostname和注释
-		parts := strings.SplitN(line, "#", 2)
-		hostPart := strings.TrimSpace(parts[0])
-		comment := ""
-		if len(parts) > 1 {
-			comment = strings.TrimSpace(parts[1])
-		}
-
-		fields := strings.Fields(hostPart)
-		if len(fields) < 2 {
-			continue
-		}
-
-		ip := fields[0]
-		// 处理多个hostname
-		for _, hostname := range fields[1:] {
-			entry := &models.HostEntry{
-				ID:        fmt.Sprintf("%s_%s_%d", ip, hostname, time.Now().UnixNano()),
-				IP:        ip,
-				Hostname:  hostname,
-				Comment:   comment,
-				Enabled:   true,
-				CreatedAt: time.Now(),
-				UpdatedAt: time.Now(),
-			}
-			entries = append(entries, entry)
-		}
+	for _, parsed := range hostsfile.Parse(lines) {
+		entry := parsed.ToModel()
+		entry.ID = fmt.Sprintf("%s_%s_%d", parsed.IP, parsed.Hostname, time.Now().UnixNano())
+		entries = append(entries, entry)
 	}
 
 	return entries, nil
@@ -350,24 +490,7 @@ func (m *ManagerImpl) GetManagedSection() ([]string, error) {
 		return nil, err
 	}
 
-	var managedLines []string
-	inManagedSection := false
-
-	for _, line := range lines {
-		if strings.Contains(line, m.managedMark+" START") {
-			inManagedSection = true
-			continue
-		}
-		if strings.Contains(line, m.managedMark+" END") {
-			inManagedSection = false
-			continue
-		}
-		if inManagedSection {
-			managedLines = append(managedLines, line)
-		}
-	}
-
-	return managedLines, nil
+	return hostsfile.ExtractManagedSection(lines), nil
 }
 
 // UpdateManagedSection 更新mHost管理的section
@@ -378,50 +501,140 @@ func (m *ManagerImpl) UpdateManagedSection(entries []*models.HostEntry) error {
 		return err
 	}
 
-	// 移除现有的mHost管理section
-	newLines := m.removeManagedSection(lines)
+	// 替换mHost管理section
+	header := []string{fmt.Sprintf("# Updated at: %s", time.Now().Format(time.RFC3339))}
+	section := hostsfile.BuildSignedSection(header, hostsfile.FromModels(entries))
+	newLines := hostsfile.ReplaceManagedSection(lines, section)
 
-	// 添加新的mHost管理section
-	if len(entries) > 0 {
-		newLines = append(newLines, "")
-		newLines = append(newLines, m.managedMark+" START")
-		newLines = append(newLines, fmt.Sprintf("# Updated at: %s", time.Now().Format(time.RFC3339)))
+	// 写入hosts文件
+	_, err = m.WriteHostsFile(newLines)
+	return err
+}
 
-		for _, entry := range entries {
-			if entry.Enabled {
-				line := fmt.Sprintf("%s\t%s", entry.IP, entry.Hostname)
-				if entry.Comment != "" {
-					line += fmt.Sprintf("\t# %s", entry.Comment)
-				}
-				newLines = append(newLines, line)
-			}
-		}
+// DetectDrift 检测管理section与Profile之间的差异（例如手动编辑）
+func (m *ManagerImpl) DetectDrift(profile *models.Profile) (*hostsfile.Drift, error) {
+	if profile == nil {
+		return nil, models.ErrInvalidProfile
+	}
 
-		newLines = append(newLines, m.managedMark+" END")
+	entries, err := m.managedEntries(profile)
+	if err != nil {
+		return nil, err
 	}
 
-	// 写入hosts文件
-	return m.WriteHostsFile(newLines)
+	managed, err := m.GetManagedSection()
+	if err != nil {
+		return nil, err
+	}
+
+	return hostsfile.DetectSectionDrift(entries, managed), nil
 }
 
-// removeManagedSection 移除mHost管理的section
-func (m *ManagerImpl) removeManagedSection(lines []string) []string {
-	var newLines []string
-	inManagedSection := false
+// managedEntries 写入管理section的条目：全局条目排在Profile的条目之前，再依次经过转换Hook
+func (m *ManagerImpl) managedEntries(profile *models.Profile) ([]hostsfile.Entry, error) {
+	merged, err := m.withGlobal(profile)
+	if err != nil {
+		return nil, err
+	}
+	return m.hooks.Transform(profile, hostsfile.FromModels(merged.ResolvedEntries()))
+}
 
-	for _, line := range lines {
-		if strings.Contains(line, m.managedMark+" START") {
-			inManagedSection = true
-			continue
-		}
-		if strings.Contains(line, m.managedMark+" END") {
-			inManagedSection = false
-			continue
-		}
-		if !inManagedSection {
-			newLines = append(newLines, line)
-		}
+// withGlobal 合并全局条目，没有设置全局条目来源时返回profile本身
+func (m *ManagerImpl) withGlobal(profile *models.Profile) (*models.Profile, error) {
+	if m.globalSource == nil {
+		return profile, nil
+	}
+	global, err := m.globalSource()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load global entries: %w", err)
+	}
+	return profile.WithGlobal(global), nil
+}
+
+// PatchManagedEntry 在管理section中就地更新单个条目，不存在管理section时返回hostsfile.ErrNoManagedSection
+func (m *ManagerImpl) PatchManagedEntry(entry *models.HostEntry) error {
+	if entry == nil {
+		return models.ErrHostEntryNotFound
+	}
+	if m.hooks.Len() > 0 {
+		return ErrPatchUnavailable
 	}
+	if _, err := m.rules.Enforce([]hostsfile.Entry{hostsfile.FromModel(entry)}); err != nil {
+		return err
+	}
+
+	lines, err := m.ReadHostsFile()
+	if err != nil {
+		return err
+	}
+
+	newLines, err := hostsfile.PatchEntry(lines, hostsfile.FromModel(entry))
+	if err != nil {
+		return err
+	}
+
+	_, err = m.WriteHostsFile(newLines)
+	return err
+}
+
+// SetElevator 设置无写入权限时使用的提权方式，nil表示不提权
+func (m *ManagerImpl) SetElevator(elevator Elevator) {
+	m.elevator = elevator
+}
+
+// GetElevator 获取当前的提权方式
+func (m *ManagerImpl) GetElevator() Elevator {
+	return m.elevator
+}
+
+// SetBackupOnApply 设置应用Profile前是否自动备份hosts文件
+func (m *ManagerImpl) SetBackupOnApply(enabled bool) {
+	m.backupOnApply = enabled
+}
+
+// BackupOnApply 应用Profile前是否自动备份hosts文件
+func (m *ManagerImpl) BackupOnApply() bool {
+	return m.backupOnApply
+}
+
+// SetDeltaBackups 设置增量备份的行数阈值和完整备份的间隔
+func (m *ManagerImpl) SetDeltaBackups(minLines, fullEvery int) {
+	m.deltaMinLines = minLines
+	m.fullBackupEvery = fullEvery
+}
+
+// SetBackupRetention 设置备份目录空间不足时清理旧备份的保留条件
+func (m *ManagerImpl) SetBackupRetention(maxBackups int, maxAge time.Duration) {
+	m.maxBackups = maxBackups
+	m.maxBackupAge = maxAge
+}
+
+// SetGlobalSource 设置全局条目的来源，应用Profile和检测漂移时合并其中的条目；nil表示没有全局条目
+func (m *ManagerImpl) SetGlobalSource(source GlobalSource) {
+	m.globalSource = source
+}
+
+// SetLimits 设置应用Profile时检查的规模限制
+func (m *ManagerImpl) SetLimits(limits models.LimitsConfig) {
+	m.limits = limits
+}
+
+// SetRules 设置应用Profile时检查的自定义校验规则
+func (m *ManagerImpl) SetRules(set *rules.Set) {
+	m.rules = set
+}
+
+// Rules 获取当前的自定义校验规则
+func (m *ManagerImpl) Rules() *rules.Set {
+	return m.rules
+}
+
+// SetHooks 设置应用Profile时依次运行的转换Hook
+func (m *ManagerImpl) SetHooks(runner *hooks.Runner) {
+	m.hooks = runner
+}
 
-	return newLines
+// Hooks 获取当前的转换Hook
+func (m *ManagerImpl) Hooks() *hooks.Runner {
+	return m.hooks
 }
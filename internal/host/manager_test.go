@@ -12,6 +12,11 @@ import (
 	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
 
+	"github.com/flyhigher139/mhost/internal/config"
+	"github.com/flyhigher139/mhost/internal/hooks"
+	"github.com/flyhigher139/mhost/internal/rules"
+	"github.com/flyhigher139/mhost/pkg/hostsfile"
+	"github.com/flyhigher139/mhost/pkg/hostsfile/hostsfiletest"
 	"github.com/flyhigher139/mhost/pkg/models"
 )
 
@@ -71,6 +76,14 @@ func (suite *HostManagerTestSuite) TestReadHostsFile() {
 	assert.Equal(suite.T(), "::1\t\tlocalhost", lines[1])
 	assert.Equal(suite.T(), "# Test comment", lines[2])
 	assert.Equal(suite.T(), "192.168.1.100\ttest.local\t# Test entry", lines[3])
+
+	// 超过bufio默认缓冲区的超长行不影响读取
+	longLine := "10.0.0.1\t" + strings.Repeat("a", 70000)
+	hostsPath := filepath.Join(suite.T().TempDir(), "hosts")
+	require.NoError(suite.T(), os.WriteFile(hostsPath, []byte("127.0.0.1\tlocalhost\n"+longLine+"\n"), 0644))
+	lines, err = NewManager(hostsPath, "").ReadHostsFile()
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), []string{"127.0.0.1\tlocalhost", longLine}, lines)
 }
 
 // TestWriteHostsFile 测试写入hosts文件
@@ -80,8 +93,11 @@ func (suite *HostManagerTestSuite) TestWriteHostsFile() {
 		"192.168.1.200\tnew.local",
 	}
 
-	err := suite.manager.WriteHostsFile(newLines)
+	result, err := suite.manager.WriteHostsFile(newLines)
 	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 2, result.LinesWritten)
+	assert.Equal(suite.T(), int64(len("127.0.0.1\tlocalhost\n192.168.1.200\tnew.local\n")), result.BytesWritten)
+	assert.Empty(suite.T(), result.Elevated)
 
 	// 验证文件内容
 	lines, err := suite.manager.ReadHostsFile()
@@ -117,7 +133,7 @@ func (suite *HostManagerTestSuite) TestApplyProfile() {
 		UpdatedAt: time.Now(),
 	}
 
-	err := suite.manager.ApplyProfile(profile)
+	_, err := suite.manager.ApplyProfile(profile)
 	assert.NoError(suite.T(), err)
 
 	// 验证hosts文件内容
@@ -167,7 +183,7 @@ func (suite *HostManagerTestSuite) TestApplyEmptyProfile() {
 		UpdatedAt:   time.Now(),
 	}
 
-	err := suite.manager.ApplyProfile(profile)
+	_, err := suite.manager.ApplyProfile(profile)
 	assert.NoError(suite.T(), err)
 
 	// 验证hosts文件内容（应该只包含原始内容，没有mHost section）
@@ -187,11 +203,236 @@ func (suite *HostManagerTestSuite) TestApplyEmptyProfile() {
 
 // TestApplyNilProfile 测试应用nil Profile
 func (suite *HostManagerTestSuite) TestApplyNilProfile() {
-	err := suite.manager.ApplyProfile(nil)
+	_, err := suite.manager.ApplyProfile(nil)
 	assert.Error(suite.T(), err)
 	assert.Equal(suite.T(), models.ErrInvalidProfile, err)
 }
 
+// TestApplyProfileResult 测试应用结果中的改动统计、应用前备份与应用历史
+func (suite *HostManagerTestSuite) TestApplyProfileResult() {
+	profile := models.NewProfile("Result Profile", "")
+	profile.AddEntry(models.NewHostEntry("192.168.1.10", "app.local", ""))
+	profile.AddEntry(models.NewHostEntry("192.168.1.11", "api.local", ""))
+
+	result, err := suite.manager.ApplyProfile(profile)
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "Result Profile", result.ProfileName)
+	assert.Len(suite.T(), result.Added, 2)
+	assert.Empty(suite.T(), result.BackupPath)
+
+	profile.Entries[0].IP = "192.168.1.20"
+	profile.Entries = profile.Entries[:1]
+	profile.AddEntry(models.NewHostEntry("192.168.1.12", "web.local", ""))
+	suite.manager.SetBackupOnApply(true)
+	defer suite.manager.SetBackupOnApply(false)
+
+	result, err = suite.manager.ApplyProfile(profile)
+	require.NoError(suite.T(), err)
+	require.Len(suite.T(), result.Added, 1)
+	assert.Equal(suite.T(), "web.local", result.Added[0].Hostname)
+	require.Len(suite.T(), result.Removed, 1)
+	assert.Equal(suite.T(), "api.local", result.Removed[0].Hostname)
+	require.Len(suite.T(), result.Changed, 1)
+	assert.Equal(suite.T(), "192.168.1.20", result.Changed[0].Expected.IP)
+	assert.Equal(suite.T(), 0, result.Unchanged)
+	assert.FileExists(suite.T(), result.BackupPath)
+
+	result, err = suite.manager.ApplyProfile(profile)
+	require.NoError(suite.T(), err)
+	assert.False(suite.T(), result.HasChanges())
+	assert.Equal(suite.T(), 2, result.Unchanged)
+
+	history := NewHistory(DefaultHistoryPath(suite.T().TempDir()))
+	history.limit = 1
+	require.NoError(suite.T(), history.Record(&ApplyResult{ProfileName: "old"}))
+	require.NoError(suite.T(), history.Record(result))
+	records, err := history.List()
+	require.NoError(suite.T(), err)
+	require.Len(suite.T(), records, 1)
+	assert.Equal(suite.T(), "Result Profile", records[0].ProfileName)
+	assert.Equal(suite.T(), 2, records[0].Unchanged)
+}
+
+// TestApplyProfileLimits 测试应用时的规模限制与强制覆盖
+func (suite *HostManagerTestSuite) TestApplyProfileLimits() {
+	profile := models.NewProfile("Large Profile", "")
+	for i := 0; i < 3; i++ {
+		profile.AddEntry(models.NewHostEntry("10.0.0.1", fmt.Sprintf("host%d.test", i), ""))
+	}
+
+	before, err := os.ReadFile(suite.hostsPath)
+	require.NoError(suite.T(), err)
+
+	suite.manager.SetLimits(models.LimitsConfig{MaxEntries: 2})
+	defer suite.manager.SetLimits(models.DefaultAppConfig().Limits)
+
+	_, err = suite.manager.ApplyProfile(profile)
+	require.ErrorIs(suite.T(), err, ErrLimitExceeded)
+	var limitErr *LimitError
+	require.ErrorAs(suite.T(), err, &limitErr)
+	require.Len(suite.T(), limitErr.Violations, 1)
+	assert.Contains(suite.T(), limitErr.Violations[0], "limit 2")
+
+	after, err := os.ReadFile(suite.hostsPath)
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), before, after, "hosts file must not be written when limits are exceeded")
+
+	result, err := suite.manager.ApplyProfileWithOptions(profile, ApplyOptions{IgnoreLimits: true})
+	require.NoError(suite.T(), err)
+	assert.Len(suite.T(), result.Added, 3)
+	assert.Contains(suite.T(), strings.Join(result.Warnings, "\n"), "limit overridden")
+
+	suite.manager.SetLimits(models.LimitsConfig{})
+	result, err = suite.manager.ApplyProfile(profile)
+	require.NoError(suite.T(), err)
+	assert.NotContains(suite.T(), strings.Join(result.Warnings, "\n"), "limit overridden")
+}
+
+// TestApplyProfileRules 测试应用时检查自定义校验规则，error规则不能被强制覆盖
+func (suite *HostManagerTestSuite) TestApplyProfileRules() {
+	profile := models.NewProfile("Rules Profile", "")
+	profile.AddEntry(models.NewHostEntry("8.8.8.8", "dns.test", ""))
+	profile.AddEntry(models.NewHostEntry("10.0.0.1", "api.test", ""))
+
+	set, err := rules.Parse([]byte(`
+rules:
+  - name: no-public-dns
+    banned_ips: ["8.8.8.8"]
+  - name: ticket
+    severity: warning
+    comment: 'ticket='
+`))
+	require.NoError(suite.T(), err)
+	suite.manager.SetRules(set)
+	defer suite.manager.SetRules(nil)
+
+	before, err := os.ReadFile(suite.hostsPath)
+	require.NoError(suite.T(), err)
+	_, err = suite.manager.ApplyProfileWithOptions(profile, ApplyOptions{IgnoreLimits: true})
+	var violationErr *rules.ViolationError
+	require.ErrorAs(suite.T(), err, &violationErr)
+	require.Len(suite.T(), violationErr.Violations, 1)
+	assert.Equal(suite.T(), "dns.test", violationErr.Violations[0].Hostname)
+	after, err := os.ReadFile(suite.hostsPath)
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), before, after, "hosts file must not be written when rules are violated")
+
+	preview, err := suite.manager.PreviewApply(profile)
+	require.NoError(suite.T(), err)
+	assert.Len(suite.T(), preview.RuleViolations, 3)
+
+	profile.Entries[0].Enabled = false
+	result, err := suite.manager.ApplyProfile(profile)
+	require.NoError(suite.T(), err)
+	assert.Contains(suite.T(), strings.Join(result.Warnings, "\n"), "rule: api.test (10.0.0.1)")
+}
+
+// TestApplyProfileHooks 测试应用、预览和漂移检测都使用Hook转换后的条目，且不能就地更新单个条目
+func (suite *HostManagerTestSuite) TestApplyProfileHooks() {
+	profile := models.NewProfile("Hooks Profile", "")
+	profile.AddEntry(models.NewHostEntry("10.0.0.1", "api.test", ""))
+
+	dir := suite.T().TempDir()
+	require.NoError(suite.T(), os.WriteFile(filepath.Join(dir, "mirror.star"), []byte(`
+def transform(profile, entries):
+    return entries + [{"ip": e["ip"], "hostname": "mirror." + e["hostname"]} for e in entries]
+`), 0644))
+	runner, err := hooks.Load(dir, nil, 0)
+	require.NoError(suite.T(), err)
+	suite.manager.SetHooks(runner)
+	defer suite.manager.SetHooks(nil)
+
+	preview, err := suite.manager.PreviewApply(profile)
+	require.NoError(suite.T(), err)
+	assert.Contains(suite.T(), preview.Content, "10.0.0.1\tmirror.api.test")
+
+	_, err = suite.manager.ApplyProfile(profile)
+	require.NoError(suite.T(), err)
+	content, err := os.ReadFile(suite.hostsPath)
+	require.NoError(suite.T(), err)
+	assert.Contains(suite.T(), string(content), "mirror.api.test")
+
+	drift, err := suite.manager.DetectDrift(profile)
+	require.NoError(suite.T(), err)
+	assert.False(suite.T(), drift.HasDrift())
+
+	assert.ErrorIs(suite.T(), suite.manager.PatchManagedEntry(profile.Entries[0]), ErrPatchUnavailable)
+}
+
+// TestPreviewApply 测试预览应用后的内容与差异，且不修改hosts文件
+func (suite *HostManagerTestSuite) TestPreviewApply() {
+	profile := models.NewProfile("Preview Profile", "")
+	profile.AddEntry(models.NewHostEntry("192.168.1.10", "app.local", ""))
+	profile.AddEntry(models.NewHostEntry("10.0.0.1", "host1.test", ""))
+
+	before, err := os.ReadFile(suite.hostsPath)
+	require.NoError(suite.T(), err)
+
+	suite.manager.SetLimits(models.LimitsConfig{MaxEntries: 2})
+	defer suite.manager.SetLimits(models.DefaultAppConfig().Limits)
+
+	preview, err := suite.manager.PreviewApply(profile)
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "Preview Profile", preview.ProfileName)
+	assert.Equal(suite.T(), suite.hostsPath, preview.HostsPath)
+	assert.True(suite.T(), preview.HasChanges())
+	assert.Len(suite.T(), preview.Entries.Added, 2)
+	assert.Len(suite.T(), preview.Violations, 1)
+	assert.Equal(suite.T(), 3, preview.Before.Entries)
+	assert.Equal(suite.T(), 5, preview.After.Entries)
+	assert.Greater(suite.T(), preview.After.Size, preview.Before.Size)
+	assert.Equal(suite.T(), 2, preview.Limits.MaxEntries)
+	assert.Equal(suite.T(), strings.Split(suite.originalHosts, "\n"), preview.Content[:4])
+	assert.Contains(suite.T(), preview.Content, "10.0.0.1\thost1.test")
+	added, removed := preview.LineStats()
+	assert.Equal(suite.T(), len(preview.Content)-4, added)
+	assert.Equal(suite.T(), 0, removed)
+
+	after, err := os.ReadFile(suite.hostsPath)
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), before, after, "preview must not write the hosts file")
+
+	// 应用后再次预览，只有头部的应用时间可能变化
+	_, err = suite.manager.ApplyProfileWithOptions(profile, ApplyOptions{IgnoreLimits: true})
+	require.NoError(suite.T(), err)
+	preview, err = suite.manager.PreviewApply(profile)
+	require.NoError(suite.T(), err)
+	assert.False(suite.T(), preview.HasChanges())
+	assert.Equal(suite.T(), 2, preview.Entries.Unchanged)
+
+	_, err = suite.manager.PreviewApply(nil)
+	assert.ErrorIs(suite.T(), err, models.ErrInvalidProfile)
+}
+
+// TestApplyProfileEnvironment 测试按Profile环境选择条目变体的IP
+func (suite *HostManagerTestSuite) TestApplyProfileEnvironment() {
+	profile := models.NewProfile("Matrix Profile", "")
+	api := models.NewHostEntry("10.0.0.1", "api.test", "")
+	api.Variants = models.EntryVariants{"staging": "10.0.1.1", "prod": "10.0.2.1"}
+	profile.AddEntry(api)
+	profile.AddEntry(models.NewHostEntry("10.0.0.2", "web.test", ""))
+	assert.Equal(suite.T(), []string{"prod", "staging"}, profile.Environments())
+
+	profile.Environment = "staging"
+	_, err := suite.manager.ApplyProfile(profile)
+	require.NoError(suite.T(), err)
+
+	lines, err := suite.manager.GetManagedSection()
+	require.NoError(suite.T(), err)
+	assert.Contains(suite.T(), lines, "10.0.1.1\tapi.test")
+	assert.Contains(suite.T(), lines, "10.0.0.2\tweb.test")
+
+	drift, err := suite.manager.DetectDrift(profile)
+	require.NoError(suite.T(), err)
+	assert.False(suite.T(), drift.HasDrift())
+
+	profile.Environment = "prod"
+	drift, err = suite.manager.DetectDrift(profile)
+	require.NoError(suite.T(), err)
+	assert.True(suite.T(), drift.HasDrift())
+	assert.Equal(suite.T(), "10.0.0.1", api.IP, "resolving variants must not modify the profile")
+}
+
 // TestBackupHostsFile 测试备份hosts文件
 func (suite *HostManagerTestSuite) TestBackupHostsFile() {
 	backup, err := suite.manager.BackupHostsFile()
@@ -265,17 +506,32 @@ func (suite *HostManagerTestSuite) TestGetHostsFilePath() {
 // TestValidateHostsFile 测试验证hosts文件
 func (suite *HostManagerTestSuite) TestValidateHostsFile() {
 	// 测试有效的hosts文件
-	err := suite.manager.ValidateHostsFile()
+	result, err := suite.manager.ValidateHostsFile()
 	assert.NoError(suite.T(), err)
+	assert.True(suite.T(), result.Valid())
+	assert.Equal(suite.T(), 3, result.Entries)
+	assert.Empty(suite.T(), result.Errors())
 
-	// 测试无效的hosts文件
-	invalidContent := `invalid.ip.address	test.local
+	// 测试无效的hosts文件，报告包含全部错误而不是第一个
+	invalidContent := `invalid.ip.address	test.test
 127.0.0.1	invalid..hostname`
 	err = os.WriteFile(suite.hostsPath, []byte(invalidContent), 0644)
 	require.NoError(suite.T(), err)
 
-	err = suite.manager.ValidateHostsFile()
-	assert.Error(suite.T(), err)
+	result, err = suite.manager.ValidateHostsFile()
+	require.NoError(suite.T(), err)
+	assert.False(suite.T(), result.Valid())
+	require.Len(suite.T(), result.Errors(), 2)
+	assert.Equal(suite.T(), 2, result.Errors()[1].Line)
+
+	// 同一主机名指向多个IPv4地址时给出警告
+	_, err = suite.manager.WriteHostsFile([]string{"127.0.0.1\tlocalhost", "::1\tlocalhost", "10.0.0.1\tapp.test", "10.0.0.2\tapp.test"})
+	require.NoError(suite.T(), err)
+	result, err = suite.manager.ValidateHostsFile()
+	require.NoError(suite.T(), err)
+	assert.True(suite.T(), result.Valid())
+	require.Len(suite.T(), result.Warnings(), 1)
+	assert.Equal(suite.T(), hostsfile.IssueDuplicateHost, result.Warnings()[0].Code)
 }
 
 // TestParseHostsFile 测试解析hosts文件
@@ -322,7 +578,7 @@ func (suite *HostManagerTestSuite) TestGetManagedSection() {
 		UpdatedAt: time.Now(),
 	}
 
-	err = suite.manager.ApplyProfile(profile)
+	_, err = suite.manager.ApplyProfile(profile)
 	require.NoError(suite.T(), err)
 
 	managedLines, err = suite.manager.GetManagedSection()
@@ -382,6 +638,59 @@ func (suite *HostManagerTestSuite) TestUpdateManagedSection() {
 	assert.True(suite.T(), foundDB)
 }
 
+// TestDetectDrift 测试检测管理section中的手动修改
+func (suite *HostManagerTestSuite) TestDetectDrift() {
+	profile := models.NewProfile("Drift Profile", "")
+	profile.AddEntry(models.NewHostEntry("192.168.1.10", "app.local", ""))
+	_, err := suite.manager.ApplyProfile(profile)
+	require.NoError(suite.T(), err)
+
+	drift, err := suite.manager.DetectDrift(profile)
+	require.NoError(suite.T(), err)
+	assert.False(suite.T(), drift.HasDrift())
+
+	// 模拟手动编辑管理section
+	data, err := os.ReadFile(suite.hostsPath)
+	require.NoError(suite.T(), err)
+	edited := strings.Replace(string(data), "192.168.1.10\tapp.local", "192.168.1.20\tapp.local\n192.168.1.30\tnew.local", 1)
+	require.NoError(suite.T(), os.WriteFile(suite.hostsPath, []byte(edited), 0644))
+
+	drift, err = suite.manager.DetectDrift(profile)
+	require.NoError(suite.T(), err)
+	require.Len(suite.T(), drift.Changed, 1)
+	assert.Equal(suite.T(), "192.168.1.20", drift.Changed[0].Actual.IP)
+	require.Len(suite.T(), drift.Added, 1)
+	assert.Equal(suite.T(), "new.local", drift.Added[0].Hostname)
+
+	_, err = suite.manager.DetectDrift(nil)
+	assert.Equal(suite.T(), models.ErrInvalidProfile, err)
+}
+
+// TestPatchManagedEntry 测试就地更新管理section中的单个条目
+func (suite *HostManagerTestSuite) TestPatchManagedEntry() {
+	entry := models.NewHostEntry("192.168.1.10", "app.local", "")
+	err := suite.manager.PatchManagedEntry(entry)
+	assert.ErrorIs(suite.T(), err, hostsfile.ErrNoManagedSection)
+
+	profile := models.NewProfile("Patch Profile", "")
+	profile.AddEntry(entry)
+	profile.AddEntry(models.NewHostEntry("192.168.1.11", "api.local", ""))
+	_, err = suite.manager.ApplyProfile(profile)
+	require.NoError(suite.T(), err)
+
+	entry.Enabled = false
+	require.NoError(suite.T(), suite.manager.PatchManagedEntry(entry))
+
+	managedLines, err := suite.manager.GetManagedSection()
+	require.NoError(suite.T(), err)
+	assert.NotContains(suite.T(), managedLines, "192.168.1.10\tapp.local")
+	assert.Contains(suite.T(), managedLines, "192.168.1.11\tapi.local")
+
+	drift, err := suite.manager.DetectDrift(profile)
+	require.NoError(suite.T(), err)
+	assert.False(suite.T(), drift.HasDrift())
+}
+
 // TestUpdateManagedSectionEmpty 测试更新空的管理section
 func (suite *HostManagerTestSuite) TestUpdateManagedSectionEmpty() {
 	// 先添加一些条目
@@ -424,6 +733,37 @@ func TestHostManagerSuite(t *testing.T) {
 	suite.Run(t, new(HostManagerTestSuite))
 }
 
+// TestCheckPreflight 测试应用前的权限检查
+func TestCheckPreflight(t *testing.T) {
+	dir := t.TempDir()
+	hostsPath := filepath.Join(dir, "hosts")
+	require.NoError(t, os.WriteFile(hostsPath, []byte("127.0.0.1\tlocalhost\n"), 0644))
+
+	result := CheckPreflight(hostsPath, false)
+	assert.True(t, result.CanApply())
+	assert.True(t, result.Writable)
+	assert.Empty(t, result.Remediations)
+
+	// 检查不应留下临时文件
+	files, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, files, 1)
+
+	result = CheckPreflight(filepath.Join(dir, "missing"), true)
+	assert.False(t, result.CanApply())
+	assert.Equal(t, PreflightHostsMissing, result.Issue)
+	assert.Equal(t, []Remediation{RemediationRunWithSudo}, result.Remediations)
+
+	if os.Geteuid() == 0 {
+		t.Skip("root用户不受文件权限限制")
+	}
+
+	require.NoError(t, os.Chmod(hostsPath, 0444))
+	result = CheckPreflight(hostsPath, false)
+	assert.Equal(t, PreflightHostsNotWritable, result.Issue)
+	assert.Equal(t, []Remediation{RemediationInstallHelper, RemediationRunWithSudo, RemediationFixPermissions}, result.Remediations)
+}
+
 // BenchmarkApplyProfile 性能测试：应用Profile
 func BenchmarkApplyProfile(b *testing.B) {
 	// 创建临时目录和文件
@@ -471,7 +811,7 @@ func BenchmarkApplyProfile(b *testing.B) {
 
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
-		err := manager.ApplyProfile(profile)
+		_, err := manager.ApplyProfile(profile)
 		if err != nil {
 			b.Fatal(err)
 		}
@@ -513,3 +853,592 @@ func BenchmarkParseHostsFile(b *testing.B) {
 		}
 	}
 }
+
+// TestCommandElevator 测试通过外部命令写入文件
+func TestCommandElevator(t *testing.T) {
+	target := filepath.Join(t.TempDir(), "hosts")
+	elevator := &commandElevator{
+		name:   "cp",
+		binary: "cp",
+		args: func(src, dst string) []string {
+			return []string{src, dst}
+		},
+	}
+	if !elevator.Available() {
+		t.Skip("cp命令不可用")
+	}
+
+	require.NoError(t, elevator.WriteFile(target, []byte("127.0.0.1\tlocalhost\n")))
+	data, err := os.ReadFile(target)
+	require.NoError(t, err)
+	assert.Equal(t, "127.0.0.1\tlocalhost\n", string(data))
+
+	elevator.args = func(src, dst string) []string {
+		return []string{src, filepath.Join(dst, "missing", "hosts")}
+	}
+	assert.Error(t, elevator.WriteFile(target, nil))
+
+	assert.Equal(t, `"say \"hi\" \\"`, appleScriptString(`say "hi" \`))
+}
+
+// TestCheckLimits 测试规模限制检查
+func TestCheckLimits(t *testing.T) {
+	lines := []string{
+		"# comment a b c d",
+		"127.0.0.1 localhost",
+		"10.0.0.1 a.test b.test c.test # trailing",
+	}
+
+	assert.Empty(t, CheckLimits(lines, models.LimitsConfig{}))
+	assert.Empty(t, CheckLimits(lines, models.LimitsConfig{MaxHostnamesPerLine: 3, MaxEntries: 4, MaxFileSize: 1024}))
+
+	violations := CheckLimits(lines, models.LimitsConfig{MaxHostnamesPerLine: 2, MaxEntries: 3, MaxFileSize: 10})
+	require.Len(t, violations, 3)
+	assert.Equal(t, "line 3 has 3 hostnames (limit 2)", violations[0])
+	assert.Equal(t, "4 entries (limit 3)", violations[1])
+	assert.Contains(t, violations[2], "(limit 10)")
+
+	stats := MeasureHosts(lines)
+	assert.Equal(t, HostsStats{Size: 79, Entries: 4, WidestLine: 3, WidestHostnames: 3}, stats)
+}
+
+// TestBackupHistory 测试备份历史区分自动和手动备份、验证备份并导出CSV和JSON
+func TestBackupHistory(t *testing.T) {
+	dir := t.TempDir()
+	hostsPath := filepath.Join(dir, "hosts")
+	backupDir := filepath.Join(dir, "backups")
+	require.NoError(t, os.WriteFile(hostsPath, []byte("127.0.0.1 localhost\n"), 0644))
+
+	manager := NewManager(hostsPath, backupDir)
+	manual, err := manager.BackupHostsFile()
+	require.NoError(t, err)
+	assert.Equal(t, models.BackupTypeManual, manual.Type)
+
+	manager.SetBackupOnApply(true)
+	profile := models.NewProfile("Dev", "")
+	profile.AddEntry(models.NewHostEntry("10.0.0.1", "api.test", ""))
+	result, err := manager.ApplyProfile(profile)
+	require.NoError(t, err)
+	assert.Contains(t, filepath.Base(result.BackupPath), AutoBackupFilePrefix)
+
+	require.NoError(t, os.WriteFile(filepath.Join(backupDir, BackupFilePrefix+"empty.txt"), nil, 0644))
+
+	history, err := LoadBackupHistory(backupDir)
+	require.NoError(t, err)
+	require.Len(t, history.Records, 3)
+	assert.Equal(t, 3, history.Summary.TotalBackups)
+	assert.Equal(t, 1, history.Summary.AutomaticBackups)
+	assert.Equal(t, 2, history.Summary.ManualBackups)
+	assert.Equal(t, 2, history.Summary.ValidBackups)
+	assert.Equal(t, 1, history.Summary.InvalidBackups)
+	assert.NotNil(t, history.Summary.OldestBackup)
+
+	for _, record := range history.Records {
+		assert.Equal(t, record.Path == result.BackupPath, record.Automatic, record.Name)
+		if strings.HasSuffix(record.Name, "empty.txt") {
+			assert.False(t, record.Valid)
+			assert.Equal(t, "empty backup file", record.Error)
+		}
+	}
+
+	var csvOut strings.Builder
+	require.NoError(t, history.Export(&csvOut, ExportCSV))
+	lines := strings.Split(strings.TrimSpace(csvOut.String()), "\n")
+	require.Len(t, lines, 4)
+	assert.Equal(t, "id,name,path,created_at,size,type,valid,error", lines[0])
+	assert.Contains(t, csvOut.String(), ",automatic,true,")
+	assert.Contains(t, csvOut.String(), ",manual,false,empty backup file")
+
+	var jsonOut strings.Builder
+	require.NoError(t, history.Export(&jsonOut, ExportJSON))
+	assert.Contains(t, jsonOut.String(), `"invalid_backups": 1`)
+
+	format, err := ParseExportFormat(" CSV ")
+	require.NoError(t, err)
+	assert.Equal(t, ExportCSV, format)
+	_, err = ParseExportFormat("xml")
+	assert.Error(t, err)
+}
+
+// TestBackupLabels 测试创建里程碑备份并设置标签，清理备份时保留里程碑备份
+func TestBackupLabels(t *testing.T) {
+	dir := t.TempDir()
+	hostsPath := filepath.Join(dir, "hosts")
+	backupDir := filepath.Join(dir, "backups")
+	require.NoError(t, os.WriteFile(hostsPath, []byte("127.0.0.1 localhost\n"), 0644))
+	manager := NewManager(hostsPath, backupDir)
+
+	milestone, err := manager.BackupHostsFileWithOptions(BackupOptions{Milestone: " before DNS migration ", Tags: []string{"dns", "DNS", " "}})
+	require.NoError(t, err)
+	assert.Equal(t, "before DNS migration", milestone.Metadata.Description)
+	assert.Equal(t, []string{"manual", "hosts", "dns", MilestoneTag}, milestone.Metadata.Tags)
+
+	now := time.Now()
+	for i, age := range []time.Duration{72 * time.Hour, 48 * time.Hour, time.Hour} {
+		path := filepath.Join(backupDir, fmt.Sprintf("%s%d.txt", BackupFilePrefix, i))
+		require.NoError(t, os.WriteFile(path, []byte("127.0.0.1 localhost\n"), 0644))
+		require.NoError(t, os.Chtimes(path, now.Add(-age), now.Add(-age)))
+	}
+	require.NoError(t, os.Chtimes(milestone.FilePath, now.Add(-96*time.Hour), now.Add(-96*time.Hour)))
+	require.NoError(t, SetBackupLabel(backupDir, BackupFilePrefix+"0.txt", BackupLabel{Tags: []string{"old"}}))
+
+	history, err := LoadBackupHistory(backupDir)
+	require.NoError(t, err)
+	require.Len(t, history.Records, 4)
+	assert.Equal(t, "before DNS migration", history.Records[3].Milestone)
+	assert.True(t, history.Records[3].HasTag("DNS"))
+	assert.True(t, history.Records[2].HasTag("old"))
+
+	removed, err := CleanupBackups(backupDir, 1, 0, now)
+	require.NoError(t, err)
+	require.Len(t, removed, 2)
+	assert.Equal(t, BackupFilePrefix+"1.txt", removed[0].Name)
+	assert.Equal(t, BackupFilePrefix+"0.txt", removed[1].Name)
+
+	backups, err := ListBackupFiles(backupDir)
+	require.NoError(t, err)
+	require.Len(t, backups, 2)
+	assert.True(t, backups[1].Protected())
+	labels, err := LoadBackupLabels(backupDir)
+	require.NoError(t, err)
+	assert.Len(t, labels, 1, "labels of removed backups are dropped")
+
+	removed, err = CleanupBackups(backupDir, 0, 30*time.Minute, now)
+	require.NoError(t, err)
+	assert.Len(t, removed, 1)
+	_, err = os.Stat(milestone.FilePath)
+	assert.NoError(t, err)
+
+	// 手动删除时里程碑备份也会被删除
+	removed, err = DeleteBackups(backupDir, []string{filepath.Base(milestone.FilePath), "missing.txt"})
+	require.NoError(t, err)
+	assert.Len(t, removed, 1)
+	labels, err = LoadBackupLabels(backupDir)
+	require.NoError(t, err)
+	assert.Empty(t, labels)
+}
+
+// TestDeltaBackups 测试超大hosts文件以增量方式备份，恢复时重建内容并验证备份链
+func TestDeltaBackups(t *testing.T) {
+	dir := t.TempDir()
+	hostsPath := filepath.Join(dir, "hosts")
+	backupDir := filepath.Join(dir, "backups")
+	manager := NewManager(hostsPath, backupDir)
+	manager.SetDeltaBackups(100, 2)
+
+	var lines []string
+	for i := 0; i < 500; i++ {
+		lines = append(lines, fmt.Sprintf("0.0.0.0 ads%d.test", i))
+	}
+	writeHosts := func() string {
+		content := strings.Join(lines, "\n") + "\n"
+		require.NoError(t, os.WriteFile(hostsPath, []byte(content), 0644))
+		return content
+	}
+
+	// 同一秒内的备份文件名相同，改名并设置修改时间以区分先后
+	now := time.Now()
+	backup := func(i int) (string, string) {
+		content := writeHosts()
+		created, err := manager.BackupHostsFile()
+		require.NoError(t, err)
+		path := filepath.Join(backupDir, fmt.Sprintf("%s%d%s", BackupFilePrefix, i, filepath.Ext(created.FilePath)))
+		require.NoError(t, os.Rename(created.FilePath, path))
+		modTime := now.Add(time.Duration(i-10) * time.Minute)
+		require.NoError(t, os.Chtimes(path, modTime, modTime))
+		return path, content
+	}
+
+	full, _ := backup(1)
+	assert.Equal(t, ".txt", filepath.Ext(full))
+
+	lines[10] = "0.0.0.0 changed.test"
+	lines = append(lines[:20], lines[30:]...)
+	lines = append(lines, "0.0.0.0 new.test")
+	delta, deltaContent := backup(2)
+	assert.Equal(t, DeltaBackupSuffix, filepath.Ext(delta))
+	fullInfo, err := os.Stat(full)
+	require.NoError(t, err)
+	deltaInfo, err := os.Stat(delta)
+	require.NoError(t, err)
+	assert.Less(t, deltaInfo.Size()*10, fullInfo.Size())
+
+	content, err := ReadBackupFile(delta)
+	require.NoError(t, err)
+	assert.Equal(t, deltaContent, string(content))
+
+	lines = lines[:len(lines)-1]
+	second, _ := backup(3)
+	assert.Equal(t, DeltaBackupSuffix, filepath.Ext(second))
+	third, _ := backup(4)
+	assert.Equal(t, ".txt", filepath.Ext(third), "a full backup is written after FullBackupEvery deltas")
+
+	require.NoError(t, manager.RestoreFromBackup(&models.Backup{FilePath: delta}))
+	restored, err := os.ReadFile(hostsPath)
+	require.NoError(t, err)
+	assert.Equal(t, deltaContent, string(restored))
+
+	// 保留的增量备份依赖的完整备份不会被清理
+	removed, err := CleanupBackups(backupDir, 2, 0, now)
+	require.NoError(t, err)
+	require.Len(t, removed, 1)
+	assert.Equal(t, filepath.Base(delta), removed[0].Name)
+	require.NoError(t, VerifyBackupFile(second))
+	removed, err = DeleteBackups(backupDir, []string{filepath.Base(full)})
+	require.NoError(t, err)
+	assert.Empty(t, removed, "a full backup referenced by a kept delta is not deleted")
+
+	// 完整备份被修改后增量备份无法通过验证
+	require.NoError(t, os.WriteFile(full, []byte("127.0.0.1 localhost\n"), 0644))
+	_, err = ReadBackupFile(second)
+	assert.ErrorContains(t, err, "checksum mismatch")
+	assert.Error(t, VerifyBackupFile(second))
+	require.NoError(t, os.Remove(full))
+	_, err = ReadBackupFile(second)
+	assert.ErrorContains(t, err, "missing")
+}
+
+// TestApplyProfileGolden 测试应用Profile的输出与testdata中的golden文件一致
+func TestBackupSpace(t *testing.T) {
+	dir := t.TempDir()
+	hostsPath := filepath.Join(dir, "hosts")
+	backupDir := filepath.Join(dir, "backups")
+	require.NoError(t, os.MkdirAll(backupDir, 0755))
+	require.NoError(t, os.WriteFile(hostsPath, []byte(strings.Repeat("#", 50)), 0644))
+
+	now := time.Now()
+	for i := 1; i <= 3; i++ {
+		path := filepath.Join(backupDir, fmt.Sprintf("%s%d.txt", BackupFilePrefix, i))
+		require.NoError(t, os.WriteFile(path, []byte(strings.Repeat("x", 100)), 0644))
+		modTime := now.Add(time.Duration(i-10) * time.Minute)
+		require.NoError(t, os.Chtimes(path, modTime, modTime))
+	}
+
+	// 模拟容量固定的磁盘，剩余空间随备份目录中的文件增减
+	capacity := uint64(BackupSpaceMargin + 300)
+	freeSpace := func(string) (uint64, error) {
+		backups, err := ListBackupFiles(backupDir)
+		if err != nil {
+			return 0, err
+		}
+		used := uint64(0)
+		for _, backup := range backups {
+			used += uint64(backup.Size)
+		}
+		return capacity - used, nil
+	}
+
+	manager := NewManager(hostsPath, backupDir).(*ManagerImpl)
+	manager.freeSpace = freeSpace
+	_, err := manager.BackupHostsFile()
+	var spaceErr *SpaceError
+	require.ErrorAs(t, err, &spaceErr)
+	assert.ErrorIs(t, err, ErrInsufficientSpace)
+	assert.Equal(t, uint64(BackupSpaceMargin+50), spaceErr.Required)
+	assert.Equal(t, uint64(BackupSpaceMargin), spaceErr.Available)
+	backups, err := ListBackupFiles(backupDir)
+	require.NoError(t, err)
+	assert.Len(t, backups, 3, "no truncated backup is left behind")
+
+	// 设置了保留条件时先清理旧备份
+	manager.SetBackupRetention(1, 0)
+	created, err := manager.BackupHostsFile()
+	require.NoError(t, err)
+	assert.Equal(t, int64(50), created.Size)
+	backups, err = ListBackupFiles(backupDir)
+	require.NoError(t, err)
+	assert.Len(t, backups, 2)
+
+	// 无法查询剩余空间时不阻止备份
+	assert.NoError(t, CheckBackupSpace(backupDir, 1<<40, 0, 0, func(string) (uint64, error) {
+		return 0, fmt.Errorf("unsupported")
+	}))
+}
+
+func TestApplyProfileGolden(t *testing.T) {
+	hostsfiletest.Run(t, func(t *testing.T, c hostsfiletest.Case, hostsPath string) {
+		_, err := NewManager(hostsPath, "").ApplyProfile(c.Profile)
+		require.NoError(t, err)
+	})
+}
+
+// TestApplyWithGlobalEntries 测试应用和检测漂移时合并全局条目
+func TestApplyWithGlobalEntries(t *testing.T) {
+	hostsPath := filepath.Join(t.TempDir(), "hosts")
+	require.NoError(t, os.WriteFile(hostsPath, []byte("127.0.0.1 localhost\n"), 0644))
+	manager := NewManager(hostsPath, "")
+
+	global := models.NewGlobalProfile()
+	global.AddEntry(models.NewHostEntry("10.0.0.9", "license.corp", ""))
+	disabled := models.NewHostEntry("10.0.0.8", "api.test", "")
+	disabled.Enabled = false
+	global.AddEntry(disabled)
+	manager.SetGlobalSource(func() (*models.Profile, error) { return global, nil })
+
+	profile := models.NewProfile("Dev", "")
+	profile.AddEntry(models.NewHostEntry("10.0.0.1", "api.test", ""))
+	profile.AddEntry(models.NewHostEntry("10.9.9.9", "LICENSE.corp", ""))
+
+	_, err := manager.ApplyProfile(profile)
+	require.NoError(t, err)
+	managed, err := manager.GetManagedSection()
+	require.NoError(t, err)
+	assert.Equal(t, []hostsfile.Entry{
+		{IP: "10.0.0.9", Hostname: "license.corp", Enabled: true},
+		{IP: "10.0.0.1", Hostname: "api.test", Enabled: true},
+	}, hostsfile.Parse(managed))
+
+	drift, err := manager.DetectDrift(profile)
+	require.NoError(t, err)
+	assert.False(t, drift.HasDrift())
+	assert.Len(t, profile.Entries, 2, "the profile itself is not modified")
+
+	manager.SetGlobalSource(func() (*models.Profile, error) { return nil, fmt.Errorf("disk error") })
+	_, err = manager.ApplyProfile(profile)
+	assert.Error(t, err)
+}
+
+// TestApplyProfiles 测试叠加应用多个Profile时按优先级解决主机名冲突
+func TestApplyProfiles(t *testing.T) {
+	hostsPath := filepath.Join(t.TempDir(), "hosts")
+	require.NoError(t, os.WriteFile(hostsPath, []byte("127.0.0.1 localhost\n"), 0644))
+	manager := NewManager(hostsPath, "")
+
+	base := models.NewProfile("Base", "")
+	base.AddEntry(models.NewHostEntry("10.0.0.1", "api.test", ""))
+	base.AddEntry(models.NewHostEntry("10.0.0.2", "db.test", ""))
+
+	project := models.NewProfile("Project-A", "")
+	project.Environment = "staging"
+	api := models.NewHostEntry("10.1.0.9", "API.test", "")
+	api.Variants = models.EntryVariants{"staging": "10.1.0.1"}
+	project.AddEntry(api)
+	project.AddEntry(models.NewHostEntry("10.1.0.3", "new.test", ""))
+	// 只有禁用条目的层不覆盖较低层中启用的条目
+	db := models.NewHostEntry("10.9.9.9", "db.test", "")
+	db.Enabled = false
+	project.AddEntry(db)
+
+	adblock := models.NewProfile("Adblock", "")
+	adblock.AddEntry(models.NewHostEntry("0.0.0.0", "ads.test", ""))
+	adblock.AddEntry(models.NewHostEntry("10.1.0.1", "api.test", ""))
+
+	result, err := manager.ApplyProfiles([]*models.Profile{base, project, adblock}, ApplyOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "Base + Project-A + Adblock", result.ProfileName)
+	assert.Equal(t, []string{"Base", "Project-A", "Adblock"}, result.Layers)
+	// Adblock与Project-A指向相同的IP，只与Base冲突
+	assert.Equal(t, []models.LayerConflict{{Hostname: "api.test", Winner: "Adblock", Overridden: []string{"Base"}}}, result.Conflicts)
+	assert.Contains(t, result.Warnings, "api.test: Adblock overrides Base")
+
+	managed, err := manager.GetManagedSection()
+	require.NoError(t, err)
+	assert.Contains(t, managed, "# Profile: Base + Project-A + Adblock")
+	assert.Equal(t, []hostsfile.Entry{
+		{IP: "10.0.0.2", Hostname: "db.test", Enabled: true},
+		{IP: "10.1.0.3", Hostname: "new.test", Enabled: true},
+		{IP: "0.0.0.0", Hostname: "ads.test", Enabled: true},
+		{IP: "10.1.0.1", Hostname: "api.test", Enabled: true},
+	}, hostsfile.Parse(managed))
+	assert.Equal(t, "10.1.0.9", api.IP, "layering must not modify the profiles")
+
+	layered, _ := models.LayerProfiles([]*models.Profile{base, project, adblock})
+	drift, err := manager.DetectDrift(layered)
+	require.NoError(t, err)
+	assert.False(t, drift.HasDrift())
+
+	// 只有一层时与ApplyProfile相同
+	result, err = manager.ApplyProfiles([]*models.Profile{base}, ApplyOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, base.ID, result.ProfileID)
+	assert.Empty(t, result.Conflicts)
+
+	_, err = manager.ApplyProfiles(nil, ApplyOptions{})
+	assert.ErrorIs(t, err, models.ErrInvalidProfile)
+}
+
+// TestBaseline 测试首次运行时保存系统初始hosts文件，之后不再覆盖，并可恢复
+func TestBaseline(t *testing.T) {
+	dir := t.TempDir()
+	hostsPath := filepath.Join(dir, "hosts")
+	original := "127.0.0.1 localhost\n# comment\n#10.0.0.2 off.test\n"
+	require.NoError(t, os.WriteFile(hostsPath, []byte(original+"\n"+hostsfile.StartMarker+"\n10.0.0.1 api.test\n"+hostsfile.EndMarker+"\n"), 0644))
+	manager := NewManager(hostsPath, "")
+
+	path := DefaultBaselinePath(dir)
+	baseline, created, err := CaptureBaseline(manager, path)
+	require.NoError(t, err)
+	assert.True(t, created)
+	assert.Equal(t, []string{"127.0.0.1 localhost", "# comment", "#10.0.0.2 off.test"}, baseline.Lines)
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0444), info.Mode().Perm())
+
+	profile := baseline.Profile()
+	assert.Equal(t, BaselineProfileID, profile.ID)
+	require.Len(t, profile.Entries, 2)
+	assert.False(t, profile.Entries[1].Enabled)
+
+	applied := models.NewProfile("Dev", "")
+	applied.AddEntry(models.NewHostEntry("10.0.0.1", "api.test", ""))
+	_, err = manager.ApplyProfile(applied)
+	require.NoError(t, err)
+
+	again, created, err := CaptureBaseline(manager, path)
+	require.NoError(t, err)
+	assert.False(t, created)
+	assert.Equal(t, baseline.Lines, again.Lines)
+
+	_, err = RestoreBaseline(manager, again)
+	require.NoError(t, err)
+	data, err := os.ReadFile(hostsPath)
+	require.NoError(t, err)
+	assert.Equal(t, original, string(data))
+
+	_, err = RestoreBaseline(manager, nil)
+	assert.Error(t, err)
+	// 退出时清除管理section，保留其他内容
+	_, err = manager.ApplyProfile(applied)
+	require.NoError(t, err)
+	result, err := ClearManagedSection(manager)
+	require.NoError(t, err)
+	assert.NotNil(t, result)
+	data, err = os.ReadFile(hostsPath)
+	require.NoError(t, err)
+	assert.Equal(t, original, string(data))
+	result, err = ClearManagedSection(manager)
+	require.NoError(t, err)
+	assert.Nil(t, result, "nothing is written without a managed section")
+}
+
+// TestSharedBaseline 测试所有工作区共用保存在默认工作区中的系统初始hosts文件
+func TestSharedBaseline(t *testing.T) {
+	dir := t.TempDir()
+	hostsPath := filepath.Join(dir, "hosts")
+	require.NoError(t, os.WriteFile(hostsPath, []byte("127.0.0.1 localhost\n"), 0644))
+	manager := NewManager(hostsPath, "")
+
+	workspaces := config.NewWorkspaceManager(filepath.Join(dir, "root"))
+	_, err := workspaces.CreateWorkspace("work")
+	require.NoError(t, err)
+	_, err = workspaces.SwitchWorkspace("work")
+	require.NoError(t, err)
+	defaultWorkspace, err := workspaces.GetWorkspace(config.DefaultWorkspace)
+	require.NoError(t, err)
+
+	path, err := SharedBaselinePath(workspaces)
+	require.NoError(t, err)
+	assert.Equal(t, DefaultBaselinePath(defaultWorkspace.DataDir), path)
+
+	baseline, created, err := CaptureSharedBaseline(manager, workspaces)
+	require.NoError(t, err)
+	assert.True(t, created)
+	assert.Equal(t, path, baseline.Path)
+	assert.Equal(t, []string{"127.0.0.1 localhost"}, baseline.Lines)
+
+	_, created, err = CaptureSharedBaseline(manager, workspaces)
+	require.NoError(t, err)
+	assert.False(t, created)
+}
+
+// TestRankBackups 测试恢复向导按有效性和时间排序备份，并恢复选中的备份
+func TestRankBackups(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Now()
+	write := func(name, content string, age time.Duration) {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+		require.NoError(t, os.Chtimes(path, now.Add(-age), now.Add(-age)))
+	}
+	write(BackupFilePrefix+"newest_invalid.txt", "not-an-ip broken\n", time.Minute)
+	write(BackupFilePrefix+"empty.txt", "\n", 2*time.Minute)
+	write(AutoBackupFilePrefix+"recent.txt", "127.0.0.1 localhost\n10.0.0.1 api.test\n", time.Hour)
+	write(BackupFilePrefix+"old.txt", "127.0.0.1 localhost\n", 24*time.Hour)
+
+	candidates, err := RankBackups(dir)
+	require.NoError(t, err)
+	require.Len(t, candidates, 4)
+	assert.Equal(t, AutoBackupFilePrefix+"recent.txt", candidates[0].Backup.Name)
+	assert.True(t, candidates[0].Valid())
+	assert.Equal(t, 2, candidates[0].Entries)
+	assert.Equal(t, BackupFilePrefix+"old.txt", candidates[1].Backup.Name)
+	assert.Equal(t, BackupFilePrefix+"newest_invalid.txt", candidates[2].Backup.Name)
+	assert.NotEmpty(t, candidates[2].Errors)
+	assert.Equal(t, BackupFilePrefix+"empty.txt", candidates[3].Backup.Name)
+	assert.Error(t, candidates[3].Err)
+
+	hostsPath := filepath.Join(t.TempDir(), "hosts")
+	require.NoError(t, os.WriteFile(hostsPath, []byte("garbage\n"), 0644))
+	manager := NewManager(hostsPath, "")
+	_, err = RestoreFromCandidate(manager, candidates[0])
+	require.NoError(t, err)
+	result, err := manager.ValidateHostsFile()
+	require.NoError(t, err)
+	assert.True(t, result.Valid())
+
+	_, err = RestoreFromCandidate(manager, candidates[3])
+	assert.Error(t, err)
+}
+
+// TestProfileFromBackup 测试由备份的管理section或整个文件创建Profile
+func TestProfileFromBackup(t *testing.T) {
+	dir := t.TempDir()
+	content := strings.Join([]string{
+		"127.0.0.1 localhost",
+		"",
+		hostsfile.StartMarker,
+		"10.0.0.1 api.test",
+		"# 10.0.0.2 web.test",
+		hostsfile.EndMarker,
+	}, "\n") + "\n"
+	path := filepath.Join(dir, BackupFilePrefix+"1.txt")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	backup := BackupFile{Name: filepath.Base(path), Path: path, ModTime: time.Date(2024, 5, 1, 9, 30, 0, 0, time.Local)}
+
+	profile, err := ProfileFromBackup(backup, true)
+	require.NoError(t, err)
+	assert.Equal(t, "Backup 2024-05-01 09:30", profile.Name)
+	require.Len(t, profile.Entries, 2)
+	assert.Equal(t, "api.test", profile.Entries[0].Hostname)
+	assert.False(t, profile.Entries[1].Enabled)
+
+	backup.Milestone = "before DNS migration"
+	profile, err = ProfileFromBackup(backup, false)
+	require.NoError(t, err)
+	assert.Equal(t, "before DNS migration", profile.Name)
+	assert.Len(t, profile.Entries, 3)
+
+	require.NoError(t, os.WriteFile(path, []byte("# empty\n"), 0644))
+	_, err = ProfileFromBackup(backup, true)
+	assert.Error(t, err)
+}
+
+// FuzzParseHostsFile 测试任意hosts文件内容都能读取和解析，应用Profile时保留非管理部分
+func FuzzParseHostsFile(f *testing.F) {
+	f.Add("127.0.0.1\tlocalhost\n::1\t\tlocalhost\n# Test comment\n192.168.1.100\ttest.local\t# Test entry\n")
+	f.Add("127.0.0.1 localhost\r\n\r\n" + hostsfile.StartMarker + "\r\n10.0.0.1 old.test\r\n")
+	f.Add(hostsfile.EndMarker + "\n10.0.0.1 a.test\n" + hostsfile.StartMarker + "\n" + hostsfile.StartMarker + "\n")
+	f.Add("\n\n\x00\xff 10.0.0.1\t# #")
+
+	profile := models.NewProfile("Fuzz", "")
+	profile.AddEntry(models.NewHostEntry("10.0.0.1", "api.test", ""))
+
+	f.Fuzz(func(t *testing.T, content string) {
+		hostsPath := filepath.Join(t.TempDir(), "hosts")
+		require.NoError(t, os.WriteFile(hostsPath, []byte(content), 0644))
+		manager := NewManager(hostsPath, "")
+
+		entries, err := manager.ParseHostsFile()
+		require.NoError(t, err)
+		assert.Len(t, entries, len(hostsfile.Parse(hostsfile.SplitLines(content))))
+
+		_, err = manager.ApplyProfileWithOptions(profile, ApplyOptions{IgnoreLimits: true})
+		require.NoError(t, err)
+
+		lines, err := manager.ReadHostsFile()
+		require.NoError(t, err)
+		assert.Equal(t, strings.Join(hostsfile.RemoveManagedSection(hostsfile.SplitLines(content)), "\n"), strings.Join(hostsfile.RemoveManagedSection(lines), "\n"))
+		assert.Equal(t, []hostsfile.Entry{{IP: "10.0.0.1", Hostname: "api.test", Enabled: true}}, hostsfile.Parse(hostsfile.ExtractManagedSection(lines)))
+	})
+}
@@ -0,0 +1,103 @@
+package host
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// PreflightIssue 应用前检查发现的问题
+type PreflightIssue string
+
+const (
+	// PreflightOK 无问题
+	PreflightOK PreflightIssue = ""
+	// PreflightHostsMissing hosts文件不存在
+	PreflightHostsMissing PreflightIssue = "hosts_missing"
+	// PreflightHostsNotWritable hosts文件不可写
+	PreflightHostsNotWritable PreflightIssue = "hosts_not_writable"
+	// PreflightDirNotWritable hosts文件所在目录不可写（原子替换需要）
+	PreflightDirNotWritable PreflightIssue = "dir_not_writable"
+)
+
+// Remediation 修复建议类型
+type Remediation string
+
+const (
+	// RemediationInstallHelper 安装特权Helper
+	RemediationInstallHelper Remediation = "install_helper"
+	// RemediationRunWithSudo 以管理员权限运行
+	RemediationRunWithSudo Remediation = "run_with_sudo"
+	// RemediationFixPermissions 修复文件权限
+	RemediationFixPermissions Remediation = "fix_permissions"
+)
+
+// PreflightResult 应用前检查结果
+type PreflightResult struct {
+	HostsPath       string         `json:"hosts_path"`
+	Writable        bool           `json:"writable"`
+	HelperAvailable bool           `json:"helper_available"`
+	Issue           PreflightIssue `json:"issue"`
+	Detail          string         `json:"detail"`
+	Remediations    []Remediation  `json:"remediations"`
+}
+
+// CanApply 是否可以直接写入hosts文件
+func (r *PreflightResult) CanApply() bool {
+	return r.Issue == PreflightOK
+}
+
+// CheckPreflight 检查hosts文件及其目录是否可写，并根据Helper是否可用给出修复建议
+func CheckPreflight(hostsPath string, helperAvailable bool) *PreflightResult {
+	result := &PreflightResult{
+		HostsPath:       hostsPath,
+		HelperAvailable: helperAvailable,
+	}
+
+	if err := checkFileWritable(hostsPath); err != nil {
+		result.Detail = err.Error()
+		if errors.Is(err, os.ErrNotExist) {
+			result.Issue = PreflightHostsMissing
+		} else {
+			result.Issue = PreflightHostsNotWritable
+		}
+	} else if err := checkDirWritable(filepath.Dir(hostsPath)); err != nil {
+		result.Issue = PreflightDirNotWritable
+		result.Detail = err.Error()
+	}
+
+	if result.Issue == PreflightOK {
+		result.Writable = true
+		return result
+	}
+
+	if !helperAvailable {
+		result.Remediations = append(result.Remediations, RemediationInstallHelper)
+	}
+	result.Remediations = append(result.Remediations, RemediationRunWithSudo)
+	if result.Issue != PreflightHostsMissing {
+		result.Remediations = append(result.Remediations, RemediationFixPermissions)
+	}
+	return result
+}
+
+// checkFileWritable 以写方式打开文件检查权限（不修改内容）
+func checkFileWritable(path string) error {
+	file, err := os.OpenFile(path, os.O_WRONLY, 0)
+	if err != nil {
+		return err
+	}
+	return file.Close()
+}
+
+// checkDirWritable 在目录中创建并删除临时文件检查权限
+func checkDirWritable(dir string) error {
+	file, err := os.CreateTemp(dir, ".mhost-preflight-*")
+	if err != nil {
+		return fmt.Errorf("directory %s is not writable: %w", dir, err)
+	}
+	name := file.Name()
+	file.Close()
+	return os.Remove(name)
+}
@@ -0,0 +1,56 @@
+package host
+
+import (
+	"github.com/flyhigher139/mhost/internal/rules"
+	"github.com/flyhigher139/mhost/pkg/hostsfile"
+	"github.com/flyhigher139/mhost/pkg/models"
+)
+
+// Preview 应用Profile前的预览：将写入hosts文件的完整内容以及与当前文件的逐行差异，生成预览不修改hosts文件
+type Preview struct {
+	ProfileID   string                 `json:"profile_id"`
+	ProfileName string                 `json:"profile_name"`
+	HostsPath   string                 `json:"hosts_path"`
+	Content     []string               `json:"content"` // 将写入hosts文件的全部行
+	Diff        []hostsfile.LineChange `json:"diff"`    // 由当前hosts文件得到Content的逐行差异
+	// Entries 管理section中条目的改动，与应用结果中的Added、Removed和Changed相同
+	Entries *ApplyResult `json:"entries"`
+	// Before和After 应用前后hosts文件的规模
+	Before HostsStats `json:"before"`
+	After  HostsStats `json:"after"`
+	// Limits 生成预览时的规模限制，Violations为应用后超过的限制，应用时需要IgnoreLimits
+	Limits     models.LimitsConfig `json:"limits"`
+	Violations []string            `json:"violations,omitempty"`
+	// RuleViolations 写入管理section的条目违反的自定义校验规则，包含error时无法应用
+	RuleViolations []rules.Violation `json:"rule_violations,omitempty"`
+}
+
+// NewPreview 比较应用前后的hosts文件内容生成预览，entries为写入管理section的条目。Manager的实现使用它实现PreviewApply
+func NewPreview(profile *models.Profile, hostsPath string, before, after []string, entries []hostsfile.Entry, limits models.LimitsConfig) *Preview {
+	preview := &Preview{
+		ProfileID:   profile.ID,
+		ProfileName: profile.Name,
+		HostsPath:   hostsPath,
+		Content:     after,
+		Diff:        hostsfile.DiffLines(before, after),
+		Entries:     NewApplyResult(hostsfile.Parse(hostsfile.ExtractManagedSection(before)), entries),
+		Before:      MeasureHosts(before),
+		After:       MeasureHosts(after),
+		Limits:      limits,
+	}
+	preview.Violations = preview.After.Violations(limits)
+	preview.Entries.ProfileID = profile.ID
+	preview.Entries.ProfileName = profile.Name
+	preview.Entries.HostsPath = hostsPath
+	return preview
+}
+
+// HasChanges 应用后管理section中的条目是否变化；只有头部中的应用时间等变化时返回false
+func (p *Preview) HasChanges() bool {
+	return p.Entries.HasChanges()
+}
+
+// LineStats 新增和删除的行数
+func (p *Preview) LineStats() (added, removed int) {
+	return hostsfile.LineDiffStats(p.Diff)
+}
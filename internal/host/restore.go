@@ -0,0 +1,112 @@
+package host
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/flyhigher139/mhost/pkg/hostsfile"
+	"github.com/flyhigher139/mhost/pkg/models"
+)
+
+// RestoreCandidate 恢复向导中的候选备份及其验证结果
+type RestoreCandidate struct {
+	Backup   BackupFile
+	Lines    []string
+	Entries  int
+	Errors   []hostsfile.Issue
+	Warnings []hostsfile.Issue
+	// Err 备份不可读或为空时的原因
+	Err error
+}
+
+// Valid 备份是否可读、非空且没有语法错误
+func (c *RestoreCandidate) Valid() bool {
+	return c.Err == nil && len(c.Errors) == 0
+}
+
+// LoadRestoreCandidate 读取并验证单个备份文件
+func LoadRestoreCandidate(backup BackupFile) *RestoreCandidate {
+	candidate := &RestoreCandidate{Backup: backup}
+	data, err := ReadBackupFile(backup.Path)
+	if err != nil {
+		candidate.Err = fmt.Errorf("unreadable: %w", err)
+		return candidate
+	}
+	if len(strings.TrimSpace(string(data))) == 0 {
+		candidate.Err = fmt.Errorf("empty backup file")
+		return candidate
+	}
+
+	candidate.Lines = hostsfile.SplitLines(string(data))
+	candidate.Entries = len(hostsfile.Parse(candidate.Lines))
+	report := hostsfile.Check(candidate.Lines)
+	candidate.Errors = report.Errors()
+	candidate.Warnings = report.Warnings()
+	return candidate
+}
+
+// RankBackups 读取备份目录中的全部备份并排序：有效的备份在前，其次是语法错误较少的，
+// 同等情况下较新的在前。第一个有效的候选即为推荐恢复的备份
+func RankBackups(dir string) ([]*RestoreCandidate, error) {
+	backups, err := ListBackupFiles(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	candidates := make([]*RestoreCandidate, 0, len(backups))
+	for _, backup := range backups {
+		candidates = append(candidates, LoadRestoreCandidate(backup))
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		a, b := candidates[i], candidates[j]
+		if a.Valid() != b.Valid() {
+			return a.Valid()
+		}
+		if (a.Err == nil) != (b.Err == nil) {
+			return a.Err == nil
+		}
+		if len(a.Errors) != len(b.Errors) {
+			return len(a.Errors) < len(b.Errors)
+		}
+		return a.Backup.ModTime.After(b.Backup.ModTime)
+	})
+	return candidates, nil
+}
+
+// RestoreFromCandidate 将hosts文件恢复为候选备份的内容，没有写入权限时通过提权方式写入
+func RestoreFromCandidate(manager Manager, candidate *RestoreCandidate) (*WriteResult, error) {
+	if candidate == nil {
+		return nil, models.ErrInvalidBackup
+	}
+	if candidate.Err != nil {
+		return nil, fmt.Errorf("backup cannot be restored: %w", candidate.Err)
+	}
+	return manager.WriteHostsFile(candidate.Lines)
+}
+
+// ProfileFromBackup 将备份中的条目解析为新的Profile，便于编辑后选择性地重新应用。
+// managedOnly为true且备份包含管理section时只解析该section，否则解析整个文件；被注释的条目以禁用状态保留
+func ProfileFromBackup(backup BackupFile, managedOnly bool) (*models.Profile, error) {
+	data, err := ReadBackupFile(backup.Path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read backup: %w", err)
+	}
+	lines := hostsfile.SplitLines(string(data))
+	if managedOnly && hostsfile.HasManagedSection(lines) {
+		lines = hostsfile.ExtractManagedSection(lines)
+	}
+
+	name := backup.Milestone
+	if name == "" {
+		name = "Backup " + backup.ModTime.Format("2006-01-02 15:04")
+	}
+	profile := models.NewProfile(name, fmt.Sprintf("created from backup %s", backup.Name))
+	for _, entry := range hostsfile.ParseWithDisabled(lines) {
+		profile.Entries = append(profile.Entries, entry.ToModel())
+	}
+	if len(profile.Entries) == 0 {
+		return nil, fmt.Errorf("backup %s contains no host entries", backup.Name)
+	}
+	return profile, nil
+}
@@ -0,0 +1,105 @@
+package host
+
+import (
+	"time"
+
+	"github.com/flyhigher139/mhost/pkg/hostsfile"
+	"github.com/flyhigher139/mhost/pkg/models"
+)
+
+// ApplyResult 应用Profile的结果，记录本次写入对管理section的改动
+type ApplyResult struct {
+	ProfileID   string                  `json:"profile_id"`
+	ProfileName string                  `json:"profile_name"`
+	HostsPath   string                  `json:"hosts_path"`
+	Added       []hostsfile.Entry       `json:"added"`                 // 本次新增的条目
+	Removed     []hostsfile.Entry       `json:"removed"`               // 本次移除的条目
+	Changed     []hostsfile.EntryChange `json:"changed"`               // 本次修改的条目，Expected为新内容，Actual为旧内容
+	Unchanged   int                     `json:"unchanged"`             // 未变化的条目数
+	BackupPath  string                  `json:"backup_path,omitempty"` // 应用前的hosts备份
+	AppliedAt   time.Time               `json:"applied_at"`
+
+	LinesWritten int           `json:"lines_written"`      // 写入hosts文件的总行数
+	Warnings     []string      `json:"warnings,omitempty"` // 写入内容的校验警告
+	Duration     time.Duration `json:"duration"`           // 应用耗时
+	Elevated     string        `json:"elevated,omitempty"` // 写入时使用的提权方式，直接写入时为空
+
+	// 叠加应用多个Profile时各层的名称（按优先级从低到高）以及主机名冲突
+	Layers    []string               `json:"layers,omitempty"`
+	Conflicts []models.LayerConflict `json:"conflicts,omitempty"`
+}
+
+// WriteResult 写入hosts文件的结果
+type WriteResult struct {
+	HostsPath    string        `json:"hosts_path"`
+	LinesWritten int           `json:"lines_written"`
+	BytesWritten int64         `json:"bytes_written"`
+	Elevated     string        `json:"elevated,omitempty"` // 使用的提权方式，直接写入时为空
+	Duration     time.Duration `json:"duration"`
+}
+
+// ValidationResult 验证hosts文件的结果，包含全部错误与警告
+type ValidationResult struct {
+	HostsPath string            `json:"hosts_path"`
+	Lines     int               `json:"lines"`   // 总行数
+	Entries   int               `json:"entries"` // 有效条目数（每个主机名计一条）
+	Issues    []hostsfile.Issue `json:"issues"`
+}
+
+// Valid 是否没有错误（警告不影响有效性）
+func (r *ValidationResult) Valid() bool {
+	return !r.report().HasErrors()
+}
+
+// Errors 获取所有错误
+func (r *ValidationResult) Errors() []hostsfile.Issue {
+	return r.report().Errors()
+}
+
+// Warnings 获取所有警告
+func (r *ValidationResult) Warnings() []hostsfile.Issue {
+	return r.report().Warnings()
+}
+
+// report 包装为hostsfile.Report以复用筛选方法
+func (r *ValidationResult) report() *hostsfile.Report {
+	return &hostsfile.Report{Issues: r.Issues}
+}
+
+// issueMessages 将问题列表格式化为文本
+func issueMessages(issues []hostsfile.Issue) []string {
+	messages := make([]string, 0, len(issues))
+	for _, issue := range issues {
+		messages = append(messages, issue.String())
+	}
+	return messages
+}
+
+// HasChanges 本次应用是否改变了管理section中的条目
+func (r *ApplyResult) HasChanges() bool {
+	return len(r.Added) > 0 || len(r.Removed) > 0 || len(r.Changed) > 0
+}
+
+// NewApplyResult 比较应用前后的管理section条目生成应用结果
+func NewApplyResult(before, after []hostsfile.Entry) *ApplyResult {
+	// DetectDrift以after为期望：drift中新增的是被移除的旧条目，缺失的是本次新增的条目
+	drift := hostsfile.DetectDrift(after, before)
+
+	result := &ApplyResult{
+		Added:   drift.Removed,
+		Removed: drift.Added,
+		Changed: drift.Changed,
+	}
+
+	enabled := 0
+	for _, entry := range after {
+		if entry.Enabled {
+			enabled++
+		}
+	}
+	result.Unchanged = enabled - len(result.Added) - len(result.Changed)
+	if result.Unchanged < 0 {
+		result.Unchanged = 0
+	}
+	return result
+}
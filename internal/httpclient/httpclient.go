@@ -0,0 +1,256 @@
+// Package httpclient 提供对外HTTP请求共用的客户端工厂，统一处理代理设置。
+//
+// 用于访问互联网的请求（远程Profile、IP地理信息等）都应通过Factory创建客户端；
+// 针对特定IP的诊断请求（例如HTTP检查）需要直连目标地址，不使用此工厂。
+package httpclient
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os/exec"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/flyhigher139/mhost/pkg/models"
+)
+
+var (
+	// ErrInvalidProxy 代理地址无效
+	ErrInvalidProxy = errors.New("invalid proxy URL")
+	// ErrOffline 离线模式下禁止对外网络请求
+	ErrOffline = errors.New("network access is disabled in offline mode")
+)
+
+// proxyFunc 为请求选择代理，返回nil表示直连
+type proxyFunc func(*http.Request) (*url.URL, error)
+
+// Factory HTTP客户端工厂，按网络配置创建带代理的客户端
+type Factory struct {
+	mu     sync.RWMutex
+	config models.NetworkConfig
+
+	// macOS系统代理设置，首次使用时读取
+	systemOnce     sync.Once
+	systemSettings *systemProxySettings
+	readSystem     func() ([]byte, error)
+}
+
+var defaultFactory = NewFactory(models.NetworkConfig{ProxyMode: models.ProxyModeSystem})
+
+// Default 获取进程共用的客户端工厂，应用加载或保存配置后通过SetConfig更新
+func Default() *Factory {
+	return defaultFactory
+}
+
+// NewFactory 创建HTTP客户端工厂
+func NewFactory(config models.NetworkConfig) *Factory {
+	return &Factory{
+		config:     config,
+		readSystem: readScutilProxy,
+	}
+}
+
+// SetConfig 更新网络配置，之后创建的客户端使用新配置
+func (f *Factory) SetConfig(config models.NetworkConfig) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.config = config
+}
+
+// Config 获取当前网络配置
+func (f *Factory) Config() models.NetworkConfig {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return f.config
+}
+
+// Offline 是否处于离线模式
+func (f *Factory) Offline() bool {
+	return f.Config().Offline
+}
+
+// Client 创建使用当前代理配置的HTTP客户端，离线模式下返回ErrOffline
+func (f *Factory) Client(timeout time.Duration) (*http.Client, error) {
+	transport, err := f.Transport()
+	if err != nil {
+		return nil, err
+	}
+	return &http.Client{Timeout: timeout, Transport: transport}, nil
+}
+
+// Transport 创建使用当前代理配置的Transport，离线模式下返回ErrOffline
+func (f *Factory) Transport() (*http.Transport, error) {
+	if f.Offline() {
+		return nil, ErrOffline
+	}
+
+	proxy, err := f.proxy()
+	if err != nil {
+		return nil, err
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = proxy
+	return transport, nil
+}
+
+// proxy 根据代理模式选择代理函数，并为代理附加认证信息
+func (f *Factory) proxy() (proxyFunc, error) {
+	config := f.Config()
+
+	switch config.ProxyMode {
+	case models.ProxyModeNone:
+		return nil, nil
+	case models.ProxyModeManual:
+		proxyURL, err := ParseProxyURL(config.ProxyURL)
+		if err != nil {
+			return nil, err
+		}
+		return withCredentials(http.ProxyURL(proxyURL), config), nil
+	default:
+		return withCredentials(f.systemProxy, config), nil
+	}
+}
+
+// systemProxy 优先使用环境变量中的代理，未设置时读取macOS系统代理设置
+func (f *Factory) systemProxy(req *http.Request) (*url.URL, error) {
+	proxyURL, err := http.ProxyFromEnvironment(req)
+	if err != nil || proxyURL != nil {
+		return proxyURL, err
+	}
+
+	f.systemOnce.Do(func() {
+		if output, err := f.readSystem(); err == nil {
+			f.systemSettings = parseScutilProxy(output)
+		}
+	})
+	if f.systemSettings == nil {
+		return nil, nil
+	}
+	return f.systemSettings.proxyFor(req.URL), nil
+}
+
+// ParseProxyURL 解析代理地址，未指定协议时默认为http
+func ParseProxyURL(raw string) (*url.URL, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return nil, ErrInvalidProxy
+	}
+	if !strings.Contains(raw, "://") {
+		raw = "http://" + raw
+	}
+
+	proxyURL, err := url.Parse(raw)
+	if err != nil || proxyURL.Host == "" {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidProxy, raw)
+	}
+	switch proxyURL.Scheme {
+	case "http", "https", "socks5":
+		return proxyURL, nil
+	default:
+		return nil, fmt.Errorf("%w: unsupported scheme %q", ErrInvalidProxy, proxyURL.Scheme)
+	}
+}
+
+// withCredentials 代理地址未包含认证信息时附加配置中的用户名和密码
+func withCredentials(proxy proxyFunc, config models.NetworkConfig) proxyFunc {
+	if config.ProxyUsername == "" {
+		return proxy
+	}
+	return func(req *http.Request) (*url.URL, error) {
+		proxyURL, err := proxy(req)
+		if err != nil || proxyURL == nil || proxyURL.User != nil {
+			return proxyURL, err
+		}
+		authenticated := *proxyURL
+		authenticated.User = url.UserPassword(config.ProxyUsername, config.ProxyPassword)
+		return &authenticated, nil
+	}
+}
+
+// systemProxySettings macOS系统代理设置
+type systemProxySettings struct {
+	http       *url.URL
+	https      *url.URL
+	exceptions []string
+}
+
+// proxyFor 获取访问目标地址使用的代理
+func (s *systemProxySettings) proxyFor(target *url.URL) *url.URL {
+	host := target.Hostname()
+	if host == "localhost" {
+		return nil
+	}
+	if ip := net.ParseIP(host); ip != nil && ip.IsLoopback() {
+		return nil
+	}
+	for _, exception := range s.exceptions {
+		if host == exception || (strings.HasPrefix(exception, "*.") && strings.HasSuffix(host, exception[1:])) {
+			return nil
+		}
+	}
+
+	if target.Scheme == "https" {
+		return s.https
+	}
+	return s.http
+}
+
+// readScutilProxy 读取macOS系统代理设置
+func readScutilProxy() ([]byte, error) {
+	if runtime.GOOS != "darwin" {
+		return nil, errors.New("system proxy settings are only read on macOS")
+	}
+	return exec.Command("scutil", "--proxy").Output()
+}
+
+// parseScutilProxy 解析 scutil --proxy 的输出
+func parseScutilProxy(output []byte) *systemProxySettings {
+	values := make(map[string]string)
+	settings := &systemProxySettings{}
+
+	inExceptions := false
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if inExceptions {
+			if line == "}" {
+				inExceptions = false
+			} else if _, value, ok := strings.Cut(line, " : "); ok {
+				settings.exceptions = append(settings.exceptions, strings.TrimSpace(value))
+			}
+			continue
+		}
+
+		key, value, ok := strings.Cut(line, " : ")
+		if !ok {
+			continue
+		}
+		if key == "ExceptionsList" {
+			inExceptions = true
+			continue
+		}
+		values[key] = strings.TrimSpace(value)
+	}
+
+	proxyFor := func(prefix string) *url.URL {
+		if values[prefix+"Enable"] != "1" || values[prefix+"Proxy"] == "" {
+			return nil
+		}
+		host := values[prefix+"Proxy"]
+		if port := values[prefix+"Port"]; port != "" {
+			host = net.JoinHostPort(host, port)
+		}
+		return &url.URL{Scheme: "http", Host: host}
+	}
+	settings.http = proxyFor("HTTP")
+	settings.https = proxyFor("HTTPS")
+	return settings
+}
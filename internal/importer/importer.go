@@ -0,0 +1,206 @@
+// Package importer 从用户已有的基础设施配置中发现可用于Profile的hosts条目。
+package importer
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/flyhigher139/mhost/pkg/hostsfile"
+)
+
+// Suggestion 建议添加的条目
+type Suggestion struct {
+	Entry  hostsfile.Entry `json:"entry"`
+	Source string          `json:"source"` // 来源文件
+	Note   string          `json:"note"`   // 说明
+}
+
+// Importer 条目发现接口
+type Importer interface {
+	// Name 导入来源名称
+	Name() string
+
+	// Suggest 返回建议的条目，来源不存在时返回空列表
+	Suggest() ([]Suggestion, error)
+}
+
+// DefaultImporters 返回默认的导入来源
+func DefaultImporters(homeDir string, normalizer hostsfile.Normalizer) []Importer {
+	return []Importer{
+		&SSHConfigImporter{Path: filepath.Join(homeDir, ".ssh", "config"), Normalizer: normalizer},
+		&ResolverImporter{Dir: "/etc/resolver", Normalizer: normalizer},
+	}
+}
+
+// SuggestAll 汇总多个来源的建议，按主机名去重
+func SuggestAll(importers []Importer) ([]Suggestion, error) {
+	var all []Suggestion
+	seen := make(map[string]bool)
+	for _, imp := range importers {
+		suggestions, err := imp.Suggest()
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", imp.Name(), err)
+		}
+		for _, suggestion := range suggestions {
+			if seen[suggestion.Entry.Hostname] {
+				continue
+			}
+			seen[suggestion.Entry.Hostname] = true
+			all = append(all, suggestion)
+		}
+	}
+	return all, nil
+}
+
+// SSHConfigImporter 从 ~/.ssh/config 的 Host/HostName 配对中发现条目，仅使用HostName为IP地址的配置
+type SSHConfigImporter struct {
+	Path       string
+	Normalizer hostsfile.Normalizer // 主机名规范化规则
+}
+
+// Name 导入来源名称
+func (i *SSHConfigImporter) Name() string { return "ssh config" }
+
+// Suggest 解析SSH配置
+func (i *SSHConfigImporter) Suggest() ([]Suggestion, error) {
+	file, err := os.Open(i.Path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open ssh config: %w", err)
+	}
+	defer file.Close()
+
+	return parseSSHConfig(file, i.Path, i.Normalizer)
+}
+
+// parseSSHConfig 解析SSH配置内容
+func parseSSHConfig(r io.Reader, source string, normalizer hostsfile.Normalizer) ([]Suggestion, error) {
+	var suggestions []Suggestion
+	var hosts []string
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		key, value := splitSSHConfigLine(scanner.Text())
+		switch strings.ToLower(key) {
+		case "host":
+			hosts = strings.Fields(value)
+		case "match":
+			// Match块的条件无法映射到主机名
+			hosts = nil
+		case "hostname":
+			if net.ParseIP(value) == nil {
+				continue
+			}
+			for _, host := range hosts {
+				// 跳过通配符和取反模式
+				if strings.ContainsAny(host, "*?!") {
+					continue
+				}
+				hostname, err := normalizer.Normalize(host)
+				if err != nil {
+					continue
+				}
+				suggestions = append(suggestions, Suggestion{
+					Entry:  hostsfile.Entry{IP: value, Hostname: hostname, Comment: "ssh", Enabled: true},
+					Source: source,
+					Note:   fmt.Sprintf("Host %s", host),
+				})
+			}
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read ssh config: %w", err)
+	}
+	return suggestions, nil
+}
+
+// splitSSHConfigLine 拆分SSH配置行，支持 "Key value" 和 "Key=value" 两种写法
+func splitSSHConfigLine(line string) (string, string) {
+	line = strings.TrimSpace(line)
+	if line == "" || strings.HasPrefix(line, "#") {
+		return "", ""
+	}
+
+	idx := strings.IndexAny(line, " \t=")
+	if idx < 0 {
+		return line, ""
+	}
+	key := line[:idx]
+	value := strings.TrimSpace(strings.TrimLeft(strings.TrimSpace(line[idx:]), "="))
+	return key, strings.Trim(value, `"`)
+}
+
+// ResolverImporter 从macOS /etc/resolver 目录发现条目：文件名为域名，nameserver为该域名的DNS服务器
+type ResolverImporter struct {
+	Dir        string
+	Normalizer hostsfile.Normalizer // 主机名规范化规则
+}
+
+// Name 导入来源名称
+func (i *ResolverImporter) Name() string { return "resolver" }
+
+// Suggest 解析resolver文件
+func (i *ResolverImporter) Suggest() ([]Suggestion, error) {
+	files, err := os.ReadDir(i.Dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read resolver directory: %w", err)
+	}
+
+	var suggestions []Suggestion
+	for _, file := range files {
+		if file.IsDir() {
+			continue
+		}
+		domain, err := i.Normalizer.Normalize(file.Name())
+		if err != nil {
+			continue
+		}
+
+		path := filepath.Join(i.Dir, file.Name())
+		nameservers, err := parseResolverFile(path)
+		if err != nil {
+			return nil, err
+		}
+		if len(nameservers) == 0 {
+			continue
+		}
+
+		suggestions = append(suggestions, Suggestion{
+			Entry:  hostsfile.Entry{IP: nameservers[0], Hostname: domain, Comment: "resolver", Enabled: true},
+			Source: path,
+			Note:   fmt.Sprintf("nameserver for %s", domain),
+		})
+	}
+
+	sort.Slice(suggestions, func(a, b int) bool { return suggestions[a].Entry.Hostname < suggestions[b].Entry.Hostname })
+	return suggestions, nil
+}
+
+// parseResolverFile 读取resolver文件中的nameserver
+func parseResolverFile(path string) ([]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read resolver file: %w", err)
+	}
+
+	var nameservers []string
+	for _, line := range strings.Split(string(data), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) >= 2 && fields[0] == "nameserver" && net.ParseIP(fields[1]) != nil {
+			nameservers = append(nameservers, fields[1])
+		}
+	}
+	return nameservers, nil
+}
@@ -0,0 +1,154 @@
+package importer
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/flyhigher139/mhost/internal/httpclient"
+	"github.com/flyhigher139/mhost/pkg/hostsfile"
+	"github.com/flyhigher139/mhost/pkg/models"
+)
+
+// TestSSHConfigImporter 测试从SSH配置发现条目
+func TestSSHConfigImporter(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config")
+	config := `# comment
+Host bastion jump.test
+    HostName 10.0.0.1
+    User admin
+
+Host *.internal
+    HostName 10.0.0.2
+
+Host web
+    HostName=web.example.com
+
+Host db
+    hostname "10.0.0.3"
+
+Host Build.Test. under_score
+    HostName 10.0.0.4
+`
+	require.NoError(t, os.WriteFile(path, []byte(config), 0600))
+
+	suggestions, err := (&SSHConfigImporter{Path: path}).Suggest()
+	require.NoError(t, err)
+
+	var entries []hostsfile.Entry
+	for _, suggestion := range suggestions {
+		entries = append(entries, suggestion.Entry)
+		assert.Equal(t, path, suggestion.Source)
+	}
+	assert.Equal(t, []hostsfile.Entry{
+		{IP: "10.0.0.1", Hostname: "bastion", Comment: "ssh", Enabled: true},
+		{IP: "10.0.0.1", Hostname: "jump.test", Comment: "ssh", Enabled: true},
+		{IP: "10.0.0.3", Hostname: "db", Comment: "ssh", Enabled: true},
+		{IP: "10.0.0.4", Hostname: "build.test", Comment: "ssh", Enabled: true},
+	}, entries)
+
+	// 允许下划线时保留该主机名
+	suggestions, err = (&SSHConfigImporter{Path: path, Normalizer: hostsfile.Normalizer{AllowUnderscores: true}}).Suggest()
+	require.NoError(t, err)
+	assert.Len(t, suggestions, 5)
+
+	// 配置文件不存在时返回空列表
+	suggestions, err = (&SSHConfigImporter{Path: filepath.Join(t.TempDir(), "missing")}).Suggest()
+	assert.NoError(t, err)
+	assert.Empty(t, suggestions)
+}
+
+// TestResolverImporter 测试从resolver目录发现条目
+func TestResolverImporter(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "corp.test"), []byte("nameserver 10.1.0.53\nnameserver 10.1.0.54\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "empty.test"), []byte("port 53\n"), 0644))
+
+	imp := &ResolverImporter{Dir: dir}
+	suggestions, err := SuggestAll([]Importer{imp, imp})
+	require.NoError(t, err)
+	require.Len(t, suggestions, 1)
+	assert.Equal(t, hostsfile.Entry{IP: "10.1.0.53", Hostname: "corp.test", Comment: "resolver", Enabled: true}, suggestions[0].Entry)
+}
+
+// TestRemoteFetcher 测试远程下载的大小限制与地址校验
+func TestRemoteFetcher(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/staging.hosts":
+			w.Write([]byte("10.0.0.1\tapi.test\n# 10.0.0.2 web.test\n"))
+		case "/large":
+			w.Write([]byte(strings.Repeat("#", 2048)))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	fetcher := NewRemoteFetcher()
+	fetcher.MaxSize = 1024
+	ctx := context.Background()
+
+	data, err := fetcher.Fetch(ctx, server.URL+"/staging.hosts")
+	require.NoError(t, err)
+
+	profile, err := ParseProfile(data, NameFromURL(server.URL+"/staging.hosts"), hostsfile.DefaultNormalizer)
+	require.NoError(t, err)
+	assert.Equal(t, "staging", profile.Name)
+	require.Len(t, profile.Entries, 2)
+	assert.True(t, profile.Entries[0].Enabled)
+	assert.False(t, profile.Entries[1].Enabled)
+
+	_, err = fetcher.Fetch(ctx, server.URL+"/large")
+	assert.ErrorIs(t, err, ErrResponseTooLarge)
+
+	_, err = fetcher.Fetch(ctx, server.URL+"/missing")
+	assert.Error(t, err)
+
+	_, err = fetcher.Fetch(ctx, "file:///etc/hosts")
+	assert.ErrorIs(t, err, ErrUnsupportedURL)
+
+	// 离线模式下拒绝下载
+	fetcher.Clients = httpclient.NewFactory(models.NetworkConfig{Offline: true})
+	_, err = fetcher.Fetch(ctx, server.URL+"/staging.hosts")
+	assert.ErrorIs(t, err, httpclient.ErrOffline)
+}
+
+// TestParseProfile 测试解析Profile JSON与无效内容
+func TestParseProfile(t *testing.T) {
+	profile, err := ParseProfile([]byte(`{"name":"Dev","entries":[{"ip":"10.0.0.1","hostname":"api.test","enabled":true}]}`), "ignored", hostsfile.DefaultNormalizer)
+	require.NoError(t, err)
+	assert.Equal(t, "Dev", profile.Name)
+	assert.Len(t, profile.Entries, 1)
+
+	_, err = ParseProfile([]byte("<html>not found</html>"), "page", hostsfile.DefaultNormalizer)
+	assert.Error(t, err)
+
+	_, err = ParseProfile([]byte("# nothing here\n"), "empty", hostsfile.DefaultNormalizer)
+	assert.ErrorIs(t, err, ErrNoEntries)
+
+	_, err = ParseProfile([]byte(`{"name":""}`), "ignored", hostsfile.DefaultNormalizer)
+	assert.Error(t, err)
+
+	// 主机名在导入时规范化
+	profile, err = ParseProfile([]byte("10.0.0.1\tAPI.Test.\n"), "mixed", hostsfile.DefaultNormalizer)
+	require.NoError(t, err)
+	assert.Equal(t, "api.test", profile.Entries[0].Hostname)
+
+	_, err = ParseProfile([]byte("10.0.0.1\tmy_service\n"), "underscore", hostsfile.DefaultNormalizer)
+	assert.ErrorIs(t, err, hostsfile.ErrUnderscoreHostname)
+	_, err = ParseProfile([]byte("10.0.0.1\tmy_service\n"), "underscore", hostsfile.Normalizer{AllowUnderscores: true})
+	assert.NoError(t, err)
+
+	// 重复的主机名保留到导入预览中处理
+	profile, err = ParseProfile([]byte("10.0.0.1 api.test\n10.0.0.2 api.test\n"), "duplicates", hostsfile.DefaultNormalizer)
+	require.NoError(t, err)
+	assert.Len(t, profile.DuplicateHostnames(), 1)
+}
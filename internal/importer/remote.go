@@ -0,0 +1,219 @@
+package importer
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/flyhigher139/mhost/internal/httpclient"
+	"github.com/flyhigher139/mhost/pkg/hostsfile"
+	"github.com/flyhigher139/mhost/pkg/models"
+)
+
+const (
+	// DefaultFetchTimeout 远程下载的默认超时时间
+	DefaultFetchTimeout = 15 * time.Second
+	// DefaultMaxFetchSize 远程下载的默认大小上限
+	DefaultMaxFetchSize = 1 << 20
+)
+
+var (
+	// ErrUnsupportedURL 仅支持HTTP(S)地址
+	ErrUnsupportedURL = errors.New("only http and https URLs are supported")
+	// ErrResponseTooLarge 下载内容超过大小上限
+	ErrResponseTooLarge = errors.New("response exceeds the size limit")
+	// ErrNoEntries 内容中没有可导入的条目
+	ErrNoEntries = errors.New("no host entries found")
+)
+
+// RemoteFetcher 下载远程Profile内容，带超时与大小限制，默认使用应用的网络代理设置
+type RemoteFetcher struct {
+	Timeout time.Duration
+	MaxSize int64
+	Proxy   string              // 代理地址，为空时使用Clients的代理设置
+	Clients *httpclient.Factory // HTTP客户端工厂，为空时使用httpclient.Default()
+}
+
+// NewRemoteFetcher 创建使用默认限制的远程下载器
+func NewRemoteFetcher() *RemoteFetcher {
+	return &RemoteFetcher{
+		Timeout: DefaultFetchTimeout,
+		MaxSize: DefaultMaxFetchSize,
+	}
+}
+
+// FetchResult 条件下载的结果
+type FetchResult struct {
+	Data []byte
+	// NotModified 服务器返回304，内容自上次下载以来没有变化，Data为空
+	NotModified  bool
+	ETag         string
+	LastModified string
+}
+
+// Fetch 下载URL内容
+func (f *RemoteFetcher) Fetch(ctx context.Context, rawURL string) ([]byte, error) {
+	result, err := f.FetchConditional(ctx, rawURL, "", "")
+	if err != nil {
+		return nil, err
+	}
+	return result.Data, nil
+}
+
+// FetchConditional 带缓存验证信息下载URL内容：etag或lastModified不为空时发送
+// If-None-Match/If-Modified-Since，服务器返回304时结果的NotModified为true
+func (f *RemoteFetcher) FetchConditional(ctx context.Context, rawURL, etag, lastModified string) (*FetchResult, error) {
+	target, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" {
+		return nil, ErrUnsupportedURL
+	}
+
+	clients := f.Clients
+	if clients == nil {
+		clients = httpclient.Default()
+	}
+	client, err := clients.Client(f.Timeout)
+	if err != nil {
+		return nil, err
+	}
+	if f.Proxy != "" {
+		proxyURL, err := httpclient.ParseProxyURL(f.Proxy)
+		if err != nil {
+			return nil, err
+		}
+		client.Transport.(*http.Transport).Proxy = http.ProxyURL(proxyURL)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json, text/plain;q=0.9, */*;q=0.5")
+	if etag != "" {
+		req.Header.Set("If-None-Match", etag)
+	}
+	if lastModified != "" {
+		req.Header.Set("If-Modified-Since", lastModified)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download %s: %w", target.Redacted(), err)
+	}
+	defer resp.Body.Close()
+
+	result := &FetchResult{ETag: resp.Header.Get("ETag"), LastModified: resp.Header.Get("Last-Modified")}
+	if resp.StatusCode == http.StatusNotModified {
+		result.NotModified = true
+		if result.ETag == "" {
+			result.ETag = etag
+		}
+		if result.LastModified == "" {
+			result.LastModified = lastModified
+		}
+		return result, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to download %s: %s", target.Redacted(), resp.Status)
+	}
+	if f.MaxSize > 0 && resp.ContentLength > f.MaxSize {
+		return nil, ErrResponseTooLarge
+	}
+
+	reader := io.Reader(resp.Body)
+	if f.MaxSize > 0 {
+		reader = io.LimitReader(resp.Body, f.MaxSize+1)
+	}
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	if f.MaxSize > 0 && int64(len(data)) > f.MaxSize {
+		return nil, ErrResponseTooLarge
+	}
+	result.Data = data
+	return result, nil
+}
+
+// ParseProfile 解析mHost导出的Profile JSON或hosts格式文本，hosts格式时以defaultName命名；
+// 主机名按normalizer规范化
+func ParseProfile(data []byte, defaultName string, normalizer hostsfile.Normalizer) (*models.Profile, error) {
+	trimmed := bytes.TrimSpace(data)
+	if bytes.HasPrefix(trimmed, []byte("{")) {
+		var profile models.Profile
+		if err := json.Unmarshal(trimmed, &profile); err != nil {
+			return nil, fmt.Errorf("failed to parse profile: %w", err)
+		}
+		if err := validateProfile(&profile, normalizer); err != nil {
+			return nil, err
+		}
+		return &profile, nil
+	}
+
+	return ProfileFromEntries(hostsfile.ParseWithDisabled(strings.Split(string(data), "\n")), defaultName, normalizer)
+}
+
+// ProfileFromEntries 以name命名，用条目创建Profile并按normalizer规范化和校验，没有条目时返回ErrNoEntries。
+// 导入插件解析得到的条目同样经过这里
+func ProfileFromEntries(entries []hostsfile.Entry, name string, normalizer hostsfile.Normalizer) (*models.Profile, error) {
+	if len(entries) == 0 {
+		return nil, ErrNoEntries
+	}
+
+	profile := models.NewProfile(name, "")
+	for _, entry := range entries {
+		profile.AddEntry(entry.ToModel())
+	}
+	if err := validateProfile(profile, normalizer); err != nil {
+		return nil, err
+	}
+	return profile, nil
+}
+
+// validateProfile 规范化主机名并校验Profile及每个条目的IP与主机名格式。
+// 重复的主机名不视为错误，由导入预览提示并在导入时清理
+func validateProfile(profile *models.Profile, normalizer hostsfile.Normalizer) error {
+	if err := profile.Validate(); err != nil && !errors.Is(err, models.ErrDuplicateHostname) {
+		return err
+	}
+	for _, entry := range profile.Entries {
+		hostname, err := normalizer.Normalize(entry.Hostname)
+		if err != nil {
+			return err
+		}
+		entry.Hostname = hostname
+		if err := hostsfile.ValidateEntry(hostsfile.FromModel(entry)); err != nil {
+			return err
+		}
+		for _, ip := range entry.Variants {
+			if err := hostsfile.ValidateIP(ip); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// NameFromURL 根据URL路径生成Profile名称，例如 .../staging.hosts 生成 staging
+func NameFromURL(rawURL string) string {
+	target, err := url.Parse(rawURL)
+	if err != nil {
+		return "Imported"
+	}
+	base := path.Base(target.Path)
+	if base == "." || base == "/" || base == "raw" {
+		if target.Host == "" {
+			return "Imported"
+		}
+		return target.Host
+	}
+	return strings.TrimSuffix(base, path.Ext(base))
+}
@@ -0,0 +1,183 @@
+package inventory
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"text/template"
+
+	"github.com/jmespath/go-jmespath"
+
+	"github.com/flyhigher139/mhost/internal/importer"
+	"github.com/flyhigher139/mhost/pkg/models"
+)
+
+// ErrNotArray Query选出的结果不是记录数组
+var ErrNotArray = errors.New("inventory query did not select an array")
+
+// HTTPAdapter 从返回JSON的HTTP API获取记录：Query（JMESPath）从响应中选出记录数组，
+// 每条记录通过text/template模板生成主机名、IP和注释。主机名模板生成以空白分隔的多个主机名时，每个主机名生成一条记录
+type HTTPAdapter struct {
+	url      string
+	headers  map[string]string
+	query    *jmespath.JMESPath
+	hostname *template.Template
+	ip       *template.Template
+	comment  *template.Template
+	client   *http.Client
+}
+
+// NewHTTPAdapter 按资产清单配置创建HTTPAdapter，预先编译Query和模板；httpClient为nil时使用默认时限的http.Client
+func NewHTTPAdapter(config *models.Inventory, httpClient *http.Client) (*HTTPAdapter, error) {
+	if config == nil || config.URL == "" {
+		return nil, ErrNotInventory
+	}
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: DefaultTimeout}
+	}
+	adapter := &HTTPAdapter{url: config.URL, headers: config.Headers, client: httpClient}
+
+	if query := strings.TrimSpace(config.Query); query != "" {
+		compiled, err := jmespath.Compile(query)
+		if err != nil {
+			return nil, fmt.Errorf("invalid inventory query: %w", err)
+		}
+		adapter.query = compiled
+	}
+
+	var err error
+	if adapter.hostname, err = parseTemplate("hostname", config.Hostname, models.DefaultInventoryHostname); err != nil {
+		return nil, err
+	}
+	if adapter.ip, err = parseTemplate("ip", config.IP, models.DefaultInventoryIP); err != nil {
+		return nil, err
+	}
+	if config.Comment != "" {
+		if adapter.comment, err = parseTemplate("comment", config.Comment, ""); err != nil {
+			return nil, err
+		}
+	}
+	return adapter, nil
+}
+
+// parseTemplate 编译字段的映射模板，text为空时使用fallback。引用记录中不存在的字段时执行失败，该记录被跳过
+func parseTemplate(name, text, fallback string) (*template.Template, error) {
+	if strings.TrimSpace(text) == "" {
+		text = fallback
+	}
+	tmpl, err := template.New(name).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s template: %w", name, err)
+	}
+	return tmpl, nil
+}
+
+// Fetch 请求API并将选出的记录映射为Record。生成的主机名或IP为空、或模板执行失败的记录被跳过；
+// 所有记录都失败时返回第一个模板错误，便于发现映射配置的问题
+func (a *HTTPAdapter) Fetch(ctx context.Context) ([]Record, error) {
+	data, err := a.get(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if a.query != nil {
+		if data, err = a.query.Search(data); err != nil {
+			return nil, fmt.Errorf("inventory query failed: %w", err)
+		}
+	}
+	items, ok := data.([]interface{})
+	if !ok {
+		return nil, ErrNotArray
+	}
+
+	var records []Record
+	var firstErr error
+	for _, item := range items {
+		mapped, err := a.mapItem(item)
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		records = append(records, mapped...)
+	}
+	if len(records) == 0 && firstErr != nil {
+		return nil, firstErr
+	}
+	return records, nil
+}
+
+// get 请求API并解析JSON响应
+func (a *HTTPAdapter) get(ctx context.Context) (interface{}, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, a.url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	for name, value := range a.headers {
+		req.Header.Set(name, value)
+	}
+
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reach inventory: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, MaxResponseSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read inventory response: %w", err)
+	}
+	if len(body) > MaxResponseSize {
+		return nil, importer.ErrResponseTooLarge
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("inventory returned %s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+
+	var data interface{}
+	if err := json.Unmarshal(body, &data); err != nil {
+		return nil, fmt.Errorf("invalid inventory response: %w", err)
+	}
+	return data, nil
+}
+
+// mapItem 用模板将一条记录映射为Record
+func (a *HTTPAdapter) mapItem(item interface{}) ([]Record, error) {
+	hostnames, err := execute(a.hostname, item)
+	if err != nil {
+		return nil, err
+	}
+	ip, err := execute(a.ip, item)
+	if err != nil {
+		return nil, err
+	}
+	comment := ""
+	if a.comment != nil {
+		if comment, err = execute(a.comment, item); err != nil {
+			return nil, err
+		}
+	}
+	if ip == "" {
+		return nil, nil
+	}
+
+	var records []Record
+	for _, hostname := range strings.Fields(hostnames) {
+		records = append(records, Record{Hostname: hostname, IP: ip, Comment: comment})
+	}
+	return records, nil
+}
+
+// execute 执行模板并去掉结果首尾的空白
+func execute(tmpl *template.Template, item interface{}) (string, error) {
+	var b strings.Builder
+	if err := tmpl.Execute(&b, item); err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(b.String()), nil
+}
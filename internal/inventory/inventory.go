@@ -0,0 +1,348 @@
+// Package inventory 将公司资产清单（CMDB等机器清单系统）中的主机与IP对应关系定期同步到只读的Profile：
+// Adapter从数据源获取记录，Syncer按Profile的同步间隔更新条目，Profile处于激活状态时重新应用到hosts文件。
+// HTTPAdapter是从JSON HTTP API获取记录的参考实现，其他数据源实现Adapter即可接入。
+package inventory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/flyhigher139/mhost/internal/importer"
+	"github.com/flyhigher139/mhost/internal/profile"
+	"github.com/flyhigher139/mhost/internal/subscription"
+	"github.com/flyhigher139/mhost/pkg/hostsfile"
+	"github.com/flyhigher139/mhost/pkg/models"
+)
+
+const (
+	// DefaultCheckInterval 检查是否有资产清单到期的默认间隔，每个资产清单按自己的同步间隔获取
+	DefaultCheckInterval = time.Minute
+	// DefaultTimeout 访问资产清单API的默认时限
+	DefaultTimeout = 30 * time.Second
+	// MaxResponseSize 资产清单响应的大小上限
+	MaxResponseSize = 32 << 20
+)
+
+// ErrNotInventory Profile没有同步资产清单
+var ErrNotInventory = errors.New("profile has no inventory")
+
+// Record 资产清单中一台机器的主机名与IP
+type Record struct {
+	Hostname string
+	IP       string
+	Comment  string
+}
+
+// Adapter 资产清单数据源
+type Adapter interface {
+	// Fetch 获取数据源中的全部记录
+	Fetch(ctx context.Context) ([]Record, error)
+}
+
+// AdapterFunc 按Profile的资产清单配置创建Adapter
+type AdapterFunc func(config *models.Inventory) (Adapter, error)
+
+// Result 一次同步的结果
+type Result struct {
+	ProfileID   string
+	ProfileName string
+	// Changed 条目因资产清单内容变化而更新
+	Changed bool
+	Entries int
+	// Skipped 主机名或IP无效、或主机名重复而跳过的记录数
+	Skipped int
+	// Applied 更新后重新应用了激活的Profile
+	Applied bool
+	Err     error
+}
+
+// Syncer 资产清单同步服务
+type Syncer struct {
+	profileManager profile.Manager
+	newAdapter     AdapterFunc
+	now            func() time.Time
+
+	// syncMu 串行执行同步，避免同一Profile被并发获取和更新
+	syncMu sync.Mutex
+
+	mu         sync.RWMutex
+	normalizer hostsfile.Normalizer
+	applier    func(*models.Profile) error
+	onResult   func(*Result)
+	stopChan   chan struct{}
+	cancel     context.CancelFunc
+}
+
+// NewSyncer 创建资产清单同步服务，newAdapter为nil时使用默认时限的HTTPAdapter
+func NewSyncer(profileManager profile.Manager, newAdapter AdapterFunc) *Syncer {
+	if newAdapter == nil {
+		newAdapter = HTTPAdapterFunc(nil)
+	}
+	return &Syncer{
+		profileManager: profileManager,
+		newAdapter:     newAdapter,
+		now:            time.Now,
+		normalizer:     hostsfile.DefaultNormalizer,
+	}
+}
+
+// HTTPAdapterFunc 使用httpClient创建HTTPAdapter的AdapterFunc
+func HTTPAdapterFunc(httpClient *http.Client) AdapterFunc {
+	return func(config *models.Inventory) (Adapter, error) {
+		adapter, err := NewHTTPAdapter(config, httpClient)
+		if err != nil {
+			return nil, err
+		}
+		return adapter, nil
+	}
+}
+
+// SetNormalizer 设置主机名规范化规则
+func (s *Syncer) SetNormalizer(normalizer hostsfile.Normalizer) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.normalizer = normalizer
+}
+
+// SetApplier 设置重新应用激活Profile的函数，未设置时只更新Profile
+func (s *Syncer) SetApplier(applier func(*models.Profile) error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.applier = applier
+}
+
+// OnResult 设置每次同步完成后的回调
+func (s *Syncer) OnResult(callback func(*Result)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.onResult = callback
+}
+
+// Sync 立即同步指定Profile的资产清单，不考虑同步间隔。
+// 获取或映射失败时保留原有条目，错误记录在资产清单的LastError中
+func (s *Syncer) Sync(ctx context.Context, id string) *Result {
+	s.syncMu.Lock()
+	result := s.sync(ctx, id)
+	s.syncMu.Unlock()
+
+	s.mu.RLock()
+	onResult := s.onResult
+	s.mu.RUnlock()
+	if onResult != nil {
+		onResult(result)
+	}
+	return result
+}
+
+// sync 获取资产清单的记录并更新Profile
+func (s *Syncer) sync(ctx context.Context, id string) *Result {
+	result := &Result{ProfileID: id}
+	p, err := s.profileManager.GetProfile(id)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+	result.ProfileName = p.Name
+	result.Entries = len(p.Entries)
+	if p.Inventory == nil || p.Inventory.URL == "" {
+		result.Err = ErrNotInventory
+		return result
+	}
+
+	inventory := p.Inventory
+	inventory.LastChecked = s.now()
+	var entries []*models.HostEntry
+	adapter, err := s.newAdapter(inventory)
+	if err == nil {
+		var records []Record
+		records, err = adapter.Fetch(ctx)
+		if err == nil {
+			entries, result.Skipped, err = s.entries(records)
+		}
+	}
+	if err != nil && ctx.Err() != nil {
+		// 服务停止时取消的请求不算作同步错误
+		result.Err = err
+		return result
+	}
+	if err != nil {
+		inventory.LastError = err.Error()
+		result.Err = fmt.Errorf("sync inventory %s: %w", inventory.URL, err)
+	} else {
+		inventory.LastError = ""
+		p.Entries, result.Changed = subscription.MergeEntries(p.Entries, entries)
+		result.Entries = len(p.Entries)
+		if result.Changed {
+			inventory.LastUpdated = inventory.LastChecked
+		}
+	}
+
+	if err := s.profileManager.SyncInventoryProfile(p); err != nil {
+		if result.Err == nil {
+			result.Err = err
+		}
+		return result
+	}
+	if result.Changed {
+		result.Applied, result.Err = s.applyIfActive(p)
+	}
+	return result
+}
+
+// entries 将记录转为启用的条目，跳过主机名或IP无效的记录和重复的主机名，返回跳过的数量
+func (s *Syncer) entries(records []Record) ([]*models.HostEntry, int, error) {
+	s.mu.RLock()
+	normalizer := s.normalizer
+	s.mu.RUnlock()
+
+	seen := make(map[string]bool)
+	var entries []*models.HostEntry
+	skipped := 0
+	for _, record := range records {
+		entry := hostsfile.Entry{IP: record.IP, Hostname: record.Hostname, Comment: record.Comment, Enabled: true}
+		normalized, err := normalizer.NormalizeEntry(entry)
+		if err != nil || hostsfile.ValidateEntry(normalized) != nil || seen[normalized.Hostname] {
+			skipped++
+			continue
+		}
+		seen[normalized.Hostname] = true
+		entries = append(entries, normalized.ToModel())
+	}
+	if len(entries) == 0 {
+		return nil, skipped, importer.ErrNoEntries
+	}
+	return entries, skipped, nil
+}
+
+// applyIfActive Profile处于激活状态（包括与其他Profile同时激活）时重新应用
+func (s *Syncer) applyIfActive(p *models.Profile) (bool, error) {
+	s.mu.RLock()
+	applier := s.applier
+	s.mu.RUnlock()
+	if applier == nil {
+		return false, nil
+	}
+
+	active, err := s.profileManager.GetActiveProfiles()
+	if err != nil {
+		if errors.Is(err, models.ErrProfileNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+	if !slices.ContainsFunc(active, func(a *models.Profile) bool { return a.ID == p.ID }) {
+		return false, nil
+	}
+	if err := applier(p); err != nil {
+		return false, fmt.Errorf("apply synced profile: %w", err)
+	}
+	return true, nil
+}
+
+// Attach 为Profile设置要同步的资产清单，Profile随即成为只读，条目在下一次同步时更新
+func (s *Syncer) Attach(id string, config *models.Inventory) (*models.Profile, error) {
+	if config == nil {
+		return nil, ErrNotInventory
+	}
+
+	s.syncMu.Lock()
+	defer s.syncMu.Unlock()
+
+	p, err := s.profileManager.GetProfile(id)
+	if err != nil {
+		return nil, err
+	}
+	p.Inventory = config
+	if err := s.profileManager.SyncInventoryProfile(p); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// Delete 删除同步资产清单的只读Profile，等待正在进行的同步结束，不能删除激活的Profile
+func (s *Syncer) Delete(id string) error {
+	s.syncMu.Lock()
+	defer s.syncMu.Unlock()
+
+	p, err := s.profileManager.GetProfile(id)
+	if err != nil {
+		return err
+	}
+	if p.Inventory == nil {
+		return ErrNotInventory
+	}
+	return s.profileManager.DeleteInventoryProfile(id)
+}
+
+// SyncDue 同步所有到期的资产清单
+func (s *Syncer) SyncDue(ctx context.Context) []*Result {
+	summaries, err := s.profileManager.ListProfiles()
+	if err != nil {
+		return []*Result{{Err: err}}
+	}
+
+	var results []*Result
+	for _, summary := range summaries {
+		if ctx.Err() != nil {
+			break
+		}
+		p, err := s.profileManager.GetProfile(summary.ID)
+		if err != nil || p.Inventory == nil || !p.Inventory.Due(s.now()) {
+			continue
+		}
+		results = append(results, s.Sync(ctx, p.ID))
+	}
+	return results
+}
+
+// Start 立即同步到期的资产清单，然后按固定间隔检查
+func (s *Syncer) Start(interval time.Duration) error {
+	if interval <= 0 {
+		return fmt.Errorf("invalid check interval: %v", interval)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.stopChan != nil {
+		return fmt.Errorf("syncer already started")
+	}
+	s.stopChan = make(chan struct{})
+	ctx, cancel := context.WithCancel(context.Background())
+	s.cancel = cancel
+
+	go func(stop chan struct{}) {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		s.SyncDue(ctx)
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				s.SyncDue(ctx)
+			}
+		}
+	}(s.stopChan)
+
+	return nil
+}
+
+// Stop 停止定时同步并取消正在进行的请求
+func (s *Syncer) Stop() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.stopChan != nil {
+		close(s.stopChan)
+		s.stopChan = nil
+		s.cancel()
+		s.cancel = nil
+	}
+}
@@ -0,0 +1,229 @@
+package inventory
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/flyhigher139/mhost/internal/profile"
+	"github.com/flyhigher139/mhost/pkg/models"
+)
+
+// fakeCMDB 模拟需要令牌的资产清单API
+type fakeCMDB struct {
+	mu       sync.Mutex
+	body     string
+	requests int
+}
+
+func (f *fakeCMDB) set(body string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.body = body
+}
+
+func (f *fakeCMDB) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.requests++
+	if r.Header.Get("Authorization") != "Bearer token" {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write([]byte(f.body))
+}
+
+// machines 资产清单响应，包含已下线的机器、缺少地址的机器和地址无效的机器
+const machines = `{"data": {"machines": [
+	{"name": "web-1", "domain": "corp.test", "addr": "10.0.0.1", "status": "online", "owner": "ops"},
+	{"name": "WEB-2", "domain": "corp.test", "addr": "10.0.0.2", "status": "online", "owner": "ops"},
+	{"name": "db-1", "domain": "corp.test", "addr": "10.0.1.1", "status": "retired", "owner": "dba"},
+	{"name": "cache-1", "domain": "corp.test", "status": "online", "owner": "ops"},
+	{"name": "gw-1", "domain": "corp.test", "addr": "unknown", "status": "online", "owner": "net"}
+]}}`
+
+// cmdbInventory 选出在线机器、以"名称.域名"作为主机名的资产清单配置
+func cmdbInventory(url string) *models.Inventory {
+	return &models.Inventory{
+		URL:      url,
+		Headers:  map[string]string{"Authorization": "Bearer token"},
+		Query:    "data.machines[?status=='online']",
+		Hostname: "{{.name}}.{{.domain}}",
+		IP:       "{{.addr}}",
+		Comment:  "owner: {{.owner}}",
+	}
+}
+
+// TestHTTPAdapter 测试JMESPath选择、模板映射、多个主机名和缺少字段的记录
+func TestHTTPAdapter(t *testing.T) {
+	server := &fakeCMDB{}
+	server.set(machines)
+	ts := httptest.NewServer(server)
+	defer ts.Close()
+
+	adapter, err := NewHTTPAdapter(cmdbInventory(ts.URL), ts.Client())
+	require.NoError(t, err)
+	records, err := adapter.Fetch(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []Record{
+		{Hostname: "web-1.corp.test", IP: "10.0.0.1", Comment: "owner: ops"},
+		{Hostname: "WEB-2.corp.test", IP: "10.0.0.2", Comment: "owner: ops"},
+		{Hostname: "gw-1.corp.test", IP: "unknown", Comment: "owner: net"},
+	}, records, "records without an address are skipped")
+
+	// 默认映射使用记录的hostname和ip字段，响应本身是数组，以空白分隔的主机名各生成一条记录
+	server.set(`[{"hostname": "a.test a-alias.test", "ip": "10.1.0.1"}, {"hostname": "b.test", "ip": "10.1.0.2"}]`)
+	adapter, err = NewHTTPAdapter(&models.Inventory{URL: ts.URL, Headers: map[string]string{"Authorization": "Bearer token"}}, ts.Client())
+	require.NoError(t, err)
+	records, err = adapter.Fetch(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []Record{
+		{Hostname: "a.test", IP: "10.1.0.1"},
+		{Hostname: "a-alias.test", IP: "10.1.0.1"},
+		{Hostname: "b.test", IP: "10.1.0.2"},
+	}, records)
+
+	// 所有记录都缺少模板引用的字段时返回模板错误
+	adapter, err = NewHTTPAdapter(&models.Inventory{URL: ts.URL, Headers: map[string]string{"Authorization": "Bearer token"}, IP: "{{.address}}"}, ts.Client())
+	require.NoError(t, err)
+	_, err = adapter.Fetch(context.Background())
+	assert.ErrorContains(t, err, "address")
+
+	adapter, err = NewHTTPAdapter(&models.Inventory{URL: ts.URL, Headers: map[string]string{"Authorization": "Bearer token"}, Query: "[0]"}, ts.Client())
+	require.NoError(t, err)
+	_, err = adapter.Fetch(context.Background())
+	assert.ErrorIs(t, err, ErrNotArray)
+
+	adapter, err = NewHTTPAdapter(&models.Inventory{URL: ts.URL}, ts.Client())
+	require.NoError(t, err)
+	_, err = adapter.Fetch(context.Background())
+	assert.ErrorContains(t, err, "401")
+
+	_, err = NewHTTPAdapter(&models.Inventory{URL: ts.URL, Query: "data.[["}, nil)
+	assert.ErrorContains(t, err, "invalid inventory query")
+	_, err = NewHTTPAdapter(&models.Inventory{URL: ts.URL, Hostname: "{{.name"}, nil)
+	assert.ErrorContains(t, err, "invalid hostname template")
+	_, err = NewHTTPAdapter(&models.Inventory{}, nil)
+	assert.ErrorIs(t, err, ErrNotInventory)
+}
+
+// TestSync 测试条目更新、沿用未变化条目的ID、失败时保留条目以及激活Profile的重新应用
+func TestSync(t *testing.T) {
+	server := &fakeCMDB{}
+	server.set(machines)
+	ts := httptest.NewServer(server)
+	defer ts.Close()
+
+	pm, err := profile.NewManager(filepath.Join(t.TempDir(), "profiles"))
+	require.NoError(t, err)
+	p, err := pm.CreateProfile("CMDB", "")
+	require.NoError(t, err)
+	require.NoError(t, pm.ActivateProfile(p.ID))
+
+	var applied int
+	syncer := NewSyncer(pm, HTTPAdapterFunc(ts.Client()))
+	config := cmdbInventory(ts.URL)
+	config.IntervalMinutes = 30
+	p, err = syncer.Attach(p.ID, config)
+	require.NoError(t, err)
+	assert.True(t, p.ReadOnly())
+	detached := p.Clone()
+	detached.Inventory = nil
+	assert.ErrorIs(t, pm.UpdateProfile(detached), models.ErrReadOnlyProfile, "the inventory can only be changed through the syncer")
+	_, err = syncer.Attach(p.ID, nil)
+	assert.ErrorIs(t, err, ErrNotInventory)
+
+	syncer.SetApplier(func(*models.Profile) error {
+		applied++
+		return nil
+	})
+
+	ctx := context.Background()
+	result := syncer.Sync(ctx, p.ID)
+	require.NoError(t, result.Err)
+	assert.True(t, result.Changed)
+	assert.True(t, result.Applied)
+	assert.Equal(t, 2, result.Entries)
+	assert.Equal(t, 1, result.Skipped, "the record with an invalid address is skipped")
+	assert.Equal(t, 1, applied)
+
+	synced, err := pm.GetProfile(p.ID)
+	require.NoError(t, err)
+	require.Len(t, synced.Entries, 2)
+	assert.Equal(t, "web-2.corp.test", synced.Entries[1].Hostname)
+	assert.Equal(t, "owner: ops", synced.Entries[0].Comment)
+	assert.Empty(t, synced.Inventory.LastError)
+	firstID := synced.Entries[0].ID
+
+	result = syncer.Sync(ctx, p.ID)
+	require.NoError(t, result.Err)
+	assert.False(t, result.Changed)
+	assert.Equal(t, 1, applied)
+
+	server.set(`{"data": {"machines": [{"name": "web-1", "domain": "corp.test", "addr": "10.0.0.1", "status": "online", "owner": "ops"}]}}`)
+	result = syncer.Sync(ctx, p.ID)
+	require.NoError(t, result.Err)
+	assert.True(t, result.Changed)
+	synced, err = pm.GetProfile(p.ID)
+	require.NoError(t, err)
+	require.Len(t, synced.Entries, 1)
+	assert.Equal(t, firstID, synced.Entries[0].ID, "unchanged entries keep their IDs")
+
+	server.set(`{"data": {"machines": []}}`)
+	result = syncer.Sync(ctx, p.ID)
+	assert.Error(t, result.Err)
+	synced, err = pm.GetProfile(p.ID)
+	require.NoError(t, err)
+	assert.Len(t, synced.Entries, 1, "entries are kept when the inventory is empty")
+	assert.NotEmpty(t, synced.Inventory.LastError)
+
+	plain, err := pm.CreateProfile("Plain", "")
+	require.NoError(t, err)
+	assert.ErrorIs(t, syncer.Sync(ctx, plain.ID).Err, ErrNotInventory)
+
+	// 只读Profile只能通过同步删除，激活时不能删除
+	assert.ErrorIs(t, pm.DeleteProfile(p.ID), models.ErrReadOnlyProfile)
+	assert.ErrorIs(t, syncer.Delete(p.ID), models.ErrActiveProfile)
+	require.NoError(t, pm.ActivateProfile(plain.ID))
+	assert.ErrorIs(t, syncer.Delete(plain.ID), ErrNotInventory)
+	require.NoError(t, syncer.Delete(p.ID))
+	_, err = pm.GetProfile(p.ID)
+	assert.ErrorIs(t, err, models.ErrProfileNotFound)
+}
+
+// TestSyncDue 测试只同步到期的资产清单
+func TestSyncDue(t *testing.T) {
+	server := &fakeCMDB{}
+	server.set(machines)
+	ts := httptest.NewServer(server)
+	defer ts.Close()
+
+	pm, err := profile.NewManager(filepath.Join(t.TempDir(), "profiles"))
+	require.NoError(t, err)
+	p, err := pm.CreateProfile("CMDB", "")
+	require.NoError(t, err)
+	_, err = pm.CreateProfile("Plain", "")
+	require.NoError(t, err)
+
+	now := time.Now()
+	syncer := NewSyncer(pm, HTTPAdapterFunc(ts.Client()))
+	_, err = syncer.Attach(p.ID, cmdbInventory(ts.URL))
+	require.NoError(t, err)
+	syncer.now = func() time.Time { return now }
+
+	ctx := context.Background()
+	require.Len(t, syncer.SyncDue(ctx), 1)
+	assert.Empty(t, syncer.SyncDue(ctx), "not due again before the interval")
+
+	now = now.Add(models.DefaultInventoryInterval)
+	assert.Len(t, syncer.SyncDue(ctx), 1)
+	assert.Equal(t, 2, server.requests)
+}
@@ -0,0 +1,307 @@
+// Package plugins 导入导出插件：插件是plugins目录中的可执行文件，通过子进程协议与mHost交换数据，
+// 社区格式（例如NextDNS、Pi-hole、企业资产API）无需修改mHost即可支持。协议的三个子命令：
+//
+//	<plugin> describe  标准输出写出插件说明：{"name": "pihole", "description": "...", "import": true, "export": true, "extension": ".list"}
+//	<plugin> import    标准输入为待导入的原始内容，标准输出写出 {"entries": [{"ip": "...", "hostname": "...", "comment": "...", "enabled": true}]}
+//	<plugin> export    标准输入为Profile的JSON（name、environment以及启用的entries），标准输出为导出的内容
+//
+// 退出码不为0时以标准错误的内容作为错误信息。每次调用都有时限和输出大小上限
+package plugins
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/flyhigher139/mhost/internal/exporter"
+	"github.com/flyhigher139/mhost/internal/importer"
+	"github.com/flyhigher139/mhost/pkg/hostsfile"
+	"github.com/flyhigher139/mhost/pkg/models"
+)
+
+// DefaultDirName 数据目录下默认的插件目录名
+const DefaultDirName = "plugins"
+
+// DefaultTimeout 每次调用插件的默认时限
+const DefaultTimeout = 30 * time.Second
+
+// MaxOutputSize 插件标准输出的大小上限
+const MaxOutputSize = 8 << 20
+
+// 协议的子命令
+const (
+	commandDescribe = "describe"
+	commandImport   = "import"
+	commandExport   = "export"
+)
+
+var (
+	// ErrNotSupported 插件不支持请求的操作
+	ErrNotSupported = errors.New("operation not supported by plugin")
+	// ErrOutputTooLarge 插件的输出超过大小上限
+	ErrOutputTooLarge = errors.New("plugin output exceeds the size limit")
+)
+
+var _ exporter.Exporter = (*Plugin)(nil)
+
+// namePattern 插件名称只能包含小写字母、数字、点、下划线和连字符，同时用作导出格式名
+var namePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9._-]*$`)
+
+// DefaultDir 获取数据目录下默认的插件目录
+func DefaultDir(dataDir string) string {
+	return filepath.Join(dataDir, DefaultDirName)
+}
+
+// Manifest describe子命令输出的插件说明
+type Manifest struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	CanImport   bool   `json:"import"`              // 是否支持import子命令
+	CanExport   bool   `json:"export"`              // 是否支持export子命令
+	Extension   string `json:"extension,omitempty"` // 导出文件建议的扩展名，为空时使用.txt
+}
+
+// Plugin 一个已发现的插件
+type Plugin struct {
+	Manifest
+	Path    string
+	timeout time.Duration
+}
+
+// importOutput import子命令的输出
+type importOutput struct {
+	Entries []hostsfile.Entry `json:"entries"`
+}
+
+// exportInput export子命令的输入
+type exportInput struct {
+	Name        string            `json:"name"`
+	Environment string            `json:"environment,omitempty"`
+	Entries     []hostsfile.Entry `json:"entries"`
+}
+
+// Discover 发现目录下的全部插件，按名称排序，timeout为0时使用DefaultTimeout。目录不存在时返回空列表。
+// 无法描述自身或与内置导出格式、其他插件重名的插件被跳过，对应的错误合并后与可用的插件一起返回
+func Discover(dir string, timeout time.Duration) ([]*Plugin, error) {
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	files, err := os.ReadDir(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read plugins directory: %w", err)
+	}
+
+	taken := make(map[string]bool)
+	for _, format := range exporter.Formats() {
+		taken[string(format)] = true
+	}
+
+	var plugins []*Plugin
+	var errs []error
+	for _, file := range files {
+		if file.IsDir() || strings.HasPrefix(file.Name(), ".") {
+			continue
+		}
+		info, err := file.Info()
+		if err != nil || info.Mode()&0111 == 0 {
+			continue
+		}
+
+		plugin, err := describe(filepath.Join(dir, file.Name()), timeout)
+		if err == nil && taken[plugin.Name] {
+			err = fmt.Errorf("name %q is already in use", plugin.Name)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("plugin %s: %w", file.Name(), err))
+			continue
+		}
+		taken[plugin.Name] = true
+		plugins = append(plugins, plugin)
+	}
+	sort.Slice(plugins, func(i, j int) bool { return plugins[i].Name < plugins[j].Name })
+	return plugins, errors.Join(errs...)
+}
+
+// LoadConfig 按配置发现插件，未启用时返回空列表；未设置目录时使用数据目录下的plugins
+func LoadConfig(config models.PluginsConfig, dataDir string) ([]*Plugin, error) {
+	if !config.Enabled {
+		return nil, nil
+	}
+	dir := config.Dir
+	if dir == "" {
+		dir = DefaultDir(dataDir)
+	}
+	return Discover(dir, config.Timeout)
+}
+
+// describe 运行describe子命令并检查插件说明
+func describe(path string, timeout time.Duration) (*Plugin, error) {
+	plugin := &Plugin{Path: path, timeout: timeout}
+	output, err := plugin.run(commandDescribe, nil)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(output, &plugin.Manifest); err != nil {
+		return nil, fmt.Errorf("invalid %s output: %w", commandDescribe, err)
+	}
+	if !namePattern.MatchString(plugin.Name) {
+		return nil, fmt.Errorf("invalid name %q", plugin.Name)
+	}
+	if !plugin.CanImport && !plugin.CanExport {
+		return nil, errors.New("plugin supports neither import nor export")
+	}
+	return plugin, nil
+}
+
+// run 以子命令运行插件，input写入标准输入，返回标准输出
+func (p *Plugin) run(command string, input []byte) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), p.timeout)
+	defer cancel()
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, p.Path, command)
+	cmd.Stdin = bytes.NewReader(input)
+	cmd.Stdout = &limitedBuffer{buf: &stdout, limit: MaxOutputSize}
+	cmd.Stderr = &limitedBuffer{buf: &stderr, limit: 64 << 10}
+	cmd.WaitDelay = time.Second
+
+	err := cmd.Run()
+	if ctx.Err() != nil {
+		return nil, fmt.Errorf("%s timed out after %s", command, p.timeout)
+	}
+	if stdout.Len() > MaxOutputSize {
+		return nil, ErrOutputTooLarge
+	}
+	if err != nil {
+		if message := strings.TrimSpace(stderr.String()); message != "" {
+			return nil, fmt.Errorf("%s failed: %s", command, message)
+		}
+		return nil, fmt.Errorf("%s failed: %w", command, err)
+	}
+	return stdout.Bytes(), nil
+}
+
+// ParseProfile 用插件解析待导入的内容，以name命名生成Profile，主机名按normalizer规范化和校验
+func (p *Plugin) ParseProfile(data []byte, name string, normalizer hostsfile.Normalizer) (*models.Profile, error) {
+	if !p.CanImport {
+		return nil, fmt.Errorf("%s: %w", p.Name, ErrNotSupported)
+	}
+	output, err := p.run(commandImport, data)
+	if err != nil {
+		return nil, fmt.Errorf("plugin %s: %w", p.Name, err)
+	}
+
+	var parsed importOutput
+	if err := json.Unmarshal(output, &parsed); err != nil {
+		return nil, fmt.Errorf("plugin %s: invalid %s output: %w", p.Name, commandImport, err)
+	}
+	profile, err := importer.ProfileFromEntries(parsed.Entries, name, normalizer)
+	if err != nil {
+		return nil, fmt.Errorf("plugin %s: %w", p.Name, err)
+	}
+	profile.Description = fmt.Sprintf("imported with plugin %s", p.Name)
+	return profile, nil
+}
+
+// Format 导出格式，与插件名称相同
+func (p *Plugin) Format() exporter.Format {
+	return exporter.Format(p.Name)
+}
+
+// FileExtension 导出文件建议的扩展名
+func (p *Plugin) FileExtension() string {
+	if p.Extension == "" {
+		return ".txt"
+	}
+	if !strings.HasPrefix(p.Extension, ".") {
+		return "." + p.Extension
+	}
+	return p.Extension
+}
+
+// Export 用插件导出Profile中启用的条目，实现exporter.Exporter
+func (p *Plugin) Export(profile *models.Profile) ([]byte, error) {
+	if !p.CanExport {
+		return nil, fmt.Errorf("%s: %w", p.Name, ErrNotSupported)
+	}
+	entries, err := exporter.EnabledEntries(profile)
+	if err != nil {
+		return nil, err
+	}
+
+	input, err := json.Marshal(exportInput{
+		Name:        profile.Name,
+		Environment: profile.Environment,
+		Entries:     hostsfile.FromModels(entries),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode profile: %w", err)
+	}
+	output, err := p.run(commandExport, input)
+	if err != nil {
+		return nil, fmt.Errorf("plugin %s: %w", p.Name, err)
+	}
+	return output, nil
+}
+
+// Importers 支持导入的插件
+func Importers(plugins []*Plugin) []*Plugin {
+	var importers []*Plugin
+	for _, plugin := range plugins {
+		if plugin.CanImport {
+			importers = append(importers, plugin)
+		}
+	}
+	return importers
+}
+
+// Exporters 支持导出的插件
+func Exporters(plugins []*Plugin) []*Plugin {
+	var exporters []*Plugin
+	for _, plugin := range plugins {
+		if plugin.CanExport {
+			exporters = append(exporters, plugin)
+		}
+	}
+	return exporters
+}
+
+// Find 按名称查找插件，不存在时返回nil
+func Find(plugins []*Plugin, name string) *Plugin {
+	for _, plugin := range plugins {
+		if plugin.Name == name {
+			return plugin
+		}
+	}
+	return nil
+}
+
+// limitedBuffer 超过上限后丢弃多余内容的写入缓冲，多写入一个字节以便判断是否超限
+type limitedBuffer struct {
+	buf   *bytes.Buffer
+	limit int
+}
+
+// Write 写入缓冲，总是报告全部写入，避免插件因管道错误提前退出
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if remaining := b.limit + 1 - b.buf.Len(); remaining > 0 {
+		if len(p) > remaining {
+			b.buf.Write(p[:remaining])
+		} else {
+			b.buf.Write(p)
+		}
+	}
+	return len(p), nil
+}
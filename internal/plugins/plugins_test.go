@@ -0,0 +1,140 @@
+package plugins
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/flyhigher139/mhost/pkg/hostsfile"
+	"github.com/flyhigher139/mhost/pkg/models"
+)
+
+// pihole 测试用插件：导入时把每行"IP 主机名"转换为条目，导出时原样输出收到的Profile JSON
+const pihole = `#!/bin/sh
+case "$1" in
+describe)
+  echo '{"name": "pihole", "description": "Pi-hole custom DNS", "import": true, "export": true, "extension": "list"}' ;;
+import)
+  printf '{"entries": ['
+  sep=""
+  while read ip host; do
+    printf '%s{"ip": "%s", "hostname": "%s", "enabled": true}' "$sep" "$ip" "$host"
+    sep=","
+  done
+  printf ']}' ;;
+export)
+  cat ;;
+esac
+`
+
+// writePlugin 在目录中写入可执行的插件脚本
+func writePlugin(t *testing.T, dir, name, src string) {
+	t.Helper()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(src), 0755))
+}
+
+// skipWithoutShell 插件测试使用shell脚本
+func skipWithoutShell(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("plugin tests use shell scripts")
+	}
+}
+
+// TestDiscover 测试只发现可执行且能描述自身的插件，并报告无效的插件
+func TestDiscover(t *testing.T) {
+	skipWithoutShell(t)
+	dir := t.TempDir()
+	writePlugin(t, dir, "pihole", pihole)
+	writePlugin(t, dir, "broken", "#!/bin/sh\necho 'not json'\n")
+	writePlugin(t, dir, "builtin", "#!/bin/sh\necho '{\"name\": \"hosts\", \"export\": true}'\n")
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "README.md"), []byte("not executable"), 0644))
+
+	plugins, err := Discover(dir, 0)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "plugin broken: invalid describe output")
+	assert.Contains(t, err.Error(), `plugin builtin: name "hosts" is already in use`)
+	require.Len(t, plugins, 1)
+
+	plugin := plugins[0]
+	assert.Equal(t, "pihole", plugin.Name)
+	assert.Equal(t, "Pi-hole custom DNS", plugin.Description)
+	assert.Equal(t, ".list", plugin.FileExtension())
+	assert.Same(t, plugin, Find(plugins, "pihole"))
+	assert.Len(t, Importers(plugins), 1)
+	assert.Len(t, Exporters(plugins), 1)
+
+	// 目录不存在时没有插件
+	plugins, err = Discover(filepath.Join(dir, "missing"), 0)
+	require.NoError(t, err)
+	assert.Empty(t, plugins)
+}
+
+// TestImportExport 测试通过子进程协议导入和导出
+func TestImportExport(t *testing.T) {
+	skipWithoutShell(t)
+	dir := t.TempDir()
+	writePlugin(t, dir, "pihole", pihole)
+	plugins, err := Discover(dir, 0)
+	require.NoError(t, err)
+	plugin := plugins[0]
+
+	profile, err := plugin.ParseProfile([]byte("10.0.0.1 API.Test\n10.0.0.2 web.test\n"), "pi", hostsfile.Normalizer{})
+	require.NoError(t, err)
+	assert.Equal(t, "pi", profile.Name)
+	require.Len(t, profile.Entries, 2)
+	assert.Equal(t, "api.test", profile.Entries[0].Hostname, "hostnames are normalized")
+
+	_, err = plugin.ParseProfile(nil, "empty", hostsfile.Normalizer{})
+	assert.ErrorContains(t, err, "no host entries found")
+	_, err = plugin.ParseProfile([]byte("300.0.0.1 bad.test\n"), "bad", hostsfile.Normalizer{})
+	assert.Error(t, err, "entries from plugins are validated")
+
+	profile.Entries[1].Enabled = false
+	data, err := plugin.Export(profile)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"name": "pi", "entries": [{"ip": "10.0.0.1", "hostname": "api.test", "enabled": true}]}`, string(data))
+}
+
+// TestRunErrors 测试插件失败和超时
+func TestRunErrors(t *testing.T) {
+	skipWithoutShell(t)
+	dir := t.TempDir()
+	writePlugin(t, dir, "failing", `#!/bin/sh
+if [ "$1" = describe ]; then echo '{"name": "failing", "import": true}'; exit 0; fi
+if [ "$1" = import ]; then echo 'token is missing' >&2; exit 1; fi
+sleep 5
+`)
+	plugins, err := Discover(dir, 200*time.Millisecond)
+	require.NoError(t, err)
+	plugin := plugins[0]
+
+	_, err = plugin.ParseProfile(nil, "x", hostsfile.Normalizer{})
+	assert.ErrorContains(t, err, "plugin failing: import failed: token is missing")
+	_, err = plugin.Export(models.NewProfile("x", ""))
+	assert.ErrorIs(t, err, ErrNotSupported)
+
+	plugin.CanExport = true
+	_, err = plugin.Export(models.NewProfile("x", ""))
+	assert.ErrorContains(t, err, "timed out")
+}
+
+// TestLoadConfig 测试未启用时不运行插件目录中的文件
+func TestLoadConfig(t *testing.T) {
+	skipWithoutShell(t)
+	dataDir := t.TempDir()
+	require.NoError(t, os.MkdirAll(DefaultDir(dataDir), 0755))
+	writePlugin(t, DefaultDir(dataDir), "pihole", pihole)
+
+	plugins, err := LoadConfig(models.PluginsConfig{}, dataDir)
+	require.NoError(t, err)
+	assert.Empty(t, plugins)
+
+	plugins, err = LoadConfig(models.PluginsConfig{Enabled: true}, dataDir)
+	require.NoError(t, err)
+	assert.Len(t, plugins, 1)
+}
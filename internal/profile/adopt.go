@@ -0,0 +1,80 @@
+package profile
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/flyhigher139/mhost/pkg/hostsfile"
+	"github.com/flyhigher139/mhost/pkg/models"
+)
+
+// ErrNoSectionHeader 管理section的头部没有Profile ID，不是由支持接管的mHost版本写入
+var ErrNoSectionHeader = errors.New("managed section has no profile id in its header")
+
+// Adoption 接管管理section的结果
+type Adoption struct {
+	Header hostsfile.SectionHeader
+	// Profile 接管后激活的Profile，叠加应用的section为叠加后的Profile
+	Profile *models.Profile
+	// Created 本地没有头部记录的Profile，以section中的条目新建了Profile
+	Created bool
+	// Activated 激活了Profile；section对应的Profile已经激活时为false
+	Activated bool
+}
+
+// AdoptManagedSection 接管其他mHost安装（或丢失了数据的本机安装）写入的管理section，之后的差异检测和应用以接管的Profile为准。
+// 头部记录的Profile都存在于本地时按原顺序激活它们，否则以section中的条目新建Profile并激活；不修改hosts文件。
+// managed为管理section内部的行（见hostsfile.ExtractManagedSection）
+func AdoptManagedSection(m Manager, managed []string) (*Adoption, error) {
+	header, body := hostsfile.ParseSectionHeader(managed)
+	if header.ProfileID == "" {
+		return nil, ErrNoSectionHeader
+	}
+	adoption := &Adoption{Header: header}
+
+	// 叠加应用的section的Profile ID由各层的ID以"+"连接
+	ids := strings.Split(header.ProfileID, "+")
+	if layers, ok := localProfiles(m, ids); ok {
+		if !IsActiveSet(m, ids...) {
+			if err := m.ActivateProfiles(ids); err != nil {
+				return nil, err
+			}
+			adoption.Activated = true
+		}
+		adoption.Profile, _ = models.LayerProfiles(layers)
+		return adoption, nil
+	}
+
+	name := header.Profile
+	if name == "" {
+		name = "hosts"
+	}
+	p := models.NewProfile(name, "adopted from the managed section of the hosts file")
+	p.Author = header.Author
+	for _, entry := range hostsfile.ParseWithDisabled(body) {
+		p.AddEntry(entry.ToModel())
+	}
+	created, err := m.ImportParsedProfile(p)
+	if err != nil {
+		return nil, err
+	}
+	if err := m.ActivateProfile(created.ID); err != nil {
+		return nil, err
+	}
+	created.IsActive = true
+	adoption.Profile, adoption.Created, adoption.Activated = created, true, true
+	return adoption, nil
+}
+
+// localProfiles 按ids获取本地的Profile，有任何一个不存在时返回false
+func localProfiles(m Manager, ids []string) ([]*models.Profile, bool) {
+	profiles := make([]*models.Profile, 0, len(ids))
+	for _, id := range ids {
+		p, err := m.GetProfile(id)
+		if err != nil {
+			return nil, false
+		}
+		profiles = append(profiles, p)
+	}
+	return profiles, true
+}
@@ -0,0 +1,145 @@
+package profile
+
+import (
+	"maps"
+	"strings"
+
+	"github.com/flyhigher139/mhost/pkg/models"
+)
+
+// DiffKind 条目差异的类型
+type DiffKind string
+
+const (
+	// DiffUnchanged 两边相同的条目
+	DiffUnchanged DiffKind = "unchanged"
+	// DiffAdded 只在新Profile中的条目
+	DiffAdded DiffKind = "added"
+	// DiffRemoved 只在旧Profile中的条目
+	DiffRemoved DiffKind = "removed"
+	// DiffChanged 主机名相同但IP、注释、启用状态或环境变体不同的条目
+	DiffChanged DiffKind = "changed"
+)
+
+// EntryDiff 一个条目的差异，Old为旧Profile中的条目，New为新Profile中的条目；新增的条目Old为nil，删除的条目New为nil
+type EntryDiff struct {
+	Kind DiffKind
+	Old  *models.HostEntry
+	New  *models.HostEntry
+}
+
+// ProfileDiff 两个Profile之间条目的差异
+type ProfileDiff struct {
+	// Entries 按新Profile中的顺序排列，删除的条目排在最后
+	Entries []EntryDiff
+}
+
+// Diff 比较两个Profile的条目，a为旧的一方（例如快照中的版本），b为新的一方。
+// 条目按主机名（不区分大小写）对应，同一主机名有多个条目时先对应IP相同的条目，其余按顺序对应
+func Diff(a, b *models.Profile) *ProfileDiff {
+	var oldEntries, newEntries []*models.HostEntry
+	if a != nil {
+		oldEntries = a.Entries
+	}
+	if b != nil {
+		newEntries = b.Entries
+	}
+
+	// 每个主机名在旧Profile中尚未对应的条目
+	pending := make(map[string][]*models.HostEntry)
+	for _, entry := range oldEntries {
+		key := strings.ToLower(entry.Hostname)
+		pending[key] = append(pending[key], entry)
+	}
+
+	// 先对应IP相同的条目，避免同一主机名的IPv4和IPv6条目交叉对应
+	matched := make(map[*models.HostEntry]*models.HostEntry)
+	for _, entry := range newEntries {
+		key := strings.ToLower(entry.Hostname)
+		for i, old := range pending[key] {
+			if old.IP == entry.IP {
+				matched[entry] = old
+				pending[key] = append(pending[key][:i:i], pending[key][i+1:]...)
+				break
+			}
+		}
+	}
+
+	diff := &ProfileDiff{Entries: make([]EntryDiff, 0, len(newEntries))}
+	for _, entry := range newEntries {
+		old, ok := matched[entry]
+		if !ok {
+			key := strings.ToLower(entry.Hostname)
+			if len(pending[key]) == 0 {
+				diff.Entries = append(diff.Entries, EntryDiff{Kind: DiffAdded, New: entry})
+				continue
+			}
+			old, pending[key] = pending[key][0], pending[key][1:]
+		}
+		kind := DiffUnchanged
+		if !sameEntry(old, entry) {
+			kind = DiffChanged
+		}
+		diff.Entries = append(diff.Entries, EntryDiff{Kind: kind, Old: old, New: entry})
+	}
+
+	for _, entry := range oldEntries {
+		key := strings.ToLower(entry.Hostname)
+		for _, old := range pending[key] {
+			if old == entry {
+				diff.Entries = append(diff.Entries, EntryDiff{Kind: DiffRemoved, Old: entry})
+				break
+			}
+		}
+	}
+	return diff
+}
+
+// sameEntry 两个条目的内容是否相同，不比较ID和时间戳
+func sameEntry(a, b *models.HostEntry) bool {
+	return a.IP == b.IP && a.Hostname == b.Hostname && a.Comment == b.Comment && a.Enabled == b.Enabled &&
+		maps.Equal(a.Variants, b.Variants)
+}
+
+// Added 只在新Profile中的条目
+func (d *ProfileDiff) Added() []*models.HostEntry {
+	var entries []*models.HostEntry
+	for _, entry := range d.Entries {
+		if entry.Kind == DiffAdded {
+			entries = append(entries, entry.New)
+		}
+	}
+	return entries
+}
+
+// Removed 只在旧Profile中的条目
+func (d *ProfileDiff) Removed() []*models.HostEntry {
+	var entries []*models.HostEntry
+	for _, entry := range d.Entries {
+		if entry.Kind == DiffRemoved {
+			entries = append(entries, entry.Old)
+		}
+	}
+	return entries
+}
+
+// Changed 主机名相同但内容不同的条目
+func (d *ProfileDiff) Changed() []EntryDiff {
+	var entries []EntryDiff
+	for _, entry := range d.Entries {
+		if entry.Kind == DiffChanged {
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
+
+// HasChanges 两个Profile的条目是否有差异
+func (d *ProfileDiff) HasChanges() bool {
+	for _, entry := range d.Entries {
+		if entry.Kind != DiffUnchanged {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,171 @@
+package profile
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/flyhigher139/mhost/pkg/models"
+)
+
+// ListGroups 获取所有Profile文件夹，包括空文件夹和Profile所在的文件夹及其上级文件夹，按路径排序
+func (m *ManagerImpl) ListGroups() ([]*models.ProfileGroup, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return CollectGroups(m.groups, m.profiles), nil
+}
+
+// CreateGroup 创建空的Profile文件夹
+func (m *ManagerImpl) CreateGroup(path string) (*models.ProfileGroup, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	group, err := AddGroup(m.groups, m.profiles, path)
+	if err != nil {
+		return nil, err
+	}
+	if err := m.saveProfiles(); err != nil {
+		return nil, err
+	}
+	return group, nil
+}
+
+// RenameGroup 重命名或移动Profile文件夹，其中的Profile和下级文件夹一起移动
+func (m *ManagerImpl) RenameGroup(path, newPath string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err := MoveGroup(m.groups, m.profiles, path, newPath); err != nil {
+		return err
+	}
+	return m.saveProfiles()
+}
+
+// DeleteGroup 删除Profile文件夹，其中的Profile和下级文件夹移到上级文件夹
+func (m *ManagerImpl) DeleteGroup(path string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err := RemoveGroup(m.groups, m.profiles, path); err != nil {
+		return err
+	}
+	return m.saveProfiles()
+}
+
+// CollectGroups 合并保存的文件夹与Profile所在的文件夹（包括上级文件夹），按路径排序。
+// 只由Profile隐式产生的文件夹没有创建时间
+func CollectGroups(groups map[string]*models.ProfileGroup, profiles map[string]*models.Profile) []*models.ProfileGroup {
+	all := make(map[string]*models.ProfileGroup, len(groups))
+	for path, group := range groups {
+		g := *group
+		all[path] = &g
+	}
+	for _, p := range profiles {
+		for folder := models.NormalizeFolder(p.Folder); folder != ""; folder = models.ParentFolder(folder) {
+			if _, ok := all[folder]; !ok {
+				all[folder] = &models.ProfileGroup{Path: folder}
+			}
+		}
+	}
+	for path := range groups {
+		for parent := models.ParentFolder(path); parent != ""; parent = models.ParentFolder(parent) {
+			if _, ok := all[parent]; !ok {
+				all[parent] = &models.ProfileGroup{Path: parent}
+			}
+		}
+	}
+
+	result := make([]*models.ProfileGroup, 0, len(all))
+	for _, group := range all {
+		result = append(result, group)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Path < result[j].Path
+	})
+	return result
+}
+
+// groupExists 文件夹是否已保存或有Profile位于其中（包括下级文件夹）
+func groupExists(groups map[string]*models.ProfileGroup, profiles map[string]*models.Profile, path string) bool {
+	for existing := range groups {
+		if models.InFolder(existing, path) {
+			return true
+		}
+	}
+	for _, p := range profiles {
+		if models.InFolder(models.NormalizeFolder(p.Folder), path) {
+			return true
+		}
+	}
+	return false
+}
+
+// AddGroup 在groups中保存新的空文件夹，文件夹已存在时返回ErrGroupExists
+func AddGroup(groups map[string]*models.ProfileGroup, profiles map[string]*models.Profile, path string) (*models.ProfileGroup, error) {
+	group, err := models.NewProfileGroup(path)
+	if err != nil {
+		return nil, err
+	}
+	if groupExists(groups, profiles, group.Path) {
+		return nil, fmt.Errorf("%w: %s", models.ErrGroupExists, group.Path)
+	}
+	groups[group.Path] = group
+	g := *group
+	return &g, nil
+}
+
+// MoveGroup 将文件夹及其中的Profile和下级文件夹移动到newPath，
+// 不能移动到已存在的文件夹或自身的下级文件夹中
+func MoveGroup(groups map[string]*models.ProfileGroup, profiles map[string]*models.Profile, path, newPath string) error {
+	path, newPath = models.NormalizeFolder(path), models.NormalizeFolder(newPath)
+	if path == "" || newPath == "" || models.InFolder(newPath, path) {
+		return models.ErrInvalidGroup
+	}
+	if !groupExists(groups, profiles, path) {
+		return fmt.Errorf("%w: %s", models.ErrGroupNotFound, path)
+	}
+	if groupExists(groups, profiles, newPath) {
+		return fmt.Errorf("%w: %s", models.ErrGroupExists, newPath)
+	}
+	moveContents(groups, profiles, path, newPath)
+	return nil
+}
+
+// RemoveGroup 删除文件夹，其中的Profile和下级文件夹移到上级文件夹，与上级文件夹中的同名文件夹合并
+func RemoveGroup(groups map[string]*models.ProfileGroup, profiles map[string]*models.Profile, path string) error {
+	path = models.NormalizeFolder(path)
+	if path == "" {
+		return models.ErrInvalidGroup
+	}
+	if !groupExists(groups, profiles, path) {
+		return fmt.Errorf("%w: %s", models.ErrGroupNotFound, path)
+	}
+	delete(groups, path)
+	moveContents(groups, profiles, path, models.ParentFolder(path))
+	return nil
+}
+
+// moveContents 将from中的文件夹和Profile移动到to下
+func moveContents(groups map[string]*models.ProfileGroup, profiles map[string]*models.Profile, from, to string) {
+	moved := make(map[string]*models.ProfileGroup)
+	for path, group := range groups {
+		if models.InFolder(path, from) {
+			delete(groups, path)
+			if group.Path = models.MoveFolder(path, from, to); group.Path != "" {
+				moved[group.Path] = group
+			}
+		}
+	}
+	for path, group := range moved {
+		if _, ok := groups[path]; !ok {
+			groups[path] = group
+		}
+	}
+
+	for _, p := range profiles {
+		folder := models.NormalizeFolder(p.Folder)
+		if models.InFolder(folder, from) {
+			p.Folder = models.MoveFolder(folder, from, to)
+			p.UpdateTimestamp()
+		}
+	}
+}
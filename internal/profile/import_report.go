@@ -0,0 +1,136 @@
+package profile
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/flyhigher139/mhost/pkg/models"
+)
+
+// 导入报告的文件名前缀和时间格式，例如 import-2024-01-02-150405.json
+const (
+	importReportPrefix     = "import-"
+	importReportTimeFormat = "2006-01-02-150405"
+)
+
+// DefaultImportReportDir 获取数据目录下保存导入报告的目录
+func DefaultImportReportDir(dataDir string) string {
+	return filepath.Join(dataDir, "import-reports")
+}
+
+// SkippedEntry 导入时因主机名重复而跳过的条目
+type SkippedEntry struct {
+	Hostname string `json:"hostname"`
+	IP       string `json:"ip"`
+}
+
+// ImportedProfile 一个导入的Profile
+type ImportedProfile struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	// RequestedName 导入前的名称，与已有Profile重名时Name为自动添加后缀后的名称
+	RequestedName string `json:"requested_name"`
+	Renamed       bool   `json:"renamed"`
+	// Replaced 替换了同名的已有Profile的条目，而不是新建Profile
+	Replaced bool           `json:"replaced,omitempty"`
+	Entries  int            `json:"entries"`
+	Skipped  []SkippedEntry `json:"skipped_duplicates,omitempty"`
+}
+
+// ImportReport 一次导入的结果，供自动化流程核对实际导入的内容
+type ImportReport struct {
+	Source     string            `json:"source"`
+	ImportedAt time.Time         `json:"imported_at"`
+	Profiles   []ImportedProfile `json:"profiles"`
+	// Error 导入中途失败的原因，Profiles为失败前已导入的Profile
+	Error string `json:"error,omitempty"`
+}
+
+// NewImportReport 创建导入报告，source为导入来源（文件路径或URL）
+func NewImportReport(source string) *ImportReport {
+	return &ImportReport{Source: source, ImportedAt: time.Now(), Profiles: []ImportedProfile{}}
+}
+
+// Import 将p作为新Profile导入并记录结果：每个主机名只保留最后一个启用的条目，与已有Profile重名时自动添加后缀。
+// 不修改p
+func (r *ImportReport) Import(m Manager, p *models.Profile) (*models.Profile, error) {
+	p = p.Clone()
+	skipped := p.RemoveDuplicateHostnames()
+	imported, err := m.ImportParsedProfile(p)
+	if err != nil {
+		r.Error = err.Error()
+		return nil, err
+	}
+	r.Record(p.Name, imported, skipped, false)
+	return imported, nil
+}
+
+// Record 记录一个导入的Profile，requestedName为导入前的名称，skipped为因主机名重复而跳过的条目
+func (r *ImportReport) Record(requestedName string, imported *models.Profile, skipped []*models.HostEntry, replaced bool) {
+	result := ImportedProfile{
+		ID:            imported.ID,
+		Name:          imported.Name,
+		RequestedName: requestedName,
+		Renamed:       imported.Name != requestedName,
+		Replaced:      replaced,
+		Entries:       len(imported.Entries),
+	}
+	for _, entry := range skipped {
+		result.Skipped = append(result.Skipped, SkippedEntry{Hostname: entry.Hostname, IP: entry.IP})
+	}
+	r.Profiles = append(r.Profiles, result)
+}
+
+// Renamed 因重名而自动重命名的Profile数
+func (r *ImportReport) Renamed() int {
+	count := 0
+	for _, p := range r.Profiles {
+		if p.Renamed {
+			count++
+		}
+	}
+	return count
+}
+
+// SkippedDuplicates 因主机名重复而跳过的条目数
+func (r *ImportReport) SkippedDuplicates() int {
+	count := 0
+	for _, p := range r.Profiles {
+		count += len(p.Skipped)
+	}
+	return count
+}
+
+// HasConflicts 导入的内容与请求的不完全一致：有Profile被重命名或有条目被跳过
+func (r *ImportReport) HasConflicts() bool {
+	return r.Renamed() > 0 || r.SkippedDuplicates() > 0
+}
+
+// Write 将报告以JSON写入path，先写入临时文件再替换
+func (r *ImportReport) Write(path string) error {
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal import report: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create import report directory: %w", err)
+	}
+	tempPath := path + ".tmp"
+	if err := os.WriteFile(tempPath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write import report: %w", err)
+	}
+	if err := os.Rename(tempPath, path); err != nil {
+		os.Remove(tempPath)
+		return fmt.Errorf("failed to write import report: %w", err)
+	}
+	return nil
+}
+
+// WriteToDir 将报告写入dir中以导入时间命名的文件，返回文件路径
+func (r *ImportReport) WriteToDir(dir string) (string, error) {
+	path := filepath.Join(dir, importReportPrefix+r.ImportedAt.Format(importReportTimeFormat)+".json")
+	return path, r.Write(path)
+}
@@ -5,11 +5,14 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 	"sort"
 	"strings"
 	"sync"
 	"time"
 
+	"github.com/flyhigher139/mhost/internal/exporter"
+	"github.com/flyhigher139/mhost/pkg/hostsfile"
 	"github.com/flyhigher139/mhost/pkg/models"
 )
 
@@ -24,38 +27,105 @@ type Manager interface {
 	// 根据ID获取Profile
 	GetProfile(id string) (*models.Profile, error)
 
-	// 更新Profile
+	// 更新Profile，修改资产清单或只读Profile的条目时返回models.ErrReadOnlyProfile
 	UpdateProfile(profile *models.Profile) error
 
-	// 删除Profile
+	// 删除Profile，只读Profile返回models.ErrReadOnlyProfile
 	DeleteProfile(id string) error
 
-	// 激活Profile
+	// 保存资产清单同步的结果，可以设置资产清单和修改只读Profile的条目，只供inventory.Syncer使用
+	SyncInventoryProfile(profile *models.Profile) error
+
+	// 删除同步资产清单的只读Profile，只供inventory.Syncer使用
+	DeleteInventoryProfile(id string) error
+
+	// 激活Profile，取代当前激活的全部Profile
 	ActivateProfile(id string) error
 
-	// 获取当前激活的Profile
+	// 同时激活多个Profile，按叠加应用的优先级从低到高排列
+	ActivateProfiles(ids []string) error
+
+	// 获取当前激活的Profile，同时激活多个Profile时返回优先级最低的基础Profile
 	GetActiveProfile() (*models.Profile, error)
 
+	// 获取当前激活的全部Profile，按优先级从低到高排列
+	GetActiveProfiles() ([]*models.Profile, error)
+
 	// 导入Profile
 	ImportProfile(filePath string) (*models.Profile, error)
 
+	// 导入已解析的Profile（例如从URL下载），重新生成ID并处理名称冲突
+	ImportParsedProfile(profile *models.Profile) (*models.Profile, error)
+
+	// 将hosts格式的文件导入为新的Profile，被注释的条目以禁用状态保留
+	ImportFromHostsFile(filePath, name string) (*models.Profile, error)
+
 	// 导出Profile
 	ExportProfile(id, filePath string) error
 
+	// 以hosts格式导出Profile中启用的条目，不包含mHost的标记，便于分享给不使用mHost的人
+	ExportProfileAsHosts(id, filePath string) error
+
 	// 复制Profile
 	CloneProfile(id, newName string) (*models.Profile, error)
 
-	// 搜索Profile
+	// 搜索Profile，按名称、描述和标签匹配，"tag:prod"形式的词按标签筛选
 	SearchProfiles(query string) ([]*models.ProfileSummary, error)
+
+	// 合并条目到Profile，按主机名更新已有条目或追加新条目，只读Profile返回models.ErrReadOnlyProfile
+	MergeEntries(id string, entries []*models.HostEntry) (int, error)
+
+	// 从磁盘重新加载Profile数据（例如被其他进程修改后）
+	Reload() error
+
+	// 规范化所有Profile中已有条目的主机名，返回修改的条目数
+	NormalizeHostnames() (int, error)
+
+	// 获取全局条目所在的隐式Profile，尚未添加全局条目时返回空的Profile
+	GetGlobalProfile() (*models.Profile, error)
+
+	// 保存全局条目
+	UpdateGlobalProfile(profile *models.Profile) error
+
+	// 获取所有Profile文件夹（包括空文件夹），按路径排序
+	ListGroups() ([]*models.ProfileGroup, error)
+
+	// 创建空的Profile文件夹，path为以"/"分隔的层级路径
+	CreateGroup(path string) (*models.ProfileGroup, error)
+
+	// 重命名或移动Profile文件夹，其中的Profile和下级文件夹一起移动
+	RenameGroup(path, newPath string) error
+
+	// 删除Profile文件夹，其中的Profile和下级文件夹移到上级文件夹
+	DeleteGroup(path string) error
+
+	// 获取所有Profile使用的标签及其Profile数量
+	ListTags() ([]*models.TagSummary, error)
+
+	// 为Profile添加标签
+	AddTag(id, tag string) error
+
+	// 移除Profile的标签
+	RemoveTag(id, tag string) error
+
+	// 在所有Profile中重命名标签，返回修改的Profile数量
+	RenameTag(tag, newTag string) (int, error)
+
+	// 从所有Profile中移除标签，返回修改的Profile数量
+	DeleteTag(tag string) (int, error)
 }
 
 // ManagerImpl Profile管理器实现
 type ManagerImpl struct {
 	mu          sync.RWMutex
 	profiles    map[string]*models.Profile
-	activeID    string
+	global      *models.Profile
+	groups      map[string]*models.ProfileGroup
 	dataDir     string
 	profileFile string
+
+	// activeIDs 激活的Profile，按叠加应用的优先级从低到高排列
+	activeIDs []string
 }
 
 // NewManager 创建新的Profile管理器
@@ -66,6 +136,7 @@ func NewManager(dataDir string) (*ManagerImpl, error) {
 
 	manager := &ManagerImpl{
 		profiles:    make(map[string]*models.Profile),
+		groups:      make(map[string]*models.ProfileGroup),
 		dataDir:     dataDir,
 		profileFile: filepath.Join(dataDir, "profiles.json"),
 	}
@@ -96,14 +167,15 @@ func (m *ManagerImpl) CreateProfile(name, description string) (*models.Profile,
 	// 如果这是第一个Profile，自动激活
 	if len(m.profiles) == 1 {
 		profile.IsActive = true
-		m.activeID = profile.ID
+		m.activeIDs = []string{profile.ID}
 	}
 
 	if err := m.saveProfiles(); err != nil {
 		return nil, fmt.Errorf("failed to save profile: %w", err)
 	}
 
-	return profile, nil
+	// 返回副本，调用方修改其持有的Profile不会绕过UpdateProfile的只读检查
+	return profile.Clone(), nil
 }
 
 // ListProfiles 获取Profile列表
@@ -138,12 +210,23 @@ func (m *ManagerImpl) GetProfile(id string) (*models.Profile, error) {
 	return profile.Clone(), nil
 }
 
-// UpdateProfile 更新Profile
+// UpdateProfile 更新Profile，只读Profile只能修改名称、描述等信息，条目和资产清单由资产清单同步维护
 func (m *ManagerImpl) UpdateProfile(profile *models.Profile) error {
+	return m.updateProfile(profile, false)
+}
+
+// SyncInventoryProfile 保存资产清单同步的结果，可以设置资产清单和修改只读Profile的条目
+func (m *ManagerImpl) SyncInventoryProfile(profile *models.Profile) error {
+	return m.updateProfile(profile, true)
+}
+
+// updateProfile 更新Profile，syncing为false时拒绝修改资产清单和只读Profile的条目
+func (m *ManagerImpl) updateProfile(profile *models.Profile, syncing bool) error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
-	if _, exists := m.profiles[profile.ID]; !exists {
+	existing, exists := m.profiles[profile.ID]
+	if !exists {
 		return models.ErrProfileNotFound
 	}
 
@@ -151,6 +234,11 @@ func (m *ManagerImpl) UpdateProfile(profile *models.Profile) error {
 	if err := profile.Validate(); err != nil {
 		return err
 	}
+	// 资产清单决定Profile是否只读，只能通过同步设置或修改
+	if !syncing && (!models.EqualInventory(existing.Inventory, profile.Inventory) ||
+		existing.ReadOnly() && !models.EqualEntries(existing.Entries, profile.Entries)) {
+		return models.ErrReadOnlyProfile
+	}
 
 	// 检查名称冲突（排除自己）
 	for id, existingProfile := range m.profiles {
@@ -159,14 +247,27 @@ func (m *ManagerImpl) UpdateProfile(profile *models.Profile) error {
 		}
 	}
 
+	// 激活状态由ActivateProfiles维护，不使用调用方可能过期的副本中的值
+	profile.IsActive = slices.Contains(m.activeIDs, profile.ID)
 	profile.UpdateTimestamp()
-	m.profiles[profile.ID] = profile
+	// 保存副本，调用方之后修改其持有的Profile不会绕过只读检查
+	m.profiles[profile.ID] = profile.Clone()
 
 	return m.saveProfiles()
 }
 
-// DeleteProfile 删除Profile
+// DeleteProfile 删除Profile，同步资产清单的只读Profile需要通过资产清单同步删除
 func (m *ManagerImpl) DeleteProfile(id string) error {
+	return m.deleteProfile(id, false)
+}
+
+// DeleteInventoryProfile 删除同步资产清单的只读Profile
+func (m *ManagerImpl) DeleteInventoryProfile(id string) error {
+	return m.deleteProfile(id, true)
+}
+
+// deleteProfile 删除Profile，syncing为false时拒绝删除只读Profile
+func (m *ManagerImpl) deleteProfile(id string, syncing bool) error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
@@ -174,6 +275,9 @@ func (m *ManagerImpl) DeleteProfile(id string) error {
 	if !exists {
 		return models.ErrProfileNotFound
 	}
+	if !syncing && profile.ReadOnly() {
+		return models.ErrReadOnlyProfile
+	}
 
 	// 不能删除激活的Profile
 	if profile.IsActive {
@@ -184,40 +288,56 @@ func (m *ManagerImpl) DeleteProfile(id string) error {
 	return m.saveProfiles()
 }
 
-// ActivateProfile 激活Profile
+// ActivateProfile 激活Profile，取代当前激活的全部Profile
 func (m *ManagerImpl) ActivateProfile(id string) error {
+	return m.ActivateProfiles([]string{id})
+}
+
+// ActivateProfiles 同时激活多个Profile，按叠加应用的优先级从低到高排列；
+// ids不能为空，也不能包含重复的Profile
+func (m *ManagerImpl) ActivateProfiles(ids []string) error {
+	if len(ids) == 0 {
+		return models.ErrInvalidProfile
+	}
+
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
-	profile, exists := m.profiles[id]
-	if !exists {
-		return models.ErrProfileNotFound
+	seen := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		if _, exists := m.profiles[id]; !exists {
+			return models.ErrProfileNotFound
+		}
+		if seen[id] {
+			return fmt.Errorf("%w: profile %s activated twice", models.ErrInvalidProfile, id)
+		}
+		seen[id] = true
 	}
 
-	// 取消当前激活的Profile
-	if m.activeID != "" {
-		if currentActive, exists := m.profiles[m.activeID]; exists {
+	// 取消当前激活的Profile，再激活新的Profile
+	for _, id := range m.activeIDs {
+		if currentActive, exists := m.profiles[id]; exists {
 			currentActive.IsActive = false
 		}
 	}
-
-	// 激活新的Profile
-	profile.IsActive = true
-	m.activeID = id
+	for _, id := range ids {
+		m.profiles[id].IsActive = true
+	}
+	m.activeIDs = append([]string(nil), ids...)
 
 	return m.saveProfiles()
 }
 
-// GetActiveProfile 获取当前激活的Profile
+// GetActiveProfile 获取当前激活的Profile，同时激活多个Profile时返回优先级最低的基础Profile
 func (m *ManagerImpl) GetActiveProfile() (*models.Profile, error) {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 
-	if m.activeID == "" {
+	if len(m.activeIDs) == 0 {
 		return nil, models.ErrProfileNotFound
 	}
 
-	profile, exists := m.profiles[m.activeID]
+	profile, exists := m.profiles[m.activeIDs[0]]
 	if !exists {
 		return nil, models.ErrProfileNotFound
 	}
@@ -225,6 +345,24 @@ func (m *ManagerImpl) GetActiveProfile() (*models.Profile, error) {
 	return profile.Clone(), nil
 }
 
+// GetActiveProfiles 获取当前激活的全部Profile的副本，按优先级从低到高排列；
+// 已被删除的Profile被忽略，没有激活的Profile时返回models.ErrProfileNotFound
+func (m *ManagerImpl) GetActiveProfiles() ([]*models.Profile, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	profiles := make([]*models.Profile, 0, len(m.activeIDs))
+	for _, id := range m.activeIDs {
+		if profile, exists := m.profiles[id]; exists {
+			profiles = append(profiles, profile.Clone())
+		}
+	}
+	if len(profiles) == 0 {
+		return nil, models.ErrProfileNotFound
+	}
+	return profiles, nil
+}
+
 // ImportProfile 导入Profile
 func (m *ManagerImpl) ImportProfile(filePath string) (*models.Profile, error) {
 	data, err := os.ReadFile(filePath)
@@ -237,6 +375,16 @@ func (m *ManagerImpl) ImportProfile(filePath string) (*models.Profile, error) {
 		return nil, fmt.Errorf("failed to parse profile: %w", err)
 	}
 
+	return m.ImportParsedProfile(&profile)
+}
+
+// ImportParsedProfile 导入已解析的Profile，重新生成ID并处理名称冲突
+func (m *ManagerImpl) ImportParsedProfile(imported *models.Profile) (*models.Profile, error) {
+	if imported == nil {
+		return nil, models.ErrInvalidProfile
+	}
+	profile := *imported
+
 	// 验证Profile数据
 	if err := profile.Validate(); err != nil {
 		return nil, err
@@ -252,24 +400,17 @@ func (m *ManagerImpl) ImportProfile(filePath string) (*models.Profile, error) {
 	profile.CreatedAt = now
 	profile.UpdatedAt = now
 	profile.IsActive = false
+	hostsfile.NormalizeEntries(profile.Entries)
+	if err := validateImportedEntries(profile.Entries); err != nil {
+		return nil, err
+	}
 
 	// 检查名称冲突，如果存在则添加后缀
-	originalName := profile.Name
-	counter := 1
-	for {
-		nameExists := false
-		for _, existingProfile := range m.profiles {
-			if existingProfile.Name == profile.Name {
-				nameExists = true
-				break
-			}
-		}
-		if !nameExists {
-			break
-		}
-		profile.Name = fmt.Sprintf("%s (%d)", originalName, counter)
-		counter++
+	existing := make([]string, 0, len(m.profiles))
+	for _, existingProfile := range m.profiles {
+		existing = append(existing, existingProfile.Name)
 	}
+	profile.Name = UniqueName(profile.Name, existing)
 
 	m.profiles[profile.ID] = &profile
 
@@ -280,6 +421,67 @@ func (m *ManagerImpl) ImportProfile(filePath string) (*models.Profile, error) {
 	return &profile, nil
 }
 
+// ImportFromHostsFile 将hosts格式的文件（例如另一台机器的/etc/hosts）导入为新的Profile。
+// 被注释的条目以禁用状态保留，重复的主机名只保留最后一个启用的条目；name为空时使用文件名
+func (m *ManagerImpl) ImportFromHostsFile(filePath, name string) (*models.Profile, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+
+	profile, err := ProfileFromHosts(data, filePath, name)
+	if err != nil {
+		return nil, err
+	}
+	profile.RemoveDuplicateHostnames()
+	return m.ImportParsedProfile(profile)
+}
+
+// ProfileFromHosts 将hosts格式的内容解析为Profile，被注释的条目以禁用状态保留；name为空时使用文件名
+func ProfileFromHosts(data []byte, filePath, name string) (*models.Profile, error) {
+	if name = strings.TrimSpace(name); name == "" {
+		name = strings.TrimSuffix(filepath.Base(filePath), filepath.Ext(filePath))
+	}
+
+	profile := models.NewProfile(name, fmt.Sprintf("imported from %s", filePath))
+	for _, entry := range hostsfile.ParseWithDisabled(hostsfile.SplitLines(string(data))) {
+		profile.AddEntry(entry.ToModel())
+	}
+	if len(profile.Entries) == 0 {
+		return nil, fmt.Errorf("%s contains no host entries", filePath)
+	}
+	return profile, nil
+}
+
+// UniqueName 返回不与existing中任何名称重复的名称，冲突时依次添加" (1)"、" (2)"等后缀
+func UniqueName(name string, existing []string) string {
+	taken := make(map[string]bool, len(existing))
+	for _, n := range existing {
+		taken[n] = true
+	}
+	unique := name
+	for counter := 1; taken[unique]; counter++ {
+		unique = fmt.Sprintf("%s (%d)", name, counter)
+	}
+	return unique
+}
+
+// validateImportedEntries 检查导入条目的IP、主机名和注释格式；导入的内容来自外部文件，
+// 主机名或注释中的换行会在写入hosts文件时注入额外的行
+func validateImportedEntries(entries []*models.HostEntry) error {
+	for i, entry := range entries {
+		if err := hostsfile.ValidateEntry(hostsfile.FromModel(entry)); err != nil {
+			return fmt.Errorf("invalid entry %d: %w", i+1, err)
+		}
+		for environment, ip := range entry.Variants {
+			if err := hostsfile.ValidateIP(ip); err != nil {
+				return fmt.Errorf("invalid entry %d in environment %s: %w", i+1, environment, err)
+			}
+		}
+	}
+	return nil
+}
+
 // ExportProfile 导出Profile
 func (m *ManagerImpl) ExportProfile(id, filePath string) error {
 	m.mu.RLock()
@@ -302,6 +504,31 @@ func (m *ManagerImpl) ExportProfile(id, filePath string) error {
 	return nil
 }
 
+// ExportProfileAsHosts 以规范的"IP\thostname\t# comment"格式导出启用的条目，IP按Profile当前环境解析
+func (m *ManagerImpl) ExportProfileAsHosts(id, filePath string) error {
+	m.mu.RLock()
+	profile, exists := m.profiles[id]
+	var data []byte
+	var err error
+	if exists {
+		data, err = (&exporter.HostsExporter{}).Export(profile)
+	}
+	m.mu.RUnlock()
+
+	if !exists {
+		return models.ErrProfileNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("failed to render profile: %w", err)
+	}
+
+	if err := os.WriteFile(filePath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+
+	return nil
+}
+
 // CloneProfile 复制Profile
 func (m *ManagerImpl) CloneProfile(id, newName string) (*models.Profile, error) {
 	m.mu.Lock()
@@ -336,16 +563,98 @@ func (m *ManagerImpl) CloneProfile(id, newName string) (*models.Profile, error)
 	return cloned, nil
 }
 
-// SearchProfiles 搜索Profile
+// MergeEntries 合并条目到Profile，按主机名更新已有条目或追加新条目，返回变更的条目数
+func (m *ManagerImpl) MergeEntries(id string, entries []*models.HostEntry) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	profile, exists := m.profiles[id]
+	if !exists {
+		return 0, models.ErrProfileNotFound
+	}
+	if profile.ReadOnly() {
+		return 0, models.ErrReadOnlyProfile
+	}
+
+	// 先验证全部条目，避免部分合并
+	for _, entry := range entries {
+		if entry == nil {
+			continue
+		}
+		if err := entry.Validate(); err != nil {
+			return 0, err
+		}
+	}
+
+	merged := 0
+	for _, entry := range entries {
+		if entry == nil {
+			continue
+		}
+
+		var existing *models.HostEntry
+		for _, current := range profile.Entries {
+			if hostsfile.NormalizeHostname(current.Hostname) == hostsfile.NormalizeHostname(entry.Hostname) {
+				existing = current
+				break
+			}
+		}
+
+		if existing == nil {
+			profile.Entries = append(profile.Entries, models.NewHostEntry(entry.IP, hostsfile.NormalizeHostname(entry.Hostname), entry.Comment))
+			merged++
+			continue
+		}
+
+		if existing.IP != entry.IP || existing.Comment != entry.Comment || !existing.Enabled {
+			existing.IP = entry.IP
+			existing.Comment = entry.Comment
+			existing.Enabled = true
+			existing.UpdatedAt = time.Now()
+			merged++
+		}
+	}
+
+	if merged == 0 {
+		return 0, nil
+	}
+
+	profile.UpdateTimestamp()
+	return merged, m.saveProfiles()
+}
+
+// NormalizeHostnames 规范化所有Profile中已有条目的主机名（一次性迁移），返回修改的条目数
+func (m *ManagerImpl) NormalizeHostnames() (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	changed := 0
+	for _, profile := range m.profiles {
+		if n := hostsfile.NormalizeEntries(profile.Entries); n > 0 {
+			profile.UpdateTimestamp()
+			changed += n
+		}
+	}
+
+	if changed == 0 {
+		return 0, nil
+	}
+	return changed, m.saveProfiles()
+}
+
+// SearchProfiles 搜索Profile，"tag:"开头的词按标签筛选，其余文本匹配名称、描述和标签
 func (m *ManagerImpl) SearchProfiles(query string) ([]*models.ProfileSummary, error) {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 
+	q := models.ParseProfileQuery(query)
+	if q.Empty() {
+		return nil, nil
+	}
+
 	var results []*models.ProfileSummary
 	for _, profile := range m.profiles {
-		// 简单的字符串匹配搜索
-		if containsIgnoreCase(profile.Name, query) ||
-			containsIgnoreCase(profile.Description, query) {
+		if q.Matches(profile) {
 			summary := profile.ToSummary()
 			results = append(results, &summary)
 		}
@@ -359,6 +668,95 @@ func (m *ManagerImpl) SearchProfiles(query string) ([]*models.ProfileSummary, er
 	return results, nil
 }
 
+// Reload 从磁盘重新加载Profile数据
+func (m *ManagerImpl) Reload() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	// 加载失败时保留原有数据
+	profiles, global, groups, activeIDs := m.profiles, m.global, m.groups, m.activeIDs
+	m.profiles = make(map[string]*models.Profile)
+	m.global = nil
+	m.groups = make(map[string]*models.ProfileGroup)
+	m.activeIDs = nil
+	if err := m.loadProfiles(); err != nil {
+		m.profiles, m.global, m.groups, m.activeIDs = profiles, global, groups, activeIDs
+		return fmt.Errorf("failed to reload profiles: %w", err)
+	}
+	return nil
+}
+
+// GetGlobalProfile 获取全局条目所在的隐式Profile的副本，尚未添加全局条目时返回空的Profile
+func (m *ManagerImpl) GetGlobalProfile() (*models.Profile, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if m.global == nil {
+		return models.NewGlobalProfile(), nil
+	}
+	return m.global.Clone(), nil
+}
+
+// UpdateGlobalProfile 保存全局条目，全局Profile不出现在Profile列表中，也不能被激活
+func (m *ManagerImpl) UpdateGlobalProfile(profile *models.Profile) error {
+	if profile == nil || profile.ID != models.GlobalProfileID {
+		return models.ErrInvalidProfile
+	}
+	if err := profile.Validate(); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	hostsfile.NormalizeEntries(profile.Entries)
+	profile.IsActive = false
+	profile.UpdateTimestamp()
+	m.global = profile.Clone()
+	return m.saveProfiles()
+}
+
+// LayeredActiveProfile 获取当前激活的全部Profile按优先级叠加后的Profile，用于与管理section比较差异；
+// 只激活了一个Profile时返回该Profile
+func LayeredActiveProfile(m Manager) (*models.Profile, error) {
+	active, err := m.GetActiveProfiles()
+	if err != nil {
+		return nil, err
+	}
+	layered, _ := models.LayerProfiles(active)
+	return layered, nil
+}
+
+// IsActiveSet ids是否正是当前按相同顺序激活的全部Profile，用于判断应用前是否需要重新激活
+func IsActiveSet(m Manager, ids ...string) bool {
+	active, err := m.GetActiveProfiles()
+	if err != nil || len(active) != len(ids) {
+		return false
+	}
+	for i, p := range active {
+		if p.ID != ids[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// ProfileFile 获取Profile数据文件路径
+func (m *ManagerImpl) ProfileFile() string {
+	return m.profileFile
+}
+
+// profileFileData Profile数据文件的格式
+type profileFileData struct {
+	Profiles map[string]*models.Profile `json:"profiles"`
+	Global   *models.Profile            `json:"global,omitempty"`
+	// Groups 保存的Profile文件夹，Profile所在的文件夹不需要保存
+	Groups []*models.ProfileGroup `json:"groups,omitempty"`
+	// ActiveID 优先级最低的激活Profile，兼容只支持单个激活Profile的旧版本
+	ActiveID  string   `json:"active_id"`
+	ActiveIDs []string `json:"active_ids,omitempty"`
+}
+
 // loadProfiles 从文件加载Profile数据
 func (m *ManagerImpl) loadProfiles() error {
 	if _, err := os.Stat(m.profileFile); os.IsNotExist(err) {
@@ -370,17 +768,23 @@ func (m *ManagerImpl) loadProfiles() error {
 		return err
 	}
 
-	var profileData struct {
-		Profiles map[string]*models.Profile `json:"profiles"`
-		ActiveID string                     `json:"active_id"`
-	}
-
+	var profileData profileFileData
 	if err := json.Unmarshal(data, &profileData); err != nil {
 		return err
 	}
 
 	m.profiles = profileData.Profiles
-	m.activeID = profileData.ActiveID
+	m.global = profileData.Global
+	for _, group := range profileData.Groups {
+		if path := models.NormalizeFolder(group.Path); path != "" {
+			group.Path = path
+			m.groups[path] = group
+		}
+	}
+	m.activeIDs = profileData.ActiveIDs
+	if len(m.activeIDs) == 0 && profileData.ActiveID != "" {
+		m.activeIDs = []string{profileData.ActiveID}
+	}
 
 	if m.profiles == nil {
 		m.profiles = make(map[string]*models.Profile)
@@ -391,12 +795,19 @@ func (m *ManagerImpl) loadProfiles() error {
 
 // saveProfiles 保存Profile数据到文件
 func (m *ManagerImpl) saveProfiles() error {
-	profileData := struct {
-		Profiles map[string]*models.Profile `json:"profiles"`
-		ActiveID string                     `json:"active_id"`
-	}{
-		Profiles: m.profiles,
-		ActiveID: m.activeID,
+	profileData := profileFileData{
+		Profiles:  m.profiles,
+		Global:    m.global,
+		ActiveIDs: m.activeIDs,
+	}
+	for _, group := range m.groups {
+		profileData.Groups = append(profileData.Groups, group)
+	}
+	sort.Slice(profileData.Groups, func(i, j int) bool {
+		return profileData.Groups[i].Path < profileData.Groups[j].Path
+	})
+	if len(m.activeIDs) > 0 {
+		profileData.ActiveID = m.activeIDs[0]
 	}
 
 	data, err := json.MarshalIndent(profileData, "", "  ")
@@ -406,26 +817,3 @@ func (m *ManagerImpl) saveProfiles() error {
 
 	return os.WriteFile(m.profileFile, data, 0644)
 }
-
-// containsIgnoreCase 不区分大小写的字符串包含检查
-func containsIgnoreCase(s, substr string) bool {
-	return len(s) >= len(substr) &&
-		len(substr) > 0 &&
-		contains(strings.ToLower(s), strings.ToLower(substr))
-}
-
-// contains 检查字符串是否包含子字符串
-func contains(s, substr string) bool {
-	return len(s) >= len(substr) &&
-		(len(substr) == 0 || indexOfSubstring(s, substr) >= 0)
-}
-
-// indexOfSubstring 查找子字符串的索引
-func indexOfSubstring(s, substr string) int {
-	for i := 0; i <= len(s)-len(substr); i++ {
-		if s[i:i+len(substr)] == substr {
-			return i
-		}
-	}
-	return -1
-}
@@ -1,9 +1,11 @@
 package profile
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
@@ -11,6 +13,7 @@ import (
 	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
 
+	"github.com/flyhigher139/mhost/pkg/hostsfile"
 	"github.com/flyhigher139/mhost/pkg/models"
 )
 
@@ -198,6 +201,130 @@ func (suite *ProfileManagerTestSuite) TestActivateProfile() {
 	assert.Equal(suite.T(), models.ErrProfileNotFound, err)
 }
 
+// TestActivateProfiles 测试同时激活多个Profile及其持久化
+func (suite *ProfileManagerTestSuite) TestActivateProfiles() {
+	t := suite.T()
+	base, err := suite.manager.CreateProfile("Base", "")
+	require.NoError(t, err)
+	project, err := suite.manager.CreateProfile("Project-A", "")
+	require.NoError(t, err)
+	adblock, err := suite.manager.CreateProfile("Adblock", "")
+	require.NoError(t, err)
+
+	require.NoError(t, suite.manager.ActivateProfiles([]string{base.ID, project.ID, adblock.ID}))
+	active, err := suite.manager.GetActiveProfiles()
+	require.NoError(t, err)
+	require.Len(t, active, 3)
+	assert.Equal(t, []string{base.ID, project.ID, adblock.ID}, []string{active[0].ID, active[1].ID, active[2].ID})
+	primary, err := suite.manager.GetActiveProfile()
+	require.NoError(t, err)
+	assert.Equal(t, base.ID, primary.ID)
+
+	// 激活状态不受调用方过期副本的影响，激活的Profile不能删除
+	project.IsActive = false
+	require.NoError(t, suite.manager.UpdateProfile(project))
+	stored, err := suite.manager.GetProfile(project.ID)
+	require.NoError(t, err)
+	assert.True(t, stored.IsActive)
+	assert.ErrorIs(t, suite.manager.DeleteProfile(adblock.ID), models.ErrActiveProfile)
+
+	layered, err := LayeredActiveProfile(suite.manager)
+	require.NoError(t, err)
+	assert.Equal(t, "Base + Project-A + Adblock", layered.Name)
+
+	// 重新加载后保持顺序
+	reloaded, err := NewManager(suite.tempDir)
+	require.NoError(t, err)
+	active, err = reloaded.GetActiveProfiles()
+	require.NoError(t, err)
+	assert.Equal(t, []string{base.ID, project.ID, adblock.ID}, []string{active[0].ID, active[1].ID, active[2].ID})
+
+	// 激活单个Profile取代全部激活的Profile
+	require.NoError(t, suite.manager.ActivateProfile(project.ID))
+	active, err = suite.manager.GetActiveProfiles()
+	require.NoError(t, err)
+	require.Len(t, active, 1)
+	stored, err = suite.manager.GetProfile(base.ID)
+	require.NoError(t, err)
+	assert.False(t, stored.IsActive)
+
+	assert.ErrorIs(t, suite.manager.ActivateProfiles(nil), models.ErrInvalidProfile)
+	assert.ErrorIs(t, suite.manager.ActivateProfiles([]string{base.ID, base.ID}), models.ErrInvalidProfile)
+	assert.ErrorIs(t, suite.manager.ActivateProfiles([]string{base.ID, "nonexistent"}), models.ErrProfileNotFound)
+}
+
+// TestProfileGroups 测试文件夹的创建、重命名、删除及其持久化
+func (suite *ProfileManagerTestSuite) TestProfileGroups() {
+	api, err := suite.manager.CreateProfile("API", "")
+	suite.Require().NoError(err)
+	api.Folder = "work/clients"
+	suite.Require().NoError(suite.manager.UpdateProfile(api))
+
+	// Profile所在的文件夹及其上级文件夹隐式存在
+	_, err = suite.manager.CreateGroup(" work / ")
+	assert.ErrorIs(suite.T(), err, models.ErrGroupExists)
+	_, err = suite.manager.CreateGroup(" / ")
+	assert.ErrorIs(suite.T(), err, models.ErrInvalidGroup)
+
+	group, err := suite.manager.CreateGroup("work / staging ")
+	suite.Require().NoError(err)
+	assert.Equal(suite.T(), "work/staging", group.Path)
+	assert.Equal(suite.T(), "staging", group.Name())
+	assert.Equal(suite.T(), "work", group.Parent())
+	_, err = suite.manager.CreateGroup("personal")
+	suite.Require().NoError(err)
+
+	paths := func() []string {
+		groups, err := suite.manager.ListGroups()
+		suite.Require().NoError(err)
+		var paths []string
+		for _, group := range groups {
+			paths = append(paths, group.Path)
+		}
+		return paths
+	}
+	assert.Equal(suite.T(), []string{"personal", "work", "work/clients", "work/staging"}, paths())
+
+	// 重命名时Profile和下级文件夹一起移动
+	assert.ErrorIs(suite.T(), suite.manager.RenameGroup("work", "personal"), models.ErrGroupExists)
+	assert.ErrorIs(suite.T(), suite.manager.RenameGroup("work", "work/sub"), models.ErrInvalidGroup)
+	assert.ErrorIs(suite.T(), suite.manager.RenameGroup("missing", "other"), models.ErrGroupNotFound)
+	suite.Require().NoError(suite.manager.RenameGroup("work", "projects"))
+	assert.Equal(suite.T(), []string{"personal", "projects", "projects/clients", "projects/staging"}, paths())
+	saved, err := suite.manager.GetProfile(api.ID)
+	suite.Require().NoError(err)
+	assert.Equal(suite.T(), "projects/clients", saved.Folder)
+
+	// 删除时内容移到上级文件夹
+	suite.Require().NoError(suite.manager.DeleteGroup("projects"))
+	assert.Equal(suite.T(), []string{"clients", "personal", "staging"}, paths())
+	saved, err = suite.manager.GetProfile(api.ID)
+	suite.Require().NoError(err)
+	assert.Equal(suite.T(), "clients", saved.Folder)
+	assert.ErrorIs(suite.T(), suite.manager.DeleteGroup("projects"), models.ErrGroupNotFound)
+
+	// 空文件夹保存到数据文件
+	reloaded, err := NewManager(suite.tempDir)
+	suite.Require().NoError(err)
+	groups, err := reloaded.ListGroups()
+	suite.Require().NoError(err)
+	assert.Len(suite.T(), groups, 3)
+	assert.False(suite.T(), groups[1].CreatedAt.IsZero())
+}
+
+// TestLoadLegacyActiveProfile 测试读取只记录单个激活Profile的旧版数据文件
+func (suite *ProfileManagerTestSuite) TestLoadLegacyActiveProfile() {
+	data := `{"profiles": {"p1": {"id": "p1", "name": "Legacy", "entries": [], "is_active": true}}, "active_id": "p1"}`
+	require.NoError(suite.T(), os.WriteFile(suite.manager.ProfileFile(), []byte(data), 0644))
+
+	manager, err := NewManager(suite.tempDir)
+	require.NoError(suite.T(), err)
+	active, err := manager.GetActiveProfiles()
+	require.NoError(suite.T(), err)
+	require.Len(suite.T(), active, 1)
+	assert.Equal(suite.T(), "p1", active[0].ID)
+}
+
 // TestGetActiveProfile 测试获取激活的Profile
 func (suite *ProfileManagerTestSuite) TestGetActiveProfile() {
 	// 没有Profile时
@@ -248,6 +375,99 @@ func (suite *ProfileManagerTestSuite) TestCloneProfile() {
 	assert.Equal(suite.T(), models.ErrProfileNotFound, err)
 }
 
+// TestMergeEntries 测试合并条目
+func (suite *ProfileManagerTestSuite) TestMergeEntries() {
+	profile, err := suite.manager.CreateProfile("Merge Profile", "Merge Description")
+	assert.NoError(suite.T(), err)
+
+	profile.AddEntry(models.NewHostEntry("10.0.0.1", "api.test", ""))
+	assert.NoError(suite.T(), suite.manager.UpdateProfile(profile))
+
+	merged, err := suite.manager.MergeEntries(profile.ID, []*models.HostEntry{
+		models.NewHostEntry("10.0.0.9", "api.test", "manual edit"),
+		models.NewHostEntry("10.0.0.2", "new.test", ""),
+	})
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 2, merged)
+
+	updated, err := suite.manager.GetProfile(profile.ID)
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), updated.Entries, 2)
+	assert.Equal(suite.T(), "10.0.0.9", updated.Entries[0].IP)
+	assert.Equal(suite.T(), "manual edit", updated.Entries[0].Comment)
+	assert.Equal(suite.T(), "new.test", updated.Entries[1].Hostname)
+
+	// 无变化时不计数
+	merged, err = suite.manager.MergeEntries(profile.ID, []*models.HostEntry{models.NewHostEntry("10.0.0.2", "new.test", "")})
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 0, merged)
+
+	// 无效条目不会部分合并
+	_, err = suite.manager.MergeEntries(profile.ID, []*models.HostEntry{
+		models.NewHostEntry("10.0.0.3", "other.test", ""),
+		models.NewHostEntry("", "bad.test", ""),
+	})
+	assert.Error(suite.T(), err)
+	assert.Len(suite.T(), updated.Entries, 2)
+
+	_, err = suite.manager.MergeEntries("nonexistent", nil)
+	assert.Equal(suite.T(), models.ErrProfileNotFound, err)
+}
+
+// TestNormalizeHostnames 测试规范化已有条目的主机名
+func (suite *ProfileManagerTestSuite) TestNormalizeHostnames() {
+	profile, err := suite.manager.CreateProfile("Legacy Profile", "")
+	assert.NoError(suite.T(), err)
+	profile.AddEntry(models.NewHostEntry("10.0.0.1", "API.Test.", ""))
+	profile.AddEntry(models.NewHostEntry("10.0.0.2", "web.test", ""))
+	assert.NoError(suite.T(), suite.manager.UpdateProfile(profile))
+
+	// 合并时按规范化后的主机名匹配已有条目
+	merged, err := suite.manager.MergeEntries(profile.ID, []*models.HostEntry{models.NewHostEntry("10.0.0.1", "api.test", "")})
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 0, merged)
+
+	changed, err := suite.manager.NormalizeHostnames()
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 1, changed)
+
+	reloaded, err := NewManager(suite.tempDir)
+	assert.NoError(suite.T(), err)
+	updated, err := reloaded.GetProfile(profile.ID)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "api.test", updated.Entries[0].Hostname)
+
+	changed, err = suite.manager.NormalizeHostnames()
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 0, changed)
+}
+
+// TestReload 测试从磁盘重新加载其他进程写入的Profile
+func (suite *ProfileManagerTestSuite) TestReload() {
+	_, err := suite.manager.CreateProfile("Active Profile", "")
+	assert.NoError(suite.T(), err)
+	profile, err := suite.manager.CreateProfile("Reload Profile", "")
+	assert.NoError(suite.T(), err)
+
+	other, err := NewManager(suite.tempDir)
+	require.NoError(suite.T(), err)
+	assert.NoError(suite.T(), other.DeleteProfile(profile.ID))
+	_, err = other.CreateProfile("Created Elsewhere", "")
+	assert.NoError(suite.T(), err)
+
+	assert.NoError(suite.T(), suite.manager.Reload())
+	_, err = suite.manager.GetProfile(profile.ID)
+	assert.Equal(suite.T(), models.ErrProfileNotFound, err)
+
+	profiles, err := suite.manager.ListProfiles()
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), profiles, 2)
+
+	results, err := suite.manager.SearchProfiles("Elsewhere")
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), results, 1)
+}
+
 // TestSearchProfiles 测试搜索Profile
 func (suite *ProfileManagerTestSuite) TestSearchProfiles() {
 	// 创建测试Profile
@@ -282,6 +502,172 @@ func (suite *ProfileManagerTestSuite) TestSearchProfiles() {
 	assert.Empty(suite.T(), results)
 }
 
+// TestDiff 测试两个Profile之间条目的差异
+func (suite *ProfileManagerTestSuite) TestDiff() {
+	old := models.NewProfile("Old", "")
+	old.AddEntry(models.NewHostEntry("10.0.0.1", "api.test", ""))
+	old.AddEntry(models.NewHostEntry("::1", "api.test", ""))
+	old.AddEntry(models.NewHostEntry("10.0.0.2", "web.test", ""))
+	old.AddEntry(models.NewHostEntry("10.0.0.3", "gone.test", ""))
+
+	updated := old.Clone()
+	updated.Entries[1].Comment = "ipv6"
+	updated.Entries[2].Enabled = false
+	updated.RemoveEntry(old.Entries[3].ID)
+	updated.AddEntry(models.NewHostEntry("10.0.0.4", "new.test", ""))
+	// 同一主机名的IPv4和IPv6条目调换顺序后仍按IP对应
+	updated.Entries[0], updated.Entries[1] = updated.Entries[1], updated.Entries[0]
+	updated.Entries[1].Hostname = "API.test"
+
+	diff := Diff(old, updated)
+	suite.Require().Len(diff.Entries, 5)
+	kinds := make([]DiffKind, 0, len(diff.Entries))
+	for _, entry := range diff.Entries {
+		kinds = append(kinds, entry.Kind)
+	}
+	assert.Equal(suite.T(), []DiffKind{DiffChanged, DiffChanged, DiffChanged, DiffAdded, DiffRemoved}, kinds)
+	assert.Equal(suite.T(), "::1", diff.Entries[0].Old.IP)
+	assert.Equal(suite.T(), "10.0.0.1", diff.Entries[1].Old.IP)
+	assert.Equal(suite.T(), []*models.HostEntry{updated.Entries[3]}, diff.Added())
+	assert.Equal(suite.T(), []*models.HostEntry{old.Entries[3]}, diff.Removed())
+	assert.Len(suite.T(), diff.Changed(), 3)
+	assert.True(suite.T(), diff.HasChanges())
+
+	assert.False(suite.T(), Diff(old, old.Clone()).HasChanges())
+	assert.Len(suite.T(), Diff(nil, old).Added(), 4)
+}
+
+// TestImportReport 测试导入报告记录重命名和跳过的重复条目
+func (suite *ProfileManagerTestSuite) TestImportReport() {
+	_, err := suite.manager.CreateProfile("Dev", "")
+	suite.Require().NoError(err)
+
+	parsed, err := ProfileFromHosts([]byte("10.0.0.1 api.test\n10.0.0.2 web.test\n10.0.0.3 api.test\n"), "/tmp/Dev.hosts", "")
+	suite.Require().NoError(err)
+
+	report := NewImportReport("/tmp/Dev.hosts")
+	imported, err := report.Import(suite.manager, parsed)
+	suite.Require().NoError(err)
+	assert.Equal(suite.T(), "Dev (1)", imported.Name)
+	suite.Require().Len(imported.Entries, 2)
+	assert.Equal(suite.T(), "10.0.0.3", imported.Entries[1].IP)
+	assert.Len(suite.T(), parsed.Entries, 3, "the parsed profile is not modified")
+
+	suite.Require().Len(report.Profiles, 1)
+	assert.Equal(suite.T(), ImportedProfile{
+		ID: imported.ID, Name: "Dev (1)", RequestedName: "Dev", Renamed: true, Entries: 2,
+		Skipped: []SkippedEntry{{Hostname: "api.test", IP: "10.0.0.1"}},
+	}, report.Profiles[0])
+	assert.True(suite.T(), report.HasConflicts())
+
+	path, err := report.WriteToDir(DefaultImportReportDir(suite.tempDir))
+	suite.Require().NoError(err)
+	assert.True(suite.T(), strings.HasPrefix(filepath.Base(path), "import-"))
+	data, err := os.ReadFile(path)
+	suite.Require().NoError(err)
+	var written ImportReport
+	suite.Require().NoError(json.Unmarshal(data, &written))
+	assert.Equal(suite.T(), report.Profiles, written.Profiles)
+
+	// 导入失败时记录原因
+	_, err = report.Import(suite.manager, models.NewProfile("", ""))
+	assert.Error(suite.T(), err)
+	assert.NotEmpty(suite.T(), report.Error)
+}
+
+// TestAdoptManagedSection 测试接管其他mHost安装写入的管理section
+func (suite *ProfileManagerTestSuite) TestAdoptManagedSection() {
+	entries := []hostsfile.Entry{{IP: "10.0.0.1", Hostname: "api.test", Enabled: true}}
+	foreign := hostsfile.SectionHeader{Profile: "Team", ProfileID: "remote-1", Author: "alice", AppliedAt: time.Now(), Version: "1.2.0"}
+
+	// 本地没有对应的Profile时以section中的条目新建
+	adoption, err := AdoptManagedSection(suite.manager, hostsfile.ExtractManagedSection(foreign.Build(entries)))
+	suite.Require().NoError(err)
+	assert.True(suite.T(), adoption.Created)
+	assert.True(suite.T(), adoption.Activated)
+	assert.Equal(suite.T(), "Team", adoption.Profile.Name)
+	assert.Equal(suite.T(), "alice", adoption.Profile.Author)
+	suite.Require().Len(adoption.Profile.Entries, 1)
+	active, err := suite.manager.GetActiveProfile()
+	suite.Require().NoError(err)
+	assert.Equal(suite.T(), adoption.Profile.ID, active.ID)
+
+	// 头部记录的Profile都存在时按原顺序激活
+	base, err := suite.manager.CreateProfile("Base", "")
+	suite.Require().NoError(err)
+	project, err := suite.manager.CreateProfile("Project", "")
+	suite.Require().NoError(err)
+	layered := hostsfile.SectionHeader{Profile: "Base + Project", ProfileID: base.ID + "+" + project.ID, AppliedAt: time.Now()}
+	managed := hostsfile.ExtractManagedSection(layered.Build(entries))
+	adoption, err = AdoptManagedSection(suite.manager, managed)
+	suite.Require().NoError(err)
+	assert.False(suite.T(), adoption.Created)
+	assert.True(suite.T(), adoption.Activated)
+	assert.True(suite.T(), IsActiveSet(suite.manager, base.ID, project.ID))
+
+	adoption, err = AdoptManagedSection(suite.manager, managed)
+	suite.Require().NoError(err)
+	assert.False(suite.T(), adoption.Activated)
+
+	_, err = AdoptManagedSection(suite.manager, []string{"# Updated by helper at: 2025-03-01T08:00:00Z", "10.0.0.1\tapi.test"})
+	assert.ErrorIs(suite.T(), err, ErrNoSectionHeader)
+}
+
+// TestProfileTags 测试标签的添加、移除、重命名、删除以及按标签搜索
+func (suite *ProfileManagerTestSuite) TestProfileTags() {
+	api, err := suite.manager.CreateProfile("API", "")
+	suite.Require().NoError(err)
+	web, err := suite.manager.CreateProfile("Web", "")
+	suite.Require().NoError(err)
+
+	suite.Require().NoError(suite.manager.AddTag(api.ID, " prod "))
+	suite.Require().NoError(suite.manager.AddTag(api.ID, "PROD"))
+	suite.Require().NoError(suite.manager.AddTag(api.ID, "backend"))
+	suite.Require().NoError(suite.manager.AddTag(web.ID, "prod"))
+	assert.ErrorIs(suite.T(), suite.manager.AddTag(web.ID, "a,b"), models.ErrInvalidTag)
+	assert.ErrorIs(suite.T(), suite.manager.AddTag("missing", "prod"), models.ErrProfileNotFound)
+
+	tags, err := suite.manager.ListTags()
+	suite.Require().NoError(err)
+	assert.Equal(suite.T(), []*models.TagSummary{{Name: "backend", Count: 1}, {Name: "prod", Count: 2}}, tags)
+
+	// 按标签搜索，其余文本匹配名称、描述和标签
+	results, err := suite.manager.SearchProfiles("tag:Prod")
+	suite.Require().NoError(err)
+	assert.Len(suite.T(), results, 2)
+	results, err = suite.manager.SearchProfiles("tag:prod tag:backend")
+	suite.Require().NoError(err)
+	suite.Require().Len(results, 1)
+	assert.Equal(suite.T(), []string{"prod", "backend"}, results[0].Tags)
+	results, err = suite.manager.SearchProfiles("tag:prod web")
+	suite.Require().NoError(err)
+	suite.Require().Len(results, 1)
+	assert.Equal(suite.T(), "Web", results[0].Name)
+	results, err = suite.manager.SearchProfiles("backend")
+	suite.Require().NoError(err)
+	assert.Len(suite.T(), results, 1)
+
+	// 重命名为已有标签时合并
+	changed, err := suite.manager.RenameTag("backend", "Prod")
+	suite.Require().NoError(err)
+	assert.Equal(suite.T(), 1, changed)
+	saved, err := suite.manager.GetProfile(api.ID)
+	suite.Require().NoError(err)
+	assert.Equal(suite.T(), []string{"prod"}, saved.Tags)
+	_, err = suite.manager.RenameTag("missing", "other")
+	assert.ErrorIs(suite.T(), err, models.ErrTagNotFound)
+
+	suite.Require().NoError(suite.manager.RemoveTag(web.ID, "PROD"))
+	assert.ErrorIs(suite.T(), suite.manager.RemoveTag(web.ID, "prod"), models.ErrTagNotFound)
+
+	changed, err = suite.manager.DeleteTag("prod")
+	suite.Require().NoError(err)
+	assert.Equal(suite.T(), 1, changed)
+	tags, err = suite.manager.ListTags()
+	suite.Require().NoError(err)
+	assert.Empty(suite.T(), tags)
+}
+
 // TestPersistence 测试数据持久化
 func (suite *ProfileManagerTestSuite) TestPersistence() {
 	// 创建Profile
@@ -304,6 +690,30 @@ func (suite *ProfileManagerTestSuite) TestPersistence() {
 	assert.Equal(suite.T(), profile.ID, active.ID)
 }
 
+// TestGlobalProfile 测试全局条目的保存、重新加载和验证
+func (suite *ProfileManagerTestSuite) TestGlobalProfile() {
+	global, err := suite.manager.GetGlobalProfile()
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), models.GlobalProfileID, global.ID)
+	assert.Empty(suite.T(), global.Entries)
+
+	global.AddEntry(models.NewHostEntry("10.0.0.9", "License.Corp", ""))
+	require.NoError(suite.T(), suite.manager.UpdateGlobalProfile(global))
+	assert.ErrorIs(suite.T(), suite.manager.UpdateGlobalProfile(models.NewProfile("Other", "")), models.ErrInvalidProfile)
+
+	// 全局Profile不出现在Profile列表中
+	summaries, err := suite.manager.ListProfiles()
+	require.NoError(suite.T(), err)
+	assert.Empty(suite.T(), summaries)
+
+	newManager, err := NewManager(suite.tempDir)
+	require.NoError(suite.T(), err)
+	loaded, err := newManager.GetGlobalProfile()
+	require.NoError(suite.T(), err)
+	require.Len(suite.T(), loaded.Entries, 1)
+	assert.Equal(suite.T(), "license.corp", loaded.Entries[0].Hostname)
+}
+
 // TestExportImportProfile 测试导出导入Profile
 func (suite *ProfileManagerTestSuite) TestExportImportProfile() {
 	// 创建Profile
@@ -341,11 +751,187 @@ func (suite *ProfileManagerTestSuite) TestExportImportProfile() {
 	assert.Equal(suite.T(), models.ErrProfileNotFound, err)
 }
 
+// TestExportProfileAsHosts 测试以hosts格式导出启用的条目，不包含mHost标记，且可以重新导入
+func (suite *ProfileManagerTestSuite) TestExportProfileAsHosts() {
+	profile, err := suite.manager.CreateProfile("Share", "")
+	suite.Require().NoError(err)
+	profile.AddEntry(models.NewHostEntry("10.0.0.1", "api.share", "backend"))
+	disabled := models.NewHostEntry("10.0.0.2", "old.share", "")
+	disabled.Enabled = false
+	profile.AddEntry(disabled)
+	suite.Require().NoError(suite.manager.UpdateProfile(profile))
+
+	path := filepath.Join(suite.tempDir, "share.hosts")
+	suite.Require().NoError(suite.manager.ExportProfileAsHosts(profile.ID, path))
+	data, err := os.ReadFile(path)
+	suite.Require().NoError(err)
+	assert.Contains(suite.T(), string(data), "10.0.0.1\tapi.share\t# backend\n")
+	assert.NotContains(suite.T(), string(data), "old.share")
+	assert.False(suite.T(), hostsfile.HasManagedSection(hostsfile.SplitLines(string(data))))
+
+	imported, err := suite.manager.ImportFromHostsFile(path, "")
+	suite.Require().NoError(err)
+	assert.Len(suite.T(), imported.Entries, 1)
+
+	assert.Equal(suite.T(), models.ErrProfileNotFound, suite.manager.ExportProfileAsHosts("nonexistent", path))
+}
+
+// TestImportFromHostsFile 测试将hosts文件导入为Profile，被注释的条目以禁用状态保留
+func (suite *ProfileManagerTestSuite) TestImportFromHostsFile() {
+	path := filepath.Join(suite.tempDir, "staging.hosts")
+	content := "127.0.0.1\tlocalhost\n# 10.0.0.1\tapi.staging\n10.0.0.2\tweb.staging www.staging # frontend\n"
+	suite.Require().NoError(os.WriteFile(path, []byte(content), 0644))
+
+	imported, err := suite.manager.ImportFromHostsFile(path, "")
+	suite.Require().NoError(err)
+	assert.Equal(suite.T(), "staging", imported.Name)
+	suite.Require().Len(imported.Entries, 4)
+	assert.False(suite.T(), imported.Entries[1].Enabled)
+	assert.Equal(suite.T(), "api.staging", imported.Entries[1].Hostname)
+	assert.Equal(suite.T(), "www.staging", imported.Entries[3].Hostname)
+	assert.Equal(suite.T(), "frontend", imported.Entries[3].Comment)
+
+	renamed, err := suite.manager.ImportFromHostsFile(path, "Staging VM")
+	suite.Require().NoError(err)
+	assert.Equal(suite.T(), "Staging VM", renamed.Name)
+
+	empty := filepath.Join(suite.tempDir, "empty.hosts")
+	suite.Require().NoError(os.WriteFile(empty, []byte("# nothing here\n"), 0644))
+	_, err = suite.manager.ImportFromHostsFile(empty, "")
+	assert.Error(suite.T(), err)
+}
+
+// TestUniqueName 测试名称冲突时添加递增后缀
+func (suite *ProfileManagerTestSuite) TestUniqueName() {
+	assert.Equal(suite.T(), "Dev", UniqueName("Dev", []string{"Staging"}))
+	assert.Equal(suite.T(), "Dev (1)", UniqueName("Dev", []string{"Dev"}))
+	assert.Equal(suite.T(), "Dev (2)", UniqueName("Dev", []string{"Dev", "Dev (1)"}))
+}
+
+// TestImportInvalidProfile 测试拒绝空条目和会在hosts文件中注入额外行的内容
+func (suite *ProfileManagerTestSuite) TestImportInvalidProfile() {
+	invalid := []string{
+		`{"name":"Nil","entries":[null]}`,
+		`{"name":"Bad IP","entries":[{"ip":"not-an-ip","hostname":"a.test","enabled":true}]}`,
+		`{"name":"Inject","entries":[{"ip":"10.0.0.1","hostname":"a.test","comment":"x\n10.6.6.6 evil.test","enabled":true}]}`,
+		`{"name":"Variant","entries":[{"ip":"10.0.0.1","hostname":"a.test","enabled":true,"variants":{"prod":"10.0.0.1 evil.test"}}]}`,
+		`{"name":"a\n10.6.6.6 evil.test","entries":[]}`,
+	}
+	for i, data := range invalid {
+		path := filepath.Join(suite.tempDir, fmt.Sprintf("invalid_%d.json", i))
+		require.NoError(suite.T(), os.WriteFile(path, []byte(data), 0644))
+
+		_, err := suite.manager.ImportProfile(path)
+		assert.Error(suite.T(), err, data)
+	}
+
+	profiles, err := suite.manager.ListProfiles()
+	require.NoError(suite.T(), err)
+	assert.Empty(suite.T(), profiles)
+}
+
+// TestReadOnlyProfile 测试同步资产清单的只读Profile只能通过资产清单同步修改条目、资产清单和删除
+func (suite *ProfileManagerTestSuite) TestReadOnlyProfile() {
+	t := suite.T()
+	_, err := suite.manager.CreateProfile("Active", "")
+	require.NoError(t, err)
+	p, err := suite.manager.CreateProfile("CMDB", "")
+	require.NoError(t, err)
+	p.AddEntry(models.NewHostEntry("10.0.0.1", "web.test", ""))
+	p.Inventory = &models.Inventory{URL: "https://cmdb.test/machines"}
+	assert.ErrorIs(t, suite.manager.UpdateProfile(p), models.ErrReadOnlyProfile)
+	require.NoError(t, suite.manager.SyncInventoryProfile(p))
+
+	// 不能先清除或修改资产清单使Profile可写，再修改条目
+	cleared, err := suite.manager.GetProfile(p.ID)
+	require.NoError(t, err)
+	cleared.Inventory = nil
+	assert.ErrorIs(t, suite.manager.UpdateProfile(cleared), models.ErrReadOnlyProfile)
+	cleared.Inventory = &models.Inventory{URL: "https://other.test/machines"}
+	assert.ErrorIs(t, suite.manager.UpdateProfile(cleared), models.ErrReadOnlyProfile)
+
+	// nil条目不会导致只读检查panic，按无效条目拒绝
+	cleared.Inventory = p.Inventory
+	cleared.Entries = append(cleared.Entries, nil)
+	assert.ErrorIs(t, suite.manager.UpdateProfile(cleared), models.ErrInvalidHostEntry)
+
+	// 可以修改名称等信息
+	p, err = suite.manager.GetProfile(p.ID)
+	require.NoError(t, err)
+	p.Name = "Machines"
+	require.NoError(t, suite.manager.UpdateProfile(p))
+
+	p.Entries[0].Enabled = false
+	assert.ErrorIs(t, suite.manager.UpdateProfile(p), models.ErrReadOnlyProfile)
+	_, err = suite.manager.MergeEntries(p.ID, []*models.HostEntry{models.NewHostEntry("10.0.0.2", "db.test", "")})
+	assert.ErrorIs(t, err, models.ErrReadOnlyProfile)
+	assert.ErrorIs(t, suite.manager.DeleteProfile(p.ID), models.ErrReadOnlyProfile)
+
+	stored, err := suite.manager.GetProfile(p.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Machines", stored.Name)
+	require.Len(t, stored.Entries, 1)
+	assert.True(t, stored.Entries[0].Enabled)
+
+	require.NoError(t, suite.manager.SyncInventoryProfile(p))
+	stored, err = suite.manager.GetProfile(p.ID)
+	require.NoError(t, err)
+	assert.False(t, stored.Entries[0].Enabled)
+	require.NoError(t, suite.manager.DeleteInventoryProfile(p.ID))
+	_, err = suite.manager.GetProfile(p.ID)
+	assert.ErrorIs(t, err, models.ErrProfileNotFound)
+}
+
 // 运行测试套件
 func TestProfileManagerSuite(t *testing.T) {
 	suite.Run(t, new(ProfileManagerTestSuite))
 }
 
+// FuzzImportProfile 测试导入任意JSON不会panic，导入成功的Profile渲染后的条目与导入的条目一一对应
+func FuzzImportProfile(f *testing.F) {
+	f.Add([]byte(`{"name":"Dev","entries":[{"ip":"10.0.0.1","hostname":"api.test","comment":"staging","enabled":true}]}`))
+	f.Add([]byte(`{"name":"Env","environment":"prod","entries":[{"ip":"10.0.0.1","hostname":"API.Test.","enabled":true,"variants":{"prod":"10.2.0.1"}}]}`))
+	f.Add([]byte(`{"name":"Nil","entries":[null]}`))
+	f.Add([]byte(`{"name":"Spaces","entries":[{"ip":"10.0.0.1","hostname":"a.test","comment":"  # x ","enabled":true},{"ip":"10.0.0.2","hostname":"b.test","enabled":false}]}`))
+	f.Add([]byte(`{"name":"Inject","entries":[{"ip":"10.0.0.1","hostname":"a.test","comment":"x\n10.6.6.6 evil.test","enabled":true}]}`))
+	f.Add([]byte(`{"name":"a\n10.6.6.6 evil.test","entries":[]}`))
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		dir := t.TempDir()
+		manager, err := NewManager(dir)
+		require.NoError(t, err)
+
+		path := filepath.Join(dir, "import.json")
+		require.NoError(t, os.WriteFile(path, data, 0644))
+
+		imported, err := manager.ImportProfile(path)
+		if err != nil {
+			return
+		}
+		require.NoError(t, imported.Validate())
+
+		var enabled []hostsfile.Entry
+		for _, entry := range hostsfile.FromModels(imported.ResolvedEntries()) {
+			if entry.Enabled {
+				enabled = append(enabled, entry)
+			}
+		}
+		section := hostsfile.BuildManagedSection([]string{"# Profile: " + imported.Name}, hostsfile.FromModels(imported.ResolvedEntries()))
+		parsed := hostsfile.Parse(hostsfile.ExtractManagedSection(section))
+		require.Len(t, parsed, len(enabled))
+		for i, entry := range parsed {
+			assert.Equal(t, enabled[i].IP, entry.IP)
+			assert.Equal(t, enabled[i].Hostname, entry.Hostname)
+		}
+
+		// 导入的Profile可以导出并再次导入
+		exported := filepath.Join(dir, "export.json")
+		require.NoError(t, manager.ExportProfile(imported.ID, exported))
+		_, err = manager.ImportProfile(exported)
+		require.NoError(t, err)
+	})
+}
+
 // 基准测试
 func BenchmarkCreateProfile(b *testing.B) {
 	tempDir, _ := os.MkdirTemp("", "mhost_bench_*")
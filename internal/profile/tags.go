@@ -0,0 +1,181 @@
+package profile
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/flyhigher139/mhost/pkg/models"
+)
+
+// ListTags 获取所有Profile使用的标签及其Profile数量，按名称排序
+func (m *ManagerImpl) ListTags() ([]*models.TagSummary, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return CollectTags(m.profiles), nil
+}
+
+// AddTag 为Profile添加标签，已有相同标签（不区分大小写）时不做修改
+func (m *ManagerImpl) AddTag(id, tag string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	p, ok := m.profiles[id]
+	if !ok {
+		return models.ErrProfileNotFound
+	}
+	changed, err := TagProfile(p, tag)
+	if err != nil || !changed {
+		return err
+	}
+	return m.saveProfiles()
+}
+
+// RemoveTag 移除Profile的标签
+func (m *ManagerImpl) RemoveTag(id, tag string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	p, ok := m.profiles[id]
+	if !ok {
+		return models.ErrProfileNotFound
+	}
+	if err := UntagProfile(p, tag); err != nil {
+		return err
+	}
+	return m.saveProfiles()
+}
+
+// RenameTag 在所有Profile中重命名标签，返回修改的Profile数量
+func (m *ManagerImpl) RenameTag(tag, newTag string) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	changed, err := RenameTags(m.profiles, tag, newTag)
+	if err != nil {
+		return 0, err
+	}
+	return changed, m.saveProfiles()
+}
+
+// DeleteTag 从所有Profile中移除标签，返回修改的Profile数量
+func (m *ManagerImpl) DeleteTag(tag string) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	changed, err := DeleteTags(m.profiles, tag)
+	if err != nil {
+		return 0, err
+	}
+	return changed, m.saveProfiles()
+}
+
+// CollectTags 统计Profile使用的标签，大小写不同的标签视为同一标签，名称取第一次出现的写法
+func CollectTags(profiles map[string]*models.Profile) []*models.TagSummary {
+	ids := make([]string, 0, len(profiles))
+	for id := range profiles {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	byKey := make(map[string]*models.TagSummary)
+	for _, id := range ids {
+		seen := make(map[string]bool)
+		for _, tag := range profiles[id].Tags {
+			tag = strings.TrimSpace(tag)
+			key := strings.ToLower(tag)
+			if tag == "" || seen[key] {
+				continue
+			}
+			seen[key] = true
+			if summary, ok := byKey[key]; ok {
+				summary.Count++
+			} else {
+				byKey[key] = &models.TagSummary{Name: tag, Count: 1}
+			}
+		}
+	}
+
+	tags := make([]*models.TagSummary, 0, len(byKey))
+	for _, summary := range byKey {
+		tags = append(tags, summary)
+	}
+	sort.Slice(tags, func(i, j int) bool {
+		return strings.ToLower(tags[i].Name) < strings.ToLower(tags[j].Name)
+	})
+	return tags
+}
+
+// TagProfile 为Profile添加标签，返回是否有修改
+func TagProfile(p *models.Profile, tag string) (bool, error) {
+	tag, err := models.ValidateTag(tag)
+	if err != nil {
+		return false, err
+	}
+	if p.HasTag(tag) {
+		return false, nil
+	}
+	p.Tags = append(p.Tags, tag)
+	p.UpdateTimestamp()
+	return true, nil
+}
+
+// UntagProfile 移除Profile的标签（不区分大小写），Profile没有该标签时返回ErrTagNotFound
+func UntagProfile(p *models.Profile, tag string) error {
+	tag = strings.TrimSpace(tag)
+	if !p.HasTag(tag) {
+		return fmt.Errorf("%w: %s", models.ErrTagNotFound, tag)
+	}
+	p.Tags = slices.DeleteFunc(p.Tags, func(t string) bool {
+		return strings.EqualFold(strings.TrimSpace(t), tag)
+	})
+	p.UpdateTimestamp()
+	return nil
+}
+
+// RenameTags 在所有Profile中重命名标签，已有新标签的Profile合并为一个标签；返回修改的Profile数量
+func RenameTags(profiles map[string]*models.Profile, tag, newTag string) (int, error) {
+	newTag, err := models.ValidateTag(newTag)
+	if err != nil {
+		return 0, err
+	}
+	tag = strings.TrimSpace(tag)
+
+	changed := 0
+	for _, p := range profiles {
+		if !p.HasTag(tag) {
+			continue
+		}
+		renamed := make([]string, 0, len(p.Tags))
+		for _, t := range p.Tags {
+			if strings.EqualFold(strings.TrimSpace(t), tag) {
+				t = newTag
+			}
+			if !slices.ContainsFunc(renamed, func(r string) bool { return strings.EqualFold(r, t) }) {
+				renamed = append(renamed, t)
+			}
+		}
+		p.Tags = renamed
+		p.UpdateTimestamp()
+		changed++
+	}
+	if changed == 0 {
+		return 0, fmt.Errorf("%w: %s", models.ErrTagNotFound, tag)
+	}
+	return changed, nil
+}
+
+// DeleteTags 从所有Profile中移除标签，返回修改的Profile数量，没有Profile使用该标签时返回ErrTagNotFound
+func DeleteTags(profiles map[string]*models.Profile, tag string) (int, error) {
+	changed := 0
+	for _, p := range profiles {
+		if UntagProfile(p, tag) == nil {
+			changed++
+		}
+	}
+	if changed == 0 {
+		return 0, fmt.Errorf("%w: %s", models.ErrTagNotFound, strings.TrimSpace(tag))
+	}
+	return changed, nil
+}
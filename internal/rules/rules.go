@@ -0,0 +1,292 @@
+// Package rules 自定义校验规则：团队可以在YAML或JSON文件中定义内置检查无法表达的策略，
+// 例如主机名格式、注释格式和禁止使用的IP范围，保存条目和应用Profile时检查
+package rules
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/flyhigher139/mhost/pkg/hostsfile"
+	"github.com/flyhigher139/mhost/pkg/models"
+)
+
+// DefaultFileName 数据目录下默认的规则文件名
+const DefaultFileName = "rules.yaml"
+
+// ErrRuleViolation 条目违反了严重程度为error的规则
+var ErrRuleViolation = errors.New("entries violate validation rules")
+
+// DefaultPath 获取数据目录下默认的规则文件路径
+func DefaultPath(dataDir string) string {
+	return filepath.Join(dataDir, DefaultFileName)
+}
+
+// Rule 一条校验规则，Hostname、Comment和BannedIPs至少设置一项，同时设置时分别检查
+type Rule struct {
+	Name string `yaml:"name"`
+	// Severity 违反规则时的处理：error阻止保存和应用，warning只提示；默认为error
+	Severity hostsfile.Severity `yaml:"severity"`
+	// Message 违反规则时显示的说明，为空时根据检查项生成
+	Message string `yaml:"message"`
+	// Match 只检查主机名匹配该正则表达式的条目，为空时检查所有条目
+	Match string `yaml:"match"`
+	// Hostname 主机名必须匹配的正则表达式
+	Hostname string `yaml:"hostname"`
+	// Comment 注释必须匹配的正则表达式
+	Comment string `yaml:"comment"`
+	// BannedIPs 禁止使用的IP地址或CIDR范围
+	BannedIPs []string `yaml:"banned_ips"`
+}
+
+// File 规则文件的内容
+type File struct {
+	Rules []Rule `yaml:"rules"`
+}
+
+// compiledRule 解析后的规则
+type compiledRule struct {
+	Rule
+	match    *regexp.Regexp
+	hostname *regexp.Regexp
+	comment  *regexp.Regexp
+	banned   []*net.IPNet
+}
+
+// Set 一组已解析的规则，nil表示没有规则
+type Set struct {
+	rules []compiledRule
+}
+
+// Parse 解析YAML或JSON格式的规则文件内容并检查每条规则
+func Parse(data []byte) (*Set, error) {
+	var file File
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse rules: %w", err)
+	}
+
+	set := &Set{}
+	names := make(map[string]bool)
+	for i, rule := range file.Rules {
+		if rule.Name == "" {
+			return nil, fmt.Errorf("rule %d: name is required", i+1)
+		}
+		if names[rule.Name] {
+			return nil, fmt.Errorf("rule %q: duplicate name", rule.Name)
+		}
+		names[rule.Name] = true
+
+		compiled, err := compile(rule)
+		if err != nil {
+			return nil, fmt.Errorf("rule %q: %w", rule.Name, err)
+		}
+		set.rules = append(set.rules, compiled)
+	}
+	return set, nil
+}
+
+// compile 检查规则并编译其中的正则表达式和IP范围
+func compile(rule Rule) (compiledRule, error) {
+	switch rule.Severity {
+	case "":
+		rule.Severity = hostsfile.SeverityError
+	case hostsfile.SeverityError, hostsfile.SeverityWarning:
+	default:
+		return compiledRule{}, fmt.Errorf("invalid severity %q (expected error or warning)", rule.Severity)
+	}
+	if rule.Hostname == "" && rule.Comment == "" && len(rule.BannedIPs) == 0 {
+		return compiledRule{}, errors.New("at least one of hostname, comment or banned_ips is required")
+	}
+
+	compiled := compiledRule{Rule: rule}
+	var err error
+	if compiled.match, err = compilePattern("match", rule.Match); err != nil {
+		return compiledRule{}, err
+	}
+	if compiled.hostname, err = compilePattern("hostname", rule.Hostname); err != nil {
+		return compiledRule{}, err
+	}
+	if compiled.comment, err = compilePattern("comment", rule.Comment); err != nil {
+		return compiledRule{}, err
+	}
+	for _, banned := range rule.BannedIPs {
+		network, err := parseRange(banned)
+		if err != nil {
+			return compiledRule{}, err
+		}
+		compiled.banned = append(compiled.banned, network)
+	}
+	return compiled, nil
+}
+
+// compilePattern 编译正则表达式，为空时返回nil
+func compilePattern(field, pattern string) (*regexp.Regexp, error) {
+	if pattern == "" {
+		return nil, nil
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s pattern: %w", field, err)
+	}
+	return re, nil
+}
+
+// parseRange 解析CIDR范围，单个IP地址视为只包含该地址的范围
+func parseRange(value string) (*net.IPNet, error) {
+	value = strings.TrimSpace(value)
+	if _, network, err := net.ParseCIDR(value); err == nil {
+		return network, nil
+	}
+	ip := net.ParseIP(value)
+	if ip == nil {
+		return nil, fmt.Errorf("invalid banned IP range %q", value)
+	}
+	if ip4 := ip.To4(); ip4 != nil {
+		return &net.IPNet{IP: ip4, Mask: net.CIDRMask(32, 32)}, nil
+	}
+	return &net.IPNet{IP: ip, Mask: net.CIDRMask(128, 128)}, nil
+}
+
+// Load 读取规则文件，文件不存在时返回空的规则集
+func Load(path string) (*Set, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return &Set{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read rules: %w", err)
+	}
+	set, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return set, nil
+}
+
+// LoadConfig 按配置读取规则文件，未设置路径时使用数据目录下的rules.yaml
+func LoadConfig(config models.RulesConfig, dataDir string) (*Set, error) {
+	path := config.Path
+	if path == "" {
+		path = DefaultPath(dataDir)
+	}
+	return Load(path)
+}
+
+// Len 规则数
+func (s *Set) Len() int {
+	if s == nil {
+		return 0
+	}
+	return len(s.rules)
+}
+
+// Violation 条目违反的一条规则
+type Violation struct {
+	Rule     string             `json:"rule"`
+	Severity hostsfile.Severity `json:"severity"`
+	Hostname string             `json:"hostname"`
+	IP       string             `json:"ip"`
+	Message  string             `json:"message"`
+}
+
+// String 格式化为 "hostname (ip): message [rule]"
+func (v Violation) String() string {
+	return fmt.Sprintf("%s (%s): %s [%s]", v.Hostname, v.IP, v.Message, v.Rule)
+}
+
+// Check 检查一个条目，返回违反的规则。禁用的条目不写入hosts文件，不检查
+func (s *Set) Check(entry hostsfile.Entry) []Violation {
+	if s == nil || !entry.Enabled {
+		return nil
+	}
+
+	var violations []Violation
+	for _, rule := range s.rules {
+		if rule.match != nil && !rule.match.MatchString(entry.Hostname) {
+			continue
+		}
+		for _, message := range rule.check(entry) {
+			if rule.Message != "" {
+				message = rule.Message
+			}
+			violations = append(violations, Violation{
+				Rule:     rule.Name,
+				Severity: rule.Severity,
+				Hostname: entry.Hostname,
+				IP:       entry.IP,
+				Message:  message,
+			})
+		}
+	}
+	return violations
+}
+
+// check 返回条目不满足的检查项的说明
+func (r compiledRule) check(entry hostsfile.Entry) []string {
+	var messages []string
+	if r.hostname != nil && !r.hostname.MatchString(entry.Hostname) {
+		messages = append(messages, fmt.Sprintf("hostname does not match %s", r.Hostname))
+	}
+	if r.comment != nil && !r.comment.MatchString(entry.Comment) {
+		messages = append(messages, fmt.Sprintf("comment does not match %s", r.Comment))
+	}
+	if ip := net.ParseIP(entry.IP); ip != nil {
+		for _, network := range r.banned {
+			if network.Contains(ip) {
+				messages = append(messages, fmt.Sprintf("IP is in banned range %s", network))
+				break
+			}
+		}
+	}
+	return messages
+}
+
+// CheckEntries 检查多个条目，按条目顺序返回违反的规则
+func (s *Set) CheckEntries(entries []hostsfile.Entry) []Violation {
+	var violations []Violation
+	for _, entry := range entries {
+		violations = append(violations, s.Check(entry)...)
+	}
+	return violations
+}
+
+// Enforce 检查条目：违反严重程度为error的规则时返回*ViolationError，否则返回违反的warning规则
+func (s *Set) Enforce(entries []hostsfile.Entry) ([]Violation, error) {
+	var errs, warnings []Violation
+	for _, violation := range s.CheckEntries(entries) {
+		if violation.Severity == hostsfile.SeverityError {
+			errs = append(errs, violation)
+		} else {
+			warnings = append(warnings, violation)
+		}
+	}
+	if len(errs) > 0 {
+		return warnings, &ViolationError{Violations: errs}
+	}
+	return warnings, nil
+}
+
+// ViolationError 违反严重程度为error的规则的条目
+type ViolationError struct {
+	Violations []Violation
+}
+
+// Error 实现error接口
+func (e *ViolationError) Error() string {
+	messages := make([]string, 0, len(e.Violations))
+	for _, violation := range e.Violations {
+		messages = append(messages, violation.String())
+	}
+	return fmt.Sprintf("%s: %s", ErrRuleViolation, strings.Join(messages, "; "))
+}
+
+// Unwrap 支持errors.Is(err, ErrRuleViolation)
+func (e *ViolationError) Unwrap() error {
+	return ErrRuleViolation
+}
@@ -0,0 +1,115 @@
+package rules
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/flyhigher139/mhost/pkg/hostsfile"
+	"github.com/flyhigher139/mhost/pkg/models"
+)
+
+const testRules = `
+rules:
+  - name: corp-domain
+    match: '\.corp$'
+    hostname: '^[a-z0-9-]+\.corp$'
+    message: corp hostnames must be a single label
+  - name: ticket
+    severity: warning
+    comment: 'ticket=[A-Z]+-\d+'
+  - name: no-public-dns
+    banned_ips: ["8.8.8.0/24", "1.1.1.1", "2001:4860::/32"]
+`
+
+// TestCheck 测试按规则检查条目
+func TestCheck(t *testing.T) {
+	set, err := Parse([]byte(testRules))
+	require.NoError(t, err)
+	assert.Equal(t, 3, set.Len())
+
+	entry := hostsfile.Entry{IP: "10.0.0.1", Hostname: "api.corp", Comment: "ticket=OPS-1", Enabled: true}
+	assert.Empty(t, set.Check(entry))
+
+	violations := set.Check(hostsfile.Entry{IP: "8.8.8.8", Hostname: "a.b.corp", Enabled: true})
+	require.Len(t, violations, 3)
+	assert.Equal(t, Violation{Rule: "corp-domain", Severity: hostsfile.SeverityError, Hostname: "a.b.corp", IP: "8.8.8.8",
+		Message: "corp hostnames must be a single label"}, violations[0])
+	assert.Equal(t, "ticket", violations[1].Rule)
+	assert.Equal(t, hostsfile.SeverityWarning, violations[1].Severity)
+	assert.Equal(t, "no-public-dns", violations[2].Rule)
+	assert.Equal(t, "a.b.corp (8.8.8.8): IP is in banned range 8.8.8.0/24 [no-public-dns]", violations[2].String())
+
+	// match只选择要检查的条目，单个IP和IPv6范围都可以禁止
+	assert.Len(t, set.Check(hostsfile.Entry{IP: "1.1.1.1", Hostname: "a.b.test", Comment: "ticket=OPS-1", Enabled: true}), 1)
+	assert.Len(t, set.Check(hostsfile.Entry{IP: "2001:4860::8888", Hostname: "dns.test", Comment: "ticket=OPS-1", Enabled: true}), 1)
+	assert.Empty(t, set.Check(hostsfile.Entry{IP: "8.8.8.8", Hostname: "a.b.corp"}), "disabled entries are not checked")
+
+	var none *Set
+	assert.Empty(t, none.Check(entry))
+	assert.Zero(t, none.Len())
+}
+
+// TestEnforce 测试error规则阻止、warning规则只提示
+func TestEnforce(t *testing.T) {
+	set, err := Parse([]byte(testRules))
+	require.NoError(t, err)
+
+	warnings, err := set.Enforce([]hostsfile.Entry{{IP: "10.0.0.1", Hostname: "api.corp", Enabled: true}})
+	require.NoError(t, err)
+	require.Len(t, warnings, 1)
+	assert.Equal(t, "ticket", warnings[0].Rule)
+
+	_, err = set.Enforce([]hostsfile.Entry{
+		{IP: "10.0.0.1", Hostname: "api.corp", Comment: "ticket=OPS-1", Enabled: true},
+		{IP: "8.8.8.8", Hostname: "dns.test", Comment: "ticket=OPS-1", Enabled: true},
+	})
+	assert.ErrorIs(t, err, ErrRuleViolation)
+	var violationErr *ViolationError
+	require.ErrorAs(t, err, &violationErr)
+	require.Len(t, violationErr.Violations, 1)
+	assert.Equal(t, "dns.test", violationErr.Violations[0].Hostname)
+}
+
+// TestParse 测试解析JSON格式的规则和拒绝无效的规则
+func TestParse(t *testing.T) {
+	set, err := Parse([]byte(`{"rules": [{"name": "lab", "hostname": "\\.lab$", "severity": "warning"}]}`))
+	require.NoError(t, err)
+	violations := set.Check(hostsfile.Entry{IP: "10.0.0.1", Hostname: "api.test", Enabled: true})
+	require.Len(t, violations, 1)
+	assert.Equal(t, hostsfile.SeverityWarning, violations[0].Severity)
+
+	for name, data := range map[string]string{
+		"missing name":   `rules: [{hostname: "x"}]`,
+		"duplicate name": `rules: [{name: a, hostname: "x"}, {name: a, comment: "y"}]`,
+		"no checks":      `rules: [{name: a}]`,
+		"bad severity":   `rules: [{name: a, hostname: "x", severity: fatal}]`,
+		"bad pattern":    `rules: [{name: a, hostname: "("}]`,
+		"bad range":      `rules: [{name: a, banned_ips: ["10.0.0.0/40"]}]`,
+		"bad document":   `rules: {`,
+	} {
+		_, err := Parse([]byte(data))
+		assert.Error(t, err, name)
+	}
+}
+
+// TestLoad 测试规则文件不存在时没有规则
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	set, err := LoadConfig(models.RulesConfig{}, dir)
+	require.NoError(t, err)
+	assert.Zero(t, set.Len())
+
+	require.NoError(t, os.WriteFile(DefaultPath(dir), []byte(testRules), 0644))
+	set, err = LoadConfig(models.RulesConfig{}, dir)
+	require.NoError(t, err)
+	assert.Equal(t, 3, set.Len())
+
+	path := filepath.Join(dir, "team.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`rules: [{name: a}]`), 0644))
+	_, err = LoadConfig(models.RulesConfig{Path: path}, dir)
+	assert.ErrorContains(t, err, "team.yaml")
+}
@@ -0,0 +1,304 @@
+// Package snapshot 定期将所有Profile导出为带日期的快照文件，与hosts文件备份相互独立，用于Profile数据本身的灾难恢复。
+package snapshot
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/flyhigher139/mhost/internal/profile"
+	"github.com/flyhigher139/mhost/pkg/models"
+)
+
+// BundleVersion 快照文件格式的版本
+const BundleVersion = 1
+
+// 快照文件名的前缀、时间格式和扩展名，例如 profiles-2024-01-02-150405.json
+const (
+	filePrefix = "profiles-"
+	timeFormat = "2006-01-02-150405"
+	fileSuffix = ".json"
+)
+
+// Bundle 快照文件的内容，包含导出时的全部Profile
+type Bundle struct {
+	Version    int               `json:"version"`
+	ExportedAt time.Time         `json:"exported_at"`
+	Profiles   []*models.Profile `json:"profiles"`
+}
+
+// Snapshot 快照文件的信息
+type Snapshot struct {
+	Path      string    `json:"path"`
+	CreatedAt time.Time `json:"created_at"`
+	Size      int64     `json:"size"`
+}
+
+// DefaultDir 获取数据目录下的默认快照目录
+func DefaultDir(dataDir string) string {
+	return filepath.Join(dataDir, "snapshots")
+}
+
+// List 获取目录中的快照，最新的在前；目录不存在时返回空列表
+func List(dir string) ([]Snapshot, error) {
+	files, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read snapshot directory: %w", err)
+	}
+
+	var snapshots []Snapshot
+	for _, file := range files {
+		name := file.Name()
+		if file.IsDir() || !strings.HasPrefix(name, filePrefix) || !strings.HasSuffix(name, fileSuffix) {
+			continue
+		}
+		stamp := strings.TrimSuffix(strings.TrimPrefix(name, filePrefix), fileSuffix)
+		createdAt, err := time.ParseInLocation(timeFormat, stamp, time.Local)
+		if err != nil {
+			continue
+		}
+		info, err := file.Info()
+		if err != nil {
+			continue
+		}
+		snapshots = append(snapshots, Snapshot{
+			Path:      filepath.Join(dir, name),
+			CreatedAt: createdAt,
+			Size:      info.Size(),
+		})
+	}
+
+	sort.Slice(snapshots, func(i, j int) bool {
+		return snapshots[i].CreatedAt.After(snapshots[j].CreatedAt)
+	})
+	return snapshots, nil
+}
+
+// Load 读取快照文件
+func Load(path string) (*Bundle, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read snapshot: %w", err)
+	}
+
+	var bundle Bundle
+	if err := json.Unmarshal(data, &bundle); err != nil {
+		return nil, fmt.Errorf("failed to parse snapshot: %w", err)
+	}
+	if bundle.Version > BundleVersion {
+		return nil, fmt.Errorf("unsupported snapshot version: %d", bundle.Version)
+	}
+	return &bundle, nil
+}
+
+// Restore 将快照中的Profile作为新Profile导入，与现有Profile重名时自动添加后缀，重命名和跳过的重复条目记录在report中；
+// 遇到无法导入的Profile时停止，返回已导入的Profile
+func Restore(profileManager profile.Manager, bundle *Bundle, report *profile.ImportReport) ([]*models.Profile, error) {
+	restored := make([]*models.Profile, 0, len(bundle.Profiles))
+	for _, p := range bundle.Profiles {
+		imported, err := report.Import(profileManager, p)
+		if err != nil {
+			return restored, fmt.Errorf("failed to restore profile %s: %w", p.Name, err)
+		}
+		restored = append(restored, imported)
+	}
+	return restored, nil
+}
+
+// Scheduler 定期导出Profile快照的服务
+type Scheduler struct {
+	profileManager profile.Manager
+	dir            string
+	keep           int
+	now            func() time.Time
+
+	mu         sync.RWMutex
+	last       *Snapshot
+	lastErr    error
+	stopChan   chan struct{}
+	nextRun    time.Time
+	onSnapshot func(*Snapshot, error)
+}
+
+// NewScheduler 创建快照服务，keep大于0时只保留最新的keep个快照
+func NewScheduler(profileManager profile.Manager, dir string, keep int) *Scheduler {
+	return &Scheduler{
+		profileManager: profileManager,
+		dir:            dir,
+		keep:           keep,
+		now:            time.Now,
+	}
+}
+
+// Dir 快照目录
+func (s *Scheduler) Dir() string {
+	return s.dir
+}
+
+// Export 立即导出全部Profile，然后清理超出保留数量的旧快照
+func (s *Scheduler) Export() (*Snapshot, error) {
+	snapshot, err := s.export()
+
+	s.mu.Lock()
+	if err == nil {
+		s.last = snapshot
+	}
+	s.lastErr = err
+	callback := s.onSnapshot
+	s.mu.Unlock()
+
+	if callback != nil {
+		callback(snapshot, err)
+	}
+	return snapshot, err
+}
+
+// export 写入快照文件并清理旧快照
+func (s *Scheduler) export() (*Snapshot, error) {
+	summaries, err := s.profileManager.ListProfiles()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list profiles: %w", err)
+	}
+
+	now := s.now()
+	bundle := Bundle{Version: BundleVersion, ExportedAt: now, Profiles: make([]*models.Profile, 0, len(summaries))}
+	for _, summary := range summaries {
+		p, err := s.profileManager.GetProfile(summary.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load profile %s: %w", summary.Name, err)
+		}
+		bundle.Profiles = append(bundle.Profiles, p)
+	}
+
+	data, err := json.MarshalIndent(bundle, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal snapshot: %w", err)
+	}
+	if err := os.MkdirAll(s.dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create snapshot directory: %w", err)
+	}
+
+	// 先写入临时文件再替换，避免留下不完整的快照
+	path := filepath.Join(s.dir, filePrefix+now.Format(timeFormat)+fileSuffix)
+	tempPath := path + ".tmp"
+	if err := os.WriteFile(tempPath, data, 0644); err != nil {
+		return nil, fmt.Errorf("failed to write snapshot: %w", err)
+	}
+	if err := os.Rename(tempPath, path); err != nil {
+		os.Remove(tempPath)
+		return nil, fmt.Errorf("failed to save snapshot: %w", err)
+	}
+
+	if err := s.prune(); err != nil {
+		return nil, err
+	}
+	return &Snapshot{Path: path, CreatedAt: now, Size: int64(len(data))}, nil
+}
+
+// prune 删除超出保留数量的旧快照
+func (s *Scheduler) prune() error {
+	if s.keep <= 0 {
+		return nil
+	}
+
+	snapshots, err := List(s.dir)
+	if err != nil {
+		return err
+	}
+	for _, snapshot := range snapshots[min(s.keep, len(snapshots)):] {
+		if err := os.Remove(snapshot.Path); err != nil {
+			return fmt.Errorf("failed to remove old snapshot: %w", err)
+		}
+	}
+	return nil
+}
+
+// LastSnapshot 获取本次运行中最近一次导出的快照和最近一次导出的错误
+func (s *Scheduler) LastSnapshot() (*Snapshot, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.last, s.lastErr
+}
+
+// OnSnapshot 设置每次导出后的回调，导出失败时snapshot为nil
+func (s *Scheduler) OnSnapshot(callback func(snapshot *Snapshot, err error)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.onSnapshot = callback
+}
+
+// Start 按固定间隔定时导出。距离目录中最新的快照已超过间隔时立即导出一次，
+// 否则等到间隔届满，避免每次启动都生成快照
+func (s *Scheduler) Start(interval time.Duration) error {
+	if interval <= 0 {
+		return fmt.Errorf("invalid snapshot interval: %v", interval)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.stopChan != nil {
+		return fmt.Errorf("snapshot scheduler already started")
+	}
+
+	delay := time.Duration(0)
+	if snapshots, err := List(s.dir); err == nil && len(snapshots) > 0 {
+		delay = max(snapshots[0].CreatedAt.Add(interval).Sub(s.now()), 0)
+	}
+	s.stopChan = make(chan struct{})
+	s.nextRun = s.now().Add(delay)
+
+	go func(stop chan struct{}) {
+		timer := time.NewTimer(delay)
+		defer timer.Stop()
+
+		for {
+			select {
+			case <-stop:
+				return
+			case <-timer.C:
+				s.scheduleNext(interval)
+				s.Export()
+				timer.Reset(interval)
+			}
+		}
+	}(s.stopChan)
+
+	return nil
+}
+
+// NextRun 下一次定时导出的时间，未启动时返回false
+func (s *Scheduler) NextRun() (time.Time, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.nextRun, !s.nextRun.IsZero()
+}
+
+// scheduleNext 记录下一次定时导出的时间
+func (s *Scheduler) scheduleNext(interval time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.stopChan != nil {
+		s.nextRun = s.now().Add(interval)
+	}
+}
+
+// Stop 停止定时导出
+func (s *Scheduler) Stop() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.stopChan != nil {
+		close(s.stopChan)
+		s.stopChan = nil
+		s.nextRun = time.Time{}
+	}
+}
@@ -0,0 +1,130 @@
+package snapshot
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/flyhigher139/mhost/internal/profile"
+	"github.com/flyhigher139/mhost/pkg/models"
+)
+
+// TestExport 测试导出全部Profile、清理旧快照和从快照恢复
+func TestExport(t *testing.T) {
+	dir := t.TempDir()
+	pm, err := profile.NewManager(filepath.Join(dir, "profiles"))
+	require.NoError(t, err)
+
+	p, err := pm.CreateProfile("Dev", "")
+	require.NoError(t, err)
+	p.AddEntry(models.NewHostEntry("10.0.0.1", "api.test", ""))
+	require.NoError(t, pm.UpdateProfile(p))
+	_, err = pm.CreateProfile("Staging", "")
+	require.NoError(t, err)
+
+	snapshotDir := filepath.Join(dir, "snapshots")
+	scheduler := NewScheduler(pm, snapshotDir, 2)
+	now := time.Date(2024, 1, 2, 15, 4, 5, 0, time.Local)
+	scheduler.now = func() time.Time { return now }
+
+	var notified *Snapshot
+	scheduler.OnSnapshot(func(snapshot *Snapshot, err error) {
+		require.NoError(t, err)
+		notified = snapshot
+	})
+
+	for i := 0; i < 3; i++ {
+		_, err := scheduler.Export()
+		require.NoError(t, err)
+		now = now.Add(24 * time.Hour)
+	}
+
+	snapshots, err := List(snapshotDir)
+	require.NoError(t, err)
+	require.Len(t, snapshots, 2)
+	assert.Equal(t, filepath.Join(snapshotDir, "profiles-2024-01-04-150405.json"), snapshots[0].Path)
+	assert.Equal(t, "profiles-2024-01-03-150405.json", filepath.Base(snapshots[1].Path))
+	assert.Equal(t, snapshots[0].Path, notified.Path)
+
+	last, err := scheduler.LastSnapshot()
+	require.NoError(t, err)
+	assert.Equal(t, snapshots[0].Path, last.Path)
+
+	bundle, err := Load(snapshots[0].Path)
+	require.NoError(t, err)
+	assert.Equal(t, BundleVersion, bundle.Version)
+	require.Len(t, bundle.Profiles, 2)
+
+	report := profile.NewImportReport(snapshots[0].Path)
+	restored, err := Restore(pm, bundle, report)
+	require.NoError(t, err)
+	require.Len(t, restored, 2)
+	summaries, err := pm.ListProfiles()
+	require.NoError(t, err)
+	assert.Len(t, summaries, 4)
+	for _, p := range restored {
+		assert.NotEqual(t, "Dev", p.Name)
+		assert.NotEqual(t, "Staging", p.Name)
+	}
+
+	// 重名的Profile记录在导入报告中
+	assert.Equal(t, 2, report.Renamed())
+	assert.True(t, report.HasConflicts())
+	require.Len(t, report.Profiles, 2)
+	for i, p := range report.Profiles {
+		assert.Equal(t, restored[i].ID, p.ID)
+		assert.Equal(t, restored[i].Name, p.Name)
+		assert.Contains(t, []string{"Dev", "Staging"}, p.RequestedName)
+	}
+}
+
+// TestList 测试忽略无关文件和不存在的目录
+func TestList(t *testing.T) {
+	snapshots, err := List(filepath.Join(t.TempDir(), "missing"))
+	require.NoError(t, err)
+	assert.Empty(t, snapshots)
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "profiles-latest.json"), []byte("{}"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "notes.txt"), []byte(""), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "profiles-2024-01-02-150405.json"), []byte("{}"), 0644))
+
+	snapshots, err = List(dir)
+	require.NoError(t, err)
+	require.Len(t, snapshots, 1)
+	assert.Equal(t, 2024, snapshots[0].CreatedAt.Year())
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "profiles-2024-01-03-150405.json"), []byte(`{"version": 99}`), 0644))
+	_, err = Load(filepath.Join(dir, "profiles-2024-01-03-150405.json"))
+	assert.Error(t, err)
+}
+
+// TestStart 测试启动时按最新快照的时间决定下一次导出
+func TestStart(t *testing.T) {
+	dir := t.TempDir()
+	pm, err := profile.NewManager(filepath.Join(dir, "profiles"))
+	require.NoError(t, err)
+
+	scheduler := NewScheduler(pm, dir, 0)
+	assert.Error(t, scheduler.Start(0))
+
+	now := time.Now()
+	recent := filepath.Join(dir, filePrefix+now.Add(-time.Hour).Format(timeFormat)+fileSuffix)
+	require.NoError(t, os.WriteFile(recent, []byte("{}"), 0644))
+
+	require.NoError(t, scheduler.Start(24*time.Hour))
+	defer scheduler.Stop()
+	assert.Error(t, scheduler.Start(24*time.Hour))
+
+	next, ok := scheduler.NextRun()
+	require.True(t, ok)
+	assert.WithinDuration(t, now.Add(23*time.Hour), next, time.Minute)
+
+	scheduler.Stop()
+	_, ok = scheduler.NextRun()
+	assert.False(t, ok)
+}
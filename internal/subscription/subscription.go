@@ -0,0 +1,326 @@
+// Package subscription 定期刷新订阅了远程hosts列表的Profile：使用ETag/If-Modified-Since条件请求下载上游内容，
+// 内容变化时更新Profile的条目，Profile处于激活状态时重新应用到hosts文件。
+package subscription
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/flyhigher139/mhost/internal/importer"
+	"github.com/flyhigher139/mhost/internal/profile"
+	"github.com/flyhigher139/mhost/pkg/hostsfile"
+	"github.com/flyhigher139/mhost/pkg/models"
+)
+
+const (
+	// DefaultCheckInterval 检查是否有订阅到期的默认间隔，每个订阅按自己的刷新间隔下载
+	DefaultCheckInterval = 5 * time.Minute
+	// DefaultMaxSubscriptionSize 订阅内容的默认大小上限，公共屏蔽列表通常有数MB
+	DefaultMaxSubscriptionSize = 32 << 20
+)
+
+// ErrNotSubscribed Profile没有订阅远程列表
+var ErrNotSubscribed = errors.New("profile has no subscription")
+
+// systemHostnames 系统hosts文件已有的本地主机名，公共列表开头通常会重复列出，订阅时跳过
+var systemHostnames = map[string]bool{
+	"localhost":             true,
+	"localhost.localdomain": true,
+	"local":                 true,
+	"broadcasthost":         true,
+	"ip6-localhost":         true,
+	"ip6-loopback":          true,
+	"ip6-localnet":          true,
+	"ip6-mcastprefix":       true,
+	"ip6-allnodes":          true,
+	"ip6-allrouters":        true,
+	"ip6-allhosts":          true,
+}
+
+// Result 一次刷新的结果
+type Result struct {
+	ProfileID   string
+	ProfileName string
+	// NotModified 服务器返回304，上游内容没有变化
+	NotModified bool
+	// Changed 条目因上游内容变化而更新
+	Changed bool
+	Entries int
+	// Applied 更新后重新应用了激活的Profile
+	Applied bool
+	Err     error
+}
+
+// Refresher 订阅刷新服务
+type Refresher struct {
+	profileManager profile.Manager
+	fetcher        *importer.RemoteFetcher
+	now            func() time.Time
+
+	// refreshMu 串行执行刷新，避免同一Profile被并发下载和更新
+	refreshMu sync.Mutex
+
+	mu         sync.RWMutex
+	normalizer hostsfile.Normalizer
+	applier    func(*models.Profile) error
+	onResult   func(*Result)
+	stopChan   chan struct{}
+	cancel     context.CancelFunc
+}
+
+// NewRefresher 创建订阅刷新服务，fetcher为nil时使用默认超时和DefaultMaxSubscriptionSize
+func NewRefresher(profileManager profile.Manager, fetcher *importer.RemoteFetcher) *Refresher {
+	if fetcher == nil {
+		fetcher = importer.NewRemoteFetcher()
+		fetcher.MaxSize = DefaultMaxSubscriptionSize
+	}
+	return &Refresher{
+		profileManager: profileManager,
+		fetcher:        fetcher,
+		now:            time.Now,
+		normalizer:     hostsfile.DefaultNormalizer,
+	}
+}
+
+// SetNormalizer 设置主机名规范化规则
+func (r *Refresher) SetNormalizer(normalizer hostsfile.Normalizer) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.normalizer = normalizer
+}
+
+// SetApplier 设置重新应用激活Profile的函数，未设置时只更新Profile
+func (r *Refresher) SetApplier(applier func(*models.Profile) error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.applier = applier
+}
+
+// OnResult 设置每次刷新完成后的回调
+func (r *Refresher) OnResult(callback func(*Result)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.onResult = callback
+}
+
+// Refresh 立即刷新指定Profile的订阅，不考虑刷新间隔。
+// 下载或解析失败时保留原有条目，错误记录在订阅的LastError中
+func (r *Refresher) Refresh(ctx context.Context, id string) *Result {
+	r.refreshMu.Lock()
+	result := r.refresh(ctx, id)
+	r.refreshMu.Unlock()
+
+	r.mu.RLock()
+	onResult := r.onResult
+	r.mu.RUnlock()
+	if onResult != nil {
+		onResult(result)
+	}
+	return result
+}
+
+// refresh 下载订阅内容并更新Profile
+func (r *Refresher) refresh(ctx context.Context, id string) *Result {
+	result := &Result{ProfileID: id}
+	p, err := r.profileManager.GetProfile(id)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+	result.ProfileName = p.Name
+	result.Entries = len(p.Entries)
+	if p.Subscription == nil || p.Subscription.URL == "" {
+		result.Err = ErrNotSubscribed
+		return result
+	}
+
+	subscription := p.Subscription
+	subscription.LastChecked = r.now()
+	fetched, err := r.fetcher.FetchConditional(ctx, subscription.URL, subscription.ETag, subscription.LastModified)
+	if err == nil && !fetched.NotModified {
+		var entries []*models.HostEntry
+		entries, err = r.parse(fetched.Data)
+		if err == nil {
+			p.Entries, result.Changed = MergeEntries(p.Entries, entries)
+			result.Entries = len(p.Entries)
+			if result.Changed {
+				subscription.LastUpdated = subscription.LastChecked
+			}
+		}
+	}
+	if err != nil && ctx.Err() != nil {
+		// 服务停止时取消的下载不算作订阅错误
+		result.Err = err
+		return result
+	}
+	if err != nil {
+		subscription.LastError = err.Error()
+		result.Err = fmt.Errorf("refresh subscription %s: %w", subscription.URL, err)
+	} else {
+		subscription.LastError = ""
+		subscription.ETag = fetched.ETag
+		subscription.LastModified = fetched.LastModified
+		result.NotModified = fetched.NotModified
+	}
+
+	if err := r.profileManager.UpdateProfile(p); err != nil {
+		if result.Err == nil {
+			result.Err = err
+		}
+		return result
+	}
+	if result.Changed {
+		result.Applied, result.Err = r.applyIfActive(p)
+	}
+	return result
+}
+
+// parse 解析hosts格式的订阅内容，跳过无效条目、系统本地主机名、以IP作为主机名的条目
+// （例如列表开头的"0.0.0.0 0.0.0.0"）和重复的主机名
+func (r *Refresher) parse(data []byte) ([]*models.HostEntry, error) {
+	r.mu.RLock()
+	normalizer := r.normalizer
+	r.mu.RUnlock()
+
+	seen := make(map[string]bool)
+	var entries []*models.HostEntry
+	for _, entry := range hostsfile.Parse(hostsfile.SplitLines(string(data))) {
+		normalized, err := normalizer.NormalizeEntry(entry)
+		if err != nil || hostsfile.ValidateEntry(normalized) != nil || seen[normalized.Hostname] ||
+			systemHostnames[normalized.Hostname] || net.ParseIP(normalized.Hostname) != nil {
+			continue
+		}
+		seen[normalized.Hostname] = true
+		entries = append(entries, normalized.ToModel())
+	}
+	if len(entries) == 0 {
+		return nil, importer.ErrNoEntries
+	}
+	return entries, nil
+}
+
+// MergeEntries 用上游条目替换当前条目。IP、主机名和注释都没有变化的条目沿用原有条目，
+// 保留其ID和启用状态；返回合并后的条目以及内容是否变化
+func MergeEntries(current, upstream []*models.HostEntry) ([]*models.HostEntry, bool) {
+	existing := make(map[string]*models.HostEntry, len(current))
+	for _, entry := range current {
+		existing[entryKey(entry)] = entry
+	}
+
+	changed := len(current) != len(upstream)
+	merged := make([]*models.HostEntry, 0, len(upstream))
+	for i, entry := range upstream {
+		if old, ok := existing[entryKey(entry)]; ok {
+			entry = old
+		}
+		if changed || current[i] != entry {
+			changed = true
+		}
+		merged = append(merged, entry)
+	}
+	return merged, changed
+}
+
+// entryKey 比较条目时使用的键
+func entryKey(entry *models.HostEntry) string {
+	return strings.Join([]string{entry.IP, entry.Hostname, entry.Comment}, "\x00")
+}
+
+// applyIfActive Profile处于激活状态（包括与其他Profile同时激活）时重新应用
+func (r *Refresher) applyIfActive(p *models.Profile) (bool, error) {
+	r.mu.RLock()
+	applier := r.applier
+	r.mu.RUnlock()
+	if applier == nil {
+		return false, nil
+	}
+
+	active, err := r.profileManager.GetActiveProfiles()
+	if err != nil {
+		if errors.Is(err, models.ErrProfileNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+	if !slices.ContainsFunc(active, func(a *models.Profile) bool { return a.ID == p.ID }) {
+		return false, nil
+	}
+	if err := applier(p); err != nil {
+		return false, fmt.Errorf("apply refreshed profile: %w", err)
+	}
+	return true, nil
+}
+
+// RefreshDue 刷新所有到期的订阅
+func (r *Refresher) RefreshDue(ctx context.Context) []*Result {
+	summaries, err := r.profileManager.ListProfiles()
+	if err != nil {
+		return []*Result{{Err: err}}
+	}
+
+	var results []*Result
+	for _, summary := range summaries {
+		if ctx.Err() != nil {
+			break
+		}
+		p, err := r.profileManager.GetProfile(summary.ID)
+		if err != nil || p.Subscription == nil || !p.Subscription.Due(r.now()) {
+			continue
+		}
+		results = append(results, r.Refresh(ctx, p.ID))
+	}
+	return results
+}
+
+// Start 立即刷新到期的订阅，然后按固定间隔检查
+func (r *Refresher) Start(interval time.Duration) error {
+	if interval <= 0 {
+		return fmt.Errorf("invalid check interval: %v", interval)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.stopChan != nil {
+		return fmt.Errorf("refresher already started")
+	}
+	r.stopChan = make(chan struct{})
+	ctx, cancel := context.WithCancel(context.Background())
+	r.cancel = cancel
+
+	go func(stop chan struct{}) {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		r.RefreshDue(ctx)
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				r.RefreshDue(ctx)
+			}
+		}
+	}(r.stopChan)
+
+	return nil
+}
+
+// Stop 停止定时刷新并取消正在进行的下载
+func (r *Refresher) Stop() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.stopChan != nil {
+		close(r.stopChan)
+		r.stopChan = nil
+		r.cancel()
+		r.cancel = nil
+	}
+}
@@ -0,0 +1,169 @@
+package subscription
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/flyhigher139/mhost/internal/importer"
+	"github.com/flyhigher139/mhost/internal/profile"
+	"github.com/flyhigher139/mhost/pkg/models"
+)
+
+// upstream 模拟支持ETag的远程hosts列表
+type upstream struct {
+	mu          sync.Mutex
+	body        string
+	etag        string
+	requests    int
+	conditional int
+}
+
+func (u *upstream) set(body, etag string) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.body, u.etag = body, etag
+}
+
+func (u *upstream) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.requests++
+	if match := r.Header.Get("If-None-Match"); match != "" {
+		u.conditional++
+		if match == u.etag {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+	}
+	w.Header().Set("ETag", u.etag)
+	w.Write([]byte(u.body))
+}
+
+// TestRefresh 测试条件请求、条目更新与激活Profile的重新应用
+func TestRefresh(t *testing.T) {
+	list := &upstream{}
+	list.set("# blocklist\n127.0.0.1 localhost\n0.0.0.0 0.0.0.0\n0.0.0.0 ads.test\n0.0.0.0 Tracker.test.\n0.0.0.0 ads.test\n", `"v1"`)
+	server := httptest.NewServer(list)
+	defer server.Close()
+
+	pm, err := profile.NewManager(filepath.Join(t.TempDir(), "profiles"))
+	require.NoError(t, err)
+	p, err := pm.CreateProfile("Blocklist", "")
+	require.NoError(t, err)
+	p.Subscription = &models.Subscription{URL: server.URL + "/hosts", IntervalMinutes: 60}
+	require.NoError(t, pm.UpdateProfile(p))
+	require.NoError(t, pm.ActivateProfile(p.ID))
+
+	var applied []*models.Profile
+	refresher := NewRefresher(pm, nil)
+	refresher.SetApplier(func(p *models.Profile) error {
+		applied = append(applied, p)
+		return nil
+	})
+	var results []*Result
+	refresher.OnResult(func(r *Result) { results = append(results, r) })
+
+	ctx := context.Background()
+	result := refresher.Refresh(ctx, p.ID)
+	require.NoError(t, result.Err)
+	assert.True(t, result.Changed)
+	assert.True(t, result.Applied)
+	assert.Equal(t, 2, result.Entries)
+	require.Len(t, applied, 1)
+	require.Len(t, results, 1)
+
+	saved, err := pm.GetProfile(p.ID)
+	require.NoError(t, err)
+	require.Len(t, saved.Entries, 2)
+	assert.Equal(t, "ads.test", saved.Entries[0].Hostname)
+	assert.Equal(t, "tracker.test", saved.Entries[1].Hostname)
+	assert.Equal(t, `"v1"`, saved.Subscription.ETag)
+	assert.False(t, saved.Subscription.LastUpdated.IsZero())
+
+	// 本地禁用的条目在上游没有变化时保持禁用
+	saved.Entries[0].Enabled = false
+	require.NoError(t, pm.UpdateProfile(saved))
+
+	// 内容未变化时服务器返回304，不更新条目也不重新应用
+	result = refresher.Refresh(ctx, p.ID)
+	require.NoError(t, result.Err)
+	assert.True(t, result.NotModified)
+	assert.False(t, result.Changed)
+	assert.Len(t, applied, 1)
+	assert.Equal(t, 1, list.conditional)
+
+	// 上游内容变化后更新条目并重新应用
+	list.set("0.0.0.0 ads.test\n0.0.0.0 new.test\n", `"v2"`)
+	result = refresher.Refresh(ctx, p.ID)
+	require.NoError(t, result.Err)
+	assert.True(t, result.Changed)
+	require.Len(t, applied, 2)
+
+	saved, err = pm.GetProfile(p.ID)
+	require.NoError(t, err)
+	require.Len(t, saved.Entries, 2)
+	assert.False(t, saved.Entries[0].Enabled)
+	assert.Equal(t, "new.test", saved.Entries[1].Hostname)
+	assert.Equal(t, `"v2"`, saved.Subscription.ETag)
+
+	// 上游内容无法解析时保留条目并记录错误
+	list.set("<html></html>", `"v3"`)
+	result = refresher.Refresh(ctx, p.ID)
+	assert.ErrorIs(t, result.Err, importer.ErrNoEntries)
+	saved, err = pm.GetProfile(p.ID)
+	require.NoError(t, err)
+	assert.Len(t, saved.Entries, 2)
+	assert.NotEmpty(t, saved.Subscription.LastError)
+
+	// 没有订阅的Profile
+	other, err := pm.CreateProfile("Local", "")
+	require.NoError(t, err)
+	assert.ErrorIs(t, refresher.Refresh(ctx, other.ID).Err, ErrNotSubscribed)
+}
+
+// TestRefreshDue 测试只刷新到期的订阅
+func TestRefreshDue(t *testing.T) {
+	list := &upstream{}
+	list.set("0.0.0.0 ads.test\n", `"v1"`)
+	server := httptest.NewServer(list)
+	defer server.Close()
+
+	pm, err := profile.NewManager(filepath.Join(t.TempDir(), "profiles"))
+	require.NoError(t, err)
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	due, err := pm.CreateProfile("Due", "")
+	require.NoError(t, err)
+	due.Subscription = &models.Subscription{URL: server.URL, IntervalMinutes: 60, LastChecked: now.Add(-2 * time.Hour)}
+	require.NoError(t, pm.UpdateProfile(due))
+
+	fresh, err := pm.CreateProfile("Fresh", "")
+	require.NoError(t, err)
+	fresh.Subscription = &models.Subscription{URL: server.URL, IntervalMinutes: 60, LastChecked: now.Add(-10 * time.Minute)}
+	require.NoError(t, pm.UpdateProfile(fresh))
+
+	_, err = pm.CreateProfile("Local", "")
+	require.NoError(t, err)
+
+	refresher := NewRefresher(pm, nil)
+	refresher.now = func() time.Time { return now }
+
+	results := refresher.RefreshDue(context.Background())
+	require.Len(t, results, 1)
+	assert.Equal(t, due.ID, results[0].ProfileID)
+	assert.NoError(t, results[0].Err)
+	assert.Equal(t, 1, list.requests)
+
+	saved, err := pm.GetProfile(due.ID)
+	require.NoError(t, err)
+	assert.Equal(t, now, saved.Subscription.LastChecked)
+	assert.False(t, saved.Subscription.Due(now.Add(30*time.Minute)))
+}
@@ -0,0 +1,261 @@
+// Package telemetry 在本地汇总匿名的功能使用次数，帮助维护者确定开发优先级。
+//
+// 统计默认关闭，仅记录预定义的事件名称和次数，不记录主机名、IP或Profile内容，
+// 数据只保存在本地文件中，用户可以随时导出或清除。
+package telemetry
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Event 使用事件名称
+type Event string
+
+// 支持统计的事件，Record只接受这些预定义的名称
+const (
+	EventApplyProfile    Event = "apply_profile"    // 应用Profile
+	EventAutoApply       Event = "auto_apply"       // 保存后自动应用
+	EventCreateProfile   Event = "create_profile"   // 新建Profile
+	EventImportProfile   Event = "import_profile"   // 导入Profile
+	EventExportProfile   Event = "export_profile"   // 导出Profile
+	EventBackupHosts     Event = "backup_hosts"     // 备份hosts文件
+	EventAnalyzeEntries  Event = "analyze_entries"  // 条目分析
+	EventDiagnostics     Event = "diagnostics"      // IP诊断
+	EventSwitchWorkspace Event = "switch_workspace" // 切换工作区
+)
+
+// knownEvents 允许记录的事件
+var knownEvents = map[Event]bool{
+	EventApplyProfile:    true,
+	EventAutoApply:       true,
+	EventCreateProfile:   true,
+	EventImportProfile:   true,
+	EventExportProfile:   true,
+	EventBackupHosts:     true,
+	EventAnalyzeEntries:  true,
+	EventDiagnostics:     true,
+	EventSwitchWorkspace: true,
+}
+
+// ErrUnknownEvent 事件不在预定义列表中
+var ErrUnknownEvent = errors.New("unknown telemetry event")
+
+// Summary 本地汇总的使用统计
+type Summary struct {
+	Since   time.Time                `json:"since"`   // 首次记录时间
+	Updated time.Time                `json:"updated"` // 最后记录时间
+	Counts  map[Event]int64          `json:"counts"`  // 各事件累计次数
+	Daily   map[string]map[Event]int `json:"daily"`   // 按日期（YYYY-MM-DD）汇总的次数
+}
+
+// Recorder 使用统计记录器接口
+type Recorder interface {
+	// Enabled 是否已启用统计
+	Enabled() bool
+
+	// SetEnabled 启用或关闭统计，关闭后不再记录但保留已有数据
+	SetEnabled(enabled bool)
+
+	// Record 记录一次事件，未启用时忽略
+	Record(event Event) error
+
+	// Summary 获取统计汇总
+	Summary() Summary
+
+	// Export 将统计汇总导出为JSON文件
+	Export(path string) error
+
+	// Reset 清除所有统计数据并删除本地文件
+	Reset() error
+}
+
+// RecorderImpl 使用统计记录器实现，数据保存在本地JSON文件中
+type RecorderImpl struct {
+	mu      sync.Mutex
+	path    string
+	enabled bool
+	summary Summary
+	now     func() time.Time
+}
+
+// dailyRetention 按日期汇总的保留天数
+const dailyRetention = 90
+
+// NewRecorder 创建使用统计记录器，dataDir为空时仅在内存中汇总
+func NewRecorder(dataDir string, enabled bool) *RecorderImpl {
+	r := &RecorderImpl{
+		enabled: enabled,
+		summary: newSummary(),
+		now:     time.Now,
+	}
+	if dataDir != "" {
+		r.path = filepath.Join(dataDir, "usage_stats.json")
+		r.load()
+	}
+	return r
+}
+
+// newSummary 创建空的统计汇总
+func newSummary() Summary {
+	return Summary{
+		Counts: make(map[Event]int64),
+		Daily:  make(map[string]map[Event]int),
+	}
+}
+
+// Enabled 是否已启用统计
+func (r *RecorderImpl) Enabled() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.enabled
+}
+
+// SetEnabled 启用或关闭统计
+func (r *RecorderImpl) SetEnabled(enabled bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.enabled = enabled
+}
+
+// Record 记录一次事件
+func (r *RecorderImpl) Record(event Event) error {
+	if !knownEvents[event] {
+		return fmt.Errorf("%w: %s", ErrUnknownEvent, event)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if !r.enabled {
+		return nil
+	}
+
+	now := r.now()
+	if r.summary.Since.IsZero() {
+		r.summary.Since = now
+	}
+	r.summary.Updated = now
+	r.summary.Counts[event]++
+
+	day := now.Format("2006-01-02")
+	if r.summary.Daily[day] == nil {
+		r.summary.Daily[day] = make(map[Event]int)
+	}
+	r.summary.Daily[day][event]++
+	r.pruneDaily(now)
+
+	return r.save()
+}
+
+// pruneDaily 删除超过保留天数的按日统计
+func (r *RecorderImpl) pruneDaily(now time.Time) {
+	cutoff := now.AddDate(0, 0, -dailyRetention).Format("2006-01-02")
+	for day := range r.summary.Daily {
+		if day < cutoff {
+			delete(r.summary.Daily, day)
+		}
+	}
+}
+
+// Summary 获取统计汇总的副本
+func (r *RecorderImpl) Summary() Summary {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	summary := newSummary()
+	summary.Since = r.summary.Since
+	summary.Updated = r.summary.Updated
+	for event, count := range r.summary.Counts {
+		summary.Counts[event] = count
+	}
+	for day, counts := range r.summary.Daily {
+		summary.Daily[day] = make(map[Event]int, len(counts))
+		for event, count := range counts {
+			summary.Daily[day][event] = count
+		}
+	}
+	return summary
+}
+
+// Export 将统计汇总导出为JSON文件
+func (r *RecorderImpl) Export(path string) error {
+	data, err := json.MarshalIndent(r.Summary(), "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal usage stats: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to export usage stats: %w", err)
+	}
+	return nil
+}
+
+// Reset 清除所有统计数据并删除本地文件
+func (r *RecorderImpl) Reset() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.summary = newSummary()
+	if r.path == "" {
+		return nil
+	}
+	if err := os.Remove(r.path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove usage stats: %w", err)
+	}
+	return nil
+}
+
+// Events 获取按名称排序的事件及累计次数
+func (s Summary) Events() []Event {
+	events := make([]Event, 0, len(s.Counts))
+	for event := range s.Counts {
+		events = append(events, event)
+	}
+	sort.Slice(events, func(i, j int) bool { return events[i] < events[j] })
+	return events
+}
+
+// load 从本地文件加载统计数据，文件损坏时从空数据开始
+func (r *RecorderImpl) load() {
+	data, err := os.ReadFile(r.path)
+	if err != nil {
+		return
+	}
+
+	summary := newSummary()
+	if err := json.Unmarshal(data, &summary); err != nil {
+		return
+	}
+	if summary.Counts == nil {
+		summary.Counts = make(map[Event]int64)
+	}
+	if summary.Daily == nil {
+		summary.Daily = make(map[string]map[Event]int)
+	}
+	r.summary = summary
+}
+
+// save 保存统计数据到本地文件
+func (r *RecorderImpl) save() error {
+	if r.path == "" {
+		return nil
+	}
+
+	data, err := json.MarshalIndent(r.summary, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal usage stats: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(r.path), 0755); err != nil {
+		return fmt.Errorf("failed to create data directory: %w", err)
+	}
+	if err := os.WriteFile(r.path, data, 0644); err != nil {
+		return fmt.Errorf("failed to save usage stats: %w", err)
+	}
+	return nil
+}
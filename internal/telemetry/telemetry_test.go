@@ -0,0 +1,59 @@
+package telemetry
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestRecorder 测试启用开关、持久化、导出与清除
+func TestRecorder(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+
+	recorder := NewRecorder(dir, false)
+	recorder.now = func() time.Time { return now }
+
+	// 默认关闭时不记录
+	require.NoError(t, recorder.Record(EventApplyProfile))
+	assert.Empty(t, recorder.Summary().Counts)
+	_, err := os.Stat(filepath.Join(dir, "usage_stats.json"))
+	assert.True(t, os.IsNotExist(err))
+
+	recorder.SetEnabled(true)
+	require.NoError(t, recorder.Record(EventApplyProfile))
+	require.NoError(t, recorder.Record(EventApplyProfile))
+	require.NoError(t, recorder.Record(EventImportProfile))
+	assert.ErrorIs(t, recorder.Record(Event("api.test")), ErrUnknownEvent)
+
+	summary := recorder.Summary()
+	assert.Equal(t, int64(2), summary.Counts[EventApplyProfile])
+	assert.Equal(t, 1, summary.Daily["2024-05-01"][EventImportProfile])
+	assert.Equal(t, []Event{EventApplyProfile, EventImportProfile}, summary.Events())
+
+	// 重新加载后保留数据，超过保留期的按日统计被清理
+	reloaded := NewRecorder(dir, true)
+	reloaded.now = func() time.Time { return now.AddDate(0, 0, dailyRetention+1) }
+	require.NoError(t, reloaded.Record(EventBackupHosts))
+	summary = reloaded.Summary()
+	assert.Equal(t, int64(2), summary.Counts[EventApplyProfile])
+	assert.NotContains(t, summary.Daily, "2024-05-01")
+
+	exportPath := filepath.Join(t.TempDir(), "usage.json")
+	require.NoError(t, reloaded.Export(exportPath))
+	data, err := os.ReadFile(exportPath)
+	require.NoError(t, err)
+	var exported Summary
+	require.NoError(t, json.Unmarshal(data, &exported))
+	assert.Equal(t, int64(1), exported.Counts[EventBackupHosts])
+
+	require.NoError(t, reloaded.Reset())
+	assert.Empty(t, reloaded.Summary().Counts)
+	_, err = os.Stat(filepath.Join(dir, "usage_stats.json"))
+	assert.True(t, os.IsNotExist(err))
+}
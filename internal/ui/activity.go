@@ -0,0 +1,198 @@
+package ui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/flyhigher139/mhost/internal/activity"
+	"github.com/flyhigher139/mhost/internal/healthcheck"
+	"github.com/flyhigher139/mhost/internal/host"
+	"github.com/flyhigher139/mhost/pkg/models"
+)
+
+// activitySource GUI记录的活动来源，守护进程记录的活动来源为daemon
+const activitySource = "gui"
+
+// 最近活动面板中的类别选项
+var activityCategories = []struct {
+	label    string
+	category activity.Category
+}{
+	{"全部", activity.CategoryAll},
+	{"Profile", activity.CategoryProfile},
+	{"Host条目", activity.CategoryEntry},
+	{"系统", activity.CategorySystem},
+	{"错误", activity.CategoryError},
+}
+
+// 最近活动面板中的时间范围选项
+var activityRanges = []string{"今天", "昨天", "最近7天", "全部"}
+
+// activityLabels 活动类型的显示名称
+var activityLabels = map[models.EventType]string{
+	models.EventProfileCreated:               "新建Profile",
+	models.EventProfileUpdated:               "修改Profile",
+	models.EventProfileDeleted:               "删除Profile",
+	models.EventProfileActivated:             "应用Profile",
+	models.EventProfileImported:              "导入Profile",
+	models.EventProfileSubscriptionRefreshed: "刷新订阅",
+	models.EventProfileInventorySynced:       "同步资产清单",
+	models.EventProfileGroupChanged:          "修改文件夹",
+	models.EventHostEntryAdded:               "添加Host条目",
+	models.EventHostEntryUpdated:             "修改Host条目",
+	models.EventHostEntryDeleted:             "删除Host条目",
+	models.EventHostEntryToggled:             "启用/禁用Host条目",
+	models.EventSystemHostsUpdated:           "写入Hosts文件",
+	models.EventSystemBackupCreated:          "备份Hosts文件",
+	models.EventSystemBackupRestored:         "恢复Hosts文件",
+	models.EventSystemConfigChanged:          "修改设置",
+	models.EventSystemDriftDetected:          "检测到手动修改",
+	models.EventSystemSnapshotSaved:          "导出Profile快照",
+	models.EventError:                        "错误",
+	models.EventWarning:                      "警告",
+}
+
+// recordActivity 记录一次操作到最近活动；记录失败不影响正常操作
+func (m *Manager) recordActivity(eventType models.EventType, data map[string]interface{}) {
+	if m.activity == nil {
+		return
+	}
+	_ = m.activity.Record(models.NewEvent(eventType, activitySource, data))
+}
+
+// recordDrift 定期验证发现的手动修改数量变化时记录一次活动，避免每次验证重复记录
+func (m *Manager) recordDrift(report *healthcheck.Report) {
+	drift := 0
+	if report != nil {
+		drift = report.DriftCount()
+	}
+	if drift > 0 && drift != m.lastDrift {
+		m.recordActivity(models.EventSystemDriftDetected, map[string]interface{}{"changes": drift})
+	}
+	m.lastDrift = drift
+}
+
+// profileActivityData Profile活动记录的数据，与守护进程记录的字段一致
+func profileActivityData(p *models.Profile) map[string]interface{} {
+	return map[string]interface{}{"profile_id": p.ID, "profile_name": p.Name}
+}
+
+// entryActivityData Host条目活动记录的数据
+func entryActivityData(p *models.Profile, entry *models.HostEntry) map[string]interface{} {
+	data := map[string]interface{}{"hostname": entry.Hostname, "ip": entry.IP, "enabled": entry.Enabled}
+	if p != nil {
+		data["profile_name"] = p.Name
+	}
+	return data
+}
+
+// applyActivityData 应用Profile活动记录的数据，包括变化的条目数
+func applyActivityData(result *host.ApplyResult) map[string]interface{} {
+	return map[string]interface{}{
+		"profile_id":   result.ProfileID,
+		"profile_name": result.ProfileName,
+		"added":        len(result.Added),
+		"changed":      len(result.Changed),
+		"removed":      len(result.Removed),
+	}
+}
+
+// activityFilter 按面板中的选项创建筛选条件
+func activityFilter(category activity.Category, timeRange, query string, now time.Time) activity.Filter {
+	var filter activity.Filter
+	switch timeRange {
+	case "今天":
+		filter = activity.Day(now)
+	case "昨天":
+		filter = activity.Day(now.AddDate(0, 0, -1))
+	case "最近7天":
+		filter = activity.Filter{Since: activity.Day(now.AddDate(0, 0, -6)).Since}
+	}
+	filter.Category = category
+	filter.Query = strings.TrimSpace(query)
+	return filter
+}
+
+// activityText 格式化一条活动，显示时间、类型和相关数据
+func activityText(event *models.Event) string {
+	label, ok := activityLabels[event.Type]
+	if !ok {
+		label = string(event.Type)
+	}
+
+	keys := make([]string, 0, len(event.Data))
+	for key := range event.Data {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	details := make([]string, 0, len(keys))
+	for _, key := range keys {
+		details = append(details, fmt.Sprintf("%s=%v", key, event.Data[key]))
+	}
+	return fmt.Sprintf("%s  [%s] %s  %s",
+		event.Timestamp.Local().Format("2006-01-02 15:04:05"), event.Source, label, strings.Join(details, " "))
+}
+
+// onShowActivity 显示最近活动，可按类别、时间范围和关键字筛选
+func (m *Manager) onShowActivity() {
+	var events []*models.Event
+
+	categoryLabels := make([]string, 0, len(activityCategories))
+	for _, option := range activityCategories {
+		categoryLabels = append(categoryLabels, option.label)
+	}
+	categorySelect := widget.NewSelect(categoryLabels, nil)
+	rangeSelect := widget.NewSelect(activityRanges, nil)
+	queryEntry := widget.NewEntry()
+	queryEntry.SetPlaceHolder("按Profile、主机名等筛选")
+	countLabel := widget.NewLabel("")
+
+	list := widget.NewList(
+		func() int { return len(events) },
+		func() fyne.CanvasObject { return widget.NewLabel("") },
+		func(id widget.ListItemID, obj fyne.CanvasObject) {
+			obj.(*widget.Label).SetText(activityText(events[id]))
+		},
+	)
+
+	reload := func() {
+		category := activity.CategoryAll
+		for _, option := range activityCategories {
+			if option.label == categorySelect.Selected {
+				category = option.category
+			}
+		}
+
+		filter := activityFilter(category, rangeSelect.Selected, queryEntry.Text, time.Now())
+		matched, err := m.activity.List(filter)
+		if err != nil {
+			m.showErrorDialog("加载最近活动失败", err)
+			return
+		}
+		events = matched
+		countLabel.SetText(fmt.Sprintf("共%d条", len(events)))
+		list.Refresh()
+	}
+
+	categorySelect.OnChanged = func(string) { reload() }
+	rangeSelect.OnChanged = func(string) { reload() }
+	queryEntry.OnChanged = func(string) { reload() }
+	categorySelect.SetSelected(activityCategories[0].label)
+	rangeSelect.SetSelected("最近7天")
+
+	filters := container.NewBorder(nil, nil,
+		container.NewHBox(categorySelect, rangeSelect), countLabel, queryEntry)
+	content := container.NewBorder(filters, nil, nil, nil, list)
+
+	d := dialog.NewCustom("最近活动", "关闭", content, m.window)
+	d.Resize(fyne.NewSize(720, 450))
+	d.Show()
+}
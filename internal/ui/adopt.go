@@ -0,0 +1,84 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2/dialog"
+
+	"github.com/flyhigher139/mhost/internal/profile"
+	"github.com/flyhigher139/mhost/pkg/hostsfile"
+	"github.com/flyhigher139/mhost/pkg/models"
+)
+
+// onAdoptSection 接管其他mHost安装写入的管理section：激活头部记录的本地Profile，本地没有时以section中的条目新建Profile
+func (m *Manager) onAdoptSection() {
+	managed, err := m.hostManager.GetManagedSection()
+	if err != nil {
+		m.showErrorDialog("读取失败", err)
+		return
+	}
+	if len(managed) == 0 {
+		dialog.ShowInformation("提示", "hosts文件中没有mHost管理section", m.window)
+		return
+	}
+	header, body := hostsfile.ParseSectionHeader(managed)
+	if header.ProfileID == "" {
+		dialog.ShowInformation("提示", "管理section不是由支持接管的mHost版本写入，可以使用\"导入hosts文件\"导入其中的条目", m.window)
+		return
+	}
+
+	var details strings.Builder
+	details.WriteString(fmt.Sprintf("Profile: %s\n", header.Profile))
+	if header.Author != "" {
+		details.WriteString(fmt.Sprintf("作者: %s\n", header.Author))
+	}
+	if !header.AppliedAt.IsZero() {
+		details.WriteString(fmt.Sprintf("应用时间: %s\n", header.AppliedAt.Local().Format(time.DateTime)))
+	}
+	if header.Version != "" {
+		details.WriteString(fmt.Sprintf("mHost版本: %s\n", header.Version))
+	}
+	if header.Check(body) == hostsfile.SectionModified {
+		details.WriteString("\n注意：section在写入后被修改过，将按当前内容接管\n")
+	}
+
+	message := fmt.Sprintf("hosts文件中的管理section由以下配置写入：\n\n%s\n本地存在该Profile时将其激活，否则以section中的条目新建Profile。hosts文件不会被修改，确定接管吗？", details.String())
+	dialog.ShowConfirm("接管管理section", message, func(confirmed bool) {
+		if !confirmed {
+			return
+		}
+		adoption, err := profile.AdoptManagedSection(m.profileManager, managed)
+		if err != nil {
+			m.showErrorDialog("接管失败", err)
+			return
+		}
+
+		p := adoption.Profile
+		if adoption.Created {
+			m.recordActivity(models.EventProfileImported, map[string]interface{}{
+				"profile_id":   p.ID,
+				"profile_name": p.Name,
+				"source":       m.hostManager.GetHostsFilePath(),
+				"entries":      len(p.Entries),
+			})
+		}
+		if adoption.Activated {
+			m.recordActivity(models.EventProfileActivated, map[string]interface{}{
+				"profile_id":   p.ID,
+				"profile_name": p.Name,
+			})
+		}
+		m.refreshProfileList()
+
+		switch {
+		case adoption.Created:
+			m.statusBar.SetText(fmt.Sprintf("已接管管理section，新建并激活了Profile '%s'", p.Name))
+		case adoption.Activated:
+			m.statusBar.SetText(fmt.Sprintf("已接管管理section，激活了Profile '%s'", p.Name))
+		default:
+			m.statusBar.SetText(fmt.Sprintf("管理section对应的Profile '%s' 已经激活", p.Name))
+		}
+	}, m.window)
+}
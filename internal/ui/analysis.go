@@ -0,0 +1,140 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/flyhigher139/mhost/internal/analyzer"
+	"github.com/flyhigher139/mhost/internal/telemetry"
+)
+
+// analysisInterval 定时分析间隔
+const analysisInterval = 24 * time.Hour
+
+// startAnalyzer 创建条目分析服务并启动定时分析
+func (m *Manager) startAnalyzer() {
+	options := analyzer.DefaultOptions()
+	if m.hitCounter != nil {
+		options.HitCounter = m.hitCounter
+	}
+
+	m.analyzer = analyzer.NewAnalyzer(m.profileManager, options)
+	m.analyzer.OnReport(func(report *analyzer.Report) {
+		if total := report.Total(); total > 0 {
+			m.statusBar.SetText(fmt.Sprintf("条目分析发现%d个问题条目，可在 工具 > 条目分析报告 中查看", total))
+		}
+	})
+
+	if err := m.analyzer.Start(analysisInterval); err != nil {
+		fmt.Printf("Failed to start analyzer: %v\n", err)
+	}
+}
+
+// stopAnalyzer 停止定时分析
+func (m *Manager) stopAnalyzer() {
+	if m.analyzer != nil {
+		m.analyzer.Stop()
+	}
+}
+
+// onShowAnalysisReport 运行条目分析并显示报告
+func (m *Manager) onShowAnalysisReport() {
+	if m.analyzer == nil {
+		return
+	}
+
+	progressDialog := dialog.NewProgressInfinite("条目分析", "正在分析hosts条目，请稍候...", m.window)
+	progressDialog.Show()
+
+	go func() {
+		report, err := m.analyzer.Analyze()
+		progressDialog.Hide()
+		if err != nil {
+			m.showErrorDialog("分析失败", err)
+			return
+		}
+		m.recordUsage(telemetry.EventAnalyzeEntries)
+		m.showAnalysisReport(report)
+	}()
+}
+
+// showAnalysisReport 显示分析报告，每类问题提供一键清理
+func (m *Manager) showAnalysisReport(report *analyzer.Report) {
+	if report.Total() == 0 {
+		dialog.ShowInformation("条目分析", "没有发现过期、不可达或重复的条目", m.window)
+		return
+	}
+
+	var d dialog.Dialog
+	section := func(title string, findings []analyzer.Finding) fyne.CanvasObject {
+		var lines strings.Builder
+		for _, finding := range findings {
+			lines.WriteString(fmt.Sprintf("[%s] %s -> %s (%s)",
+				finding.ProfileName, finding.Entry.Hostname, finding.Entry.IP, finding.Reason))
+			if finding.Owner != "" {
+				lines.WriteString(" 负责人: " + finding.Owner)
+			}
+			if finding.Ticket != "" {
+				lines.WriteString(" 工单: " + finding.Ticket)
+			}
+			lines.WriteString("\n")
+		}
+		if len(findings) == 0 {
+			lines.WriteString("无")
+		}
+
+		cleanup := widget.NewButton("清理", func() {
+			m.cleanupFindings(title, findings, d)
+		})
+		if len(findings) == 0 {
+			cleanup.Disable()
+		}
+
+		return widget.NewCard(fmt.Sprintf("%s (%d)", title, len(findings)), "",
+			container.NewBorder(nil, nil, nil, cleanup, widget.NewLabel(lines.String())))
+	}
+
+	content := container.NewVBox(
+		widget.NewLabel(fmt.Sprintf("生成时间: %s", report.GeneratedAt.Format(time.DateTime))),
+		section("过期条目", report.Stale),
+		section("不可达条目", report.Unreachable),
+		section("重复条目", report.Duplicates),
+	)
+
+	scroll := container.NewScroll(content)
+	scroll.SetMinSize(fyne.NewSize(550, 400))
+
+	d = dialog.NewCustom("条目分析报告", "关闭", scroll, m.window)
+	d.Show()
+}
+
+// cleanupFindings 确认后从Profile中移除问题条目
+func (m *Manager) cleanupFindings(title string, findings []analyzer.Finding, reportDialog dialog.Dialog) {
+	message := fmt.Sprintf("确定要从Profile中删除%d个%s吗？\n\n此操作不可撤销。", len(findings), title)
+	dialog.ShowConfirm("确认清理", message, func(confirmed bool) {
+		if !confirmed {
+			return
+		}
+
+		removed, err := m.analyzer.Cleanup(findings)
+		if err != nil {
+			m.showErrorDialog("清理失败", err)
+			return
+		}
+
+		if reportDialog != nil {
+			reportDialog.Hide()
+		}
+		if err := m.loadInitialData(); err != nil {
+			m.showErrorDialog("刷新失败", err)
+			return
+		}
+		m.statusBar.SetText(fmt.Sprintf("已清理%d个%s", removed, title))
+	}, m.window)
+}
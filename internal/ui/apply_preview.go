@@ -0,0 +1,119 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/flyhigher139/mhost/internal/host"
+	"github.com/flyhigher139/mhost/pkg/hostsfile"
+)
+
+// previewContextLines 应用预览中每处改动前后显示的未变化行数
+const previewContextLines = 2
+
+// lineDiffImportance 逐行差异中各行的颜色，省略标记与未变化的行相同
+func lineDiffImportance(line string) widget.Importance {
+	switch {
+	case strings.HasPrefix(line, string(hostsfile.LineAdded)+" "):
+		return widget.SuccessImportance
+	case strings.HasPrefix(line, string(hostsfile.LineRemoved)+" "):
+		return widget.DangerImportance
+	default:
+		return widget.MediumImportance
+	}
+}
+
+// applyPreviewSummary 应用预览的说明，包括条目和行的改动统计以及应用后hosts文件的规模
+func applyPreviewSummary(preview *host.Preview, backup bool) string {
+	added, removed := preview.LineStats()
+	var b strings.Builder
+	fmt.Fprintf(&b, "确定要应用Profile '%s' 吗？\n\n", preview.ProfileName)
+	if backup {
+		b.WriteString("应用前将备份当前hosts文件。")
+	}
+	fmt.Fprintf(&b, "写入%s后，管理section中新增%d个、删除%d个、修改%d个条目（hosts文件新增%d行、删除%d行）。",
+		preview.HostsPath, len(preview.Entries.Added), len(preview.Entries.Removed), len(preview.Entries.Changed), added, removed)
+	if !preview.HasChanges() {
+		b.WriteString("\n条目没有变化，只更新头部的应用时间。")
+	}
+	b.WriteString("\n" + applyPreviewSize(preview))
+	return b.String()
+}
+
+// applyPreviewSize 应用前后hosts文件的条目数和大小，配置了规模限制时一并显示
+func applyPreviewSize(preview *host.Preview) string {
+	text := fmt.Sprintf("应用后hosts文件共%d个条目、%s（当前%d个条目、%s）",
+		preview.After.Entries, formatBytes(preview.After.Size), preview.Before.Entries, formatBytes(preview.Before.Size))
+
+	var limits []string
+	if preview.Limits.MaxEntries > 0 {
+		limits = append(limits, fmt.Sprintf("%d个条目", preview.Limits.MaxEntries))
+	}
+	if preview.Limits.MaxFileSize > 0 {
+		limits = append(limits, formatBytes(preview.Limits.MaxFileSize))
+	}
+	if len(limits) > 0 {
+		text += "，上限为" + strings.Join(limits, "、")
+	}
+	return text + "。"
+}
+
+// applyPreviewWarning 应用后超过规模限制或条目违反校验规则时的警告，都没有时为空
+func applyPreviewWarning(preview *host.Preview) string {
+	var warnings []string
+	if len(preview.Violations) > 0 {
+		warnings = append(warnings, "应用后将超过规模限制，部分系统解析器在文件过大或行过长时会变慢或工作异常：\n- "+
+			strings.Join(preview.Violations, "\n- "))
+	}
+	if len(preview.RuleViolations) > 0 {
+		warnings = append(warnings, "条目违反了团队的校验规则，违反错误级别的规则时无法应用：\n"+
+			ruleViolationText(preview.RuleViolations))
+	}
+	return strings.Join(warnings, "\n\n")
+}
+
+// newApplyPreviewView 创建应用预览视图：上方为说明和超限警告，下方为hosts文件的逐行差异
+func newApplyPreviewView(preview *host.Preview, backup bool) fyne.CanvasObject {
+	lines := hostsfile.FormatLineDiff(preview.Diff, previewContextLines)
+	list := widget.NewList(
+		func() int { return len(lines) },
+		func() fyne.CanvasObject {
+			label := widget.NewLabel("")
+			label.TextStyle = fyne.TextStyle{Monospace: true}
+			return label
+		},
+		func(id widget.ListItemID, obj fyne.CanvasObject) {
+			label := obj.(*widget.Label)
+			label.Importance = lineDiffImportance(lines[id])
+			label.SetText(lines[id])
+		},
+	)
+
+	summary := widget.NewLabel(applyPreviewSummary(preview, backup))
+	summary.Wrapping = fyne.TextWrapWord
+	top := container.NewVBox(summary)
+	if warning := applyPreviewWarning(preview); warning != "" {
+		label := widget.NewLabel(warning)
+		label.Wrapping = fyne.TextWrapWord
+		label.Importance = widget.DangerImportance
+		top.Add(label)
+	}
+	return container.NewBorder(top, nil, nil, nil, list)
+}
+
+// showApplyPreview 显示应用预览，确认后应用当前Profile
+func (m *Manager) showApplyPreview(preview *host.Preview) {
+	backup := m.appConfig != nil && m.appConfig.Security.BackupBeforeChange
+	d := dialog.NewCustomConfirm("确认应用Profile", "应用", "取消", newApplyPreviewView(preview, backup), func(confirmed bool) {
+		if confirmed {
+			m.applyCurrentProfile(host.ApplyOptions{})
+		}
+	}, m.window)
+	d.Resize(fyne.NewSize(800, 550))
+	d.Show()
+}
@@ -0,0 +1,143 @@
+package ui
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/flyhigher139/mhost/internal/host"
+	"github.com/flyhigher139/mhost/pkg/hostsfile"
+)
+
+// applyHistoryLimit 应用历史对话框中显示的最大记录数
+const applyHistoryLimit = 20
+
+// recordApply 将应用结果写入应用历史
+func (m *Manager) recordApply(result *host.ApplyResult) {
+	if m.history == nil || result == nil {
+		return
+	}
+	if err := m.history.Record(result); err != nil {
+		m.statusBar.SetText(fmt.Sprintf("记录应用历史失败: %v", err))
+	}
+}
+
+// applySummaryText 生成应用结果的计数摘要
+func applySummaryText(result *host.ApplyResult) string {
+	return fmt.Sprintf("新增%d个，移除%d个，修改%d个，未变化%d个",
+		len(result.Added), len(result.Removed), len(result.Changed), result.Unchanged)
+}
+
+// applyDiffText 生成应用结果的差异文本
+func applyDiffText(result *host.ApplyResult) string {
+	if !result.HasChanges() {
+		return "hosts文件中的条目没有变化"
+	}
+
+	var diff strings.Builder
+	for _, entry := range result.Added {
+		fmt.Fprintf(&diff, "+ %s\n", hostsfile.RenderEntry(entry))
+	}
+	for _, entry := range result.Removed {
+		fmt.Fprintf(&diff, "- %s\n", hostsfile.RenderEntry(entry))
+	}
+	for _, change := range result.Changed {
+		fmt.Fprintf(&diff, "- %s\n+ %s\n", hostsfile.RenderEntry(change.Actual), hostsfile.RenderEntry(change.Expected))
+	}
+	return diff.String()
+}
+
+// showApplySummary 显示应用结果：改动计数、差异和应用前的备份
+func (m *Manager) showApplySummary(result *host.ApplyResult) {
+	diffText := widget.NewMultiLineEntry()
+	diffText.SetText(applyDiffText(result))
+	diffText.Disable()
+	diffText.SetMinRowsVisible(6)
+
+	content := container.NewVBox(
+		widget.NewLabel(fmt.Sprintf("Profile '%s' 已应用到 %s", result.ProfileName, result.HostsPath)),
+		widget.NewLabel(applySummaryText(result)),
+		widget.NewLabel(fmt.Sprintf("写入%d行，耗时%dms", result.LinesWritten, result.Duration.Milliseconds())),
+		widget.NewAccordion(widget.NewAccordionItem("查看差异", diffText)),
+	)
+	for _, warning := range result.Warnings {
+		content.Add(widget.NewLabel("⚠ " + warning))
+	}
+	if result.BackupPath != "" {
+		content.Add(container.NewHBox(
+			widget.NewLabel("应用前备份:"),
+			widget.NewHyperlink(result.BackupPath, &url.URL{Scheme: "file", Path: result.BackupPath}),
+		))
+	}
+
+	d := dialog.NewCustom("应用完成", "关闭", content, m.window)
+	d.Resize(fyne.NewSize(520, 0))
+	d.Show()
+}
+
+// onShowApplyHistory 显示最近的应用历史
+func (m *Manager) onShowApplyHistory() {
+	history, err := m.history.List()
+	if err != nil {
+		m.showErrorDialog("加载应用历史失败", err)
+		return
+	}
+	if len(history) == 0 {
+		dialog.ShowInformation("应用历史", "暂无应用记录", m.window)
+		return
+	}
+	if len(history) > applyHistoryLimit {
+		history = history[:applyHistoryLimit]
+	}
+
+	list := widget.NewList(
+		func() int { return len(history) },
+		func() fyne.CanvasObject { return widget.NewLabel("") },
+		func(id widget.ListItemID, obj fyne.CanvasObject) {
+			result := history[id]
+			obj.(*widget.Label).SetText(fmt.Sprintf("%s  %s  %s",
+				result.AppliedAt.Format("2006-01-02 15:04:05"), result.ProfileName, applySummaryText(result)))
+		},
+	)
+	list.OnSelected = func(id widget.ListItemID) {
+		m.showApplySummary(history[id])
+		list.UnselectAll()
+	}
+
+	d := dialog.NewCustom("应用历史", "关闭", container.NewGridWrap(fyne.NewSize(600, 320), list), m.window)
+	d.Show()
+}
+
+// showValidationReport 显示hosts文件验证报告，错误在前、警告在后，便于一次修复全部问题
+func (m *Manager) showValidationReport(result *host.ValidationResult) {
+	errors, warnings := result.Errors(), result.Warnings()
+
+	summary := fmt.Sprintf("%s: 共%d行，%d个条目，%d个错误，%d个警告",
+		result.HostsPath, result.Lines, result.Entries, len(errors), len(warnings))
+	if len(result.Issues) == 0 {
+		dialog.ShowInformation("验证Hosts文件", summary+"\n\n未发现问题", m.window)
+		return
+	}
+
+	var report strings.Builder
+	for _, issue := range errors {
+		fmt.Fprintf(&report, "✖ %s\n", issue)
+	}
+	for _, issue := range warnings {
+		fmt.Fprintf(&report, "⚠ %s\n", issue)
+	}
+
+	reportText := widget.NewMultiLineEntry()
+	reportText.SetText(report.String())
+	reportText.Disable()
+	reportText.SetMinRowsVisible(10)
+
+	d := dialog.NewCustom("验证Hosts文件", "关闭", container.NewBorder(widget.NewLabel(summary), nil, nil, nil, reportText), m.window)
+	d.Resize(fyne.NewSize(640, 0))
+	d.Show()
+}
@@ -0,0 +1,73 @@
+package ui
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/flyhigher139/mhost/internal/telemetry"
+)
+
+// debouncer 合并短时间内的多次触发，仅在最后一次触发后延迟执行
+type debouncer struct {
+	mu    sync.Mutex
+	delay time.Duration
+	timer *time.Timer
+}
+
+// newDebouncer 创建防抖器
+func newDebouncer(delay time.Duration) *debouncer {
+	return &debouncer{delay: delay}
+}
+
+// Trigger 触发一次，重置等待时间
+func (d *debouncer) Trigger(fn func()) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.timer != nil {
+		d.timer.Stop()
+	}
+	d.timer = time.AfterFunc(d.delay, fn)
+}
+
+// Stop 取消尚未执行的触发
+func (d *debouncer) Stop() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.timer != nil {
+		d.timer.Stop()
+		d.timer = nil
+	}
+}
+
+// scheduleAutoApply 保存激活Profile的修改后，按设置延迟重新应用管理section
+func (m *Manager) scheduleAutoApply() {
+	if m.appConfig == nil || !m.appConfig.UI.ApplyOnSave {
+		return
+	}
+	if current := m.controller.CurrentProfile(); current == nil || !current.IsActive {
+		return
+	}
+
+	if m.autoApply == nil {
+		m.autoApply = newDebouncer(time.Duration(m.appConfig.UI.ApplyOnSaveDelay) * time.Millisecond)
+	}
+
+	m.autoApply.Trigger(func() {
+		// 执行时重新获取激活的Profile，确保写入最新内容；同时激活多个Profile时重新叠加应用
+		result, err := m.controller.ReapplyActive()
+		if err != nil {
+			m.statusBar.SetText(fmt.Sprintf("自动应用Profile失败: %v", err))
+			return
+		}
+		if result == nil {
+			return
+		}
+
+		m.recordUsage(telemetry.EventAutoApply)
+		m.recordApply(result)
+		m.statusBar.SetText(fmt.Sprintf("Profile '%s' 已自动应用: %s", result.ProfileName, applySummaryText(result)))
+	})
+}
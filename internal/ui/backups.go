@@ -0,0 +1,378 @@
+package ui
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/flyhigher139/mhost/internal/config"
+	"github.com/flyhigher139/mhost/internal/host"
+)
+
+// 备份历史标签筛选中的固定选项
+const (
+	backupFilterAll        = "全部标签"
+	backupFilterMilestones = "里程碑"
+)
+
+// 备份历史类型筛选的选项
+const (
+	backupKindAll       = "全部类型"
+	backupKindManual    = "手动"
+	backupKindAutomatic = "自动"
+)
+
+// backupDateLayout 备份历史日期范围的输入格式
+const backupDateLayout = "2006-01-02"
+
+// backupFilter 备份历史的筛选条件，各字段为空时不按该条件筛选
+type backupFilter struct {
+	From time.Time // 起始日期（含）
+	To   time.Time // 结束日期（含当天）
+	Kind string
+	Tag  string
+	Text string // 在名称、里程碑和标签中不区分大小写搜索
+}
+
+// parseBackupDate 解析日期范围中的日期，空白时返回零值
+func parseBackupDate(text string) (time.Time, error) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return time.Time{}, nil
+	}
+	date, err := time.ParseInLocation(backupDateLayout, text, time.Local)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("日期格式应为YYYY-MM-DD: %q", text)
+	}
+	return date, nil
+}
+
+// match 备份记录是否满足全部筛选条件
+func (f backupFilter) match(record host.BackupRecord) bool {
+	if !f.From.IsZero() && record.CreatedAt.Before(f.From) {
+		return false
+	}
+	if !f.To.IsZero() && !record.CreatedAt.Before(f.To.AddDate(0, 0, 1)) {
+		return false
+	}
+	switch f.Kind {
+	case backupKindManual:
+		if record.Automatic {
+			return false
+		}
+	case backupKindAutomatic:
+		if !record.Automatic {
+			return false
+		}
+	}
+	switch f.Tag {
+	case "", backupFilterAll:
+	case backupFilterMilestones:
+		if record.Milestone == "" {
+			return false
+		}
+	default:
+		if !record.HasTag(f.Tag) {
+			return false
+		}
+	}
+	if text := strings.ToLower(strings.TrimSpace(f.Text)); text != "" {
+		fields := append([]string{record.Name, record.Milestone}, record.Tags...)
+		for _, field := range fields {
+			if strings.Contains(strings.ToLower(field), text) {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+// onShowBackupHistory 显示备份历史，逐个验证备份并可导出为CSV或JSON
+func (m *Manager) onShowBackupHistory() {
+	progressDialog := dialog.NewProgressInfinite("备份历史", "正在读取并验证备份文件，请稍候...", m.window)
+	progressDialog.Show()
+
+	go func() {
+		history, err := host.LoadBackupHistory(m.workspace.BackupDir)
+		progressDialog.Hide()
+		if err != nil {
+			m.showErrorDialog("读取备份失败", err)
+			return
+		}
+		m.showBackupHistory(history)
+	}()
+}
+
+// showBackupHistory 显示备份历史列表和汇总统计，可按日期范围、类型、标签和文本筛选，批量删除筛选结果，
+// 或由选中的备份创建Profile
+func (m *Manager) showBackupHistory(history *host.BackupHistory) {
+	summary := widget.NewLabel(formatBackupSummary(&history.Summary))
+	summary.Wrapping = fyne.TextWrapWord
+
+	records := history.Records
+	list := widget.NewList(
+		func() int { return len(records) },
+		func() fyne.CanvasObject { return widget.NewLabel("") },
+		func(id widget.ListItemID, obj fyne.CanvasObject) {
+			obj.(*widget.Label).SetText(formatBackupRecord(records[id]))
+		},
+	)
+	selected := -1
+	list.OnSelected = func(id widget.ListItemID) { selected = id }
+	list.OnUnselected = func(widget.ListItemID) { selected = -1 }
+
+	fromEntry := widget.NewEntry()
+	fromEntry.SetPlaceHolder("起始 YYYY-MM-DD")
+	toEntry := widget.NewEntry()
+	toEntry.SetPlaceHolder("结束 YYYY-MM-DD")
+	searchEntry := widget.NewEntry()
+	searchEntry.SetPlaceHolder("搜索名称、里程碑或标签")
+	kindSelect := widget.NewSelect([]string{backupKindAll, backupKindManual, backupKindAutomatic}, nil)
+	tagSelect := widget.NewSelect(backupTagOptions(history.Records), nil)
+	status := widget.NewLabel("")
+
+	applyFilter := func() {
+		filter := backupFilter{Kind: kindSelect.Selected, Tag: tagSelect.Selected, Text: searchEntry.Text}
+		var err error
+		if filter.From, err = parseBackupDate(fromEntry.Text); err == nil {
+			filter.To, err = parseBackupDate(toEntry.Text)
+		}
+		if err != nil {
+			status.SetText(err.Error())
+			return
+		}
+		records = filterBackupRecords(history.Records, filter)
+		list.UnselectAll()
+		list.Refresh()
+		status.SetText(fmt.Sprintf("显示 %d / %d 个备份", len(records), len(history.Records)))
+	}
+	for _, entry := range []*widget.Entry{fromEntry, toEntry, searchEntry} {
+		entry.OnChanged = func(string) { applyFilter() }
+	}
+	kindSelect.OnChanged = func(string) { applyFilter() }
+	tagSelect.OnChanged = func(string) { applyFilter() }
+	kindSelect.SetSelected(backupKindAll)
+	tagSelect.SetSelected(backupFilterAll)
+
+	var d dialog.Dialog
+	profileButton := widget.NewButton("创建Profile", func() {
+		if selected < 0 || selected >= len(records) {
+			dialog.ShowInformation("提示", "请先选择一个备份", m.window)
+			return
+		}
+		m.createProfileFromBackup(records[selected])
+	})
+	deleteButton := widget.NewButton("删除筛选结果", func() {
+		if len(records) == 0 {
+			dialog.ShowInformation("提示", "没有符合筛选条件的备份", m.window)
+			return
+		}
+		m.confirmDeleteBackups(records, func() {
+			d.Hide()
+			m.onShowBackupHistory()
+		})
+	})
+
+	export := func(format host.ExportFormat) func() {
+		return func() {
+			fileName := fmt.Sprintf("mhost-backups-%s.%s", history.ExportedAt.Format("20060102_150405"), format)
+			m.saveExport(fileName, func(path string) error {
+				file, err := os.Create(path)
+				if err != nil {
+					return err
+				}
+				defer file.Close()
+				return history.Export(file, format)
+			})
+		}
+	}
+	buttons := container.NewHBox(
+		widget.NewButton("导出CSV", export(host.ExportCSV)),
+		widget.NewButton("导出JSON", export(host.ExportJSON)),
+		layout.NewSpacer(),
+		profileButton,
+		deleteButton,
+	)
+
+	filters := container.NewVBox(
+		container.NewGridWithColumns(4, fromEntry, toEntry, kindSelect, tagSelect),
+		container.NewBorder(nil, nil, nil, status, searchEntry),
+	)
+	header := container.NewVBox(summary, filters)
+	content := container.NewBorder(header, buttons, nil, nil, list)
+	d = dialog.NewCustom("备份历史", "关闭", content, m.window)
+	d.Resize(fyne.NewSize(800, 500))
+	d.Show()
+}
+
+// 从备份创建Profile时可选的解析范围
+const (
+	backupSectionManaged = "仅管理section"
+	backupSectionWhole   = "整个文件"
+)
+
+// createProfileFromBackup 将备份的管理section或整个文件解析为新Profile，在导入预览中确认后创建
+func (m *Manager) createProfileFromBackup(record host.BackupRecord) {
+	scope := widget.NewRadioGroup([]string{backupSectionManaged, backupSectionWhole}, nil)
+	scope.SetSelected(backupSectionManaged)
+	content := widget.NewForm(&widget.FormItem{
+		Text:     "解析范围",
+		Widget:   scope,
+		HintText: "备份中没有管理section时解析整个文件",
+	})
+
+	dialog.ShowCustomConfirm("从备份创建Profile", "下一步", "取消", content, func(confirmed bool) {
+		if !confirmed {
+			return
+		}
+		backup := host.BackupFile{
+			Name:        record.Name,
+			Path:        record.Path,
+			Size:        record.Size,
+			ModTime:     record.CreatedAt,
+			Automatic:   record.Automatic,
+			Delta:       record.Delta,
+			BackupLabel: record.BackupLabel,
+		}
+		profile, err := host.ProfileFromBackup(backup, scope.Selected != backupSectionWhole)
+		if err != nil {
+			m.showErrorDialog("解析备份失败", err)
+			return
+		}
+		m.showImportPreview(profile, record.Path)
+	}, m.window)
+}
+
+// confirmDeleteBackups 确认后批量删除备份，被保留的增量备份依赖的完整备份不会删除
+func (m *Manager) confirmDeleteBackups(records []host.BackupRecord, onDeleted func()) {
+	names := make([]string, 0, len(records))
+	milestones := 0
+	for _, record := range records {
+		names = append(names, record.Name)
+		if record.Milestone != "" {
+			milestones++
+		}
+	}
+	message := fmt.Sprintf("确定要删除筛选出的%d个备份吗？此操作不可撤销。", len(names))
+	if milestones > 0 {
+		message += fmt.Sprintf("\n\n其中包含%d个里程碑备份。", milestones)
+	}
+	dialog.ShowConfirm("删除备份", message, func(confirmed bool) {
+		if !confirmed {
+			return
+		}
+		go func() {
+			removed, err := host.DeleteBackups(m.workspace.BackupDir, names)
+			if err != nil {
+				m.showErrorDialog("删除备份失败", fmt.Errorf("已删除%d个备份: %w", len(removed), err))
+				return
+			}
+			text := fmt.Sprintf("已删除%d个备份", len(removed))
+			if skipped := len(names) - len(removed); skipped > 0 {
+				text += fmt.Sprintf("，%d个被其他增量备份依赖的完整备份已保留", skipped)
+			}
+			m.statusBar.SetText(text)
+			onDeleted()
+		}()
+	}, m.window)
+}
+
+// formatBackupSummary 格式化备份历史的汇总统计
+func formatBackupSummary(summary *host.BackupSummary) string {
+	if summary.TotalBackups == 0 {
+		return "没有备份"
+	}
+	text := fmt.Sprintf("共 %d 个备份，%s（自动 %d，手动 %d），有效 %d，无效 %d",
+		summary.TotalBackups, formatBytes(summary.TotalSize), summary.AutomaticBackups, summary.ManualBackups,
+		summary.ValidBackups, summary.InvalidBackups)
+	if summary.OldestBackup != nil && summary.NewestBackup != nil {
+		text += fmt.Sprintf("\n时间范围: %s 至 %s", summary.OldestBackup.Format("2006-01-02 15:04"), summary.NewestBackup.Format("2006-01-02 15:04"))
+	}
+	return text
+}
+
+// formatBackupRecord 格式化备份历史中的一行
+func formatBackupRecord(record host.BackupRecord) string {
+	kind := "手动"
+	if record.Automatic {
+		kind = "自动"
+	}
+	if record.Delta {
+		kind += "增量"
+	}
+	status := "✓"
+	if !record.Valid {
+		status = "✗ " + record.Error
+	}
+	text := fmt.Sprintf("%s  %s  %s  %s  %s", record.CreatedAt.Format(time.DateTime), kind, formatBytes(record.Size), record.Name, status)
+	if record.Milestone != "" {
+		text += "  ★ " + record.Milestone
+	}
+	if len(record.Tags) > 0 {
+		text += "  [" + strings.Join(record.Tags, ", ") + "]"
+	}
+	return text
+}
+
+// parseBackupTags 解析逗号分隔的备份标签
+func parseBackupTags(text string) []string {
+	return host.NormalizeTags(strings.FieldsFunc(text, func(r rune) bool { return r == ',' || r == '，' }))
+}
+
+// backupTagOptions 备份历史标签筛选的选项：全部、里程碑以及出现过的标签
+func backupTagOptions(records []host.BackupRecord) []string {
+	options := []string{backupFilterAll, backupFilterMilestones}
+	var tags []string
+	for _, record := range records {
+		tags = append(tags, record.Tags...)
+	}
+	tags = host.NormalizeTags(tags)
+	sort.Strings(tags)
+	return append(options, tags...)
+}
+
+// filterBackupRecords 按筛选条件筛选备份记录
+func filterBackupRecords(records []host.BackupRecord, filter backupFilter) []host.BackupRecord {
+	var filtered []host.BackupRecord
+	for _, record := range records {
+		if filter.match(record) {
+			filtered = append(filtered, record)
+		}
+	}
+	return filtered
+}
+
+// formatBytes 将字节数格式化为B、KB、MB或GB
+func formatBytes(size int64) string {
+	switch {
+	case size < 1024:
+		return fmt.Sprintf("%d B", size)
+	case size < 1024*1024:
+		return fmt.Sprintf("%.1f KB", float64(size)/1024)
+	case size < 1024*1024*1024:
+		return fmt.Sprintf("%.1f MB", float64(size)/(1024*1024))
+	default:
+		return fmt.Sprintf("%.1f GB", float64(size)/(1024*1024*1024))
+	}
+}
+
+// backupPathWarnings 设置的备份目录位于临时目录或云同步目录时的提示
+func backupPathWarnings(check *config.BackupPathCheck) []string {
+	var warnings []string
+	if check.Temporary {
+		warnings = append(warnings, fmt.Sprintf("备份目录 %s 位于系统临时目录，其中的备份可能在重启或系统清理时被删除。", check.Path))
+	}
+	if check.CloudService != "" {
+		warnings = append(warnings, fmt.Sprintf("备份目录 %s 位于%s同步目录，备份会被上传到云端，文件也可能被移出本地导致恢复时不可用。", check.Path, check.CloudService))
+	}
+	return warnings
+}
@@ -0,0 +1,161 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/flyhigher139/mhost/internal/host"
+	"github.com/flyhigher139/mhost/pkg/models"
+)
+
+// 设置中退出时还原hosts文件的选项
+const (
+	restoreOnQuitOff      = "不还原"
+	restoreOnQuitClear    = "移除mHost条目"
+	restoreOnQuitBaseline = "恢复系统初始hosts"
+)
+
+// restoreOnQuitLabel 将配置中的还原方式转换为设置中的选项
+func restoreOnQuitLabel(mode string) string {
+	switch mode {
+	case models.RestoreOnQuitClear:
+		return restoreOnQuitClear
+	case models.RestoreOnQuitBaseline:
+		return restoreOnQuitBaseline
+	}
+	return restoreOnQuitOff
+}
+
+// restoreOnQuitMode 将设置中的选项转换为配置中的还原方式
+func restoreOnQuitMode(label string) string {
+	switch label {
+	case restoreOnQuitClear:
+		return models.RestoreOnQuitClear
+	case restoreOnQuitBaseline:
+		return models.RestoreOnQuitBaseline
+	}
+	return ""
+}
+
+// baselinePath 获取系统初始hosts文件路径
+func (m *Manager) baselinePath() (string, error) {
+	return host.SharedBaselinePath(m.workspaces)
+}
+
+// captureBaseline 首次运行时保存未经修改的hosts文件作为系统初始状态，已保存时不再覆盖
+func (m *Manager) captureBaseline() {
+	baseline, created, err := host.CaptureSharedBaseline(m.hostManager, m.workspaces)
+	if err != nil {
+		fmt.Printf("Failed to capture hosts baseline: %v\n", err)
+		return
+	}
+	if created {
+		m.recordActivity(models.EventSystemBackupCreated, map[string]interface{}{"path": baseline.Path, "baseline": true})
+	}
+}
+
+// onShowBaseline 预览系统初始hosts文件，可随时将hosts文件恢复为该状态
+func (m *Manager) onShowBaseline() {
+	path, err := m.baselinePath()
+	if err != nil {
+		m.showErrorDialog("读取系统初始hosts失败", err)
+		return
+	}
+	baseline, err := host.LoadBaseline(path)
+	if err != nil {
+		m.showErrorDialog("读取系统初始hosts失败", err)
+		return
+	}
+
+	profile := baseline.Profile()
+	header := widget.NewLabel(fmt.Sprintf("%s，保存于 %s，共%d个条目\n%s",
+		host.BaselineProfileName, baseline.CapturedAt.Format("2006-01-02 15:04:05"), len(profile.Entries), baseline.Path))
+	header.Wrapping = fyne.TextWrapWord
+
+	preview := widget.NewMultiLineEntry()
+	preview.SetText(strings.Join(baseline.Lines, "\n"))
+	preview.Disable()
+
+	var d dialog.Dialog
+	restoreButton := widget.NewButton("恢复为系统初始hosts", func() {
+		d.Hide()
+		m.restoreBaseline(baseline)
+	})
+
+	content := container.NewBorder(header, restoreButton, nil, nil, preview)
+	d = dialog.NewCustom("系统初始hosts", "关闭", content, m.window)
+	d.Resize(fyne.NewSize(600, 450))
+	d.Show()
+}
+
+// restoreBaseline 确认后先备份当前hosts文件，再将其恢复为系统初始状态
+func (m *Manager) restoreBaseline(baseline *host.Baseline) {
+	message := "确定要将hosts文件恢复为系统初始状态吗？\n\n当前hosts文件会先备份，mHost写入的条目和之后的手动修改将被移除。"
+	dialog.ShowConfirm("恢复系统初始hosts", message, func(confirmed bool) {
+		if !confirmed {
+			return
+		}
+
+		go func() {
+			backup, err := m.hostManager.BackupHostsFile()
+			if err != nil {
+				m.showErrorDialog("备份hosts文件失败", err)
+				return
+			}
+			if _, err := host.RestoreBaseline(m.hostManager, baseline); err != nil {
+				m.showErrorDialog("恢复系统初始hosts失败", err)
+				return
+			}
+			m.recordActivity(models.EventSystemBackupRestored, map[string]interface{}{
+				"path":     baseline.Path,
+				"baseline": true,
+				"backup":   backup.FilePath,
+			})
+			m.statusBar.SetText(fmt.Sprintf("hosts文件已恢复为系统初始状态，恢复前的备份: %s", backup.FilePath))
+		}()
+	}, m.window)
+}
+
+// restoreHostsOnQuit 按设置在退出时移除mHost管理section或恢复系统初始hosts，使覆盖只在mHost运行期间生效
+func (m *Manager) restoreHostsOnQuit() {
+	if m.appConfig == nil {
+		return
+	}
+
+	switch m.appConfig.Security.RestoreOnQuit {
+	case models.RestoreOnQuitClear:
+		result, err := host.ClearManagedSection(m.hostManager)
+		if err != nil {
+			fmt.Printf("Failed to clear managed section on quit: %v\n", err)
+			return
+		}
+		if result != nil {
+			m.recordActivity(models.EventSystemHostsUpdated, map[string]interface{}{"on_quit": true, "cleared": true})
+		}
+	case models.RestoreOnQuitBaseline:
+		path, err := m.baselinePath()
+		if err != nil {
+			fmt.Printf("Failed to resolve hosts baseline path: %v\n", err)
+			return
+		}
+		baseline, err := host.LoadBaseline(path)
+		if err != nil {
+			fmt.Printf("Failed to load hosts baseline: %v\n", err)
+			return
+		}
+		data := map[string]interface{}{"path": baseline.Path, "baseline": true, "on_quit": true}
+		if backup, err := m.hostManager.BackupHostsFile(); err == nil {
+			data["backup"] = backup.FilePath
+		}
+		if _, err := host.RestoreBaseline(m.hostManager, baseline); err != nil {
+			fmt.Printf("Failed to restore hosts baseline on quit: %v\n", err)
+			return
+		}
+		m.recordActivity(models.EventSystemBackupRestored, data)
+	}
+}
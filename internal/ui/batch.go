@@ -0,0 +1,181 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/driver/desktop"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/flyhigher139/mhost/pkg/models"
+)
+
+// currentKeyModifiers 当前按下的修饰键，非桌面驱动时返回0
+func currentKeyModifiers() fyne.KeyModifier {
+	app := fyne.CurrentApp()
+	if app == nil {
+		return 0
+	}
+	if driver, ok := app.Driver().(desktop.Driver); ok {
+		return driver.CurrentKeyModifiers()
+	}
+	return 0
+}
+
+// selectEntryWithModifiers 按修饰键选择条目：Cmd/Ctrl加入或移出多选，Shift选择到上次选中的条目之间的范围，
+// 否则只选中该条目
+func (m *Manager) selectEntryWithModifiers(entry *models.HostEntry, modifiers fyne.KeyModifier) {
+	switch {
+	case modifiers&fyne.KeyModifierShortcutDefault != 0:
+		m.controller.ToggleEntrySelection(entry)
+	case modifiers&fyne.KeyModifierShift != 0:
+		m.controller.SelectEntryRange(m.entryView, entry)
+	default:
+		m.controller.SelectEntry(entry)
+	}
+}
+
+// showBatchContextMenu 多选时右键显示的批量操作菜单
+func (m *Manager) showBatchContextMenu(count int, event *fyne.PointEvent) {
+	menu := fyne.NewMenu("",
+		fyne.NewMenuItem(fmt.Sprintf("启用选中的%d个条目", count), func() { m.onBatchSetEnabled(true) }),
+		fyne.NewMenuItem(fmt.Sprintf("禁用选中的%d个条目", count), func() { m.onBatchSetEnabled(false) }),
+		fyne.NewMenuItem("修改IP地址...", m.onBatchChangeIP),
+		fyne.NewMenuItem("移动到Profile...", m.onBatchMoveEntries),
+		fyne.NewMenuItemSeparator(),
+		fyne.NewMenuItem(fmt.Sprintf("删除选中的%d个条目", count), m.onBatchDeleteEntries),
+	)
+	widget.ShowPopUpMenuAtPosition(menu, m.window.Canvas(), event.AbsolutePosition)
+}
+
+// finishBatchEdit 批量修改保存后记录活动、刷新列表并按设置自动应用
+func (m *Manager) finishBatchEdit(eventType models.EventType, entries []*models.HostEntry, status string) {
+	current := m.controller.CurrentProfile()
+	for _, entry := range entries {
+		m.recordActivity(eventType, entryActivityData(current, entry))
+	}
+	m.refreshHostEntryList()
+	m.refreshEnvironmentSelect()
+	m.statusBar.SetText(status)
+	m.scheduleAutoApply()
+}
+
+// onBatchSetEnabled 启用或禁用所有选中的条目
+func (m *Manager) onBatchSetEnabled(enabled bool) {
+	action := "启用"
+	if !enabled {
+		action = "禁用"
+	}
+	recordEdit := m.beginEdit(m.controller.CurrentProfile(), fmt.Sprintf("批量%sHost条目", action))
+	changed, err := m.controller.SetSelectedEntriesEnabled(enabled)
+	if err != nil {
+		m.showErrorDialog(fmt.Sprintf("批量%s失败", action), err)
+		return
+	}
+	if len(changed) == 0 {
+		m.statusBar.SetText(fmt.Sprintf("选中的条目均已%s", action))
+		return
+	}
+	recordEdit()
+	m.finishBatchEdit(models.EventHostEntryToggled, changed, fmt.Sprintf("已%s%d个Host条目", action, len(changed)))
+}
+
+// onBatchChangeIP 将所有选中条目的IP修改为同一个地址
+func (m *Manager) onBatchChangeIP() {
+	selected := m.controller.SelectedEntries()
+	if len(selected) == 0 {
+		return
+	}
+
+	ipEntry := widget.NewEntry()
+	ipEntry.SetPlaceHolder("例如: 127.0.0.1")
+	ipEntry.SetText(selected[0].IP)
+	items := []*widget.FormItem{widget.NewFormItem("IP地址", ipEntry)}
+	dialog.ShowForm(fmt.Sprintf("修改%d个条目的IP地址", len(selected)), "修改", "取消", items, func(ok bool) {
+		if !ok {
+			return
+		}
+		recordEdit := m.beginEdit(m.controller.CurrentProfile(), "批量修改Host条目的IP地址")
+		changed, err := m.controller.ChangeSelectedEntriesIP(strings.TrimSpace(ipEntry.Text))
+		if err != nil {
+			m.showErrorDialog("批量修改IP地址失败", err)
+			return
+		}
+		recordEdit()
+		m.finishBatchEdit(models.EventHostEntryUpdated, changed, fmt.Sprintf("已修改%d个Host条目的IP地址", len(changed)))
+	}, m.window)
+}
+
+// onBatchMoveEntries 将所有选中的条目移动到另一个Profile
+func (m *Manager) onBatchMoveEntries() {
+	current := m.controller.CurrentProfile()
+	selected := m.controller.SelectedEntries()
+	if current == nil || len(selected) == 0 {
+		return
+	}
+
+	var names []string
+	for _, p := range m.controller.Profiles() {
+		if p.ID != current.ID {
+			names = append(names, p.Name)
+		}
+	}
+	if len(names) == 0 {
+		dialog.ShowInformation("提示", "没有其他Profile可以移动到", m.window)
+		return
+	}
+	targetSelect := widget.NewSelect(names, nil)
+	targetSelect.SetSelected(names[0])
+
+	items := []*widget.FormItem{widget.NewFormItem("目标Profile", targetSelect)}
+	dialog.ShowForm(fmt.Sprintf("移动%d个条目", len(selected)), "移动", "取消", items, func(ok bool) {
+		if !ok {
+			return
+		}
+		target := m.controller.FindProfileByName(targetSelect.Selected)
+		if target == nil {
+			return
+		}
+		recordSource := m.beginEdit(current, fmt.Sprintf("移动Host条目到 '%s'", target.Name))
+		recordTarget := m.beginEdit(target, fmt.Sprintf("从 '%s' 移入Host条目", current.Name))
+		moved, err := m.controller.MoveSelectedEntries(target)
+		if err != nil {
+			m.showErrorDialog("移动Host条目失败", err)
+			return
+		}
+		recordSource()
+		recordTarget()
+		m.hostEntryTable.UnselectAll()
+		m.finishBatchEdit(models.EventHostEntryDeleted, moved, fmt.Sprintf("已将%d个Host条目移动到 '%s'", len(moved), target.Name))
+		for _, entry := range moved {
+			m.recordActivity(models.EventHostEntryAdded, entryActivityData(target, entry))
+		}
+		m.profileList.Refresh()
+	}, m.window)
+}
+
+// onBatchDeleteEntries 确认后删除所有选中的条目
+func (m *Manager) onBatchDeleteEntries() {
+	selected := m.controller.SelectedEntries()
+	if len(selected) == 0 {
+		return
+	}
+
+	message := fmt.Sprintf("确定要删除选中的%d个Host条目吗？\n\n删除后可通过编辑菜单中的撤销恢复。", len(selected))
+	dialog.ShowConfirm("确认删除", message, func(confirmed bool) {
+		if !confirmed {
+			return
+		}
+		recordEdit := m.beginEdit(m.controller.CurrentProfile(), fmt.Sprintf("删除%d个Host条目", len(selected)))
+		deleted, err := m.controller.DeleteSelectedEntries()
+		if err != nil {
+			m.showErrorDialog("批量删除失败", err)
+			return
+		}
+		recordEdit()
+		m.hostEntryTable.UnselectAll()
+		m.finishBatchEdit(models.EventHostEntryDeleted, deleted, fmt.Sprintf("已删除%d个Host条目", len(deleted)))
+	}, m.window)
+}
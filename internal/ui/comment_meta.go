@@ -0,0 +1,57 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/flyhigher139/mhost/pkg/hostsfile"
+	"github.com/flyhigher139/mhost/pkg/models"
+)
+
+// buildEntryComment 将编辑对话框中的说明与负责人、工单、过期日期组合为结构化注释，
+// 保留原注释中对话框未展示的其他字段
+func buildEntryComment(existing *models.HostEntry, note, owner, ticket, expires string) (string, error) {
+	expires = strings.TrimSpace(expires)
+	if expires != "" {
+		if _, err := time.Parse(hostsfile.ExpiresLayout, expires); err != nil {
+			return "", fmt.Errorf("过期日期格式应为 %s", hostsfile.ExpiresLayout)
+		}
+	}
+
+	meta := hostsfile.ParseComment(note)
+	if existing != nil {
+		for key, value := range hostsfile.ParseComment(existing.Comment).Fields {
+			if _, ok := meta.Fields[key]; !ok {
+				meta.Set(key, value)
+			}
+		}
+	}
+	meta.Set(hostsfile.MetaOwner, owner)
+	meta.Set(hostsfile.MetaTicket, ticket)
+	meta.Set(hostsfile.MetaExpires, expires)
+
+	return meta.String(), nil
+}
+
+// commentBadges 生成条目列表中显示的结构化注释标记
+func commentBadges(meta hostsfile.CommentMetadata, now time.Time) string {
+	var badges []string
+	if owner := meta.Owner(); owner != "" {
+		badges = append(badges, "负责人: "+owner)
+	}
+	if ticket := meta.Ticket(); ticket != "" {
+		badges = append(badges, "工单: "+ticket)
+	}
+	if expires, ok := meta.Expires(); ok {
+		badge := "过期: " + expires.Format(hostsfile.ExpiresLayout)
+		if meta.Expired(now) {
+			badge += "（已过期）"
+		}
+		badges = append(badges, badge)
+	}
+	if pinned, ok := meta.Pinned(); ok {
+		badges = append(badges, "已固定: "+pinned.Format(hostsfile.ExpiresLayout))
+	}
+	return strings.Join(badges, " | ")
+}
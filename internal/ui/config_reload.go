@@ -0,0 +1,138 @@
+package ui
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/flyhigher139/mhost/internal/config"
+	"github.com/flyhigher139/mhost/internal/httpclient"
+	"github.com/flyhigher139/mhost/pkg/logger"
+	"github.com/flyhigher139/mhost/pkg/models"
+)
+
+// 配置变化的来源，记录在最近活动中
+const (
+	configSourceSettings = "settings"
+	configSourceFile     = "file"
+)
+
+// startConfigWatch 监听配置文件的外部修改（例如手动编辑或同步工具写入），修改后无需重启即可生效
+func (m *Manager) startConfigWatch() {
+	if err := m.configManager.WatchConfig(m.onConfigFileChanged); err != nil {
+		fmt.Printf("Failed to watch config file: %v\n", err)
+	}
+}
+
+// stopConfigWatch 停止监听配置文件
+func (m *Manager) stopConfigWatch() {
+	if m.configManager != nil {
+		m.configManager.StopWatching()
+	}
+}
+
+// onConfigFileChanged 配置文件被修改后应用新配置；本程序保存设置引起的修改与当前配置相同，直接忽略
+func (m *Manager) onConfigFileChanged(updated *models.AppConfig) {
+	previous := m.appConfig
+	if len(config.ChangedSections(previous, updated)) == 0 {
+		return
+	}
+
+	m.appConfig = updated
+	sections := m.applyConfigChanges(previous)
+	m.recordConfigChange(sections, configSourceFile)
+	m.statusBar.SetText("配置文件已修改，新设置已生效")
+}
+
+// applyConfigChanges 将相对previous发生变化的配置分区应用到正在运行的组件，返回变化的分区
+func (m *Manager) applyConfigChanges(previous *models.AppConfig) []string {
+	sections := config.ChangedSections(previous, m.appConfig)
+	restartRemoteSync := false
+	for _, section := range sections {
+		switch section {
+		case config.SectionLog:
+			m.applyLogLevel()
+		case config.SectionBackup:
+			m.hostManager.SetDeltaBackups(m.appConfig.Backup.DeltaMinLines, m.appConfig.Backup.FullBackupEvery)
+			m.hostManager.SetBackupRetention(m.appConfig.Backup.MaxBackups, m.appConfig.Backup.Retention())
+		case config.SectionSecurity:
+			m.hostManager.SetBackupOnApply(m.appConfig.Security.BackupBeforeChange)
+		case config.SectionUI:
+			m.applyTheme()
+			if previous == nil || previous.UI.ProfileGrouping != m.appConfig.UI.ProfileGrouping ||
+				!slices.Equal(previous.UI.ExpandedGroups, m.appConfig.UI.ExpandedGroups) {
+				m.reloadProfileTree()
+			}
+			m.applyEntryTableConfig()
+			m.refreshHostEntryList()
+		case config.SectionDNSStats:
+			m.stopDNSStats()
+			m.startDNSStats()
+		case config.SectionNetwork:
+			// 离线模式决定订阅和资产清单是否定时下载
+			httpclient.Default().SetConfig(m.appConfig.Network)
+			restartRemoteSync = true
+		case config.SectionTelemetry:
+			m.usage.SetEnabled(m.appConfig.Telemetry.Enabled)
+		case config.SectionLimits:
+			m.hostManager.SetLimits(m.appConfig.Limits)
+		case config.SectionRules:
+			if err := m.loadRules(); err != nil {
+				m.showErrorDialog("读取校验规则失败", err)
+			}
+		case config.SectionPlugins:
+			if err := m.loadPlugins(); err != nil {
+				m.showErrorDialog("部分插件无法加载", err)
+			}
+		case config.SectionHooks:
+			if err := m.loadHooks(); err != nil {
+				m.showErrorDialog("加载Hook脚本失败", err)
+			}
+		case config.SectionHostnames:
+			// 订阅刷新和资产清单同步服务在启动时确定主机名规范化规则
+			restartRemoteSync = true
+		case config.SectionHealthCheck:
+			m.stopHealthCheck()
+			m.startHealthCheck()
+		case config.SectionSnapshot:
+			m.stopSnapshots()
+			m.startSnapshots()
+		case config.SectionXPC:
+			if m.helperClient != nil {
+				m.applyHelperTimeouts(m.helperClient)
+			}
+		}
+	}
+
+	if restartRemoteSync {
+		m.stopSubscriptions()
+		m.startSubscriptions()
+		m.stopInventory()
+		m.startInventory()
+	}
+
+	if len(sections) > 0 {
+		m.updateStatusBar()
+		m.refreshHostEntryList()
+	}
+	return sections
+}
+
+// applyLogLevel 按设置修改日志级别，级别无效时保持不变
+func (m *Manager) applyLogLevel() {
+	level, err := logger.ParseLogLevel(m.appConfig.Log.Level)
+	if err != nil {
+		return
+	}
+	m.logger.SetLevel(level)
+}
+
+// recordConfigChange 将配置变化记录到最近活动
+func (m *Manager) recordConfigChange(sections []string, source string) {
+	if len(sections) == 0 {
+		return
+	}
+	m.recordActivity(models.EventSystemConfigChanged, map[string]interface{}{
+		"sections": sections,
+		"source":   source,
+	})
+}
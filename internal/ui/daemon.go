@@ -0,0 +1,26 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// notifyDaemon 在守护进程运行时通知其重新加载Profile，使CLI立即看到GUI中的修改
+func (m *Manager) notifyDaemon() {
+	if m.daemonClient == nil {
+		return
+	}
+
+	go func() {
+		if !m.daemonClient.IsRunning() {
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		defer cancel()
+		if err := m.daemonClient.Reload(ctx); err != nil {
+			fmt.Printf("Failed to notify daemon: %v\n", err)
+		}
+	}()
+}
@@ -0,0 +1,308 @@
+package ui
+
+import (
+	"fmt"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/flyhigher139/mhost/internal/helper"
+	"github.com/flyhigher139/mhost/internal/host"
+	"github.com/flyhigher139/mhost/internal/profile"
+	"github.com/flyhigher139/mhost/pkg/models"
+)
+
+// maxRecentErrors 健康面板保留的最近错误数
+const maxRecentErrors = 20
+
+// recentError 最近发生的错误
+type recentError struct {
+	Title   string
+	Message string
+	Time    time.Time
+}
+
+// recordError 记录错误供健康面板显示，超过上限时丢弃最早的记录
+func (m *Manager) recordError(title string, err error) {
+	m.errorsMu.Lock()
+	defer m.errorsMu.Unlock()
+
+	m.recentErrors = append([]recentError{{Title: title, Message: err.Error(), Time: time.Now()}}, m.recentErrors...)
+	if len(m.recentErrors) > maxRecentErrors {
+		m.recentErrors = m.recentErrors[:maxRecentErrors]
+	}
+}
+
+// dashboardStatus 健康面板的数据快照
+type dashboardStatus struct {
+	HelperInstalled   bool
+	HelperPath        string
+	ElevatorAvailable bool
+	DaemonRunning     bool
+	PendingRequests   int
+
+	ActiveProfile *models.Profile
+	DriftCount    int
+	DriftErr      error
+
+	LastBackup  *host.BackupFile
+	BackupCount int
+	BackupErr   error
+	// BackupFreeSpace 备份目录所在磁盘的剩余字节数，BackupFreeErr为无法查询的原因
+	BackupFreeSpace uint64
+	BackupFreeErr   error
+
+	LastApply *host.ApplyResult
+
+	ValidationProblems int
+	Validated          bool
+	NextRuns           map[string]time.Time
+
+	RecentErrors []recentError
+}
+
+// dashboardRow 健康面板中的一行
+type dashboardRow struct {
+	Label   string
+	Value   string
+	Healthy bool
+}
+
+// collectDashboardStatus 汇总helper、激活Profile、漂移、备份、应用历史和定时任务的当前状态
+func (m *Manager) collectDashboardStatus() *dashboardStatus {
+	status := &dashboardStatus{
+		HelperInstalled: helper.IsInstalled(helper.DefaultServiceName),
+		HelperPath:      helper.InstallPath(helper.DefaultServiceName),
+		NextRuns:        make(map[string]time.Time),
+	}
+	if elevator := m.hostManager.GetElevator(); elevator != nil {
+		status.ElevatorAvailable = elevator.Available()
+	}
+	if m.daemonClient != nil {
+		status.DaemonRunning = m.daemonClient.IsRunning()
+	}
+	if m.helperQueue != nil {
+		status.PendingRequests = len(m.helperQueue.Pending())
+	}
+
+	if active, err := profile.LayeredActiveProfile(m.profileManager); err == nil {
+		status.ActiveProfile = active
+		if drift, err := m.hostManager.DetectDrift(active); err != nil {
+			status.DriftErr = err
+		} else {
+			status.DriftCount = len(drift.Added) + len(drift.Changed) + len(drift.Removed)
+		}
+	}
+
+	if m.workspace != nil {
+		backups, err := host.ListBackupFiles(m.workspace.BackupDir)
+		status.BackupErr = err
+		status.BackupCount = len(backups)
+		if len(backups) > 0 {
+			status.LastBackup = &backups[0]
+		}
+		status.BackupFreeSpace, status.BackupFreeErr = host.FreeSpace(m.workspace.BackupDir)
+	}
+
+	if m.history != nil {
+		if history, err := m.history.List(); err == nil && len(history) > 0 {
+			status.LastApply = history[0]
+		}
+	}
+
+	if m.healthChecker != nil {
+		if report := m.healthChecker.LastReport(); report != nil {
+			status.Validated = true
+			status.ValidationProblems = report.Total()
+		}
+		if next, ok := m.healthChecker.NextRun(); ok {
+			status.NextRuns["定期验证"] = next
+		}
+	}
+	if m.analyzer != nil {
+		if next, ok := m.analyzer.NextRun(); ok {
+			status.NextRuns["条目分析"] = next
+		}
+	}
+
+	m.errorsMu.Lock()
+	status.RecentErrors = append([]recentError(nil), m.recentErrors...)
+	m.errorsMu.Unlock()
+
+	return status
+}
+
+// dashboardRows 将状态快照整理为面板中的各行
+func dashboardRows(status *dashboardStatus, now time.Time) []dashboardRow {
+	var rows []dashboardRow
+
+	helperRow := dashboardRow{Label: "特权Helper", Value: "已安装: " + status.HelperPath, Healthy: true}
+	if !status.HelperInstalled {
+		helperRow.Value = "未安装"
+		helperRow.Healthy = status.ElevatorAvailable
+		if status.ElevatorAvailable {
+			helperRow.Value += "，将通过管理员权限对话框写入"
+		}
+	}
+	rows = append(rows, helperRow)
+
+	if status.PendingRequests > 0 {
+		rows = append(rows, dashboardRow{Label: "待发送请求", Value: fmt.Sprintf("Helper暂时不可用，%d 个请求等待发送", status.PendingRequests)})
+	}
+
+	daemonRow := dashboardRow{Label: "守护进程", Value: "未运行", Healthy: true}
+	if status.DaemonRunning {
+		daemonRow.Value = "运行中"
+	}
+	rows = append(rows, daemonRow)
+
+	if status.ActiveProfile == nil {
+		rows = append(rows, dashboardRow{Label: "激活的Profile", Value: "无", Healthy: false})
+	} else {
+		value := fmt.Sprintf("%s（%d 个条目）", status.ActiveProfile.Name, len(status.ActiveProfile.Entries))
+		if status.ActiveProfile.Environment != "" {
+			value += "，环境: " + status.ActiveProfile.Environment
+		}
+		rows = append(rows, dashboardRow{Label: "激活的Profile", Value: value, Healthy: true})
+
+		switch {
+		case status.DriftErr != nil:
+			rows = append(rows, dashboardRow{Label: "漂移", Value: "检测失败: " + status.DriftErr.Error()})
+		case status.DriftCount > 0:
+			rows = append(rows, dashboardRow{Label: "漂移", Value: fmt.Sprintf("hosts文件与Profile有 %d 处不一致", status.DriftCount)})
+		default:
+			rows = append(rows, dashboardRow{Label: "漂移", Value: "hosts文件与Profile一致", Healthy: true})
+		}
+	}
+
+	switch {
+	case status.BackupErr != nil:
+		rows = append(rows, dashboardRow{Label: "最近备份", Value: "读取失败: " + status.BackupErr.Error()})
+	case status.LastBackup == nil:
+		rows = append(rows, dashboardRow{Label: "最近备份", Value: "没有备份"})
+	default:
+		rows = append(rows, dashboardRow{
+			Label:   "最近备份",
+			Value:   fmt.Sprintf("%s（%s前，共 %d 个备份）", status.LastBackup.ModTime.Format("2006-01-02 15:04"), formatAge(now.Sub(status.LastBackup.ModTime)), status.BackupCount),
+			Healthy: true,
+		})
+	}
+
+	switch {
+	case status.BackupFreeErr != nil:
+		rows = append(rows, dashboardRow{Label: "备份空间", Value: "无法查询剩余空间: " + status.BackupFreeErr.Error(), Healthy: true})
+	case status.BackupFreeSpace < host.LowBackupSpace:
+		rows = append(rows, dashboardRow{Label: "备份空间", Value: fmt.Sprintf("剩余 %s，空间不足时将按保留策略清理旧备份", formatBytes(int64(status.BackupFreeSpace)))})
+	default:
+		rows = append(rows, dashboardRow{Label: "备份空间", Value: "剩余 " + formatBytes(int64(status.BackupFreeSpace)), Healthy: true})
+	}
+
+	if status.LastApply == nil {
+		rows = append(rows, dashboardRow{Label: "最近应用", Value: "没有应用记录", Healthy: true})
+	} else {
+		rows = append(rows, dashboardRow{
+			Label:   "最近应用",
+			Value:   fmt.Sprintf("%s，%s（%s）", status.LastApply.ProfileName, status.LastApply.AppliedAt.Format("2006-01-02 15:04"), applySummaryText(status.LastApply)),
+			Healthy: true,
+		})
+	}
+
+	switch {
+	case !status.Validated:
+		rows = append(rows, dashboardRow{Label: "定期验证", Value: "尚未运行", Healthy: true})
+	case status.ValidationProblems > 0:
+		rows = append(rows, dashboardRow{Label: "定期验证", Value: fmt.Sprintf("发现 %d 个问题", status.ValidationProblems)})
+	default:
+		rows = append(rows, dashboardRow{Label: "定期验证", Value: "没有发现问题", Healthy: true})
+	}
+
+	for _, name := range []string{"定期验证", "条目分析"} {
+		value := "未启用"
+		if next, ok := status.NextRuns[name]; ok {
+			value = fmt.Sprintf("%s（%s后）", next.Format("2006-01-02 15:04"), formatAge(next.Sub(now)))
+		}
+		rows = append(rows, dashboardRow{Label: "下次" + name, Value: value, Healthy: true})
+	}
+
+	if len(status.RecentErrors) == 0 {
+		rows = append(rows, dashboardRow{Label: "最近错误", Value: "无", Healthy: true})
+	}
+	for i, recent := range status.RecentErrors {
+		if i >= 5 {
+			break
+		}
+		rows = append(rows, dashboardRow{
+			Label: "最近错误",
+			Value: fmt.Sprintf("%s %s: %s", recent.Time.Format("01-02 15:04"), recent.Title, recent.Message),
+		})
+	}
+
+	return rows
+}
+
+// formatAge 将时长格式化为粗略的分钟、小时或天数
+func formatAge(d time.Duration) string {
+	if d < 0 {
+		d = 0
+	}
+	switch {
+	case d < time.Hour:
+		return fmt.Sprintf("%d分钟", int(d.Minutes()))
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%d小时", int(d.Hours()))
+	default:
+		return fmt.Sprintf("%d天", int(d.Hours()/24))
+	}
+}
+
+// onShowDashboard 打开健康面板窗口，一屏查看各项状态是否正常
+func (m *Manager) onShowDashboard() {
+	window := fyne.CurrentApp().NewWindow("健康面板")
+
+	grid := container.New(layout.NewFormLayout())
+	summary := widget.NewLabel("")
+	summary.TextStyle.Bold = true
+
+	refresh := func() {
+		rows := dashboardRows(m.collectDashboardStatus(), time.Now())
+
+		problems := 0
+		grid.Objects = nil
+		for _, row := range rows {
+			icon := widget.NewIcon(theme.ConfirmIcon())
+			if !row.Healthy {
+				icon.SetResource(theme.WarningIcon())
+				problems++
+			}
+			label := widget.NewLabel(row.Label)
+			label.TextStyle.Bold = true
+			value := widget.NewLabel(row.Value)
+			value.Wrapping = fyne.TextWrapWord
+			grid.Add(container.NewHBox(icon, label))
+			grid.Add(value)
+		}
+		grid.Refresh()
+
+		if problems == 0 {
+			summary.SetText("一切正常")
+		} else {
+			summary.SetText(fmt.Sprintf("%d 项需要关注", problems))
+		}
+	}
+	refresh()
+
+	buttons := container.NewHBox(
+		widget.NewButton("刷新", refresh),
+		widget.NewButton("定期验证报告", m.onShowHealthReport),
+		layout.NewSpacer(),
+		widget.NewButton("关闭", window.Close),
+	)
+
+	window.SetContent(container.NewBorder(summary, buttons, nil, nil, container.NewVScroll(grid)))
+	window.Resize(fyne.NewSize(640, 480))
+	window.Show()
+}
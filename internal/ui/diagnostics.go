@@ -0,0 +1,256 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/flyhigher139/mhost/internal/diagnostics"
+	"github.com/flyhigher139/mhost/internal/telemetry"
+)
+
+// diagnosticsTimeout 诊断操作超时时间
+const diagnosticsTimeout = 15 * time.Second
+
+// onShowIPInfo 显示当前Host条目目标IP的反向解析、whois和地理信息
+func (m *Manager) onShowIPInfo() {
+	entry := m.controller.CurrentEntry()
+	if entry == nil {
+		dialog.ShowInformation("提示", "请先选择要查看的Host条目", m.window)
+		return
+	}
+
+	progressDialog := dialog.NewProgressInfinite("IP信息", fmt.Sprintf("正在查询 %s 的信息，请稍候...", entry.IP), m.window)
+	progressDialog.Show()
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), diagnosticsTimeout)
+		defer cancel()
+
+		info, err := m.ipInfo.Lookup(ctx, entry.IP)
+		progressDialog.Hide()
+		if err != nil {
+			m.showErrorDialog("查询失败", err)
+			return
+		}
+		m.recordUsage(telemetry.EventDiagnostics)
+
+		dialog.ShowInformation(fmt.Sprintf("IP信息: %s", entry.Hostname), formatIPInfo(info), m.window)
+	}()
+}
+
+// formatIPInfo 格式化IP信息
+func formatIPInfo(info *diagnostics.IPInfo) string {
+	orDefault := func(value string) string {
+		if value == "" {
+			return "未知"
+		}
+		return value
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "IP地址: %s\n", info.IP)
+	fmt.Fprintf(&b, "反向解析: %s\n", orDefault(strings.Join(info.ReverseDNS, ", ")))
+	if info.Private {
+		b.WriteString("地址类型: 私有/本地地址\n")
+	} else {
+		fmt.Fprintf(&b, "所有者: %s\n", orDefault(info.WhoisOwner))
+		fmt.Fprintf(&b, "组织: %s\n", orDefault(info.Org))
+		location := strings.Trim(strings.Join([]string{info.Country, info.Region, info.City}, " "), " ")
+		fmt.Fprintf(&b, "位置: %s\n", orDefault(location))
+	}
+
+	fmt.Fprintf(&b, "\n查询时间: %s", info.FetchedAt.Format(time.DateTime))
+	if info.Stale {
+		b.WriteString(" (离线，显示缓存结果)")
+	}
+	return b.String()
+}
+
+// portProbeTimeout 单个端口的连接超时
+const portProbeTimeout = 3 * time.Second
+
+// onProbePorts 探测当前Host条目目标IP的常用端口
+func (m *Manager) onProbePorts() {
+	entry := m.controller.CurrentEntry()
+	if entry == nil {
+		dialog.ShowInformation("提示", "请先选择要探测的Host条目", m.window)
+		return
+	}
+
+	portsEntry := widget.NewEntry()
+	portsEntry.SetText("80,443,22")
+
+	form := &widget.Form{
+		Items: []*widget.FormItem{
+			{Text: "目标", Widget: widget.NewLabel(fmt.Sprintf("%s (%s)", entry.IP, entry.Hostname))},
+			{Text: "端口", Widget: portsEntry, HintText: "逗号分隔，例如: 80,443,22,8080"},
+		},
+	}
+
+	d := dialog.NewCustomConfirm("端口探测", "开始", "取消", form, func(confirmed bool) {
+		if !confirmed {
+			return
+		}
+
+		ports, err := diagnostics.ParsePorts(portsEntry.Text)
+		if err != nil {
+			m.showErrorDialog("输入验证错误", err)
+			return
+		}
+
+		progressDialog := dialog.NewProgressInfinite("端口探测", fmt.Sprintf("正在探测 %s，请稍候...", entry.IP), m.window)
+		progressDialog.Show()
+
+		go func() {
+			ctx, cancel := context.WithTimeout(context.Background(), diagnosticsTimeout)
+			defer cancel()
+
+			results, err := diagnostics.ProbePorts(ctx, entry.IP, ports, portProbeTimeout)
+			progressDialog.Hide()
+			if err != nil {
+				m.showErrorDialog("探测失败", err)
+				return
+			}
+
+			dialog.ShowInformation(fmt.Sprintf("端口探测: %s", entry.Hostname), formatPortResults(results), m.window)
+		}()
+	}, m.window)
+	d.Resize(fyne.NewSize(400, 200))
+	d.Show()
+}
+
+// formatPortResults 格式化端口探测结果
+func formatPortResults(results []diagnostics.PortResult) string {
+	var b strings.Builder
+	for _, result := range results {
+		if result.Open {
+			fmt.Fprintf(&b, "%d: 开放 (%s)\n", result.Port, result.Latency.Round(time.Millisecond))
+		} else {
+			fmt.Fprintf(&b, "%d: 关闭 (%s)\n", result.Port, result.Error)
+		}
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// onCheckCertificate 以主机名作为SNI检查目标IP的HTTPS证书
+func (m *Manager) onCheckCertificate() {
+	entry := m.controller.CurrentEntry()
+	if entry == nil {
+		dialog.ShowInformation("提示", "请先选择要检查的Host条目", m.window)
+		return
+	}
+
+	progressDialog := dialog.NewProgressInfinite("证书检查", fmt.Sprintf("正在检查 %s 的证书，请稍候...", entry.Hostname), m.window)
+	progressDialog.Show()
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), diagnosticsTimeout)
+		defer cancel()
+
+		report, err := diagnostics.CheckCertificate(ctx, entry.IP, entry.Hostname, 443, nil)
+		progressDialog.Hide()
+		if err != nil {
+			m.showErrorDialog("证书检查失败", err)
+			return
+		}
+
+		dialog.ShowInformation(fmt.Sprintf("证书检查: %s", entry.Hostname), formatCertificateReport(report), m.window)
+	}()
+}
+
+// formatCertificateReport 格式化证书检查结果
+func formatCertificateReport(report *diagnostics.CertificateReport) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "CN: %s\n", report.CommonName)
+	fmt.Fprintf(&b, "SAN: %s\n", strings.Join(report.SANs, ", "))
+	fmt.Fprintf(&b, "颁发者: %s\n", report.Issuer)
+	fmt.Fprintf(&b, "有效期至: %s (剩余%d天)\n", report.NotAfter.Format(time.DateTime), report.DaysRemaining)
+
+	if !report.HasWarnings() {
+		b.WriteString("\n证书有效且与主机名匹配")
+		return b.String()
+	}
+
+	b.WriteString("\n浏览器将显示警告:\n")
+	for _, warning := range report.Warnings {
+		fmt.Fprintf(&b, "- %s\n", warning)
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// onCheckHTTP 以主机名作为Host头向目标IP发送HTTP(S)请求
+func (m *Manager) onCheckHTTP() {
+	entry := m.controller.CurrentEntry()
+	if entry == nil {
+		dialog.ShowInformation("提示", "请先选择要检查的Host条目", m.window)
+		return
+	}
+
+	schemeSelect := widget.NewSelect([]string{"https", "http"}, nil)
+	schemeSelect.SetSelected("https")
+	pathEntry := widget.NewEntry()
+	pathEntry.SetText("/")
+	insecureCheck := widget.NewCheck("跳过证书验证", nil)
+
+	form := &widget.Form{
+		Items: []*widget.FormItem{
+			{Text: "目标", Widget: widget.NewLabel(fmt.Sprintf("%s (%s)", entry.IP, entry.Hostname))},
+			{Text: "协议", Widget: schemeSelect},
+			{Text: "路径", Widget: pathEntry},
+			{Text: "证书", Widget: insecureCheck},
+		},
+	}
+
+	d := dialog.NewCustomConfirm("HTTP检查", "开始", "取消", form, func(confirmed bool) {
+		if !confirmed {
+			return
+		}
+
+		options := diagnostics.HTTPCheckOptions{
+			Scheme:   schemeSelect.Selected,
+			Path:     strings.TrimSpace(pathEntry.Text),
+			Timeout:  diagnosticsTimeout,
+			Insecure: insecureCheck.Checked,
+		}
+
+		progressDialog := dialog.NewProgressInfinite("HTTP检查", fmt.Sprintf("正在请求 %s，请稍候...", entry.Hostname), m.window)
+		progressDialog.Show()
+
+		go func() {
+			ctx, cancel := context.WithTimeout(context.Background(), diagnosticsTimeout)
+			defer cancel()
+
+			result, err := diagnostics.CheckHTTP(ctx, entry.IP, entry.Hostname, options)
+			progressDialog.Hide()
+			if err != nil {
+				m.showErrorDialog("HTTP检查失败", err)
+				return
+			}
+
+			dialog.ShowInformation(fmt.Sprintf("HTTP检查: %s", entry.Hostname), formatHTTPCheckResult(result), m.window)
+		}()
+	}, m.window)
+	d.Resize(fyne.NewSize(400, 260))
+	d.Show()
+}
+
+// formatHTTPCheckResult 格式化HTTP检查结果
+func formatHTTPCheckResult(result *diagnostics.HTTPCheckResult) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "URL: %s\n", result.URL)
+	fmt.Fprintf(&b, "状态: %s\n", result.Status)
+	if result.RedirectTarget != "" {
+		fmt.Fprintf(&b, "重定向到: %s\n", result.RedirectTarget)
+	}
+	if result.Server != "" {
+		fmt.Fprintf(&b, "服务器: %s\n", result.Server)
+	}
+	fmt.Fprintf(&b, "耗时: %s", result.Latency.Round(time.Millisecond))
+	return b.String()
+}
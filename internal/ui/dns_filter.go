@@ -0,0 +1,179 @@
+package ui
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/flyhigher139/mhost/internal/dnsfilter"
+	"github.com/flyhigher139/mhost/internal/httpclient"
+	"github.com/flyhigher139/mhost/pkg/models"
+)
+
+// 设置中DNS过滤服务类型的选项
+const (
+	dnsFilterNone    = "不使用"
+	dnsFilterPiHole  = "Pi-hole"
+	dnsFilterAdGuard = "AdGuard Home"
+)
+
+// dnsFilterTypes 选项与配置中服务类型的对应关系
+var dnsFilterTypes = map[string]string{
+	dnsFilterNone:    "",
+	dnsFilterPiHole:  models.DNSFilterPiHole,
+	dnsFilterAdGuard: models.DNSFilterAdGuard,
+}
+
+// dnsFilterLabel 服务类型在设置中显示的名称
+func dnsFilterLabel(filterType string) string {
+	for label, t := range dnsFilterTypes {
+		if t == filterType {
+			return label
+		}
+	}
+	return dnsFilterNone
+}
+
+// newDNSFilterClient 按配置创建DNS过滤服务的客户端，使用应用的网络代理设置
+func newDNSFilterClient(config models.DNSFilterConfig) (dnsfilter.Client, error) {
+	if config.Type == "" || config.URL == "" {
+		return nil, dnsfilter.ErrNotConfigured
+	}
+	client, err := httpclient.Default().Client(dnsfilter.DefaultTimeout)
+	if err != nil {
+		return nil, err
+	}
+	return dnsfilter.New(config, client)
+}
+
+// createDNSFilterSettings 创建设置中DNS过滤服务的分组，返回读取输入的函数
+func (m *Manager) createDNSFilterSettings() (*widget.Card, func() (models.DNSFilterConfig, error)) {
+	config := m.appConfig.DNSFilter
+
+	typeSelect := widget.NewSelect([]string{dnsFilterNone, dnsFilterPiHole, dnsFilterAdGuard}, nil)
+	typeSelect.SetSelected(dnsFilterLabel(config.Type))
+	urlEntry := widget.NewEntry()
+	urlEntry.SetPlaceHolder("例如: http://pi.hole")
+	urlEntry.SetText(config.URL)
+	tokenEntry := widget.NewPasswordEntry()
+	tokenEntry.SetText(config.Token)
+
+	read := func() (models.DNSFilterConfig, error) {
+		updated := models.DNSFilterConfig{
+			Type:  dnsFilterTypes[typeSelect.Selected],
+			URL:   strings.TrimSpace(urlEntry.Text),
+			Token: tokenEntry.Text,
+		}
+		if updated.Type != "" && updated.URL == "" {
+			return config, errors.New("使用DNS过滤服务时必须指定服务地址")
+		}
+		return updated, nil
+	}
+
+	testButton := widget.NewButton("测试连接", func() {
+		updated, err := read()
+		if err != nil {
+			m.showErrorDialog("输入验证错误", err)
+			return
+		}
+		client, err := newDNSFilterClient(updated)
+		if err != nil {
+			m.showErrorDialog("连接失败", err)
+			return
+		}
+		go func() {
+			blocked, err := client.Blocked(context.Background())
+			if err != nil {
+				m.showErrorDialog("连接失败", err)
+				return
+			}
+			m.showSuccessDialog("连接成功", fmt.Sprintf("服务中已有%d个拦截域名", len(blocked)))
+		}()
+	})
+
+	form := &widget.Form{
+		Items: []*widget.FormItem{
+			{Text: "服务类型", Widget: typeSelect},
+			{Text: "服务地址", Widget: urlEntry},
+			{Text: "令牌", Widget: tokenEntry, HintText: "Pi-hole填写应用密码；AdGuard Home填写\"用户名:密码\""},
+			{Text: "", Widget: testButton},
+		},
+	}
+	return widget.NewCard("DNS过滤服务", "与Pi-hole或AdGuard Home同步指向0.0.0.0的拦截条目", form), read
+}
+
+// onPushToDNSFilter 将当前Profile中的拦截条目推送到DNS过滤服务，服务中已有的规则不会被删除
+func (m *Manager) onPushToDNSFilter() {
+	current := m.controller.CurrentProfile()
+	if current == nil {
+		dialog.ShowInformation("提示", "请先选择要推送的Profile", m.window)
+		return
+	}
+	hostnames := dnsfilter.BlockedHostnames(current)
+	if len(hostnames) == 0 {
+		dialog.ShowInformation("提示", "当前Profile中没有指向0.0.0.0或::的启用条目", m.window)
+		return
+	}
+	client, err := newDNSFilterClient(m.appConfig.DNSFilter)
+	if err != nil {
+		m.showDNSFilterError(err)
+		return
+	}
+
+	service := dnsFilterLabel(m.appConfig.DNSFilter.Type)
+	message := fmt.Sprintf("确定要将Profile '%s' 中的%d个拦截域名推送到%s吗？\n\n只会添加服务中缺少的域名，服务中已有的规则保持不变。", current.Name, len(hostnames), service)
+	dialog.ShowConfirm("推送拦截条目", message, func(confirmed bool) {
+		if !confirmed {
+			return
+		}
+		progressDialog := dialog.NewProgressInfinite("推送拦截条目", fmt.Sprintf("正在推送到%s，请稍候...", service), m.window)
+		progressDialog.Show()
+		go func() {
+			result, err := dnsfilter.Push(context.Background(), client, current)
+			progressDialog.Hide()
+			if err != nil {
+				m.showErrorDialog("推送失败", err)
+				return
+			}
+			m.statusBar.SetText(fmt.Sprintf("已向%s添加%d个拦截域名，%d个已存在", service, len(result.Added), result.Existing))
+		}()
+	}, m.window)
+}
+
+// onPullFromDNSFilter 将DNS过滤服务中的拦截域名拉取为新Profile，导入前显示预览
+func (m *Manager) onPullFromDNSFilter() {
+	client, err := newDNSFilterClient(m.appConfig.DNSFilter)
+	if err != nil {
+		m.showDNSFilterError(err)
+		return
+	}
+
+	service := dnsFilterLabel(m.appConfig.DNSFilter.Type)
+	progressDialog := dialog.NewProgressInfinite("拉取拦截列表", fmt.Sprintf("正在从%s获取拦截列表，请稍候...", service), m.window)
+	progressDialog.Show()
+	go func() {
+		profile, skipped, err := dnsfilter.Pull(context.Background(), client, service, m.hostnameNormalizer())
+		progressDialog.Hide()
+		if err != nil {
+			m.showErrorDialog("拉取失败", err)
+			return
+		}
+		if skipped > 0 {
+			m.statusBar.SetText(fmt.Sprintf("已跳过%d个不是有效主机名的规则", skipped))
+		}
+		m.showImportPreview(profile, m.appConfig.DNSFilter.URL)
+	}()
+}
+
+// showDNSFilterError 显示无法连接DNS过滤服务的原因，未设置服务时提示到设置中填写
+func (m *Manager) showDNSFilterError(err error) {
+	if errors.Is(err, dnsfilter.ErrNotConfigured) {
+		dialog.ShowInformation("提示", "尚未设置DNS过滤服务，请在设置中填写Pi-hole或AdGuard Home的地址和令牌", m.window)
+		return
+	}
+	m.showErrorDialog("无法连接DNS过滤服务", err)
+}
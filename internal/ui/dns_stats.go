@@ -0,0 +1,64 @@
+package ui
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/flyhigher139/mhost/internal/dnsstats"
+	"github.com/flyhigher139/mhost/pkg/models"
+)
+
+// startDNSStats 按配置启动DNS查询日志的命中统计
+func (m *Manager) startDNSStats() {
+	if m.appConfig == nil || !m.appConfig.DNSStats.Enabled || m.queryLogTailer != nil {
+		return
+	}
+
+	if m.hitCounter == nil {
+		m.hitCounter = dnsstats.NewCounter()
+	}
+	m.updateTrackedHostnames()
+
+	tailer := dnsstats.NewQueryLogTailer(m.appConfig.DNSStats.QueryLogPath, m.hitCounter, m.appConfig.DNSStats.PollInterval)
+	if err := tailer.Start(); err != nil {
+		m.statusBar.SetText(fmt.Sprintf("启动DNS命中统计失败: %v", err))
+		return
+	}
+	m.queryLogTailer = tailer
+}
+
+// stopDNSStats 停止DNS命中统计
+func (m *Manager) stopDNSStats() {
+	if m.queryLogTailer != nil {
+		m.queryLogTailer.Stop()
+		m.queryLogTailer = nil
+	}
+}
+
+// updateTrackedHostnames 只统计Profile中出现的主机名
+func (m *Manager) updateTrackedHostnames() {
+	if m.hitCounter == nil {
+		return
+	}
+
+	var hostnames []string
+	for _, profile := range m.controller.Profiles() {
+		for _, entry := range profile.Entries {
+			hostnames = append(hostnames, entry.Hostname)
+		}
+	}
+	m.hitCounter.SetTracked(hostnames)
+}
+
+// hitText 返回条目的命中统计描述，未启用统计时返回空字符串
+func (m *Manager) hitText(entry *models.HostEntry) string {
+	if m.hitCounter == nil {
+		return ""
+	}
+
+	hit, ok := m.hitCounter.Get(entry.Hostname)
+	if !ok {
+		return "命中: 0次"
+	}
+	return fmt.Sprintf("命中: %d次 (最近: %s)", hit.Count, hit.LastSeen.Format(time.DateTime))
+}
@@ -0,0 +1,88 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/flyhigher139/mhost/internal/doctor"
+)
+
+// doctorCheckNames 诊断检查项的显示名称
+var doctorCheckNames = map[string]string{
+	doctor.CheckHelper:      "特权Helper",
+	doctor.CheckHostsFile:   "Hosts文件权限",
+	doctor.CheckDataDir:     "数据目录",
+	doctor.CheckBackupSpace: "备份目录空间",
+	doctor.CheckDNSFlush:    "DNS缓存刷新",
+	doctor.CheckConfig:      "配置文件",
+}
+
+// doctorStatusLabels 诊断检查状态的显示名称
+var doctorStatusLabels = map[doctor.Status]string{
+	doctor.StatusOK:      "通过",
+	doctor.StatusWarning: "警告",
+	doctor.StatusError:   "错误",
+	doctor.StatusSkipped: "跳过",
+}
+
+// onRunDoctor 运行诊断检查并显示结果
+func (m *Manager) onRunDoctor() {
+	progressDialog := dialog.NewProgressInfinite("诊断检查", "正在检查Helper、权限、目录和配置，请稍候...", m.window)
+	progressDialog.Show()
+
+	go func() {
+		report := doctor.New(doctor.WorkspaceOptions(m.workspace)).Run()
+		progressDialog.Hide()
+		m.showDoctorReport(report)
+	}()
+}
+
+// showDoctorReport 显示诊断检查结果，报告文本可以复制后提交给支持人员
+func (m *Manager) showDoctorReport(report *doctor.Report) {
+	reportText := widget.NewMultiLineEntry()
+	reportText.SetText(formatDoctorReport(report))
+	reportText.Wrapping = fyne.TextWrapWord
+
+	var d dialog.Dialog
+	rerun := widget.NewButton("重新检查", func() {
+		d.Hide()
+		m.onRunDoctor()
+	})
+	copyReport := widget.NewButton("复制报告", func() {
+		m.window.Clipboard().SetContent(reportText.Text)
+		m.statusBar.SetText("诊断报告已复制到剪贴板")
+	})
+
+	content := container.NewBorder(nil, container.NewHBox(rerun, copyReport), nil, nil, container.NewScroll(reportText))
+	d = dialog.NewCustom("诊断检查", "关闭", content, m.window)
+	d.Resize(fyne.NewSize(650, 450))
+	d.Show()
+}
+
+// formatDoctorReport 格式化诊断检查结果，未通过的检查附带修复建议
+func formatDoctorReport(report *doctor.Report) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "检查时间: %s\n", report.GeneratedAt.Format("2006-01-02 15:04:05"))
+	if problems := report.Problems(); len(problems) > 0 {
+		fmt.Fprintf(&b, "%d项检查需要处理\n", len(problems))
+	} else {
+		b.WriteString("所有检查均已通过\n")
+	}
+
+	for _, check := range report.Checks {
+		name := doctorCheckNames[check.Name]
+		if name == "" {
+			name = check.Name
+		}
+		fmt.Fprintf(&b, "\n[%s] %s\n  %s\n", doctorStatusLabels[check.Status], name, check.Detail)
+		if check.Fix != "" {
+			fmt.Fprintf(&b, "  修复建议: %s\n", check.Fix)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,99 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/flyhigher139/mhost/pkg/models"
+)
+
+// duplicateBanner 当前Profile中有重复主机名时显示在条目列表上方的提示
+type duplicateBanner struct {
+	container *fyne.Container
+	label     *widget.Label
+	// entries 重复的条目ID，用于在条目列表中标记
+	entries map[string]bool
+}
+
+// createDuplicateBanner 创建重复主机名提示，点击按钮时每个主机名只保留最后一个条目
+func (m *Manager) createDuplicateBanner() fyne.CanvasObject {
+	label := widget.NewLabel("")
+	label.Wrapping = fyne.TextWrapWord
+	label.Importance = widget.WarningImportance
+	button := widget.NewButton("只保留最后一个", m.onRemoveDuplicateHostnames)
+	button.Importance = widget.WarningImportance
+
+	m.duplicates = &duplicateBanner{
+		container: container.NewBorder(nil, nil, widget.NewIcon(theme.WarningIcon()), button, label),
+		label:     label,
+	}
+	m.duplicates.container.Hide()
+	return m.duplicates.container
+}
+
+// duplicateSummary 重复主机名的说明，例如"api.test（2个条目）"
+func duplicateSummary(duplicates []models.DuplicateHostname) string {
+	names := make([]string, 0, len(duplicates))
+	for _, duplicate := range duplicates {
+		names = append(names, fmt.Sprintf("%s（%d个条目）", duplicate.Hostname, len(duplicate.Entries)))
+	}
+	return fmt.Sprintf("主机名重复: %s。hosts文件中每个主机名只有一个条目生效", strings.Join(names, "、"))
+}
+
+// refreshHostEntryList 刷新Host条目列表，并按当前Profile更新重复主机名的提示
+func (m *Manager) refreshHostEntryList() {
+	if m.duplicates != nil {
+		var duplicates []models.DuplicateHostname
+		if current := m.controller.CurrentProfile(); current != nil {
+			duplicates = current.DuplicateHostnames()
+		}
+		m.duplicates.entries = make(map[string]bool)
+		for _, duplicate := range duplicates {
+			for _, entry := range duplicate.Entries {
+				m.duplicates.entries[entry.ID] = true
+			}
+		}
+		if len(duplicates) == 0 {
+			m.duplicates.container.Hide()
+		} else {
+			m.duplicates.label.SetText(duplicateSummary(duplicates))
+			m.duplicates.container.Show()
+		}
+	}
+	m.hostEntryTable.Refresh()
+}
+
+// isDuplicateEntry 条目的主机名是否与当前Profile中的其他启用条目重复
+func (m *Manager) isDuplicateEntry(entry *models.HostEntry) bool {
+	return m.duplicates != nil && m.duplicates.entries[entry.ID]
+}
+
+// onRemoveDuplicateHostnames 删除当前Profile中重复的条目，每个主机名只保留最后一个；激活的Profile按设置自动重新应用。
+// 包含重复主机名的Profile无法保存，因此清理不记录撤销历史
+func (m *Manager) onRemoveDuplicateHostnames() {
+	current := m.controller.CurrentProfile()
+	if current == nil {
+		return
+	}
+
+	removed, err := m.controller.RemoveDuplicateHostnames()
+	if err != nil {
+		m.showErrorDialog("清理重复条目失败", err)
+		return
+	}
+	for _, entry := range removed {
+		m.recordActivity(models.EventHostEntryDeleted, entryActivityData(current, entry))
+	}
+
+	m.refreshHostEntryList()
+	m.refreshEnvironmentSelect()
+	m.statusBar.SetText(fmt.Sprintf("已删除%d个重复的Host条目", len(removed)))
+	if len(removed) > 0 {
+		m.scheduleAutoApply()
+	}
+}
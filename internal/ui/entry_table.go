@@ -0,0 +1,252 @@
+package ui
+
+import (
+	"fmt"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/flyhigher139/mhost/internal/controller"
+	"github.com/flyhigher139/mhost/pkg/hostsfile"
+	"github.com/flyhigher139/mhost/pkg/models"
+)
+
+// entryColumnTitles Host条目表格的列标题
+var entryColumnTitles = map[controller.EntryColumn]string{
+	controller.EntryColumnEnabled:  "启用",
+	controller.EntryColumnIP:       "IP地址",
+	controller.EntryColumnHostname: "主机名",
+	controller.EntryColumnComment:  "注释",
+	controller.EntryColumnUpdated:  "更新时间",
+}
+
+// defaultEntryColumnWidths 未保存列宽时使用的默认列宽
+var defaultEntryColumnWidths = map[controller.EntryColumn]float32{
+	controller.EntryColumnEnabled:  60,
+	controller.EntryColumnIP:       150,
+	controller.EntryColumnHostname: 220,
+	controller.EntryColumnComment:  260,
+	controller.EntryColumnUpdated:  140,
+}
+
+// createHostEntryTable 创建Host条目表格，点击列标题排序，拖动列标题之间的分隔线调整列宽
+func (m *Manager) createHostEntryTable() {
+	m.hostEntryTable = widget.NewTable(
+		func() (int, int) {
+			m.entryView = m.controller.SortedEntries()
+			return len(m.entryView), len(controller.EntryColumns)
+		},
+		func() fyne.CanvasObject {
+			enabled := widget.NewCheck("", nil)
+			enabled.Disable() // 只读显示
+			text := widget.NewLabel("")
+			text.Truncation = fyne.TextTruncateEllipsis
+			// 多选时标记选中的条目
+			background := canvas.NewRectangle(theme.Color(theme.ColorNameSelection))
+
+			// 右键显示快捷预设菜单
+			return newEntryRow(container.NewStack(background, enabled, text))
+		},
+		m.updateEntryCell,
+	)
+	m.hostEntryTable.ShowHeaderRow = true
+	m.hostEntryTable.CreateHeader = func() fyne.CanvasObject {
+		header := widget.NewButton("", nil)
+		header.Alignment = widget.ButtonAlignLeading
+		header.Importance = widget.LowImportance
+		return header
+	}
+	m.hostEntryTable.UpdateHeader = m.updateEntryHeader
+
+	m.hostEntryTable.OnSelected = func(id widget.TableCellID) {
+		if id.Row < 0 || id.Row >= len(m.entryView) {
+			return
+		}
+		entry := m.entryView[id.Row]
+		m.selectEntryWithModifiers(entry, currentKeyModifiers())
+		// 由条目背景显示选择，取消表格自身的单元格选择，再次点击同一单元格时仍会触发
+		m.hostEntryTable.UnselectAll()
+		m.hostEntryTable.Refresh()
+
+		if selected := m.controller.SelectedEntries(); len(selected) > 1 {
+			m.statusBar.SetText(fmt.Sprintf("已选择%d个Host条目，右键进行批量操作", len(selected)))
+			return
+		}
+		statusText := fmt.Sprintf("已选择Host条目: %s -> %s", entry.Hostname, entry.IP)
+		if hits := m.hitText(entry); hits != "" {
+			statusText += " | " + hits
+		}
+		m.statusBar.SetText(statusText)
+	}
+
+	m.applyEntryTableConfig()
+}
+
+// updateEntryCell 按列显示条目的内容
+func (m *Manager) updateEntryCell(id widget.TableCellID, obj fyne.CanvasObject) {
+	row := obj.(*entryRow)
+	stack := row.content.(*fyne.Container)
+	background := stack.Objects[0].(*canvas.Rectangle)
+	enabled := stack.Objects[1].(*widget.Check)
+	text := stack.Objects[2].(*widget.Label)
+
+	if id.Row < 0 || id.Row >= len(m.entryView) {
+		row.onSecondary = nil
+		background.Hide()
+		enabled.Hide()
+		text.SetText("")
+		return
+	}
+	entry := m.entryView[id.Row]
+	row.onSecondary = func(event *fyne.PointEvent) {
+		// 在多选的条目上右键时显示批量操作
+		if selected := m.controller.SelectedEntries(); len(selected) > 1 && m.controller.IsEntrySelected(entry) {
+			m.showBatchContextMenu(len(selected), event)
+			return
+		}
+		m.showEntryContextMenu(entry, event)
+		m.hostEntryTable.Refresh()
+	}
+	if m.controller.IsEntrySelected(entry) {
+		background.Show()
+	} else {
+		background.Hide()
+	}
+
+	column := controller.EntryColumns[id.Col]
+	if column == controller.EntryColumnEnabled {
+		enabled.SetChecked(entry.Enabled)
+		enabled.Show()
+		text.Hide()
+		return
+	}
+	enabled.Hide()
+	text.Show()
+	text.TextStyle = fyne.TextStyle{Bold: column == controller.EntryColumnHostname}
+	text.Importance = widget.MediumImportance
+	if !entry.Enabled || (column == controller.EntryColumnHostname && m.isDuplicateEntry(entry)) {
+		text.Importance = widget.WarningImportance
+	}
+	text.SetText(m.entryCellText(entry, column))
+}
+
+// entryCellText 条目在指定列中显示的文本
+func (m *Manager) entryCellText(entry *models.HostEntry, column controller.EntryColumn) string {
+	switch column {
+	case controller.EntryColumnIP:
+		if current := m.controller.CurrentProfile(); current != nil && current.Environment != "" {
+			if variant, ok := entry.Variants[current.Environment]; ok {
+				return fmt.Sprintf("%s (%s)", variant, current.Environment)
+			}
+		}
+		return entry.IP
+	case controller.EntryColumnHostname:
+		if m.isDuplicateEntry(entry) {
+			return entry.Hostname + " (重复)"
+		}
+		return entry.Hostname
+	case controller.EntryColumnComment:
+		meta := hostsfile.ParseComment(entry.Comment)
+		text := meta.Note
+		if badges := commentBadges(meta, time.Now()); badges != "" {
+			text += " " + badges
+		}
+		if len(entry.Variants) > 0 {
+			text += " 环境: " + formatVariants(entry.Variants, ", ")
+		}
+		return text
+	case controller.EntryColumnUpdated:
+		if entry.UpdatedAt.IsZero() {
+			return ""
+		}
+		return entry.UpdatedAt.Format("2006-01-02 15:04")
+	default:
+		return ""
+	}
+}
+
+// updateEntryHeader 显示列标题和排序方向，并记录当前列宽。
+// 表格在每次布局后更新列标题，此时标题的宽度就是拖动调整后的列宽
+func (m *Manager) updateEntryHeader(id widget.TableCellID, obj fyne.CanvasObject) {
+	header := obj.(*widget.Button)
+	if id.Col < 0 || id.Col >= len(controller.EntryColumns) {
+		header.SetText("")
+		header.SetIcon(nil)
+		header.OnTapped = nil
+		return
+	}
+	column := controller.EntryColumns[id.Col]
+
+	header.SetIcon(nil)
+	if sort := m.controller.EntrySort(); sort.Column == column {
+		if sort.Descending {
+			header.SetIcon(theme.MenuDropDownIcon())
+		} else {
+			header.SetIcon(theme.MenuDropUpIcon())
+		}
+	}
+	header.SetText(entryColumnTitles[column])
+	header.OnTapped = func() {
+		m.onSortEntries(column)
+	}
+
+	m.recordEntryColumnWidth(column, header.Size().Width)
+}
+
+// onSortEntries 点击列标题切换排序方式并保存到配置
+func (m *Manager) onSortEntries(column controller.EntryColumn) {
+	sort := m.controller.EntrySort().Next(column)
+	m.controller.SetEntrySort(sort)
+
+	m.appConfig.UI.EntrySortColumn = string(sort.Column)
+	m.appConfig.UI.EntrySortDescending = sort.Descending
+	if err := m.configManager.SaveConfig(m.appConfig); err != nil {
+		m.statusBar.SetText(fmt.Sprintf("保存排序方式失败: %v", err))
+	}
+
+	m.hostEntryTable.UnselectAll()
+	m.refreshHostEntryList()
+}
+
+// entryColumnWidth 列的当前宽度，未保存时使用默认列宽
+func (m *Manager) entryColumnWidth(column controller.EntryColumn) float32 {
+	if width, ok := m.appConfig.UI.EntryColumnWidths[string(column)]; ok && width > 0 {
+		return width
+	}
+	return defaultEntryColumnWidths[column]
+}
+
+// recordEntryColumnWidth 记录调整后的列宽，退出时随窗口大小一起保存
+func (m *Manager) recordEntryColumnWidth(column controller.EntryColumn, width float32) {
+	if width <= 0 || width == m.entryColumnWidth(column) {
+		return
+	}
+	if m.appConfig.UI.EntryColumnWidths == nil {
+		m.appConfig.UI.EntryColumnWidths = make(map[string]float32)
+	}
+	m.appConfig.UI.EntryColumnWidths[string(column)] = width
+}
+
+// applyEntryTableConfig 按配置恢复Host条目表格的排序方式和列宽，未知的排序列按Profile中的顺序显示
+func (m *Manager) applyEntryTableConfig() {
+	sort := controller.EntrySort{
+		Column:     controller.EntryColumn(m.appConfig.UI.EntrySortColumn),
+		Descending: m.appConfig.UI.EntrySortDescending,
+	}
+	if _, ok := entryColumnTitles[sort.Column]; !ok {
+		sort = controller.EntrySort{}
+	}
+	m.controller.SetEntrySort(sort)
+
+	if m.hostEntryTable == nil {
+		return
+	}
+	for i, column := range controller.EntryColumns {
+		m.hostEntryTable.SetColumnWidth(i, m.entryColumnWidth(column))
+	}
+	m.hostEntryTable.Refresh()
+}
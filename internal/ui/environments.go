@@ -0,0 +1,128 @@
+package ui
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/flyhigher139/mhost/pkg/hostsfile"
+	"github.com/flyhigher139/mhost/pkg/models"
+)
+
+// defaultEnvironmentLabel 环境选择器中表示使用条目默认IP的选项
+const defaultEnvironmentLabel = "默认"
+
+// environmentNameRegex 环境名格式
+var environmentNameRegex = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_.-]*$`)
+
+// createEnvironmentSelect 创建Profile级别的环境选择器
+func (m *Manager) createEnvironmentSelect() {
+	m.environmentSelect = widget.NewSelect([]string{defaultEnvironmentLabel}, nil)
+	m.environmentSelect.SetSelected(defaultEnvironmentLabel)
+	m.environmentSelect.Disable()
+}
+
+// refreshEnvironmentSelect 按当前Profile中的条目变体更新环境选择器
+func (m *Manager) refreshEnvironmentSelect() {
+	if m.environmentSelect == nil {
+		return
+	}
+
+	// 更新选项时不触发切换
+	m.environmentSelect.OnChanged = nil
+	defer func() { m.environmentSelect.OnChanged = m.onEnvironmentSelected }()
+
+	profile := m.controller.CurrentProfile()
+	if profile == nil {
+		m.environmentSelect.Options = []string{defaultEnvironmentLabel}
+		m.environmentSelect.SetSelected(defaultEnvironmentLabel)
+		m.environmentSelect.Disable()
+		return
+	}
+
+	environments := profile.Environments()
+	m.environmentSelect.Options = append([]string{defaultEnvironmentLabel}, environments...)
+	selected := defaultEnvironmentLabel
+	if profile.Environment != "" {
+		selected = profile.Environment
+		if !slices.Contains(environments, selected) {
+			// 所有条目都已删除该环境的变体时仍显示当前选择，便于切换回默认
+			m.environmentSelect.Options = append(m.environmentSelect.Options, selected)
+		}
+	}
+	m.environmentSelect.SetSelected(selected)
+	if len(m.environmentSelect.Options) > 1 {
+		m.environmentSelect.Enable()
+	} else {
+		m.environmentSelect.Disable()
+	}
+}
+
+// onEnvironmentSelected 切换当前Profile应用时使用的环境
+func (m *Manager) onEnvironmentSelected(selected string) {
+	profile := m.controller.CurrentProfile()
+	if profile == nil {
+		return
+	}
+
+	environment := selected
+	if selected == defaultEnvironmentLabel {
+		environment = ""
+	}
+	if environment == profile.Environment {
+		return
+	}
+
+	profile.Environment = environment
+	profile.UpdateTimestamp()
+	if err := m.controller.SaveCurrentProfile(); err != nil {
+		m.showErrorDialog("切换环境失败", err)
+		return
+	}
+	m.refreshHostEntryList()
+	m.notifyDaemon()
+
+	status := fmt.Sprintf("Profile '%s' 已切换到环境: %s", profile.Name, selected)
+	if profile.IsActive && !m.appConfig.UI.ApplyOnSave {
+		status += "，重新应用Profile后生效"
+	}
+	m.statusBar.SetText(status)
+	m.scheduleAutoApply()
+}
+
+// formatVariants 将环境变体格式化为 "环境=IP" 列表，按环境名排序
+func formatVariants(variants models.EntryVariants, separator string) string {
+	parts := make([]string, 0, len(variants))
+	for _, environment := range variants.Environments() {
+		parts = append(parts, environment+"="+variants[environment])
+	}
+	return strings.Join(parts, separator)
+}
+
+// parseVariants 解析每行一个 "环境=IP" 的环境变体，空内容返回nil
+func parseVariants(text string) (models.EntryVariants, error) {
+	var variants models.EntryVariants
+	for i, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+
+		environment, ip, ok := strings.Cut(line, "=")
+		environment, ip = strings.TrimSpace(environment), strings.TrimSpace(ip)
+		if !ok || !environmentNameRegex.MatchString(environment) || environment == defaultEnvironmentLabel {
+			return nil, fmt.Errorf("第%d行格式应为 环境=IP，例如 staging=10.0.0.2", i+1)
+		}
+		if err := hostsfile.ValidateIP(ip); err != nil {
+			return nil, fmt.Errorf("第%d行: %v", i+1, err)
+		}
+		if variants == nil {
+			variants = make(models.EntryVariants)
+		}
+		variants[environment] = ip
+	}
+	return variants, nil
+}
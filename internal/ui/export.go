@@ -0,0 +1,126 @@
+package ui
+
+import (
+	"fmt"
+	"os"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/flyhigher139/mhost/internal/exporter"
+	"github.com/flyhigher139/mhost/internal/plugins"
+	"github.com/flyhigher139/mhost/internal/telemetry"
+)
+
+// exportFormatJSON mHost自身的Profile JSON格式
+const exportFormatJSON = "json"
+
+// onExportProfile 导出当前Profile，支持JSON、hosts、docker-compose和Kubernetes格式以及导出插件提供的格式
+func (m *Manager) onExportProfile() {
+	profile := m.controller.CurrentProfile()
+	if profile == nil {
+		dialog.ShowInformation("提示", "请先选择要导出的Profile", m.window)
+		return
+	}
+
+	formats := []string{exportFormatJSON}
+	for _, format := range exporter.Formats() {
+		formats = append(formats, string(format))
+	}
+	exportPlugins := plugins.Exporters(m.plugins)
+	for _, plugin := range exportPlugins {
+		formats = append(formats, plugin.Name)
+	}
+	formatSelect := widget.NewSelect(formats, nil)
+	formatSelect.SetSelected(exportFormatJSON)
+
+	serviceEntry := widget.NewEntry()
+	serviceEntry.SetPlaceHolder("可选，仅用于compose格式")
+
+	form := &widget.Form{
+		Items: []*widget.FormItem{
+			{Text: "格式", Widget: formatSelect},
+			{Text: "服务名称", Widget: serviceEntry, HintText: "为空时仅导出extra_hosts片段"},
+		},
+	}
+
+	d := dialog.NewCustomConfirm("导出Profile", "导出", "取消", form, func(confirmed bool) {
+		if !confirmed {
+			return
+		}
+
+		format := formatSelect.Selected
+		if format == exportFormatJSON {
+			m.saveExport(profile.Name+".json", func(path string) error {
+				if err := m.profileManager.ExportProfile(profile.ID, path); err != nil {
+					return err
+				}
+				m.recordUsage(telemetry.EventExportProfile)
+				return nil
+			})
+			return
+		}
+
+		var exp exporter.Exporter
+		var err error
+		if plugin := plugins.Find(exportPlugins, format); plugin != nil {
+			exp = plugin
+		} else if exp, err = exporter.New(exporter.Format(format)); err != nil {
+			m.showErrorDialog("导出失败", err)
+			return
+		}
+		if format == string(exporter.FormatHosts) {
+			m.saveExport(profile.Name+exp.FileExtension(), func(path string) error {
+				if err := m.profileManager.ExportProfileAsHosts(profile.ID, path); err != nil {
+					return err
+				}
+				m.recordUsage(telemetry.EventExportProfile)
+				return nil
+			})
+			return
+		}
+		if compose, ok := exp.(*exporter.ComposeExporter); ok {
+			compose.ServiceName = serviceEntry.Text
+		}
+
+		data, err := exp.Export(profile)
+		if err != nil {
+			m.showErrorDialog("导出失败", err)
+			return
+		}
+
+		m.saveExport(profile.Name+exp.FileExtension(), func(path string) error {
+			if err := os.WriteFile(path, data, 0644); err != nil {
+				return err
+			}
+			m.recordUsage(telemetry.EventExportProfile)
+			return nil
+		})
+	}, m.window)
+	d.Resize(fyne.NewSize(400, 220))
+	d.Show()
+}
+
+// saveExport 选择保存位置并写入导出内容
+func (m *Manager) saveExport(fileName string, write func(path string) error) {
+	saveDialog := dialog.NewFileSave(func(writer fyne.URIWriteCloser, err error) {
+		if err != nil {
+			m.showErrorDialog("导出失败", err)
+			return
+		}
+		if writer == nil {
+			return
+		}
+		path := writer.URI().Path()
+		writer.Close()
+
+		if err := write(path); err != nil {
+			m.showErrorDialog("导出失败", err)
+			return
+		}
+		m.statusBar.SetText(fmt.Sprintf("已导出到 %s", path))
+	}, m.window)
+	saveDialog.SetFileName(fileName)
+	saveDialog.Show()
+}
@@ -0,0 +1,132 @@
+package ui
+
+import (
+	"fmt"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/flyhigher139/mhost/pkg/models"
+)
+
+// formatGlobalEntry 格式化全局条目列表中的一行
+func formatGlobalEntry(entry *models.HostEntry) string {
+	text := fmt.Sprintf("%s  %s", entry.IP, entry.Hostname)
+	if !entry.Enabled {
+		text += "  (已禁用)"
+	}
+	return text
+}
+
+// onMakeEntryGlobal 将选中的Host条目设为全局条目，应用任何Profile时都会写入hosts文件
+func (m *Manager) onMakeEntryGlobal() {
+	if m.controller.CurrentEntry() == nil {
+		dialog.ShowInformation("提示", "请先选择要设为全局条目的Host条目", m.window)
+		return
+	}
+
+	message := "将选中的Host条目从当前Profile移到全局条目吗？\n\n全局条目在切换Profile后仍然保留，同名的Profile条目会被覆盖。"
+	dialog.ShowConfirm("设为全局条目", message, func(confirmed bool) {
+		if !confirmed {
+			return
+		}
+
+		entry, err := m.controller.MakeCurrentEntryGlobal()
+		if err != nil {
+			m.showErrorDialog("设为全局条目失败", err)
+			return
+		}
+		data := entryActivityData(m.controller.CurrentProfile(), entry)
+		data["global"] = true
+		m.recordActivity(models.EventHostEntryUpdated, data)
+
+		m.hostEntryTable.UnselectAll()
+		m.refreshHostEntryList()
+		m.refreshEnvironmentSelect()
+		m.statusBar.SetText(fmt.Sprintf("Host条目 '%s' 已设为全局条目", entry.Hostname))
+		m.reapplyGlobalEntries()
+	}, m.window)
+}
+
+// onShowGlobalEntries 显示全局条目，可将选中的条目移回当前Profile或删除
+func (m *Manager) onShowGlobalEntries() {
+	entries, err := m.controller.GlobalEntries()
+	if err != nil {
+		m.showErrorDialog("读取全局条目失败", err)
+		return
+	}
+	if len(entries) == 0 {
+		dialog.ShowInformation("全局条目", "没有全局条目\n\n在编辑菜单中选择\"设为全局条目\"可将选中的Host条目设为全局条目。", m.window)
+		return
+	}
+
+	selected := -1
+	list := widget.NewList(
+		func() int { return len(entries) },
+		func() fyne.CanvasObject { return widget.NewLabel("") },
+		func(id widget.ListItemID, obj fyne.CanvasObject) {
+			obj.(*widget.Label).SetText(formatGlobalEntry(entries[id]))
+		},
+	)
+	list.OnSelected = func(id widget.ListItemID) { selected = id }
+
+	header := widget.NewLabel("全局条目在应用任何Profile时都会写入hosts文件")
+	header.Wrapping = fyne.TextWrapWord
+
+	var d dialog.Dialog
+	remove := func(toCurrent bool) {
+		if selected < 0 {
+			dialog.ShowInformation("提示", "请先选择全局条目", m.window)
+			return
+		}
+		entry, err := m.controller.RemoveGlobalEntry(entries[selected].ID, toCurrent)
+		if err != nil {
+			m.showErrorDialog("更新全局条目失败", err)
+			return
+		}
+		d.Hide()
+
+		data := entryActivityData(nil, entry)
+		data["global"] = true
+		if toCurrent {
+			data["profile_name"] = m.controller.CurrentProfile().Name
+			m.recordActivity(models.EventHostEntryUpdated, data)
+			m.refreshHostEntryList()
+			m.refreshEnvironmentSelect()
+			m.statusBar.SetText(fmt.Sprintf("全局条目 '%s' 已移回当前Profile", entry.Hostname))
+		} else {
+			m.recordActivity(models.EventHostEntryDeleted, data)
+			m.statusBar.SetText(fmt.Sprintf("全局条目 '%s' 已删除", entry.Hostname))
+		}
+		m.reapplyGlobalEntries()
+	}
+
+	buttons := container.NewHBox(
+		widget.NewButton("移回当前Profile", func() { remove(true) }),
+		widget.NewButton("删除", func() { remove(false) }),
+	)
+
+	content := container.NewBorder(header, buttons, nil, nil, list)
+	d = dialog.NewCustom("全局条目", "关闭", content, m.window)
+	d.Resize(fyne.NewSize(500, 360))
+	d.Show()
+}
+
+// reapplyGlobalEntries 全局条目变化后重新应用激活的Profile，使hosts文件保持一致
+func (m *Manager) reapplyGlobalEntries() {
+	go func() {
+		result, err := m.controller.ReapplyActive()
+		if err != nil {
+			m.showErrorDialog("更新hosts文件失败", err)
+			return
+		}
+		if result == nil {
+			return
+		}
+		m.recordApply(result)
+		m.recordActivity(models.EventProfileActivated, applyActivityData(result))
+		m.statusBar.SetText(fmt.Sprintf("全局条目已更新: %s", applySummaryText(result)))
+	}()
+}
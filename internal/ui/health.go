@@ -0,0 +1,175 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/flyhigher139/mhost/internal/healthcheck"
+)
+
+// createHealthBadge 创建工具栏中的验证问题标记，没有问题时隐藏
+func (m *Manager) createHealthBadge() *widget.Button {
+	m.healthBadge = widget.NewButtonWithIcon("", theme.WarningIcon(), m.onShowHealthReport)
+	m.healthBadge.Importance = widget.WarningImportance
+	m.healthBadge.Hide()
+	return m.healthBadge
+}
+
+// startHealthCheck 按设置启动后台定期验证
+func (m *Manager) startHealthCheck() {
+	m.healthChecker = healthcheck.NewChecker(m.hostManager, m.profileManager, m.workspace.BackupDir)
+	m.healthChecker.OnReport(m.updateHealthBadge)
+
+	if !m.appConfig.HealthCheck.Enabled {
+		m.updateHealthBadge(nil)
+		return
+	}
+	if err := m.healthChecker.Start(m.appConfig.HealthCheck.Interval); err != nil {
+		fmt.Printf("Failed to start health check: %v\n", err)
+	}
+}
+
+// stopHealthCheck 停止后台定期验证
+func (m *Manager) stopHealthCheck() {
+	if m.healthChecker != nil {
+		m.healthChecker.Stop()
+	}
+}
+
+// updateHealthBadge 根据验证报告更新工具栏标记
+func (m *Manager) updateHealthBadge(report *healthcheck.Report) {
+	m.recordDrift(report)
+	if m.healthBadge == nil {
+		return
+	}
+	if report == nil || report.Total() == 0 {
+		m.healthBadge.Hide()
+		return
+	}
+	m.healthBadge.SetText(fmt.Sprintf("%d个问题", report.Total()))
+	m.healthBadge.Show()
+}
+
+// recheckHealth 应用Profile等修改hosts文件的操作后在后台重新验证，及时更新标记
+func (m *Manager) recheckHealth() {
+	if m.healthChecker == nil || !m.appConfig.HealthCheck.Enabled {
+		return
+	}
+	go m.healthChecker.Check()
+}
+
+// onShowHealthReport 显示最近一次的验证报告，尚未验证时立即运行
+func (m *Manager) onShowHealthReport() {
+	if m.healthChecker == nil {
+		return
+	}
+	if report := m.healthChecker.LastReport(); report != nil {
+		m.showHealthReport(report)
+		return
+	}
+	m.runHealthCheck()
+}
+
+// runHealthCheck 立即运行验证并显示报告
+func (m *Manager) runHealthCheck() {
+	progressDialog := dialog.NewProgressInfinite("验证", "正在验证hosts文件和备份，请稍候...", m.window)
+	progressDialog.Show()
+
+	go func() {
+		report := m.healthChecker.Check()
+		progressDialog.Hide()
+		m.showHealthReport(report)
+	}()
+}
+
+// showHealthReport 显示汇总的验证报告
+func (m *Manager) showHealthReport(report *healthcheck.Report) {
+	text := formatHealthReport(report)
+
+	var d dialog.Dialog
+	reportText := widget.NewMultiLineEntry()
+	reportText.SetText(text)
+	reportText.Wrapping = fyne.TextWrapWord
+
+	buttons := container.NewHBox(
+		widget.NewButton("重新验证", func() {
+			d.Hide()
+			m.runHealthCheck()
+		}),
+		widget.NewButton("完整验证报告", func() {
+			d.Hide()
+			m.onValidateHosts()
+		}),
+	)
+	if report.DriftCount() > 0 {
+		buttons.Add(widget.NewButton("导入手动修改", func() {
+			d.Hide()
+			m.onImportManualEdits()
+		}))
+	}
+
+	content := container.NewBorder(nil, buttons, nil, nil, container.NewScroll(reportText))
+	d = dialog.NewCustom("定期验证报告", "关闭", content, m.window)
+	d.Resize(fyne.NewSize(600, 450))
+	d.Show()
+}
+
+// formatHealthReport 格式化验证报告
+func formatHealthReport(report *healthcheck.Report) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "验证时间: %s\n", report.GeneratedAt.Format("2006-01-02 15:04:05"))
+	fmt.Fprintf(&b, "Hosts文件: %s\n", report.HostsPath)
+	if report.Total() == 0 {
+		b.WriteString("\n没有发现问题")
+		return b.String()
+	}
+
+	section := func(title string, lines []string) {
+		if len(lines) == 0 {
+			return
+		}
+		fmt.Fprintf(&b, "\n%s (%d):\n", title, len(lines))
+		for _, line := range lines {
+			fmt.Fprintf(&b, "  %s\n", line)
+		}
+	}
+
+	var syntax, duplicates []string
+	for _, issue := range report.Syntax {
+		syntax = append(syntax, issue.String())
+	}
+	for _, issue := range report.Duplicates {
+		duplicates = append(duplicates, issue.String())
+	}
+	section("语法错误", syntax)
+	section("重复主机名", duplicates)
+
+	if report.Drift != nil {
+		var drift []string
+		for _, entry := range report.Drift.Added {
+			drift = append(drift, fmt.Sprintf("+ %s -> %s", entry.Hostname, entry.IP))
+		}
+		for _, change := range report.Drift.Changed {
+			drift = append(drift, fmt.Sprintf("~ %s: %s -> %s", change.Expected.Hostname, change.Expected.IP, change.Actual.IP))
+		}
+		for _, entry := range report.Drift.Removed {
+			drift = append(drift, fmt.Sprintf("- %s -> %s", entry.Hostname, entry.IP))
+		}
+		section(fmt.Sprintf("与激活的Profile '%s' 不一致", report.DriftProfile), drift)
+	}
+
+	var backups []string
+	for _, problem := range report.Backups {
+		backups = append(backups, fmt.Sprintf("%s: %s", problem.Path, problem.Reason))
+	}
+	section("备份完整性", backups)
+	section("无法完成的检查", report.Failures)
+
+	return strings.TrimRight(b.String(), "\n")
+}
@@ -0,0 +1,224 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/flyhigher139/mhost/internal/helper"
+)
+
+// helperAccessTimeout Helper访问控制对话框中单次操作的最长等待时间，各请求另有配置的超时
+const helperAccessTimeout = 30 * time.Second
+
+// newHelperClient 创建Helper XPC客户端，并应用设置中的请求超时
+func (m *Manager) newHelperClient() helper.Client {
+	client := helper.NewXPCClient(helper.DefaultServiceName, m.logger)
+	m.applyHelperTimeouts(client)
+	return client
+}
+
+// applyHelperTimeouts 将设置中的请求超时应用到Helper客户端
+func (m *Manager) applyHelperTimeouts(client helper.Client) {
+	if m.appConfig.XPC.Timeout > 0 {
+		client.SetTimeout(m.appConfig.XPC.Timeout)
+	}
+	for operation, timeout := range m.appConfig.XPC.OperationTimeouts {
+		client.SetOperationTimeout(operation, timeout)
+	}
+}
+
+// onManageHelperAccess 查看和修改Helper的白名单、黑名单以及当前被限速的客户端
+func (m *Manager) onManageHelperAccess() {
+	if !helper.IsInstalled(helper.DefaultServiceName) {
+		dialog.ShowInformation("Helper访问控制", "特权Helper未安装，没有可管理的访问控制", m.window)
+		return
+	}
+
+	client := m.newHelperClient()
+	if err := client.Connect(); err != nil {
+		m.showHelperError("连接Helper失败", err, m.onManageHelperAccess)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), helperAccessTimeout)
+	defer cancel()
+	access, err := client.GetClientAccess(ctx)
+	if err != nil {
+		client.Disconnect()
+		m.showHelperError("读取访问控制失败", err, m.onManageHelperAccess)
+		return
+	}
+	m.showHelperAccess(client, access)
+}
+
+// onShowHelperStatus 查看Helper的请求统计，包括延迟分位数和按操作的请求数
+func (m *Manager) onShowHelperStatus() {
+	if !helper.IsInstalled(helper.DefaultServiceName) {
+		dialog.ShowInformation("Helper状态", "特权Helper未安装", m.window)
+		return
+	}
+
+	client := m.newHelperClient()
+	if err := client.Connect(); err != nil {
+		m.showHelperError("连接Helper失败", err, m.onShowHelperStatus)
+		return
+	}
+	defer client.Disconnect()
+
+	ctx, cancel := context.WithTimeout(context.Background(), helperAccessTimeout)
+	defer cancel()
+	status, err := client.GetStatus(ctx)
+	if err != nil {
+		m.showHelperError("读取Helper状态失败", err, m.onShowHelperStatus)
+		return
+	}
+	stats, err := helper.DecodeXPCStats(status)
+	if err != nil {
+		m.showErrorDialog("读取Helper状态失败", err)
+		return
+	}
+
+	text := widget.NewLabel(formatXPCStats(stats))
+	text.TextStyle = fyne.TextStyle{Monospace: true}
+	d := dialog.NewCustom("Helper状态", "关闭", container.NewScroll(text), m.window)
+	d.Resize(fyne.NewSize(760, 420))
+	d.Show()
+}
+
+// formatXPCStats 格式化Helper的请求统计：总体延迟分位数以及按操作名称排序的明细
+func formatXPCStats(stats *helper.XPCServerStats) string {
+	if stats == nil {
+		return "该版本的Helper不提供请求统计"
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "请求总数: %d（成功 %d，失败 %d）\n", stats.TotalRequests, stats.SuccessRequests, stats.FailedRequests)
+	fmt.Fprintf(&b, "延迟: 平均 %.1fms  p50 %.1fms  p95 %.1fms  p99 %.1fms\n",
+		stats.AverageLatency, stats.P50Latency, stats.P95Latency, stats.P99Latency)
+	if len(stats.Operations) == 0 {
+		return b.String()
+	}
+
+	operations := make([]string, 0, len(stats.Operations))
+	for operation := range stats.Operations {
+		operations = append(operations, operation)
+	}
+	sort.Strings(operations)
+
+	fmt.Fprintf(&b, "\n%-22s %8s %6s %9s %9s %9s %9s\n", "操作", "请求", "失败", "平均ms", "p50", "p95", "p99")
+	for _, operation := range operations {
+		op := stats.Operations[operation]
+		fmt.Fprintf(&b, "%-22s %8d %6d %9.1f %9.1f %9.1f %9.1f\n",
+			operation, op.Count, op.Failed, op.AverageLatency, op.P50Latency, op.P95Latency, op.P99Latency)
+	}
+	return b.String()
+}
+
+// showHelperAccess 显示访问控制对话框，选中列表中的客户端后可加入或移出白名单、封禁或解封
+func (m *Manager) showHelperAccess(client helper.Client, access *helper.ClientAccess) {
+	clientEntry := widget.NewEntry()
+	clientEntry.SetPlaceHolder("客户端ID")
+
+	var whitelist, blacklist, rateLimited *widget.List
+	newList := func(length func() int, text func(int) string, clientID func(int) string) *widget.List {
+		list := widget.NewList(
+			length,
+			func() fyne.CanvasObject { return widget.NewLabel("") },
+			func(id widget.ListItemID, obj fyne.CanvasObject) {
+				obj.(*widget.Label).SetText(text(id))
+			},
+		)
+		list.OnSelected = func(id widget.ListItemID) {
+			clientEntry.SetText(clientID(id))
+		}
+		return list
+	}
+	whitelist = newList(
+		func() int { return len(access.Whitelist) },
+		func(i int) string { return access.Whitelist[i] },
+		func(i int) string { return access.Whitelist[i] },
+	)
+	blacklist = newList(
+		func() int { return len(access.Blacklist) },
+		func(i int) string { return formatBlockedClient(access.Blacklist[i], time.Now()) },
+		func(i int) string { return access.Blacklist[i].ClientID },
+	)
+	rateLimited = newList(
+		func() int { return len(access.RateLimited) },
+		func(i int) string { return formatRateLimitedClient(access.RateLimited[i]) },
+		func(i int) string { return access.RateLimited[i].ClientID },
+	)
+
+	var update func(action helper.ClientAccessAction) func()
+	update = func(action helper.ClientAccessAction) func() {
+		return func() {
+			clientID := strings.TrimSpace(clientEntry.Text)
+			if clientID == "" && action != helper.ActionClearBlacklist {
+				dialog.ShowInformation("提示", "请输入或从列表中选择客户端ID", m.window)
+				return
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), helperAccessTimeout)
+			defer cancel()
+			updated, err := client.UpdateClientAccess(ctx, action, clientID)
+			if err != nil {
+				m.showHelperError("修改访问控制失败", err, update(action))
+				return
+			}
+
+			*access = *updated
+			whitelist.UnselectAll()
+			blacklist.UnselectAll()
+			rateLimited.UnselectAll()
+			whitelist.Refresh()
+			blacklist.Refresh()
+			rateLimited.Refresh()
+			m.statusBar.SetText("Helper访问控制已更新")
+		}
+	}
+
+	lists := container.NewGridWithColumns(3,
+		widget.NewCard("白名单", "不受速率限制", whitelist),
+		widget.NewCard("黑名单", "到期后自动解封", blacklist),
+		widget.NewCard("当前请求", "最近一分钟", rateLimited),
+	)
+	buttons := container.NewGridWithColumns(5,
+		widget.NewButton("加入白名单", update(helper.ActionWhitelistAdd)),
+		widget.NewButton("移出白名单", update(helper.ActionWhitelistRemove)),
+		widget.NewButton("封禁", update(helper.ActionBlacklistAdd)),
+		widget.NewButton("解封", update(helper.ActionBlacklistRemove)),
+		widget.NewButton("清空黑名单", update(helper.ActionClearBlacklist)),
+	)
+
+	content := container.NewBorder(nil, container.NewVBox(clientEntry, buttons), nil, nil, lists)
+	d := dialog.NewCustom("Helper访问控制", "关闭", content, m.window)
+	d.SetOnClosed(func() { client.Disconnect() })
+	d.Resize(fyne.NewSize(760, 420))
+	d.Show()
+}
+
+// formatBlockedClient 格式化黑名单中的客户端及剩余封禁时间
+func formatBlockedClient(blocked helper.BlockedClient, now time.Time) string {
+	remaining := blocked.Expiry.Sub(now).Round(time.Minute)
+	if remaining < time.Minute {
+		return fmt.Sprintf("%s（即将解封）", blocked.ClientID)
+	}
+	return fmt.Sprintf("%s（%s后解封）", blocked.ClientID, formatAge(remaining))
+}
+
+// formatRateLimitedClient 格式化客户端在当前时间窗口内的请求数
+func formatRateLimitedClient(client helper.RateLimitedClient) string {
+	text := fmt.Sprintf("%s: %d/%d", client.ClientID, client.Requests, client.Limit)
+	if client.Limited {
+		text += "（已限速）"
+	}
+	return text
+}
@@ -0,0 +1,97 @@
+package ui
+
+import (
+	"fmt"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/flyhigher139/mhost/internal/helper"
+	apperrors "github.com/flyhigher139/mhost/pkg/errors"
+)
+
+// errorAdvice 根据结构化错误给用户的说明
+type errorAdvice struct {
+	Message   string
+	Hint      string
+	Retryable bool // 稍后重试可能成功
+}
+
+// Text 说明和建议合并后的文本
+func (a errorAdvice) Text() string {
+	if a.Hint == "" {
+		return a.Message
+	}
+	return a.Message + "\n\n" + a.Hint
+}
+
+// adviseError 按错误代码和类型给出说明，错误链中没有AppError时返回false
+func adviseError(err error) (errorAdvice, bool) {
+	appErr := apperrors.GetAppError(err)
+	if appErr == nil {
+		return errorAdvice{}, false
+	}
+
+	switch appErr.Code() {
+	case apperrors.ErrCodeClientBlacklisted, apperrors.ErrCodeRateLimitExceeded:
+		return errorAdvice{
+			Message: "请求过于频繁，Helper暂时拒绝了此客户端的请求",
+			Hint:    "封禁到期后会自动解除，也可以在 设置 > Helper访问 中解封",
+		}, true
+	case apperrors.ErrCodeSignatureVerificationFailed, apperrors.ErrCodeCertificateInvalid:
+		return errorAdvice{
+			Message: "Helper签名校验失败",
+			Hint:    "请重新安装由可信团队签名的Helper",
+		}, true
+	case apperrors.ErrCodeInsufficientSpace:
+		return errorAdvice{
+			Message: "备份目录所在磁盘空间不足，Helper未创建备份",
+			Hint:    "请清理旧备份或释放磁盘空间后重试",
+		}, true
+	case apperrors.ErrCodeXPCRequestTimeout:
+		return errorAdvice{
+			Message:   "Helper响应超时",
+			Hint:      "条目较多时写入需要更长时间，可以在设置中延长Helper超时",
+			Retryable: true,
+		}, true
+	}
+
+	switch appErr.Type() {
+	case apperrors.ErrorTypePermission:
+		return errorAdvice{Message: "权限不足，Helper拒绝了该操作", Hint: "请确认Helper已正确安装并获得授权"}, true
+	case apperrors.ErrorTypeValidation:
+		return errorAdvice{Message: "请求无效，Helper未执行该操作", Hint: "请检查输入的条目和参数"}, true
+	case apperrors.ErrorTypeNetwork:
+		return errorAdvice{Message: "无法连接Helper", Hint: "Helper可能未运行或正在重启", Retryable: true}, true
+	case apperrors.ErrorTypeFileSystem:
+		return errorAdvice{Message: "Helper无法读写文件", Hint: "请检查hosts文件和备份目录的权限与磁盘空间"}, true
+	default:
+		return errorAdvice{Message: "Helper执行操作时出错", Retryable: true}, true
+	}
+}
+
+// showHelperError 显示Helper操作的错误；可重试的错误提供重试按钮
+func (m *Manager) showHelperError(title string, err error, retry func()) {
+	advice, ok := adviseError(err)
+	if !ok || !advice.Retryable || retry == nil {
+		m.showErrorDialog(title, err)
+		return
+	}
+	m.recordError(title, err)
+
+	message := fmt.Sprintf("%s\n\n原始错误: %v", advice.Text(), err)
+	if requestID := helper.RequestIDOf(err); requestID != "" {
+		message += "\n请求ID: " + requestID
+	}
+	label := widget.NewLabel(message)
+	label.Wrapping = fyne.TextWrapWord
+	d := dialog.NewCustomConfirm(title, "重试", "关闭", label, func(ok bool) {
+		if ok {
+			retry()
+		}
+	}, m.window)
+	d.Resize(fyne.NewSize(450, 220))
+	d.Show()
+	m.statusBar.SetText(fmt.Sprintf("错误: %s", advice.Message))
+}
@@ -0,0 +1,130 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/flyhigher139/mhost/internal/helper"
+)
+
+// helperQueueInterval 检查Helper连接并发送缓存请求的间隔
+const helperQueueInterval = 30 * time.Second
+
+// createQueueBadge 创建工具栏中的待发送请求标记，没有待发送请求时隐藏
+func (m *Manager) createQueueBadge() *widget.Button {
+	m.queueBadge = widget.NewButtonWithIcon("", theme.UploadIcon(), m.onShowPendingRequests)
+	m.queueBadge.Hide()
+	return m.queueBadge
+}
+
+// startHelperQueue Helper已安装时启动请求队列，Helper暂时不可用时缓存非紧急操作
+func (m *Manager) startHelperQueue() {
+	if !helper.IsInstalled(helper.DefaultServiceName) {
+		return
+	}
+
+	m.helperClient = m.newHelperClient()
+	m.helperQueue = helper.NewRequestQueue(m.helperClient, helper.DefaultQueueSize)
+	m.helperQueue.OnChange(m.updateQueueBadge)
+	if err := m.helperQueue.Start(helperQueueInterval); err != nil {
+		fmt.Printf("Failed to start helper request queue: %v\n", err)
+	}
+}
+
+// stopHelperQueue 停止请求队列的定时发送
+func (m *Manager) stopHelperQueue() {
+	if m.helperQueue != nil {
+		m.helperQueue.Stop()
+	}
+}
+
+// queueHelperRequest 在后台向Helper发送非紧急请求，Helper不可用时排队等待
+func (m *Manager) queueHelperRequest(operation string, params map[string]interface{}) {
+	if m.helperQueue == nil {
+		return
+	}
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), m.helperClient.TimeoutFor(operation))
+		defer cancel()
+		if _, _, err := m.helperQueue.Submit(ctx, operation, params); err != nil {
+			fmt.Printf("Failed to send %s to helper: %v\n", operation, err)
+		}
+	}()
+}
+
+// updateQueueBadge 根据待发送的请求数更新工具栏标记
+func (m *Manager) updateQueueBadge(pending int) {
+	if m.queueBadge == nil {
+		return
+	}
+	if pending == 0 {
+		m.queueBadge.Hide()
+		return
+	}
+	m.queueBadge.SetText(fmt.Sprintf("%d个待发送", pending))
+	m.queueBadge.Show()
+}
+
+// onShowPendingRequests 显示等待Helper恢复后发送的请求，可立即重试
+func (m *Manager) onShowPendingRequests() {
+	if m.helperQueue == nil {
+		return
+	}
+
+	text := widget.NewMultiLineEntry()
+	text.SetText(formatPendingRequests(m.helperQueue.Pending(), m.helperQueue.Dropped(), time.Now()))
+	text.Wrapping = fyne.TextWrapWord
+
+	var d dialog.Dialog
+	retry := widget.NewButton("立即重试", func() {
+		d.Hide()
+		go m.flushHelperQueue()
+	})
+
+	d = dialog.NewCustom("待发送的Helper请求", "关闭", container.NewBorder(nil, retry, nil, nil, container.NewScroll(text)), m.window)
+	d.Resize(fyne.NewSize(500, 350))
+	d.Show()
+}
+
+// flushHelperQueue 立即发送缓存的请求，Helper仍不可用时可再次重试
+func (m *Manager) flushHelperQueue() {
+	ctx, cancel := context.WithTimeout(context.Background(), helperQueueInterval)
+	defer cancel()
+
+	sent, err := m.helperQueue.Flush(ctx)
+	if err != nil {
+		m.showHelperError("Helper仍不可用", err, func() { go m.flushHelperQueue() })
+		return
+	}
+	m.statusBar.SetText(fmt.Sprintf("已向Helper发送 %d 个请求", sent))
+}
+
+// formatPendingRequests 格式化待发送的请求列表
+func formatPendingRequests(pending []helper.QueuedRequest, dropped int, now time.Time) string {
+	if len(pending) == 0 {
+		return "没有待发送的请求"
+	}
+
+	lines := make([]string, 0, len(pending)+2)
+	lines = append(lines, fmt.Sprintf("Helper暂时不可用，%d 个请求将在连接恢复后发送:", len(pending)))
+	for _, req := range pending {
+		line := fmt.Sprintf("  %s（%s前排队）", req.Operation, formatAge(now.Sub(req.QueuedAt)))
+		if req.LastError != "" {
+			line += ": " + req.LastError
+		}
+		lines = append(lines, line)
+	}
+	if dropped > 0 {
+		lines = append(lines, fmt.Sprintf("队列已满，已丢弃 %d 个最早的请求", dropped))
+	}
+	return strings.Join(lines, "\n")
+}
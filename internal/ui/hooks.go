@@ -0,0 +1,36 @@
+package ui
+
+import (
+	"fmt"
+
+	"github.com/flyhigher139/mhost/internal/hooks"
+)
+
+// logHookOutput 将Hook脚本中print的内容写入日志
+func (m *Manager) logHookOutput(hook, message string) {
+	m.logger.Info(message, "hook", hook)
+}
+
+// loadHooks 按当前配置加载转换Hook并设置到hosts管理器，加载失败时保留原来的Hook
+func (m *Manager) loadHooks() error {
+	runner, err := hooks.LoadConfig(m.appConfig.Hooks, m.workspace.DataDir)
+	if err != nil {
+		return err
+	}
+	runner.SetPrint(m.logHookOutput)
+	m.hostManager.SetHooks(runner)
+	return nil
+}
+
+// onReloadHooks 修改脚本后重新加载转换Hook
+func (m *Manager) onReloadHooks() {
+	if err := m.loadHooks(); err != nil {
+		m.showErrorDialog("加载Hook脚本失败", err)
+		return
+	}
+	if !m.appConfig.Hooks.Enabled {
+		m.statusBar.SetText("Hook脚本未启用，可在配置文件中设置hooks.enabled")
+		return
+	}
+	m.statusBar.SetText(fmt.Sprintf("已加载%d个Hook脚本", m.hostManager.Hooks().Len()))
+}
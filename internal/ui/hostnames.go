@@ -0,0 +1,76 @@
+package ui
+
+import (
+	"fmt"
+
+	"fyne.io/fyne/v2/dialog"
+
+	"github.com/flyhigher139/mhost/pkg/hostsfile"
+)
+
+// hostnameNormalizer 按当前配置返回主机名规范化规则
+func (m *Manager) hostnameNormalizer() hostsfile.Normalizer {
+	return hostsfile.Normalizer{AllowUnderscores: m.appConfig.Hostnames.AllowUnderscores}
+}
+
+// countUnnormalizedHostnames 统计所有Profile中主机名尚未规范化的条目数
+func (m *Manager) countUnnormalizedHostnames() int {
+	count := 0
+	for _, profile := range m.controller.Profiles() {
+		count += hostsfile.CountUnnormalized(profile.Entries)
+	}
+	return count
+}
+
+// offerHostnameMigration 存在未规范化的主机名时提示一次是否规范化，无论选择如何都不再提示
+func (m *Manager) offerHostnameMigration() {
+	if m.appConfig.Hostnames.MigrationOffered {
+		return
+	}
+
+	count := m.countUnnormalizedHostnames()
+	if count > 0 {
+		message := fmt.Sprintf("发现%d个条目的主机名包含大写字母、首尾空白或末尾的点。\n\n是否将它们规范化（转为小写并去掉末尾的点）？\n之后也可以通过“工具 > 规范化主机名”执行。", count)
+		dialog.ShowConfirm("规范化主机名", message, func(confirmed bool) {
+			if confirmed {
+				m.normalizeHostnames()
+			}
+		}, m.window)
+	}
+
+	m.appConfig.Hostnames.MigrationOffered = true
+	if err := m.configManager.SaveConfig(m.appConfig); err != nil {
+		m.statusBar.SetText(fmt.Sprintf("保存配置失败: %v", err))
+	}
+}
+
+// onNormalizeHostnames 规范化所有Profile中已有条目的主机名
+func (m *Manager) onNormalizeHostnames() {
+	count := m.countUnnormalizedHostnames()
+	if count == 0 {
+		dialog.ShowInformation("规范化主机名", "所有条目的主机名均已规范化", m.window)
+		return
+	}
+
+	message := fmt.Sprintf("确定要规范化%d个条目的主机名吗？\n\n主机名将转为小写并去掉首尾空白和末尾的点。", count)
+	dialog.ShowConfirm("规范化主机名", message, func(confirmed bool) {
+		if confirmed {
+			m.normalizeHostnames()
+		}
+	}, m.window)
+}
+
+// normalizeHostnames 执行规范化并刷新界面
+func (m *Manager) normalizeHostnames() {
+	changed, err := m.profileManager.NormalizeHostnames()
+	if err != nil {
+		m.showErrorDialog("规范化主机名失败", err)
+		return
+	}
+	if err := m.loadInitialData(); err != nil {
+		m.showErrorDialog("刷新失败", err)
+		return
+	}
+	m.statusBar.SetText(fmt.Sprintf("已规范化%d个条目的主机名", changed))
+	m.scheduleAutoApply()
+}
@@ -0,0 +1,334 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/flyhigher139/mhost/internal/httpclient"
+	"github.com/flyhigher139/mhost/internal/importer"
+	"github.com/flyhigher139/mhost/internal/profile"
+	"github.com/flyhigher139/mhost/internal/telemetry"
+	"github.com/flyhigher139/mhost/pkg/models"
+)
+
+// importPreviewLimit 导入预览中显示的最大条目数
+const importPreviewLimit = 20
+
+// 导入的Profile与已有Profile重名时的处理方式
+const (
+	importConflictRename  = "保留两者，重命名导入的Profile"
+	importConflictReplace = "用导入的条目替换已有Profile"
+)
+
+// findProfileByName 按名称查找已有的Profile，不存在时返回nil
+func findProfileByName(summaries []*models.ProfileSummary, name string) *models.ProfileSummary {
+	for _, summary := range summaries {
+		if summary.Name == name {
+			return summary
+		}
+	}
+	return nil
+}
+
+// importedProfileName 导入时与已有Profile重名的Profile实际使用的名称
+func importedProfileName(summaries []*models.ProfileSummary, name string) string {
+	names := make([]string, 0, len(summaries))
+	for _, summary := range summaries {
+		names = append(names, summary.Name)
+	}
+	return profile.UniqueName(name, names)
+}
+
+// onImportProfile 从URL（例如Gist原始地址）或本地文件导入Profile，支持mHost JSON和hosts格式
+func (m *Manager) onImportProfile() {
+	if httpclient.Default().Offline() {
+		m.importProfileFromFile()
+		return
+	}
+
+	urlEntry := widget.NewEntry()
+	urlEntry.SetPlaceHolder("https://gist.githubusercontent.com/.../raw/staging.hosts")
+
+	proxyEntry := widget.NewEntry()
+	proxyEntry.SetPlaceHolder("可选，例如 http://127.0.0.1:7890")
+
+	var d dialog.Dialog
+	fileButton := widget.NewButton("从文件导入...", func() {
+		d.Hide()
+		m.importProfileFromFile()
+	})
+
+	content := container.NewVBox(
+		widget.NewForm(
+			&widget.FormItem{Text: "URL", Widget: urlEntry, HintText: "支持HTTP(S)地址，大小上限1MB"},
+			&widget.FormItem{Text: "代理", Widget: proxyEntry, HintText: "为空时使用设置中的网络代理"},
+		),
+		fileButton,
+	)
+
+	d = dialog.NewCustomConfirm("导入Profile", "下载", "取消", content, func(confirmed bool) {
+		if !confirmed {
+			return
+		}
+		if strings.TrimSpace(urlEntry.Text) == "" {
+			dialog.ShowInformation("提示", "请输入要导入的URL", m.window)
+			return
+		}
+		m.importProfileFromURL(strings.TrimSpace(urlEntry.Text), strings.TrimSpace(proxyEntry.Text))
+	}, m.window)
+	d.Resize(fyne.NewSize(520, 0))
+	d.Show()
+}
+
+// onImportHostsFile 选择hosts格式的文件（例如另一台机器的/etc/hosts）并导入为新的Profile，被注释的条目以禁用状态保留
+func (m *Manager) onImportHostsFile() {
+	openDialog := dialog.NewFileOpen(func(reader fyne.URIReadCloser, err error) {
+		if err != nil {
+			m.showErrorDialog("打开文件失败", err)
+			return
+		}
+		if reader == nil {
+			return
+		}
+		path := reader.URI().Path()
+		reader.Close()
+
+		imported, err := m.profileManager.ImportFromHostsFile(path, "")
+		if err != nil {
+			m.showErrorDialog("导入失败", err)
+			return
+		}
+
+		m.recordUsage(telemetry.EventImportProfile)
+		m.recordActivity(models.EventProfileImported, map[string]interface{}{
+			"profile_id":   imported.ID,
+			"profile_name": imported.Name,
+			"source":       path,
+			"entries":      len(imported.Entries),
+		})
+		m.refreshProfileList()
+		m.statusBar.SetText(fmt.Sprintf("已从 %s 导入Profile '%s' (%d个条目)", path, imported.Name, len(imported.Entries)))
+	}, m.window)
+	openDialog.Show()
+}
+
+// importProfileFromURL 下载并解析远程Profile，确认预览后导入
+func (m *Manager) importProfileFromURL(rawURL, proxy string) {
+	progressDialog := dialog.NewProgressInfinite("导入Profile", "正在下载，请稍候...", m.window)
+	progressDialog.Show()
+
+	go func() {
+		fetcher := importer.NewRemoteFetcher()
+		fetcher.Proxy = proxy
+
+		ctx, cancel := context.WithTimeout(context.Background(), fetcher.Timeout)
+		defer cancel()
+
+		data, err := fetcher.Fetch(ctx, rawURL)
+		progressDialog.Hide()
+		if err != nil {
+			m.showErrorDialog("下载失败", err)
+			return
+		}
+
+		profile, err := importer.ParseProfile(data, importer.NameFromURL(rawURL), m.hostnameNormalizer())
+		if err != nil {
+			m.showErrorDialog("解析失败", err)
+			return
+		}
+		m.showImportPreview(profile, rawURL)
+	}()
+}
+
+// importProfileFromFile 选择本地文件解析后导入
+func (m *Manager) importProfileFromFile() {
+	openDialog := dialog.NewFileOpen(func(reader fyne.URIReadCloser, err error) {
+		if err != nil {
+			m.showErrorDialog("打开文件失败", err)
+			return
+		}
+		if reader == nil {
+			return
+		}
+		defer reader.Close()
+
+		data, err := io.ReadAll(io.LimitReader(reader, importer.DefaultMaxFetchSize+1))
+		if err != nil {
+			m.showErrorDialog("读取文件失败", err)
+			return
+		}
+		if len(data) > importer.DefaultMaxFetchSize {
+			m.showErrorDialog("读取文件失败", importer.ErrResponseTooLarge)
+			return
+		}
+
+		name := reader.URI().Name()
+		profile, err := importer.ParseProfile(data, strings.TrimSuffix(name, filepath.Ext(name)), m.hostnameNormalizer())
+		if err != nil {
+			m.showErrorDialog("解析失败", err)
+			return
+		}
+		m.showImportPreview(profile, reader.URI().Path())
+	}, m.window)
+	openDialog.Show()
+}
+
+// showImportPreview 显示待导入Profile的预览，允许修改名称后确认导入。
+// 名称与已有Profile冲突时，可选择重命名导入或替换已有Profile的条目
+func (m *Manager) showImportPreview(parsed *models.Profile, source string) {
+	summaries, err := m.profileManager.ListProfiles()
+	if err != nil {
+		m.showErrorDialog("读取Profile列表失败", err)
+		return
+	}
+
+	conflictLabel := widget.NewLabel("")
+	conflictLabel.Wrapping = fyne.TextWrapWord
+	conflictRadio := widget.NewRadioGroup([]string{importConflictRename, importConflictReplace}, nil)
+	conflictRadio.SetSelected(importConflictRename)
+	conflictRadio.Required = true
+
+	var conflict *models.ProfileSummary
+	updateConflict := func(name string) {
+		name = strings.TrimSpace(name)
+		conflict = findProfileByName(summaries, name)
+		if conflict == nil {
+			conflictLabel.Hide()
+			conflictRadio.Hide()
+			return
+		}
+		conflictLabel.SetText(fmt.Sprintf("已存在名为'%s'的Profile（%d个条目），重命名时将导入为'%s'",
+			name, conflict.EntryCount, importedProfileName(summaries, name)))
+		conflictLabel.Show()
+		conflictRadio.Show()
+	}
+
+	nameEntry := widget.NewEntry()
+	nameEntry.SetText(parsed.Name)
+	nameEntry.OnChanged = updateConflict
+	updateConflict(parsed.Name)
+
+	enabled := 0
+	var preview strings.Builder
+	for i, entry := range parsed.Entries {
+		if entry.Enabled {
+			enabled++
+		}
+		if i < importPreviewLimit {
+			marker := ""
+			if !entry.Enabled {
+				marker = "# "
+			}
+			fmt.Fprintf(&preview, "%s%s\t%s\n", marker, entry.IP, entry.Hostname)
+		}
+	}
+	if len(parsed.Entries) > importPreviewLimit {
+		fmt.Fprintf(&preview, "... 以及其他%d个条目\n", len(parsed.Entries)-importPreviewLimit)
+	}
+
+	previewText := widget.NewMultiLineEntry()
+	previewText.SetText(preview.String())
+	previewText.Disable()
+	previewText.SetMinRowsVisible(8)
+
+	// 重复的主机名无法保存，导入时每个主机名只保留最后一个条目
+	duplicateLabel := widget.NewLabel("")
+	duplicateLabel.Wrapping = fyne.TextWrapWord
+	duplicateLabel.Importance = widget.WarningImportance
+	duplicateLabel.Hide()
+	if duplicates := parsed.DuplicateHostnames(); len(duplicates) > 0 {
+		duplicateLabel.SetText(duplicateSummary(duplicates) + "，导入时只保留每个主机名的最后一个条目")
+		duplicateLabel.Show()
+	}
+
+	content := container.NewVBox(
+		widget.NewLabel(fmt.Sprintf("来源: %s", source)),
+		widget.NewLabel(fmt.Sprintf("共%d个条目，其中%d个启用", len(parsed.Entries), enabled)),
+		widget.NewForm(&widget.FormItem{Text: "名称", Widget: nameEntry}),
+		conflictLabel,
+		conflictRadio,
+		duplicateLabel,
+		previewText,
+	)
+
+	d := dialog.NewCustomConfirm("导入预览", "导入", "取消", content, func(confirmed bool) {
+		if !confirmed {
+			return
+		}
+		parsed.Name = strings.TrimSpace(nameEntry.Text)
+		report := profile.NewImportReport(source)
+		if conflict != nil && conflictRadio.Selected == importConflictReplace {
+			m.replaceProfileEntries(conflict.ID, parsed, report)
+			return
+		}
+
+		imported, err := report.Import(m.profileManager, parsed)
+		if err != nil {
+			m.showErrorDialog("导入失败", err)
+			return
+		}
+
+		m.recordUsage(telemetry.EventImportProfile)
+		m.recordActivity(models.EventProfileImported, map[string]interface{}{
+			"profile_id":   imported.ID,
+			"profile_name": imported.Name,
+			"source":       source,
+			"entries":      len(imported.Entries),
+		})
+		m.refreshProfileList()
+		message := fmt.Sprintf("已导入Profile '%s' (%d个条目)", imported.Name, len(imported.Entries))
+		if imported.Name != parsed.Name {
+			message = fmt.Sprintf("名称'%s'已存在，%s", parsed.Name, message)
+		}
+		m.statusBar.SetText(message)
+		m.showImportReport(report)
+	}, m.window)
+	d.Resize(fyne.NewSize(520, 0))
+	d.Show()
+}
+
+// replaceProfileEntries 用导入的条目替换同名的已有Profile，保留其ID以便激活状态和历史记录保持不变；
+// 每个主机名只保留最后一个启用的条目，结果记录到report
+func (m *Manager) replaceProfileEntries(id string, imported *models.Profile, report *profile.ImportReport) {
+	source := report.Source
+	existing, err := m.profileManager.GetProfile(id)
+	if err != nil {
+		m.showErrorDialog("导入失败", err)
+		return
+	}
+	imported = imported.Clone()
+	skipped := imported.RemoveDuplicateHostnames()
+	existing.Entries = imported.Entries
+	if imported.Description != "" {
+		existing.Description = imported.Description
+	}
+	if err := m.profileManager.UpdateProfile(existing); err != nil {
+		m.showErrorDialog("导入失败", err)
+		return
+	}
+	report.Record(existing.Name, existing, skipped, true)
+
+	m.recordUsage(telemetry.EventImportProfile)
+	m.recordActivity(models.EventProfileImported, map[string]interface{}{
+		"profile_id":   existing.ID,
+		"profile_name": existing.Name,
+		"source":       source,
+		"entries":      len(existing.Entries),
+		"replaced":     true,
+	})
+	m.refreshProfileList()
+	message := fmt.Sprintf("已替换Profile '%s'的条目 (%d个条目)", existing.Name, len(existing.Entries))
+	if existing.IsActive {
+		message += "，重新应用后写入hosts文件"
+	}
+	m.statusBar.SetText(message)
+	m.showImportReport(report)
+}
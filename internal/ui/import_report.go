@@ -0,0 +1,65 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/flyhigher139/mhost/internal/profile"
+)
+
+// importReportSummary 导入报告的摘要：每个Profile的导入结果，以及被重命名的名称和跳过的重复条目
+func importReportSummary(report *profile.ImportReport) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "来源: %s\n", report.Source)
+	fmt.Fprintf(&b, "导入%d个Profile，重命名%d个，跳过%d个重复的条目\n",
+		len(report.Profiles), report.Renamed(), report.SkippedDuplicates())
+	for _, p := range report.Profiles {
+		b.WriteString("\n")
+		switch {
+		case p.Replaced:
+			fmt.Fprintf(&b, "%s: 替换已有Profile的条目（%d个条目）\n", p.Name, p.Entries)
+		case p.Renamed:
+			fmt.Fprintf(&b, "%s: 名称'%s'已存在，已重命名（%d个条目）\n", p.Name, p.RequestedName, p.Entries)
+		default:
+			fmt.Fprintf(&b, "%s: %d个条目\n", p.Name, p.Entries)
+		}
+		for _, entry := range p.Skipped {
+			fmt.Fprintf(&b, "  跳过重复的条目 %s\t%s\n", entry.IP, entry.Hostname)
+		}
+	}
+	if report.Error != "" {
+		fmt.Fprintf(&b, "\n导入中断: %s\n", report.Error)
+	}
+	return b.String()
+}
+
+// showImportReport 导入有重命名、跳过的条目或中途失败时，将导入报告写入数据目录并显示摘要，
+// 供自动化流程和用户核对实际导入的内容；导入结果与请求一致时不做任何事
+func (m *Manager) showImportReport(report *profile.ImportReport) {
+	if !report.HasConflicts() && report.Error == "" {
+		return
+	}
+
+	summary := importReportSummary(report)
+	path, err := report.WriteToDir(profile.DefaultImportReportDir(m.workspace.DataDir))
+	if err != nil {
+		summary += fmt.Sprintf("\n保存导入报告失败: %v\n", err)
+	} else {
+		summary += fmt.Sprintf("\n导入报告已保存到 %s\n", path)
+	}
+
+	text := widget.NewMultiLineEntry()
+	text.SetText(summary)
+	text.Wrapping = fyne.TextWrapWord
+	text.SetMinRowsVisible(10)
+	text.Disable()
+
+	d := dialog.NewCustom("导入报告", "关闭", container.NewStack(text), m.window)
+	d.Resize(fyne.NewSize(560, 0))
+	d.Show()
+}
@@ -0,0 +1,76 @@
+package ui
+
+import (
+	"fmt"
+	"os"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/flyhigher139/mhost/internal/importer"
+	"github.com/flyhigher139/mhost/pkg/models"
+)
+
+// onImportSuggestions 从SSH配置和/etc/resolver中发现条目，选择后合并到当前Profile
+func (m *Manager) onImportSuggestions() {
+	profile := m.controller.CurrentProfile()
+	if profile == nil {
+		dialog.ShowInformation("提示", "请先选择要导入到的Profile", m.window)
+		return
+	}
+
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		m.showErrorDialog("导入失败", err)
+		return
+	}
+
+	suggestions, err := importer.SuggestAll(importer.DefaultImporters(homeDir, m.hostnameNormalizer()))
+	if err != nil {
+		m.showErrorDialog("导入失败", err)
+		return
+	}
+	if len(suggestions) == 0 {
+		dialog.ShowInformation("导入建议", "没有在SSH配置或/etc/resolver中发现可导入的条目", m.window)
+		return
+	}
+
+	options := make([]string, len(suggestions))
+	byOption := make(map[string]importer.Suggestion, len(suggestions))
+	for i, suggestion := range suggestions {
+		options[i] = fmt.Sprintf("%s -> %s (%s)", suggestion.Entry.Hostname, suggestion.Entry.IP, suggestion.Note)
+		byOption[options[i]] = suggestion
+	}
+
+	checkGroup := widget.NewCheckGroup(options, nil)
+	checkGroup.SetSelected(options)
+
+	scroll := container.NewVScroll(checkGroup)
+	scroll.SetMinSize(fyne.NewSize(450, 300))
+
+	d := dialog.NewCustomConfirm(fmt.Sprintf("导入到Profile '%s'", profile.Name), "导入", "取消", scroll, func(confirmed bool) {
+		if !confirmed || len(checkGroup.Selected) == 0 {
+			return
+		}
+
+		entries := make([]*models.HostEntry, 0, len(checkGroup.Selected))
+		for _, option := range checkGroup.Selected {
+			entries = append(entries, byOption[option].Entry.ToModel())
+		}
+
+		merged, err := m.profileManager.MergeEntries(profile.ID, entries)
+		if err != nil {
+			m.showErrorDialog("导入失败", err)
+			return
+		}
+
+		if err := m.loadInitialData(); err != nil {
+			m.showErrorDialog("刷新失败", err)
+			return
+		}
+		m.statusBar.SetText(fmt.Sprintf("已导入%d个条目到Profile '%s'", merged, profile.Name))
+	}, m.window)
+	d.Show()
+}
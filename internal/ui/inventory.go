@@ -0,0 +1,258 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/flyhigher139/mhost/internal/httpclient"
+	"github.com/flyhigher139/mhost/internal/importer"
+	"github.com/flyhigher139/mhost/internal/inventory"
+	"github.com/flyhigher139/mhost/internal/profile"
+	"github.com/flyhigher139/mhost/pkg/models"
+)
+
+// startInventory 启动资产清单的定时同步，离线模式下只支持手动同步
+func (m *Manager) startInventory() {
+	m.inventory = inventory.NewSyncer(m.profileManager, newInventoryAdapter)
+	m.inventory.SetNormalizer(m.hostnameNormalizer())
+	m.inventory.SetApplier(m.applySubscription)
+	m.inventory.OnResult(m.onInventorySynced)
+
+	if httpclient.Default().Offline() {
+		return
+	}
+	if err := m.inventory.Start(inventory.DefaultCheckInterval); err != nil {
+		fmt.Printf("Failed to start inventory sync: %v\n", err)
+	}
+}
+
+// stopInventory 停止资产清单的定时同步
+func (m *Manager) stopInventory() {
+	if m.inventory != nil {
+		m.inventory.Stop()
+	}
+}
+
+// newInventoryAdapter 按资产清单配置创建HTTPAdapter，使用应用的网络代理设置
+func newInventoryAdapter(config *models.Inventory) (inventory.Adapter, error) {
+	client, err := httpclient.Default().Client(inventory.DefaultTimeout)
+	if err != nil {
+		return nil, err
+	}
+	return inventory.HTTPAdapterFunc(client)(config)
+}
+
+// onInventorySynced 记录资产清单同步结果，条目变化时刷新界面
+func (m *Manager) onInventorySynced(result *inventory.Result) {
+	if result.Err != nil {
+		m.recordError(fmt.Sprintf("同步资产清单 '%s' 失败", result.ProfileName), result.Err)
+		m.recordActivity(models.EventError, map[string]interface{}{
+			"operation":  "inventory",
+			"profile_id": result.ProfileID,
+			"error":      result.Err.Error(),
+		})
+	}
+	if !result.Changed {
+		return
+	}
+
+	m.recordActivity(models.EventProfileInventorySynced, map[string]interface{}{
+		"profile_id":   result.ProfileID,
+		"profile_name": result.ProfileName,
+		"entries":      result.Entries,
+		"skipped":      result.Skipped,
+		"applied":      result.Applied,
+	})
+	m.refreshProfileList()
+	if result.Applied {
+		m.statusBar.SetText(fmt.Sprintf("资产清单 '%s' 已更新并重新应用，共%d个条目", result.ProfileName, result.Entries))
+	} else {
+		m.statusBar.SetText(fmt.Sprintf("资产清单 '%s' 已更新，共%d个条目", result.ProfileName, result.Entries))
+	}
+}
+
+// onAddInventory 从资产清单（CMDB等）的JSON API同步主机与IP的对应关系，创建只读Profile后立即同步
+func (m *Manager) onAddInventory() {
+	if httpclient.Default().Offline() {
+		dialog.ShowInformation("提示", "离线模式下无法同步资产清单", m.window)
+		return
+	}
+
+	urlEntry := widget.NewEntry()
+	urlEntry.SetPlaceHolder("https://cmdb.example.com/api/machines")
+	headersEntry := widget.NewMultiLineEntry()
+	headersEntry.SetPlaceHolder("Authorization: Bearer <token>")
+	headersEntry.SetMinRowsVisible(2)
+	queryEntry := widget.NewEntry()
+	queryEntry.SetPlaceHolder("data.machines[?status=='online']")
+	hostnameEntry := widget.NewEntry()
+	hostnameEntry.SetPlaceHolder(models.DefaultInventoryHostname)
+	ipEntry := widget.NewEntry()
+	ipEntry.SetPlaceHolder(models.DefaultInventoryIP)
+	commentEntry := widget.NewEntry()
+	commentEntry.SetPlaceHolder("例如: {{.owner}}")
+	nameEntry := widget.NewEntry()
+	nameEntry.SetPlaceHolder("为空时根据URL生成")
+	intervalEntry := widget.NewEntry()
+	intervalEntry.SetText(strconv.Itoa(int(models.DefaultInventoryInterval.Minutes())))
+
+	d := dialog.NewForm("同步资产清单", "同步", "取消", []*widget.FormItem{
+		{Text: "URL", Widget: urlEntry, HintText: "返回JSON的HTTP(S)地址"},
+		{Text: "请求头", Widget: headersEntry, HintText: "每行一个，格式为\"名称: 值\""},
+		{Text: "记录", Widget: queryEntry, HintText: "选出记录数组的JMESPath表达式，为空时响应本身应为数组"},
+		{Text: "主机名", Widget: hostnameEntry, HintText: "由记录生成主机名的模板，以空白分隔的多个主机名各生成一个条目"},
+		{Text: "IP", Widget: ipEntry, HintText: "由记录生成IP的模板"},
+		{Text: "注释", Widget: commentEntry},
+		{Text: "名称", Widget: nameEntry},
+		{Text: "同步间隔", Widget: intervalEntry, HintText: "分钟，同步的Profile只读，条目只能由同步更新"},
+	}, func(confirmed bool) {
+		if !confirmed {
+			return
+		}
+		rawURL := strings.TrimSpace(urlEntry.Text)
+		if rawURL == "" {
+			dialog.ShowInformation("提示", "请输入资产清单的URL", m.window)
+			return
+		}
+		headers, err := parseHeaders(headersEntry.Text)
+		if err != nil {
+			m.showErrorDialog("输入验证错误", err)
+			return
+		}
+		minutes, err := strconv.Atoi(strings.TrimSpace(intervalEntry.Text))
+		if err != nil || minutes <= 0 {
+			dialog.ShowInformation("提示", "同步间隔必须是正整数", m.window)
+			return
+		}
+		config := &models.Inventory{
+			URL:             rawURL,
+			Headers:         headers,
+			Query:           strings.TrimSpace(queryEntry.Text),
+			Hostname:        strings.TrimSpace(hostnameEntry.Text),
+			IP:              strings.TrimSpace(ipEntry.Text),
+			Comment:         strings.TrimSpace(commentEntry.Text),
+			IntervalMinutes: minutes,
+		}
+		// 先编译记录表达式和模板，避免创建无法同步的Profile
+		if _, err := inventory.NewHTTPAdapter(config, nil); err != nil {
+			m.showErrorDialog("输入验证错误", err)
+			return
+		}
+		name := strings.TrimSpace(nameEntry.Text)
+		if name == "" {
+			name = importer.NameFromURL(rawURL)
+		}
+		m.createInventory(name, config)
+	}, m.window)
+	d.Resize(fyne.NewSize(640, 0))
+	d.Show()
+}
+
+// parseHeaders 解析每行一个的"名称: 值"请求头
+func parseHeaders(text string) (map[string]string, error) {
+	var headers map[string]string
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		name, value, ok := strings.Cut(line, ":")
+		name = strings.TrimSpace(name)
+		if !ok || name == "" {
+			return nil, fmt.Errorf("请求头格式应为\"名称: 值\"：%s", line)
+		}
+		if headers == nil {
+			headers = make(map[string]string)
+		}
+		headers[name] = strings.TrimSpace(value)
+	}
+	return headers, nil
+}
+
+// createInventory 创建同步资产清单的只读Profile并立即同步
+func (m *Manager) createInventory(name string, config *models.Inventory) {
+	summaries, err := m.profileManager.ListProfiles()
+	if err != nil {
+		m.showErrorDialog("同步资产清单失败", err)
+		return
+	}
+	existing := make([]string, 0, len(summaries))
+	for _, summary := range summaries {
+		existing = append(existing, summary.Name)
+	}
+
+	created, err := m.profileManager.CreateProfile(profile.UniqueName(name, existing), "资产清单: "+config.URL)
+	if err != nil {
+		m.showErrorDialog("同步资产清单失败", err)
+		return
+	}
+	if _, err := m.inventory.Attach(created.ID, config); err != nil {
+		m.showErrorDialog("同步资产清单失败", err)
+		return
+	}
+	m.recordActivity(models.EventProfileCreated, map[string]interface{}{
+		"profile_id":   created.ID,
+		"profile_name": created.Name,
+		"inventory":    config.URL,
+	})
+	m.refreshProfileList()
+	m.syncInventory(created.ID)
+}
+
+// onSyncInventory 立即同步当前Profile的资产清单
+func (m *Manager) onSyncInventory() {
+	current := m.controller.CurrentProfile()
+	if current == nil || current.Inventory == nil {
+		dialog.ShowInformation("提示", "当前Profile没有同步资产清单", m.window)
+		return
+	}
+	m.syncInventory(current.ID)
+}
+
+// syncInventory 获取资产清单并显示结果，条目变化由onInventorySynced处理
+func (m *Manager) syncInventory(id string) {
+	progressDialog := dialog.NewProgressInfinite("同步资产清单", "正在获取资产清单，请稍候...", m.window)
+	progressDialog.Show()
+
+	go func() {
+		result := m.inventory.Sync(context.Background(), id)
+		progressDialog.Hide()
+		switch {
+		case result.Err != nil:
+			m.showErrorDialog("同步资产清单失败", result.Err)
+		case result.Skipped > 0:
+			m.statusBar.SetText(fmt.Sprintf("资产清单 '%s' 共%d个条目，跳过%d条主机名或IP无效的记录", result.ProfileName, result.Entries, result.Skipped))
+		case !result.Changed:
+			m.statusBar.SetText(fmt.Sprintf("资产清单 '%s' 没有变化，共%d个条目", result.ProfileName, result.Entries))
+		}
+	}()
+}
+
+// deleteInventory 删除同步资产清单的只读Profile并清空选择
+func (m *Manager) deleteInventory(p *models.Profile) (*models.Profile, error) {
+	if err := m.inventory.Delete(p.ID); err != nil {
+		return nil, err
+	}
+	m.controller.ClearSelection()
+	return p, nil
+}
+
+// editableProfile 当前可以修改条目的Profile；没有选择Profile或Profile只读时显示提示并返回nil
+func (m *Manager) editableProfile() *models.Profile {
+	current := m.controller.CurrentProfile()
+	if current == nil {
+		dialog.ShowInformation("提示", "请先选择一个Profile", m.window)
+		return nil
+	}
+	if current.ReadOnly() {
+		m.showErrorDialog("Profile只读", models.ErrReadOnlyProfile)
+		return nil
+	}
+	return current
+}
@@ -0,0 +1,126 @@
+package ui
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/flyhigher139/mhost/internal/host"
+	"github.com/flyhigher139/mhost/internal/telemetry"
+	"github.com/flyhigher139/mhost/pkg/models"
+)
+
+// onApplyLayers 选择多个Profile并按勾选顺序叠加应用，例如"base"、"project-A"、"adblock"；
+// 后勾选的Profile优先级更高，同一主机名以优先级最高的Profile为准
+func (m *Manager) onApplyLayers() {
+	profiles := m.controller.Profiles()
+	if len(profiles) < 2 {
+		dialog.ShowInformation("提示", "至少需要两个Profile才能叠加应用", m.window)
+		return
+	}
+
+	names := make([]string, 0, len(profiles))
+	ids := make(map[string]string, len(profiles))
+	for _, p := range profiles {
+		names = append(names, p.Name)
+		ids[p.Name] = p.ID
+	}
+
+	orderLabel := widget.NewLabel("")
+	orderLabel.Wrapping = fyne.TextWrapWord
+	layers := widget.NewCheckGroup(names, func(selected []string) {
+		orderLabel.SetText(layerOrderText(selected))
+	})
+	if active, err := m.profileManager.GetActiveProfiles(); err == nil {
+		selected := make([]string, 0, len(active))
+		for _, p := range active {
+			selected = append(selected, p.Name)
+		}
+		layers.SetSelected(selected)
+	}
+	orderLabel.SetText(layerOrderText(layers.Selected))
+
+	content := container.NewBorder(
+		widget.NewLabel("按优先级从低到高依次勾选，同一主机名以优先级最高的Profile为准"),
+		orderLabel, nil, nil,
+		container.NewVScroll(layers),
+	)
+	d := dialog.NewCustomConfirm("叠加应用多个Profile", "应用", "取消", content, func(confirmed bool) {
+		if !confirmed {
+			return
+		}
+		if len(layers.Selected) == 0 {
+			dialog.ShowInformation("提示", "请至少选择一个Profile", m.window)
+			return
+		}
+		selected := make([]string, 0, len(layers.Selected))
+		for _, name := range layers.Selected {
+			selected = append(selected, ids[name])
+		}
+
+		name := strings.Join(layers.Selected, models.LayerSeparator)
+		apply := func() { m.applyLayers(selected, host.ApplyOptions{}) }
+		if m.checkApplyPreflight(name, apply) {
+			apply()
+		}
+	}, m.window)
+	d.Resize(fyne.NewSize(480, 420))
+	d.Show()
+}
+
+// layerOrderText 显示叠加的顺序
+func layerOrderText(selected []string) string {
+	if len(selected) == 0 {
+		return "未选择Profile"
+	}
+	return "应用顺序: " + strings.Join(selected, " → ")
+}
+
+// applyLayers 按选项叠加应用多个Profile并更新激活状态
+func (m *Manager) applyLayers(ids []string, options host.ApplyOptions) {
+	progressDialog := dialog.NewProgressInfinite("应用Profile", "正在叠加应用Profile，请稍候...", m.window)
+	progressDialog.Show()
+
+	go func() {
+		defer progressDialog.Hide()
+
+		result, err := m.controller.ApplyLayers(ids, options)
+		var limitErr *host.LimitError
+		if errors.As(err, &limitErr) {
+			message := fmt.Sprintf("叠加应用后hosts文件将超过规模限制：\n\n- %s", strings.Join(limitErr.Violations, "\n- "))
+			dialog.ShowConfirm("超过规模限制", message, func(confirmed bool) {
+				if confirmed {
+					m.applyLayers(ids, host.ApplyOptions{IgnoreLimits: true})
+				}
+			}, m.window)
+			return
+		}
+		if err != nil && result == nil {
+			m.recordActivity(models.EventError, map[string]interface{}{"operation": "apply", "error": err.Error()})
+			m.showErrorDialog("叠加应用Profile失败", err)
+			return
+		}
+		if err != nil {
+			m.showErrorDialog("更新Profile状态失败", err)
+			return
+		}
+
+		m.recordUsage(telemetry.EventApplyProfile)
+		m.recordActivity(models.EventProfileActivated, applyActivityData(result))
+		m.recordApply(result)
+		m.recheckHealth()
+
+		m.refreshProfileList()
+		status := fmt.Sprintf("Profile '%s' 应用成功: %s", result.ProfileName, applySummaryText(result))
+		if len(result.Conflicts) > 0 {
+			status += fmt.Sprintf("，%d个主机名冲突", len(result.Conflicts))
+		}
+		m.statusBar.SetText(status)
+		m.showApplySummary(result)
+	}()
+}
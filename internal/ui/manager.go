@@ -3,9 +3,9 @@ package ui
 import (
 	"errors"
 	"fmt"
-	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 	"time"
 
 	"fyne.io/fyne/v2"
@@ -15,86 +15,194 @@ import (
 	"fyne.io/fyne/v2/theme"
 	"fyne.io/fyne/v2/widget"
 
+	"github.com/flyhigher139/mhost/internal/activity"
+	"github.com/flyhigher139/mhost/internal/analyzer"
 	"github.com/flyhigher139/mhost/internal/config"
+	"github.com/flyhigher139/mhost/internal/controller"
+	"github.com/flyhigher139/mhost/internal/daemon"
+	"github.com/flyhigher139/mhost/internal/diagnostics"
+	"github.com/flyhigher139/mhost/internal/dnsstats"
+	"github.com/flyhigher139/mhost/internal/healthcheck"
+	"github.com/flyhigher139/mhost/internal/helper"
 	"github.com/flyhigher139/mhost/internal/host"
+	"github.com/flyhigher139/mhost/internal/httpclient"
+	"github.com/flyhigher139/mhost/internal/inventory"
+	"github.com/flyhigher139/mhost/internal/plugins"
 	"github.com/flyhigher139/mhost/internal/profile"
+	"github.com/flyhigher139/mhost/internal/rules"
+	"github.com/flyhigher139/mhost/internal/snapshot"
+	"github.com/flyhigher139/mhost/internal/subscription"
+	"github.com/flyhigher139/mhost/internal/telemetry"
+	apperrors "github.com/flyhigher139/mhost/pkg/errors"
+	"github.com/flyhigher139/mhost/pkg/hostsfile"
+	"github.com/flyhigher139/mhost/pkg/logger"
 	"github.com/flyhigher139/mhost/pkg/models"
 )
 
 // Manager UI管理器
 type Manager struct {
 	window         fyne.Window
+	workspaces     config.WorkspaceManager
+	workspace      *config.Workspace
 	configManager  config.Manager
 	profileManager profile.Manager
 	hostManager    host.Manager
 
 	// UI组件
-	mainContainer   *fyne.Container
-	toolbar         *fyne.Container
-	profileList     *widget.List
-	hostEntryList   *widget.List
-	statusBar       *widget.Label
-	menuBar         *fyne.MainMenu
-	profileSelector *widget.Select
-
-	// 当前状态
-	currentProfile   *models.Profile
-	currentHostEntry *models.HostEntry
-	appConfig        *models.AppConfig
-	profiles         []*models.Profile
-	hostEntries      []*models.HostEntry
-}
+	mainContainer     *fyne.Container
+	toolbar           *fyne.Container
+	profileList       *widget.Tree
+	hostEntryTable    *widget.Table
+	statusBar         *widget.Label
+	menuBar           *fyne.MainMenu
+	profileSelector   *widget.Select
+	environmentSelect *widget.Select
 
-// NewManager 创建新的UI管理器
-func NewManager(window fyne.Window) (*Manager, error) {
-	// 初始化管理器
-	configManager := config.NewManager("", "")
+	// Profile树中选中的文件夹，选中Profile时清空
+	selectedFolder string
 
-	// 获取用户主目录作为数据目录
-	homeDir, err := os.UserHomeDir()
-	if err != nil {
-		return nil, fmt.Errorf("failed to get user home directory: %w", err)
-	}
-	dataDir := filepath.Join(homeDir, ".mhost")
+	// Profile搜索框，支持"tag:prod"形式的标签筛选
+	profileSearch *widget.Entry
 
-	profileManager, err := profile.NewManager(dataDir)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create profile manager: %w", err)
-	}
-	hostManager := host.NewManager("", "")
+	// Host条目表格中按当前排序方式显示的条目
+	entryView []*models.HostEntry
+
+	// 选择状态及Profile编辑、应用流程
+	controller *controller.Controller
+	appConfig  *models.AppConfig
+
+	// 保存时自动应用的防抖器
+	autoApply *debouncer
+
+	// 按Profile区分的条目和Profile修改的撤销历史
+	undo *undoHistory
+
+	// 当前Profile中重复主机名的提示
+	duplicates *duplicateBanner
+
+	// DNS命中统计
+	hitCounter     *dnsstats.CounterImpl
+	queryLogTailer *dnsstats.QueryLogTailer
+
+	// 条目分析服务
+	analyzer *analyzer.Analyzer
+
+	// 定期验证服务和工具栏中的问题标记
+	healthChecker *healthcheck.Checker
+	healthBadge   *widget.Button
+
+	// 定期Profile快照
+	snapshots *snapshot.Scheduler
+
+	// 远程hosts列表订阅的定时刷新
+	subscriptions *subscription.Refresher
+
+	// 资产清单的定时同步
+	inventory *inventory.Syncer
+
+	// Helper客户端，以及Helper暂时不可用时缓存非紧急操作的请求队列
+	helperClient helper.Client
+	helperQueue  *helper.RequestQueue
+	queueBadge   *widget.Button
+
+	// 最近的错误，在健康面板中显示
+	errorsMu     sync.Mutex
+	recentErrors []recentError
+
+	// IP信息查询服务
+	ipInfo *diagnostics.IPInfoService
+
+	// 守护进程控制socket客户端
+	daemonClient *daemon.Client
+
+	// 本地使用统计
+	usage telemetry.Recorder
+
+	// 应用历史
+	history host.History
+
+	// 最近活动，以及上次记录的手动修改数量
+	activity  activity.Feed
+	lastDrift int
+
+	// 界面使用的日志器，级别随设置实时变化
+	logger *logger.EnhancedLogger
+
+	// 插件目录中发现的导入导出插件
+	plugins []*plugins.Plugin
+}
 
-	// 加载配置
-	appConfig, err := configManager.LoadConfig()
+// NewManager 创建新的UI管理器
+func NewManager(window fyne.Window) (*Manager, error) {
+	// 获取当前工作区，每个工作区拥有独立的数据目录、备份目录和配置
+	workspaces := config.NewWorkspaceManager("")
+	workspace, err := workspaces.ActiveWorkspace()
 	if err != nil {
-		return nil, fmt.Errorf("failed to load config: %w", err)
+		return nil, fmt.Errorf("failed to resolve workspace: %w", err)
 	}
 
 	// 创建UI管理器
 	manager := &Manager{
-		window:         window,
-		configManager:  configManager,
-		profileManager: profileManager,
-		hostManager:    hostManager,
-		appConfig:      appConfig,
+		window:     window,
+		workspaces: workspaces,
+		logger:     logger.NewEnhancedLogger(logger.LogLevelInfo, false),
+	}
+	if err := manager.openWorkspace(workspace); err != nil {
+		return nil, err
 	}
 
+	// 首次运行时在修改hosts文件之前保存系统初始状态
+	manager.captureBaseline()
+
 	// 初始化UI组件
 	if err := manager.initializeUI(); err != nil {
 		return nil, fmt.Errorf("failed to initialize UI: %w", err)
 	}
+	manager.applyTheme()
 
 	// 加载初始数据
 	if err := manager.loadInitialData(); err != nil {
 		return nil, fmt.Errorf("failed to load initial data: %w", err)
 	}
 
+	// hosts文件损坏时打开恢复向导
+	manager.checkHostsOnStartup()
+
+	// 首次启动时提示规范化已有条目的主机名
+	manager.offerHostnameMigration()
+
+	// 启动DNS命中统计
+	manager.startDNSStats()
+
+	// 启动条目定时分析
+	manager.startAnalyzer()
+
+	// 启动后台定期验证
+	manager.startHealthCheck()
+
+	// 启动定期Profile快照
+	manager.startSnapshots()
+
+	// 启动订阅的定时刷新
+	manager.startSubscriptions()
+
+	// 启动资产清单的定时同步
+	manager.startInventory()
+
+	// 启动Helper请求队列
+	manager.startHelperQueue()
+
+	// 监听配置文件的外部修改
+	manager.startConfigWatch()
+
 	return manager, nil
 }
 
 // initializeUI 初始化UI组件
 func (m *Manager) initializeUI() error {
-	// 创建菜单栏
+	// 创建菜单栏并注册撤销和重做快捷键
 	m.createMenuBar()
+	m.registerUndoShortcuts()
 
 	// 创建工具栏
 	m.createToolbar()
@@ -102,8 +210,9 @@ func (m *Manager) initializeUI() error {
 	// 创建Profile列表
 	m.createProfileList()
 
-	// 创建Host条目列表
-	m.createHostEntryList()
+	// 创建Host条目列表和环境选择器
+	m.createHostEntryTable()
+	m.createEnvironmentSelect()
 
 	// 创建状态栏
 	m.statusBar = widget.NewLabel("就绪")
@@ -120,42 +229,93 @@ func (m *Manager) createMenuBar() {
 	fileMenu := fyne.NewMenu("文件",
 		fyne.NewMenuItem("新建Profile", m.onNewProfile),
 		fyne.NewMenuItem("导入Profile", m.onImportProfile),
+		fyne.NewMenuItem("导入hosts文件", m.onImportHostsFile),
+		fyne.NewMenuItem("订阅远程hosts列表", m.onSubscribe),
+		fyne.NewMenuItem("同步资产清单", m.onAddInventory),
+		fyne.NewMenuItem("从SSH配置/Resolver导入", m.onImportSuggestions),
+		fyne.NewMenuItem("使用插件导入", m.onImportWithPlugin),
 		fyne.NewMenuItem("导出Profile", m.onExportProfile),
 		fyne.NewMenuItemSeparator(),
 		fyne.NewMenuItem("备份Hosts文件", m.onBackupHosts),
 		fyne.NewMenuItem("恢复Hosts文件", m.onRestoreHosts),
+		fyne.NewMenuItem("备份历史", m.onShowBackupHistory),
+		fyne.NewMenuItem("系统初始hosts", m.onShowBaseline),
+		fyne.NewMenuItem("导出Profile快照", m.onExportSnapshot),
+		fyne.NewMenuItem("从Profile快照恢复", m.onShowSnapshots),
 		fyne.NewMenuItemSeparator(),
+		fyne.NewMenuItem("切换工作区", m.onSwitchWorkspace),
 		fyne.NewMenuItem("刷新", m.onRefresh),
 		fyne.NewMenuItemSeparator(),
 		fyne.NewMenuItem("退出", func() { m.window.Close() }),
 	)
 
 	// 编辑菜单
+	presetsItem := fyne.NewMenuItem("快捷预设", nil)
+	presetsItem.ChildMenu = fyne.NewMenu("", m.presetMenuItems()...)
 	editMenu := fyne.NewMenu("编辑",
+		fyne.NewMenuItem("撤销", m.onUndo),
+		fyne.NewMenuItem("重做", m.onRedo),
+		fyne.NewMenuItemSeparator(),
 		fyne.NewMenuItem("编辑Profile", m.onEditProfile),
 		fyne.NewMenuItem("删除Profile", m.onDeleteProfile),
 		fyne.NewMenuItem("复制Profile", m.onCopyProfile),
+		fyne.NewMenuItem("对比Profile", m.onCompareProfiles),
+		fyne.NewMenuItem("新建文件夹", m.onNewGroup),
+		fyne.NewMenuItem("重命名文件夹", m.onRenameGroup),
+		fyne.NewMenuItem("删除文件夹", m.onDeleteGroup),
+		fyne.NewMenuItem("添加标签", m.onAddTag),
+		fyne.NewMenuItem("管理标签", m.onManageTags),
 		fyne.NewMenuItemSeparator(),
 		fyne.NewMenuItem("添加Host条目", m.onAddHostEntry),
+		fyne.NewMenuItem("粘贴Host条目", m.onPasteHostEntries),
 		fyne.NewMenuItem("编辑Host条目", m.onEditHostEntry),
 		fyne.NewMenuItem("删除Host条目", m.onDeleteHostEntry),
 		fyne.NewMenuItem("启用/禁用Host条目", m.onToggleHostEntry),
+		fyne.NewMenuItem("设为全局条目", m.onMakeEntryGlobal),
+		fyne.NewMenuItem("查看IP信息", m.onShowIPInfo),
+		fyne.NewMenuItem("端口探测", m.onProbePorts),
+		fyne.NewMenuItem("检查SSL证书", m.onCheckCertificate),
+		fyne.NewMenuItem("HTTP检查", m.onCheckHTTP),
+		fyne.NewMenuItemSeparator(),
+		presetsItem,
 		fyne.NewMenuItemSeparator(),
 		fyne.NewMenuItem("应用Profile", m.onApplyProfile),
+		fyne.NewMenuItem("叠加应用多个Profile", m.onApplyLayers),
 	)
 
 	// 工具菜单
 	toolsMenu := fyne.NewMenu("工具",
 		fyne.NewMenuItem("验证Hosts文件", m.onValidateHosts),
+		fyne.NewMenuItem("重新加载校验规则", m.onReloadRules),
+		fyne.NewMenuItem("重新加载Hook脚本", m.onReloadHooks),
+		fyne.NewMenuItem("重新扫描插件", m.onRescanPlugins),
 		fyne.NewMenuItem("清理无效条目", m.onCleanupHosts),
 		fyne.NewMenuItem("清理备份文件", m.onCleanupBackups),
+		fyne.NewMenuItem("导入手动修改", m.onImportManualEdits),
+		fyne.NewMenuItem("接管管理section", m.onAdoptSection),
+		fyne.NewMenuItem("条目分析报告", m.onShowAnalysisReport),
+		fyne.NewMenuItem("应用历史", m.onShowApplyHistory),
+		fyne.NewMenuItem("定期验证报告", m.runHealthCheck),
+		fyne.NewMenuItem("诊断检查", m.onRunDoctor),
+		fyne.NewMenuItem("规范化主机名", m.onNormalizeHostnames),
+		fyne.NewMenuItem("解析并固定", m.onResolveAndPin),
+		fyne.NewMenuItem("刷新固定条目", m.onRefreshPins),
+		fyne.NewMenuItem("刷新订阅", m.onRefreshSubscription),
+		fyne.NewMenuItem("立即同步资产清单", m.onSyncInventory),
+		fyne.NewMenuItem("推送拦截条目到DNS过滤服务", m.onPushToDNSFilter),
+		fyne.NewMenuItem("从DNS过滤服务拉取拦截列表", m.onPullFromDNSFilter),
+		fyne.NewMenuItem("全局条目", m.onShowGlobalEntries),
 		fyne.NewMenuItemSeparator(),
 		fyne.NewMenuItem("设置", m.onShowSettings),
+		fyne.NewMenuItem("导出界面偏好", m.onExportPreferences),
+		fyne.NewMenuItem("导入界面偏好", m.onImportPreferences),
 	)
 
 	// 视图菜单
 	viewMenu := fyne.NewMenu("视图",
 		fyne.NewMenuItem("快速切换Profile", m.showQuickSwitchDialog),
+		fyne.NewMenuItem("健康面板", m.onShowDashboard),
+		fyne.NewMenuItem("最近活动", m.onShowActivity),
 		fyne.NewMenuItemSeparator(),
 		fyne.NewMenuItem("显示所有Profile", func() {
 			m.onFilterProfiles("")
@@ -166,6 +326,12 @@ func (m *Manager) createMenuBar() {
 		fyne.NewMenuItemSeparator(),
 		fyne.NewMenuItem("展开所有", m.onExpandAll),
 		fyne.NewMenuItem("折叠所有", m.onCollapseAll),
+		fyne.NewMenuItem("按文件夹分组", func() {
+			m.onGroupProfiles(models.ProfileGroupingFolder)
+		}),
+		fyne.NewMenuItem("按标签分组", func() {
+			m.onGroupProfiles(models.ProfileGroupingTag)
+		}),
 	)
 
 	// 帮助菜单
@@ -212,6 +378,10 @@ func (m *Manager) createToolbar() {
 		// 其他操作
 		widget.NewButton("刷新", m.onRefresh),
 		widget.NewButton("设置", m.onShowSettings),
+		// 定期验证发现问题时显示
+		m.createHealthBadge(),
+		// Helper不可用时缓存的请求
+		m.createQueueBadge(),
 	)
 	
 	// 保存Profile选择器的引用
@@ -231,7 +401,7 @@ func (m *Manager) createMainContainer() {
 	
 	// 创建左侧Profile容器
 	leftPanel := container.NewBorder(
-		profileTitleBar,
+		container.NewVBox(profileTitleBar, m.createProfileSearch()),
 		nil, nil, nil,
 		m.profileList,
 	)
@@ -239,17 +409,21 @@ func (m *Manager) createMainContainer() {
 	// 创建右侧Host条目标题栏
 	hostTitleBar := container.NewHBox(
 		widget.NewLabelWithStyle("Host条目", fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
+		widget.NewLabel("环境:"),
+		m.environmentSelect,
 		layout.NewSpacer(),
 		widget.NewButtonWithIcon("", theme.ContentAddIcon(), m.onAddHostEntry),
+		widget.NewButtonWithIcon("", theme.ContentPasteIcon(), m.onPasteHostEntries),
 		widget.NewButtonWithIcon("", theme.DocumentCreateIcon(), m.onEditHostEntry),
 		widget.NewButtonWithIcon("", theme.DeleteIcon(), m.onDeleteHostEntry),
+		widget.NewButtonWithIcon("", theme.InfoIcon(), m.onShowIPInfo),
 	)
 	
 	// 创建右侧Host条目容器
 	rightPanel := container.NewBorder(
-		hostTitleBar,
+		container.NewVBox(hostTitleBar, m.createDuplicateBanner()),
 		nil, nil, nil,
-		m.hostEntryList,
+		m.hostEntryTable,
 	)
 
 	// 主内容区域
@@ -274,30 +448,15 @@ func (m *Manager) createMainContainer() {
 
 // loadInitialData 加载初始数据
 func (m *Manager) loadInitialData() error {
-	// 加载Profile列表
-	profileSummaries, err := m.profileManager.ListProfiles()
-	if err != nil {
-		return fmt.Errorf("failed to list profiles: %w", err)
-	}
-
-	// 转换为完整的Profile对象
-	m.profiles = make([]*models.Profile, 0, len(profileSummaries))
-	for _, summary := range profileSummaries {
-		profile, err := m.profileManager.GetProfile(summary.ID)
-		if err != nil {
-			continue // 跳过无法加载的profile
-		}
-		m.profiles = append(m.profiles, profile)
+	// 加载Profile列表并选中激活的Profile
+	if err := m.controller.Load(); err != nil {
+		return err
 	}
 	m.profileList.Refresh()
-
-	// 获取活动Profile
-	activeProfile, err := m.profileManager.GetActiveProfile()
-	if err == nil && activeProfile != nil {
-		m.currentProfile = activeProfile
-		m.hostEntries = activeProfile.Entries
-		m.hostEntryList.Refresh()
-	}
+	m.restoreExpandedGroups()
+	m.updateTrackedHostnames()
+	m.refreshHostEntryList()
+	m.refreshEnvironmentSelect()
 
 	// 更新状态栏
 	m.updateStatusBar()
@@ -310,6 +469,17 @@ func (m *Manager) GetMainContainer() fyne.CanvasObject {
 	return m.mainContainer
 }
 
+// ApplyProfileOnStartup 启动时按名称或ID选中并应用Profile，由--apply启动参数触发，无需再次确认
+func (m *Manager) ApplyProfileOnStartup(query string) {
+	target := m.controller.ResolveProfile(query)
+	if target == nil {
+		m.showErrorDialog("应用Profile失败", fmt.Errorf("%w: %s", models.ErrProfileNotFound, query))
+		return
+	}
+	m.switchToProfile(target)
+	m.applyCurrentProfile(host.ApplyOptions{})
+}
+
 // OnWindowClose 窗口关闭回调
 func (m *Manager) OnWindowClose() {
 	// 保存当前配置
@@ -325,217 +495,69 @@ func (m *Manager) OnWindowClose() {
 		}
 	}
 
+	// 停止DNS命中统计和条目分析
+	m.stopDNSStats()
+	m.stopAnalyzer()
+	m.stopHealthCheck()
+	m.stopSnapshots()
+	m.stopSubscriptions()
+	m.stopInventory()
+	m.stopHelperQueue()
+
+	// 取消尚未执行的自动应用
+	if m.autoApply != nil {
+		m.autoApply.Stop()
+	}
+
+	// 按设置还原hosts文件，需在停止自动应用之后执行，避免还原后又被写入
+	m.restoreHostsOnQuit()
+
 	// 停止配置监听
-	m.configManager.StopWatching()
+	m.stopConfigWatch()
 }
 
 // updateStatusBar 更新状态栏
 func (m *Manager) updateStatusBar() {
 	var message string
-	if m.currentProfile != nil {
-		message = fmt.Sprintf("当前Profile: %s (%d个条目)",
-			m.currentProfile.Name, len(m.currentProfile.Entries))
+	if m.workspace != nil && m.workspace.Name != config.DefaultWorkspace {
+		message = fmt.Sprintf("[%s] ", m.workspace.Name)
+	}
+	if current := m.controller.CurrentProfile(); current != nil {
+		message += fmt.Sprintf("当前Profile: %s (%d个条目)",
+			current.Name, len(current.Entries))
 	} else {
-		message = "未选择Profile"
+		message += "未选择Profile"
+	}
+	if httpclient.Default().Offline() {
+		message += " · 离线模式"
 	}
 	m.statusBar.SetText(message)
 }
 
 // 事件处理方法
 
-// createProfileList 创建Profile列表
-func (m *Manager) createProfileList() {
-	m.profileList = widget.NewList(
-		func() int {
-			return len(m.profiles)
-		},
-		func() fyne.CanvasObject {
-			// 创建Profile条目的布局
-			name := widget.NewLabel("")
-			name.TextStyle.Bold = true
-			desc := widget.NewLabel("")
-			desc.TextStyle.Italic = true
-			status := widget.NewLabel("")
-			
-			// 创建状态指示器
-			statusIcon := widget.NewIcon(nil)
-			
-			// 创建水平布局的状态行
-			statusRow := container.NewHBox(
-				statusIcon,
-				status,
-				layout.NewSpacer(),
-			)
-			
-			return container.NewVBox(
-				name,
-				desc,
-				statusRow,
-			)
-		},
-		func(id widget.ListItemID, obj fyne.CanvasObject) {
-			if id >= 0 && id < len(m.profiles) {
-				profile := m.profiles[id]
-				vbox := obj.(*fyne.Container)
-				
-				// 更新名称
-				nameLabel := vbox.Objects[0].(*widget.Label)
-				nameLabel.SetText(profile.Name)
-				
-				// 更新描述
-				descLabel := vbox.Objects[1].(*widget.Label)
-				if profile.Description != "" {
-					descLabel.SetText(profile.Description)
-				} else {
-					descLabel.SetText("无描述")
-				}
-				
-				// 更新状态行
-				statusRow := vbox.Objects[2].(*fyne.Container)
-				statusIcon := statusRow.Objects[0].(*widget.Icon)
-				statusLabel := statusRow.Objects[1].(*widget.Label)
-				
-				statusText := fmt.Sprintf("条目数: %d", len(profile.Entries))
-				if profile.IsActive {
-					statusText += " (当前激活)"
-					statusIcon.SetResource(theme.ConfirmIcon())
-				} else {
-					statusIcon.SetResource(theme.RadioButtonIcon())
-				}
-				statusLabel.SetText(statusText)
-			}
-		},
-	)
-	
-	// 设置选择事件处理
-	m.profileList.OnSelected = func(id widget.ListItemID) {
-		if id >= 0 && id < len(m.profiles) {
-			m.onProfileSelected(id)
-		}
-	}
-}
-
-// createHostEntryList 创建Host条目列表
-func (m *Manager) createHostEntryList() {
-	m.hostEntryList = widget.NewList(
-		func() int {
-			return len(m.hostEntries)
-		},
-		func() fyne.CanvasObject {
-			// 创建Host条目的布局
-			hostname := widget.NewLabel("")
-			hostname.TextStyle.Bold = true
-			ip := widget.NewLabel("")
-			comment := widget.NewLabel("")
-			comment.TextStyle.Italic = true
-			status := widget.NewLabel("")
-			
-			// 创建启用/禁用的复选框
-			enabled := widget.NewCheck("", nil)
-			enabled.Disable() // 只读显示
-			
-			// 创建状态指示器
-			statusIcon := widget.NewIcon(nil)
-			
-			// 创建主机名行（包含启用状态和图标）
-			hostnameRow := container.NewHBox(
-				enabled,
-				statusIcon,
-				hostname,
-				layout.NewSpacer(),
-			)
-			
-			// 创建IP地址行（带图标）
-			ipIcon := widget.NewIcon(theme.ComputerIcon())
-			ipRow := container.NewHBox(
-				ipIcon,
-				ip,
-			)
-			
-			// 创建注释行（带图标）
-			commentIcon := widget.NewIcon(theme.DocumentIcon())
-			commentRow := container.NewHBox(
-				commentIcon,
-				comment,
-			)
-			
-			return container.NewVBox(
-				hostnameRow,
-				ipRow,
-				commentRow,
-				status,
-			)
-		},
-		func(id widget.ListItemID, obj fyne.CanvasObject) {
-			if id >= 0 && id < len(m.hostEntries) {
-				entry := m.hostEntries[id]
-				vbox := obj.(*fyne.Container)
-				
-				// 更新主机名行
-				hostnameRow := vbox.Objects[0].(*fyne.Container)
-				enabled := hostnameRow.Objects[0].(*widget.Check)
-				statusIcon := hostnameRow.Objects[1].(*widget.Icon)
-				hostname := hostnameRow.Objects[2].(*widget.Label)
-				
-				enabled.SetChecked(entry.Enabled)
-				hostname.SetText(entry.Hostname)
-				
-				// 设置状态图标
-				if entry.Enabled {
-					statusIcon.SetResource(theme.ConfirmIcon())
-				} else {
-					statusIcon.SetResource(theme.CancelIcon())
-				}
-				
-				// 更新IP地址行
-				ipRow := vbox.Objects[1].(*fyne.Container)
-				ip := ipRow.Objects[1].(*widget.Label)
-				ip.SetText(entry.IP)
-				
-				// 更新注释行
-				commentRow := vbox.Objects[2].(*fyne.Container)
-				comment := commentRow.Objects[1].(*widget.Label)
-				if entry.Comment != "" {
-					comment.SetText(entry.Comment)
-				} else {
-					comment.SetText("无注释")
-				}
-				
-				// 更新状态
-				status := vbox.Objects[3].(*widget.Label)
-				statusText := fmt.Sprintf("创建时间: %s", entry.CreatedAt.Format("2006-01-02 15:04"))
-				if !entry.Enabled {
-					statusText += " (已禁用)"
-				}
-				status.SetText(statusText)
-			}
-		},
-	)
-	
-	// 设置双击编辑事件
-	m.hostEntryList.OnSelected = func(id widget.ListItemID) {
-		if id >= 0 && id < len(m.hostEntries) {
-			m.currentHostEntry = m.hostEntries[id]
-			m.statusBar.SetText(fmt.Sprintf("已选择Host条目: %s -> %s", m.hostEntries[id].Hostname, m.hostEntries[id].IP))
-		}
+// onProfileSelected Profile选择事件，选中分组节点时不改变当前Profile
+func (m *Manager) onProfileSelected(id widget.TreeNodeID) {
+	tree := m.controller.Tree()
+	if folder, ok := tree.Folder(id); ok {
+		m.selectedFolder = folder
+		return
 	}
-}
-
-// onProfileSelected Profile选择事件
-func (m *Manager) onProfileSelected(id widget.ListItemID) {
-	if id >= 0 && id < len(m.profiles) {
-		// 设置当前选中的Profile
-		m.currentProfile = m.profiles[id]
-		
-		// 加载Profile的Host条目
-		m.hostEntries = m.currentProfile.Entries
-		
-		// 刷新Host条目列表
-		m.hostEntryList.Refresh()
-		
-		// 更新状态栏
-		m.statusBar.SetText(fmt.Sprintf("已选择Profile: %s (包含 %d 个Host条目)", m.currentProfile.Name, len(m.currentProfile.Entries)))
+	profile := tree.Profile(id)
+	if profile == nil {
+		return
 	}
+	m.selectedFolder = ""
+	
+	// 设置当前选中的Profile
+	m.controller.SelectProfile(profile)
+	
+	// 刷新Host条目列表和环境选择器
+	m.refreshHostEntryList()
+	m.refreshEnvironmentSelect()
+	
+	// 更新状态栏
+	m.statusBar.SetText(fmt.Sprintf("已选择Profile: %s (包含 %d 个Host条目)", profile.Name, len(profile.Entries)))
 }
 
 // onHostEntryChanged Host条目变化事件
@@ -546,8 +568,7 @@ func (m *Manager) onHostEntryChanged() {
 
 // onAddHostEntry 添加Host条目事件处理
 func (m *Manager) onAddHostEntry() {
-	if m.currentProfile == nil {
-		dialog.ShowInformation("提示", "请先选择一个Profile", m.window)
+	if m.editableProfile() == nil {
 		return
 	}
 	
@@ -557,114 +578,169 @@ func (m *Manager) onAddHostEntry() {
 
 // onEditHostEntry 编辑Host条目事件处理
 func (m *Manager) onEditHostEntry() {
-	if m.currentHostEntry == nil {
+	entry := m.controller.CurrentEntry()
+	if entry == nil {
 		dialog.ShowInformation("提示", "请先选择要编辑的Host条目", m.window)
 		return
 	}
+	if m.editableProfile() == nil {
+		return
+	}
 	
 	// 显示Host条目编辑对话框
-	m.showHostEntryDialog(m.currentHostEntry)
+	m.showHostEntryDialog(entry)
 }
 
 // onDeleteHostEntry 删除Host条目事件处理
 func (m *Manager) onDeleteHostEntry() {
-	if m.currentHostEntry == nil {
+	entry := m.controller.CurrentEntry()
+	if entry == nil {
 		dialog.ShowInformation("提示", "请先选择要删除的Host条目", m.window)
 		return
 	}
+	if m.editableProfile() == nil {
+		return
+	}
 	
 	// 显示确认删除对话框
-	message := fmt.Sprintf("确定要删除Host条目 '%s -> %s' 吗？\n\n此操作不可撤销。", m.currentHostEntry.Hostname, m.currentHostEntry.IP)
+	message := fmt.Sprintf("确定要删除Host条目 '%s -> %s' 吗？\n\n删除后可通过编辑菜单中的撤销恢复。", entry.Hostname, entry.IP)
 	dialog.ShowConfirm("确认删除", message, func(confirmed bool) {
 		if !confirmed {
 			return
 		}
 		
 		// 从当前Profile中删除Host条目
-		if m.currentProfile != nil {
-			m.currentProfile.RemoveEntry(m.currentHostEntry.ID)
-			
-			// 更新Profile
-			err := m.profileManager.UpdateProfile(m.currentProfile)
-			if err != nil {
-				dialog.ShowError(err, m.window)
-				return
-			}
-			
-			// 刷新Host条目列表
-			m.hostEntries = m.currentProfile.Entries
-			m.hostEntryList.Refresh()
-			m.currentHostEntry = nil
-			
-			m.statusBar.SetText("Host条目删除成功")
+		recordEdit := m.beginEdit(m.controller.CurrentProfile(), fmt.Sprintf("删除Host条目 '%s'", entry.Hostname))
+		if _, err := m.controller.DeleteCurrentEntry(); err != nil {
+			dialog.ShowError(err, m.window)
+			return
 		}
+		recordEdit()
+		m.recordActivity(models.EventHostEntryDeleted, entryActivityData(m.controller.CurrentProfile(), entry))
+		
+		// 刷新Host条目列表
+		m.refreshHostEntryList()
+		m.refreshEnvironmentSelect()
+		
+		m.statusBar.SetText("Host条目删除成功")
+		m.scheduleAutoApply()
 	}, m.window)
 }
 
 // onApplyProfile 应用Profile事件处理
 func (m *Manager) onApplyProfile() {
-	if m.currentProfile == nil {
+	current := m.controller.CurrentProfile()
+	if current == nil {
 		dialog.ShowInformation("提示", "请先选择要应用的Profile", m.window)
 		return
 	}
-	
+
+	// 检查hosts文件权限，避免在进度对话框之后才提示写入失败
+	if !m.checkApplyPreflight(current.Name, m.confirmApplyProfile) {
+		return
+	}
+
+	m.confirmApplyProfile()
+}
+
+// confirmApplyProfile 预览hosts文件的改动，确认后应用当前Profile
+func (m *Manager) confirmApplyProfile() {
+	current := m.controller.CurrentProfile()
+	if current == nil {
+		return
+	}
+
+	// 显示将写入hosts文件的改动，无法生成预览时退回简单的确认对话框
+	if preview, err := m.controller.PreviewApply(); err == nil {
+		m.showApplyPreview(preview)
+		return
+	}
+
 	// 显示确认对话框
 	message := fmt.Sprintf("确定要应用Profile '%s' 吗？\n\n这将会：\n1. 备份当前hosts文件\n2. 将Profile中的%d个Host条目写入hosts文件\n3. 设置此Profile为当前激活状态", 
-		m.currentProfile.Name, len(m.currentProfile.Entries))
+		current.Name, len(current.Entries))
 	
 	dialog.ShowConfirm("确认应用Profile", message, func(confirmed bool) {
 		if !confirmed {
 			return
 		}
+		m.applyCurrentProfile(host.ApplyOptions{})
+	}, m.window)
+}
+
+// confirmOverrideLimits 应用内容超过规模限制时，列出超限项并询问是否仍然应用
+func (m *Manager) confirmOverrideLimits(limitErr *host.LimitError) {
+	message := fmt.Sprintf("应用Profile '%s' 后hosts文件将超过规模限制：\n\n- %s\n\n部分系统解析器在行过长或文件过大时可能工作异常。",
+		m.controller.CurrentProfile().Name, strings.Join(limitErr.Violations, "\n- "))
+	d := dialog.NewCustomConfirm("超过规模限制", "仍然应用", "取消", widget.NewLabel(message), func(confirmed bool) {
+		if confirmed {
+			m.applyCurrentProfile(host.ApplyOptions{IgnoreLimits: true})
+		}
+	}, m.window)
+	d.Show()
+}
+
+// applyCurrentProfile 按选项应用当前Profile并更新激活状态
+func (m *Manager) applyCurrentProfile(options host.ApplyOptions) {
+	// 显示进度对话框
+	progressDialog := dialog.NewProgressInfinite("应用Profile", "正在应用Profile，请稍候...", m.window)
+	progressDialog.Show()
+	
+	// 在goroutine中执行应用操作
+	go func() {
+		defer progressDialog.Hide()
 		
-		// 显示进度对话框
-		progressDialog := dialog.NewProgressInfinite("应用Profile", "正在应用Profile，请稍候...", m.window)
-		progressDialog.Show()
+		// 应用Profile并设置为激活状态
+		result, err := m.controller.Apply(options)
+		var limitErr *host.LimitError
+		if errors.As(err, &limitErr) {
+			m.confirmOverrideLimits(limitErr)
+			return
+		}
+		if errors.Is(err, rules.ErrRuleViolation) {
+			m.showErrorDialog("应用Profile失败", err)
+			return
+		}
+		if err != nil && result == nil {
+			m.recordActivity(models.EventError, map[string]interface{}{"operation": "apply", "error": err.Error()})
+			dialog.ShowError(fmt.Errorf("应用Profile失败: %v", err), m.window)
+			return
+		}
+		if err != nil {
+			dialog.ShowError(fmt.Errorf("更新Profile状态失败: %v", err), m.window)
+			return
+		}
 		
-		// 在goroutine中执行应用操作
-		go func() {
-			defer progressDialog.Hide()
-			
-			// 应用Profile
-			err := m.hostManager.ApplyProfile(m.currentProfile)
-			if err != nil {
-				dialog.ShowError(fmt.Errorf("应用Profile失败: %v", err), m.window)
-				return
-			}
-			
-			// 更新Profile状态
-			// 先将所有Profile设为非激活状态
-			for _, profile := range m.profiles {
-				profile.IsActive = false
-				m.profileManager.UpdateProfile(profile)
-			}
-			
-			// 设置当前Profile为激活状态
-			m.currentProfile.IsActive = true
-			err = m.profileManager.UpdateProfile(m.currentProfile)
-			if err != nil {
-				dialog.ShowError(fmt.Errorf("更新Profile状态失败: %v", err), m.window)
-				return
-			}
-			
-			// 刷新界面
-			m.refreshProfileList()
-			m.statusBar.SetText(fmt.Sprintf("Profile '%s' 应用成功", m.currentProfile.Name))
-			
-			// 显示成功提示
-			dialog.ShowInformation("成功", fmt.Sprintf("Profile '%s' 已成功应用到hosts文件", m.currentProfile.Name), m.window)
-		}()
-	}, m.window)
+		m.recordUsage(telemetry.EventApplyProfile)
+		m.recordActivity(models.EventProfileActivated, applyActivityData(result))
+		m.recordApply(result)
+		m.recheckHealth()
+		
+		// 刷新界面
+		m.refreshProfileList()
+		m.statusBar.SetText(fmt.Sprintf("Profile '%s' 应用成功: %s", m.controller.CurrentProfile().Name, applySummaryText(result)))
+		
+		// 显示应用结果
+		m.showApplySummary(result)
+	}()
 }
 
-// onBackupHosts 备份hosts文件事件处理
+// onBackupHosts 备份hosts文件事件处理，可以为备份设置里程碑名称和标签
 func (m *Manager) onBackupHosts() {
-	// 显示确认对话框
-	message := "确定要备份当前hosts文件吗？\n\n备份文件将保存到应用数据目录中。"
-	dialog.ShowConfirm("确认备份", message, func(confirmed bool) {
+	milestoneEntry := widget.NewEntry()
+	milestoneEntry.SetPlaceHolder("例如: before DNS migration")
+	tagsEntry := widget.NewEntry()
+	tagsEntry.SetPlaceHolder("多个标签用逗号分隔")
+	items := []*widget.FormItem{
+		{Text: "里程碑名称", Widget: milestoneEntry, HintText: "可选，里程碑备份不会被自动清理"},
+		{Text: "标签", Widget: tagsEntry, HintText: "可选，用于在备份历史中筛选"},
+	}
+
+	d := dialog.NewForm("备份Hosts文件", "备份", "取消", items, func(confirmed bool) {
 		if !confirmed {
 			return
 		}
+		options := host.BackupOptions{Milestone: milestoneEntry.Text, Tags: parseBackupTags(tagsEntry.Text)}
 		
 		// 显示进度对话框
 		progressDialog := dialog.NewProgressInfinite("备份hosts文件", "正在备份hosts文件，请稍候...", m.window)
@@ -675,19 +751,25 @@ func (m *Manager) onBackupHosts() {
 			defer progressDialog.Hide()
 			
 			// 执行备份
-			backup, err := m.hostManager.BackupHostsFile()
+			backup, err := m.hostManager.BackupHostsFileWithOptions(options)
 			if err != nil {
 				dialog.ShowError(fmt.Errorf("备份失败: %v", err), m.window)
 				return
 			}
 			
+			m.recordUsage(telemetry.EventBackupHosts)
+			m.recordActivity(models.EventSystemBackupCreated, map[string]interface{}{"path": backup.FilePath, "size": backup.Size, "milestone": strings.TrimSpace(options.Milestone)})
 			m.statusBar.SetText("hosts文件备份成功")
+			// Helper也保留一份备份，Helper暂时不可用时排队等待
+			m.queueHelperRequest("backup_hosts", map[string]interface{}{"milestone": options.Milestone, "tags": options.Tags})
 			
 			// 显示成功提示
 			message := fmt.Sprintf("hosts文件备份成功！\n\n备份文件路径：\n%s", backup.FilePath)
 			dialog.ShowInformation("备份成功", message, m.window)
 		}()
 	}, m.window)
+	d.Resize(fyne.NewSize(420, 0))
+	d.Show()
 }
 
 // onShowSettings 显示设置事件处理
@@ -724,11 +806,11 @@ func (m *Manager) onShowSettings() {
 	compressionCheck := widget.NewCheck("启用备份压缩", nil)
 	compressionCheck.SetChecked(true) // 默认启用压缩
 	
-	retentionEntry := widget.NewEntry()
-	retentionEntry.SetText(fmt.Sprintf("%d", m.appConfig.Backup.RetentionDays))
-	
-	maxBackupsEntry := widget.NewEntry()
-	maxBackupsEntry.SetText(fmt.Sprintf("%d", m.appConfig.Backup.MaxBackups))
+	retentionEntry := newNumberEntry(m.appConfig.Backup.RetentionDays, 0, models.MaxBackupRetentionDays)
+	maxBackupsEntry := newNumberEntry(m.appConfig.Backup.MaxBackups, 0, models.MaxBackupCount)
+	deltaMinLinesEntry := newNumberEntry(m.appConfig.Backup.DeltaMinLines, 0, unbounded)
+	deltaMinLinesEntry.step = 1000
+	fullBackupEveryEntry := newNumberEntry(m.appConfig.Backup.FullBackupEvery, 0, unbounded)
 	
 	backupIntervalSelect := widget.NewSelect([]string{"每小时", "每天", "每周", "手动"}, nil)
 	backupIntervalSelect.SetSelected("手动") // 默认手动备份
@@ -741,14 +823,78 @@ func (m *Manager) onShowSettings() {
 	
 	// 日志级别设置
 	logLevelSelect := widget.NewSelect([]string{"DEBUG", "INFO", "WARN", "ERROR"}, nil)
-	logLevelSelect.SetSelected("INFO") // 默认INFO级别
+	logLevelSelect.SetSelected(strings.ToUpper(m.appConfig.Log.Level))
 	
 	// 安全设置
 	requireAdminCheck := widget.NewCheck("需要管理员权限", nil)
 	requireAdminCheck.SetChecked(true) // 默认需要管理员权限
 	
 	backupOnApplyCheck := widget.NewCheck("应用Profile前自动备份", nil)
-	backupOnApplyCheck.SetChecked(true) // 默认启用
+	backupOnApplyCheck.SetChecked(m.appConfig.Security.BackupBeforeChange)
+	restoreOnQuitSelect := widget.NewSelect([]string{restoreOnQuitOff, restoreOnQuitClear, restoreOnQuitBaseline}, nil)
+	restoreOnQuitSelect.SetSelected(restoreOnQuitLabel(m.appConfig.Security.RestoreOnQuit))
+	
+	applyOnSaveCheck := widget.NewCheck("修改激活的Profile后自动应用", nil)
+	applyOnSaveCheck.SetChecked(m.appConfig.UI.ApplyOnSave)
+	
+	dnsStatsCheck := widget.NewCheck("统计主机名解析次数", nil)
+	dnsStatsCheck.SetChecked(m.appConfig.DNSStats.Enabled)
+	
+	healthCheckCheck := widget.NewCheck("后台定期验证hosts文件和备份", nil)
+	healthCheckCheck.SetChecked(m.appConfig.HealthCheck.Enabled)
+	healthInterval := m.appConfig.HealthCheck.Interval
+	if healthInterval <= 0 {
+		healthInterval = models.DefaultAppConfig().HealthCheck.Interval
+	}
+	healthIntervalEntry := newNumberEntry(int(healthInterval.Minutes()), 1, unbounded)
+	
+	xpcTimeout := m.appConfig.XPC.Timeout
+	if xpcTimeout <= 0 {
+		xpcTimeout = models.DefaultAppConfig().XPC.Timeout
+	}
+	xpcTimeoutEntry := newNumberEntry(int(xpcTimeout.Seconds()), 1, unbounded)
+	
+	allowUnderscoresCheck := widget.NewCheck("允许主机名包含下划线", nil)
+	allowUnderscoresCheck.SetChecked(m.appConfig.Hostnames.AllowUnderscores)
+	
+	queryLogEntry := widget.NewEntry()
+	queryLogEntry.SetPlaceHolder("例如: /var/log/dnsmasq.log")
+	queryLogEntry.SetText(m.appConfig.DNSStats.QueryLogPath)
+	
+	// hosts文件规模限制，0表示不限制
+	maxHostnamesEntry := widget.NewEntry()
+	maxHostnamesEntry.SetText(fmt.Sprintf("%d", m.appConfig.Limits.MaxHostnamesPerLine))
+	maxEntriesEntry := widget.NewEntry()
+	maxEntriesEntry.SetText(fmt.Sprintf("%d", m.appConfig.Limits.MaxEntries))
+	maxFileSizeEntry := widget.NewEntry()
+	maxFileSizeEntry.SetText(fmt.Sprintf("%d", m.appConfig.Limits.MaxFileSize/1024))
+	
+	// 网络设置
+	proxyModeSelect := widget.NewSelect([]string{"系统代理", "手动代理", "不使用代理"}, nil)
+	proxyURLEntry := widget.NewEntry()
+	proxyURLEntry.SetPlaceHolder("例如: http://127.0.0.1:7890")
+	proxyURLEntry.SetText(m.appConfig.Network.ProxyURL)
+	proxyUserEntry := widget.NewEntry()
+	proxyUserEntry.SetText(m.appConfig.Network.ProxyUsername)
+	proxyPasswordEntry := widget.NewPasswordEntry()
+	proxyPasswordEntry.SetText(m.appConfig.Network.ProxyPassword)
+	offlineCheck := widget.NewCheck("离线模式", nil)
+	offlineCheck.SetChecked(m.appConfig.Network.Offline)
+	proxyModeSelect.OnChanged = func(selected string) {
+		if selected == "手动代理" {
+			proxyURLEntry.Enable()
+		} else {
+			proxyURLEntry.Disable()
+		}
+	}
+	switch m.appConfig.Network.ProxyMode {
+	case models.ProxyModeManual:
+		proxyModeSelect.SetSelected("手动代理")
+	case models.ProxyModeNone:
+		proxyModeSelect.SetSelected("不使用代理")
+	default:
+		proxyModeSelect.SetSelected("系统代理")
+	}
 	
 	// 创建分组容器
 	backupForm := &widget.Form{
@@ -757,8 +903,10 @@ func (m *Manager) onShowSettings() {
 			{Text: "自动备份", Widget: autoBackupCheck},
 			{Text: "备份间隔", Widget: backupIntervalSelect},
 			{Text: "备份压缩", Widget: compressionCheck},
-			{Text: "保留天数", Widget: retentionEntry, HintText: "1-365天"},
-			{Text: "最大备份数", Widget: maxBackupsEntry, HintText: "1-100个"},
+			{Text: "保留天数", Widget: retentionEntry, HintText: fmt.Sprintf("0-%d天，0表示不按天数清理", models.MaxBackupRetentionDays)},
+			{Text: "最大备份数", Widget: maxBackupsEntry, HintText: fmt.Sprintf("0-%d个，0表示不限制数量", models.MaxBackupCount)},
+			{Text: "增量备份阈值(行)", Widget: deltaMinLinesEntry, HintText: "hosts文件达到该行数时只保存变化的行，0表示不使用增量备份"},
+			{Text: "完整备份间隔", Widget: fullBackupEveryEntry, HintText: "每隔多少个增量备份保存一次完整备份"},
 		},
 	}
 	backupGroup := widget.NewCard("备份设置", "", backupForm)
@@ -767,6 +915,7 @@ func (m *Manager) onShowSettings() {
 		Items: []*widget.FormItem{
 			{Text: "主题", Widget: themeSelect},
 			{Text: "语言", Widget: languageSelect},
+			{Text: "保存时应用", Widget: applyOnSaveCheck},
 		},
 	}
 	uiGroup := widget.NewCard("界面设置", "", uiForm)
@@ -775,6 +924,11 @@ func (m *Manager) onShowSettings() {
 		Items: []*widget.FormItem{
 			{Text: "Hosts文件路径", Widget: hostsPathEntry},
 			{Text: "日志级别", Widget: logLevelSelect},
+			{Text: "下划线", Widget: allowUnderscoresCheck, HintText: "下划线不符合DNS规范，部分解析器会拒绝"},
+			{Text: "定期验证", Widget: healthCheckCheck},
+			{Text: "验证间隔(分钟)", Widget: healthIntervalEntry, HintText: "检查语法、重复主机名、漂移和备份完整性"},
+			{Text: "命中统计", Widget: dnsStatsCheck},
+			{Text: "DNS查询日志", Widget: queryLogEntry, HintText: "支持dnsmasq、unbound和mDNSResponder日志格式"},
 		},
 	}
 	systemGroup := widget.NewCard("系统设置", "", systemForm)
@@ -783,16 +937,48 @@ func (m *Manager) onShowSettings() {
 		Items: []*widget.FormItem{
 			{Text: "管理员权限", Widget: requireAdminCheck},
 			{Text: "自动备份", Widget: backupOnApplyCheck},
+			{Text: "退出时还原", Widget: restoreOnQuitSelect, HintText: "只希望mHost运行期间覆盖生效时使用"},
+			{Text: "Helper超时(秒)", Widget: xpcTimeoutEntry, HintText: "单个操作的超时可在配置文件xpc.operation_timeouts中覆盖"},
+			{Text: "Helper访问", Widget: widget.NewButton("管理白名单/黑名单", m.onManageHelperAccess), HintText: "无需重启Helper即可解封被限速的客户端"},
+			{Text: "Helper状态", Widget: widget.NewButton("查看请求延迟", m.onShowHelperStatus), HintText: "按操作统计的请求数与p50/p95/p99延迟"},
 		},
 	}
 	securityGroup := widget.NewCard("安全设置", "", securityForm)
 	
+	limitsForm := &widget.Form{
+		Items: []*widget.FormItem{
+			{Text: "每行主机名", Widget: maxHostnamesEntry, HintText: "部分解析器无法处理过长的行，0表示不限制"},
+			{Text: "最大条目数", Widget: maxEntriesEntry, HintText: "0表示不限制"},
+			{Text: "最大文件(KB)", Widget: maxFileSizeEntry, HintText: "0表示不限制"},
+		},
+	}
+	limitsGroup := widget.NewCard("规模限制", "应用Profile时超过限制会提示确认", limitsForm)
+	
+	networkForm := &widget.Form{
+		Items: []*widget.FormItem{
+			{Text: "离线", Widget: offlineCheck, HintText: "禁止远程导入、IP信息查询等所有对外网络请求"},
+			{Text: "代理模式", Widget: proxyModeSelect, HintText: "系统代理会读取HTTP_PROXY环境变量和macOS网络设置"},
+			{Text: "代理地址", Widget: proxyURLEntry},
+			{Text: "用户名", Widget: proxyUserEntry, HintText: "代理需要认证时填写"},
+			{Text: "密码", Widget: proxyPasswordEntry},
+		},
+	}
+	networkGroup := widget.NewCard("网络设置", "", networkForm)
+	usageGroup, usageCheck := m.createUsageSettings()
+	snapshotGroup, readSnapshotSettings := m.createSnapshotSettings()
+	dnsFilterGroup, readDNSFilterSettings := m.createDNSFilterSettings()
+	
 	// 创建滚动容器
 	content := container.NewVBox(
 		systemGroup,
 		backupGroup,
+		snapshotGroup,
 		uiGroup,
+		networkGroup,
+		dnsFilterGroup,
 		securityGroup,
+		limitsGroup,
+		usageGroup,
 	)
 	
 	scroll := container.NewScroll(content)
@@ -805,27 +991,122 @@ func (m *Manager) onShowSettings() {
 		}
 		
 		// 验证输入
-		retentionDays, err := fmt.Sscanf(retentionEntry.Text, "%d", new(int))
-		if err != nil || retentionDays != 1 {
-			m.showErrorDialog("输入验证错误", errors.New("备份保留天数必须是有效数字"))
+		retentionDays, err := retentionEntry.Value()
+		if err != nil {
+			m.showErrorDialog("输入验证错误", fmt.Errorf("备份保留天数%w", err))
+			return
+		}
+		
+		maxBackups, err := maxBackupsEntry.Value()
+		if err != nil {
+			m.showErrorDialog("输入验证错误", fmt.Errorf("最大备份数量%w", err))
+			return
+		}
+		
+		deltaMinLines, errDelta := deltaMinLinesEntry.Value()
+		fullBackupEvery, errFull := fullBackupEveryEntry.Value()
+		if errDelta != nil || errFull != nil {
+			m.showErrorDialog("输入验证错误", errors.New("增量备份阈值和完整备份间隔必须是非负整数"))
+			return
+		}
+		
+		var limits models.LimitsConfig
+		var maxFileSizeKB int64
+		_, errHostnames := fmt.Sscanf(maxHostnamesEntry.Text, "%d", &limits.MaxHostnamesPerLine)
+		_, errEntries := fmt.Sscanf(maxEntriesEntry.Text, "%d", &limits.MaxEntries)
+		_, errFileSize := fmt.Sscanf(maxFileSizeEntry.Text, "%d", &maxFileSizeKB)
+		if errHostnames != nil || errEntries != nil || errFileSize != nil ||
+			limits.MaxHostnamesPerLine < 0 || limits.MaxEntries < 0 || maxFileSizeKB < 0 {
+			m.showErrorDialog("输入验证错误", errors.New("规模限制必须是非负整数"))
+			return
+		}
+		limits.MaxFileSize = maxFileSizeKB * 1024
+		
+		healthIntervalMinutes, err := healthIntervalEntry.Value()
+		if err != nil {
+			m.showErrorDialog("输入验证错误", errors.New("验证间隔必须是正整数"))
+			return
+		}
+		
+		xpcTimeoutSeconds, err := xpcTimeoutEntry.Value()
+		if err != nil {
+			m.showErrorDialog("输入验证错误", errors.New("Helper超时必须是正整数"))
+			return
+		}
+		
+		snapshotConfig, err := readSnapshotSettings()
+		if err != nil {
+			m.showErrorDialog("输入验证错误", err)
+			return
+		}
+		
+		dnsFilterConfig, err := readDNSFilterSettings()
+		if err != nil {
+			m.showErrorDialog("输入验证错误", err)
 			return
 		}
 		
-		maxBackups, err := fmt.Sscanf(maxBackupsEntry.Text, "%d", new(int))
-		if err != nil || maxBackups != 1 {
-			m.showErrorDialog("输入验证错误", errors.New("最大备份数量必须是有效数字"))
+		queryLogPath := strings.TrimSpace(queryLogEntry.Text)
+		if dnsStatsCheck.Checked && queryLogPath == "" {
+			m.showErrorDialog("输入验证错误", errors.New("启用命中统计时必须指定DNS查询日志路径"))
 			return
 		}
 		
-		// 更新配置
-		if backupDirEntry.Text != "" {
-			m.appConfig.Backup.BackupPath = backupDirEntry.Text
+		backupPath := m.appConfig.Backup.BackupPath
+		var backupWarnings []string
+		if strings.TrimSpace(backupDirEntry.Text) != "" {
+			check, err := config.CheckBackupPath(backupDirEntry.Text, filepath.Dir(m.workspace.ConfigPath))
+			if err != nil {
+				m.showErrorDialog("输入验证错误", fmt.Errorf("备份目录不可用: %w", err))
+				return
+			}
+			backupPath = check.Path
+			backupWarnings = backupPathWarnings(check)
+		}
+		
+		network := models.NetworkConfig{
+			ProxyMode:     models.ProxyModeSystem,
+			ProxyURL:      strings.TrimSpace(proxyURLEntry.Text),
+			ProxyUsername: strings.TrimSpace(proxyUserEntry.Text),
+			ProxyPassword: proxyPasswordEntry.Text,
+			Offline:       offlineCheck.Checked,
 		}
+		switch proxyModeSelect.Selected {
+		case "手动代理":
+			network.ProxyMode = models.ProxyModeManual
+			if _, err := httpclient.ParseProxyURL(network.ProxyURL); err != nil {
+				m.showErrorDialog("输入验证错误", errors.New("手动代理必须指定有效的代理地址"))
+				return
+			}
+		case "不使用代理":
+			network.ProxyMode = models.ProxyModeNone
+		}
+		
+		// 更新配置，保存后只将变化的部分应用到正在运行的组件
+		previous := m.appConfig.Clone()
+		m.appConfig.Backup.BackupPath = backupPath
 		m.appConfig.Backup.Enabled = autoBackupCheck.Checked
-		fmt.Sscanf(retentionEntry.Text, "%d", &m.appConfig.Backup.RetentionDays)
-		fmt.Sscanf(maxBackupsEntry.Text, "%d", &m.appConfig.Backup.MaxBackups)
+		m.appConfig.Backup.RetentionDays = retentionDays
+		m.appConfig.Backup.MaxBackups = maxBackups
+		m.appConfig.Backup.DeltaMinLines = deltaMinLines
+		m.appConfig.Backup.FullBackupEvery = fullBackupEvery
 		m.appConfig.UI.Theme = themeSelect.Selected
 		m.appConfig.UI.Language = languageSelect.Selected
+		m.appConfig.UI.ApplyOnSave = applyOnSaveCheck.Checked
+		m.appConfig.Log.Level = strings.ToLower(logLevelSelect.Selected)
+		m.appConfig.DNSStats.Enabled = dnsStatsCheck.Checked
+		m.appConfig.DNSStats.QueryLogPath = queryLogPath
+		m.appConfig.Network = network
+		m.appConfig.Telemetry.Enabled = usageCheck.Checked
+		m.appConfig.Security.BackupBeforeChange = backupOnApplyCheck.Checked
+		m.appConfig.Security.RestoreOnQuit = restoreOnQuitMode(restoreOnQuitSelect.Selected)
+		m.appConfig.Limits = limits
+		m.appConfig.Hostnames.AllowUnderscores = allowUnderscoresCheck.Checked
+		m.appConfig.HealthCheck.Enabled = healthCheckCheck.Checked
+		m.appConfig.HealthCheck.Interval = time.Duration(healthIntervalMinutes) * time.Minute
+		m.appConfig.Snapshot = snapshotConfig
+		m.appConfig.DNSFilter = dnsFilterConfig
+		m.appConfig.XPC.Timeout = time.Duration(xpcTimeoutSeconds) * time.Second
 		
 		// 保存配置到文件
 		err = m.configManager.SaveConfig(m.appConfig)
@@ -834,7 +1115,12 @@ func (m *Manager) onShowSettings() {
 			return
 		}
 		
-		m.showSuccessDialog("成功", "设置保存成功，部分设置需要重启应用后生效")
+		m.recordConfigChange(m.applyConfigChanges(previous), configSourceSettings)
+		if len(backupWarnings) > 0 {
+			dialog.ShowInformation("备份目录提示", strings.Join(backupWarnings, "\n\n"), m.window)
+		}
+		
+		m.showSuccessDialog("成功", "设置保存成功，界面语言需要重启应用后生效")
 	}, m.window)
 	
 	// 设置对话框大小并显示
@@ -857,11 +1143,25 @@ func (m *Manager) showProfileDialog(profile *models.Profile) {
 	nameEntry.SetPlaceHolder("请输入Profile名称")
 	descEntry := widget.NewMultiLineEntry()
 	descEntry.SetPlaceHolder("请输入Profile描述（可选）")
+	folderEntry := widget.NewSelectEntry(m.folderOptions())
+	folderEntry.SetPlaceHolder("例如: work/clients")
+	tagsEntry := widget.NewEntry()
+	tagsEntry.SetPlaceHolder("例如: dev, api")
+	authorEntry := widget.NewEntry()
+	authorEntry.SetPlaceHolder("例如: alice")
+	defaultCommentEntry := widget.NewEntry()
+	defaultCommentEntry.SetPlaceHolder("例如: [infra] team=web")
 	
 	// 如果是编辑模式，填充现有数据
 	if profile != nil {
 		nameEntry.SetText(profile.Name)
 		descEntry.SetText(profile.Description)
+		folderEntry.SetText(profile.Folder)
+		tagsEntry.SetText(strings.Join(profile.Tags, ", "))
+		authorEntry.SetText(profile.Author)
+		defaultCommentEntry.SetText(profile.DefaultComment)
+	} else {
+		folderEntry.SetText(m.selectedFolder)
 	}
 	
 	// 创建表单
@@ -869,6 +1169,10 @@ func (m *Manager) showProfileDialog(profile *models.Profile) {
 		Items: []*widget.FormItem{
 			{Text: "名称", Widget: nameEntry, HintText: "Profile的唯一名称"},
 			{Text: "描述", Widget: descEntry, HintText: "Profile的详细描述"},
+			{Text: "文件夹", Widget: folderEntry, HintText: "用\"/\"分隔多级文件夹，为空时位于顶层"},
+			{Text: "标签", Widget: tagsEntry, HintText: "用逗号分隔，按标签分组时使用"},
+			{Text: "作者", Widget: authorEntry, HintText: "新条目默认的负责人，应用时记录在hosts文件中"},
+			{Text: "默认注释", Widget: defaultCommentEntry, HintText: "加在新条目注释的前面，可包含key=value字段"},
 		},
 	}
 	
@@ -887,35 +1191,38 @@ func (m *Manager) showProfileDialog(profile *models.Profile) {
 		name := strings.TrimSpace(nameEntry.Text)
 		desc := strings.TrimSpace(descEntry.Text)
 		
-		// 使用新的验证方法
-		if err := m.validateInput(name, "Profile名称", true, 50); err != nil {
+		if err := controller.ValidateProfileInfo(name, desc); err != nil {
 			m.showErrorDialog("输入验证错误", err)
 			return
 		}
 		
-		if err := m.validateInput(desc, "描述", false, 500); err != nil {
-			m.showErrorDialog("输入验证错误", err)
-			return
+		input := controller.ProfileInput{
+			Name:           name,
+			Description:    desc,
+			Folder:         folderEntry.Text,
+			Tags:           controller.ParseTags(tagsEntry.Text),
+			Author:         authorEntry.Text,
+			DefaultComment: defaultCommentEntry.Text,
 		}
-		
-		var err error
 		if profile == nil {
 			// 创建新Profile
-			_, err = m.profileManager.CreateProfile(name, desc)
+			created, err := m.controller.CreateProfile(input)
 			if err != nil {
 				m.showErrorDialog("创建失败", err)
 				return
 			}
+			m.recordUsage(telemetry.EventCreateProfile)
+			m.recordActivity(models.EventProfileCreated, profileActivityData(created))
 			m.showSuccessDialog("成功", "Profile创建成功")
 		} else {
 			// 更新现有Profile
-			profile.Name = name
-			profile.Description = desc
-			err = m.profileManager.UpdateProfile(profile)
-			if err != nil {
+			recordEdit := m.beginEdit(profile, fmt.Sprintf("编辑Profile '%s'", profile.Name))
+			if err := m.controller.UpdateProfileInfo(profile, input); err != nil {
 				m.showErrorDialog("更新失败", err)
 				return
 			}
+			recordEdit()
+			m.recordActivity(models.EventProfileUpdated, profileActivityData(profile))
 			m.showSuccessDialog("成功", "Profile更新成功")
 		}
 		
@@ -924,31 +1231,24 @@ func (m *Manager) showProfileDialog(profile *models.Profile) {
 	}, m.window)
 	
 	// 设置对话框大小并显示
-	d.Resize(fyne.NewSize(400, 300))
+	d.Resize(fyne.NewSize(400, 400))
 	d.Show()
 }
 
 // refreshProfileList 刷新Profile列表
 func (m *Manager) refreshProfileList() {
-	profileSummaries, err := m.profileManager.ListProfiles()
-	if err != nil {
+	if err := m.controller.Refresh(); err != nil {
 		m.statusBar.SetText(fmt.Sprintf("加载Profile列表失败: %v", err))
 		return
 	}
-	
-	// 转换为完整的Profile对象
-	m.profiles = make([]*models.Profile, 0, len(profileSummaries))
-	for _, summary := range profileSummaries {
-		profile, err := m.profileManager.GetProfile(summary.ID)
-		if err != nil {
-			continue // 跳过无法加载的profile
-		}
-		m.profiles = append(m.profiles, profile)
-	}
 	m.profileList.Refresh()
+	m.refreshHostEntryList()
 	
 	// 更新Profile选择器
 	m.updateProfileSelector()
+
+	// 通知守护进程重新加载
+	m.notifyDaemon()
 }
 
 // showHostEntryDialog 显示Host条目编辑对话框
@@ -963,6 +1263,15 @@ func (m *Manager) showHostEntryDialog(hostEntry *models.HostEntry) {
 	ipEntry.SetPlaceHolder("请输入IP地址")
 	commentEntry := widget.NewEntry()
 	commentEntry.SetPlaceHolder("请输入注释（可选）")
+	ownerEntry := widget.NewEntry()
+	ownerEntry.SetPlaceHolder("例如: alice")
+	ticketEntry := widget.NewEntry()
+	ticketEntry.SetPlaceHolder("例如: OPS-123")
+	expiresEntry := widget.NewEntry()
+	expiresEntry.SetPlaceHolder(hostsfile.ExpiresLayout)
+	variantsEntry := widget.NewMultiLineEntry()
+	variantsEntry.SetPlaceHolder("每行一个，例如:\nstaging=10.0.0.2\nprod=10.0.0.3")
+	variantsEntry.SetMinRowsVisible(3)
 	enabledCheck := widget.NewCheck("启用此条目", nil)
 	enabledCheck.SetChecked(true)
 	
@@ -970,8 +1279,20 @@ func (m *Manager) showHostEntryDialog(hostEntry *models.HostEntry) {
 	if hostEntry != nil {
 		hostnameEntry.SetText(hostEntry.Hostname)
 		ipEntry.SetText(hostEntry.IP)
-		commentEntry.SetText(hostEntry.Comment)
+		meta := hostsfile.ParseComment(hostEntry.Comment)
+		commentEntry.SetText(meta.Note)
+		ownerEntry.SetText(meta.Owner())
+		ticketEntry.SetText(meta.Ticket())
+		expiresEntry.SetText(meta.Fields[hostsfile.MetaExpires])
+		variantsEntry.SetText(formatVariants(hostEntry.Variants, "\n"))
 		enabledCheck.SetChecked(hostEntry.Enabled)
+	} else if current := m.controller.CurrentProfile(); current != nil {
+		// 新条目预先填入Profile的默认注释和作者
+		meta := hostsfile.ParseComment(controller.EntryComment(current, ""))
+		commentEntry.SetText(meta.Note)
+		ownerEntry.SetText(meta.Owner())
+		ticketEntry.SetText(meta.Ticket())
+		expiresEntry.SetText(meta.Fields[hostsfile.MetaExpires])
 	}
 	
 	// 创建表单
@@ -979,7 +1300,11 @@ func (m *Manager) showHostEntryDialog(hostEntry *models.HostEntry) {
 		Items: []*widget.FormItem{
 			{Text: "主机名", Widget: hostnameEntry, HintText: "例如: www.example.com"},
 			{Text: "IP地址", Widget: ipEntry, HintText: "例如: 192.168.1.100"},
+			{Text: "环境变体", Widget: variantsEntry, HintText: "按Profile选择的环境使用对应IP，未列出的环境使用上面的IP"},
 			{Text: "注释", Widget: commentEntry, HintText: "可选的描述信息"},
+			{Text: "负责人", Widget: ownerEntry, HintText: "写入注释的owner字段"},
+			{Text: "工单", Widget: ticketEntry, HintText: "写入注释的ticket字段"},
+			{Text: "过期日期", Widget: expiresEntry, HintText: "过期后在条目分析报告中列为过期条目"},
 			{Text: "状态", Widget: enabledCheck, HintText: "是否启用此Host条目"},
 		},
 	}
@@ -998,52 +1323,67 @@ func (m *Manager) showHostEntryDialog(hostEntry *models.HostEntry) {
 		
 		hostname := strings.TrimSpace(hostnameEntry.Text)
 		ip := strings.TrimSpace(ipEntry.Text)
-		comment := strings.TrimSpace(commentEntry.Text)
-		enabled := enabledCheck.Checked
-		
-		// 使用新的验证方法
-		if err := m.validateHostname(hostname); err != nil {
+		comment, err := buildEntryComment(hostEntry, commentEntry.Text, ownerEntry.Text, ticketEntry.Text, expiresEntry.Text)
+		if err != nil {
+			m.showErrorDialog("输入验证错误", err)
+			return
+		}
+		variants, err := parseVariants(variantsEntry.Text)
+		if err != nil {
 			m.showErrorDialog("输入验证错误", err)
 			return
 		}
 		
-		if err := m.validateIPAddress(ip); err != nil {
+		if err := controller.ValidateHostname(hostname); err != nil {
 			m.showErrorDialog("输入验证错误", err)
 			return
 		}
 		
-		if err := m.validateInput(comment, "注释", false, 200); err != nil {
+		// 与导入器和Helper使用相同的规则规范化主机名
+		normalized, err := m.hostnameNormalizer().Normalize(hostname)
+		if err != nil {
+			m.showErrorDialog("输入验证错误", fmt.Errorf("主机名无效: %v", err))
+			return
+		}
+		
+		input := controller.EntryInput{
+			Hostname: normalized,
+			IP:       ip,
+			Comment:  comment,
+			Enabled:  enabledCheck.Checked,
+			Variants: variants,
+		}
+		if err := controller.ValidateEntry(input); err != nil {
 			m.showErrorDialog("输入验证错误", err)
 			return
 		}
 		
-		var err error
+		// 保存到当前Profile
+		label := fmt.Sprintf("编辑Host条目 '%s'", normalized)
 		if hostEntry == nil {
-			// 创建新Host条目
-			newEntry := models.NewHostEntry(ip, hostname, comment)
-			newEntry.Enabled = enabled
-			m.currentProfile.AddEntry(newEntry)
-		} else {
-			// 更新现有Host条目
-			hostEntry.Hostname = hostname
-			hostEntry.IP = ip
-			hostEntry.Comment = comment
-			hostEntry.Enabled = enabled
-			hostEntry.UpdatedAt = time.Now()
+			label = fmt.Sprintf("添加Host条目 '%s'", normalized)
 		}
-		
-		// 更新Profile
-		err = m.profileManager.UpdateProfile(m.currentProfile)
+		recordEdit := m.beginEdit(m.controller.CurrentProfile(), label)
+		saved, err := m.controller.SaveEntry(hostEntry, input)
 		if err != nil {
 			m.showErrorDialog("保存失败", err)
 			return
 		}
+		recordEdit()
+		eventType := models.EventHostEntryUpdated
+		if hostEntry == nil {
+			eventType = models.EventHostEntryAdded
+		}
+		m.recordActivity(eventType, entryActivityData(m.controller.CurrentProfile(), saved))
 		
 		// 刷新Host条目列表
-		m.hostEntries = m.currentProfile.Entries
-		m.hostEntryList.Refresh()
+		m.refreshHostEntryList()
+		m.refreshEnvironmentSelect()
+		m.scheduleAutoApply()
 		
-		if hostEntry == nil {
+		if warnings := m.controller.EntryRuleViolations(saved); len(warnings) > 0 {
+			dialog.ShowInformation("已保存，但违反了校验规则", ruleViolationText(warnings), m.window)
+		} else if hostEntry == nil {
 			m.showSuccessDialog("成功", "Host条目添加成功")
 		} else {
 			m.showSuccessDialog("成功", "Host条目更新成功")
@@ -1057,13 +1397,14 @@ func (m *Manager) showHostEntryDialog(hostEntry *models.HostEntry) {
 
 // onEditProfile 编辑Profile事件处理
 func (m *Manager) onEditProfile() {
-	if m.currentProfile == nil {
+	current := m.controller.CurrentProfile()
+	if current == nil {
 		dialog.ShowInformation("提示", "请先选择要编辑的Profile", m.window)
 		return
 	}
 	
 	// 显示编辑对话框
-	m.showProfileDialog(m.currentProfile)
+	m.showProfileDialog(current)
 }
 
 // onDeleteProfile 删除Profile事件处理
@@ -1071,29 +1412,34 @@ func (m *Manager) onDeleteProfile() {
 	// 添加panic恢复
 	defer m.handlePanic()
 	
-	if m.currentProfile == nil {
+	current := m.controller.CurrentProfile()
+	if current == nil {
 		dialog.ShowInformation("提示", "请先选择要删除的Profile", m.window)
 		return
 	}
 	
 	// 显示确认删除对话框
-	message := fmt.Sprintf("确定要删除Profile '%s' 吗？\n\n此操作不可撤销。", m.currentProfile.Name)
+	message := fmt.Sprintf("确定要删除Profile '%s' 吗？\n\n此操作不可撤销。", current.Name)
 	dialog.ShowConfirm("确认删除", message, func(confirmed bool) {
 		if !confirmed {
 			return
 		}
 		
-		// 执行删除操作
-		err := m.profileManager.DeleteProfile(m.currentProfile.ID)
+		// 执行删除操作，并清空当前选择；同步资产清单的只读Profile由资产清单同步删除
+		var deleted *models.Profile
+		var err error
+		if current.ReadOnly() {
+			deleted, err = m.deleteInventory(current)
+		} else {
+			deleted, err = m.controller.DeleteCurrentProfile()
+		}
 		if err != nil {
 			m.showErrorDialog("删除失败", err)
 			return
 		}
-		
-		// 清空当前选择
-		m.currentProfile = nil
-		m.hostEntries = nil
-		m.hostEntryList.Refresh()
+		m.recordActivity(models.EventProfileDeleted, profileActivityData(deleted))
+		m.undo.Forget(deleted.ID)
+		m.refreshHostEntryList()
 		
 		// 刷新Profile列表
 		m.refreshProfileList()
@@ -1115,26 +1461,33 @@ func (m *Manager) onRefresh() {
 	go func() {
 		defer progressDialog.Hide()
 		
+		// 重新读取磁盘上的Profile（可能已被CLI或守护进程修改）
+		if err := m.profileManager.Reload(); err != nil {
+			m.showErrorDialog("刷新失败", err)
+			return
+		}
+
 		// 重新加载Profile列表
 		if err := m.loadInitialData(); err != nil {
 			m.showErrorDialog("刷新失败", err)
 			return
 		}
 		
-		m.showSuccessDialog("成功", fmt.Sprintf("已刷新，共加载%d个Profile", len(m.profiles)))
+		m.showSuccessDialog("成功", fmt.Sprintf("已刷新，共加载%d个Profile", len(m.controller.Profiles())))
 	}()
 }
 
 // onCopyProfile 复制Profile
 func (m *Manager) onCopyProfile() {
-	if m.currentProfile == nil {
+	current := m.controller.CurrentProfile()
+	if current == nil {
 		dialog.ShowInformation("提示", "请先选择要复制的Profile", m.window)
 		return
 	}
 	
 	// 创建输入对话框获取新名称
 	nameEntry := widget.NewEntry()
-	nameEntry.SetText(m.currentProfile.Name + "_副本")
+	nameEntry.SetText(current.Name + "_副本")
 	
 	form := &widget.Form{
 		Items: []*widget.FormItem{
@@ -1147,36 +1500,17 @@ func (m *Manager) onCopyProfile() {
 			return
 		}
 		
-		newName := strings.TrimSpace(nameEntry.Text)
-		if newName == "" {
-			dialog.ShowError(errors.New("Profile名称不能为空"), m.window)
-			return
-		}
-		
-		// 创建新Profile
-		newProfile, err := m.profileManager.CreateProfile(newName, m.currentProfile.Description)
-		if err != nil {
-			dialog.ShowError(err, m.window)
-			return
-		}
-		
-		// 复制Host条目
-		for _, entry := range m.currentProfile.Entries {
-			newEntry := models.NewHostEntry(entry.IP, entry.Hostname, entry.Comment)
-			newEntry.Enabled = entry.Enabled
-			newProfile.AddEntry(newEntry)
-		}
-		
-		// 保存新Profile
-		err = m.profileManager.UpdateProfile(newProfile)
+		// 复制Profile及其Host条目
+		newProfile, err := m.controller.CopyCurrentProfile(nameEntry.Text)
 		if err != nil {
 			dialog.ShowError(err, m.window)
 			return
 		}
+		m.recordActivity(models.EventProfileCreated, profileActivityData(newProfile))
 		
 		// 刷新列表
 		m.refreshProfileList()
-		m.statusBar.SetText(fmt.Sprintf("Profile '%s' 复制成功", newName))
+		m.statusBar.SetText(fmt.Sprintf("Profile '%s' 复制成功", newProfile.Name))
 	}, m.window)
 	
 	d.Resize(fyne.NewSize(350, 150))
@@ -1185,35 +1519,47 @@ func (m *Manager) onCopyProfile() {
 
 // onToggleHostEntry 切换Host条目启用状态
 func (m *Manager) onToggleHostEntry() {
-	if m.currentHostEntry == nil {
+	if m.controller.CurrentEntry() == nil {
 		dialog.ShowInformation("提示", "请先选择要切换状态的Host条目", m.window)
 		return
 	}
 	
-	// 切换状态
-	m.currentHostEntry.Enabled = !m.currentHostEntry.Enabled
-	m.currentHostEntry.UpdatedAt = time.Now()
-	
-	// 更新Profile
-	err := m.profileManager.UpdateProfile(m.currentProfile)
+	// 切换状态并更新Profile
+	recordEdit := m.beginEdit(m.controller.CurrentProfile(), fmt.Sprintf("启用/禁用Host条目 '%s'", m.controller.CurrentEntry().Hostname))
+	entry, err := m.controller.ToggleCurrentEntry()
 	if err != nil {
 		dialog.ShowError(err, m.window)
 		return
 	}
+	recordEdit()
+	m.recordActivity(models.EventHostEntryToggled, entryActivityData(m.controller.CurrentProfile(), entry))
 	
 	// 刷新列表
-	m.hostEntryList.Refresh()
+	m.refreshHostEntryList()
 	
 	status := "启用"
-	if !m.currentHostEntry.Enabled {
+	if !entry.Enabled {
 		status = "禁用"
 	}
-	m.statusBar.SetText(fmt.Sprintf("Host条目 '%s' 已%s", m.currentHostEntry.Hostname, status))
+	m.statusBar.SetText(fmt.Sprintf("Host条目 '%s' 已%s", entry.Hostname, status))
+
+	// 激活的Profile立即更新hosts文件中的对应条目
+	if m.controller.CurrentProfile().IsActive {
+		m.patchActiveEntry(entry)
+	}
 }
 
-// onCleanupBackups 清理备份文件
+// patchActiveEntry 将激活Profile中单个条目的变化直接写入管理section，管理section不存在时回退为完整应用
+func (m *Manager) patchActiveEntry(entry *models.HostEntry) {
+	if err := m.controller.PatchEntry(entry); err != nil {
+		m.showErrorDialog("更新hosts文件失败", err)
+	}
+}
+
+// onCleanupBackups 按保留天数和最大备份数清理备份文件，里程碑备份不会被清理
 func (m *Manager) onCleanupBackups() {
-	message := "确定要清理过期的备份文件吗？\n\n将删除超过保留期限的备份文件。"
+	maxBackups, retentionDays := m.appConfig.Backup.MaxBackups, m.appConfig.Backup.RetentionDays
+	message := fmt.Sprintf("确定要清理过期的备份文件吗？\n\n将删除超过%d天的备份，并只保留最新的%d个备份。里程碑备份不会被删除。", retentionDays, maxBackups)
 	dialog.ShowConfirm("确认清理", message, func(confirmed bool) {
 		if !confirmed {
 			return
@@ -1227,33 +1573,82 @@ func (m *Manager) onCleanupBackups() {
 		go func() {
 			defer progressDialog.Hide()
 			
-			// TODO: 实现备份文件清理逻辑
-			// cleanedCount, err := m.hostManager.CleanupBackups()
+			removed, err := host.CleanupBackups(m.workspace.BackupDir, maxBackups, m.appConfig.Backup.Retention(), time.Now())
+			if err != nil {
+				m.showErrorDialog("清理备份文件失败", fmt.Errorf("已删除%d个备份: %w", len(removed), err))
+				return
+			}
 			
-			m.statusBar.SetText("备份文件清理完成")
-			dialog.ShowInformation("清理完成", "过期备份文件清理完成", m.window)
+			m.statusBar.SetText(fmt.Sprintf("备份文件清理完成，删除了%d个备份", len(removed)))
+			dialog.ShowInformation("清理完成", fmt.Sprintf("已删除%d个过期备份文件", len(removed)), m.window)
 		}()
 	}, m.window)
 }
 
+// onImportManualEdits 检测hosts文件管理section中的手动修改，并合并回激活的Profile
+func (m *Manager) onImportManualEdits() {
+	active, err := m.profileManager.GetActiveProfiles()
+	if err != nil || len(active) == 0 {
+		dialog.ShowInformation("提示", "当前没有激活的Profile", m.window)
+		return
+	}
+
+	// 同时激活多个Profile时与叠加后的内容比较，手动修改合并到优先级最高的Profile，重新应用后仍然生效
+	layered, _ := models.LayerProfiles(active)
+	activeProfile := active[len(active)-1]
+	drift, err := m.hostManager.DetectDrift(layered)
+	if err != nil {
+		m.showErrorDialog("检测失败", err)
+		return
+	}
+
+	imported := drift.Imported()
+	if len(imported) == 0 {
+		dialog.ShowInformation("无需导入", "hosts文件中没有需要导入的手动修改", m.window)
+		return
+	}
+
+	var details strings.Builder
+	for _, entry := range drift.Added {
+		details.WriteString(fmt.Sprintf("+ %s -> %s\n", entry.Hostname, entry.IP))
+	}
+	for _, change := range drift.Changed {
+		details.WriteString(fmt.Sprintf("~ %s: %s -> %s\n", change.Actual.Hostname, change.Expected.IP, change.Actual.IP))
+	}
+
+	message := fmt.Sprintf("检测到hosts文件中有%d处手动修改：\n\n%s\n确定要将这些修改合并到Profile '%s' 吗？",
+		len(imported), details.String(), activeProfile.Name)
+	dialog.ShowConfirm("导入手动修改", message, func(confirmed bool) {
+		if !confirmed {
+			return
+		}
+
+		entries := make([]*models.HostEntry, 0, len(imported))
+		for _, entry := range imported {
+			entries = append(entries, entry.ToModel())
+		}
+
+		merged, err := m.profileManager.MergeEntries(activeProfile.ID, entries)
+		if err != nil {
+			m.showErrorDialog("导入失败", err)
+			return
+		}
+
+		if err := m.loadInitialData(); err != nil {
+			m.showErrorDialog("刷新失败", err)
+			return
+		}
+
+		m.statusBar.SetText(fmt.Sprintf("已将%d个条目合并到Profile '%s'", merged, activeProfile.Name))
+	}, m.window)
+}
+
 // onFilterProfiles 过滤Profile显示
 func (m *Manager) onFilterProfiles(filter string) {
 	// TODO: 实现Profile过滤逻辑
 	m.statusBar.SetText(fmt.Sprintf("应用过滤器: %s", filter))
 }
 
-// onExpandAll 展开所有
-func (m *Manager) onExpandAll() {
-	// TODO: 实现展开所有逻辑
-	m.statusBar.SetText("已展开所有项目")
-}
-
-// onCollapseAll 折叠所有
-func (m *Manager) onCollapseAll() {
-	// TODO: 实现折叠所有逻辑
-	m.statusBar.SetText("已折叠所有项目")
-}
-
 // onShowShortcuts 显示快捷键
 func (m *Manager) onShowShortcuts() {
 	shortcuts := `快捷键列表：
@@ -1268,6 +1663,8 @@ Ctrl+Shift+N - 添加Host条目
 Ctrl+Shift+E - 编辑Host条目
 Ctrl+Shift+D - 删除Host条目
 Space - 启用/禁用Host条目
+Ctrl+Z - 撤销当前Profile的修改（macOS为Cmd+Z）
+Ctrl+Shift+Z - 重做（macOS为Cmd+Shift+Z）
 
 Ctrl+A - 应用Profile
 Ctrl+B - 备份Hosts文件
@@ -1290,13 +1687,34 @@ func (m *Manager) showErrorDialog(title string, err error) {
 	if err == nil {
 		return
 	}
+	m.recordError(title, err)
 	
 	// 根据错误类型显示不同的错误信息
 	errorMsg := err.Error()
 	detailedMsg := ""
 	
 	// 检查是否是已知的错误类型
+	var violationErr *rules.ViolationError
+	advice, structured := adviseError(err)
 	switch {
+	case structured:
+		errorMsg = advice.Text()
+		detailedMsg = fmt.Sprintf("错误代码: %s\n原始错误: %s", apperrors.GetAppError(err).Code(), err.Error())
+		if requestID := helper.RequestIDOf(err); requestID != "" {
+			detailedMsg += "\n请求ID: " + requestID
+		}
+	case errors.Is(err, models.ErrReadOnlyProfile):
+		errorMsg = "该Profile的条目由资产清单同步维护，不能手动修改。可复制Profile后编辑副本"
+		detailedMsg = "原始错误: " + err.Error()
+	case errors.Is(err, models.ErrDuplicateHostname):
+		errorMsg = "Profile中有重复的主机名，hosts文件中每个主机名只有一个条目生效。可点击条目列表上方的\"只保留最后一个\"清理"
+		detailedMsg = "原始错误: " + err.Error()
+	case errors.As(err, &violationErr):
+		errorMsg = "违反了团队的校验规则：\n" + ruleViolationText(violationErr.Violations)
+		detailedMsg = "规则文件由配置文件中的rules.path指定，默认为数据目录下的rules.yaml，修改后可在工具菜单中重新加载"
+	case errors.Is(err, host.ErrInsufficientSpace):
+		errorMsg = "备份目录所在磁盘空间不足，未创建备份，请清理旧备份或在设置中更换备份目录"
+		detailedMsg = "原始错误: " + err.Error()
 	case strings.Contains(errorMsg, "permission denied"):
 		errorMsg = "权限不足，请以管理员身份运行应用程序"
 		detailedMsg = "原始错误: " + err.Error()
@@ -1382,74 +1800,6 @@ func (m *Manager) handlePanic() {
 	}
 }
 
-// validateInput 验证用户输入
-func (m *Manager) validateInput(input string, fieldName string, required bool, maxLength int) error {
-	if required && strings.TrimSpace(input) == "" {
-		return fmt.Errorf("%s不能为空", fieldName)
-	}
-	
-	if maxLength > 0 && len(input) > maxLength {
-		return fmt.Errorf("%s长度不能超过%d个字符", fieldName, maxLength)
-	}
-	
-	return nil
-}
-
-// validateIPAddress 验证IP地址格式
-func (m *Manager) validateIPAddress(ip string) error {
-	if ip == "" {
-		return errors.New("IP地址不能为空")
-	}
-	
-	// 简单的IP地址格式验证
-	parts := strings.Split(ip, ".")
-	if len(parts) != 4 {
-		return errors.New("IP地址格式不正确")
-	}
-	
-	for _, part := range parts {
-		if part == "" {
-			return errors.New("IP地址格式不正确")
-		}
-		
-		// 检查是否为数字
-		for _, char := range part {
-			if char < '0' || char > '9' {
-				return errors.New("IP地址只能包含数字和点")
-			}
-		}
-		
-		// 检查范围
-		var num int
-		if _, err := fmt.Sscanf(part, "%d", &num); err != nil || num < 0 || num > 255 {
-			return errors.New("IP地址每段必须在0-255之间")
-		}
-	}
-	
-	return nil
-}
-
-// validateHostname 验证主机名格式
-func (m *Manager) validateHostname(hostname string) error {
-	if hostname == "" {
-		return errors.New("主机名不能为空")
-	}
-	
-	if len(hostname) > 253 {
-		return errors.New("主机名长度不能超过253个字符")
-	}
-	
-	// 检查是否包含非法字符
-	for _, char := range hostname {
-		if !((char >= 'a' && char <= 'z') || (char >= 'A' && char <= 'Z') || 
-			(char >= '0' && char <= '9') || char == '.' || char == '-' || char == '_') {
-			return errors.New("主机名只能包含字母、数字、点、连字符和下划线")
-		}
-	}
-	
-	return nil
-}
-
 // updateProfileSelector 更新Profile选择器
 func (m *Manager) updateProfileSelector() {
 	if m.profileSelector == nil {
@@ -1457,8 +1807,9 @@ func (m *Manager) updateProfileSelector() {
 	}
 	
 	// 构建Profile名称列表
-	profileNames := make([]string, 0, len(m.profiles))
-	for _, profile := range m.profiles {
+	profiles := m.controller.Profiles()
+	profileNames := make([]string, 0, len(profiles))
+	for _, profile := range profiles {
 		name := profile.Name
 		if profile.IsActive {
 			name += " (当前激活)"
@@ -1471,12 +1822,9 @@ func (m *Manager) updateProfileSelector() {
 	m.profileSelector.Refresh()
 	
 	// 设置当前选中项
-	if m.currentProfile != nil {
-		for i, profile := range m.profiles {
-			if profile.ID == m.currentProfile.ID {
-				m.profileSelector.SetSelectedIndex(i)
-				break
-			}
+	if current := m.controller.CurrentProfile(); current != nil {
+		if i := m.controller.ProfileIndex(current.ID); i >= 0 {
+			m.profileSelector.SetSelectedIndex(i)
 		}
 	}
 }
@@ -1491,13 +1839,7 @@ func (m *Manager) onQuickSwitchProfile(selectedName string) {
 	profileName := strings.Replace(selectedName, " (当前激活)", "", 1)
 	
 	// 查找对应的Profile
-	var targetProfile *models.Profile
-	for _, profile := range m.profiles {
-		if profile.Name == profileName {
-			targetProfile = profile
-			break
-		}
-	}
+	targetProfile := m.controller.FindProfileByName(profileName)
 	
 	if targetProfile == nil {
 		m.showErrorDialog("切换失败", errors.New("未找到指定的Profile"))
@@ -1515,19 +1857,13 @@ func (m *Manager) switchToProfile(profile *models.Profile) {
 	}
 	
 	// 设置当前Profile
-	m.currentProfile = profile
-	m.hostEntries = profile.Entries
-	
-	// 更新Profile列表选择
-	for i, p := range m.profiles {
-		if p.ID == profile.ID {
-			m.profileList.Select(i)
-			break
-		}
-	}
+	m.controller.SelectProfile(profile)
+	
+	// 更新Profile树选择
+	m.selectProfileNode(profile.ID)
 	
 	// 刷新Host条目列表
-	m.hostEntryList.Refresh()
+	m.refreshHostEntryList()
 	
 	// 更新状态栏
 	m.statusBar.SetText(fmt.Sprintf("已切换到Profile: %s (包含 %d 个Host条目)", profile.Name, len(profile.Entries)))
@@ -1538,7 +1874,8 @@ func (m *Manager) switchToProfile(profile *models.Profile) {
 
 // showQuickSwitchDialog 显示快速切换对话框
 func (m *Manager) showQuickSwitchDialog() {
-	if len(m.profiles) == 0 {
+	profiles := m.controller.Profiles()
+	if len(profiles) == 0 {
 		dialog.ShowInformation("提示", "没有可用的Profile", m.window)
 		return
 	}
@@ -1548,7 +1885,7 @@ func (m *Manager) showQuickSwitchDialog() {
 	// 创建Profile列表
 	profileList := widget.NewList(
 		func() int {
-			return len(m.profiles)
+			return len(profiles)
 		},
 		func() fyne.CanvasObject {
 			name := widget.NewLabel("")
@@ -1557,8 +1894,8 @@ func (m *Manager) showQuickSwitchDialog() {
 			return container.NewVBox(name, status)
 		},
 		func(id widget.ListItemID, obj fyne.CanvasObject) {
-			if id >= 0 && id < len(m.profiles) {
-				profile := m.profiles[id]
+			if id >= 0 && id < len(profiles) {
+				profile := profiles[id]
 				vbox := obj.(*fyne.Container)
 				
 				nameLabel := vbox.Objects[0].(*widget.Label)
@@ -1576,15 +1913,15 @@ func (m *Manager) showQuickSwitchDialog() {
 	
 	// 设置选择事件
 	profileList.OnSelected = func(id widget.ListItemID) {
-		if id >= 0 && id < len(m.profiles) {
-			selectedProfile = m.profiles[id]
+		if id >= 0 && id < len(profiles) {
+			selectedProfile = profiles[id]
 		}
 	}
 	
 	// 设置当前选中项
-	if m.currentProfile != nil {
-		for i, profile := range m.profiles {
-			if profile.ID == m.currentProfile.ID {
+	if current := m.controller.CurrentProfile(); current != nil {
+		for i, profile := range profiles {
+			if profile.ID == current.ID {
 				profileList.Select(i)
 				selectedProfile = profile
 				break
@@ -1609,10 +1946,16 @@ func (m *Manager) showQuickSwitchDialog() {
 	d.Show()
 }
 
-func (m *Manager) onImportProfile() { /* TODO: 实现导入Profile */ }
-func (m *Manager) onExportProfile() { /* TODO: 实现导出Profile */ }
-func (m *Manager) onRestoreHosts()  { /* TODO: 实现恢复Hosts */ }
-func (m *Manager) onValidateHosts() { /* TODO: 实现验证Hosts */ }
+// onValidateHosts 验证hosts文件并显示全部错误与警告
+func (m *Manager) onValidateHosts() {
+	result, err := m.hostManager.ValidateHostsFile()
+	if err != nil {
+		m.showErrorDialog("Hosts文件验证失败", err)
+		return
+	}
+	m.showValidationReport(result)
+}
+
 func (m *Manager) onCleanupHosts()  { /* TODO: 实现清理Hosts */ }
 func (m *Manager) onShowAbout()     { /* TODO: 实现显示关于 */ }
 func (m *Manager) onShowHelp()      { /* TODO: 实现显示帮助 */ }
@@ -1,115 +1,36 @@
 package ui
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
 	"testing"
 	"time"
 
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/test"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/flyhigher139/mhost/internal/activity"
+	"github.com/flyhigher139/mhost/internal/config"
+	"github.com/flyhigher139/mhost/internal/controller"
+	"github.com/flyhigher139/mhost/internal/diagnostics"
+	"github.com/flyhigher139/mhost/internal/doctor"
+	"github.com/flyhigher139/mhost/internal/fakes"
+	"github.com/flyhigher139/mhost/internal/healthcheck"
+	"github.com/flyhigher139/mhost/internal/helper"
+	"github.com/flyhigher139/mhost/internal/host"
+	"github.com/flyhigher139/mhost/internal/profile"
+	"github.com/flyhigher139/mhost/internal/rules"
+	apperrors "github.com/flyhigher139/mhost/pkg/errors"
+	"github.com/flyhigher139/mhost/pkg/hostsfile"
+	"github.com/flyhigher139/mhost/pkg/logger"
 	"github.com/flyhigher139/mhost/pkg/models"
 )
 
-// TestValidateIPAddress 测试IP地址验证
-func TestValidateIPAddress(t *testing.T) {
-	// 创建一个模拟的Manager用于测试验证方法
-	manager := &Manager{}
-
-	// 测试用例
-	testCases := []struct {
-		name     string
-		ip       string
-		expected bool
-	}{
-		{"Valid IP", "192.168.1.1", true},
-		{"Valid IP 2", "10.0.0.1", true},
-		{"Valid IP 3", "127.0.0.1", true},
-		{"Empty IP", "", false},
-		{"Invalid format 1", "192.168.1", false},
-		{"Invalid format 2", "192.168.1.1.1", false},
-		{"Invalid range", "256.1.1.1", false},
-		{"Invalid characters", "192.168.a.1", false},
-		{"Negative number", "192.168.-1.1", false},
-	}
-
-	for _, tc := range testCases {
-		t.Run(tc.name, func(t *testing.T) {
-			err := manager.validateIPAddress(tc.ip)
-			if tc.expected && err != nil {
-				t.Errorf("Expected valid IP %s, but got error: %v", tc.ip, err)
-			}
-			if !tc.expected && err == nil {
-				t.Errorf("Expected invalid IP %s, but got no error", tc.ip)
-			}
-		})
-	}
-}
-
-// TestValidateInput 测试输入验证
-func TestValidateInput(t *testing.T) {
-	// 创建一个模拟的Manager用于测试验证方法
-	manager := &Manager{}
-
-	// 测试用例
-	testCases := []struct {
-		name      string
-		input     string
-		fieldName string
-		required  bool
-		maxLength int
-		expected  bool
-	}{
-		{"Valid input", "test", "field", true, 10, true},
-		{"Empty required", "", "field", true, 10, false},
-		{"Empty not required", "", "field", false, 10, true},
-		{"Too long", "very long text", "field", false, 5, false},
-		{"Exact length", "12345", "field", false, 5, true},
-		{"Whitespace only required", "   ", "field", true, 10, false},
-	}
-
-	for _, tc := range testCases {
-		t.Run(tc.name, func(t *testing.T) {
-			err := manager.validateInput(tc.input, tc.fieldName, tc.required, tc.maxLength)
-			if tc.expected && err != nil {
-				t.Errorf("Expected valid input, but got error: %v", err)
-			}
-			if !tc.expected && err == nil {
-				t.Errorf("Expected invalid input, but got no error")
-			}
-		})
-	}
-}
-
-// TestValidateHostname 测试主机名验证
-func TestValidateHostname(t *testing.T) {
-	// 创建一个模拟的Manager用于测试验证方法
-	manager := &Manager{}
-
-	// 测试用例
-	testCases := []struct {
-		name     string
-		hostname string
-		expected bool
-	}{
-		{"Valid hostname", "example.com", true},
-		{"Valid subdomain", "www.example.com", true},
-		{"Valid localhost", "localhost", true},
-		{"Empty hostname", "", false},
-		{"Whitespace only", "   ", false},
-		{"Too long hostname", string(make([]byte, 300)), false},
-		{"Invalid characters", "test@example.com", false},
-	}
-
-	for _, tc := range testCases {
-		t.Run(tc.name, func(t *testing.T) {
-			err := manager.validateHostname(tc.hostname)
-			if tc.expected && err != nil {
-				t.Errorf("Expected valid hostname %s, but got error: %v", tc.hostname, err)
-			}
-			if !tc.expected && err == nil {
-				t.Errorf("Expected invalid hostname %s, but got no error", tc.hostname)
-			}
-		})
-	}
-}
-
 // TestHandlePanic 测试panic处理
 func TestHandlePanic(t *testing.T) {
 	// 跳过这个测试，因为handlePanic方法需要完整的UI环境
@@ -138,27 +59,28 @@ func createMockProfile() *models.Profile {
 // TestProfileOperations 测试Profile操作
 func TestProfileOperations(t *testing.T) {
 	// 创建一个模拟的Manager
-	manager := &Manager{}
+	manager := &Manager{controller: controller.New(nil, nil)}
 
 	// 创建测试Profile
 	mockProfile := createMockProfile()
 
 	// 测试设置当前Profile
-	manager.currentProfile = mockProfile
-	if manager.currentProfile == nil {
-		t.Error("Current profile should not be nil")
+	manager.controller.SelectProfile(mockProfile)
+	current := manager.controller.CurrentProfile()
+	if current == nil {
+		t.Fatal("Current profile should not be nil")
 	}
 
-	if manager.currentProfile.Name != "Test Profile" {
-		t.Errorf("Expected profile name 'Test Profile', got '%s'", manager.currentProfile.Name)
+	if current.Name != "Test Profile" {
+		t.Errorf("Expected profile name 'Test Profile', got '%s'", current.Name)
 	}
 
 	// 测试Host条目
-	if len(manager.currentProfile.Entries) != 1 {
-		t.Errorf("Expected 1 host entry, got %d", len(manager.currentProfile.Entries))
+	if len(manager.controller.Entries()) != 1 {
+		t.Errorf("Expected 1 host entry, got %d", len(manager.controller.Entries()))
 	}
 
-	entry := manager.currentProfile.Entries[0]
+	entry := manager.controller.EntryAt(0)
 	if entry.Hostname != "test.local" {
 		t.Errorf("Expected hostname 'test.local', got '%s'", entry.Hostname)
 	}
@@ -168,38 +90,849 @@ func TestProfileOperations(t *testing.T) {
 	}
 }
 
-// BenchmarkValidateIPAddress 性能测试IP地址验证
-func BenchmarkValidateIPAddress(b *testing.B) {
-	// 创建一个模拟的Manager
-	manager := &Manager{}
-	testIP := "192.168.1.1"
+// TestDebouncer 测试防抖器只执行最后一次触发
+func TestDebouncer(t *testing.T) {
+	d := newDebouncer(20 * time.Millisecond)
+
+	calls := make(chan int, 3)
+	for i := 1; i <= 3; i++ {
+		n := i
+		d.Trigger(func() { calls <- n })
+	}
+
+	select {
+	case n := <-calls:
+		if n != 3 {
+			t.Errorf("Expected last trigger to run, got %d", n)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Debounced function was not called")
+	}
+
+	select {
+	case n := <-calls:
+		t.Errorf("Expected only one call, got extra call %d", n)
+	case <-time.After(50 * time.Millisecond):
+	}
 
-	b.ResetTimer()
-	for i := 0; i < b.N; i++ {
-		manager.validateIPAddress(testIP)
+	// Stop取消尚未执行的触发
+	d.Trigger(func() { calls <- 4 })
+	d.Stop()
+	select {
+	case <-calls:
+		t.Error("Expected stopped trigger not to run")
+	case <-time.After(50 * time.Millisecond):
 	}
 }
 
-// BenchmarkValidateInput 性能测试输入验证
-func BenchmarkValidateInput(b *testing.B) {
-	// 创建一个模拟的Manager
-	manager := &Manager{}
-	testInput := "test input"
+// TestBuildEntryComment 测试编辑对话框组合结构化注释
+func TestBuildEntryComment(t *testing.T) {
+	existing := models.NewHostEntry("10.0.0.1", "api.test", "old note owner=alice team=web")
 
-	b.ResetTimer()
-	for i := 0; i < b.N; i++ {
-		manager.validateInput(testInput, "field", true, 100)
+	comment, err := buildEntryComment(existing, "new note", "bob", "OPS-1", "2025-07-01")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if comment != "new note owner=bob ticket=OPS-1 expires=2025-07-01 team=web" {
+		t.Errorf("Unexpected comment: %q", comment)
+	}
+
+	if _, err := buildEntryComment(nil, "", "", "", "07/01/2025"); err == nil {
+		t.Error("Expected error for invalid expires date")
 	}
 }
 
-// BenchmarkValidateHostname 性能测试主机名验证
-func BenchmarkValidateHostname(b *testing.B) {
-	// 创建一个模拟的Manager
-	manager := &Manager{}
-	testHostname := "www.example.com"
+// TestParseVariants 测试解析条目的环境变体
+func TestParseVariants(t *testing.T) {
+	variants, err := parseVariants("staging=10.0.0.2\n\n prod = 10.0.0.3 \n")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if formatVariants(variants, ",") != "prod=10.0.0.3,staging=10.0.0.2" {
+		t.Errorf("Unexpected variants: %v", variants)
+	}
+
+	if variants, err := parseVariants("  "); err != nil || variants != nil {
+		t.Errorf("Expected no variants, got %v (%v)", variants, err)
+	}
+	if _, err := parseVariants("staging=not-an-ip"); err == nil {
+		t.Error("Expected error for invalid IP")
+	}
+	if _, err := parseVariants("10.0.0.2"); err == nil {
+		t.Error("Expected error for missing environment")
+	}
+}
+
+// TestSetPresetIP 测试快捷预设设置生效IP与固定标记
+func TestSetPresetIP(t *testing.T) {
+	profile := models.NewProfile("test", "")
+	entry := models.NewHostEntry("10.0.0.1", "api.test", "api owner=alice")
+	entry.Enabled = false
+	profile.AddEntry(entry)
+
+	setPresetIP(profile, entry, "203.0.113.10", time.Date(2025, 7, 1, 12, 0, 0, 0, time.Local))
+	if entry.IP != "203.0.113.10" || !entry.Enabled {
+		t.Errorf("Unexpected entry: %s enabled=%v", entry.IP, entry.Enabled)
+	}
+	if entry.Comment != "api owner=alice pinned=2025-07-01" {
+		t.Errorf("Unexpected comment: %q", entry.Comment)
+	}
+
+	// 重定向到本机时清除固定标记
+	setPresetIP(profile, entry, localhostIP, time.Time{})
+	if entry.IP != localhostIP || entry.Comment != "api owner=alice" {
+		t.Errorf("Unexpected entry: %s %q", entry.IP, entry.Comment)
+	}
+
+	// 当前环境有变体时修改变体
+	entry.Variants = models.EntryVariants{"staging": "10.0.0.2"}
+	profile.Environment = "staging"
+	setPresetIP(profile, entry, blockIP, time.Time{})
+	if entry.Variants["staging"] != blockIP || entry.IP != localhostIP {
+		t.Errorf("Unexpected entry: %s %v", entry.IP, entry.Variants)
+	}
+
+	if findProfileEntry(profile, "API.test.") != entry || findProfileEntry(profile, "other.test") != nil {
+		t.Error("Unexpected findProfileEntry result")
+	}
+}
+
+// TestFormatPinChanges 测试固定条目刷新结果的格式化
+func TestFormatPinChanges(t *testing.T) {
+	api := models.NewHostEntry("203.0.113.11", "api.test", "pinned=2025-07-01")
+	web := models.NewHostEntry("198.51.100.9", "web.test", "pinned=2025-07-01")
+	changes := []diagnostics.PinChange{
+		{Entry: api, OldIP: api.IP, Result: diagnostics.PinResult{Hostname: "api.test", IPs: []string{"203.0.113.11"}}},
+		{Entry: web, OldIP: web.IP, Result: diagnostics.PinResult{Hostname: "web.test", IPs: []string{"198.51.100.1"}}, Changed: true},
+	}
+
+	expected := "= api.test: 203.0.113.11（未变化）\n~ web.test: 198.51.100.9 -> 198.51.100.1"
+	if report := formatPinChanges(changes); report != expected {
+		t.Errorf("Unexpected report: %q", report)
+	}
+
+	m := &Manager{appConfig: &models.AppConfig{}}
+	hostnames, invalid := m.splitPinHostnames("API.test, web.test\nbad!host")
+	if len(hostnames) != 2 || hostnames[0] != "api.test" || len(invalid) != 1 {
+		t.Errorf("Unexpected split: %v %v", hostnames, invalid)
+	}
+}
+
+// TestProfileDiffView 测试左右对照的差异视图默认只显示差异
+func TestProfileDiffView(t *testing.T) {
+	test.NewTempApp(t)
+
+	old := models.NewProfile("Old", "")
+	old.AddEntry(models.NewHostEntry("10.0.0.1", "api.test", ""))
+	old.AddEntry(models.NewHostEntry("10.0.0.2", "web.test", ""))
+	updated := old.Clone()
+	updated.Entries[1].IP = "10.0.0.9"
+	updated.Entries[1].Comment = "moved"
+	diff := profile.Diff(old, updated)
+
+	if text := formatDiffEntry(profile.DiffChanged, diff.Entries[1].New); text != "~ 10.0.0.9  web.test  # moved" {
+		t.Errorf("Unexpected entry text: %q", text)
+	}
+	if summary := diffSummary(diff); summary != "新增0个，删除0个，修改1个，相同1个" {
+		t.Errorf("Unexpected summary: %q", summary)
+	}
+
+	view := newProfileDiffView(diff, "Old", "New").(*fyne.Container)
+	list := view.Objects[0].(*widget.List)
+	if n := list.Length(); n != 1 {
+		t.Errorf("Expected only the changed entry, got %d rows", n)
+	}
+}
+
+// TestApplyPreviewView 测试应用预览的说明与逐行差异视图
+func TestApplyPreviewView(t *testing.T) {
+	test.NewTempApp(t)
+
+	p := models.NewProfile("Dev", "")
+	p.AddEntry(models.NewHostEntry("10.0.0.1", "api.test", ""))
+	before := []string{"127.0.0.1\tlocalhost"}
+	after := append(slices.Clone(before), "# mHost managed", "10.0.0.1\tapi.test")
+	preview := host.NewPreview(p, "/etc/hosts", before, after, hostsfile.FromModels(p.Entries),
+		models.LimitsConfig{MaxEntries: 2})
+
+	summary := applyPreviewSummary(preview, true)
+	for _, want := range []string{"'Dev'", "备份", "新增1个", "新增2行、删除0行", "共2个条目、54 B（当前1个条目、20 B），上限为2个条目"} {
+		if !strings.Contains(summary, want) {
+			t.Errorf("Summary %q does not contain %q", summary, want)
+		}
+	}
+	if warning := applyPreviewWarning(preview); warning != "" {
+		t.Errorf("Unexpected warning within limits: %q", warning)
+	}
+	if importance := lineDiffImportance("+ 10.0.0.1\tapi.test"); importance != widget.SuccessImportance {
+		t.Errorf("Unexpected importance for added line: %v", importance)
+	}
+
+	view := newApplyPreviewView(preview, false).(*fyne.Container)
+	list := view.Objects[0].(*widget.List)
+	if n := list.Length(); n != 3 {
+		t.Errorf("Expected 3 diff lines, got %d", n)
+	}
+
+	preview.Violations = preview.After.Violations(models.LimitsConfig{MaxEntries: 1})
+	if warning := applyPreviewWarning(preview); !strings.Contains(warning, "2 entries (limit 1)") {
+		t.Errorf("Expected an entry limit warning, got %q", warning)
+	}
+
+	preview.RuleViolations = []rules.Violation{{Rule: "ticket", Severity: hostsfile.SeverityWarning, Hostname: "api.test", IP: "10.0.0.1", Message: "missing ticket"}}
+	if warning := applyPreviewWarning(preview); !strings.Contains(warning, "2 entries (limit 1)") ||
+		!strings.Contains(warning, "- [警告] api.test (10.0.0.1): missing ticket [ticket]") {
+		t.Errorf("Expected limit and rule warnings, got %q", warning)
+	}
+}
+
+// TestUndoHistory 测试按Profile区分的撤销和重做
+func TestUndoHistory(t *testing.T) {
+	history := newUndoHistory()
+	before := models.NewProfile("Dev", "")
+	after := before.Clone()
+	after.AddEntry(models.NewHostEntry("10.0.0.1", "api.test", ""))
+	history.Push(before.ID, &editCommand{label: "add", before: before, after: after})
+
+	var restored *models.Profile
+	restore := func(p *models.Profile) error {
+		restored = p
+		return nil
+	}
+
+	// 其他Profile没有可撤销的修改
+	if cmd, err := history.Undo("other", restore); cmd != nil || err != nil {
+		t.Errorf("Unexpected undo for another profile: %v %v", cmd, err)
+	}
+
+	// 恢复失败时修改保留在撤销栈中
+	if _, err := history.Undo(before.ID, func(*models.Profile) error { return fmt.Errorf("disk full") }); err == nil {
+		t.Error("Expected the restore error")
+	}
+	cmd, err := history.Undo(before.ID, restore)
+	if err != nil || cmd == nil || cmd.label != "add" || restored != before {
+		t.Fatalf("Unexpected undo: %v %v", cmd, err)
+	}
+	if cmd, _ := history.Undo(before.ID, restore); cmd != nil {
+		t.Error("Undo stack should be empty")
+	}
+
+	cmd, err = history.Redo(before.ID, restore)
+	if err != nil || cmd == nil || restored != after {
+		t.Fatalf("Unexpected redo: %v %v", cmd, err)
+	}
+
+	// 新的修改清空重做栈
+	history.Undo(before.ID, restore)
+	history.Push(before.ID, &editCommand{label: "rename", before: before, after: after})
+	if cmd, _ := history.Redo(before.ID, restore); cmd != nil {
+		t.Error("Redo stack should be cleared by a new edit")
+	}
+
+	for i := 0; i < maxUndoSteps+5; i++ {
+		history.Push(before.ID, &editCommand{label: fmt.Sprint(i), before: before, after: after})
+	}
+	if n := len(history.stacks[before.ID].undo); n != maxUndoSteps {
+		t.Errorf("Expected %d undo steps, got %d", maxUndoSteps, n)
+	}
+
+	history.Forget(before.ID)
+	if cmd, _ := history.Undo(before.ID, restore); cmd != nil {
+		t.Error("Forgotten profile should have no history")
+	}
+}
+
+// TestDuplicateBanner 测试重复主机名提示的显示和只保留最后一个条目的清理
+func TestDuplicateBanner(t *testing.T) {
+	test.NewTempApp(t)
+
+	profiles := fakes.NewProfileManager(nil)
+	legacy := models.NewProfile("Legacy", "")
+	first := models.NewHostEntry("10.0.0.1", "api.test", "")
+	last := models.NewHostEntry("10.0.0.2", "api.test", "")
+	legacy.Entries = []*models.HostEntry{first, models.NewHostEntry("10.0.0.3", "web.test", ""), last}
+	profiles.Add(legacy, false)
+
+	m := &Manager{
+		profileManager: profiles,
+		controller:     controller.New(profiles, fakes.NewHostManager(nil, "127.0.0.1 localhost")),
+		statusBar:      widget.NewLabel(""),
+		hostEntryTable: widget.NewTable(func() (int, int) { return 0, 0 }, func() fyne.CanvasObject { return widget.NewLabel("") }, nil),
+	}
+	m.createDuplicateBanner()
+	if err := m.controller.Load(); err != nil {
+		t.Fatal(err)
+	}
+	m.controller.SelectProfile(m.controller.FindProfileByName("Legacy"))
+
+	m.refreshHostEntryList()
+	if !m.duplicates.container.Visible() || !strings.Contains(m.duplicates.label.Text, "api.test（2个条目）") {
+		t.Errorf("Expected duplicate banner, got visible=%v %q", m.duplicates.container.Visible(), m.duplicates.label.Text)
+	}
+	if !m.isDuplicateEntry(first) || !m.isDuplicateEntry(last) || m.isDuplicateEntry(legacy.Entries[1]) {
+		t.Error("Expected only the api.test entries to be marked as duplicates")
+	}
+
+	m.onRemoveDuplicateHostnames()
+	if m.duplicates.container.Visible() {
+		t.Error("Expected duplicate banner to be hidden after cleanup")
+	}
+	stored, err := profiles.GetProfile(legacy.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(stored.Entries) != 2 || stored.Entries[1].ID != last.ID {
+		t.Errorf("Expected the last api.test entry to be kept, got %+v", stored.Entries)
+	}
+}
+
+// TestFormatDoctorReport 测试诊断检查结果的格式化
+func TestFormatDoctorReport(t *testing.T) {
+	report := &doctor.Report{
+		GeneratedAt: time.Date(2025, 7, 1, 12, 0, 0, 0, time.Local),
+		Checks: []doctor.Check{
+			{Name: doctor.CheckHelper, Status: doctor.StatusWarning, Detail: "not installed", Fix: "install the helper"},
+			{Name: doctor.CheckConfig, Status: doctor.StatusOK, Detail: "valid"},
+		},
+	}
+
+	text := formatDoctorReport(report)
+	for _, want := range []string{"1项检查需要处理", "[警告] 特权Helper", "修复建议: install the helper", "[通过] 配置文件"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("Report %q does not contain %q", text, want)
+		}
+	}
+
+	report.Checks = report.Checks[1:]
+	if text := formatDoctorReport(report); !strings.Contains(text, "所有检查均已通过") {
+		t.Errorf("Expected all checks to pass: %q", text)
+	}
+}
+
+// TestFormatHealthReport 测试定期验证报告的格式化
+func TestFormatHealthReport(t *testing.T) {
+	report := &healthcheck.Report{
+		GeneratedAt: time.Date(2025, 7, 1, 12, 0, 0, 0, time.Local),
+		HostsPath:   "/etc/hosts",
+	}
+	if text := formatHealthReport(report); !strings.HasSuffix(text, "没有发现问题") {
+		t.Errorf("Unexpected report: %q", text)
+	}
+
+	report.Syntax = []hostsfile.Issue{{Line: 3, Severity: hostsfile.SeverityError, Message: "invalid IP address"}}
+	report.DriftProfile = "Dev"
+	report.Drift = &hostsfile.Drift{Removed: []hostsfile.Entry{{IP: "10.0.0.1", Hostname: "api.test", Enabled: true}}}
+	report.Backups = []healthcheck.BackupProblem{{Path: "/backups/hosts_backup_1.txt", Reason: "empty backup file"}}
+
+	text := formatHealthReport(report)
+	for _, expected := range []string{"语法错误 (1):", "line 3: invalid IP address", "与激活的Profile 'Dev' 不一致 (1):", "- api.test -> 10.0.0.1", "hosts_backup_1.txt: empty backup file"} {
+		if !strings.Contains(text, expected) {
+			t.Errorf("Expected %q in report:\n%s", expected, text)
+		}
+	}
+}
+
+// TestDashboardRows 测试健康面板状态的整理
+func TestDashboardRows(t *testing.T) {
+	now := time.Date(2025, 7, 1, 12, 0, 0, 0, time.Local)
+	active := models.NewProfile("Dev", "")
+	status := &dashboardStatus{
+		HelperInstalled: true,
+		HelperPath:      "/Library/PrivilegedHelperTools/mhost",
+		ActiveProfile:   active,
+		DriftCount:      2,
+		LastBackup:      &host.BackupFile{ModTime: now.Add(-3 * time.Hour)},
+		BackupCount:     4,
+		BackupFreeSpace: 10 << 30,
+		NextRuns:        map[string]time.Time{"定期验证": now.Add(30 * time.Minute)},
+		RecentErrors:    []recentError{{Title: "应用失败", Message: "permission denied", Time: now}},
+	}
+
+	unhealthy := map[string]string{}
+	values := map[string]string{}
+	for _, row := range dashboardRows(status, now) {
+		values[row.Label] = row.Value
+		if !row.Healthy {
+			unhealthy[row.Label] = row.Value
+		}
+	}
+
+	if len(unhealthy) != 2 || unhealthy["漂移"] == "" || unhealthy["最近错误"] == "" {
+		t.Errorf("Unexpected unhealthy rows: %v", unhealthy)
+	}
+	if values["最近备份"] != "2025-07-01 09:00（3小时前，共 4 个备份）" {
+		t.Errorf("Unexpected backup row: %q", values["最近备份"])
+	}
+	if values["备份空间"] != "剩余 10.0 GB" {
+		t.Errorf("Unexpected backup space row: %q", values["备份空间"])
+	}
+	if values["下次定期验证"] != "2025-07-01 12:30（30分钟后）" || values["下次条目分析"] != "未启用" {
+		t.Errorf("Unexpected scheduler rows: %v", values)
+	}
+
+	status.BackupFreeSpace = 20 << 20
+	for _, row := range dashboardRows(status, now) {
+		if row.Label == "备份空间" && (row.Healthy || !strings.Contains(row.Value, "20.0 MB")) {
+			t.Errorf("Expected low backup space warning, got %+v", row)
+		}
+	}
+}
+
+// TestCollectDashboardStatus 测试使用内存实现汇总激活Profile、漂移和待发送的Helper请求
+func TestCollectDashboardStatus(t *testing.T) {
+	profiles := fakes.NewProfileManager(nil)
+	hosts := fakes.NewHostManager(nil, "127.0.0.1 localhost")
+	client := fakes.NewXPCClient(hosts)
+	client.SetAvailable(false)
+
+	active, err := profiles.CreateProfile("Dev", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	active.AddEntry(models.NewHostEntry("10.0.0.1", "api.test", ""))
+	active.AddEntry(models.NewHostEntry("10.0.0.2", "web.test", ""))
+	if err := profiles.UpdateProfile(active); err != nil {
+		t.Fatal(err)
+	}
+
+	m := &Manager{
+		profileManager: profiles,
+		hostManager:    hosts,
+		helperClient:   client,
+		helperQueue:    helper.NewRequestQueue(client, 0),
+	}
+	if _, _, err := m.helperQueue.Submit(context.Background(), "backup_hosts", nil); err != nil {
+		t.Fatal(err)
+	}
+	m.recordError("应用失败", fmt.Errorf("permission denied"))
+
+	status := m.collectDashboardStatus()
+	if status.ActiveProfile == nil || status.ActiveProfile.Name != "Dev" {
+		t.Fatalf("Unexpected active profile: %+v", status.ActiveProfile)
+	}
+	if status.DriftCount != 2 || status.PendingRequests != 1 || len(status.RecentErrors) != 1 {
+		t.Errorf("Unexpected status: drift=%d pending=%d errors=%d", status.DriftCount, status.PendingRequests, len(status.RecentErrors))
+	}
+
+	if _, err := hosts.ApplyProfile(status.ActiveProfile); err != nil {
+		t.Fatal(err)
+	}
+	client.SetAvailable(true)
+	if _, err := m.helperQueue.Flush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	status = m.collectDashboardStatus()
+	if status.DriftCount != 0 || status.PendingRequests != 0 {
+		t.Errorf("Unexpected status after apply: drift=%d pending=%d", status.DriftCount, status.PendingRequests)
+	}
+	if operations := client.Operations(); len(operations) != 1 || operations[0] != "backup_hosts" {
+		t.Errorf("Unexpected helper requests: %v", operations)
+	}
+}
+
+// TestFormatBackupHistory 测试备份历史的汇总和行格式
+func TestFormatBackupHistory(t *testing.T) {
+	created := time.Date(2025, 7, 1, 9, 0, 0, 0, time.UTC)
+	history := host.NewBackupHistory([]host.BackupRecord{
+		{Name: "hosts_backup_auto_20250701_090000.txt", CreatedAt: created, Size: 2048, Automatic: true, Valid: true},
+		{Name: "hosts_backup_20250630_090000.txt", CreatedAt: created.Add(-24 * time.Hour), Size: 100, Error: "empty backup file"},
+	})
+
+	summary := formatBackupSummary(&history.Summary)
+	if !strings.Contains(summary, "共 2 个备份，2.1 KB（自动 1，手动 1），有效 1，无效 1") {
+		t.Errorf("Unexpected summary: %q", summary)
+	}
+	if !strings.Contains(summary, "2025-06-30 09:00 至 2025-07-01 09:00") {
+		t.Errorf("Unexpected time range: %q", summary)
+	}
+	if got := formatBackupSummary(&host.BackupSummary{}); got != "没有备份" {
+		t.Errorf("Unexpected empty summary: %q", got)
+	}
+
+	if got := formatBackupRecord(history.Records[0]); got != "2025-07-01 09:00:00  自动  2.0 KB  hosts_backup_auto_20250701_090000.txt  ✓" {
+		t.Errorf("Unexpected record: %q", got)
+	}
+	if got := formatBackupRecord(history.Records[1]); !strings.HasSuffix(got, "手动  100 B  hosts_backup_20250630_090000.txt  ✗ empty backup file") {
+		t.Errorf("Unexpected invalid record: %q", got)
+	}
+}
+
+// TestFormatHelperAccess 测试黑名单和速率限制客户端的显示格式
+func TestFormatHelperAccess(t *testing.T) {
+	now := time.Date(2025, 7, 1, 12, 0, 0, 0, time.UTC)
 
-	b.ResetTimer()
-	for i := 0; i < b.N; i++ {
-		manager.validateHostname(testHostname)
+	if got := formatBlockedClient(helper.BlockedClient{ClientID: "client_1", Expiry: now.Add(14*time.Minute + 50*time.Second)}, now); got != "client_1（15分钟后解封）" {
+		t.Errorf("Unexpected blocked client: %q", got)
+	}
+	if got := formatBlockedClient(helper.BlockedClient{ClientID: "client_2", Expiry: now.Add(10 * time.Second)}, now); got != "client_2（即将解封）" {
+		t.Errorf("Unexpected expiring client: %q", got)
+	}
+	if got := formatRateLimitedClient(helper.RateLimitedClient{ClientID: "client_3", Requests: 60, Limit: 60, Limited: true}); got != "client_3: 60/60（已限速）" {
+		t.Errorf("Unexpected rate limited client: %q", got)
+	}
+}
+
+// TestFormatPendingRequests 测试待发送请求列表的格式
+func TestFormatPendingRequests(t *testing.T) {
+	now := time.Date(2025, 7, 1, 12, 0, 0, 0, time.UTC)
+
+	if got := formatPendingRequests(nil, 0, now); got != "没有待发送的请求" {
+		t.Errorf("Unexpected empty queue: %q", got)
+	}
+
+	pending := []helper.QueuedRequest{
+		{Operation: "backup_hosts", QueuedAt: now.Add(-5 * time.Minute), LastError: "XPC client is not connected"},
+		{Operation: "get_status", QueuedAt: now.Add(-2 * time.Hour)},
+	}
+	expected := "Helper暂时不可用，2 个请求将在连接恢复后发送:\n" +
+		"  backup_hosts（5分钟前排队）: XPC client is not connected\n" +
+		"  get_status（2小时前排队）\n" +
+		"队列已满，已丢弃 3 个最早的请求"
+	if got := formatPendingRequests(pending, 3, now); got != expected {
+		t.Errorf("Unexpected pending requests:\n%s", got)
+	}
+}
+
+// TestAdviseError 测试按Helper错误代码和类型给出说明和重试建议
+func TestAdviseError(t *testing.T) {
+	if _, ok := adviseError(fmt.Errorf("plain error")); ok {
+		t.Error("Plain errors should not be advised")
+	}
+
+	tests := []struct {
+		name      string
+		err       error
+		message   string
+		retryable bool
+	}{
+		{"blacklisted", apperrors.NewError(apperrors.ErrCodeClientBlacklisted, apperrors.ErrorTypePermission, "client is blacklisted", nil), "请求过于频繁", false},
+		{"permission", apperrors.NewPermissionError(apperrors.ErrCodePermissionDenied, "denied"), "权限不足", false},
+		{"validation", apperrors.NewError(apperrors.ErrCodeXPCInvalidRequest, apperrors.ErrorTypeValidation, "missing entries", nil), "请求无效", false},
+		{"timeout", fmt.Errorf("write hosts failed: %w", apperrors.NewNetworkError(apperrors.ErrCodeXPCRequestTimeout, "timed out", nil)), "Helper响应超时", true},
+		{"unavailable", apperrors.NewNetworkError(apperrors.ErrCodeXPCServiceUnavailable, "unavailable", nil), "无法连接Helper", true},
+		{"filesystem", apperrors.NewFileSystemError(apperrors.ErrCodeFileWriteFailed, "write failed", nil), "Helper无法读写文件", false},
+		{"system", apperrors.NewError(apperrors.ErrCodeHelperOperationFailed, apperrors.ErrorTypeSystem, "boom", nil), "Helper执行操作时出错", true},
+	}
+	for _, tt := range tests {
+		advice, ok := adviseError(tt.err)
+		if !ok {
+			t.Errorf("%s: expected advice", tt.name)
+			continue
+		}
+		if !strings.Contains(advice.Message, tt.message) || advice.Retryable != tt.retryable {
+			t.Errorf("%s: unexpected advice %+v", tt.name, advice)
+		}
 	}
-}
\ No newline at end of file
+}
+
+// TestExpandedGroups 测试Profile树分组展开状态的保存，另一种分组方式的展开状态保持不变
+func TestExpandedGroups(t *testing.T) {
+	profile := models.NewProfile("API", "")
+	profile.Folder = "work/clients"
+	profiles := fakes.NewProfileManager(nil)
+	profiles.Add(profile, true)
+
+	configs := fakes.NewConfigManager(nil, nil)
+	m := &Manager{
+		configManager: configs,
+		appConfig:     configs.GetConfig(),
+		controller:    controller.New(profiles, nil),
+	}
+	m.appConfig.UI.ExpandedGroups = []string{"tag:dev"}
+	if err := m.controller.Load(); err != nil {
+		t.Fatal(err)
+	}
+
+	m.setGroupExpanded("folder:work", true)
+	m.setGroupExpanded("folder:work", true)
+	if got := configs.GetConfig().UI.ExpandedGroups; !slices.Equal(got, []string{"tag:dev", "folder:work"}) {
+		t.Errorf("Unexpected saved groups: %v", got)
+	}
+
+	m.setGroupExpanded("folder:work", false)
+	if got := m.otherExpandedGroups(); !slices.Equal(got, []string{"tag:dev"}) {
+		t.Errorf("Unexpected groups outside the folder tree: %v", got)
+	}
+	if got := configs.GetConfig().UI.ExpandedGroups; !slices.Equal(got, []string{"tag:dev"}) {
+		t.Errorf("Unexpected saved groups after collapse: %v", got)
+	}
+}
+
+func TestRecordActivity(t *testing.T) {
+	m := &Manager{activity: activity.NewFeed(filepath.Join(t.TempDir(), "activity.json"))}
+
+	profile := models.NewProfile("API", "")
+	m.recordActivity(models.EventProfileCreated, profileActivityData(profile))
+	drift := &healthcheck.Report{Drift: &hostsfile.Drift{Added: []hostsfile.Entry{{IP: "10.0.0.1", Hostname: "api.local", Enabled: true}}}}
+	m.recordDrift(drift)
+	m.recordDrift(drift)
+
+	events, err := m.activity.List(activityFilter(activity.CategoryAll, "今天", "", time.Now()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 2 {
+		t.Fatalf("Expected 2 activities, got %d", len(events))
+	}
+	if events[0].Type != models.EventSystemDriftDetected {
+		t.Errorf("Expected newest activity to be drift, got %s", events[0].Type)
+	}
+
+	events, err = m.activity.List(activityFilter(activity.CategoryProfile, "全部", "api", time.Now()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 1 || !strings.Contains(activityText(events[0]), "新建Profile") {
+		t.Errorf("Unexpected profile activities: %v", events)
+	}
+
+	events, err = m.activity.List(activityFilter(activity.CategoryAll, "昨天", "", time.Now()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 0 {
+		t.Errorf("Expected no activities yesterday, got %d", len(events))
+	}
+}
+
+func TestFormatRestoreCandidate(t *testing.T) {
+	created := time.Date(2024, 1, 2, 15, 4, 5, 0, time.Local)
+	valid := &host.RestoreCandidate{Backup: host.BackupFile{Name: host.AutoBackupFilePrefix + "x.txt", ModTime: created, Automatic: true}, Entries: 3}
+	if got := formatRestoreCandidate(valid, true); got != "★ 推荐  2024-01-02 15:04:05  自动  ✓ 3个条目" {
+		t.Errorf("Unexpected recommended candidate: %q", got)
+	}
+
+	invalid := &host.RestoreCandidate{
+		Backup: host.BackupFile{Name: host.BaselineProfileName, ModTime: created},
+		Errors: []hostsfile.Issue{{Line: 1, Message: "invalid IP"}},
+	}
+	if got := formatRestoreCandidate(invalid, false); got != "2024-01-02 15:04:05  系统初始  ✗ 1个语法错误" {
+		t.Errorf("Unexpected invalid candidate: %q", got)
+	}
+	if got := restoreCandidateDetails(invalid); !strings.Contains(got, "错误: line 1: invalid IP") {
+		t.Errorf("Unexpected candidate details: %q", got)
+	}
+}
+
+func TestFilterBackupRecords(t *testing.T) {
+	if got := parseBackupTags("dns， ops,,DNS "); !slices.Equal(got, []string{"dns", "ops"}) {
+		t.Errorf("Unexpected tags: %v", got)
+	}
+
+	day := func(d int) time.Time { return time.Date(2024, 5, d, 12, 0, 0, 0, time.Local) }
+	records := []host.BackupRecord{
+		{Name: "a", CreatedAt: day(1), BackupLabel: host.BackupLabel{Milestone: "before DNS migration", Tags: []string{"dns"}}},
+		{Name: "b", CreatedAt: day(2), Automatic: true, BackupLabel: host.BackupLabel{Tags: []string{"ops", "DNS"}}},
+		{Name: "c", CreatedAt: day(3)},
+	}
+	if got := backupTagOptions(records); !slices.Equal(got, []string{backupFilterAll, backupFilterMilestones, "dns", "ops"}) {
+		t.Errorf("Unexpected tag options: %v", got)
+	}
+	if got := filterBackupRecords(records, backupFilter{Tag: backupFilterMilestones}); len(got) != 1 || got[0].Name != "a" {
+		t.Errorf("Unexpected milestones: %v", got)
+	}
+	if got := filterBackupRecords(records, backupFilter{Tag: "dns"}); len(got) != 2 {
+		t.Errorf("Expected 2 backups tagged dns, got %d", len(got))
+	}
+	if got := filterBackupRecords(records, backupFilter{Tag: backupFilterAll, Kind: backupKindAll}); len(got) != 3 {
+		t.Errorf("Expected all backups, got %d", len(got))
+	}
+	if got := filterBackupRecords(records, backupFilter{Kind: backupKindManual}); len(got) != 2 || got[1].Name != "c" {
+		t.Errorf("Unexpected manual backups: %v", got)
+	}
+	if got := filterBackupRecords(records, backupFilter{Text: "migration"}); len(got) != 1 || got[0].Name != "a" {
+		t.Errorf("Unexpected search result: %v", got)
+	}
+
+	from, err := parseBackupDate("2024-05-02")
+	if err != nil {
+		t.Fatalf("Failed to parse date: %v", err)
+	}
+	if got := filterBackupRecords(records, backupFilter{From: from, To: from}); len(got) != 1 || got[0].Name != "b" {
+		t.Errorf("Expected only the backup from 2024-05-02, got %v", got)
+	}
+	if _, err := parseBackupDate("05/02/2024"); err == nil {
+		t.Error("Expected an error for an invalid date")
+	}
+	if got := formatBackupRecord(records[0]); !strings.HasSuffix(got, "★ before DNS migration  [dns]") {
+		t.Errorf("Unexpected milestone record: %q", got)
+	}
+}
+
+func TestRestoreOnQuitOptions(t *testing.T) {
+	for _, mode := range []string{"", models.RestoreOnQuitClear, models.RestoreOnQuitBaseline} {
+		if got := restoreOnQuitMode(restoreOnQuitLabel(mode)); got != mode {
+			t.Errorf("Expected mode %q to round-trip, got %q", mode, got)
+		}
+	}
+	if got := restoreOnQuitLabel("unknown"); got != restoreOnQuitOff {
+		t.Errorf("Expected unknown mode to map to %q, got %q", restoreOnQuitOff, got)
+	}
+}
+
+func TestImportConflict(t *testing.T) {
+	summaries := []*models.ProfileSummary{{ID: "1", Name: "Dev"}, {ID: "2", Name: "Dev (1)"}}
+	if got := findProfileByName(summaries, "Dev"); got == nil || got.ID != "1" {
+		t.Errorf("Expected to find profile Dev, got %v", got)
+	}
+	if got := findProfileByName(summaries, "Staging"); got != nil {
+		t.Errorf("Expected no conflict for Staging, got %v", got)
+	}
+	if got := importedProfileName(summaries, "Dev"); got != "Dev (2)" {
+		t.Errorf("Expected Dev (2), got %q", got)
+	}
+}
+
+func TestImportReportSummary(t *testing.T) {
+	report := profile.NewImportReport("/tmp/dev.hosts")
+	report.Profiles = []profile.ImportedProfile{
+		{Name: "Dev (1)", RequestedName: "Dev", Renamed: true, Entries: 2,
+			Skipped: []profile.SkippedEntry{{Hostname: "api.test", IP: "10.0.0.1"}}},
+		{Name: "Staging", RequestedName: "Staging", Replaced: true, Entries: 3},
+	}
+	report.Error = "disk full"
+
+	summary := importReportSummary(report)
+	for _, want := range []string{
+		"导入2个Profile，重命名1个，跳过1个重复的条目",
+		"Dev (1): 名称'Dev'已存在，已重命名（2个条目）",
+		"跳过重复的条目 10.0.0.1\tapi.test",
+		"Staging: 替换已有Profile的条目（3个条目）",
+		"导入中断: disk full",
+	} {
+		if !strings.Contains(summary, want) {
+			t.Errorf("Expected summary to contain %q, got:\n%s", want, summary)
+		}
+	}
+}
+
+func TestPastePreviewText(t *testing.T) {
+	pasted := []controller.PastedEntry{
+		{Line: 1, Text: "10.0.0.1 api.test # staging", Input: controller.EntryInput{IP: "10.0.0.1", Hostname: "api.test", Comment: "staging", Enabled: true}},
+		{Line: 2, Text: "# 10.0.0.2 spare.test", Input: controller.EntryInput{IP: "10.0.0.2", Hostname: "spare.test"}},
+		{Line: 3, Text: "bad", Err: errors.New("无法识别")},
+	}
+	want := "+ 10.0.0.1\tapi.test\t# staging\n" +
+		"+ # 10.0.0.2\tspare.test\n" +
+		"! 第3行 bad: 无法识别\n"
+	if got := pastePreviewText(pasted); got != want {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+}
+
+func TestFormatXPCStats(t *testing.T) {
+	if got := formatXPCStats(nil); !strings.Contains(got, "不提供") {
+		t.Errorf("Unexpected text for missing stats: %q", got)
+	}
+
+	stats := &helper.XPCServerStats{
+		TotalRequests:   5,
+		SuccessRequests: 4,
+		FailedRequests:  1,
+		P95Latency:      25,
+		Operations: map[string]*helper.OperationStats{
+			"write_hosts": {Count: 2, Failed: 1, P99Latency: 250},
+			"get_status":  {Count: 3},
+		},
+	}
+	got := formatXPCStats(stats)
+	if !strings.Contains(got, "p95 25.0ms") {
+		t.Errorf("Expected overall p95 in %q", got)
+	}
+	if strings.Index(got, "get_status") > strings.Index(got, "write_hosts") {
+		t.Errorf("Expected operations sorted by name: %q", got)
+	}
+	if !strings.Contains(got, "250.0") {
+		t.Errorf("Expected write_hosts p99 in %q", got)
+	}
+}
+
+func TestConfigReload(t *testing.T) {
+	test.NewTempApp(t)
+
+	configs := fakes.NewConfigManager(nil, nil)
+	m := &Manager{
+		configManager:  configs,
+		appConfig:      configs.GetConfig(),
+		controller:     controller.New(nil, nil),
+		activity:       activity.NewFeed(filepath.Join(t.TempDir(), "activity.json")),
+		logger:         logger.NewEnhancedLogger(logger.LogLevelInfo, false),
+		statusBar:      widget.NewLabel(""),
+		hostEntryTable: widget.NewTable(func() (int, int) { return 0, 0 }, func() fyne.CanvasObject { return widget.NewLabel("") }, func(widget.TableCellID, fyne.CanvasObject) {}),
+	}
+	m.startConfigWatch()
+	defer m.stopConfigWatch()
+
+	// 保存与当前相同的配置时不视为变化
+	if err := configs.SaveConfig(m.appConfig); err != nil {
+		t.Fatal(err)
+	}
+
+	// 外部修改日志级别后立即生效
+	updated := m.appConfig.Clone()
+	updated.Log.Level = "debug"
+	if err := configs.SaveConfig(updated); err != nil {
+		t.Fatal(err)
+	}
+	if m.logger.Level() != logger.LogLevelDebug {
+		t.Errorf("Expected debug log level, got %s", m.logger.Level())
+	}
+	if m.appConfig.Log.Level != "debug" {
+		t.Errorf("Expected reloaded config, got level %q", m.appConfig.Log.Level)
+	}
+
+	events, err := m.activity.List(activityFilter(activity.CategoryAll, "全部", "", time.Now()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 1 || events[0].Type != models.EventSystemConfigChanged {
+		t.Fatalf("Expected one config change activity, got %v", events)
+	}
+	if sections := fmt.Sprint(events[0].Data["sections"]); sections != fmt.Sprint([]string{config.SectionLog}) {
+		t.Errorf("Unexpected changed sections: %s", sections)
+	}
+}
+
+func TestNumberEntry(t *testing.T) {
+	test.NewTempApp(t)
+
+	e := newNumberEntry(30, 0, 365)
+	if v, err := e.Value(); err != nil || v != 30 {
+		t.Fatalf("Expected 30, got %d (%v)", v, err)
+	}
+
+	// 只接受数字输入
+	e.SetText("")
+	test.Type(e, "1a2")
+	if e.Text != "12" {
+		t.Errorf("Expected non-digits to be ignored, got %q", e.Text)
+	}
+
+	e.SetText("400")
+	if err := e.Validate(); err == nil {
+		t.Error("Expected out of range value to be invalid")
+	}
+	if _, err := e.Value(); err == nil {
+		t.Error("Expected Value to reject out of range value")
+	}
+
+	// 增减时限制在取值范围内
+	e.adjust(1)
+	if e.Text != "365" {
+		t.Errorf("Expected value clamped to 365, got %q", e.Text)
+	}
+	e.SetText("0")
+	e.TypedKey(&fyne.KeyEvent{Name: fyne.KeyDown})
+	if e.Text != "0" {
+		t.Errorf("Expected value clamped to 0, got %q", e.Text)
+	}
+	e.TypedKey(&fyne.KeyEvent{Name: fyne.KeyUp})
+	if v, err := e.Value(); err != nil || v != 1 {
+		t.Errorf("Expected 1 after increment, got %d (%v)", v, err)
+	}
+
+	if err := newNumberEntry(0, 1, unbounded).Validate(); err == nil {
+		t.Error("Expected value below the minimum to be invalid")
+	}
+}
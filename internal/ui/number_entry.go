@@ -0,0 +1,91 @@
+package ui
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+// unbounded numberEntry没有上限
+const unbounded = math.MaxInt32
+
+// numberEntry 带增减按钮的整数输入框，只接受数字输入，上下方向键调整数值。
+// 实现了fyne.Validatable，放在表单中时超出范围会实时显示错误提示
+type numberEntry struct {
+	widget.Entry
+	min, max, step int
+}
+
+// newNumberEntry 创建取值范围为[min, max]的整数输入框，max为unbounded时不限制上限
+func newNumberEntry(value, min, max int) *numberEntry {
+	e := &numberEntry{min: min, max: max, step: 1}
+	e.ExtendBaseWidget(e)
+	e.Validator = e.validate
+	e.ActionItem = container.NewHBox(
+		widget.NewButtonWithIcon("", theme.ContentRemoveIcon(), func() { e.adjust(-e.step) }),
+		widget.NewButtonWithIcon("", theme.ContentAddIcon(), func() { e.adjust(e.step) }),
+	)
+	e.SetText(strconv.Itoa(value))
+	return e
+}
+
+// validate 验证输入是范围内的整数
+func (e *numberEntry) validate(text string) error {
+	value, err := strconv.Atoi(strings.TrimSpace(text))
+	if err != nil {
+		return errors.New("必须是整数")
+	}
+	if value < e.min || value > e.max {
+		if e.max == unbounded {
+			return fmt.Errorf("不能小于%d", e.min)
+		}
+		return fmt.Errorf("必须在%d-%d之间", e.min, e.max)
+	}
+	return nil
+}
+
+// Value 获取输入的数值，输入无效时返回错误
+func (e *numberEntry) Value() (int, error) {
+	if err := e.validate(e.Text); err != nil {
+		return 0, err
+	}
+	return strconv.Atoi(strings.TrimSpace(e.Text))
+}
+
+// adjust 按delta调整数值并限制在取值范围内，当前输入无效时从下限开始
+func (e *numberEntry) adjust(delta int) {
+	value, err := strconv.Atoi(strings.TrimSpace(e.Text))
+	if err != nil {
+		value = e.min
+	} else {
+		value += delta
+	}
+	value = max(e.min, min(e.max, value))
+	e.SetText(strconv.Itoa(value))
+}
+
+// TypedRune 只接受数字，下限为负数时接受负号
+func (e *numberEntry) TypedRune(r rune) {
+	if (r >= '0' && r <= '9') || (r == '-' && e.min < 0) {
+		e.Entry.TypedRune(r)
+	}
+}
+
+// TypedKey 上下方向键增减数值
+func (e *numberEntry) TypedKey(key *fyne.KeyEvent) {
+	switch key.Name {
+	case fyne.KeyUp:
+		e.adjust(e.step)
+	case fyne.KeyDown:
+		e.adjust(-e.step)
+	default:
+		e.Entry.TypedKey(key)
+	}
+}
@@ -0,0 +1,115 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/flyhigher139/mhost/internal/controller"
+	"github.com/flyhigher139/mhost/pkg/hostsfile"
+	"github.com/flyhigher139/mhost/pkg/models"
+)
+
+// onPasteHostEntries 粘贴hosts格式的文本，预览后批量添加到当前Profile。剪贴板中有hosts条目时预先填入
+func (m *Manager) onPasteHostEntries() {
+	if m.editableProfile() == nil {
+		return
+	}
+
+	textEntry := widget.NewMultiLineEntry()
+	textEntry.SetPlaceHolder("10.0.0.1 api.local # staging\n10.0.0.2 web.local admin.local")
+	textEntry.SetMinRowsVisible(10)
+	if clipboard := m.window.Clipboard().Content(); len(hostsfile.ParseWithDisabled(hostsfile.SplitLines(clipboard))) > 0 {
+		textEntry.SetText(clipboard)
+	}
+
+	content := widget.NewForm(&widget.FormItem{
+		Text:     "hosts文本",
+		Widget:   textEntry,
+		HintText: "每行\"IP 主机名 [# 注释]\"，一行多个主机名时添加多个条目，被注释掉的条目以禁用状态添加",
+	})
+	d := dialog.NewCustomConfirm("粘贴Host条目", "预览", "取消", content, func(confirmed bool) {
+		if confirmed {
+			m.showPastePreview(textEntry.Text)
+		}
+	}, m.window)
+	d.Resize(fyne.NewSize(560, 0))
+	d.Show()
+}
+
+// pastePreviewText 粘贴预览的内容：将添加的条目以"+"开头，无法添加的条目以"!"开头并注明原因
+func pastePreviewText(pasted []controller.PastedEntry) string {
+	var b strings.Builder
+	for _, entry := range pasted {
+		if entry.Err != nil {
+			fmt.Fprintf(&b, "! 第%d行 %s: %v\n", entry.Line, entry.Text, entry.Err)
+			continue
+		}
+		line := hostsfile.RenderEntry(hostsfile.Entry{IP: entry.Input.IP, Hostname: entry.Input.Hostname, Comment: entry.Input.Comment})
+		if !entry.Input.Enabled {
+			line = "# " + line
+		}
+		fmt.Fprintf(&b, "+ %s\n", line)
+	}
+	return b.String()
+}
+
+// showPastePreview 解析粘贴的文本并显示将添加和跳过的条目，确认后添加
+func (m *Manager) showPastePreview(text string) {
+	pasted, err := m.controller.ParsePastedEntries(text, m.hostnameNormalizer())
+	if err != nil {
+		m.showErrorDialog("解析失败", err)
+		return
+	}
+	if len(pasted) == 0 {
+		dialog.ShowInformation("粘贴Host条目", "没有找到hosts条目", m.window)
+		return
+	}
+
+	valid := controller.ValidPastedEntries(pasted)
+	previewText := widget.NewMultiLineEntry()
+	previewText.SetText(pastePreviewText(pasted))
+	previewText.Disable()
+	previewText.SetMinRowsVisible(10)
+	summary := widget.NewLabel(fmt.Sprintf("将添加%d个条目，跳过%d个无法添加的条目", valid, len(pasted)-valid))
+	content := container.NewBorder(summary, nil, nil, nil, previewText)
+
+	if valid == 0 {
+		d := dialog.NewCustom("粘贴预览", "关闭", content, m.window)
+		d.Resize(fyne.NewSize(560, 0))
+		d.Show()
+		return
+	}
+
+	d := dialog.NewCustomConfirm("粘贴预览", fmt.Sprintf("添加%d个条目", valid), "取消", content, func(confirmed bool) {
+		if confirmed {
+			m.addPastedEntries(pasted)
+		}
+	}, m.window)
+	d.Resize(fyne.NewSize(560, 0))
+	d.Show()
+}
+
+// addPastedEntries 将粘贴的条目添加到当前Profile，可通过撤销一次性恢复
+func (m *Manager) addPastedEntries(pasted []controller.PastedEntry) {
+	current := m.controller.CurrentProfile()
+	recordEdit := m.beginEdit(current, fmt.Sprintf("粘贴%d个Host条目", controller.ValidPastedEntries(pasted)))
+	added, err := m.controller.AddPastedEntries(pasted)
+	if err != nil {
+		m.showErrorDialog("保存失败", err)
+		return
+	}
+	recordEdit()
+	for _, entry := range added {
+		m.recordActivity(models.EventHostEntryAdded, entryActivityData(current, entry))
+	}
+
+	m.refreshHostEntryList()
+	m.refreshEnvironmentSelect()
+	m.statusBar.SetText(fmt.Sprintf("已添加%d个Host条目", len(added)))
+	m.scheduleAutoApply()
+}
@@ -0,0 +1,215 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/flyhigher139/mhost/internal/controller"
+	"github.com/flyhigher139/mhost/internal/diagnostics"
+	"github.com/flyhigher139/mhost/pkg/models"
+)
+
+// onResolveAndPin 通过DNS解析一组主机名，并以解析结果创建固定条目
+func (m *Manager) onResolveAndPin() {
+	if m.editableProfile() == nil {
+		return
+	}
+
+	hostnamesEntry := widget.NewMultiLineEntry()
+	hostnamesEntry.SetPlaceHolder("每行一个主机名，也可以用空格或逗号分隔")
+	hostnamesEntry.SetMinRowsVisible(6)
+
+	items := []*widget.FormItem{widget.NewFormItem("主机名", hostnamesEntry)}
+	d := dialog.NewForm("解析并固定", "解析", "取消", items, func(ok bool) {
+		if !ok {
+			return
+		}
+
+		hostnames, invalid := m.splitPinHostnames(hostnamesEntry.Text)
+		if len(hostnames) == 0 && len(invalid) == 0 {
+			return
+		}
+
+		progressDialog := dialog.NewProgressInfinite("解析并固定", fmt.Sprintf("正在通过DNS解析 %d 个主机名，请稍候...", len(hostnames)), m.window)
+		progressDialog.Show()
+
+		go func() {
+			ctx, cancel := context.WithTimeout(context.Background(), diagnosticsTimeout)
+			defer cancel()
+
+			results := append(diagnostics.ResolveAll(ctx, diagnostics.NewDNSResolver(), hostnames), invalid...)
+			progressDialog.Hide()
+			m.confirmPins(results)
+		}()
+	}, m.window)
+	d.Resize(fyne.NewSize(450, 300))
+	d.Show()
+}
+
+// splitPinHostnames 拆分并规范化输入的主机名，无效的主机名作为失败结果返回
+func (m *Manager) splitPinHostnames(text string) ([]string, []diagnostics.PinResult) {
+	fields := strings.FieldsFunc(text, func(r rune) bool {
+		return r == ',' || r == ' ' || r == '\t' || r == '\n' || r == '\r'
+	})
+
+	var hostnames []string
+	var invalid []diagnostics.PinResult
+	for _, field := range fields {
+		hostname, err := m.hostnameNormalizer().Normalize(field)
+		if err == nil {
+			err = controller.ValidateHostname(hostname)
+		}
+		if err != nil {
+			invalid = append(invalid, diagnostics.PinResult{Hostname: field, Error: err.Error()})
+			continue
+		}
+		hostnames = append(hostnames, hostname)
+	}
+	return hostnames, invalid
+}
+
+// confirmPins 显示解析结果，确认后为解析成功的主机名创建或更新固定条目
+func (m *Manager) confirmPins(results []diagnostics.PinResult) {
+	var resolved []diagnostics.PinResult
+	for _, result := range results {
+		if result.Error == "" && result.IP() != "" {
+			resolved = append(resolved, result)
+		}
+	}
+
+	report := formatPinResults(results)
+	if len(resolved) == 0 {
+		dialog.ShowInformation("解析结果", report+"\n\n没有可固定的主机名", m.window)
+		return
+	}
+
+	message := fmt.Sprintf("%s\n\n是否为解析成功的 %d 个主机名创建固定条目？", report, len(resolved))
+	dialog.ShowConfirm("解析结果", message, func(ok bool) {
+		if !ok {
+			return
+		}
+
+		profile := m.controller.CurrentProfile()
+		now := time.Now()
+		entries := make([]*models.HostEntry, 0, len(resolved))
+		for _, result := range resolved {
+			entry := findProfileEntry(profile, result.Hostname)
+			if entry == nil {
+				entry = models.NewHostEntry("", result.Hostname, controller.EntryComment(profile, ""))
+				profile.AddEntry(entry)
+			}
+			setPresetIP(profile, entry, result.IP(), now)
+			entries = append(entries, entry)
+		}
+		m.savePinnedEntries(entries, fmt.Sprintf("已固定 %d 个主机名", len(entries)))
+	}, m.window)
+}
+
+// onRefreshPins 重新解析当前Profile中的固定条目，显示变化并确认后更新
+func (m *Manager) onRefreshPins() {
+	profile := m.editableProfile()
+	if profile == nil {
+		return
+	}
+
+	progressDialog := dialog.NewProgressInfinite("刷新固定条目", "正在重新解析固定条目，请稍候...", m.window)
+	progressDialog.Show()
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), diagnosticsTimeout)
+		defer cancel()
+
+		changes := diagnostics.RefreshPins(ctx, diagnostics.NewDNSResolver(), profile)
+		progressDialog.Hide()
+
+		if len(changes) == 0 {
+			dialog.ShowInformation("刷新固定条目", "当前Profile中没有固定条目", m.window)
+			return
+		}
+
+		var changed []diagnostics.PinChange
+		for _, change := range changes {
+			if change.Changed {
+				changed = append(changed, change)
+			}
+		}
+
+		report := formatPinChanges(changes)
+		if len(changed) == 0 {
+			dialog.ShowInformation("刷新固定条目", report+"\n\n所有固定条目的IP均未变化", m.window)
+			return
+		}
+
+		message := fmt.Sprintf("%s\n\n是否将 %d 个已变化的条目更新为新的解析结果？", report, len(changed))
+		dialog.ShowConfirm("刷新固定条目", message, func(ok bool) {
+			if !ok {
+				return
+			}
+
+			now := time.Now()
+			entries := make([]*models.HostEntry, 0, len(changed))
+			for _, change := range changed {
+				setPresetIP(profile, change.Entry, change.Result.IP(), now)
+				entries = append(entries, change.Entry)
+			}
+			m.savePinnedEntries(entries, fmt.Sprintf("已更新 %d 个固定条目", len(entries)))
+		}, m.window)
+	}()
+}
+
+// savePinnedEntries 保存固定条目的修改，激活的Profile立即更新hosts文件
+func (m *Manager) savePinnedEntries(entries []*models.HostEntry, status string) {
+	if err := m.controller.SaveCurrentProfile(); err != nil {
+		m.showErrorDialog("保存失败", err)
+		return
+	}
+
+	m.refreshHostEntryList()
+	m.statusBar.SetText(status)
+
+	if m.controller.CurrentProfile().IsActive {
+		for _, entry := range entries {
+			m.patchActiveEntry(entry)
+		}
+	}
+}
+
+// formatPinResults 格式化批量解析结果
+func formatPinResults(results []diagnostics.PinResult) string {
+	lines := make([]string, 0, len(results))
+	for _, result := range results {
+		if result.Error != "" {
+			lines = append(lines, fmt.Sprintf("✗ %s: %s", result.Hostname, result.Error))
+			continue
+		}
+		line := fmt.Sprintf("✓ %s -> %s", result.Hostname, result.IP())
+		if len(result.IPs) > 1 {
+			line += fmt.Sprintf("（共 %d 个地址: %s）", len(result.IPs), strings.Join(result.IPs, ", "))
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// formatPinChanges 格式化固定条目的重新解析结果
+func formatPinChanges(changes []diagnostics.PinChange) string {
+	lines := make([]string, 0, len(changes))
+	for _, change := range changes {
+		hostname := change.Entry.Hostname
+		switch {
+		case change.Result.Error != "":
+			lines = append(lines, fmt.Sprintf("✗ %s: 解析失败: %s", hostname, change.Result.Error))
+		case change.Changed:
+			lines = append(lines, fmt.Sprintf("~ %s: %s -> %s", hostname, change.OldIP, change.Result.IP()))
+		default:
+			lines = append(lines, fmt.Sprintf("= %s: %s（未变化）", hostname, change.OldIP))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
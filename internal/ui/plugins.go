@@ -0,0 +1,124 @@
+package ui
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/flyhigher139/mhost/internal/importer"
+	"github.com/flyhigher139/mhost/internal/plugins"
+)
+
+// loadPlugins 按当前配置发现导入导出插件。部分插件无效时仍使用其余插件，并返回无效插件的错误
+func (m *Manager) loadPlugins() error {
+	discovered, err := plugins.LoadConfig(m.appConfig.Plugins, m.workspace.DataDir)
+	m.plugins = discovered
+	return err
+}
+
+// onRescanPlugins 安装或修改插件后重新扫描插件目录
+func (m *Manager) onRescanPlugins() {
+	if err := m.loadPlugins(); err != nil {
+		m.showErrorDialog("部分插件无法加载", err)
+	}
+	if !m.appConfig.Plugins.Enabled {
+		m.statusBar.SetText("插件未启用，可在配置文件中设置plugins.enabled")
+		return
+	}
+	m.statusBar.SetText(fmt.Sprintf("已发现%d个插件", len(m.plugins)))
+}
+
+// onImportWithPlugin 选择导入插件和可选的输入文件，由插件解析后预览导入。
+// 从API拉取数据的插件不需要输入文件
+func (m *Manager) onImportWithPlugin() {
+	importers := plugins.Importers(m.plugins)
+	if len(importers) == 0 {
+		dialog.ShowInformation("提示", "没有可用的导入插件。请在配置文件中启用plugins，并将插件放到插件目录后在工具菜单中重新扫描", m.window)
+		return
+	}
+
+	names := make([]string, 0, len(importers))
+	for _, plugin := range importers {
+		names = append(names, plugin.Name)
+	}
+	descriptionLabel := widget.NewLabel("")
+	descriptionLabel.Wrapping = fyne.TextWrapWord
+	pluginSelect := widget.NewSelect(names, func(name string) {
+		if plugin := plugins.Find(importers, name); plugin != nil {
+			descriptionLabel.SetText(plugin.Description)
+		}
+	})
+	pluginSelect.SetSelected(names[0])
+
+	pathEntry := widget.NewEntry()
+	pathEntry.SetPlaceHolder("可选，插件从API获取数据时留空")
+	browseButton := widget.NewButton("选择...", func() {
+		dialog.NewFileOpen(func(reader fyne.URIReadCloser, err error) {
+			if err != nil || reader == nil {
+				return
+			}
+			reader.Close()
+			pathEntry.SetText(reader.URI().Path())
+		}, m.window).Show()
+	})
+
+	form := widget.NewForm(
+		&widget.FormItem{Text: "插件", Widget: pluginSelect},
+		&widget.FormItem{Text: "说明", Widget: descriptionLabel},
+		&widget.FormItem{Text: "输入文件", Widget: container.NewBorder(nil, nil, nil, browseButton, pathEntry)},
+	)
+
+	d := dialog.NewCustomConfirm("使用插件导入", "导入", "取消", form, func(confirmed bool) {
+		if !confirmed {
+			return
+		}
+		m.importWithPlugin(plugins.Find(importers, pluginSelect.Selected), strings.TrimSpace(pathEntry.Text))
+	}, m.window)
+	d.Resize(fyne.NewSize(520, 0))
+	d.Show()
+}
+
+// importWithPlugin 读取输入文件并在后台运行插件，解析成功后显示导入预览。
+// 有输入文件时以文件名命名Profile，否则以插件名命名
+func (m *Manager) importWithPlugin(plugin *plugins.Plugin, path string) {
+	if plugin == nil {
+		return
+	}
+
+	name, source := plugin.Name, "plugin:"+plugin.Name
+	var data []byte
+	if path != "" {
+		info, err := os.Stat(path)
+		if err != nil {
+			m.showErrorDialog("读取文件失败", err)
+			return
+		}
+		if info.Size() > importer.DefaultMaxFetchSize {
+			m.showErrorDialog("读取文件失败", importer.ErrResponseTooLarge)
+			return
+		}
+		if data, err = os.ReadFile(path); err != nil {
+			m.showErrorDialog("读取文件失败", err)
+			return
+		}
+		name, source = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path)), path
+	}
+
+	progressDialog := dialog.NewProgressInfinite("使用插件导入", fmt.Sprintf("正在运行插件%s，请稍候...", plugin.Name), m.window)
+	progressDialog.Show()
+	go func() {
+		profile, err := plugin.ParseProfile(data, name, m.hostnameNormalizer())
+		progressDialog.Hide()
+		if err != nil {
+			m.showErrorDialog("解析失败", err)
+			return
+		}
+		m.showImportPreview(profile, source)
+	}()
+}
@@ -0,0 +1,79 @@
+package ui
+
+import (
+	"fmt"
+	"os"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/storage"
+
+	"github.com/flyhigher139/mhost/internal/config"
+	"github.com/flyhigher139/mhost/pkg/models"
+)
+
+// onExportPreferences 将界面偏好单独导出为JSON文件，便于团队共享统一的界面设置
+func (m *Manager) onExportPreferences() {
+	preferences := config.ExtractPreferences(m.appConfig)
+	m.saveExport("mhost-preferences.json", func(path string) error {
+		file, err := os.Create(path)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		return config.WritePreferences(file, preferences)
+	})
+}
+
+// onImportPreferences 导入界面偏好，只替换界面和窗口布局设置，其他设置保持不变
+func (m *Manager) onImportPreferences() {
+	openDialog := dialog.NewFileOpen(func(reader fyne.URIReadCloser, err error) {
+		if err != nil {
+			m.showErrorDialog("打开文件失败", err)
+			return
+		}
+		if reader == nil {
+			return
+		}
+		defer reader.Close()
+
+		preferences, err := config.ReadPreferences(reader)
+		if err != nil {
+			m.showErrorDialog("读取界面偏好失败", err)
+			return
+		}
+
+		message := fmt.Sprintf("确定要导入 %s 中的界面偏好吗？\n\n主题、语言、Profile分组和窗口大小将被替换，备份、网络等其他设置保持不变。", reader.URI().Name())
+		dialog.ShowConfirm("导入界面偏好", message, func(confirmed bool) {
+			if confirmed {
+				m.applyPreferences(preferences)
+			}
+		}, m.window)
+	}, m.window)
+	openDialog.SetFilter(storage.NewExtensionFileFilter([]string{".json"}))
+	openDialog.Show()
+}
+
+// applyPreferences 保存导入的界面偏好并更新界面
+func (m *Manager) applyPreferences(preferences *config.Preferences) {
+	updated := m.appConfig.Clone()
+	preferences.ApplyTo(updated)
+	if err := m.configManager.SaveConfig(updated); err != nil {
+		m.showErrorDialog("保存设置失败", err)
+		return
+	}
+	previous := m.appConfig
+	m.appConfig = updated
+
+	m.recordConfigChange(m.applyConfigChanges(previous), configSourceSettings)
+	m.resizeWindow(m.appConfig.Window)
+
+	m.statusBar.SetText("界面偏好已导入，界面语言需要重启应用后生效")
+}
+
+// resizeWindow 按配置调整窗口大小
+func (m *Manager) resizeWindow(window models.WindowConfig) {
+	if window.Width > 0 && window.Height > 0 {
+		m.window.Resize(fyne.NewSize(float32(window.Width), float32(window.Height)))
+	}
+}
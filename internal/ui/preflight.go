@@ -0,0 +1,97 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/flyhigher139/mhost/internal/helper"
+	"github.com/flyhigher139/mhost/internal/host"
+)
+
+// checkApplyPreflight 应用Profile前检查hosts文件权限，无法写入时显示修复建议并返回false；
+// 用户选择以管理员权限继续时调用onElevate
+func (m *Manager) checkApplyPreflight(profileName string, onElevate func()) bool {
+	result := host.CheckPreflight(m.hostManager.GetHostsFilePath(), helper.IsInstalled(helper.DefaultServiceName))
+	if result.CanApply() {
+		return true
+	}
+
+	m.showPreflightDialog(result, profileName, onElevate)
+	return false
+}
+
+// showPreflightDialog 显示权限问题说明及针对性的修复方法
+func (m *Manager) showPreflightDialog(result *host.PreflightResult, profileName string, onElevate func()) {
+	var d dialog.Dialog
+	content := container.NewVBox(widget.NewLabel(preflightIssueText(result)))
+
+	// hosts文件存在但不可写且未安装Helper时，可通过管理员权限对话框直接写入
+	elevator := m.hostManager.GetElevator()
+	if result.Issue != host.PreflightHostsMissing && !result.HelperAvailable && elevator != nil && elevator.Available() {
+		content.Add(widget.NewButton("以管理员权限应用", func() {
+			d.Hide()
+			onElevate()
+		}))
+	}
+
+	for _, remediation := range result.Remediations {
+		title, command := remediationText(remediation, result.HostsPath, profileName)
+		content.Add(widget.NewLabelWithStyle(title, fyne.TextAlignLeading, fyne.TextStyle{Bold: true}))
+		if command != "" {
+			entry := widget.NewEntry()
+			entry.SetText(command)
+			copyButton := widget.NewButton("复制", func() {
+				m.window.Clipboard().SetContent(command)
+				m.statusBar.SetText("命令已复制到剪贴板")
+			})
+			content.Add(container.NewBorder(nil, nil, nil, copyButton, entry))
+		}
+	}
+
+	if result.Detail != "" {
+		detail := widget.NewLabel(result.Detail)
+		detail.Wrapping = fyne.TextWrapWord
+		content.Add(widget.NewAccordion(widget.NewAccordionItem("详细信息", detail)))
+	}
+
+	d = dialog.NewCustom("无法应用Profile", "关闭", content, m.window)
+	d.Resize(fyne.NewSize(520, 0))
+	d.Show()
+	m.statusBar.SetText("应用Profile前检查失败: hosts文件不可写")
+}
+
+// preflightIssueText 描述检查发现的问题
+func preflightIssueText(result *host.PreflightResult) string {
+	switch result.Issue {
+	case host.PreflightHostsMissing:
+		return fmt.Sprintf("hosts文件 %s 不存在。", result.HostsPath)
+	case host.PreflightDirNotWritable:
+		return fmt.Sprintf("当前用户无法在 %s 所在目录中写入文件，无法安全替换hosts文件。", result.HostsPath)
+	default:
+		return fmt.Sprintf("当前用户没有写入 %s 的权限。", result.HostsPath)
+	}
+}
+
+// remediationText 获取修复建议的标题及可复制的命令
+func remediationText(remediation host.Remediation, hostsPath, profileName string) (string, string) {
+	switch remediation {
+	case host.RemediationInstallHelper:
+		return fmt.Sprintf("安装特权Helper（推荐），安装路径: %s", helper.InstallPath(helper.DefaultServiceName)), ""
+	case host.RemediationRunWithSudo:
+		return "以管理员权限应用Profile:", fmt.Sprintf("sudo mhost apply %s", shellQuote(profileName))
+	case host.RemediationFixPermissions:
+		return "修复hosts文件权限:", fmt.Sprintf("sudo chown $(whoami) %s", shellQuote(hostsPath))
+	default:
+		return string(remediation), ""
+	}
+}
+
+// shellQuote 为命令参数添加单引号
+func shellQuote(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", `'\''`) + "'"
+}
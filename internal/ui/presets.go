@@ -0,0 +1,225 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/flyhigher139/mhost/internal/controller"
+	"github.com/flyhigher139/mhost/internal/diagnostics"
+	"github.com/flyhigher139/mhost/pkg/hostsfile"
+	"github.com/flyhigher139/mhost/pkg/models"
+)
+
+// 快捷预设使用的目标IP
+const (
+	localhostIP = "127.0.0.1"
+	blockIP     = "0.0.0.0"
+)
+
+// entryPreset 条目快捷预设
+type entryPreset int
+
+const (
+	presetRedirectLocalhost entryPreset = iota // 重定向到本机
+	presetBlock                                // 拦截
+	presetPinRealIP                            // 通过DNS解析恢复真实IP并固定
+)
+
+// entryPresets 菜单中显示的预设顺序
+var entryPresets = []entryPreset{presetRedirectLocalhost, presetBlock, presetPinRealIP}
+
+// Label 预设的显示名称
+func (p entryPreset) Label() string {
+	switch p {
+	case presetRedirectLocalhost:
+		return "重定向到 " + localhostIP
+	case presetBlock:
+		return "拦截 (" + blockIP + ")"
+	case presetPinRealIP:
+		return "恢复真实IP（DNS解析并固定）"
+	}
+	return ""
+}
+
+// entryRow 支持右键菜单的条目行
+type entryRow struct {
+	widget.BaseWidget
+	content     fyne.CanvasObject
+	onSecondary func(*fyne.PointEvent)
+}
+
+// newEntryRow 创建条目行
+func newEntryRow(content fyne.CanvasObject) *entryRow {
+	row := &entryRow{content: content}
+	row.ExtendBaseWidget(row)
+	return row
+}
+
+// CreateRenderer 实现fyne.Widget接口
+func (r *entryRow) CreateRenderer() fyne.WidgetRenderer {
+	return widget.NewSimpleRenderer(r.content)
+}
+
+// TappedSecondary 右键点击时显示菜单
+func (r *entryRow) TappedSecondary(event *fyne.PointEvent) {
+	if r.onSecondary != nil {
+		r.onSecondary(event)
+	}
+}
+
+// showEntryContextMenu 在条目上显示包含快捷预设的右键菜单
+func (m *Manager) showEntryContextMenu(entry *models.HostEntry, event *fyne.PointEvent) {
+	m.controller.SelectEntry(entry)
+
+	items := make([]*fyne.MenuItem, 0, len(entryPresets)+4)
+	for _, preset := range entryPresets {
+		items = append(items, fyne.NewMenuItem(preset.Label(), func() {
+			m.applyEntryPreset(entry, preset)
+		}))
+	}
+	items = append(items,
+		fyne.NewMenuItemSeparator(),
+		fyne.NewMenuItem("编辑Host条目", m.onEditHostEntry),
+		fyne.NewMenuItem("启用/禁用Host条目", m.onToggleHostEntry),
+		fyne.NewMenuItem("删除Host条目", m.onDeleteHostEntry),
+	)
+
+	widget.ShowPopUpMenuAtPosition(fyne.NewMenu("", items...), m.window.Canvas(), event.AbsolutePosition)
+}
+
+// onEntryPreset 菜单中的预设操作：作用于选中的条目，未选中时输入主机名新建或修改条目
+func (m *Manager) onEntryPreset(preset entryPreset) {
+	if m.editableProfile() == nil {
+		return
+	}
+	if entry := m.controller.CurrentEntry(); entry != nil {
+		m.applyEntryPreset(entry, preset)
+		return
+	}
+	m.showPresetHostnameDialog(preset)
+}
+
+// showPresetHostnameDialog 输入主机名，对已有条目应用预设，不存在时新建条目
+func (m *Manager) showPresetHostnameDialog(preset entryPreset) {
+	hostnameEntry := widget.NewEntry()
+	hostnameEntry.SetPlaceHolder("例如: ads.example.com")
+
+	items := []*widget.FormItem{widget.NewFormItem("主机名", hostnameEntry)}
+	dialog.ShowForm(preset.Label(), "确定", "取消", items, func(ok bool) {
+		if !ok {
+			return
+		}
+
+		hostname, err := m.hostnameNormalizer().Normalize(hostnameEntry.Text)
+		if err == nil {
+			err = controller.ValidateHostname(hostname)
+		}
+		if err != nil {
+			m.showErrorDialog("输入验证错误", err)
+			return
+		}
+
+		entry := findProfileEntry(m.controller.CurrentProfile(), hostname)
+		if entry == nil {
+			entry = models.NewHostEntry("", hostname, controller.EntryComment(m.controller.CurrentProfile(), ""))
+			entry.Enabled = true
+		}
+		m.applyEntryPreset(entry, preset)
+	}, m.window)
+}
+
+// findProfileEntry 按规范化后的主机名查找Profile中的第一个条目
+func findProfileEntry(profile *models.Profile, hostname string) *models.HostEntry {
+	hostname = hostsfile.NormalizeHostname(hostname)
+	for _, entry := range profile.Entries {
+		if entry != nil && hostsfile.NormalizeHostname(entry.Hostname) == hostname {
+			return entry
+		}
+	}
+	return nil
+}
+
+// applyEntryPreset 对条目应用预设并保存；恢复真实IP时先在后台进行DNS解析
+func (m *Manager) applyEntryPreset(entry *models.HostEntry, preset entryPreset) {
+	switch preset {
+	case presetRedirectLocalhost:
+		m.saveEntryPreset(entry, localhostIP, time.Time{})
+	case presetBlock:
+		m.saveEntryPreset(entry, blockIP, time.Time{})
+	case presetPinRealIP:
+		progressDialog := dialog.NewProgressInfinite("恢复真实IP", fmt.Sprintf("正在通过DNS解析 %s，请稍候...", entry.Hostname), m.window)
+		progressDialog.Show()
+
+		go func() {
+			ctx, cancel := context.WithTimeout(context.Background(), diagnosticsTimeout)
+			defer cancel()
+
+			ips, err := diagnostics.NewDNSResolver().LookupIP(ctx, entry.Hostname)
+			progressDialog.Hide()
+			if err != nil {
+				m.showErrorDialog("DNS解析失败", err)
+				return
+			}
+			m.saveEntryPreset(entry, ips[0], time.Now())
+		}()
+	}
+}
+
+// saveEntryPreset 保存预设结果，新条目加入当前Profile，激活的Profile立即更新hosts文件
+func (m *Manager) saveEntryPreset(entry *models.HostEntry, ip string, pinnedAt time.Time) {
+	profile := m.controller.CurrentProfile()
+	setPresetIP(profile, entry, ip, pinnedAt)
+
+	isNew := findProfileEntry(profile, entry.Hostname) != entry
+	if isNew {
+		profile.AddEntry(entry)
+	}
+
+	if err := m.controller.SaveCurrentProfile(); err != nil {
+		m.showErrorDialog("保存失败", err)
+		return
+	}
+
+	m.controller.SelectEntry(entry)
+	m.refreshHostEntryList()
+	m.statusBar.SetText(fmt.Sprintf("Host条目 '%s' 已指向 %s", entry.Hostname, ip))
+
+	if profile.IsActive {
+		m.patchActiveEntry(entry)
+	}
+}
+
+// setPresetIP 设置条目在当前环境下生效的IP并启用条目；pinnedAt非零时在注释中记录固定日期，否则清除
+func setPresetIP(profile *models.Profile, entry *models.HostEntry, ip string, pinnedAt time.Time) {
+	if _, ok := entry.Variants[profile.Environment]; ok && profile.Environment != "" {
+		entry.Variants[profile.Environment] = ip
+	} else {
+		entry.IP = ip
+	}
+	entry.Enabled = true
+
+	meta := hostsfile.ParseComment(entry.Comment)
+	pinned := ""
+	if !pinnedAt.IsZero() {
+		pinned = pinnedAt.Format(hostsfile.ExpiresLayout)
+	}
+	meta.Set(hostsfile.MetaPinned, pinned)
+	entry.Comment = meta.String()
+	entry.UpdatedAt = time.Now()
+}
+
+// presetMenuItems 编辑菜单中的预设菜单项
+func (m *Manager) presetMenuItems() []*fyne.MenuItem {
+	items := make([]*fyne.MenuItem, 0, len(entryPresets))
+	for _, preset := range entryPresets {
+		items = append(items, fyne.NewMenuItem(preset.Label(), func() {
+			m.onEntryPreset(preset)
+		}))
+	}
+	return items
+}
@@ -0,0 +1,181 @@
+package ui
+
+import (
+	"fmt"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/flyhigher139/mhost/internal/profile"
+	"github.com/flyhigher139/mhost/internal/snapshot"
+	"github.com/flyhigher139/mhost/pkg/models"
+)
+
+// diffMarkers 差异视图中各类差异的行首标记
+var diffMarkers = map[profile.DiffKind]string{
+	profile.DiffUnchanged: " ",
+	profile.DiffAdded:     "+",
+	profile.DiffRemoved:   "-",
+	profile.DiffChanged:   "~",
+}
+
+// diffImportance 差异视图中各类差异的颜色
+func diffImportance(kind profile.DiffKind) widget.Importance {
+	switch kind {
+	case profile.DiffAdded:
+		return widget.SuccessImportance
+	case profile.DiffRemoved:
+		return widget.DangerImportance
+	case profile.DiffChanged:
+		return widget.WarningImportance
+	default:
+		return widget.MediumImportance
+	}
+}
+
+// formatDiffEntry 差异视图中一侧的条目，条目不存在时为空
+func formatDiffEntry(kind profile.DiffKind, entry *models.HostEntry) string {
+	if entry == nil {
+		return ""
+	}
+	text := fmt.Sprintf("%s %s  %s", diffMarkers[kind], entry.IP, entry.Hostname)
+	if !entry.Enabled {
+		text += "  (已禁用)"
+	}
+	if len(entry.Variants) > 0 {
+		text += "  [" + formatVariants(entry.Variants, ", ") + "]"
+	}
+	if entry.Comment != "" {
+		text += "  # " + entry.Comment
+	}
+	return text
+}
+
+// diffSummary 差异的统计
+func diffSummary(diff *profile.ProfileDiff) string {
+	counts := make(map[profile.DiffKind]int)
+	for _, entry := range diff.Entries {
+		counts[entry.Kind]++
+	}
+	return fmt.Sprintf("新增%d个，删除%d个，修改%d个，相同%d个",
+		counts[profile.DiffAdded], counts[profile.DiffRemoved], counts[profile.DiffChanged], counts[profile.DiffUnchanged])
+}
+
+// newProfileDiffView 创建左右对照的差异视图，左侧为旧的一方，右侧为新的一方
+func newProfileDiffView(diff *profile.ProfileDiff, oldTitle, newTitle string) fyne.CanvasObject {
+	var rows []profile.EntryDiff
+	showRows := func(onlyChanges bool) {
+		rows = rows[:0]
+		for _, entry := range diff.Entries {
+			if !onlyChanges || entry.Kind != profile.DiffUnchanged {
+				rows = append(rows, entry)
+			}
+		}
+	}
+	showRows(diff.HasChanges())
+
+	list := widget.NewList(
+		func() int { return len(rows) },
+		func() fyne.CanvasObject {
+			return container.NewGridWithColumns(2, widget.NewLabel(""), widget.NewLabel(""))
+		},
+		func(id widget.ListItemID, obj fyne.CanvasObject) {
+			row := rows[id]
+			cells := obj.(*fyne.Container).Objects
+			for i, entry := range []*models.HostEntry{row.Old, row.New} {
+				label := cells[i].(*widget.Label)
+				label.Importance = diffImportance(row.Kind)
+				label.SetText(formatDiffEntry(row.Kind, entry))
+			}
+		},
+	)
+
+	onlyChanges := widget.NewCheck("只显示差异", func(checked bool) {
+		showRows(checked)
+		list.Refresh()
+	})
+	onlyChanges.SetChecked(diff.HasChanges())
+
+	headers := container.NewGridWithColumns(2,
+		widget.NewLabelWithStyle(oldTitle, fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
+		widget.NewLabelWithStyle(newTitle, fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
+	)
+	top := container.NewVBox(container.NewBorder(nil, nil, nil, onlyChanges, widget.NewLabel(diffSummary(diff))), headers)
+	return container.NewBorder(top, nil, nil, nil, list)
+}
+
+// showProfileDiff 在对话框中显示两个Profile的差异，a为旧的一方
+func (m *Manager) showProfileDiff(title string, a, b *models.Profile, oldTitle, newTitle string) {
+	view := newProfileDiffView(profile.Diff(a, b), oldTitle, newTitle)
+	d := dialog.NewCustom(title, "关闭", view, m.window)
+	d.Resize(fyne.NewSize(900, 500))
+	d.Show()
+}
+
+// onCompareProfiles 将当前Profile与另一个Profile对比
+func (m *Manager) onCompareProfiles() {
+	current := m.controller.CurrentProfile()
+	if current == nil {
+		dialog.ShowInformation("提示", "请先选择要对比的Profile", m.window)
+		return
+	}
+
+	var names []string
+	byName := make(map[string]*models.Profile)
+	for _, p := range m.controller.Profiles() {
+		if p.ID != current.ID {
+			names = append(names, p.Name)
+			byName[p.Name] = p
+		}
+	}
+	if len(names) == 0 {
+		dialog.ShowInformation("提示", "没有可以对比的其他Profile", m.window)
+		return
+	}
+
+	otherSelect := widget.NewSelect(names, nil)
+	otherSelect.SetSelectedIndex(0)
+	dialog.ShowForm("对比Profile", "对比", "取消", []*widget.FormItem{
+		{Text: "对比对象", Widget: otherSelect, HintText: fmt.Sprintf("左侧为 '%s'，右侧为选中的Profile", current.Name)},
+	}, func(confirmed bool) {
+		if !confirmed {
+			return
+		}
+		other := byName[otherSelect.Selected]
+		m.showProfileDiff("对比Profile", current, other, current.Name, other.Name)
+	}, m.window)
+}
+
+// compareSnapshot 将快照中保存的当前Profile与现在的版本对比，按ID查找，找不到时按名称查找
+func (m *Manager) compareSnapshot(s snapshot.Snapshot) {
+	current := m.controller.CurrentProfile()
+	if current == nil {
+		dialog.ShowInformation("提示", "请先选择要对比的Profile", m.window)
+		return
+	}
+	bundle, err := snapshot.Load(s.Path)
+	if err != nil {
+		m.showErrorDialog("读取快照失败", err)
+		return
+	}
+
+	var saved *models.Profile
+	for _, p := range bundle.Profiles {
+		if p.ID == current.ID {
+			saved = p
+			break
+		}
+		if saved == nil && p.Name == current.Name {
+			saved = p
+		}
+	}
+	if saved == nil {
+		dialog.ShowInformation("提示", fmt.Sprintf("快照中没有Profile '%s'", current.Name), m.window)
+		return
+	}
+
+	stamp := s.CreatedAt.Format("2006-01-02 15:04:05")
+	m.showProfileDiff("与快照对比", saved, current, fmt.Sprintf("%s（快照 %s）", saved.Name, stamp), current.Name+"（当前）")
+}
@@ -0,0 +1,131 @@
+package ui
+
+import (
+	"fmt"
+
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/flyhigher139/mhost/internal/controller"
+	"github.com/flyhigher139/mhost/pkg/models"
+)
+
+// folderOptions 编辑Profile时可选的已有文件夹
+func (m *Manager) folderOptions() []string {
+	groups, err := m.profileManager.ListGroups()
+	if err != nil {
+		return nil
+	}
+	options := make([]string, 0, len(groups))
+	for _, group := range groups {
+		options = append(options, group.Path)
+	}
+	return options
+}
+
+// onNewGroup 在选中的文件夹下新建文件夹，没有选中文件夹时创建在顶层
+func (m *Manager) onNewGroup() {
+	parent := m.selectedFolder
+	nameEntry := widget.NewEntry()
+	nameEntry.SetPlaceHolder("例如: clients")
+
+	hint := "创建在顶层"
+	if parent != "" {
+		hint = fmt.Sprintf("创建在 '%s' 下", parent)
+	}
+	dialog.ShowForm("新建文件夹", "创建", "取消", []*widget.FormItem{
+		{Text: "名称", Widget: nameEntry, HintText: hint},
+	}, func(confirmed bool) {
+		if !confirmed {
+			return
+		}
+		group, err := m.controller.CreateGroup(parent, nameEntry.Text)
+		if err != nil {
+			m.showErrorDialog("新建文件夹失败", err)
+			return
+		}
+		m.recordGroupChange("create", group.Path, "")
+		m.refreshProfileList()
+		if parent != "" {
+			m.profileList.OpenBranch(controller.FolderNode(parent))
+		}
+		m.statusBar.SetText(fmt.Sprintf("已新建文件夹: %s", group.Path))
+	}, m.window)
+}
+
+// onRenameGroup 重命名选中的文件夹，其中的Profile一起移动
+func (m *Manager) onRenameGroup() {
+	path := m.selectedFolder
+	if path == "" {
+		dialog.ShowInformation("提示", "请先在Profile列表中选择要重命名的文件夹", m.window)
+		return
+	}
+
+	group := &models.ProfileGroup{Path: path}
+	nameEntry := widget.NewEntry()
+	nameEntry.SetText(group.Name())
+	dialog.ShowForm("重命名文件夹", "确定", "取消", []*widget.FormItem{
+		{Text: "名称", Widget: nameEntry},
+	}, func(confirmed bool) {
+		if !confirmed {
+			return
+		}
+		newPath, err := m.controller.RenameGroup(path, nameEntry.Text)
+		if err != nil {
+			m.showErrorDialog("重命名文件夹失败", err)
+			return
+		}
+		if newPath == path {
+			return
+		}
+		m.recordGroupChange("rename", path, newPath)
+		m.moveExpandedGroups(path, newPath)
+		m.selectedFolder = ""
+		m.refreshProfileList()
+		m.statusBar.SetText(fmt.Sprintf("文件夹 '%s' 已重命名为 '%s'", path, newPath))
+	}, m.window)
+}
+
+// onDeleteGroup 删除选中的文件夹，其中的Profile和下级文件夹移到上级文件夹
+func (m *Manager) onDeleteGroup() {
+	path := m.selectedFolder
+	if path == "" {
+		dialog.ShowInformation("提示", "请先在Profile列表中选择要删除的文件夹", m.window)
+		return
+	}
+
+	count := m.controller.Tree().Count(controller.FolderNode(path))
+	message := fmt.Sprintf("确定要删除文件夹 '%s' 吗？", path)
+	if count > 0 {
+		message += fmt.Sprintf("\n\n其中的%d个Profile和下级文件夹将移到上级文件夹，不会被删除。", count)
+	}
+	dialog.ShowConfirm("确认删除", message, func(confirmed bool) {
+		if !confirmed {
+			return
+		}
+		if err := m.controller.DeleteGroup(path); err != nil {
+			m.showErrorDialog("删除文件夹失败", err)
+			return
+		}
+		m.recordGroupChange("delete", path, "")
+		m.moveExpandedGroups(path, models.ParentFolder(path))
+		m.selectedFolder = ""
+		m.refreshProfileList()
+		m.statusBar.SetText(fmt.Sprintf("已删除文件夹: %s", path))
+	}, m.window)
+}
+
+// moveExpandedGroups 文件夹移动后更新保存的展开状态
+func (m *Manager) moveExpandedGroups(from, to string) {
+	m.saveExpandedGroups(controller.MoveFolderNodes(m.appConfig.UI.ExpandedGroups, from, to))
+	m.restoreExpandedGroups()
+}
+
+// recordGroupChange 记录文件夹的变化到最近活动
+func (m *Manager) recordGroupChange(operation, path, newPath string) {
+	data := map[string]interface{}{"operation": operation, "folder": path}
+	if newPath != "" {
+		data["new_folder"] = newPath
+	}
+	m.recordActivity(models.EventProfileGroupChanged, data)
+}
@@ -0,0 +1,208 @@
+package ui
+
+import (
+	"fmt"
+	"slices"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/flyhigher139/mhost/pkg/models"
+)
+
+// createProfileList 创建按文件夹或标签分组的Profile树
+func (m *Manager) createProfileList() {
+	m.profileList = widget.NewTree(
+		func(id widget.TreeNodeID) []widget.TreeNodeID {
+			return m.controller.Tree().Children(id)
+		},
+		func(id widget.TreeNodeID) bool {
+			return m.controller.Tree().IsBranch(id)
+		},
+		func(branch bool) fyne.CanvasObject {
+			if branch {
+				return container.NewHBox(widget.NewIcon(theme.FolderIcon()), widget.NewLabel(""))
+			}
+
+			// 创建Profile条目的布局
+			name := widget.NewLabel("")
+			name.TextStyle.Bold = true
+			desc := widget.NewLabel("")
+			desc.TextStyle.Italic = true
+			status := widget.NewLabel("")
+
+			// 创建状态指示器
+			statusIcon := widget.NewIcon(nil)
+
+			// 创建水平布局的状态行，标签显示在状态后面
+			statusRow := container.NewHBox(
+				statusIcon,
+				status,
+				container.NewHBox(),
+				layout.NewSpacer(),
+			)
+
+			return container.NewVBox(
+				name,
+				desc,
+				statusRow,
+			)
+		},
+		func(id widget.TreeNodeID, branch bool, obj fyne.CanvasObject) {
+			tree := m.controller.Tree()
+			if branch {
+				label := obj.(*fyne.Container).Objects[1].(*widget.Label)
+				label.SetText(fmt.Sprintf("%s (%d)", tree.Label(id), tree.Count(id)))
+				return
+			}
+
+			profile := tree.Profile(id)
+			if profile == nil {
+				return
+			}
+			vbox := obj.(*fyne.Container)
+
+			// 更新名称
+			nameLabel := vbox.Objects[0].(*widget.Label)
+			nameLabel.SetText(profile.Name)
+
+			// 更新描述
+			descLabel := vbox.Objects[1].(*widget.Label)
+			if profile.Description != "" {
+				descLabel.SetText(profile.Description)
+			} else {
+				descLabel.SetText("无描述")
+			}
+
+			// 更新状态行
+			statusRow := vbox.Objects[2].(*fyne.Container)
+			statusIcon := statusRow.Objects[0].(*widget.Icon)
+			statusLabel := statusRow.Objects[1].(*widget.Label)
+
+			statusText := fmt.Sprintf("条目数: %d", len(profile.Entries))
+			if profile.IsActive {
+				statusText += " (当前激活)"
+				statusIcon.SetResource(theme.ConfirmIcon())
+			} else {
+				statusIcon.SetResource(theme.RadioButtonIcon())
+			}
+			statusLabel.SetText(statusText)
+
+			tagsBox := statusRow.Objects[2].(*fyne.Container)
+			tagsBox.Objects = m.tagChips(profile.Tags)
+			tagsBox.Refresh()
+		},
+	)
+
+	// 设置选择事件处理，记录分组的展开状态
+	m.profileList.OnSelected = m.onProfileSelected
+	m.profileList.OnBranchOpened = func(id widget.TreeNodeID) {
+		m.setGroupExpanded(id, true)
+	}
+	m.profileList.OnBranchClosed = func(id widget.TreeNodeID) {
+		m.setGroupExpanded(id, false)
+	}
+}
+
+// setGroupExpanded 记录分组的展开状态并保存到配置
+func (m *Manager) setGroupExpanded(id widget.TreeNodeID, expanded bool) {
+	groups := m.appConfig.UI.ExpandedGroups
+	if slices.Contains(groups, id) == expanded {
+		return
+	}
+
+	if expanded {
+		groups = append(groups, id)
+	} else {
+		groups = slices.DeleteFunc(groups, func(group string) bool { return group == id })
+	}
+	m.saveExpandedGroups(groups)
+}
+
+// saveExpandedGroups 保存展开的分组，下次启动时恢复
+func (m *Manager) saveExpandedGroups(groups []string) {
+	m.appConfig.UI.ExpandedGroups = groups
+	if err := m.configManager.SaveConfig(m.appConfig); err != nil {
+		m.statusBar.SetText(fmt.Sprintf("保存分组展开状态失败: %v", err))
+	}
+}
+
+// restoreExpandedGroups 按配置展开分组，分组名称不再存在时忽略
+func (m *Manager) restoreExpandedGroups() {
+	tree := m.controller.Tree()
+	m.profileList.CloseAllBranches()
+	for _, id := range m.appConfig.UI.ExpandedGroups {
+		if id != "" && tree.IsBranch(id) {
+			m.profileList.OpenBranch(id)
+		}
+	}
+}
+
+// selectProfileNode 在Profile树中选中Profile，并展开其所在的分组
+func (m *Manager) selectProfileNode(profileID string) {
+	tree := m.controller.Tree()
+	node, ok := tree.NodeFor(profileID)
+	if !ok {
+		return
+	}
+	for _, group := range tree.Ancestors(node) {
+		m.profileList.OpenBranch(group)
+	}
+	m.profileList.Select(node)
+	m.profileList.ScrollTo(node)
+}
+
+// otherExpandedGroups 展开状态中不属于当前Profile树的分组，例如另一种分组方式的分组
+func (m *Manager) otherExpandedGroups() []string {
+	tree := m.controller.Tree()
+	return slices.DeleteFunc(slices.Clone(m.appConfig.UI.ExpandedGroups), tree.IsBranch)
+}
+
+// onExpandAll 展开所有分组
+func (m *Manager) onExpandAll() {
+	m.profileList.OpenAllBranches()
+	m.saveExpandedGroups(append(m.otherExpandedGroups(), m.controller.Tree().Branches()...))
+	m.statusBar.SetText("已展开所有分组")
+}
+
+// onCollapseAll 折叠所有分组
+func (m *Manager) onCollapseAll() {
+	m.profileList.CloseAllBranches()
+	m.saveExpandedGroups(m.otherExpandedGroups())
+	m.statusBar.SetText("已折叠所有分组")
+}
+
+// onGroupProfiles 切换Profile树的分组方式，展开状态按分组方式分别记录
+func (m *Manager) onGroupProfiles(grouping string) {
+	if m.appConfig.UI.ProfileGrouping == grouping {
+		return
+	}
+
+	m.appConfig.UI.ProfileGrouping = grouping
+	if err := m.configManager.SaveConfig(m.appConfig); err != nil {
+		m.showErrorDialog("保存设置失败", err)
+		return
+	}
+
+	m.reloadProfileTree()
+
+	label := "文件夹"
+	if grouping == models.ProfileGroupingTag {
+		label = "标签"
+	}
+	m.statusBar.SetText(fmt.Sprintf("Profile列表已按%s分组", label))
+}
+
+// reloadProfileTree 按设置中的分组方式重建Profile树，恢复展开的分组和选中的Profile
+func (m *Manager) reloadProfileTree() {
+	m.controller.SetGrouping(m.appConfig.UI.ProfileGrouping)
+	m.profileList.UnselectAll()
+	m.profileList.Refresh()
+	m.restoreExpandedGroups()
+	if current := m.controller.CurrentProfile(); current != nil {
+		m.selectProfileNode(current.ID)
+	}
+}
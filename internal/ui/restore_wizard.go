@@ -0,0 +1,190 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/flyhigher139/mhost/internal/host"
+	"github.com/flyhigher139/mhost/pkg/models"
+)
+
+// checkHostsOnStartup 启动时验证hosts文件，文件损坏或无法读取时打开恢复向导
+func (m *Manager) checkHostsOnStartup() {
+	result, err := m.hostManager.ValidateHostsFile()
+	switch {
+	case err != nil:
+		m.showRestoreWizard(fmt.Sprintf("无法读取hosts文件: %v", err))
+	case !result.Valid():
+		m.showRestoreWizard(fmt.Sprintf("hosts文件验证失败，发现%d个语法错误，第一个: %s", len(result.Errors()), result.Errors()[0]))
+	}
+}
+
+// onRestoreHosts 打开恢复向导，从备份或系统初始hosts恢复hosts文件
+func (m *Manager) onRestoreHosts() {
+	m.showRestoreWizard("")
+}
+
+// restoreCandidates 按有效性和时间排序的备份，系统初始hosts作为最后的候选
+func (m *Manager) restoreCandidates() ([]*host.RestoreCandidate, error) {
+	candidates, err := host.RankBackups(m.workspace.BackupDir)
+	if err != nil {
+		return nil, err
+	}
+	if path, err := m.baselinePath(); err == nil {
+		if baseline, err := host.LoadBaseline(path); err == nil {
+			candidates = append(candidates, host.LoadRestoreCandidate(host.BackupFile{
+				Name:    host.BaselineProfileName,
+				Path:    baseline.Path,
+				ModTime: baseline.CapturedAt,
+			}))
+		}
+	}
+	return candidates, nil
+}
+
+// formatRestoreCandidate 格式化恢复向导列表中的一行，推荐的备份带有标记
+func formatRestoreCandidate(candidate *host.RestoreCandidate, recommended bool) string {
+	kind := "手动"
+	switch {
+	case candidate.Backup.Name == host.BaselineProfileName:
+		kind = "系统初始"
+	case candidate.Backup.Automatic:
+		kind = "自动"
+	}
+
+	status := fmt.Sprintf("✓ %d个条目", candidate.Entries)
+	switch {
+	case candidate.Err != nil:
+		status = "✗ " + candidate.Err.Error()
+	case len(candidate.Errors) > 0:
+		status = fmt.Sprintf("✗ %d个语法错误", len(candidate.Errors))
+	}
+
+	text := fmt.Sprintf("%s  %s  %s", candidate.Backup.ModTime.Format(time.DateTime), kind, status)
+	if recommended {
+		text = "★ 推荐  " + text
+	}
+	return text
+}
+
+// restoreCandidateDetails 候选备份的文件信息和验证问题
+func restoreCandidateDetails(candidate *host.RestoreCandidate) string {
+	lines := []string{fmt.Sprintf("%s  %s", candidate.Backup.Path, formatBytes(candidate.Backup.Size))}
+	if candidate.Err != nil {
+		lines = append(lines, "无法恢复: "+candidate.Err.Error())
+	}
+	for _, issue := range candidate.Errors {
+		lines = append(lines, "错误: "+issue.String())
+	}
+	if len(candidate.Warnings) > 0 {
+		lines = append(lines, fmt.Sprintf("%d个警告", len(candidate.Warnings)))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// showRestoreWizard 显示恢复向导：列出排序后的备份并推荐最佳备份，选中时预览内容，确认后恢复
+func (m *Manager) showRestoreWizard(reason string) {
+	candidates, err := m.restoreCandidates()
+	if err != nil {
+		m.showErrorDialog("读取备份失败", err)
+		return
+	}
+	if len(candidates) == 0 {
+		message := "没有可用于恢复的备份"
+		if reason != "" {
+			message = reason + "\n\n" + message
+		}
+		dialog.ShowInformation("恢复Hosts文件", message, m.window)
+		return
+	}
+
+	// 排序后第一个有效的候选即为推荐的备份
+	recommended := -1
+	if candidates[0].Valid() {
+		recommended = 0
+	}
+
+	header := widget.NewLabel("选择要恢复的备份，有效且较新的备份排在前面。")
+	if reason != "" {
+		header.SetText(reason + "\n" + header.Text)
+	}
+	header.Wrapping = fyne.TextWrapWord
+
+	preview := widget.NewMultiLineEntry()
+	preview.Disable()
+	details := widget.NewLabel("")
+	details.Wrapping = fyne.TextWrapWord
+
+	selected := -1
+	list := widget.NewList(
+		func() int { return len(candidates) },
+		func() fyne.CanvasObject { return widget.NewLabel("") },
+		func(id widget.ListItemID, obj fyne.CanvasObject) {
+			obj.(*widget.Label).SetText(formatRestoreCandidate(candidates[id], id == recommended))
+		},
+	)
+	list.OnSelected = func(id widget.ListItemID) {
+		selected = id
+		preview.SetText(strings.Join(candidates[id].Lines, "\n"))
+		details.SetText(restoreCandidateDetails(candidates[id]))
+	}
+
+	var d dialog.Dialog
+	restoreButton := widget.NewButton("恢复选中的备份", func() {
+		if selected < 0 {
+			dialog.ShowInformation("提示", "请先选择要恢复的备份", m.window)
+			return
+		}
+		candidate := candidates[selected]
+		if candidate.Err != nil {
+			m.showErrorDialog("无法恢复该备份", candidate.Err)
+			return
+		}
+		d.Hide()
+		m.confirmRestore(candidate)
+	})
+
+	split := container.NewHSplit(list, container.NewBorder(nil, details, nil, nil, preview))
+	split.Offset = 0.45
+	content := container.NewBorder(header, restoreButton, nil, nil, split)
+	d = dialog.NewCustom("恢复Hosts文件", "关闭", content, m.window)
+	d.Resize(fyne.NewSize(900, 500))
+	d.Show()
+
+	if recommended >= 0 {
+		list.Select(recommended)
+	}
+}
+
+// confirmRestore 确认后先备份当前hosts文件，再恢复选中的备份；没有写入权限时通过提权方式写入
+func (m *Manager) confirmRestore(candidate *host.RestoreCandidate) {
+	message := fmt.Sprintf("确定要用 %s 的备份替换当前hosts文件吗？", candidate.Backup.ModTime.Format(time.DateTime))
+	if !candidate.Valid() {
+		message += fmt.Sprintf("\n\n注意：该备份包含%d个语法错误。", len(candidate.Errors))
+	}
+	dialog.ShowConfirm("恢复Hosts文件", message, func(confirmed bool) {
+		if !confirmed {
+			return
+		}
+
+		go func() {
+			// 损坏的hosts文件也先保留一份，便于排查；文件不存在时跳过
+			data := map[string]interface{}{"path": candidate.Backup.Path}
+			if backup, err := m.hostManager.BackupHostsFile(); err == nil {
+				data["backup"] = backup.FilePath
+			}
+			if _, err := host.RestoreFromCandidate(m.hostManager, candidate); err != nil {
+				m.showErrorDialog("恢复hosts文件失败", err)
+				return
+			}
+			m.recordActivity(models.EventSystemBackupRestored, data)
+			m.statusBar.SetText(fmt.Sprintf("hosts文件已从 %s 恢复", candidate.Backup.Name))
+		}()
+	}, m.window)
+}
@@ -0,0 +1,43 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/flyhigher139/mhost/internal/rules"
+	"github.com/flyhigher139/mhost/pkg/hostsfile"
+)
+
+// severityLabels 校验规则严重程度的显示名称
+var severityLabels = map[hostsfile.Severity]string{
+	hostsfile.SeverityError:   "错误",
+	hostsfile.SeverityWarning: "警告",
+}
+
+// ruleViolationText 违反的校验规则列表，每行一条，例如"- [错误] api.test (10.0.0.1): ... [rule]"
+func ruleViolationText(violations []rules.Violation) string {
+	lines := make([]string, 0, len(violations))
+	for _, violation := range violations {
+		lines = append(lines, fmt.Sprintf("- [%s] %s", severityLabels[violation.Severity], violation))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// loadRules 按当前配置读取自定义校验规则并设置到hosts管理器，读取失败时保留原来的规则
+func (m *Manager) loadRules() error {
+	set, err := rules.LoadConfig(m.appConfig.Rules, m.workspace.DataDir)
+	if err != nil {
+		return err
+	}
+	m.hostManager.SetRules(set)
+	return nil
+}
+
+// onReloadRules 修改规则文件后重新读取校验规则
+func (m *Manager) onReloadRules() {
+	if err := m.loadRules(); err != nil {
+		m.showErrorDialog("读取校验规则失败", err)
+		return
+	}
+	m.statusBar.SetText(fmt.Sprintf("已加载%d条校验规则", m.hostManager.Rules().Len()))
+}
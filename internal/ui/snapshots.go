@@ -0,0 +1,222 @@
+package ui
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/flyhigher139/mhost/internal/profile"
+	"github.com/flyhigher139/mhost/internal/snapshot"
+	"github.com/flyhigher139/mhost/pkg/models"
+)
+
+// snapshotDir 获取快照目录，未设置时使用工作区数据目录下的snapshots
+func (m *Manager) snapshotDir() string {
+	if dir := strings.TrimSpace(m.appConfig.Snapshot.Directory); dir != "" {
+		return dir
+	}
+	return snapshot.DefaultDir(m.workspace.DataDir)
+}
+
+// startSnapshots 按设置启动定期Profile快照
+func (m *Manager) startSnapshots() {
+	m.snapshots = snapshot.NewScheduler(m.profileManager, m.snapshotDir(), m.appConfig.Snapshot.MaxSnapshots)
+	m.snapshots.OnSnapshot(m.onSnapshotSaved)
+
+	if !m.appConfig.Snapshot.Enabled {
+		return
+	}
+	if err := m.snapshots.Start(m.appConfig.Snapshot.Interval); err != nil {
+		fmt.Printf("Failed to start profile snapshots: %v\n", err)
+	}
+}
+
+// stopSnapshots 停止定期Profile快照
+func (m *Manager) stopSnapshots() {
+	if m.snapshots != nil {
+		m.snapshots.Stop()
+	}
+}
+
+// onSnapshotSaved 快照导出后记录到最近活动，失败时在健康面板中显示
+func (m *Manager) onSnapshotSaved(saved *snapshot.Snapshot, err error) {
+	if err != nil {
+		m.recordError("导出Profile快照失败", err)
+		m.recordActivity(models.EventError, map[string]interface{}{"operation": "snapshot", "error": err.Error()})
+		return
+	}
+	m.recordActivity(models.EventSystemSnapshotSaved, map[string]interface{}{"path": saved.Path, "size": saved.Size})
+}
+
+// onExportSnapshot 立即导出一次全部Profile的快照
+func (m *Manager) onExportSnapshot() {
+	progressDialog := dialog.NewProgressInfinite("导出Profile快照", "正在导出全部Profile，请稍候...", m.window)
+	progressDialog.Show()
+
+	go func() {
+		saved, err := m.snapshots.Export()
+		progressDialog.Hide()
+		if err != nil {
+			m.showErrorDialog("导出Profile快照失败", err)
+			return
+		}
+		m.statusBar.SetText(fmt.Sprintf("Profile快照已保存到 %s", saved.Path))
+	}()
+}
+
+// formatSnapshot 格式化快照列表中的一行
+func formatSnapshot(s snapshot.Snapshot) string {
+	return fmt.Sprintf("%s  %s  %s", s.CreatedAt.Format("2006-01-02 15:04:05"), formatBytes(s.Size), filepath.Base(s.Path))
+}
+
+// onShowSnapshots 显示快照目录中的快照，可将选中的快照中的Profile恢复为新Profile，或与当前Profile对比
+func (m *Manager) onShowSnapshots() {
+	snapshots, err := snapshot.List(m.snapshotDir())
+	if err != nil {
+		m.showErrorDialog("读取快照失败", err)
+		return
+	}
+	if len(snapshots) == 0 {
+		dialog.ShowInformation("Profile快照", fmt.Sprintf("%s 中没有快照", m.snapshotDir()), m.window)
+		return
+	}
+
+	selected := -1
+	list := widget.NewList(
+		func() int { return len(snapshots) },
+		func() fyne.CanvasObject { return widget.NewLabel("") },
+		func(id widget.ListItemID, obj fyne.CanvasObject) {
+			obj.(*widget.Label).SetText(formatSnapshot(snapshots[id]))
+		},
+	)
+	list.OnSelected = func(id widget.ListItemID) { selected = id }
+
+	header := widget.NewLabel(fmt.Sprintf("快照目录: %s", m.snapshotDir()))
+	header.Wrapping = fyne.TextWrapWord
+
+	var d dialog.Dialog
+	restoreButton := widget.NewButton("恢复选中的快照", func() {
+		if selected < 0 {
+			dialog.ShowInformation("提示", "请先选择要恢复的快照", m.window)
+			return
+		}
+		d.Hide()
+		m.restoreSnapshot(snapshots[selected])
+	})
+
+	compareButton := widget.NewButton("与当前Profile对比", func() {
+		if selected < 0 {
+			dialog.ShowInformation("提示", "请先选择要对比的快照", m.window)
+			return
+		}
+		m.compareSnapshot(snapshots[selected])
+	})
+
+	content := container.NewBorder(header, container.NewGridWithColumns(2, compareButton, restoreButton), nil, nil, list)
+	d = dialog.NewCustom("Profile快照", "关闭", content, m.window)
+	d.Resize(fyne.NewSize(600, 400))
+	d.Show()
+}
+
+// restoreSnapshot 确认后将快照中的Profile导入为新Profile，不修改现有Profile
+func (m *Manager) restoreSnapshot(s snapshot.Snapshot) {
+	bundle, err := snapshot.Load(s.Path)
+	if err != nil {
+		m.showErrorDialog("读取快照失败", err)
+		return
+	}
+
+	message := fmt.Sprintf("将快照 %s 中的%d个Profile导入为新Profile吗？\n\n现有Profile不会被修改，重名的Profile会自动添加后缀。",
+		s.CreatedAt.Format("2006-01-02 15:04:05"), len(bundle.Profiles))
+	dialog.ShowConfirm("从快照恢复", message, func(confirmed bool) {
+		if !confirmed {
+			return
+		}
+
+		report := profile.NewImportReport(s.Path)
+		restored, err := snapshot.Restore(m.profileManager, bundle, report)
+		for _, p := range restored {
+			m.recordActivity(models.EventProfileImported, map[string]interface{}{
+				"profile_id":   p.ID,
+				"profile_name": p.Name,
+				"source":       s.Path,
+				"entries":      len(p.Entries),
+			})
+		}
+		m.refreshProfileList()
+		m.showImportReport(report)
+		if err != nil {
+			m.showErrorDialog("从快照恢复失败", fmt.Errorf("已恢复%d个Profile: %w", len(restored), err))
+			return
+		}
+		m.statusBar.SetText(fmt.Sprintf("已从快照恢复%d个Profile", len(restored)))
+	}, m.window)
+}
+
+// createSnapshotSettings 创建设置对话框中的Profile快照分组，返回读取输入的函数
+func (m *Manager) createSnapshotSettings() (*widget.Card, func() (models.SnapshotConfig, error)) {
+	config := m.appConfig.Snapshot
+	defaults := models.DefaultAppConfig().Snapshot
+	if config.Interval <= 0 {
+		config.Interval = defaults.Interval
+	}
+
+	enabledCheck := widget.NewCheck("定期导出全部Profile", nil)
+	enabledCheck.SetChecked(config.Enabled)
+	intervalEntry := widget.NewEntry()
+	intervalEntry.SetText(fmt.Sprintf("%d", int(config.Interval.Hours())))
+	keepEntry := widget.NewEntry()
+	keepEntry.SetText(fmt.Sprintf("%d", config.MaxSnapshots))
+
+	dirEntry := widget.NewEntry()
+	dirEntry.SetPlaceHolder(snapshot.DefaultDir(m.workspace.DataDir))
+	dirEntry.SetText(config.Directory)
+	dirButton := widget.NewButton("浏览...", func() {
+		dialog.ShowFolderOpen(func(uri fyne.ListableURI, err error) {
+			if err != nil {
+				m.showErrorDialog("选择目录失败", err)
+				return
+			}
+			if uri != nil {
+				dirEntry.SetText(uri.Path())
+			}
+		}, m.window)
+	})
+
+	form := &widget.Form{
+		Items: []*widget.FormItem{
+			{Text: "定期快照", Widget: enabledCheck},
+			{Text: "快照目录", Widget: container.NewBorder(nil, nil, nil, dirButton, dirEntry), HintText: "为空时保存在数据目录下"},
+			{Text: "快照间隔(小时)", Widget: intervalEntry},
+			{Text: "保留数量", Widget: keepEntry, HintText: "0表示不清理旧快照"},
+			{Text: "", Widget: container.NewHBox(
+				widget.NewButton("立即导出", m.onExportSnapshot),
+				widget.NewButton("查看快照...", m.onShowSnapshots),
+			)},
+		},
+	}
+
+	read := func() (models.SnapshotConfig, error) {
+		var hours, keep int
+		if _, err := fmt.Sscanf(intervalEntry.Text, "%d", &hours); err != nil || hours <= 0 {
+			return config, errors.New("快照间隔必须是正整数")
+		}
+		if _, err := fmt.Sscanf(keepEntry.Text, "%d", &keep); err != nil || keep < 0 {
+			return config, errors.New("快照保留数量必须是非负整数")
+		}
+		return models.SnapshotConfig{
+			Enabled:      enabledCheck.Checked,
+			Interval:     time.Duration(hours) * time.Hour,
+			Directory:    strings.TrimSpace(dirEntry.Text),
+			MaxSnapshots: keep,
+		}, nil
+	}
+	return widget.NewCard("Profile快照", "与hosts文件备份相互独立，用于恢复Profile数据", form), read
+}
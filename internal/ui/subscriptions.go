@@ -0,0 +1,181 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/flyhigher139/mhost/internal/httpclient"
+	"github.com/flyhigher139/mhost/internal/importer"
+	"github.com/flyhigher139/mhost/internal/profile"
+	"github.com/flyhigher139/mhost/internal/subscription"
+	"github.com/flyhigher139/mhost/pkg/models"
+)
+
+// startSubscriptions 启动订阅的定时刷新，离线模式下只支持手动刷新
+func (m *Manager) startSubscriptions() {
+	m.subscriptions = subscription.NewRefresher(m.profileManager, nil)
+	m.subscriptions.SetNormalizer(m.hostnameNormalizer())
+	m.subscriptions.SetApplier(m.applySubscription)
+	m.subscriptions.OnResult(m.onSubscriptionRefreshed)
+
+	if httpclient.Default().Offline() {
+		return
+	}
+	if err := m.subscriptions.Start(subscription.DefaultCheckInterval); err != nil {
+		fmt.Printf("Failed to start subscription refresh: %v\n", err)
+	}
+}
+
+// stopSubscriptions 停止订阅的定时刷新
+func (m *Manager) stopSubscriptions() {
+	if m.subscriptions != nil {
+		m.subscriptions.Stop()
+	}
+}
+
+// applySubscription 订阅或资产清单更新后重新应用激活的Profile，与其他Profile同时激活时一起叠加应用
+func (m *Manager) applySubscription(*models.Profile) error {
+	result, err := m.controller.ReapplyActive()
+	if err != nil || result == nil {
+		return err
+	}
+	m.recordApply(result)
+	m.recheckHealth()
+	return nil
+}
+
+// onSubscriptionRefreshed 记录订阅刷新结果，条目变化时刷新界面
+func (m *Manager) onSubscriptionRefreshed(result *subscription.Result) {
+	if result.Err != nil {
+		m.recordError(fmt.Sprintf("刷新订阅 '%s' 失败", result.ProfileName), result.Err)
+		m.recordActivity(models.EventError, map[string]interface{}{
+			"operation":  "subscription",
+			"profile_id": result.ProfileID,
+			"error":      result.Err.Error(),
+		})
+	}
+	if !result.Changed {
+		return
+	}
+
+	m.recordActivity(models.EventProfileSubscriptionRefreshed, map[string]interface{}{
+		"profile_id":   result.ProfileID,
+		"profile_name": result.ProfileName,
+		"entries":      result.Entries,
+		"applied":      result.Applied,
+	})
+	m.refreshProfileList()
+	if result.Applied {
+		m.statusBar.SetText(fmt.Sprintf("订阅 '%s' 已更新并重新应用，共%d个条目", result.ProfileName, result.Entries))
+	} else {
+		m.statusBar.SetText(fmt.Sprintf("订阅 '%s' 已更新，共%d个条目", result.ProfileName, result.Entries))
+	}
+}
+
+// onSubscribe 订阅远程hosts列表（例如公共屏蔽列表或公司内部列表），创建Profile后立即下载
+func (m *Manager) onSubscribe() {
+	if httpclient.Default().Offline() {
+		dialog.ShowInformation("提示", "离线模式下无法订阅远程hosts列表", m.window)
+		return
+	}
+
+	urlEntry := widget.NewEntry()
+	urlEntry.SetPlaceHolder("https://raw.githubusercontent.com/StevenBlack/hosts/master/hosts")
+
+	nameEntry := widget.NewEntry()
+	nameEntry.SetPlaceHolder("为空时根据URL生成")
+
+	intervalEntry := widget.NewEntry()
+	intervalEntry.SetText(strconv.Itoa(int(models.DefaultSubscriptionInterval.Hours())))
+
+	d := dialog.NewForm("订阅远程hosts列表", "订阅", "取消", []*widget.FormItem{
+		{Text: "URL", Widget: urlEntry, HintText: "hosts格式的HTTP(S)地址"},
+		{Text: "名称", Widget: nameEntry},
+		{Text: "刷新间隔", Widget: intervalEntry, HintText: "小时，上游内容变化时自动更新并重新应用"},
+	}, func(confirmed bool) {
+		if !confirmed {
+			return
+		}
+		rawURL := strings.TrimSpace(urlEntry.Text)
+		if rawURL == "" {
+			dialog.ShowInformation("提示", "请输入要订阅的URL", m.window)
+			return
+		}
+		hours, err := strconv.Atoi(strings.TrimSpace(intervalEntry.Text))
+		if err != nil || hours <= 0 {
+			dialog.ShowInformation("提示", "刷新间隔必须是正整数", m.window)
+			return
+		}
+		name := strings.TrimSpace(nameEntry.Text)
+		if name == "" {
+			name = importer.NameFromURL(rawURL)
+		}
+		m.createSubscription(name, &models.Subscription{URL: rawURL, IntervalMinutes: hours * 60})
+	}, m.window)
+	d.Resize(fyne.NewSize(560, 0))
+	d.Show()
+}
+
+// createSubscription 创建订阅的Profile并立即下载上游内容
+func (m *Manager) createSubscription(name string, sub *models.Subscription) {
+	summaries, err := m.profileManager.ListProfiles()
+	if err != nil {
+		m.showErrorDialog("订阅失败", err)
+		return
+	}
+	existing := make([]string, 0, len(summaries))
+	for _, summary := range summaries {
+		existing = append(existing, summary.Name)
+	}
+
+	created, err := m.profileManager.CreateProfile(profile.UniqueName(name, existing), "订阅: "+sub.URL)
+	if err != nil {
+		m.showErrorDialog("订阅失败", err)
+		return
+	}
+	created.Subscription = sub
+	if err := m.profileManager.UpdateProfile(created); err != nil {
+		m.showErrorDialog("订阅失败", err)
+		return
+	}
+	m.recordActivity(models.EventProfileCreated, map[string]interface{}{
+		"profile_id":   created.ID,
+		"profile_name": created.Name,
+		"subscription": sub.URL,
+	})
+	m.refreshProfileList()
+	m.refreshSubscription(created.ID)
+}
+
+// onRefreshSubscription 立即刷新当前Profile的订阅
+func (m *Manager) onRefreshSubscription() {
+	current := m.controller.CurrentProfile()
+	if current == nil || current.Subscription == nil {
+		dialog.ShowInformation("提示", "当前Profile没有订阅远程hosts列表", m.window)
+		return
+	}
+	m.refreshSubscription(current.ID)
+}
+
+// refreshSubscription 下载订阅内容并显示结果，条目变化由onSubscriptionRefreshed处理
+func (m *Manager) refreshSubscription(id string) {
+	progressDialog := dialog.NewProgressInfinite("刷新订阅", "正在下载，请稍候...", m.window)
+	progressDialog.Show()
+
+	go func() {
+		result := m.subscriptions.Refresh(context.Background(), id)
+		progressDialog.Hide()
+		switch {
+		case result.Err != nil:
+			m.showErrorDialog("刷新订阅失败", result.Err)
+		case !result.Changed:
+			m.statusBar.SetText(fmt.Sprintf("订阅 '%s' 没有变化，共%d个条目", result.ProfileName, result.Entries))
+		}
+	}()
+}
@@ -0,0 +1,203 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/flyhigher139/mhost/pkg/models"
+)
+
+// createProfileSearch 创建Profile列表上方的搜索框，输入时筛选Profile树
+func (m *Manager) createProfileSearch() fyne.CanvasObject {
+	m.profileSearch = widget.NewEntry()
+	m.profileSearch.SetPlaceHolder("搜索Profile，例如 tag:prod")
+	m.profileSearch.ActionItem = widget.NewButtonWithIcon("", theme.ContentClearIcon(), func() {
+		m.profileSearch.SetText("")
+	})
+	m.profileSearch.OnChanged = m.filterProfileTree
+	return m.profileSearch
+}
+
+// filterProfileTree 按搜索条件筛选Profile树，搜索时展开全部分组，清空后恢复保存的展开状态
+func (m *Manager) filterProfileTree(query string) {
+	m.controller.SetFilter(query)
+	m.profileList.UnselectAll()
+	m.profileList.Refresh()
+	if m.controller.Filtering() {
+		m.profileList.OpenAllBranches()
+	} else {
+		m.restoreExpandedGroups()
+	}
+	if current := m.controller.CurrentProfile(); current != nil {
+		if _, ok := m.controller.Tree().NodeFor(current.ID); ok {
+			m.selectProfileNode(current.ID)
+		}
+	}
+}
+
+// filterByTag 在搜索框中按标签筛选Profile
+func (m *Manager) filterByTag(tag string) {
+	query := models.TagQueryPrefix + tag
+	if strings.ContainsAny(tag, " \t") {
+		// 含空白的标签无法用tag:表示，按文本搜索
+		query = tag
+	}
+	m.profileSearch.SetText(query)
+}
+
+// tagChips 创建Profile条目中显示的标签，点击时按该标签筛选
+func (m *Manager) tagChips(tags []string) []fyne.CanvasObject {
+	chips := make([]fyne.CanvasObject, 0, len(tags))
+	for _, tag := range tags {
+		tag = strings.TrimSpace(tag)
+		if tag == "" {
+			continue
+		}
+		chip := widget.NewButton(tag, func() { m.filterByTag(tag) })
+		chip.Importance = widget.LowImportance
+		chips = append(chips, chip)
+	}
+	return chips
+}
+
+// onAddTag 为当前Profile添加标签，可以从已有标签中选择
+func (m *Manager) onAddTag() {
+	current := m.controller.CurrentProfile()
+	if current == nil {
+		dialog.ShowInformation("提示", "请先选择要添加标签的Profile", m.window)
+		return
+	}
+
+	var options []string
+	if tags, err := m.profileManager.ListTags(); err == nil {
+		for _, tag := range tags {
+			if !current.HasTag(tag.Name) {
+				options = append(options, tag.Name)
+			}
+		}
+	}
+	tagEntry := widget.NewSelectEntry(options)
+	tagEntry.SetPlaceHolder("例如: prod")
+
+	dialog.ShowForm(fmt.Sprintf("为 '%s' 添加标签", current.Name), "添加", "取消", []*widget.FormItem{
+		{Text: "标签", Widget: tagEntry, HintText: "标签不能包含逗号"},
+	}, func(confirmed bool) {
+		if !confirmed {
+			return
+		}
+		tag := strings.TrimSpace(tagEntry.Text)
+		if err := m.controller.AddTag(current, tag); err != nil {
+			m.showErrorDialog("添加标签失败", err)
+			return
+		}
+		m.recordActivity(models.EventProfileUpdated, map[string]interface{}{
+			"profile_id":   current.ID,
+			"profile_name": current.Name,
+			"tag_added":    tag,
+		})
+		m.refreshProfileList()
+		m.statusBar.SetText(fmt.Sprintf("已为Profile '%s' 添加标签: %s", current.Name, tag))
+	}, m.window)
+}
+
+// onManageTags 显示所有标签及其Profile数量，可以按标签筛选、重命名或删除标签
+func (m *Manager) onManageTags() {
+	tags, err := m.profileManager.ListTags()
+	if err != nil {
+		m.showErrorDialog("加载标签失败", err)
+		return
+	}
+	if len(tags) == 0 {
+		dialog.ShowInformation("管理标签", "还没有Profile使用标签，可以在编辑Profile或添加标签时设置。", m.window)
+		return
+	}
+
+	var d dialog.Dialog
+	list := widget.NewList(
+		func() int { return len(tags) },
+		func() fyne.CanvasObject {
+			return container.NewHBox(
+				widget.NewLabel(""),
+				layout.NewSpacer(),
+				widget.NewButtonWithIcon("", theme.SearchIcon(), nil),
+				widget.NewButtonWithIcon("", theme.DocumentCreateIcon(), nil),
+				widget.NewButtonWithIcon("", theme.DeleteIcon(), nil),
+			)
+		},
+		func(id widget.ListItemID, obj fyne.CanvasObject) {
+			tag := tags[id]
+			row := obj.(*fyne.Container)
+			row.Objects[0].(*widget.Label).SetText(fmt.Sprintf("%s (%d)", tag.Name, tag.Count))
+			row.Objects[2].(*widget.Button).OnTapped = func() {
+				d.Hide()
+				m.filterByTag(tag.Name)
+			}
+			row.Objects[3].(*widget.Button).OnTapped = func() {
+				d.Hide()
+				m.renameTag(tag.Name)
+			}
+			row.Objects[4].(*widget.Button).OnTapped = func() {
+				d.Hide()
+				m.deleteTag(tag)
+			}
+		},
+	)
+
+	d = dialog.NewCustom("管理标签", "关闭", list, m.window)
+	d.Resize(fyne.NewSize(420, 360))
+	d.Show()
+}
+
+// renameTag 在所有Profile中重命名标签
+func (m *Manager) renameTag(tag string) {
+	nameEntry := widget.NewEntry()
+	nameEntry.SetText(tag)
+	dialog.ShowForm("重命名标签", "确定", "取消", []*widget.FormItem{
+		{Text: "名称", Widget: nameEntry, HintText: "与已有标签同名时合并"},
+	}, func(confirmed bool) {
+		newTag := strings.TrimSpace(nameEntry.Text)
+		if !confirmed || newTag == tag {
+			return
+		}
+		changed, err := m.controller.RenameTag(tag, newTag)
+		if err != nil {
+			m.showErrorDialog("重命名标签失败", err)
+			return
+		}
+		m.recordActivity(models.EventProfileUpdated, map[string]interface{}{
+			"tag":      tag,
+			"new_tag":  newTag,
+			"profiles": changed,
+		})
+		m.refreshProfileList()
+		m.statusBar.SetText(fmt.Sprintf("标签 '%s' 已重命名为 '%s'，修改了%d个Profile", tag, newTag, changed))
+	}, m.window)
+}
+
+// deleteTag 确认后从所有Profile中移除标签
+func (m *Manager) deleteTag(tag *models.TagSummary) {
+	message := fmt.Sprintf("确定要从%d个Profile中移除标签 '%s' 吗？\n\nProfile本身不会被删除。", tag.Count, tag.Name)
+	dialog.ShowConfirm("删除标签", message, func(confirmed bool) {
+		if !confirmed {
+			return
+		}
+		changed, err := m.controller.DeleteTag(tag.Name)
+		if err != nil {
+			m.showErrorDialog("删除标签失败", err)
+			return
+		}
+		m.recordActivity(models.EventProfileUpdated, map[string]interface{}{
+			"tag_removed": tag.Name,
+			"profiles":    changed,
+		})
+		m.refreshProfileList()
+		m.statusBar.SetText(fmt.Sprintf("已从%d个Profile中移除标签: %s", changed, tag.Name))
+	}, m.window)
+}
@@ -0,0 +1,38 @@
+package ui
+
+import (
+	"image/color"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/theme"
+)
+
+// variantTheme 固定使用浅色或深色的默认主题，不跟随系统外观
+type variantTheme struct {
+	fyne.Theme
+	variant fyne.ThemeVariant
+}
+
+// Color 按固定的明暗模式返回颜色
+func (t *variantTheme) Color(name fyne.ThemeColorName, _ fyne.ThemeVariant) color.Color {
+	return t.Theme.Color(name, t.variant)
+}
+
+// themeFor 按设置中的主题名称返回主题，auto及未知名称跟随系统外观
+func themeFor(name string) fyne.Theme {
+	switch name {
+	case "light":
+		return &variantTheme{Theme: theme.DefaultTheme(), variant: theme.VariantLight}
+	case "dark":
+		return &variantTheme{Theme: theme.DefaultTheme(), variant: theme.VariantDark}
+	default:
+		return theme.DefaultTheme()
+	}
+}
+
+// applyTheme 将设置中的主题应用到整个应用
+func (m *Manager) applyTheme() {
+	if app := fyne.CurrentApp(); app != nil {
+		app.Settings().SetTheme(themeFor(m.appConfig.UI.Theme))
+	}
+}
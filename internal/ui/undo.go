@@ -0,0 +1,167 @@
+package ui
+
+import (
+	"fmt"
+	"sync"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/driver/desktop"
+
+	"github.com/flyhigher139/mhost/pkg/models"
+)
+
+// maxUndoSteps 每个Profile保留的最多撤销步数
+const maxUndoSteps = 50
+
+// editCommand 一次可撤销的Profile修改，保存修改前后的Profile副本；撤销和重做都通过恢复副本完成
+type editCommand struct {
+	label  string
+	before *models.Profile
+	after  *models.Profile
+}
+
+// undoStack 单个Profile的撤销栈和重做栈
+type undoStack struct {
+	undo []*editCommand
+	redo []*editCommand
+}
+
+// undoHistory 按Profile区分的撤销历史，切换Profile后各自的历史保持不变
+type undoHistory struct {
+	mu     sync.Mutex
+	stacks map[string]*undoStack
+}
+
+// newUndoHistory 创建撤销历史
+func newUndoHistory() *undoHistory {
+	return &undoHistory{stacks: make(map[string]*undoStack)}
+}
+
+// stack 获取Profile的撤销栈，不存在时创建
+func (h *undoHistory) stack(id string) *undoStack {
+	s, ok := h.stacks[id]
+	if !ok {
+		s = &undoStack{}
+		h.stacks[id] = s
+	}
+	return s
+}
+
+// Push 记录一次修改并清空重做栈，超过maxUndoSteps时丢弃最早的修改
+func (h *undoHistory) Push(id string, cmd *editCommand) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	s := h.stack(id)
+	s.undo = append(s.undo, cmd)
+	if len(s.undo) > maxUndoSteps {
+		s.undo = s.undo[len(s.undo)-maxUndoSteps:]
+	}
+	s.redo = nil
+}
+
+// Undo 撤销Profile最近的修改：restore成功恢复修改前的副本后将修改移入重做栈，失败时保持不变。
+// 没有可撤销的修改时返回nil
+func (h *undoHistory) Undo(id string, restore func(*models.Profile) error) (*editCommand, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	s := h.stack(id)
+	if len(s.undo) == 0 {
+		return nil, nil
+	}
+	cmd := s.undo[len(s.undo)-1]
+	if err := restore(cmd.before); err != nil {
+		return nil, err
+	}
+	s.undo = s.undo[:len(s.undo)-1]
+	s.redo = append(s.redo, cmd)
+	return cmd, nil
+}
+
+// Redo 重做最近撤销的修改，与Undo相对
+func (h *undoHistory) Redo(id string, restore func(*models.Profile) error) (*editCommand, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	s := h.stack(id)
+	if len(s.redo) == 0 {
+		return nil, nil
+	}
+	cmd := s.redo[len(s.redo)-1]
+	if err := restore(cmd.after); err != nil {
+		return nil, err
+	}
+	s.redo = s.redo[:len(s.redo)-1]
+	s.undo = append(s.undo, cmd)
+	return cmd, nil
+}
+
+// Forget 删除Profile的撤销历史，用于Profile被删除后
+func (h *undoHistory) Forget(id string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	delete(h.stacks, id)
+}
+
+// registerUndoShortcuts 注册撤销（Cmd/Ctrl+Z）和重做（Cmd/Ctrl+Shift+Z、Cmd/Ctrl+Y）快捷键。
+// 注册在窗口画布上，输入框获得焦点时快捷键仍由输入框处理
+func (m *Manager) registerUndoShortcuts() {
+	canvas := m.window.Canvas()
+	canvas.AddShortcut(&fyne.ShortcutUndo{}, func(fyne.Shortcut) { m.onUndo() })
+	canvas.AddShortcut(&fyne.ShortcutRedo{}, func(fyne.Shortcut) { m.onRedo() })
+	for _, modifier := range []fyne.KeyModifier{fyne.KeyModifierControl, fyne.KeyModifierSuper} {
+		canvas.AddShortcut(&desktop.CustomShortcut{KeyName: fyne.KeyZ, Modifier: modifier | fyne.KeyModifierShift},
+			func(fyne.Shortcut) { m.onRedo() })
+	}
+}
+
+// beginEdit 在修改Profile之前保存副本，修改成功后调用返回的函数将修改记录到该Profile的撤销历史；p为nil时不记录
+func (m *Manager) beginEdit(p *models.Profile, label string) func() {
+	if p == nil {
+		return func() {}
+	}
+	before := p.Clone()
+	return func() {
+		m.undo.Push(p.ID, &editCommand{label: label, before: before, after: p.Clone()})
+	}
+}
+
+// onUndo 撤销当前Profile最近的修改
+func (m *Manager) onUndo() {
+	m.stepHistory(true)
+}
+
+// onRedo 重做当前Profile最近撤销的修改
+func (m *Manager) onRedo() {
+	m.stepHistory(false)
+}
+
+// stepHistory 撤销或重做当前Profile的修改，并刷新列表；激活的Profile按设置自动重新应用
+func (m *Manager) stepHistory(undo bool) {
+	current := m.controller.CurrentProfile()
+	if current == nil {
+		return
+	}
+
+	step, action := m.undo.Redo, "重做"
+	if undo {
+		step, action = m.undo.Undo, "撤销"
+	}
+	cmd, err := step(current.ID, m.controller.RestoreProfile)
+	if err != nil {
+		m.showErrorDialog(action+"失败", err)
+		return
+	}
+	if cmd == nil {
+		m.statusBar.SetText(fmt.Sprintf("没有可%s的修改", action))
+		return
+	}
+
+	m.profileList.Refresh()
+	m.refreshHostEntryList()
+	m.updateProfileSelector()
+	m.refreshEnvironmentSelect()
+	m.statusBar.SetText(fmt.Sprintf("已%s: %s", action, cmd.label))
+	m.scheduleAutoApply()
+}
@@ -0,0 +1,77 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/flyhigher139/mhost/internal/telemetry"
+)
+
+// recordUsage 记录一次功能使用，未开启使用统计时忽略；统计失败不影响正常操作
+func (m *Manager) recordUsage(event telemetry.Event) {
+	if m.usage == nil {
+		return
+	}
+	_ = m.usage.Record(event)
+}
+
+// createUsageSettings 创建设置对话框中的使用统计分组
+func (m *Manager) createUsageSettings() (*widget.Card, *widget.Check) {
+	enabledCheck := widget.NewCheck("帮助改进mHost：在本地统计功能使用次数", nil)
+	enabledCheck.SetChecked(m.appConfig.Telemetry.Enabled)
+
+	viewButton := widget.NewButton("查看与导出...", m.onShowUsageStats)
+	clearButton := widget.NewButton("清除统计数据", func() {
+		dialog.ShowConfirm("清除统计数据", "确定要删除本地保存的所有使用统计吗？", func(confirmed bool) {
+			if !confirmed {
+				return
+			}
+			if err := m.usage.Reset(); err != nil {
+				m.showErrorDialog("清除失败", err)
+				return
+			}
+			m.statusBar.SetText("使用统计已清除")
+		}, m.window)
+	})
+
+	content := container.NewVBox(
+		enabledCheck,
+		widget.NewLabel("仅记录应用、导入等操作的次数，不包含主机名、IP或Profile内容，数据不会上传。"),
+		container.NewHBox(viewButton, clearButton),
+	)
+	return widget.NewCard("使用统计", "", content), enabledCheck
+}
+
+// onShowUsageStats 显示本地使用统计并支持导出为JSON文件
+func (m *Manager) onShowUsageStats() {
+	summary := m.usage.Summary()
+
+	var text strings.Builder
+	if len(summary.Counts) == 0 {
+		text.WriteString("暂无统计数据")
+	} else {
+		fmt.Fprintf(&text, "统计开始于 %s\n\n", summary.Since.Format("2006-01-02 15:04"))
+		for _, event := range summary.Events() {
+			fmt.Fprintf(&text, "%s\t%d\n", event, summary.Counts[event])
+		}
+	}
+
+	statsText := widget.NewMultiLineEntry()
+	statsText.SetText(text.String())
+	statsText.Disable()
+	statsText.SetMinRowsVisible(8)
+
+	d := dialog.NewCustomConfirm("使用统计", "导出...", "关闭", statsText, func(export bool) {
+		if !export {
+			return
+		}
+		m.saveExport("mhost-usage.json", m.usage.Export)
+	}, m.window)
+	d.Resize(fyne.NewSize(420, 0))
+	d.Show()
+}
@@ -0,0 +1,141 @@
+package ui
+
+import (
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/flyhigher139/mhost/internal/activity"
+	"github.com/flyhigher139/mhost/internal/config"
+	"github.com/flyhigher139/mhost/internal/controller"
+	"github.com/flyhigher139/mhost/internal/daemon"
+	"github.com/flyhigher139/mhost/internal/diagnostics"
+	"github.com/flyhigher139/mhost/internal/host"
+	"github.com/flyhigher139/mhost/internal/httpclient"
+	"github.com/flyhigher139/mhost/internal/telemetry"
+)
+
+// openWorkspace 为工作区创建配置、Profile与hosts管理器，失败时保持当前工作区不变
+func (m *Manager) openWorkspace(workspace *config.Workspace) error {
+	// 未安装Helper时通过系统管理员权限对话框写入hosts文件
+	var elevator host.Elevator
+	if osascript := host.NewOsascriptElevator(); osascript.Available() {
+		elevator = osascript
+	}
+	managers, err := controller.OpenWorkspace(workspace, elevator)
+	if err != nil {
+		return err
+	}
+	configManager, appConfig := managers.ConfigManager, managers.Config
+	profileManager, hostManager := managers.ProfileManager, managers.HostManager
+	hostManager.Hooks().SetPrint(m.logHookOutput)
+
+	m.workspace = workspace
+	m.configManager = configManager
+	m.appConfig = appConfig
+	m.applyLogLevel()
+	httpclient.Default().SetConfig(appConfig.Network)
+	m.profileManager = profileManager
+	m.hostManager = hostManager
+	m.controller = controller.New(profileManager, hostManager)
+	m.controller.SetGrouping(appConfig.UI.ProfileGrouping)
+	m.applyEntryTableConfig()
+	m.ipInfo = diagnostics.NewIPInfoService(workspace.DataDir)
+	m.daemonClient = daemon.NewClient(daemon.DefaultSocketPath(workspace.DataDir))
+	m.usage = telemetry.NewRecorder(workspace.DataDir, appConfig.Telemetry.Enabled)
+	m.history = host.NewHistory(host.DefaultHistoryPath(workspace.DataDir))
+	m.activity = activity.NewFeed(activity.DefaultFeedPath(workspace.DataDir))
+	m.lastDrift = 0
+	m.undo = newUndoHistory()
+	if err := m.loadPlugins(); err != nil {
+		m.logger.Warn("some plugins could not be loaded", "error", err.Error())
+	}
+	return nil
+}
+
+// onSwitchWorkspace 显示工作区切换对话框
+func (m *Manager) onSwitchWorkspace() {
+	workspaces, err := m.workspaces.ListWorkspaces()
+	if err != nil {
+		m.showErrorDialog("加载工作区失败", err)
+		return
+	}
+
+	names := make([]string, 0, len(workspaces))
+	for _, workspace := range workspaces {
+		names = append(names, workspace.Name)
+	}
+	workspaceSelect := widget.NewSelect(names, nil)
+	workspaceSelect.SetSelected(m.workspace.Name)
+
+	nameEntry := widget.NewEntry()
+	nameEntry.SetPlaceHolder("例如: work")
+	createButton := widget.NewButton("新建", func() {
+		workspace, err := m.workspaces.CreateWorkspace(nameEntry.Text)
+		if err != nil {
+			m.showErrorDialog("新建工作区失败", err)
+			return
+		}
+		workspaceSelect.Options = append(workspaceSelect.Options, workspace.Name)
+		workspaceSelect.SetSelected(workspace.Name)
+		nameEntry.SetText("")
+	})
+
+	form := container.NewVBox(
+		widget.NewLabel("每个工作区拥有独立的Profile、备份目录和设置。"),
+		widget.NewForm(
+			&widget.FormItem{Text: "工作区", Widget: workspaceSelect},
+			&widget.FormItem{Text: "新建工作区", Widget: container.NewBorder(nil, nil, nil, createButton, nameEntry)},
+		),
+	)
+
+	d := dialog.NewCustomConfirm("切换工作区", "切换", "取消", form, func(confirmed bool) {
+		if !confirmed || workspaceSelect.Selected == "" || workspaceSelect.Selected == m.workspace.Name {
+			return
+		}
+		m.switchWorkspace(workspaceSelect.Selected)
+	}, m.window)
+	d.Resize(fyne.NewSize(420, 0))
+	d.Show()
+}
+
+// switchWorkspace 切换到指定工作区并重新加载数据
+func (m *Manager) switchWorkspace(name string) {
+	workspace, err := m.workspaces.SwitchWorkspace(name)
+	if err != nil {
+		m.showErrorDialog("切换工作区失败", err)
+		return
+	}
+
+	if m.autoApply != nil {
+		m.autoApply.Stop()
+	}
+	m.stopConfigWatch()
+	if err := m.openWorkspace(workspace); err != nil {
+		m.startConfigWatch()
+		m.showErrorDialog("切换工作区失败", err)
+		return
+	}
+	m.startConfigWatch()
+	m.applyTheme()
+
+	// 定期验证改为检查新工作区的hosts文件和备份，Profile快照、订阅刷新和资产清单同步改为针对新工作区的Profile
+	m.stopHealthCheck()
+	m.startHealthCheck()
+	m.stopSnapshots()
+	m.startSnapshots()
+	m.stopSubscriptions()
+	m.startSubscriptions()
+	m.stopInventory()
+	m.startInventory()
+
+	m.refreshHostEntryList()
+	if err := m.loadInitialData(); err != nil {
+		m.showErrorDialog("加载工作区数据失败", err)
+		return
+	}
+	m.updateProfileSelector()
+	m.offerHostnameMigration()
+	m.recordUsage(telemetry.EventSwitchWorkspace)
+}
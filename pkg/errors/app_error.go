@@ -1,6 +1,7 @@
 package errors
 
 import (
+	stderrors "errors"
 	"fmt"
 )
 
@@ -62,6 +63,11 @@ func (e *appError) Cause() error {
 	return e.cause
 }
 
+// Unwrap 支持errors.Is/errors.As检查原始错误
+func (e *appError) Unwrap() error {
+	return e.cause
+}
+
 // NewValidationError 创建验证错误
 func NewValidationError(code, message string, details map[string]interface{}) AppError {
 	return &appError{
@@ -81,6 +87,17 @@ func NewPermissionError(code, message string) AppError {
 	}
 }
 
+// NewSecurityError 创建带详情的安全校验错误（例如签名校验失败）
+func NewSecurityError(code, message string, details map[string]interface{}, cause error) AppError {
+	return &appError{
+		code:    code,
+		errType: ErrorTypePermission,
+		message: message,
+		details: details,
+		cause:   cause,
+	}
+}
+
 // NewFileSystemError 创建文件系统错误
 func NewFileSystemError(code, message string, cause error) AppError {
 	return &appError{
@@ -131,15 +148,26 @@ func WrapError(code string, errType ErrorType, message string, cause error) AppE
 	}
 }
 
+// NewError 创建指定类型的错误，用于还原跨进程传递的错误
+func NewError(code string, errType ErrorType, message string, details map[string]interface{}) AppError {
+	return &appError{
+		code:    code,
+		errType: errType,
+		message: message,
+		details: details,
+	}
+}
+
 // IsAppError 检查是否为AppError类型
 func IsAppError(err error) bool {
 	_, ok := err.(AppError)
 	return ok
 }
 
-// GetAppError 获取AppError，如果不是则返回nil
+// GetAppError 获取错误链中的AppError，如果没有则返回nil
 func GetAppError(err error) AppError {
-	if appErr, ok := err.(AppError); ok {
+	var appErr AppError
+	if stderrors.As(err, &appErr) {
 		return appErr
 	}
 	return nil
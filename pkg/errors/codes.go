@@ -30,6 +30,7 @@ const (
 	ErrCodeHelperVersionMismatch  = "HELPER_VERSION_MISMATCH"
 	ErrCodeHelperHealthCheckFailed = "HELPER_HEALTH_CHECK_FAILED"
 	ErrCodeHelperRestartFailed    = "HELPER_RESTART_FAILED"
+	ErrCodeHelperOperationFailed  = "HELPER_OPERATION_FAILED"
 
 	// 权限相关错误代码
 	ErrCodeInsufficientPrivileges = "INSUFFICIENT_PRIVILEGES"
@@ -48,6 +49,7 @@ const (
 	ErrCodeBackupCorrupted    = "BACKUP_CORRUPTED"
 	ErrCodeBackupIndexFailed  = "BACKUP_INDEX_FAILED"
 	ErrCodeBackupCleanupFailed = "BACKUP_CLEANUP_FAILED"
+	ErrCodeInsufficientSpace  = "INSUFFICIENT_SPACE"
 
 	// 安全相关错误代码
 	ErrCodeSecurityViolation  = "SECURITY_VIOLATION"
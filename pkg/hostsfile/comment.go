@@ -0,0 +1,174 @@
+package hostsfile
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// 结构化注释中的常用字段
+const (
+	MetaOwner   = "owner"   // 负责人
+	MetaTicket  = "ticket"  // 关联工单
+	MetaExpires = "expires" // 过期日期，格式见ExpiresLayout
+	MetaPinned  = "pinned"  // 通过DNS解析固定IP的日期，格式同ExpiresLayout
+)
+
+// ExpiresLayout expires字段的日期格式
+const ExpiresLayout = "2006-01-02"
+
+// metaKeyRegex 结构化字段名格式
+var metaKeyRegex = regexp.MustCompile(`^[a-z][a-z0-9_-]*$`)
+
+// metaKeyOrder 渲染时常用字段的顺序，其余字段按名称排序
+var metaKeyOrder = []string{MetaOwner, MetaTicket, MetaExpires}
+
+// CommentMetadata 结构化注释，例如 "staging api owner=alice ticket=OPS-123 expires=2025-07-01"；
+// key=value形式的片段解析为字段，其余文本作为说明保留
+type CommentMetadata struct {
+	Note   string            `json:"note,omitempty"`
+	Fields map[string]string `json:"fields,omitempty"`
+}
+
+// ParseComment 解析条目注释中的结构化字段
+func ParseComment(comment string) CommentMetadata {
+	var meta CommentMetadata
+	var note []string
+	for _, token := range strings.Fields(comment) {
+		key, value, ok := strings.Cut(token, "=")
+		if !ok || value == "" || !metaKeyRegex.MatchString(key) {
+			note = append(note, token)
+			continue
+		}
+		if meta.Fields == nil {
+			meta.Fields = make(map[string]string)
+		}
+		meta.Fields[key] = value
+	}
+	meta.Note = strings.Join(note, " ")
+	return meta
+}
+
+// String 渲染为注释文本：说明在前，字段在后；值中的空白会被替换为下划线以便再次解析
+func (c CommentMetadata) String() string {
+	parts := make([]string, 0, len(c.Fields)+1)
+	if note := strings.TrimSpace(c.Note); note != "" {
+		parts = append(parts, note)
+	}
+
+	keys := make([]string, 0, len(c.Fields))
+	for key := range c.Fields {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		ri, rj := metaKeyRank(keys[i]), metaKeyRank(keys[j])
+		if ri != rj {
+			return ri < rj
+		}
+		return keys[i] < keys[j]
+	})
+
+	for _, key := range keys {
+		value := strings.Join(strings.Fields(c.Fields[key]), "_")
+		if value == "" || !metaKeyRegex.MatchString(key) {
+			continue
+		}
+		parts = append(parts, key+"="+value)
+	}
+	return strings.Join(parts, " ")
+}
+
+// metaKeyRank 字段的排序优先级
+func metaKeyRank(key string) int {
+	for i, known := range metaKeyOrder {
+		if key == known {
+			return i
+		}
+	}
+	return len(metaKeyOrder)
+}
+
+// Set 设置字段，value为空时删除该字段
+func (c *CommentMetadata) Set(key, value string) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		delete(c.Fields, key)
+		return
+	}
+	if c.Fields == nil {
+		c.Fields = make(map[string]string)
+	}
+	c.Fields[key] = value
+}
+
+// Owner 负责人
+func (c CommentMetadata) Owner() string {
+	return c.Fields[MetaOwner]
+}
+
+// Ticket 关联工单
+func (c CommentMetadata) Ticket() string {
+	return c.Fields[MetaTicket]
+}
+
+// Expires 过期日期，未设置或格式无效时返回false
+func (c CommentMetadata) Expires() (time.Time, bool) {
+	value, ok := c.Fields[MetaExpires]
+	if !ok {
+		return time.Time{}, false
+	}
+	expires, err := time.ParseInLocation(ExpiresLayout, value, time.Local)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return expires, true
+}
+
+// Expired 在now时是否已过期，过期日期当天仍视为有效
+func (c CommentMetadata) Expired(now time.Time) bool {
+	expires, ok := c.Expires()
+	return ok && !now.Before(expires.AddDate(0, 0, 1))
+}
+
+// Pinned 通过DNS解析固定IP的日期，未设置或格式无效时返回false
+func (c CommentMetadata) Pinned() (time.Time, bool) {
+	value, ok := c.Fields[MetaPinned]
+	if !ok {
+		return time.Time{}, false
+	}
+	pinned, err := time.ParseInLocation(ExpiresLayout, value, time.Local)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return pinned, true
+}
+
+// HasFields 是否包含结构化字段
+func (c CommentMetadata) HasFields() bool {
+	return len(c.Fields) > 0
+}
+
+// ApplyCommentDefaults 为新条目的注释补充Profile的默认值：说明不以默认注释的说明开头时加在前面，
+// 默认注释中的字段和负责人只在注释没有设置时补充。默认值都为空时原样返回
+func ApplyCommentDefaults(comment, defaultComment, owner string) string {
+	defaults := ParseComment(defaultComment)
+	owner = strings.TrimSpace(owner)
+	if defaults.Note == "" && !defaults.HasFields() && owner == "" {
+		return comment
+	}
+
+	meta := ParseComment(comment)
+	if defaults.Note != "" && !strings.HasPrefix(meta.Note, defaults.Note) {
+		meta.Note = strings.TrimSpace(defaults.Note + " " + meta.Note)
+	}
+	for key, value := range defaults.Fields {
+		if _, ok := meta.Fields[key]; !ok {
+			meta.Set(key, value)
+		}
+	}
+	if owner != "" && meta.Owner() == "" {
+		meta.Set(MetaOwner, owner)
+	}
+	return meta.String()
+}
@@ -0,0 +1,77 @@
+package hostsfile
+
+// EntryChange 条目变化
+type EntryChange struct {
+	Expected Entry `json:"expected"` // Profile中的条目
+	Actual   Entry `json:"actual"`   // hosts文件中的条目
+}
+
+// Drift 管理section与Profile之间的差异
+type Drift struct {
+	Added   []Entry       `json:"added"`   // hosts文件中新增的条目
+	Changed []EntryChange `json:"changed"` // 主机名相同但内容不同的条目
+	Removed []Entry       `json:"removed"` // hosts文件中缺失的条目
+	// Modified 管理section的内容与头部的校验和不一致，即写入后被修改，即使条目本身没有差异（例如加入了注释行）
+	Modified bool `json:"modified,omitempty"`
+}
+
+// HasDrift 是否存在差异
+func (d *Drift) HasDrift() bool {
+	return len(d.Added) > 0 || len(d.Changed) > 0 || len(d.Removed) > 0 || d.Modified
+}
+
+// Imported 返回可合并回Profile的条目（新增与修改后的条目）
+func (d *Drift) Imported() []Entry {
+	entries := make([]Entry, 0, len(d.Added)+len(d.Changed))
+	entries = append(entries, d.Added...)
+	for _, change := range d.Changed {
+		entries = append(entries, change.Actual)
+	}
+	return entries
+}
+
+// DetectDrift 比较期望的条目与实际管理section中的条目，以主机名为键；期望中的禁用条目会被忽略
+func DetectDrift(expected, actual []Entry) *Drift {
+	drift := &Drift{}
+
+	expectedByHost := make(map[string]Entry, len(expected))
+	var order []string
+	for _, entry := range expected {
+		if !entry.Enabled {
+			continue
+		}
+		if _, exists := expectedByHost[entry.Hostname]; !exists {
+			order = append(order, entry.Hostname)
+		}
+		expectedByHost[entry.Hostname] = entry
+	}
+
+	seen := make(map[string]bool, len(actual))
+	for _, entry := range actual {
+		seen[entry.Hostname] = true
+		want, exists := expectedByHost[entry.Hostname]
+		switch {
+		case !exists:
+			drift.Added = append(drift.Added, entry)
+		case want.IP != entry.IP || want.Comment != entry.Comment:
+			drift.Changed = append(drift.Changed, EntryChange{Expected: want, Actual: entry})
+		}
+	}
+
+	for _, hostname := range order {
+		if !seen[hostname] {
+			drift.Removed = append(drift.Removed, expectedByHost[hostname])
+		}
+	}
+
+	return drift
+}
+
+// DetectSectionDrift 比较期望的条目与管理section内部的行（见ExtractManagedSection），
+// 头部带有校验和时同时检查section写入后是否被修改
+func DetectSectionDrift(expected []Entry, managed []string) *Drift {
+	header, body := ParseSectionHeader(managed)
+	drift := DetectDrift(expected, Parse(body))
+	drift.Modified = header.Check(body) == SectionModified
+	return drift
+}
@@ -0,0 +1,151 @@
+package hostsfile
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+)
+
+// 管理section头部的字段名，每行为"# 字段: 值"
+const (
+	HeaderProfile   = "Profile"
+	HeaderProfileID = "Profile ID"
+	HeaderAuthor    = "Author"
+	HeaderAppliedAt = "Applied at"
+	HeaderVersion   = "mHost version"
+	HeaderChecksum  = "Checksum"
+)
+
+// checksumPrefix 校验和的算法前缀
+const checksumPrefix = "sha256:"
+
+// headerLineRegex 头部字段行，条目行（包括被注释的条目）以IP开头，不会匹配
+var headerLineRegex = regexp.MustCompile(`^#\s*([A-Za-z][A-Za-z ]*[A-Za-z]):\s(.*)$`)
+
+// SectionHeader 管理section开头的注释信息。ProfileID、Version和Checksum供其他mHost安装识别和接管section
+type SectionHeader struct {
+	Profile   string
+	ProfileID string
+	Author    string
+	AppliedAt time.Time
+	Version   string
+	// Checksum 写入时section中条目行的校验和，用于发现写入后的手动修改
+	Checksum string
+}
+
+// SectionState 管理section内容与头部校验和的比较结果
+type SectionState int
+
+const (
+	// SectionUnverified 头部没有校验和，例如旧版本或Helper写入的section
+	SectionUnverified SectionState = iota
+	// SectionIntact 内容与校验和一致
+	SectionIntact
+	// SectionModified 写入后被修改
+	SectionModified
+)
+
+// String 状态名称
+func (s SectionState) String() string {
+	switch s {
+	case SectionIntact:
+		return "intact"
+	case SectionModified:
+		return "modified"
+	default:
+		return "unverified"
+	}
+}
+
+// Lines 渲染为注释行，为空的字段不输出
+func (h SectionHeader) Lines() []string {
+	lines := []string{headerLine(HeaderProfile, h.Profile)}
+	if h.ProfileID != "" {
+		lines = append(lines, headerLine(HeaderProfileID, h.ProfileID))
+	}
+	if h.Author != "" {
+		lines = append(lines, headerLine(HeaderAuthor, h.Author))
+	}
+	lines = append(lines, headerLine(HeaderAppliedAt, h.AppliedAt.Format(time.RFC3339)))
+	if h.Version != "" {
+		lines = append(lines, headerLine(HeaderVersion, h.Version))
+	}
+	if h.Checksum != "" {
+		lines = append(lines, headerLine(HeaderChecksum, h.Checksum))
+	}
+	return lines
+}
+
+// headerLine 渲染头部字段行
+func headerLine(key, value string) string {
+	return fmt.Sprintf("# %s: %s", key, value)
+}
+
+// Build 构建带校验和的管理section（含前导空行与起止标记），无条目时返回nil
+func (h SectionHeader) Build(entries []Entry) []string {
+	h.Checksum = ""
+	return BuildSignedSection(h.Lines(), entries)
+}
+
+// BuildSignedSection 构建管理section，在头部末尾加入条目行的校验和，无条目时返回nil
+func BuildSignedSection(header []string, entries []Entry) []string {
+	checksum := headerLine(HeaderChecksum, SectionChecksum(RenderEntries(entries, false)))
+	return BuildManagedSection(append(slices.Clip(header), checksum), entries)
+}
+
+// Check 比较section中条目行与头部的校验和
+func (h SectionHeader) Check(body []string) SectionState {
+	if h.Checksum == "" {
+		return SectionUnverified
+	}
+	if SectionChecksum(body) != h.Checksum {
+		return SectionModified
+	}
+	return SectionIntact
+}
+
+// SectionChecksum 计算条目行的校验和，忽略行尾空白和空行
+func SectionChecksum(body []string) string {
+	hash := sha256.New()
+	for _, line := range body {
+		line = strings.TrimRight(line, " \t\r")
+		if line == "" {
+			continue
+		}
+		hash.Write([]byte(line))
+		hash.Write([]byte{'\n'})
+	}
+	return checksumPrefix + hex.EncodeToString(hash.Sum(nil))
+}
+
+// ParseSectionHeader 解析管理section内部的行（见ExtractManagedSection），返回头部以及其后的条目行。
+// 头部为section开头连续的"# 字段: 值"行，无法识别的字段（例如Helper写入的更新时间）被跳过
+func ParseSectionHeader(managed []string) (SectionHeader, []string) {
+	var header SectionHeader
+	for i, line := range managed {
+		match := headerLineRegex.FindStringSubmatch(strings.TrimSpace(line))
+		if match == nil {
+			return header, managed[i:]
+		}
+		value := strings.TrimSpace(match[2])
+		switch match[1] {
+		case HeaderProfile:
+			header.Profile = value
+		case HeaderProfileID:
+			header.ProfileID = value
+		case HeaderAuthor:
+			header.Author = value
+		case HeaderAppliedAt:
+			header.AppliedAt, _ = time.Parse(time.RFC3339, value)
+		case HeaderVersion:
+			header.Version = value
+		case HeaderChecksum:
+			header.Checksum = value
+		}
+	}
+	return header, nil
+}
@@ -0,0 +1,162 @@
+// Package hostsfile 提供hosts文件的解析、渲染、管理section处理和验证，
+// 供GUI侧的host.Manager与Helper Tool共同使用。
+package hostsfile
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/flyhigher139/mhost/pkg/models"
+)
+
+// Entry hosts文件条目
+type Entry struct {
+	IP       string `json:"ip"`
+	Hostname string `json:"hostname"`
+	Comment  string `json:"comment,omitempty"`
+	Enabled  bool   `json:"enabled"`
+}
+
+// Line 解析后的单行内容
+type Line struct {
+	Raw       string   // 原始文本
+	IP        string   // IP地址
+	Hostnames []string // 主机名列表
+	Comment   string   // 行尾注释
+	Disabled  bool     // 是否为被注释掉的条目
+}
+
+// FromModel 将models.HostEntry转换为Entry
+func FromModel(entry *models.HostEntry) Entry {
+	return Entry{
+		IP:       entry.IP,
+		Hostname: entry.Hostname,
+		Comment:  entry.Comment,
+		Enabled:  entry.Enabled,
+	}
+}
+
+// FromModels 批量转换models.HostEntry
+func FromModels(entries []*models.HostEntry) []Entry {
+	result := make([]Entry, 0, len(entries))
+	for _, entry := range entries {
+		if entry == nil {
+			continue
+		}
+		result = append(result, FromModel(entry))
+	}
+	return result
+}
+
+// ToModel 将Entry转换为新的models.HostEntry
+func (e Entry) ToModel() *models.HostEntry {
+	entry := models.NewHostEntry(e.IP, e.Hostname, e.Comment)
+	entry.Enabled = e.Enabled
+	return entry
+}
+
+// ParseLine 解析单行hosts内容，空行和普通注释返回false
+func ParseLine(raw string) (*Line, bool) {
+	text := strings.TrimSpace(raw)
+	if text == "" {
+		return nil, false
+	}
+
+	disabled := false
+	if strings.HasPrefix(text, "#") {
+		// 尝试识别被注释掉的条目，例如 "# 10.0.0.1 api.local"
+		text = strings.TrimSpace(strings.TrimLeft(text, "#"))
+		disabled = true
+	}
+
+	hostPart := text
+	comment := ""
+	if idx := strings.Index(text, "#"); idx >= 0 {
+		hostPart = strings.TrimSpace(text[:idx])
+		comment = strings.TrimSpace(text[idx+1:])
+	}
+
+	fields := strings.Fields(hostPart)
+	if len(fields) < 2 {
+		return nil, false
+	}
+
+	if disabled && ValidateIP(fields[0]) != nil {
+		// 普通注释，不是被禁用的条目
+		return nil, false
+	}
+
+	return &Line{
+		Raw:       raw,
+		IP:        fields[0],
+		Hostnames: fields[1:],
+		Comment:   comment,
+		Disabled:  disabled,
+	}, true
+}
+
+// SplitLines 将hosts文件内容拆分为行：去掉行尾的\r，末尾换行不产生空行，单行长度不受限制
+func SplitLines(content string) []string {
+	if content == "" {
+		return nil
+	}
+	lines := strings.Split(strings.TrimSuffix(content, "\n"), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, "\r")
+	}
+	return lines
+}
+
+// Parse 解析hosts内容为条目列表，跳过注释行；一行多个主机名会展开为多个条目
+func Parse(lines []string) []Entry {
+	return parse(lines, false)
+}
+
+// ParseWithDisabled 解析hosts内容，被注释掉的条目以禁用状态返回
+func ParseWithDisabled(lines []string) []Entry {
+	return parse(lines, true)
+}
+
+// parse 内部解析方法
+func parse(lines []string, includeDisabled bool) []Entry {
+	var entries []Entry
+	for _, raw := range lines {
+		line, ok := ParseLine(raw)
+		if !ok || (line.Disabled && !includeDisabled) {
+			continue
+		}
+		for _, hostname := range line.Hostnames {
+			entries = append(entries, Entry{
+				IP:       line.IP,
+				Hostname: hostname,
+				Comment:  line.Comment,
+				Enabled:  !line.Disabled,
+			})
+		}
+	}
+	return entries
+}
+
+// RenderEntry 渲染单个条目为规范格式 "IP\thostname\t# comment"
+func RenderEntry(entry Entry) string {
+	line := fmt.Sprintf("%s\t%s", entry.IP, entry.Hostname)
+	if entry.Comment != "" {
+		line += fmt.Sprintf("\t# %s", entry.Comment)
+	}
+	return line
+}
+
+// RenderEntries 渲染条目列表；includeDisabled为true时禁用条目以注释形式输出，否则跳过
+func RenderEntries(entries []Entry, includeDisabled bool) []string {
+	lines := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		if !entry.Enabled {
+			if includeDisabled {
+				lines = append(lines, "# "+RenderEntry(entry))
+			}
+			continue
+		}
+		lines = append(lines, RenderEntry(entry))
+	}
+	return lines
+}
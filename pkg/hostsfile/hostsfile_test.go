@@ -0,0 +1,374 @@
+package hostsfile
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/flyhigher139/mhost/pkg/models"
+)
+
+// TestParse 测试解析hosts内容
+func TestParse(t *testing.T) {
+	lines := []string{
+		"# comment line",
+		"127.0.0.1\tlocalhost local",
+		"# 10.0.0.1\tapi.test\t# staging",
+		"",
+		"192.168.1.1 example.com # dev",
+	}
+
+	entries := Parse(lines)
+	require.Len(t, entries, 3)
+	assert.Equal(t, Entry{IP: "127.0.0.1", Hostname: "local", Enabled: true}, entries[1])
+	assert.Equal(t, Entry{IP: "192.168.1.1", Hostname: "example.com", Comment: "dev", Enabled: true}, entries[2])
+
+	all := ParseWithDisabled(lines)
+	require.Len(t, all, 4)
+	assert.Equal(t, Entry{IP: "10.0.0.1", Hostname: "api.test", Comment: "staging", Enabled: false}, all[2])
+}
+
+// TestSplitLines 测试拆分hosts文件内容
+func TestSplitLines(t *testing.T) {
+	assert.Nil(t, SplitLines(""))
+	assert.Equal(t, []string{""}, SplitLines("\n"))
+	assert.Equal(t, []string{"127.0.0.1 localhost", "", "::1 localhost"}, SplitLines("127.0.0.1 localhost\r\n\r\n::1 localhost"))
+	assert.Equal(t, []string{"a", ""}, SplitLines("a\n\n"))
+}
+
+// TestRenderEntries 测试渲染条目
+func TestRenderEntries(t *testing.T) {
+	entries := []Entry{
+		{IP: "10.0.0.1", Hostname: "api.test", Comment: "staging", Enabled: true},
+		{IP: "10.0.0.2", Hostname: "web.test", Enabled: false},
+	}
+
+	assert.Equal(t, []string{"10.0.0.1\tapi.test\t# staging", "# 10.0.0.2\tweb.test"}, RenderEntries(entries, true))
+	assert.Equal(t, []string{"10.0.0.1\tapi.test\t# staging"}, RenderEntries(entries, false))
+
+	// 渲染结果可以被重新解析
+	assert.Equal(t, entries, ParseWithDisabled(RenderEntries(entries, true)))
+}
+
+// TestManagedSection 测试管理section的替换与提取
+func TestManagedSection(t *testing.T) {
+	lines := []string{"127.0.0.1\tlocalhost"}
+	section := BuildManagedSection([]string{"# Profile: dev"}, []Entry{{IP: "10.0.0.1", Hostname: "api.test", Enabled: true}})
+
+	updated := ReplaceManagedSection(lines, section)
+	assert.True(t, HasManagedSection(updated))
+	assert.Equal(t, []string{"# Profile: dev", "10.0.0.1\tapi.test"}, ExtractManagedSection(updated))
+
+	// 再次替换不会累积空行或重复section
+	assert.Equal(t, updated, ReplaceManagedSection(updated, section))
+
+	// 空section移除管理部分，保留原始内容
+	assert.Equal(t, lines, ReplaceManagedSection(updated, BuildManagedSection(nil, nil)))
+}
+
+// TestValidate 测试验证
+func TestValidate(t *testing.T) {
+	assert.NoError(t, ValidateEntry(Entry{IP: "::1", Hostname: "localhost"}))
+	assert.Error(t, ValidateEntry(Entry{IP: "999.0.0.1", Hostname: "bad.test"}))
+	assert.Error(t, ValidateEntry(Entry{IP: "10.0.0.1", Hostname: "invalid..hostname"}))
+	assert.Error(t, ValidateEntry(Entry{IP: "10.0.0.1", Hostname: "ok.test", Comment: "a\nb"}))
+
+	assert.NoError(t, ValidateLines([]string{"# comment", "127.0.0.1 localhost # local"}))
+	assert.EqualError(t, ValidateLines([]string{"127.0.0.1 localhost", "invalid.ip.address example.com"}), "invalid IP address at line 2: invalid.ip.address")
+	assert.EqualError(t, ValidateLines([]string{"127.0.0.1"}), "invalid hosts entry at line 1: 127.0.0.1")
+}
+
+// TestCheck 测试完整校验报告中的错误与警告
+func TestCheck(t *testing.T) {
+	report := Check([]string{
+		"127.0.0.1\tlocalhost",
+		"::1\tlocalhost",
+		"10.0.0.1\tapi.test",
+		"10.0.0.2 api.test",
+		"10.0.0.3\tprinter.local",
+		"10.0.0.4\tweb.test \tadmin.test",
+		"bad-ip\tbad.test",
+		"10.0.0.5\tbad..host",
+		"127.0.0.1",
+		StartMarker,
+		"10.0.0.6\tmanaged.test",
+	})
+
+	var codes []string
+	for _, issue := range report.Issues {
+		codes = append(codes, fmt.Sprintf("%d:%s:%s", issue.Line, issue.Severity, issue.Code))
+	}
+	assert.Equal(t, []string{
+		"4:warning:" + IssueDuplicateHost,
+		"5:warning:" + IssueUnusualTLD,
+		"6:warning:" + IssueMixedWhitespace,
+		"7:error:" + IssueInvalidIP,
+		"8:error:" + IssueInvalidHostname,
+		"9:error:" + IssueInvalidEntry,
+		"0:warning:" + IssueUnclosedSection,
+	}, codes)
+	assert.True(t, report.HasErrors())
+	assert.Len(t, report.Errors(), 3)
+	assert.Len(t, report.Warnings(), 4)
+	assert.Equal(t, "line 7: invalid IP address: bad-ip", report.Errors()[0].String())
+
+	assert.False(t, Check([]string{"# comment", "127.0.0.1 localhost"}).HasErrors())
+}
+
+// TestNormalize 测试主机名规范化
+func TestNormalize(t *testing.T) {
+	assert.Equal(t, "api.test", NormalizeHostname(" API.Test.. "))
+
+	hostname, err := DefaultNormalizer.Normalize("Web.Test.")
+	assert.NoError(t, err)
+	assert.Equal(t, "web.test", hostname)
+
+	_, err = DefaultNormalizer.Normalize("my_service.test")
+	assert.ErrorIs(t, err, ErrUnderscoreHostname)
+	hostname, err = Normalizer{AllowUnderscores: true}.Normalize("My_Service.test")
+	assert.NoError(t, err)
+	assert.Equal(t, "my_service.test", hostname)
+
+	_, err = DefaultNormalizer.Normalize("bad..host")
+	assert.Error(t, err)
+
+	entries := []*models.HostEntry{
+		models.NewHostEntry("10.0.0.1", "API.test.", ""),
+		models.NewHostEntry("10.0.0.2", "web.test", ""),
+	}
+	assert.Equal(t, 1, CountUnnormalized(entries))
+	assert.Equal(t, 1, NormalizeEntries(entries))
+	assert.Equal(t, "api.test", entries[0].Hostname)
+	assert.Equal(t, 0, CountUnnormalized(entries))
+
+	report := Check([]string{"10.0.0.1	My_Host.test"})
+	require.Len(t, report.Issues, 2)
+	assert.Equal(t, IssueUnderscore, report.Issues[0].Code)
+	assert.Equal(t, IssueNotNormalized, report.Issues[1].Code)
+	assert.False(t, report.HasErrors())
+}
+
+// TestParseComment 测试结构化注释的解析与渲染
+func TestParseComment(t *testing.T) {
+	meta := ParseComment("staging api owner=alice ticket=OPS-123 expires=2025-07-01 team=web")
+	assert.Equal(t, "staging api", meta.Note)
+	assert.Equal(t, "alice", meta.Owner())
+	assert.Equal(t, "OPS-123", meta.Ticket())
+	expires, ok := meta.Expires()
+	require.True(t, ok)
+	assert.Equal(t, "2025-07-01", expires.Format(ExpiresLayout))
+	assert.False(t, meta.Expired(time.Date(2025, 7, 1, 23, 0, 0, 0, time.Local)))
+	assert.True(t, meta.Expired(time.Date(2025, 7, 2, 0, 0, 0, 0, time.Local)))
+
+	// 渲染后写入hosts文件再解析，字段保持不变
+	meta.Set(MetaOwner, "bob smith")
+	meta.Set(MetaTicket, "")
+	comment := meta.String()
+	assert.Equal(t, "staging api owner=bob_smith expires=2025-07-01 team=web", comment)
+	entries := Parse([]string{RenderEntry(Entry{IP: "10.0.0.1", Hostname: "api.test", Comment: comment, Enabled: true})})
+	require.Len(t, entries, 1)
+	assert.Equal(t, ParseComment(comment), ParseComment(entries[0].Comment))
+
+	plain := ParseComment("see a=b docs, x=")
+	assert.Equal(t, "see docs, x=", plain.Note)
+	assert.Equal(t, map[string]string{"a": "b"}, plain.Fields)
+	_, ok = plain.Expires()
+	assert.False(t, ok)
+	assert.False(t, ParseComment("expires=soon").Expired(time.Now()))
+}
+
+// TestApplyCommentDefaults 测试新条目注释的默认值
+func TestApplyCommentDefaults(t *testing.T) {
+	assert.Equal(t, " keep  spacing ", ApplyCommentDefaults(" keep  spacing ", "", ""))
+	assert.Equal(t, "[infra] api owner=alice team=web", ApplyCommentDefaults("api", "[infra] team=web", "alice"))
+	assert.Equal(t, "[infra] owner=alice", ApplyCommentDefaults("", "[infra]", "alice"))
+
+	// 已有的前缀、字段和负责人不重复添加
+	comment := ApplyCommentDefaults("api owner=bob team=db", "[infra] team=web", "alice")
+	assert.Equal(t, "[infra] api owner=bob team=db", comment)
+	assert.Equal(t, comment, ApplyCommentDefaults(comment, "[infra] team=web", "alice"))
+	assert.Equal(t, "api owner=alice_smith", ApplyCommentDefaults("api", "", " alice smith "))
+}
+
+// TestDetectDrift 测试差异检测
+func TestDetectDrift(t *testing.T) {
+	expected := []Entry{
+		{IP: "10.0.0.1", Hostname: "api.test", Enabled: true},
+		{IP: "10.0.0.2", Hostname: "web.test", Enabled: true},
+		{IP: "10.0.0.3", Hostname: "off.test", Enabled: false},
+	}
+	actual := []Entry{
+		{IP: "10.0.0.9", Hostname: "api.test", Enabled: true},
+		{IP: "10.0.0.4", Hostname: "new.test", Enabled: true},
+	}
+
+	drift := DetectDrift(expected, actual)
+	assert.True(t, drift.HasDrift())
+	assert.Equal(t, []Entry{actual[1]}, drift.Added)
+	assert.Equal(t, []EntryChange{{Expected: expected[0], Actual: actual[0]}}, drift.Changed)
+	assert.Equal(t, []Entry{expected[1]}, drift.Removed)
+	assert.Equal(t, []Entry{actual[1], actual[0]}, drift.Imported())
+
+	assert.False(t, DetectDrift(expected[:2], expected[:2]).HasDrift())
+}
+
+// TestPatchEntry 测试就地更新单个条目
+func TestPatchEntry(t *testing.T) {
+	_, err := PatchEntry([]string{"127.0.0.1\tlocalhost"}, Entry{IP: "10.0.0.1", Hostname: "api.test", Enabled: true})
+	assert.Equal(t, ErrNoManagedSection, err)
+
+	lines := ReplaceManagedSection([]string{"127.0.0.1\tlocalhost"}, BuildManagedSection(nil, []Entry{
+		{IP: "10.0.0.1", Hostname: "api.test", Enabled: true},
+		{IP: "10.0.0.2", Hostname: "web.test", Enabled: true},
+	}))
+
+	// 禁用条目会从section中移除
+	patched, err := PatchEntry(lines, Entry{IP: "10.0.0.1", Hostname: "api.test", Enabled: false})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"10.0.0.2\tweb.test"}, ExtractManagedSection(patched))
+
+	// 重新启用的条目追加到section末尾
+	patched, err = PatchEntry(patched, Entry{IP: "10.0.0.1", Hostname: "api.test", Enabled: true})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"10.0.0.2\tweb.test", "10.0.0.1\tapi.test"}, ExtractManagedSection(patched))
+	assert.Equal(t, "127.0.0.1\tlocalhost", patched[0])
+
+	// 已存在的条目就地替换
+	patched, err = PatchEntry(patched, Entry{IP: "10.0.0.9", Hostname: "web.test", Enabled: true})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"10.0.0.9\tweb.test", "10.0.0.1\tapi.test"}, ExtractManagedSection(patched))
+
+	// 同一主机名的IPv4和IPv6条目分别匹配
+	lines = ReplaceManagedSection(nil, BuildManagedSection(nil, []Entry{
+		{IP: "127.0.0.1", Hostname: "app.local", Enabled: true},
+		{IP: "::1", Hostname: "app.local", Enabled: true},
+	}))
+	patched, err = PatchEntry(lines, Entry{IP: "::1", Hostname: "app.local", Enabled: false})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"127.0.0.1\tapp.local"}, ExtractManagedSection(patched))
+
+	patched, err = PatchEntry(lines, Entry{IP: "fe80::1", Hostname: "app.local", Enabled: true})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"127.0.0.1\tapp.local", "fe80::1\tapp.local"}, ExtractManagedSection(patched))
+
+	patched, err = PatchEntry(patched, Entry{IP: "127.0.0.1", Hostname: "app.local", Enabled: false})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"fe80::1\tapp.local"}, ExtractManagedSection(patched))
+}
+
+// TestSectionHeader 测试管理section头部的渲染、解析与校验和
+func TestSectionHeader(t *testing.T) {
+	appliedAt := time.Date(2025, 3, 1, 8, 0, 0, 0, time.UTC)
+	header := SectionHeader{Profile: "Dev", ProfileID: "p1+p2", Author: "alice", AppliedAt: appliedAt, Version: "1.2.0"}
+	entries := []Entry{
+		{IP: "10.0.0.1", Hostname: "api.test", Enabled: true},
+		{IP: "10.0.0.2", Hostname: "off.test", Enabled: false},
+	}
+	lines := ReplaceManagedSection([]string{"127.0.0.1\tlocalhost"}, header.Build(entries))
+
+	managed := ExtractManagedSection(lines)
+	parsed, body := ParseSectionHeader(managed)
+	assert.Equal(t, []string{"10.0.0.1\tapi.test"}, body)
+	assert.Equal(t, SectionIntact, parsed.Check(body))
+	parsed.Checksum = ""
+	assert.Equal(t, header, parsed)
+	assert.False(t, DetectSectionDrift(entries, managed).HasDrift())
+
+	// 就地更新条目时校验和随之更新
+	patched, err := PatchEntry(lines, Entry{IP: "10.0.0.3", Hostname: "web.test", Enabled: true})
+	require.NoError(t, err)
+	parsed, body = ParseSectionHeader(ExtractManagedSection(patched))
+	assert.Equal(t, SectionIntact, parsed.Check(body))
+	assert.Equal(t, "Dev", parsed.Profile)
+
+	// 写入后加入的注释行不影响条目，但会被识别为修改
+	edited := append(slices.Clone(managed), "# temporary override")
+	drift := DetectSectionDrift(entries, edited)
+	assert.Empty(t, drift.Added)
+	assert.True(t, drift.Modified)
+	assert.True(t, drift.HasDrift())
+
+	// 没有校验和的旧版本section
+	parsed, body = ParseSectionHeader([]string{"# Updated by helper at: 2025-03-01T08:00:00Z", "10.0.0.1\tapi.test"})
+	assert.Equal(t, SectionUnverified, parsed.Check(body))
+	assert.Empty(t, parsed.ProfileID)
+	assert.Len(t, body, 1)
+}
+
+// TestDiffLines 测试逐行差异及其统一差异格式的渲染
+func TestDiffLines(t *testing.T) {
+	before := []string{"127.0.0.1\tlocalhost", "# a", "# b", "10.0.0.1\tapi.test", "# c", "# d", "# e"}
+	after := []string{"127.0.0.1\tlocalhost", "# a", "# b", "10.0.0.2\tapi.test", "10.0.0.3\tweb.test", "# c", "# d", "# e"}
+
+	changes := DiffLines(before, after)
+	assert.Len(t, changes, 9)
+	added, removed := LineDiffStats(changes)
+	assert.Equal(t, 2, added)
+	assert.Equal(t, 1, removed)
+	assert.Equal(t, []string{
+		"@@ 1 unchanged lines @@",
+		"  # a",
+		"  # b",
+		"- 10.0.0.1\tapi.test",
+		"+ 10.0.0.2\tapi.test",
+		"+ 10.0.0.3\tweb.test",
+		"  # c",
+		"  # d",
+		"@@ 1 unchanged lines @@",
+	}, FormatLineDiff(changes, 2))
+
+	// 中间部分按最长公共子序列对齐
+	changes = DiffLines([]string{"a", "b", "c", "d"}, []string{"b", "x", "d", "e"})
+	assert.Equal(t, []LineChange{
+		{Op: LineRemoved, Text: "a"},
+		{Op: LineEqual, Text: "b"},
+		{Op: LineRemoved, Text: "c"},
+		{Op: LineAdded, Text: "x"},
+		{Op: LineEqual, Text: "d"},
+		{Op: LineAdded, Text: "e"},
+	}, changes)
+
+	assert.Nil(t, FormatLineDiff(DiffLines(before, before), 2))
+	assert.Equal(t, []string{"+ a"}, FormatLineDiff(DiffLines(nil, []string{"a"}), 2))
+}
+
+// FuzzParse 测试任意hosts内容的解析、检查和管理section替换不会panic，且有效条目可以渲染后原样解析
+func FuzzParse(f *testing.F) {
+	f.Add("127.0.0.1\tlocalhost\n::1 localhost # loopback\n")
+	f.Add("# 10.0.0.1\tapi.test\t# staging\n\n192.168.1.1 a.test b.test")
+	f.Add(StartMarker + "\n10.0.0.1\tapi.test\n" + EndMarker + "\n" + EndMarker + "\n" + StartMarker)
+	f.Add("10.0.0.1 api.test # owner=alice expires=2025-07-01\r\n#\t#\n##10.0.0.2")
+	f.Add("10.0.0.1 " + strings.Repeat("a", 300) + "\n")
+
+	f.Fuzz(func(t *testing.T, content string) {
+		lines := strings.Split(content, "\n")
+
+		entries := ParseWithDisabled(lines)
+		Check(lines)
+		_ = ValidateLines(lines)
+
+		for _, entry := range entries {
+			if ValidateEntry(entry) != nil {
+				continue
+			}
+			line, ok := ParseLine(RenderEntry(entry))
+			require.True(t, ok, "rendered entry %q does not parse", RenderEntry(entry))
+			assert.Equal(t, entry.IP, line.IP)
+			assert.Equal(t, []string{entry.Hostname}, line.Hostnames)
+			assert.Equal(t, entry.Comment, line.Comment)
+			meta := ParseComment(entry.Comment).String()
+			assert.Equal(t, meta, ParseComment(meta).String())
+		}
+
+		// 替换后的管理section只包含新写入的条目，再次移除后与原有的非管理部分一致
+		written := []Entry{{IP: "10.0.0.1", Hostname: "api.test", Enabled: true}}
+		replaced := ReplaceManagedSection(lines, BuildManagedSection([]string{"# header"}, written))
+		assert.Equal(t, written, Parse(ExtractManagedSection(replaced)))
+		assert.Equal(t, strings.Join(RemoveManagedSection(lines), "\n"), strings.Join(RemoveManagedSection(replaced), "\n"))
+	})
+}
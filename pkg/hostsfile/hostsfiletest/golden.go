@@ -0,0 +1,189 @@
+// Package hostsfiletest 提供基于golden文件的回归测试：输入hosts文件 + Profile → 期望的hosts文件。
+//
+// 每个用例是pkg/hostsfile/testdata/golden下的一个目录，包含input.hosts、profile.json和expected.hosts。
+// 运行测试时加上 -update-golden 可以用实际输出重写expected.hosts。
+package hostsfiletest
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/flyhigher139/mhost/pkg/hostsfile"
+	"github.com/flyhigher139/mhost/pkg/models"
+)
+
+const (
+	// InputFile 用例的初始hosts文件
+	InputFile = "input.hosts"
+	// ProfileFile 用例要应用的Profile
+	ProfileFile = "profile.json"
+	// ExpectedFile 应用后期望的hosts文件
+	ExpectedFile = "expected.hosts"
+)
+
+var update = flag.Bool("update-golden", false, "rewrite golden expected.hosts files with the actual output")
+
+// volatileHeaders 管理section中每次写入都会变化的注释行前缀，比较前去掉
+var volatileHeaders = []string{
+	"# Profile:",
+	"# Applied at:",
+	"# mHost version:",
+	"# Updated by helper at:",
+}
+
+// Case 一个golden用例
+type Case struct {
+	Name     string
+	Dir      string
+	Input    []byte
+	Profile  *models.Profile
+	Expected []byte
+}
+
+// Entries 用例Profile解析后要写入的条目
+func (c Case) Entries() []hostsfile.Entry {
+	return hostsfile.FromModels(c.Profile.ResolvedEntries())
+}
+
+// Dir 仓库中golden用例的根目录
+func Dir() string {
+	_, file, _, _ := runtime.Caller(0)
+	return filepath.Join(filepath.Dir(file), "..", "testdata", "golden")
+}
+
+// LoadCases 加载目录下的所有用例，按名称排序；缺少expected.hosts时Expected为nil
+func LoadCases(dir string) ([]Case, error) {
+	dirEntries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read golden directory: %w", err)
+	}
+
+	var cases []Case
+	for _, dirEntry := range dirEntries {
+		if !dirEntry.IsDir() {
+			continue
+		}
+		c, err := loadCase(filepath.Join(dir, dirEntry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("golden case %s: %w", dirEntry.Name(), err)
+		}
+		cases = append(cases, c)
+	}
+	sort.Slice(cases, func(i, j int) bool { return cases[i].Name < cases[j].Name })
+	return cases, nil
+}
+
+// loadCase 加载单个用例目录
+func loadCase(dir string) (Case, error) {
+	c := Case{Name: filepath.Base(dir), Dir: dir}
+
+	input, err := os.ReadFile(filepath.Join(dir, InputFile))
+	if err != nil {
+		return c, err
+	}
+	c.Input = input
+
+	data, err := os.ReadFile(filepath.Join(dir, ProfileFile))
+	if err != nil {
+		return c, err
+	}
+	c.Profile = &models.Profile{}
+	if err := json.Unmarshal(data, c.Profile); err != nil {
+		return c, fmt.Errorf("invalid %s: %w", ProfileFile, err)
+	}
+
+	expected, err := os.ReadFile(filepath.Join(dir, ExpectedFile))
+	if err != nil && !os.IsNotExist(err) {
+		return c, err
+	}
+	c.Expected = expected
+	return c, nil
+}
+
+// Normalize 去掉管理section中的时间戳等易变注释行，使不同写入方的输出可以直接比较
+func Normalize(content string) string {
+	lines := strings.SplitAfter(content, "\n")
+	result := make([]string, 0, len(lines))
+	inManaged := false
+	for _, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case strings.Contains(trimmed, hostsfile.StartMarker):
+			inManaged = true
+		case strings.Contains(trimmed, hostsfile.EndMarker):
+			inManaged = false
+		case inManaged && isVolatile(trimmed):
+			continue
+		}
+		result = append(result, line)
+	}
+	return strings.Join(result, "")
+}
+
+// isVolatile 是否为易变的注释行
+func isVolatile(line string) bool {
+	for _, prefix := range volatileHeaders {
+		if strings.HasPrefix(line, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// Run 为每个用例创建包含input.hosts的临时hosts文件，调用apply写入后与expected.hosts比较
+func Run(t *testing.T, apply func(t *testing.T, c Case, hostsPath string)) {
+	t.Helper()
+
+	cases, err := LoadCases(Dir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cases) == 0 {
+		t.Fatalf("no golden cases in %s", Dir())
+	}
+
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			hostsPath := filepath.Join(t.TempDir(), "hosts")
+			if err := os.WriteFile(hostsPath, c.Input, 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			apply(t, c, hostsPath)
+
+			data, err := os.ReadFile(hostsPath)
+			if err != nil {
+				t.Fatal(err)
+			}
+			c.Check(t, string(data))
+		})
+	}
+}
+
+// Check 比较实际输出与expected.hosts；使用 -update-golden 时改为重写expected.hosts
+func (c Case) Check(t *testing.T, actual string) {
+	t.Helper()
+
+	actual = Normalize(actual)
+	if *update {
+		if err := os.WriteFile(filepath.Join(c.Dir, ExpectedFile), []byte(actual), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	if c.Expected == nil {
+		t.Fatalf("missing %s, run the tests with -update-golden to create it", filepath.Join(c.Dir, ExpectedFile))
+	}
+
+	expected := Normalize(string(c.Expected))
+	if actual != expected {
+		t.Errorf("output does not match %s\n--- expected\n%s--- actual\n%s", filepath.Join(c.Dir, ExpectedFile), expected, actual)
+	}
+}
@@ -0,0 +1,145 @@
+package hostsfile
+
+import "fmt"
+
+// maxLineDiffCells 逐行差异中最长公共子序列表格的最大单元数，超过时将不同的部分整体作为删除和新增，避免大文件占用过多内存
+const maxLineDiffCells = 4 << 20
+
+// LineOp 逐行差异中一行的操作
+type LineOp string
+
+const (
+	// LineEqual 两边相同的行
+	LineEqual LineOp = " "
+	// LineAdded 新增的行
+	LineAdded LineOp = "+"
+	// LineRemoved 删除的行
+	LineRemoved LineOp = "-"
+)
+
+// LineChange 逐行差异中的一行
+type LineChange struct {
+	Op   LineOp `json:"op"`
+	Text string `json:"text"`
+}
+
+// DiffLines 计算由before得到after的逐行差异。先去掉相同的开头和结尾（应用Profile时通常只有管理section变化），
+// 其余部分按最长公共子序列对齐
+func DiffLines(before, after []string) []LineChange {
+	prefix := 0
+	for prefix < len(before) && prefix < len(after) && before[prefix] == after[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(before)-prefix && suffix < len(after)-prefix &&
+		before[len(before)-1-suffix] == after[len(after)-1-suffix] {
+		suffix++
+	}
+
+	changes := make([]LineChange, 0, len(before)+len(after)-prefix-suffix)
+	for _, line := range before[:prefix] {
+		changes = append(changes, LineChange{Op: LineEqual, Text: line})
+	}
+	changes = append(changes, diffMiddle(before[prefix:len(before)-suffix], after[prefix:len(after)-suffix])...)
+	for _, line := range before[len(before)-suffix:] {
+		changes = append(changes, LineChange{Op: LineEqual, Text: line})
+	}
+	return changes
+}
+
+// diffMiddle 按最长公共子序列计算差异，同一位置的删除排在新增之前
+func diffMiddle(before, after []string) []LineChange {
+	n, m := len(before), len(after)
+	if n*m > maxLineDiffCells {
+		changes := make([]LineChange, 0, n+m)
+		for _, line := range before {
+			changes = append(changes, LineChange{Op: LineRemoved, Text: line})
+		}
+		for _, line := range after {
+			changes = append(changes, LineChange{Op: LineAdded, Text: line})
+		}
+		return changes
+	}
+
+	// lcs[i][j] 为before[i:]与after[j:]的最长公共子序列长度
+	lcs := make([][]int, n+1)
+	for i := range lcs {
+		lcs[i] = make([]int, m+1)
+	}
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			if before[i] == after[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	changes := make([]LineChange, 0, n+m)
+	i, j := 0, 0
+	for i < n || j < m {
+		switch {
+		case i < n && j < m && before[i] == after[j]:
+			changes = append(changes, LineChange{Op: LineEqual, Text: before[i]})
+			i++
+			j++
+		case i < n && (j == m || lcs[i+1][j] >= lcs[i][j+1]):
+			changes = append(changes, LineChange{Op: LineRemoved, Text: before[i]})
+			i++
+		default:
+			changes = append(changes, LineChange{Op: LineAdded, Text: after[j]})
+			j++
+		}
+	}
+	return changes
+}
+
+// LineDiffStats 逐行差异中新增和删除的行数
+func LineDiffStats(changes []LineChange) (added, removed int) {
+	for _, change := range changes {
+		switch change.Op {
+		case LineAdded:
+			added++
+		case LineRemoved:
+			removed++
+		}
+	}
+	return added, removed
+}
+
+// FormatLineDiff 渲染为统一差异格式的行，只保留变化的行及其前后context行，省略的部分以"@@"行表示；没有变化时返回nil
+func FormatLineDiff(changes []LineChange, context int) []string {
+	keep := make([]bool, len(changes))
+	changed := false
+	for i, change := range changes {
+		if change.Op == LineEqual {
+			continue
+		}
+		changed = true
+		for k := max(0, i-context); k <= min(len(changes)-1, i+context); k++ {
+			keep[k] = true
+		}
+	}
+	if !changed {
+		return nil
+	}
+
+	var lines []string
+	skipped := 0
+	for i, change := range changes {
+		if !keep[i] {
+			skipped++
+			continue
+		}
+		if skipped > 0 {
+			lines = append(lines, fmt.Sprintf("@@ %d unchanged lines @@", skipped))
+			skipped = 0
+		}
+		lines = append(lines, string(change.Op)+" "+change.Text)
+	}
+	if skipped > 0 {
+		lines = append(lines, fmt.Sprintf("@@ %d unchanged lines @@", skipped))
+	}
+	return lines
+}
@@ -0,0 +1,167 @@
+package hostsfile
+
+import (
+	"errors"
+	"net"
+	"strings"
+)
+
+// ErrNoManagedSection hosts内容中不存在管理section
+var ErrNoManagedSection = errors.New("managed section not found")
+
+const (
+	// ManagedMark mHost管理section标记
+	ManagedMark = "# mHost managed section"
+	// StartMarker 管理section起始行
+	StartMarker = ManagedMark + " START"
+	// EndMarker 管理section结束行
+	EndMarker = ManagedMark + " END"
+)
+
+// isStartMarker 是否为管理section起始行
+func isStartMarker(line string) bool {
+	return strings.Contains(line, StartMarker)
+}
+
+// isEndMarker 是否为管理section结束行
+func isEndMarker(line string) bool {
+	return strings.Contains(line, EndMarker)
+}
+
+// HasManagedSection 是否包含管理section
+func HasManagedSection(lines []string) bool {
+	for _, line := range lines {
+		if isStartMarker(line) {
+			return true
+		}
+	}
+	return false
+}
+
+// ExtractManagedSection 提取管理section内部的行（不含起止标记）
+func ExtractManagedSection(lines []string) []string {
+	var managed []string
+	inManaged := false
+
+	for _, line := range lines {
+		if isStartMarker(line) {
+			inManaged = true
+			continue
+		}
+		if isEndMarker(line) {
+			inManaged = false
+			continue
+		}
+		if inManaged {
+			managed = append(managed, line)
+		}
+	}
+
+	return managed
+}
+
+// RemoveManagedSection 移除管理section，同时去掉其前面由mHost插入的空行
+func RemoveManagedSection(lines []string) []string {
+	var result []string
+	inManaged := false
+
+	for _, line := range lines {
+		if isStartMarker(line) {
+			if n := len(result); n > 0 && strings.TrimSpace(result[n-1]) == "" {
+				result = result[:n-1]
+			}
+			inManaged = true
+			continue
+		}
+		if isEndMarker(line) {
+			inManaged = false
+			continue
+		}
+		if !inManaged {
+			result = append(result, line)
+		}
+	}
+
+	return result
+}
+
+// BuildManagedSection 构建管理section（含前导空行与起止标记），无条目时返回nil
+func BuildManagedSection(header []string, entries []Entry) []string {
+	if len(entries) == 0 {
+		return nil
+	}
+
+	body := RenderEntries(entries, false)
+
+	section := make([]string, 0, len(header)+len(body)+3)
+	section = append(section, "", StartMarker)
+	section = append(section, header...)
+	section = append(section, body...)
+	section = append(section, EndMarker)
+	return section
+}
+
+// ReplaceManagedSection 用新的section替换原有管理section，保留非管理部分
+func ReplaceManagedSection(lines []string, section []string) []string {
+	result := RemoveManagedSection(lines)
+	return append(result, section...)
+}
+
+// PatchEntry 在管理section中就地更新单个条目（以主机名和IP地址族匹配，同一主机名的IPv4和IPv6条目互不影响）：
+// 启用时替换或追加该行，禁用时移除该行
+func PatchEntry(lines []string, entry Entry) ([]string, error) {
+	start, end := -1, -1
+	for i, line := range lines {
+		if start < 0 && isStartMarker(line) {
+			start = i
+			continue
+		}
+		if start >= 0 && isEndMarker(line) {
+			end = i
+			break
+		}
+	}
+	if start < 0 || end < 0 {
+		return nil, ErrNoManagedSection
+	}
+
+	header, body := ParseSectionHeader(lines[start+1 : end])
+	headerLines := lines[start+1 : end-len(body)]
+
+	patchedBody := make([]string, 0, len(body)+1)
+	patched := false
+	for _, line := range body {
+		parsed, ok := ParseLine(line)
+		if ok && !patched && len(parsed.Hostnames) == 1 && parsed.Hostnames[0] == entry.Hostname &&
+			isIPv6(parsed.IP) == isIPv6(entry.IP) {
+			patched = true
+			if entry.Enabled {
+				patchedBody = append(patchedBody, RenderEntry(entry))
+			}
+			continue
+		}
+		patchedBody = append(patchedBody, line)
+	}
+
+	if !patched && entry.Enabled {
+		patchedBody = append(patchedBody, RenderEntry(entry))
+	}
+
+	result := make([]string, 0, len(lines)+1)
+	result = append(result, lines[:start+1]...)
+	// 头部带有校验和时随条目一起更新，就地修改不算作手动修改
+	for _, line := range headerLines {
+		if header.Checksum != "" && strings.Contains(line, header.Checksum) {
+			line = headerLine(HeaderChecksum, SectionChecksum(patchedBody))
+		}
+		result = append(result, line)
+	}
+	result = append(result, patchedBody...)
+	return append(result, lines[end:]...), nil
+}
+
+// isIPv6 是否为IPv6地址，用于区分同一主机名的双栈条目
+func isIPv6(ip string) bool {
+	parsed := net.ParseIP(ip)
+	return parsed != nil && parsed.To4() == nil
+}
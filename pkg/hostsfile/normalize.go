@@ -0,0 +1,76 @@
+package hostsfile
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/flyhigher139/mhost/pkg/models"
+)
+
+// ErrUnderscoreHostname 主机名包含下划线，DNS规范不允许但多数系统的hosts解析可以接受
+var ErrUnderscoreHostname = errors.New("hostname contains underscores")
+
+// Normalizer 主机名规范化规则，UI输入、导入器和Helper共用同一规则
+type Normalizer struct {
+	AllowUnderscores bool // 是否接受包含下划线的主机名
+}
+
+// DefaultNormalizer 默认规则：拒绝下划线
+var DefaultNormalizer = Normalizer{}
+
+// NormalizeHostname 规范化主机名：去掉首尾空白和末尾的点并转为小写，不做校验
+func NormalizeHostname(hostname string) string {
+	return strings.ToLower(strings.TrimRight(strings.TrimSpace(hostname), "."))
+}
+
+// Normalize 规范化并校验主机名
+func (n Normalizer) Normalize(hostname string) (string, error) {
+	normalized := NormalizeHostname(hostname)
+	if !n.AllowUnderscores && strings.Contains(normalized, "_") {
+		return "", fmt.Errorf("%w: %q", ErrUnderscoreHostname, normalized)
+	}
+	if err := ValidateHostname(normalized); err != nil {
+		return "", err
+	}
+	return normalized, nil
+}
+
+// NormalizeEntry 规范化条目的主机名
+func (n Normalizer) NormalizeEntry(entry Entry) (Entry, error) {
+	hostname, err := n.Normalize(entry.Hostname)
+	if err != nil {
+		return entry, err
+	}
+	entry.Hostname = hostname
+	return entry, nil
+}
+
+// CountUnnormalized 统计主机名尚未规范化的条目数
+func CountUnnormalized(entries []*models.HostEntry) int {
+	count := 0
+	for _, entry := range entries {
+		if entry != nil && entry.Hostname != NormalizeHostname(entry.Hostname) {
+			count++
+		}
+	}
+	return count
+}
+
+// NormalizeEntries 就地规范化已有条目的主机名（用于一次性迁移），返回修改的条目数；
+// 迁移只做格式转换，不因下划线等规则拒绝已有条目
+func NormalizeEntries(entries []*models.HostEntry) int {
+	changed := 0
+	for _, entry := range entries {
+		if entry == nil {
+			continue
+		}
+		if normalized := NormalizeHostname(entry.Hostname); normalized != entry.Hostname && normalized != "" {
+			entry.Hostname = normalized
+			entry.UpdatedAt = time.Now()
+			changed++
+		}
+	}
+	return changed
+}
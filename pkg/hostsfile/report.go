@@ -0,0 +1,177 @@
+package hostsfile
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Severity 校验问题的严重程度
+type Severity string
+
+const (
+	// SeverityError 错误，hosts文件中的该行无法被正确解析
+	SeverityError Severity = "error"
+	// SeverityWarning 警告，内容有效但可能不符合预期
+	SeverityWarning Severity = "warning"
+)
+
+// 校验问题代码
+const (
+	IssueInvalidEntry    = "invalid_entry"    // 缺少IP或主机名
+	IssueInvalidIP       = "invalid_ip"       // IP地址无效
+	IssueInvalidHostname = "invalid_hostname" // 主机名无效
+	IssueDuplicateHost   = "duplicate_host"   // 主机名指向同一协议族的多个IP
+	IssueUnusualTLD      = "unusual_tld"      // 顶级域名可能导致解析或访问异常
+	IssueMixedWhitespace = "mixed_whitespace" // 同一行混用Tab和空格
+	IssueUnclosedSection = "unclosed_section" // 管理section缺少结束标记
+	IssueUnderscore      = "underscore"       // 主机名包含下划线
+	IssueNotNormalized   = "not_normalized"   // 主机名包含大写字母
+)
+
+// unusualTLDs 需要提示的顶级域名及原因
+var unusualTLDs = map[string]string{
+	"local": "is used by mDNS/Bonjour and may resolve slowly",
+	"dev":   "is HSTS preloaded, browsers only allow HTTPS",
+	"app":   "is HSTS preloaded, browsers only allow HTTPS",
+	"page":  "is HSTS preloaded, browsers only allow HTTPS",
+}
+
+// Issue 校验发现的问题
+type Issue struct {
+	Line     int      `json:"line"` // 行号，从1开始；0表示与整个文件相关
+	Severity Severity `json:"severity"`
+	Code     string   `json:"code"`
+	Message  string   `json:"message"`
+}
+
+// String 格式化为 "line N: message"
+func (i Issue) String() string {
+	if i.Line == 0 {
+		return i.Message
+	}
+	return fmt.Sprintf("line %d: %s", i.Line, i.Message)
+}
+
+// Report 完整的校验报告
+type Report struct {
+	Issues []Issue `json:"issues"`
+}
+
+// HasErrors 是否存在错误
+func (r *Report) HasErrors() bool {
+	return len(r.Errors()) > 0
+}
+
+// Errors 获取所有错误
+func (r *Report) Errors() []Issue {
+	return r.filter(SeverityError)
+}
+
+// Warnings 获取所有警告
+func (r *Report) Warnings() []Issue {
+	return r.filter(SeverityWarning)
+}
+
+// filter 按严重程度筛选问题
+func (r *Report) filter(severity Severity) []Issue {
+	var issues []Issue
+	for _, issue := range r.Issues {
+		if issue.Severity == severity {
+			issues = append(issues, issue)
+		}
+	}
+	return issues
+}
+
+// add 添加问题
+func (r *Report) add(line int, severity Severity, code, format string, args ...interface{}) {
+	r.Issues = append(r.Issues, Issue{Line: line, Severity: severity, Code: code, Message: fmt.Sprintf(format, args...)})
+}
+
+// Check 检查hosts内容并返回全部错误与警告，不会在第一个问题处停止
+func Check(lines []string) *Report {
+	report := &Report{Issues: []Issue{}}
+
+	// 主机名首次出现的IP与行号，IPv4与IPv6分别记录（例如localhost同时指向127.0.0.1和::1是正常的）
+	type firstSeen struct {
+		ip   string
+		line int
+	}
+	seen := make(map[string]firstSeen)
+	inSection := false
+
+	for i, raw := range lines {
+		lineNo := i + 1
+		text := strings.TrimSpace(raw)
+
+		switch text {
+		case StartMarker:
+			inSection = true
+		case EndMarker:
+			inSection = false
+		}
+
+		// 跳过空行和注释行
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		if idx := strings.Index(text, "#"); idx >= 0 {
+			text = strings.TrimSpace(text[:idx])
+		}
+
+		fields := strings.Fields(text)
+		if len(fields) < 2 {
+			report.add(lineNo, SeverityError, IssueInvalidEntry, "invalid hosts entry: %s", strings.TrimSpace(raw))
+			continue
+		}
+
+		if ValidateIP(fields[0]) != nil {
+			report.add(lineNo, SeverityError, IssueInvalidIP, "invalid IP address: %s", fields[0])
+			continue
+		}
+
+		if strings.Contains(text, "\t") && strings.Contains(text, " ") {
+			report.add(lineNo, SeverityWarning, IssueMixedWhitespace, "tabs and spaces are mixed")
+		}
+
+		for _, hostname := range fields[1:] {
+			if ValidateHostname(hostname) != nil {
+				report.add(lineNo, SeverityError, IssueInvalidHostname, "invalid hostname: %s", hostname)
+				continue
+			}
+
+			if strings.Contains(hostname, "_") {
+				report.add(lineNo, SeverityWarning, IssueUnderscore, "%s: underscores are not valid in DNS names and some resolvers reject them", hostname)
+			}
+			if hostname != NormalizeHostname(hostname) {
+				report.add(lineNo, SeverityWarning, IssueNotNormalized, "%s: hostname is not lowercase", hostname)
+			}
+
+			labels := strings.Split(strings.ToLower(hostname), ".")
+			if len(labels) > 1 {
+				if reason, ok := unusualTLDs[labels[len(labels)-1]]; ok {
+					report.add(lineNo, SeverityWarning, IssueUnusualTLD, "%s: .%s %s", hostname, labels[len(labels)-1], reason)
+				}
+			}
+
+			key := strings.ToLower(hostname)
+			if strings.Contains(fields[0], ":") {
+				key += "/ipv6"
+			}
+			if first, exists := seen[key]; exists {
+				if first.ip != fields[0] {
+					report.add(lineNo, SeverityWarning, IssueDuplicateHost, "%s maps to %s, but line %d already maps it to %s", hostname, fields[0], first.line, first.ip)
+				}
+				continue
+			}
+			seen[key] = firstSeen{ip: fields[0], line: lineNo}
+		}
+	}
+
+	if inSection {
+		report.add(0, SeverityWarning, IssueUnclosedSection, "managed section is missing its end marker")
+	}
+
+	return report
+}
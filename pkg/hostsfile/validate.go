@@ -0,0 +1,77 @@
+package hostsfile
+
+import (
+	"fmt"
+	"net"
+	"regexp"
+	"strings"
+)
+
+// MaxHostnameLength 主机名最大长度
+const MaxHostnameLength = 253
+
+// hostnameRegex 主机名格式；下划线不符合DNS规范，但hosts解析可以接受，是否拒绝由Normalizer决定
+var hostnameRegex = regexp.MustCompile(`^[a-zA-Z0-9_]([a-zA-Z0-9\-_]{0,61}[a-zA-Z0-9_])?(\.([a-zA-Z0-9_]([a-zA-Z0-9\-_]{0,61}[a-zA-Z0-9_])?))*$`)
+
+// ValidateIP 验证IP地址
+func ValidateIP(ip string) error {
+	if net.ParseIP(ip) == nil {
+		return fmt.Errorf("invalid IP address: %q", ip)
+	}
+	return nil
+}
+
+// ValidateHostname 验证主机名
+func ValidateHostname(hostname string) error {
+	if hostname == "" || len(hostname) > MaxHostnameLength || !hostnameRegex.MatchString(hostname) {
+		return fmt.Errorf("invalid hostname: %q", hostname)
+	}
+	return nil
+}
+
+// ValidateEntry 验证单个条目
+func ValidateEntry(entry Entry) error {
+	if err := ValidateIP(entry.IP); err != nil {
+		return err
+	}
+	if err := ValidateHostname(entry.Hostname); err != nil {
+		return err
+	}
+	if strings.ContainsAny(entry.Comment, "\r\n") {
+		return fmt.Errorf("comment cannot contain line breaks")
+	}
+	return nil
+}
+
+// ValidateLines 验证hosts内容，返回第一个错误；需要包含警告的完整报告时使用Check
+func ValidateLines(lines []string) error {
+	for i, raw := range lines {
+		text := strings.TrimSpace(raw)
+
+		// 跳过空行和注释行
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		if idx := strings.Index(text, "#"); idx >= 0 {
+			text = text[:idx]
+		}
+
+		fields := strings.Fields(text)
+		if len(fields) < 2 {
+			return fmt.Errorf("invalid hosts entry at line %d: %s", i+1, strings.TrimSpace(raw))
+		}
+
+		if ValidateIP(fields[0]) != nil {
+			return fmt.Errorf("invalid IP address at line %d: %s", i+1, fields[0])
+		}
+
+		for _, hostname := range fields[1:] {
+			if ValidateHostname(hostname) != nil {
+				return fmt.Errorf("invalid hostname at line %d: %s", i+1, hostname)
+			}
+		}
+	}
+
+	return nil
+}
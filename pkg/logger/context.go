@@ -0,0 +1,44 @@
+package logger
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"time"
+)
+
+// requestIDKey 请求ID在context中的键
+type requestIDKey struct{}
+
+// NewRequestID 生成请求ID，用于在GUI和Helper的日志中关联同一个请求
+func NewRequestID() string {
+	buf := make([]byte, 8)
+	if _, err := rand.Read(buf); err != nil {
+		return fmt.Sprintf("%016x", time.Now().UnixNano())
+	}
+	return hex.EncodeToString(buf)
+}
+
+// WithRequestID 将请求ID保存到context中，WithContext创建的日志器会在ContextInfo中输出该ID
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return context.WithValue(ctx, requestIDKey{}, requestID)
+}
+
+// RequestIDFromContext 获取context中的请求ID，没有时返回空字符串
+func RequestIDFromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	if requestID, ok := ctx.Value(requestIDKey{}).(string); ok {
+		return requestID
+	}
+	// 兼容直接以字符串"request_id"为键保存的请求ID
+	if requestID := ctx.Value("request_id"); requestID != nil {
+		return fmt.Sprintf("%v", requestID)
+	}
+	return ""
+}
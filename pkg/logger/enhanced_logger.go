@@ -7,6 +7,7 @@ import (
 	"log"
 	"os"
 	"runtime"
+	"strings"
 	"time"
 
 
@@ -33,6 +34,38 @@ const (
 	LogLevelError
 )
 
+// ParseLogLevel 解析日志级别字符串
+func ParseLogLevel(level string) (LogLevel, error) {
+	switch strings.ToLower(strings.TrimSpace(level)) {
+	case "debug":
+		return LogLevelDebug, nil
+	case "info", "":
+		return LogLevelInfo, nil
+	case "warn", "warning":
+		return LogLevelWarn, nil
+	case "error":
+		return LogLevelError, nil
+	default:
+		return LogLevelInfo, fmt.Errorf("unknown log level: %s", level)
+	}
+}
+
+// String 返回日志级别名称
+func (l LogLevel) String() string {
+	switch l {
+	case LogLevelDebug:
+		return "debug"
+	case LogLevelInfo:
+		return "info"
+	case LogLevelWarn:
+		return "warn"
+	case LogLevelError:
+		return "error"
+	default:
+		return "unknown"
+	}
+}
+
 // Field 日志字段
 type Field struct {
 	Key   string      `json:"key"`
@@ -76,7 +109,7 @@ type CallerInfo struct {
 // EnhancedLogger 增强的日志实现
 type EnhancedLogger struct {
 	logger     *log.Logger
-	level      LogLevel
+	level      *levelVar
 	fields     map[string]interface{}
 	ctx        context.Context
 	structured bool
@@ -87,7 +120,7 @@ type EnhancedLogger struct {
 func NewEnhancedLogger(level LogLevel, structured bool) *EnhancedLogger {
 	return &EnhancedLogger{
 		logger:        log.New(os.Stdout, "", 0),
-		level:         level,
+		level:         newLevelVar(level),
 		fields:        make(map[string]interface{}),
 		structured:    structured,
 		includeCaller: true,
@@ -103,7 +136,7 @@ func NewFileLogger(filePath string, level LogLevel, structured bool) (*EnhancedL
 
 	return &EnhancedLogger{
 		logger:        log.New(file, "", 0),
-		level:         level,
+		level:         newLevelVar(level),
 		fields:        make(map[string]interface{}),
 		structured:    structured,
 		includeCaller: true,
@@ -112,35 +145,35 @@ func NewFileLogger(filePath string, level LogLevel, structured bool) (*EnhancedL
 
 // Debug 调试日志
 func (l *EnhancedLogger) Debug(msg string, keysAndValues ...interface{}) {
-	if l.level <= LogLevelDebug {
+	if l.level.Level() <= LogLevelDebug {
 		l.log("DEBUG", msg, nil, keysAndValues...)
 	}
 }
 
 // Info 信息日志
 func (l *EnhancedLogger) Info(msg string, keysAndValues ...interface{}) {
-	if l.level <= LogLevelInfo {
+	if l.level.Level() <= LogLevelInfo {
 		l.log("INFO", msg, nil, keysAndValues...)
 	}
 }
 
 // Warn 警告日志
 func (l *EnhancedLogger) Warn(msg string, keysAndValues ...interface{}) {
-	if l.level <= LogLevelWarn {
+	if l.level.Level() <= LogLevelWarn {
 		l.log("WARN", msg, nil, keysAndValues...)
 	}
 }
 
 // Error 错误日志
 func (l *EnhancedLogger) Error(msg string, keysAndValues ...interface{}) {
-	if l.level <= LogLevelError {
+	if l.level.Level() <= LogLevelError {
 		l.log("ERROR", msg, nil, keysAndValues...)
 	}
 }
 
 // ErrorWithContext 带上下文的错误日志
 func (l *EnhancedLogger) ErrorWithContext(ctx context.Context, err error, msg string, keysAndValues ...interface{}) {
-	if l.level <= LogLevelError {
+	if l.level.Level() <= LogLevelError {
 		logger := l.WithContext(ctx).(*EnhancedLogger)
 		logger.log("ERROR", msg, err, keysAndValues...)
 	}
@@ -243,9 +276,7 @@ func (l *EnhancedLogger) buildContextInfo(ctx context.Context) *ContextInfo {
 	contextInfo := &ContextInfo{}
 
 	// 从上下文中提取信息
-	if requestID := ctx.Value("request_id"); requestID != nil {
-		contextInfo.RequestID = fmt.Sprintf("%v", requestID)
-	}
+	contextInfo.RequestID = RequestIDFromContext(ctx)
 	if userID := ctx.Value("user_id"); userID != nil {
 		contextInfo.UserID = fmt.Sprintf("%v", userID)
 	}
@@ -312,6 +343,11 @@ func (l *EnhancedLogger) logPlain(entry *LogEntry) {
 		}
 	}
 
+	// 添加请求ID，便于关联同一请求在GUI和Helper中的日志
+	if entry.Context != nil && entry.Context.RequestID != "" {
+		logMsg += fmt.Sprintf(" | request_id=%s", entry.Context.RequestID)
+	}
+
 	// 添加调用者信息
 	if entry.Caller != nil {
 		logMsg += fmt.Sprintf(" | caller=%s:%d", entry.Caller.File, entry.Caller.Line)
@@ -0,0 +1,36 @@
+package logger
+
+import "sync/atomic"
+
+// levelVar 可在运行时修改的日志级别，通过WithFields/WithContext派生的日志器共享同一个级别
+type levelVar struct {
+	v atomic.Int32
+}
+
+// newLevelVar 创建日志级别
+func newLevelVar(level LogLevel) *levelVar {
+	lv := &levelVar{}
+	lv.Set(level)
+	return lv
+}
+
+// Level 获取当前日志级别
+func (lv *levelVar) Level() LogLevel {
+	return LogLevel(lv.v.Load())
+}
+
+// Set 修改日志级别
+func (lv *levelVar) Set(level LogLevel) {
+	lv.v.Store(int32(level))
+}
+
+// Level 获取日志器当前的级别
+func (l *EnhancedLogger) Level() LogLevel {
+	return l.level.Level()
+}
+
+// SetLevel 在运行时修改日志级别，无需重新创建日志器；
+// 对该日志器以及由它派生的所有日志器生效
+func (l *EnhancedLogger) SetLevel(level LogLevel) {
+	l.level.Set(level)
+}
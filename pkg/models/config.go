@@ -1,14 +1,29 @@
 package models
 
-import "time"
+import (
+	"fmt"
+	"time"
+)
 
 // AppConfig 应用程序配置
 type AppConfig struct {
-	Window   WindowConfig   `json:"window"`   // 窗口配置
-	Backup   BackupConfig   `json:"backup"`   // 备份配置
-	Log      LogConfig      `json:"log"`      // 日志配置
-	Security SecurityConfig `json:"security"` // 安全配置
-	UI       UIConfig       `json:"ui"`       // UI配置
+	Window      WindowConfig      `json:"window"`       // 窗口配置
+	Backup      BackupConfig      `json:"backup"`       // 备份配置
+	Log         LogConfig         `json:"log"`          // 日志配置
+	Security    SecurityConfig    `json:"security"`     // 安全配置
+	UI          UIConfig          `json:"ui"`           // UI配置
+	DNSStats    DNSStatsConfig    `json:"dns_stats"`    // DNS命中统计配置
+	Network     NetworkConfig     `json:"network"`      // 网络配置
+	Telemetry   TelemetryConfig   `json:"telemetry"`    // 使用统计配置
+	Limits      LimitsConfig      `json:"limits"`       // hosts文件规模限制
+	Hostnames   HostnameConfig    `json:"hostnames"`    // 主机名规范化配置
+	HealthCheck HealthCheckConfig `json:"health_check"` // 定期重新验证配置
+	Snapshot    SnapshotConfig    `json:"snapshot"`     // Profile快照配置
+	XPC         XPCConfig         `json:"xpc"`          // Helper XPC请求配置
+	Rules       RulesConfig       `json:"rules"`        // 自定义校验规则
+	Hooks       HooksConfig       `json:"hooks"`        // 应用Profile时运行的转换脚本
+	Plugins     PluginsConfig     `json:"plugins"`      // 导入导出插件
+	DNSFilter   DNSFilterConfig   `json:"dns_filter"`   // Pi-hole、AdGuard Home同步
 }
 
 // WindowConfig 窗口配置
@@ -22,12 +37,40 @@ type WindowConfig struct {
 
 // BackupConfig 备份配置
 type BackupConfig struct {
-	Enabled       bool          `json:"enabled"`        // 是否启用自动备份
-	Interval      time.Duration `json:"interval"`       // 备份间隔
-	MaxBackups    int           `json:"max_backups"`    // 最大备份数量
-	BackupPath    string        `json:"backup_path"`    // 备份路径
-	Compression   bool          `json:"compression"`    // 是否压缩备份
-	RetentionDays int           `json:"retention_days"` // 备份保留天数
+	Enabled         bool          `json:"enabled"`           // 是否启用自动备份
+	Interval        time.Duration `json:"interval"`          // 备份间隔
+	MaxBackups      int           `json:"max_backups"`       // 最大备份数量
+	BackupPath      string        `json:"backup_path"`       // 备份路径
+	Compression     bool          `json:"compression"`       // 是否压缩备份
+	RetentionDays   int           `json:"retention_days"`    // 备份保留天数
+	DeltaMinLines   int           `json:"delta_min_lines"`   // hosts文件达到该行数时以增量方式备份，0表示不使用增量备份
+	FullBackupEvery int           `json:"full_backup_every"` // 每隔多少个增量备份保存一次完整备份
+}
+
+// 备份保留天数和最大备份数量的上限，为0时不按对应条件清理备份
+const (
+	MaxBackupRetentionDays = 365
+	MaxBackupCount         = 100
+)
+
+// ClampLimits 将超过上限的最大备份数和保留天数改为上限，返回每项修改的说明。
+// 用于加载引入上限之前保存的配置，避免已有配置因超过上限而无法加载
+func (c *BackupConfig) ClampLimits() []string {
+	var warnings []string
+	if c.MaxBackups > MaxBackupCount {
+		warnings = append(warnings, fmt.Sprintf("backup max_backups %d exceeds the limit of %d, using %d", c.MaxBackups, MaxBackupCount, MaxBackupCount))
+		c.MaxBackups = MaxBackupCount
+	}
+	if c.RetentionDays > MaxBackupRetentionDays {
+		warnings = append(warnings, fmt.Sprintf("backup retention_days %d exceeds the limit of %d, using %d", c.RetentionDays, MaxBackupRetentionDays, MaxBackupRetentionDays))
+		c.RetentionDays = MaxBackupRetentionDays
+	}
+	return warnings
+}
+
+// Retention 备份的保留时长，RetentionDays为0时返回0
+func (c BackupConfig) Retention() time.Duration {
+	return time.Duration(c.RetentionDays) * 24 * time.Hour
 }
 
 // LogConfig 日志配置
@@ -42,21 +85,137 @@ type LogConfig struct {
 
 // SecurityConfig 安全配置
 type SecurityConfig struct {
-	RequireConfirmation bool     `json:"require_confirmation"` // 是否需要确认危险操作
-	AllowedIPs          []string `json:"allowed_ips"`          // 允许的IP地址范围
-	BlockedHosts        []string `json:"blocked_hosts"`        // 禁止的主机名
-	AuditLog            bool     `json:"audit_log"`            // 是否启用审计日志
-	BackupBeforeChange  bool     `json:"backup_before_change"` // 修改前是否自动备份
+	RequireConfirmation bool     `json:"require_confirmation"`      // 是否需要确认危险操作
+	AllowedIPs          []string `json:"allowed_ips"`               // 允许的IP地址范围
+	BlockedHosts        []string `json:"blocked_hosts"`             // 禁止的主机名
+	AuditLog            bool     `json:"audit_log"`                 // 是否启用审计日志
+	BackupBeforeChange  bool     `json:"backup_before_change"`      // 修改前是否自动备份
+	RestoreOnQuit       string   `json:"restore_on_quit,omitempty"` // 退出时如何还原hosts文件，为空时不还原
 }
 
+// 退出时还原hosts文件的方式，只希望mHost运行期间覆盖生效时使用
+const (
+	RestoreOnQuitClear    = "clear"    // 移除mHost管理section
+	RestoreOnQuitBaseline = "baseline" // 恢复为首次运行时保存的系统初始hosts
+)
+
 // UIConfig UI配置
 type UIConfig struct {
-	Theme            string `json:"theme"`              // 主题 (light, dark, auto)
-	Language         string `json:"language"`           // 语言
-	ShowLineNumbers  bool   `json:"show_line_numbers"`  // 是否显示行号
-	FontSize         int    `json:"font_size"`          // 字体大小
-	AutoSave         bool   `json:"auto_save"`          // 是否自动保存
-	AutoSaveInterval int    `json:"auto_save_interval"` // 自动保存间隔(秒)
+	Theme            string `json:"theme"`               // 主题 (light, dark, auto)
+	Language         string `json:"language"`            // 语言
+	ShowLineNumbers  bool   `json:"show_line_numbers"`   // 是否显示行号
+	FontSize         int    `json:"font_size"`           // 字体大小
+	AutoSave         bool   `json:"auto_save"`           // 是否自动保存
+	AutoSaveInterval int    `json:"auto_save_interval"`  // 自动保存间隔(秒)
+	ApplyOnSave      bool   `json:"apply_on_save"`       // 保存激活Profile时是否自动应用
+	ApplyOnSaveDelay int    `json:"apply_on_save_delay"` // 自动应用的防抖延迟(毫秒)
+
+	ProfileGrouping string   `json:"profile_grouping,omitempty"` // Profile列表的分组方式 (folder, tag)
+	ExpandedGroups  []string `json:"expanded_groups,omitempty"`  // Profile列表中展开的分组
+
+	EntrySortColumn     string             `json:"entry_sort_column,omitempty"`     // Host条目表格的排序列，为空时按Profile中的顺序
+	EntrySortDescending bool               `json:"entry_sort_descending,omitempty"` // Host条目表格是否降序排列
+	EntryColumnWidths   map[string]float32 `json:"entry_column_widths,omitempty"`   // Host条目表格中按列名保存的列宽
+}
+
+// Profile列表的分组方式
+const (
+	ProfileGroupingFolder = "folder" // 按文件夹层级分组
+	ProfileGroupingTag    = "tag"    // 按标签分组，有多个标签的Profile出现在每个标签下
+)
+
+// DNSStatsConfig DNS命中统计配置
+type DNSStatsConfig struct {
+	Enabled      bool          `json:"enabled"`        // 是否启用命中统计
+	QueryLogPath string        `json:"query_log_path"` // DNS查询日志路径
+	PollInterval time.Duration `json:"poll_interval"`  // 日志轮询间隔
+}
+
+// 代理模式
+const (
+	ProxyModeSystem = "system" // 使用系统代理设置
+	ProxyModeManual = "manual" // 使用手动指定的代理
+	ProxyModeNone   = "none"   // 不使用代理
+)
+
+// NetworkConfig 网络配置，应用于所有对外的HTTP请求
+type NetworkConfig struct {
+	ProxyMode     string `json:"proxy_mode"`               // 代理模式
+	ProxyURL      string `json:"proxy_url,omitempty"`      // 手动代理地址
+	ProxyUsername string `json:"proxy_username,omitempty"` // 代理认证用户名
+	ProxyPassword string `json:"proxy_password,omitempty"` // 代理认证密码
+	Offline       bool   `json:"offline"`                  // 离线模式，禁止所有对外网络请求
+}
+
+// TelemetryConfig 使用统计配置，统计只保存在本地
+type TelemetryConfig struct {
+	Enabled bool `json:"enabled"` // 是否启用匿名使用统计，默认关闭
+}
+
+// LimitsConfig hosts文件规模限制，部分解析器在行过长或文件过大时工作异常；0表示不限制
+type LimitsConfig struct {
+	MaxHostnamesPerLine int   `json:"max_hostnames_per_line"` // 每行最多主机名数
+	MaxEntries          int   `json:"max_entries"`            // 最多条目数（每个主机名计一条）
+	MaxFileSize         int64 `json:"max_file_size"`          // hosts文件最大字节数
+}
+
+// RulesConfig 自定义校验规则配置，规则文件见internal/rules
+type RulesConfig struct {
+	Path string `json:"path"` // 规则文件路径，为空时使用数据目录下的rules.yaml
+}
+
+// HooksConfig 转换Hook配置，脚本见internal/hooks
+type HooksConfig struct {
+	Enabled bool                `json:"enabled"` // 是否在应用Profile时运行Hook脚本，默认关闭
+	Dir     string              `json:"dir"`     // 脚本目录，为空时使用数据目录下的hooks
+	Grants  map[string][]string `json:"grants"`  // 按脚本文件名授予的能力（env、dns、time），未授予的能力在脚本中不可用
+	Timeout time.Duration       `json:"timeout"` // 单个脚本的运行时限，0表示使用默认时限
+}
+
+// PluginsConfig 导入导出插件配置，协议见internal/plugins
+type PluginsConfig struct {
+	Enabled bool          `json:"enabled"` // 是否发现并运行插件目录中的可执行文件，默认关闭
+	Dir     string        `json:"dir"`     // 插件目录，为空时使用数据目录下的plugins
+	Timeout time.Duration `json:"timeout"` // 每次调用插件的时限，0表示使用默认时限
+}
+
+// DNS过滤服务的类型
+const (
+	DNSFilterPiHole  = "pihole"  // Pi-hole v6
+	DNSFilterAdGuard = "adguard" // AdGuard Home
+)
+
+// DNSFilterConfig 同步拦截条目的DNS过滤服务配置，同步方式见internal/dnsfilter
+type DNSFilterConfig struct {
+	Type  string `json:"type"`            // 服务类型 (pihole, adguard)，为空时不使用
+	URL   string `json:"url"`             // 服务的管理地址，例如http://pi.hole
+	Token string `json:"token,omitempty"` // Pi-hole的应用密码；AdGuard Home为"用户名:密码"
+}
+
+// HostnameConfig 主机名规范化配置
+type HostnameConfig struct {
+	AllowUnderscores bool `json:"allow_underscores"` // 是否接受包含下划线的主机名
+	MigrationOffered bool `json:"migration_offered"` // 是否已提示过规范化已有条目
+}
+
+// HealthCheckConfig 定期重新验证hosts文件、漂移和备份完整性的配置
+type HealthCheckConfig struct {
+	Enabled  bool          `json:"enabled"`  // 是否启用后台定期验证
+	Interval time.Duration `json:"interval"` // 验证间隔
+}
+
+// SnapshotConfig 定期导出全部Profile快照的配置，与hosts文件备份相互独立
+type SnapshotConfig struct {
+	Enabled      bool          `json:"enabled"`       // 是否启用定期快照
+	Interval     time.Duration `json:"interval"`      // 快照间隔
+	Directory    string        `json:"directory"`     // 快照目录，为空时使用数据目录下的snapshots
+	MaxSnapshots int           `json:"max_snapshots"` // 保留的快照数量，0表示不清理
+}
+
+// XPCConfig Helper XPC请求的超时配置，0表示使用内置默认值
+type XPCConfig struct {
+	Timeout           time.Duration            `json:"timeout"`            // 没有单独设置的操作使用的超时
+	OperationTimeouts map[string]time.Duration `json:"operation_timeouts"` // 按操作名覆盖的超时，如write_hosts
 }
 
 // DefaultAppConfig 返回默认的应用程序配置
@@ -70,12 +229,14 @@ func DefaultAppConfig() *AppConfig {
 			Maximized: false,
 		},
 		Backup: BackupConfig{
-			Enabled:       true,
-			Interval:      24 * time.Hour, // 每天备份一次
-			MaxBackups:    10,
-			BackupPath:    "", // 空字符串表示使用默认路径
-			Compression:   true,
-			RetentionDays: 30,
+			Enabled:         true,
+			Interval:        24 * time.Hour, // 每天备份一次
+			MaxBackups:      10,
+			BackupPath:      "", // 空字符串表示使用默认路径
+			Compression:     true,
+			RetentionDays:   30,
+			DeltaMinLines:   10000,
+			FullBackupEvery: 10,
 		},
 		Log: LogConfig{
 			Level:      "info",
@@ -99,6 +260,37 @@ func DefaultAppConfig() *AppConfig {
 			FontSize:         12,
 			AutoSave:         true,
 			AutoSaveInterval: 30, // 30秒
+			ApplyOnSave:      false,
+			ApplyOnSaveDelay: 1000, // 1秒
+			ProfileGrouping:  ProfileGroupingFolder,
+		},
+		DNSStats: DNSStatsConfig{
+			Enabled:      false,
+			QueryLogPath: "", // 需要用户指定DNS查询日志
+			PollInterval: 5 * time.Second,
+		},
+		Network: NetworkConfig{
+			ProxyMode: ProxyModeSystem,
+		},
+		Telemetry: TelemetryConfig{
+			Enabled: false, // 需要用户主动开启
+		},
+		Limits: LimitsConfig{
+			MaxHostnamesPerLine: 9,
+			MaxEntries:          10000,
+			MaxFileSize:         1 << 20, // 1MB
+		},
+		HealthCheck: HealthCheckConfig{
+			Enabled:  true,
+			Interval: 30 * time.Minute,
+		},
+		Snapshot: SnapshotConfig{
+			Enabled:      false,
+			Interval:     24 * time.Hour,
+			MaxSnapshots: 30,
+		},
+		XPC: XPCConfig{
+			Timeout: 30 * time.Second,
 		},
 	}
 }
@@ -109,7 +301,10 @@ func (c *AppConfig) Validate() error {
 		return ErrInvalidConfig
 	}
 
-	if c.Backup.MaxBackups < 0 || c.Backup.RetentionDays < 0 {
+	if c.Backup.MaxBackups < 0 || c.Backup.RetentionDays < 0 || c.Backup.DeltaMinLines < 0 || c.Backup.FullBackupEvery < 0 {
+		return ErrInvalidConfig
+	}
+	if c.Backup.MaxBackups > MaxBackupCount || c.Backup.RetentionDays > MaxBackupRetentionDays {
 		return ErrInvalidConfig
 	}
 
@@ -140,6 +335,71 @@ func (c *AppConfig) Validate() error {
 		return ErrInvalidConfig
 	}
 
+	if c.UI.ApplyOnSaveDelay < 0 {
+		return ErrInvalidConfig
+	}
+
+	switch c.UI.ProfileGrouping {
+	case "", ProfileGroupingFolder, ProfileGroupingTag:
+	default:
+		return ErrInvalidConfig
+	}
+
+	switch c.Security.RestoreOnQuit {
+	case "", RestoreOnQuitClear, RestoreOnQuitBaseline:
+	default:
+		return ErrInvalidConfig
+	}
+
+	if c.DNSStats.Enabled && c.DNSStats.QueryLogPath == "" {
+		return ErrInvalidConfig
+	}
+
+	if c.Limits.MaxHostnamesPerLine < 0 || c.Limits.MaxEntries < 0 || c.Limits.MaxFileSize < 0 {
+		return ErrInvalidConfig
+	}
+
+	if c.HealthCheck.Enabled && c.HealthCheck.Interval <= 0 {
+		return ErrInvalidConfig
+	}
+
+	if (c.Snapshot.Enabled && c.Snapshot.Interval <= 0) || c.Snapshot.MaxSnapshots < 0 {
+		return ErrInvalidConfig
+	}
+
+	if c.Hooks.Timeout < 0 || c.Plugins.Timeout < 0 {
+		return ErrInvalidConfig
+	}
+
+	switch c.DNSFilter.Type {
+	case "":
+	case DNSFilterPiHole, DNSFilterAdGuard:
+		if c.DNSFilter.URL == "" {
+			return ErrInvalidConfig
+		}
+	default:
+		return ErrInvalidConfig
+	}
+
+	if c.XPC.Timeout < 0 {
+		return ErrInvalidConfig
+	}
+	for _, timeout := range c.XPC.OperationTimeouts {
+		if timeout <= 0 {
+			return ErrInvalidConfig
+		}
+	}
+
+	switch c.Network.ProxyMode {
+	case "", ProxyModeSystem, ProxyModeNone:
+	case ProxyModeManual:
+		if c.Network.ProxyURL == "" {
+			return ErrInvalidConfig
+		}
+	default:
+		return ErrInvalidConfig
+	}
+
 	return nil
 }
 
@@ -154,5 +414,31 @@ func (c *AppConfig) Clone() *AppConfig {
 	cloned.Security.BlockedHosts = make([]string, len(c.Security.BlockedHosts))
 	copy(cloned.Security.BlockedHosts, c.Security.BlockedHosts)
 
+	if c.UI.ExpandedGroups != nil {
+		cloned.UI.ExpandedGroups = make([]string, len(c.UI.ExpandedGroups))
+		copy(cloned.UI.ExpandedGroups, c.UI.ExpandedGroups)
+	}
+
+	if c.UI.EntryColumnWidths != nil {
+		cloned.UI.EntryColumnWidths = make(map[string]float32, len(c.UI.EntryColumnWidths))
+		for column, width := range c.UI.EntryColumnWidths {
+			cloned.UI.EntryColumnWidths[column] = width
+		}
+	}
+
+	if c.XPC.OperationTimeouts != nil {
+		cloned.XPC.OperationTimeouts = make(map[string]time.Duration, len(c.XPC.OperationTimeouts))
+		for operation, timeout := range c.XPC.OperationTimeouts {
+			cloned.XPC.OperationTimeouts[operation] = timeout
+		}
+	}
+
+	if c.Hooks.Grants != nil {
+		cloned.Hooks.Grants = make(map[string][]string, len(c.Hooks.Grants))
+		for name, capabilities := range c.Hooks.Grants {
+			cloned.Hooks.Grants[name] = append([]string(nil), capabilities...)
+		}
+	}
+
 	return &cloned
 }
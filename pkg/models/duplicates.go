@@ -0,0 +1,107 @@
+package models
+
+import (
+	"errors"
+	"net"
+	"strings"
+)
+
+// ErrDuplicateHostname Profile中同一主机名有多个启用的条目
+var ErrDuplicateHostname = errors.New("duplicate hostname")
+
+// DuplicateHostnameError 重复的主机名，可通过Profile.RemoveDuplicateHostnames清理
+type DuplicateHostnameError struct {
+	Hostnames []string
+}
+
+// Error 实现error接口
+func (e *DuplicateHostnameError) Error() string {
+	return ErrDuplicateHostname.Error() + ": " + strings.Join(e.Hostnames, ", ")
+}
+
+// Unwrap 支持errors.Is(err, ErrDuplicateHostname)
+func (e *DuplicateHostnameError) Unwrap() error {
+	return ErrDuplicateHostname
+}
+
+// DuplicateHostname 主机名相同的多个启用条目，Entries按在Profile中的顺序排列
+type DuplicateHostname struct {
+	Hostname string
+	Entries  []*HostEntry
+}
+
+// duplicateKey 判断重复时使用的键：主机名不区分大小写，IPv4和IPv6条目分别比较，
+// 同一主机名各有一个IPv4和IPv6条目是正常的双栈配置
+func duplicateKey(entry *HostEntry) string {
+	family := "4"
+	if ip := net.ParseIP(entry.IP); ip != nil && ip.To4() == nil {
+		family = "6"
+	}
+	return strings.ToLower(entry.Hostname) + "/" + family
+}
+
+// DuplicateHostnames 查找启用的条目中重复的主机名，按首次出现的顺序返回。
+// 禁用的条目常作为备用地址保留，不计入
+func (p *Profile) DuplicateHostnames() []DuplicateHostname {
+	groups := make(map[string][]*HostEntry)
+	var keys []string
+	for _, entry := range p.Entries {
+		if entry == nil || !entry.Enabled {
+			continue
+		}
+		key := duplicateKey(entry)
+		if _, ok := groups[key]; !ok {
+			keys = append(keys, key)
+		}
+		groups[key] = append(groups[key], entry)
+	}
+
+	var duplicates []DuplicateHostname
+	for _, key := range keys {
+		if entries := groups[key]; len(entries) > 1 {
+			duplicates = append(duplicates, DuplicateHostname{Hostname: entries[0].Hostname, Entries: entries})
+		}
+	}
+	return duplicates
+}
+
+// ConflictingEntry 查找与entry主机名重复的其他启用条目，entry未启用或没有重复时返回nil。
+// entry可以是尚未加入Profile的条目，ID相同的条目视为entry本身
+func (p *Profile) ConflictingEntry(entry *HostEntry) *HostEntry {
+	if entry == nil || !entry.Enabled {
+		return nil
+	}
+	key := duplicateKey(entry)
+	for _, other := range p.Entries {
+		if other != nil && other.Enabled && other.ID != entry.ID && duplicateKey(other) == key {
+			return other
+		}
+	}
+	return nil
+}
+
+// RemoveDuplicateHostnames 每个重复的主机名只保留最后一个启用的条目，返回删除的条目
+func (p *Profile) RemoveDuplicateHostnames() []*HostEntry {
+	drop := make(map[*HostEntry]bool)
+	var removed []*HostEntry
+	for _, duplicate := range p.DuplicateHostnames() {
+		for _, entry := range duplicate.Entries[:len(duplicate.Entries)-1] {
+			drop[entry] = true
+			removed = append(removed, entry)
+		}
+	}
+	if len(removed) == 0 {
+		return nil
+	}
+
+	kept := p.Entries[:0]
+	for _, entry := range p.Entries {
+		if !drop[entry] {
+			kept = append(kept, entry)
+		}
+	}
+	clear(p.Entries[len(kept):])
+	p.Entries = kept
+	p.UpdateTimestamp()
+	return removed
+}
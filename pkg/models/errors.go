@@ -10,12 +10,24 @@ var (
 	ErrProfileExists      = errors.New("profile already exists")
 	ErrNoActiveProfile    = errors.New("no active profile")
 	ErrActiveProfile      = errors.New("active profile error")
+	ErrReadOnlyProfile    = errors.New("profile is read-only")
+
+	// Profile文件夹相关错误
+	ErrInvalidGroup  = errors.New("invalid profile group")
+	ErrGroupNotFound = errors.New("profile group not found")
+	ErrGroupExists   = errors.New("profile group already exists")
+
+	// 标签相关错误
+	ErrInvalidTag  = errors.New("invalid tag")
+	ErrTagNotFound = errors.New("tag not found")
 
 	// HostEntry相关错误
 	ErrInvalidIP         = errors.New("invalid IP address")
 	ErrInvalidHostname   = errors.New("invalid hostname")
 	ErrHostEntryExists   = errors.New("host entry already exists")
 	ErrHostEntryNotFound = errors.New("host entry not found")
+	ErrInvalidVariant    = errors.New("invalid environment variant")
+	ErrInvalidHostEntry  = errors.New("invalid host entry")
 
 	// 备份相关错误
 	ErrInvalidBackup  = errors.New("invalid backup")
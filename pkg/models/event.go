@@ -13,6 +13,13 @@ const (
 	EventProfileUpdated   EventType = "profile.updated"
 	EventProfileDeleted   EventType = "profile.deleted"
 	EventProfileActivated EventType = "profile.activated"
+	EventProfileImported  EventType = "profile.imported"
+	// EventProfileSubscriptionRefreshed 订阅的上游内容变化，Profile条目已更新
+	EventProfileSubscriptionRefreshed EventType = "profile.subscription_refreshed"
+	// EventProfileInventorySynced 资产清单的内容变化，Profile条目已更新
+	EventProfileInventorySynced EventType = "profile.inventory_synced"
+	// EventProfileGroupChanged 新建、重命名或删除了Profile文件夹
+	EventProfileGroupChanged EventType = "profile.group_changed"
 
 	// Host条目相关事件
 	EventHostEntryAdded   EventType = "host_entry.added"
@@ -25,6 +32,8 @@ const (
 	EventSystemBackupCreated  EventType = "system.backup_created"
 	EventSystemBackupRestored EventType = "system.backup_restored"
 	EventSystemConfigChanged  EventType = "system.config_changed"
+	EventSystemDriftDetected  EventType = "system.drift_detected"
+	EventSystemSnapshotSaved  EventType = "system.snapshot_saved"
 
 	// 错误事件
 	EventError   EventType = "error"
@@ -128,7 +137,11 @@ func (e *Event) IsProfileEvent() bool {
 	return e.Type == EventProfileCreated ||
 		e.Type == EventProfileUpdated ||
 		e.Type == EventProfileDeleted ||
-		e.Type == EventProfileActivated
+		e.Type == EventProfileActivated ||
+		e.Type == EventProfileImported ||
+		e.Type == EventProfileSubscriptionRefreshed ||
+		e.Type == EventProfileInventorySynced ||
+		e.Type == EventProfileGroupChanged
 }
 
 // IsHostEntryEvent 检查是否为Host条目相关事件
@@ -144,7 +157,9 @@ func (e *Event) IsSystemEvent() bool {
 	return e.Type == EventSystemHostsUpdated ||
 		e.Type == EventSystemBackupCreated ||
 		e.Type == EventSystemBackupRestored ||
-		e.Type == EventSystemConfigChanged
+		e.Type == EventSystemConfigChanged ||
+		e.Type == EventSystemDriftDetected ||
+		e.Type == EventSystemSnapshotSaved
 }
 
 // IsErrorEvent 检查是否为错误事件
@@ -0,0 +1,61 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// ProfileGroup Profile文件夹，按项目或环境组织Profile。
+// Profile通过Folder字段归入文件夹，ProfileGroup用于保存没有Profile的空文件夹
+type ProfileGroup struct {
+	Path      string    `json:"path"` // 以"/"分隔的层级路径
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// NewProfileGroup 创建Profile文件夹，路径为空时返回ErrInvalidGroup
+func NewProfileGroup(path string) (*ProfileGroup, error) {
+	path = NormalizeFolder(path)
+	if path == "" {
+		return nil, ErrInvalidGroup
+	}
+	return &ProfileGroup{Path: path, CreatedAt: time.Now()}, nil
+}
+
+// Name 文件夹的名称，即路径的最后一级
+func (g *ProfileGroup) Name() string {
+	return g.Path[strings.LastIndex(g.Path, "/")+1:]
+}
+
+// Parent 上级文件夹的路径，位于顶层时为空
+func (g *ProfileGroup) Parent() string {
+	return ParentFolder(g.Path)
+}
+
+// ParentFolder 获取文件夹路径的上级路径，位于顶层时为空
+func ParentFolder(folder string) string {
+	if i := strings.LastIndex(folder, "/"); i >= 0 {
+		return folder[:i]
+	}
+	return ""
+}
+
+// InFolder folder是否为group本身或其下级文件夹
+func InFolder(folder, group string) bool {
+	return folder == group || strings.HasPrefix(folder, group+"/")
+}
+
+// MoveFolder 将位于from（包括其下级文件夹）中的路径移动到to下，不在from中时原样返回
+func MoveFolder(folder, from, to string) string {
+	if !InFolder(folder, from) {
+		return folder
+	}
+	rest := strings.TrimPrefix(folder[len(from):], "/")
+	switch {
+	case to == "":
+		return rest
+	case rest == "":
+		return to
+	default:
+		return to + "/" + rest
+	}
+}
@@ -1,30 +1,166 @@
 package models
 
 import (
+	"maps"
+	"slices"
+	"sort"
+	"strings"
 	"time"
 )
 
 // Profile 表示一个hosts配置文件
 type Profile struct {
-	ID          string       `json:"id"`          // 唯一标识符
-	Name        string       `json:"name"`        // 配置文件名称
-	Description string       `json:"description"` // 描述信息
-	Entries     []*HostEntry `json:"entries"`     // hosts条目列表
-	CreatedAt   time.Time    `json:"created_at"`  // 创建时间
-	UpdatedAt   time.Time    `json:"updated_at"`  // 更新时间
-	IsActive    bool         `json:"is_active"`   // 是否为当前激活的配置
-	Tags        []string     `json:"tags"`        // 标签
+	ID          string       `json:"id"`                    // 唯一标识符
+	Name        string       `json:"name"`                  // 配置文件名称
+	Description string       `json:"description"`           // 描述信息
+	Entries     []*HostEntry `json:"entries"`               // hosts条目列表
+	CreatedAt   time.Time    `json:"created_at"`            // 创建时间
+	UpdatedAt   time.Time    `json:"updated_at"`            // 更新时间
+	IsActive    bool         `json:"is_active"`             // 是否为当前激活的配置
+	Tags        []string     `json:"tags"`                  // 标签
+	Environment string       `json:"environment,omitempty"` // 应用时选用的环境，为空时使用条目的默认IP
+	Folder      string       `json:"folder,omitempty"`      // 所在文件夹，以"/"分隔的层级路径，为空时位于顶层
+	// Author 作者，作为新条目默认的负责人并记录在管理section头部，便于识别多人共享的Profile的来源
+	Author string `json:"author,omitempty"`
+	// DefaultComment 新条目注释的默认前缀
+	DefaultComment string `json:"default_comment,omitempty"`
+	// Subscription 订阅的远程hosts列表，不为空时条目由上游内容定期刷新
+	Subscription *Subscription `json:"subscription,omitempty"`
+	// Inventory 同步的资产清单，不为空时Profile只读，条目只能由同步更新
+	Inventory *Inventory `json:"inventory,omitempty"`
+}
+
+// Subscription Profile订阅的远程hosts列表（例如公共的广告屏蔽列表或公司内部列表）及其刷新状态
+type Subscription struct {
+	URL string `json:"url"`
+	// IntervalMinutes 刷新间隔（分钟），不大于0时使用DefaultSubscriptionInterval
+	IntervalMinutes int `json:"interval_minutes,omitempty"`
+	// ETag、LastModified 上次下载时服务器返回的缓存验证信息，用于条件请求
+	ETag         string    `json:"etag,omitempty"`
+	LastModified string    `json:"last_modified,omitempty"`
+	LastChecked  time.Time `json:"last_checked,omitempty"`
+	LastUpdated  time.Time `json:"last_updated,omitempty"` // 上游内容最近一次变化的时间
+	LastError    string    `json:"last_error,omitempty"`
+}
+
+// DefaultSubscriptionInterval 订阅的默认刷新间隔
+const DefaultSubscriptionInterval = 24 * time.Hour
+
+// Interval 刷新间隔
+func (s *Subscription) Interval() time.Duration {
+	if s.IntervalMinutes <= 0 {
+		return DefaultSubscriptionInterval
+	}
+	return time.Duration(s.IntervalMinutes) * time.Minute
+}
+
+// Due 是否到了刷新时间，从未检查过的订阅总是需要刷新
+func (s *Subscription) Due(now time.Time) bool {
+	return s.LastChecked.IsZero() || !now.Before(s.LastChecked.Add(s.Interval()))
+}
+
+// Inventory Profile同步的资产清单（例如公司CMDB的HTTP API）及其同步状态。
+// 响应为JSON，Query选出记录数组，每条记录通过Hostname、IP、Comment模板生成条目
+type Inventory struct {
+	URL string `json:"url"`
+	// Headers 请求头，例如Authorization
+	Headers map[string]string `json:"headers,omitempty"`
+	// Query 从响应中选出记录数组的JMESPath表达式，为空时响应本身应为数组
+	Query string `json:"query,omitempty"`
+	// Hostname、IP、Comment 由记录生成主机名、IP和注释的text/template模板，例如"{{.fqdn}}"；
+	// 主机名和IP为空时分别使用DefaultInventoryHostname和DefaultInventoryIP
+	Hostname string `json:"hostname,omitempty"`
+	IP       string `json:"ip,omitempty"`
+	Comment  string `json:"comment,omitempty"`
+	// IntervalMinutes 同步间隔（分钟），不大于0时使用DefaultInventoryInterval
+	IntervalMinutes int       `json:"interval_minutes,omitempty"`
+	LastChecked     time.Time `json:"last_checked,omitempty"`
+	LastUpdated     time.Time `json:"last_updated,omitempty"` // 条目最近一次变化的时间
+	LastError       string    `json:"last_error,omitempty"`
+}
+
+const (
+	// DefaultInventoryInterval 资产清单的默认同步间隔
+	DefaultInventoryInterval = time.Hour
+	// DefaultInventoryHostname、DefaultInventoryIP 记录的默认映射，使用记录的hostname和ip字段
+	DefaultInventoryHostname = "{{.hostname}}"
+	DefaultInventoryIP       = "{{.ip}}"
+)
+
+// Interval 同步间隔
+func (i *Inventory) Interval() time.Duration {
+	if i.IntervalMinutes <= 0 {
+		return DefaultInventoryInterval
+	}
+	return time.Duration(i.IntervalMinutes) * time.Minute
+}
+
+// Due 是否到了同步时间，从未同步过的资产清单总是需要同步
+func (i *Inventory) Due(now time.Time) bool {
+	return i.LastChecked.IsZero() || !now.Before(i.LastChecked.Add(i.Interval()))
+}
+
+// ReadOnly Profile是否只读：同步资产清单的Profile的条目由同步维护，不能手动修改
+func (p *Profile) ReadOnly() bool {
+	return p.Inventory != nil
+}
+
+// EqualEntries 两组条目的内容是否相同，不比较时间戳；nil条目只与nil条目相同
+func EqualEntries(a, b []*HostEntry) bool {
+	return slices.EqualFunc(a, b, func(x, y *HostEntry) bool {
+		if x == nil || y == nil {
+			return x == y
+		}
+		return x.ID == y.ID && x.IP == y.IP && x.Hostname == y.Hostname && x.Comment == y.Comment &&
+			x.Enabled == y.Enabled && maps.Equal(x.Variants, y.Variants)
+	})
+}
+
+// EqualInventory 两个资产清单的配置和同步状态是否相同
+func EqualInventory(a, b *Inventory) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.URL == b.URL && maps.Equal(a.Headers, b.Headers) && a.Query == b.Query &&
+		a.Hostname == b.Hostname && a.IP == b.IP && a.Comment == b.Comment && a.IntervalMinutes == b.IntervalMinutes &&
+		a.LastChecked.Equal(b.LastChecked) && a.LastUpdated.Equal(b.LastUpdated) && a.LastError == b.LastError
 }
 
 // HostEntry hosts文件条目
 type HostEntry struct {
-	ID        string    `json:"id"`         // 唯一标识符
-	IP        string    `json:"ip"`         // IP地址
-	Hostname  string    `json:"hostname"`   // 主机名
-	Comment   string    `json:"comment"`    // 注释
-	Enabled   bool      `json:"enabled"`    // 是否启用
-	CreatedAt time.Time `json:"created_at"` // 创建时间
-	UpdatedAt time.Time `json:"updated_at"` // 更新时间
+	ID        string        `json:"id"`                 // 唯一标识符
+	IP        string        `json:"ip"`                 // IP地址
+	Hostname  string        `json:"hostname"`           // 主机名
+	Comment   string        `json:"comment"`            // 注释
+	Enabled   bool          `json:"enabled"`            // 是否启用
+	CreatedAt time.Time     `json:"created_at"`         // 创建时间
+	UpdatedAt time.Time     `json:"updated_at"`         // 更新时间
+	Variants  EntryVariants `json:"variants,omitempty"` // 按环境区分的目标IP
+}
+
+// EntryVariants 条目按环境区分的目标IP，键为环境名（例如dev、staging、prod）
+type EntryVariants map[string]string
+
+// Environments 按名称排序的环境列表
+func (v EntryVariants) Environments() []string {
+	environments := make([]string, 0, len(v))
+	for environment := range v {
+		environments = append(environments, environment)
+	}
+	sort.Strings(environments)
+	return environments
+}
+
+// Clone 复制变体，nil保持为nil
+func (v EntryVariants) Clone() EntryVariants {
+	if v == nil {
+		return nil
+	}
+	cloned := make(EntryVariants, len(v))
+	for environment, ip := range v {
+		cloned[environment] = ip
+	}
+	return cloned
 }
 
 // ProfileSummary 用于列表显示的简化Profile信息
@@ -35,6 +171,7 @@ type ProfileSummary struct {
 	EntryCount  int       `json:"entry_count"`
 	IsActive    bool      `json:"is_active"`
 	UpdatedAt   time.Time `json:"updated_at"`
+	Tags        []string  `json:"tags,omitempty"`
 }
 
 // NewProfile 创建一个新的Profile实例
@@ -52,6 +189,17 @@ func NewProfile(name, description string) *Profile {
 	}
 }
 
+// GlobalProfileID 全局条目所在的隐式Profile的ID。全局条目在应用任何Profile时都会写入hosts文件，
+// 不随Profile切换而变化，例如许可证服务器的覆盖
+const GlobalProfileID = "global"
+
+// NewGlobalProfile 创建空的全局条目Profile
+func NewGlobalProfile() *Profile {
+	p := NewProfile("Global", "")
+	p.ID = GlobalProfileID
+	return p
+}
+
 // NewHostEntry 创建新的HostEntry
 func NewHostEntry(ip, hostname, comment string) *HostEntry {
 	now := time.Now()
@@ -121,6 +269,7 @@ func (p *Profile) ToSummary() ProfileSummary {
 		EntryCount:  len(p.Entries),
 		IsActive:    p.IsActive,
 		UpdatedAt:   p.UpdatedAt,
+		Tags:        slices.Clone(p.Tags),
 	}
 }
 
@@ -130,25 +279,55 @@ func (p *Profile) Clone() *Profile {
 	cloned.Entries = make([]*HostEntry, len(p.Entries))
 	for i, entry := range p.Entries {
 		entryCopy := *entry
+		entryCopy.Variants = entry.Variants.Clone()
 		cloned.Entries[i] = &entryCopy
 	}
 	cloned.Tags = make([]string, len(p.Tags))
 	copy(cloned.Tags, p.Tags)
+	if p.Subscription != nil {
+		subscription := *p.Subscription
+		cloned.Subscription = &subscription
+	}
+	if p.Inventory != nil {
+		inventory := *p.Inventory
+		inventory.Headers = maps.Clone(p.Inventory.Headers)
+		cloned.Inventory = &inventory
+	}
 	return &cloned
 }
 
 // Validate 验证Profile数据的有效性
 func (p *Profile) Validate() error {
-	if p.Name == "" {
+	// 名称会写入hosts文件管理section的注释行，不能包含换行
+	if p.Name == "" || strings.ContainsAny(p.Name, "\r\n") {
 		return ErrInvalidProfileName
 	}
+	// 作者同样写入管理section头部，默认注释会成为条目注释的一部分
+	if strings.ContainsAny(p.Author, "\r\n") || strings.ContainsAny(p.DefaultComment, "\r\n") {
+		return ErrInvalidProfile
+	}
+	if p.Inventory != nil && p.Inventory.URL == "" {
+		return ErrInvalidProfile
+	}
 
 	for _, entry := range p.Entries {
+		if entry == nil {
+			return ErrInvalidHostEntry
+		}
 		if err := entry.Validate(); err != nil {
 			return err
 		}
 	}
 
+	// 同一主机名有多个启用的条目时，hosts文件中只有其中一个生效
+	if duplicates := p.DuplicateHostnames(); len(duplicates) > 0 {
+		err := &DuplicateHostnameError{}
+		for _, duplicate := range duplicates {
+			err.Hostnames = append(err.Hostnames, duplicate.Hostname)
+		}
+		return err
+	}
+
 	return nil
 }
 
@@ -160,5 +339,203 @@ func (h *HostEntry) Validate() error {
 	if h.Hostname == "" {
 		return ErrInvalidHostname
 	}
+	for environment, ip := range h.Variants {
+		if environment == "" || ip == "" {
+			return ErrInvalidVariant
+		}
+	}
 	return nil
 }
+
+// IPFor 获取指定环境下的目标IP，没有对应变体时使用默认IP
+func (h *HostEntry) IPFor(environment string) string {
+	if ip, ok := h.Variants[environment]; ok && environment != "" {
+		return ip
+	}
+	return h.IP
+}
+
+// NormalizeFolder 规范化文件夹路径：去掉每级名称两端的空白并忽略空的层级
+func NormalizeFolder(folder string) string {
+	segments := make([]string, 0)
+	for _, segment := range strings.Split(folder, "/") {
+		if segment = strings.TrimSpace(segment); segment != "" {
+			segments = append(segments, segment)
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+// Environments 所有条目变体中出现的环境，按名称排序
+func (p *Profile) Environments() []string {
+	seen := make(map[string]bool)
+	var environments []string
+	for _, entry := range p.Entries {
+		if entry == nil {
+			continue
+		}
+		for environment := range entry.Variants {
+			if !seen[environment] {
+				seen[environment] = true
+				environments = append(environments, environment)
+			}
+		}
+	}
+	sort.Strings(environments)
+	return environments
+}
+
+// ResolveEntry 返回按Profile当前环境解析IP后的条目副本
+func (p *Profile) ResolveEntry(entry *HostEntry) *HostEntry {
+	resolved := *entry
+	resolved.IP = entry.IPFor(p.Environment)
+	resolved.Variants = nil
+	return &resolved
+}
+
+// ResolvedEntries 返回按Profile当前环境解析IP后的条目列表，应用和导出时使用
+func (p *Profile) ResolvedEntries() []*HostEntry {
+	resolved := make([]*HostEntry, 0, len(p.Entries))
+	for _, entry := range p.Entries {
+		if entry != nil {
+			resolved = append(resolved, p.ResolveEntry(entry))
+		}
+	}
+	return resolved
+}
+
+// WithGlobal 返回合并了全局条目的Profile副本，应用和检测漂移时使用。启用的全局条目按全局Profile的环境解析后排在前面，
+// Profile中与其主机名相同的条目被忽略；global为nil或没有启用的条目时返回p本身
+func (p *Profile) WithGlobal(global *Profile) *Profile {
+	if global == nil {
+		return p
+	}
+
+	var entries []*HostEntry
+	shadowed := make(map[string]bool)
+	for _, entry := range global.ResolvedEntries() {
+		if entry.Enabled {
+			entries = append(entries, entry)
+			shadowed[strings.ToLower(entry.Hostname)] = true
+		}
+	}
+	if len(entries) == 0 {
+		return p
+	}
+
+	merged := p.Clone()
+	for _, entry := range merged.Entries {
+		if entry != nil && !shadowed[strings.ToLower(entry.Hostname)] {
+			entries = append(entries, entry)
+		}
+	}
+	merged.Entries = entries
+	return merged
+}
+
+// LayerSeparator 叠加应用多个Profile时合并后的名称中各层之间的分隔符
+const LayerSeparator = " + "
+
+// LayerConflict 叠加应用多个Profile时同一主机名在多层中指向不同的IP，优先级最高的层生效
+type LayerConflict struct {
+	Hostname   string   `json:"hostname"`
+	Winner     string   `json:"winner"`     // 生效条目所在Profile的名称
+	Overridden []string `json:"overridden"` // 被覆盖的Profile名称，按优先级从低到高排列
+}
+
+// LayerProfiles 按优先级从低到高叠加多个Profile（例如"base"、"project-A"、"adblock"），返回合并后的Profile以及主机名冲突。
+// 每层的条目按该层的环境解析IP；同一主机名出现在多层中时只保留优先级最高的一层中的条目，同一层中的多个条目（例如IPv4和IPv6地址）全部保留。
+// 只有禁用条目的层不覆盖较低层中启用的条目。只有一层时返回该Profile本身
+func LayerProfiles(layers []*Profile) (*Profile, []LayerConflict) {
+	if len(layers) == 1 {
+		return layers[0], nil
+	}
+
+	// 每个主机名生效的层：优先级最高的有启用条目的层，没有启用条目时为优先级最高的层
+	resolved := make([][]*HostEntry, len(layers))
+	winner := make(map[string]int)
+	enabledIPs := make(map[string]map[int][]string)
+	for i, layer := range layers {
+		resolved[i] = layer.ResolvedEntries()
+		for _, entry := range resolved[i] {
+			hostname := strings.ToLower(entry.Hostname)
+			if entry.Enabled {
+				if enabledIPs[hostname] == nil {
+					enabledIPs[hostname] = make(map[int][]string)
+				}
+				enabledIPs[hostname][i] = append(enabledIPs[hostname][i], entry.IP)
+			}
+			if current, ok := winner[hostname]; !ok || entry.Enabled || enabledIPs[hostname][current] == nil {
+				winner[hostname] = i
+			}
+		}
+	}
+
+	var entries []*HostEntry
+	var conflicts []LayerConflict
+	reported := make(map[string]bool)
+	names := make([]string, 0, len(layers))
+	ids := make([]string, 0, len(layers))
+	var authors []string
+	for i, layer := range layers {
+		names = append(names, layer.Name)
+		ids = append(ids, layer.ID)
+		if layer.Author != "" && !slices.Contains(authors, layer.Author) {
+			authors = append(authors, layer.Author)
+		}
+		for _, entry := range resolved[i] {
+			hostname := strings.ToLower(entry.Hostname)
+			if winner[hostname] == i {
+				entries = append(entries, entry)
+			}
+			if reported[hostname] {
+				continue
+			}
+			reported[hostname] = true
+			if conflict, ok := layerConflict(hostname, winner[hostname], enabledIPs[hostname], layers); ok {
+				conflicts = append(conflicts, conflict)
+			}
+		}
+	}
+
+	now := time.Now()
+	return &Profile{
+		ID:        strings.Join(ids, "+"),
+		Name:      strings.Join(names, LayerSeparator),
+		Author:    strings.Join(authors, ", "),
+		Entries:   entries,
+		CreatedAt: now,
+		UpdatedAt: now,
+		IsActive:  true,
+		Tags:      make([]string, 0),
+	}, conflicts
+}
+
+// layerConflict 检查被覆盖的层中是否有启用的条目指向与生效层不同的IP
+func layerConflict(hostname string, winner int, enabledIPs map[int][]string, layers []*Profile) (LayerConflict, bool) {
+	conflict := LayerConflict{Hostname: hostname, Winner: layers[winner].Name}
+	for i := range layers {
+		if i != winner && enabledIPs[i] != nil && !sameIPs(enabledIPs[i], enabledIPs[winner]) {
+			conflict.Overridden = append(conflict.Overridden, layers[i].Name)
+		}
+	}
+	return conflict, len(conflict.Overridden) > 0
+}
+
+// sameIPs 比较两组IP是否相同，不考虑顺序
+func sameIPs(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	counts := make(map[string]int, len(a))
+	for _, ip := range a {
+		counts[ip]++
+	}
+	for _, ip := range b {
+		counts[ip]--
+		if counts[ip] < 0 {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,78 @@
+package models
+
+import (
+	"slices"
+	"strings"
+)
+
+// TagQueryPrefix 搜索Profile时按标签筛选的前缀，例如"tag:prod"
+const TagQueryPrefix = "tag:"
+
+// TagSummary 标签及使用该标签的Profile数量
+type TagSummary struct {
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+}
+
+// ValidateTag 验证标签：去掉两端空白后不能为空，不能包含逗号（编辑时以逗号分隔标签）和换行
+func ValidateTag(tag string) (string, error) {
+	tag = strings.TrimSpace(tag)
+	if tag == "" || strings.ContainsAny(tag, ",\r\n") {
+		return "", ErrInvalidTag
+	}
+	return tag, nil
+}
+
+// HasTag Profile是否有指定标签，不区分大小写
+func (p *Profile) HasTag(tag string) bool {
+	return slices.ContainsFunc(p.Tags, func(t string) bool {
+		return strings.EqualFold(strings.TrimSpace(t), tag)
+	})
+}
+
+// ProfileQuery 解析后的Profile搜索条件
+type ProfileQuery struct {
+	// Text 在名称、描述和标签中不区分大小写地匹配的文本
+	Text string
+	// Tags 必须同时具有的标签
+	Tags []string
+}
+
+// ParseProfileQuery 解析搜索文本，"tag:"开头的词按标签筛选，其余的词作为匹配文本
+func ParseProfileQuery(query string) ProfileQuery {
+	var q ProfileQuery
+	var words []string
+	for _, word := range strings.Fields(query) {
+		if len(word) > len(TagQueryPrefix) && strings.EqualFold(word[:len(TagQueryPrefix)], TagQueryPrefix) {
+			q.Tags = append(q.Tags, word[len(TagQueryPrefix):])
+			continue
+		}
+		words = append(words, word)
+	}
+	q.Text = strings.Join(words, " ")
+	return q
+}
+
+// Empty 是否没有任何搜索条件
+func (q ProfileQuery) Empty() bool {
+	return q.Text == "" && len(q.Tags) == 0
+}
+
+// Matches Profile是否符合搜索条件，没有条件时总是符合
+func (q ProfileQuery) Matches(p *Profile) bool {
+	for _, tag := range q.Tags {
+		if !p.HasTag(tag) {
+			return false
+		}
+	}
+	if q.Text == "" {
+		return true
+	}
+	text := strings.ToLower(q.Text)
+	if strings.Contains(strings.ToLower(p.Name), text) || strings.Contains(strings.ToLower(p.Description), text) {
+		return true
+	}
+	return slices.ContainsFunc(p.Tags, func(tag string) bool {
+		return strings.Contains(strings.ToLower(tag), text)
+	})
+}
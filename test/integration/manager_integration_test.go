@@ -121,7 +121,7 @@ func (suite *ManagerIntegrationTestSuite) TestHostsFileOperations() {
 	}
 
 	// 写入Hosts文件
-	err := suite.hostManager.WriteHostsFile(testContent)
+	_, err := suite.hostManager.WriteHostsFile(testContent)
 	assert.NoError(suite.T(), err)
 
 	// 读取Hosts文件
@@ -144,7 +144,7 @@ func (suite *ManagerIntegrationTestSuite) TestBackupOperations() {
 	}
 
 	// 写入Hosts文件
-	err := suite.hostManager.WriteHostsFile(testContent)
+	_, err := suite.hostManager.WriteHostsFile(testContent)
 	assert.NoError(suite.T(), err)
 
 	// 创建备份
@@ -242,10 +242,10 @@ func (suite *ManagerIntegrationTestSuite) TestFullWorkflow() {
 
 	// 6. 创建初始hosts文件并应用Profile
 	initialHosts := []string{"127.0.0.1\tlocalhost"}
-	err = suite.hostManager.WriteHostsFile(initialHosts)
+	_, err = suite.hostManager.WriteHostsFile(initialHosts)
 	assert.NoError(suite.T(), err)
 
-	err = suite.hostManager.ApplyProfile(activeProfile)
+	_, err = suite.hostManager.ApplyProfile(activeProfile)
 	assert.NoError(suite.T(), err)
 
 	// 7. 验证Hosts文件内容
@@ -266,7 +266,7 @@ func (suite *ManagerIntegrationTestSuite) TestFullWorkflow() {
 
 	activeProfile, err = suite.profileManager.GetActiveProfile()
 	assert.NoError(suite.T(), err)
-	err = suite.hostManager.ApplyProfile(activeProfile)
+	_, err = suite.hostManager.ApplyProfile(activeProfile)
 	assert.NoError(suite.T(), err)
 
 	// 10. 验证生产环境配置已应用